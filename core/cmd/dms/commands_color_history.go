@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/colorpicker"
+	"github.com/spf13/cobra"
+)
+
+var (
+	colorHistoryJSON   bool
+	colorHistoryLimit  int
+	colorHistoryFormat string
+)
+
+var colorHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse picked colors",
+	Long: `List picked colors, newest first. Picking a color again moves it to the top
+and keeps its name.
+
+Colors are referred to by id prefix, #hex value or name; commands that take
+an optional color default to the latest pick.
+
+Examples:
+  dms color history                       # List picked colors
+  dms color history copy --format rgb     # Copy the latest color as RGB
+  dms color history rename 3fa2 "Brand blue"
+  dms color history contrast              # Compare the last two picks
+  dms color history contrast Text "#1e1e2e"
+  dms color history clear`,
+	Args: cobra.NoArgs,
+	Run:  runColorHistory,
+}
+
+var colorHistoryCopyCmd = &cobra.Command{
+	Use:   "copy [color]",
+	Short: "Copy a picked color to the clipboard",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runColorHistoryCopy,
+}
+
+var colorHistoryRenameCmd = &cobra.Command{
+	Use:   "rename <color> <name>",
+	Short: "Name a picked color",
+	Args:  cobra.ExactArgs(2),
+	Run:   runColorHistoryRename,
+}
+
+var colorHistoryDeleteCmd = &cobra.Command{
+	Use:   "delete <color>",
+	Short: "Remove a color from the history",
+	Args:  cobra.ExactArgs(1),
+	Run:   runColorHistoryDelete,
+}
+
+var colorHistoryClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all colors from the history",
+	Args:  cobra.NoArgs,
+	Run:   runColorHistoryClear,
+}
+
+var colorHistoryContrastCmd = &cobra.Command{
+	Use:   "contrast [foreground] [background]",
+	Short: "Check the contrast between two colors",
+	Long: `Show the WCAG 2 contrast ratio and level (AAA, AA, AA LG for large text only,
+or FAIL) and the APCA-style lightness contrast (LC) of text in the foreground
+color on the background. Colors are history references or #hex values;
+without arguments the last two picks are compared.`,
+	Args: cobra.RangeArgs(0, 2),
+	Run:  runColorHistoryContrast,
+}
+
+func init() {
+	colorHistoryCmd.Flags().BoolVar(&colorHistoryJSON, "json", false, "Output as JSON")
+	colorHistoryCmd.Flags().IntVarP(&colorHistoryLimit, "limit", "n", 20, "Number of colors to show (0 for all)")
+	colorHistoryCopyCmd.Flags().StringVarP(&colorHistoryFormat, "format", "f", "hex", "Copy as hex, rgb, hsl, hsv or cmyk")
+	colorHistoryCopyCmd.Flags().BoolVarP(&colorLowercase, "lowercase", "l", false, "Copy hex in lowercase")
+	colorHistoryContrastCmd.Flags().BoolVar(&colorHistoryJSON, "json", false, "Output as JSON")
+
+	colorHistoryCmd.AddCommand(colorHistoryCopyCmd, colorHistoryRenameCmd, colorHistoryDeleteCmd, colorHistoryClearCmd, colorHistoryContrastCmd)
+	colorCmd.AddCommand(colorHistoryCmd)
+}
+
+func runColorHistory(cmd *cobra.Command, args []string) {
+	entries, err := colorpicker.DefaultPalette().List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if colorHistoryLimit > 0 && len(entries) > colorHistoryLimit {
+		entries = entries[:colorHistoryLimit]
+	}
+
+	if colorHistoryJSON {
+		if entries == nil {
+			entries = []colorpicker.PaletteEntry{}
+		}
+		out, _ := json.MarshalIndent(entries, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No colors in history")
+		return
+	}
+
+	for _, e := range entries {
+		line := fmt.Sprintf("%s  %s  %s", e.ID, e.Time.Local().Format("2006-01-02 15:04:05"), e.Hex)
+		if e.Name != "" {
+			line += "  " + e.Name
+		}
+		printColorSwatch(e.Color(), line)
+	}
+}
+
+func runColorHistoryCopy(cmd *cobra.Command, args []string) {
+	entry, err := colorpicker.DefaultPalette().Get(historyRef(args))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	text := entry.Color().Format(colorpicker.ParseFormat(colorHistoryFormat), colorLowercase, "")
+	if err := clipboard.CopyText(text); err != nil {
+		fmt.Fprintf(os.Stderr, "Error copying to clipboard: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(text)
+}
+
+func runColorHistoryRename(cmd *cobra.Command, args []string) {
+	entry, err := colorpicker.DefaultPalette().Rename(args[0], args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Named %s (%s) %q\n", entry.ID, entry.Hex, entry.Name)
+}
+
+func runColorHistoryDelete(cmd *cobra.Command, args []string) {
+	entry, err := colorpicker.DefaultPalette().Delete(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Removed %s (%s)\n", entry.ID, entry.Hex)
+}
+
+func runColorHistoryClear(cmd *cobra.Command, args []string) {
+	if err := colorpicker.DefaultPalette().Clear(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Color history cleared")
+}
+
+func runColorHistoryContrast(cmd *cobra.Command, args []string) {
+	palette := colorpicker.DefaultPalette()
+
+	var fg, bg colorpicker.Color
+	switch len(args) {
+	case 2:
+		fg, bg = resolveHistoryColor(palette, args[0]), resolveHistoryColor(palette, args[1])
+	case 1:
+		fmt.Fprintln(os.Stderr, "Error: give both colors, or none to compare the last two picks")
+		os.Exit(1)
+	default:
+		entries, err := palette.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(entries) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: need two colors in history to compare")
+			os.Exit(1)
+		}
+		fg, bg = entries[1].Color(), entries[0].Color()
+	}
+
+	c := colorpicker.ContrastOf(fg, bg)
+	if colorHistoryJSON {
+		out, _ := json.MarshalIndent(c, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	fmt.Printf("\033[48;2;%d;%d;%dm\033[38;2;%d;%d;%dm %s on %s \033[0m\n",
+		bg.R, bg.G, bg.B, fg.R, fg.G, fg.B, c.Foreground, c.Background)
+	fmt.Printf("Ratio    %.2f:1 (%s)\n", c.Ratio, c.Level())
+	fmt.Printf("Normal   AA %s  AAA %s\n", passMark(c.AA), passMark(c.AAA))
+	fmt.Printf("Large    AA %s  AAA %s\n", passMark(c.AALarge), passMark(c.AAALarge))
+	fmt.Printf("LC       %.0f\n", c.Lc)
+}
+
+func resolveHistoryColor(palette *colorpicker.Palette, ref string) colorpicker.Color {
+	if strings.HasPrefix(ref, "#") {
+		if c, err := colorpicker.ParseHex(ref); err == nil {
+			return c
+		}
+	}
+	entry, err := palette.Get(ref)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return entry.Color()
+}
+
+func passMark(ok bool) string {
+	if ok {
+		return "pass"
+	}
+	return "fail"
+}
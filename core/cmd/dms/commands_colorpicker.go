@@ -1,8 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/colorpicker"
@@ -14,6 +16,9 @@ var (
 	colorAutocopy  bool
 	colorNotify    bool
 	colorLowercase bool
+	colorMulti     bool
+	colorName      string
+	colorNoHistory bool
 )
 
 var colorCmd = &cobra.Command{
@@ -29,6 +34,13 @@ var colorPickCmd = &cobra.Command{
 
 Click on any pixel to capture its color, or press Escape to cancel.
 
+With --multi the picker stays open: click (or press Space) to collect
+colors and press Enter, Escape or right click when done. The last two picks
+are compared on screen: the earlier one as text on the later one, with the
+WCAG contrast ratio and level and the APCA-style lightness contrast (LC).
+
+Picked colors are kept in the color history (see 'dms color history').
+
 Output format flags (mutually exclusive, default: --hex):
   --hex  - Hexadecimal (#RRGGBB)
   --rgb  - RGB values (R G B)
@@ -42,7 +54,9 @@ Examples:
   dms color pick --rgb          # Output as RGB
   dms color pick --json         # Output all formats as JSON
   dms color pick --hex -l       # Output hex in lowercase
-  dms color pick -a             # Auto-copy result to clipboard`,
+  dms color pick -a             # Auto-copy result to clipboard
+  dms color pick --multi        # Collect colors and check their contrast
+  dms color pick --name Accent  # Name the color in the history`,
 	Run: runColorPick,
 }
 
@@ -56,6 +70,9 @@ func init() {
 	colorPickCmd.Flags().StringVarP(&colorOutputFmt, "output-format", "o", "", "Custom output format template")
 	colorPickCmd.Flags().BoolVarP(&colorAutocopy, "autocopy", "a", false, "Copy result to clipboard")
 	colorPickCmd.Flags().BoolVarP(&colorLowercase, "lowercase", "l", false, "Output hex in lowercase")
+	colorPickCmd.Flags().BoolVarP(&colorMulti, "multi", "m", false, "Keep picking until Enter/Escape and compare the last two colors")
+	colorPickCmd.Flags().StringVar(&colorName, "name", "", "Name for the picked color in the history")
+	colorPickCmd.Flags().BoolVar(&colorNoHistory, "no-history", false, "Don't add the picked colors to the history")
 
 	colorPickCmd.MarkFlagsMutuallyExclusive("hex", "rgb", "hsl", "hsv", "cmyk", "json")
 
@@ -85,6 +102,11 @@ func runColorPick(cmd *cobra.Command, args []string) {
 	}
 
 	picker := colorpicker.New(config)
+	if colorMulti {
+		runColorPickMulti(picker, config, jsonOutput)
+		return
+	}
+
 	color, err := picker.Run()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -94,6 +116,7 @@ func runColorPick(cmd *cobra.Command, args []string) {
 	if color == nil {
 		os.Exit(0)
 	}
+	addToColorHistory(*color)
 
 	var output string
 	if jsonOutput {
@@ -113,10 +136,90 @@ func runColorPick(cmd *cobra.Command, args []string) {
 
 	if jsonOutput {
 		fmt.Println(output)
-	} else if color.IsDark() {
-		fmt.Printf("\033[48;2;%d;%d;%dm\033[97m %s \033[0m\n", color.R, color.G, color.B, output)
 	} else {
-		fmt.Printf("\033[48;2;%d;%d;%dm\033[30m %s \033[0m\n", color.R, color.G, color.B, output)
+		printColorSwatch(*color, output)
+	}
+}
+
+func runColorPickMulti(picker *colorpicker.Picker, config colorpicker.Config, jsonOutput bool) {
+	colors, err := picker.RunMulti()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(colors) == 0 {
+		os.Exit(0)
+	}
+	addToColorHistory(colors...)
+
+	var contrast *colorpicker.Contrast
+	if n := len(colors); n >= 2 {
+		c := colorpicker.ContrastOf(colors[n-2], colors[n-1])
+		contrast = &c
+	}
+
+	lines := make([]string, len(colors))
+	for i, c := range colors {
+		lines[i] = c.Format(config.Format, config.Lowercase, config.CustomFormat)
+	}
+
+	var output string
+	if jsonOutput {
+		result := struct {
+			Colors   []colorpicker.ColorJSON `json:"colors"`
+			Contrast *colorpicker.Contrast   `json:"contrast,omitempty"`
+		}{Contrast: contrast}
+		for _, c := range colors {
+			result.Colors = append(result.Colors, c.JSON())
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		output = string(data)
+	} else {
+		output = strings.Join(lines, "\n")
+	}
+
+	if colorAutocopy {
+		copyToClipboard(output)
+	}
+
+	if jsonOutput {
+		fmt.Println(output)
+		return
+	}
+	for i, c := range colors {
+		printColorSwatch(c, lines[i])
+	}
+	if contrast != nil {
+		fmt.Printf("Contrast %s on %s: %s\n", contrast.Foreground, contrast.Background, contrast)
+	}
+}
+
+func addToColorHistory(colors ...colorpicker.Color) {
+	if colorNoHistory {
+		return
+	}
+	palette := colorpicker.DefaultPalette()
+	added, err := palette.Add(colors...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: color history: %v\n", err)
+		return
+	}
+	if colorName != "" {
+		if _, err := palette.Rename(added[len(added)-1].ID, colorName); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: color history: %v\n", err)
+		}
+	}
+}
+
+func printColorSwatch(color colorpicker.Color, text string) {
+	if color.IsDark() {
+		fmt.Printf("\033[48;2;%d;%d;%dm\033[97m %s \033[0m\n", color.R, color.G, color.B, text)
+	} else {
+		fmt.Printf("\033[48;2;%d;%d;%dm\033[30m %s \033[0m\n", color.R, color.G, color.B, text)
 	}
 }
 
@@ -516,6 +516,7 @@ func getCommonCommands() []*cobra.Command {
 		setupCmd,
 		colorCmd,
 		screenshotCmd,
+		recordCmd,
 		notifyActionCmd,
 		notifyCmd,
 		genericNotifyActionCmd,
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/config"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
 	"github.com/spf13/cobra"
@@ -25,7 +26,7 @@ var resolveIncludeCmd = &cobra.Command{
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		switch len(args) {
 		case 0:
-			return []string{"hyprland", "niri", "mangowc"}, cobra.ShellCompDirectiveNoFileComp
+			return []string{"hyprland", "niri", "mangowc", "sway"}, cobra.ShellCompDirectiveNoFileComp
 		case 1:
 			return []string{"cursor.kdl", "cursor.conf", "outputs.kdl", "outputs.conf", "binds.kdl", "binds.conf"}, cobra.ShellCompDirectiveNoFileComp
 		}
@@ -38,6 +39,50 @@ func init() {
 	configCmd.AddCommand(resolveIncludeCmd)
 }
 
+var statusJSONOutput bool
+
+var configStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show deployed configs that drifted from their template",
+	Long:  "Compare every compositor config deployed by dms setup with what was written and with the template it came from.",
+	Args:  cobra.NoArgs,
+	Run:   runConfigStatus,
+}
+
+func init() {
+	configStatusCmd.Flags().BoolVar(&statusJSONOutput, "json", false, "Output as JSON")
+	configCmd.AddCommand(configStatusCmd)
+}
+
+func runConfigStatus(cmd *cobra.Command, args []string) {
+	statuses, err := config.NewConfigDeployer(nil).Status()
+	if err != nil {
+		log.Fatalf("Failed to read deployment status: %v", err)
+	}
+
+	if statusJSONOutput {
+		out, _ := json.MarshalIndent(statuses, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("No deployed configs recorded yet")
+		return
+	}
+
+	for _, status := range statuses {
+		fmt.Printf("%-10s %-9s %s\n", status.ConfigType, status.State, status.Path)
+		fmt.Printf("    deployed %s\n", status.DeployedAt.Local().Format("2006-01-02 15:04:05"))
+		if len(status.DriftedSections) > 0 {
+			fmt.Printf("    differs from template in: %s\n", strings.Join(status.DriftedSections, ", "))
+		}
+		if status.UpstreamChanged {
+			fmt.Println("    template has been updated, run 'dms setup' to merge it")
+		}
+	}
+}
+
 type IncludeResult struct {
 	Exists   bool `json:"exists"`
 	Included bool `json:"included"`
@@ -57,6 +102,8 @@ func runResolveInclude(cmd *cobra.Command, args []string) {
 		result, err = checkNiriInclude(filename)
 	case "mangowc", "dwl", "mango":
 		result, err = checkMangoWCInclude(filename)
+	case "sway":
+		result, err = checkSwayInclude(filename)
 	default:
 		log.Fatalf("Unknown compositor: %s", compositor)
 	}
@@ -311,6 +358,82 @@ func mangowcFindInclude(filePath, target string, processed map[string]bool) bool
 	return false
 }
 
+func checkSwayInclude(filename string) (IncludeResult, error) {
+	configDir, err := utils.ExpandPath("$HOME/.config/sway")
+	if err != nil {
+		return IncludeResult{}, err
+	}
+
+	targetPath := filepath.Join(configDir, "dms", filename)
+	result := IncludeResult{}
+
+	if _, err := os.Stat(targetPath); err == nil {
+		result.Exists = true
+	}
+
+	mainConfig := filepath.Join(configDir, "config")
+	if _, err := os.Stat(mainConfig); os.IsNotExist(err) {
+		return result, nil
+	}
+
+	processed := make(map[string]bool)
+	result.Included = swayFindInclude(mainConfig, "dms/"+filename, processed)
+	return result, nil
+}
+
+func swayFindInclude(filePath, target string, processed map[string]bool) bool {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return false
+	}
+
+	if processed[absPath] {
+		return false
+	}
+	processed[absPath] = true
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return false
+	}
+
+	baseDir := filepath.Dir(absPath)
+	lines := strings.Split(string(data), "\n")
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "#") || trimmed == "" {
+			continue
+		}
+
+		includePath, ok := strings.CutPrefix(trimmed, "include ")
+		if !ok {
+			continue
+		}
+
+		includePath = strings.Trim(strings.TrimSpace(includePath), `"'`)
+		if matchesTarget(includePath, target) {
+			return true
+		}
+
+		fullPath := includePath
+		if !filepath.IsAbs(includePath) {
+			fullPath = filepath.Join(baseDir, includePath)
+		}
+
+		expanded, err := utils.ExpandPath(fullPath)
+		if err != nil {
+			continue
+		}
+
+		if swayFindInclude(expanded, target, processed) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func matchesTarget(path, target string) bool {
 	path = strings.TrimPrefix(path, "./")
 	target = strings.TrimPrefix(target, "./")
@@ -844,7 +844,7 @@ func checkOptionalDependencies() []checkResult {
 		name, cmd, desc string
 		important       bool
 	}{
-		{"matugen", "matugen", "Dynamic theming", true},
+		{"matugen", "matugen", "Dynamic theming (built-in fallback)", false},
 		{"dgop", "dgop", "System monitoring", true},
 		{"cava", "cava", "Audio visualizer", true},
 		{"khal", "khal", "Calendar events", false},
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyJSONOutput bool
+	historyLimit      int
+	rollbackForce     bool
+)
+
+var configHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List recorded config changes",
+	Long:  "List every change DMS made to config files, newest first, with the command that made it.",
+	Args:  cobra.NoArgs,
+	Run:   runConfigHistory,
+}
+
+var configDiffCmd = &cobra.Command{
+	Use:   "diff [rev]",
+	Short: "Show the changes made by a revision",
+	Long:  "Show a unified diff of the files changed by a revision. Defaults to the latest revision.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runConfigDiff,
+}
+
+var configRollbackCmd = &cobra.Command{
+	Use:   "rollback [rev]",
+	Short: "Undo the changes made by a revision",
+	Long:  "Restore every file changed by a revision to its previous content. Defaults to the latest revision. The rollback itself is recorded and can be undone.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runConfigRollback,
+}
+
+func init() {
+	configHistoryCmd.Flags().BoolVar(&historyJSONOutput, "json", false, "Output as JSON")
+	configHistoryCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Number of revisions to show (0 for all)")
+	configRollbackCmd.Flags().BoolVarP(&rollbackForce, "force", "f", false, "Roll back even if files changed since the revision")
+
+	configCmd.AddCommand(configHistoryCmd, configDiffCmd, configRollbackCmd)
+}
+
+func runConfigHistory(cmd *cobra.Command, args []string) {
+	revisions, err := snapshot.Default().List()
+	if err != nil {
+		log.Fatalf("Failed to read config history: %v", err)
+	}
+
+	var shown []snapshot.Revision
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if historyLimit > 0 && len(shown) >= historyLimit {
+			break
+		}
+		shown = append(shown, revisions[i])
+	}
+
+	if historyJSONOutput {
+		if shown == nil {
+			shown = []snapshot.Revision{}
+		}
+		out, _ := json.MarshalIndent(shown, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	if len(shown) == 0 {
+		fmt.Println("No config history recorded yet")
+		return
+	}
+
+	for _, rev := range shown {
+		fmt.Printf("%s  %s  %s\n", rev.ID, rev.Time.Local().Format("2006-01-02 15:04:05"), rev.Command)
+		for _, f := range rev.Files {
+			status := "M"
+			switch {
+			case f.Before == "":
+				status = "A"
+			case f.After == "":
+				status = "D"
+			}
+			fmt.Printf("    %s %s\n", status, f.Path)
+		}
+	}
+}
+
+func resolveRevision(args []string) (*snapshot.Store, *snapshot.Revision) {
+	ref := ""
+	if len(args) > 0 {
+		ref = args[0]
+	}
+
+	store := snapshot.Default()
+	rev, err := store.Resolve(ref)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	return store, rev
+}
+
+func runConfigDiff(cmd *cobra.Command, args []string) {
+	store, rev := resolveRevision(args)
+
+	diff, err := store.Diff(rev)
+	if err != nil {
+		log.Fatalf("Failed to diff revision %s: %v", rev.ID, err)
+	}
+	fmt.Print(diff)
+}
+
+func runConfigRollback(cmd *cobra.Command, args []string) {
+	store, rev := resolveRevision(args)
+
+	restored, err := store.Rollback(snapshot.CommandLine(), rev, rollbackForce)
+	var conflict *snapshot.RollbackError
+	switch {
+	case errors.As(err, &conflict):
+		fmt.Fprintf(os.Stderr, "Refusing to roll back %s, these files changed since:\n", rev.ID)
+		for _, path := range conflict.Conflicts {
+			fmt.Fprintf(os.Stderr, "    %s\n", path)
+		}
+		fmt.Fprintln(os.Stderr, "Use --force to overwrite them.")
+		os.Exit(1)
+	case err != nil:
+		log.Fatalf("Rollback of %s failed after restoring %d file(s): %v", rev.ID, len(restored), err)
+	}
+
+	fmt.Printf("Rolled back %s (%s)\n", rev.ID, rev.Command)
+	for _, path := range restored {
+		fmt.Printf("    %s\n", path)
+	}
+}
@@ -57,6 +57,22 @@ var keybindsRemoveCmd = &cobra.Command{
 	Run:   runKeybindsRemove,
 }
 
+var keybindsMigrateCmd = &cobra.Command{
+	Use:   "migrate <from> <to>",
+	Short: "Migrate keybinds between compositors",
+	Long:  "Translate keybinds from one compositor's config into another's DMS override file, reporting binds that have no equivalent",
+	Args:  cobra.ExactArgs(2),
+	Run:   runKeybindsMigrate,
+}
+
+var keybindsCheckCmd = &cobra.Command{
+	Use:   "check <provider> [key]",
+	Short: "Check keybinds for conflicts",
+	Long:  "Report keys bound more than once across the compositor config, DMS overrides and application cheatsheets. With a key, only report conflicts that binding it as a DMS override would cause.",
+	Args:  cobra.RangeArgs(1, 2),
+	Run:   runKeybindsCheck,
+}
+
 func init() {
 	keybindsListCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 	keybindsShowCmd.Flags().String("path", "", "Override config path for the provider")
@@ -68,10 +84,15 @@ func init() {
 	keybindsSetCmd.Flags().String("replace-key", "", "Original key to replace (removes old key)")
 	keybindsSetCmd.Flags().String("flags", "", "Hyprland bind flags (e.g., 'e' for repeat, 'l' for locked, 'r' for release)")
 
+	keybindsMigrateCmd.Flags().Bool("dry-run", false, "Show the translation without writing the override file")
+	keybindsCheckCmd.Flags().String("action", "", "Action of the bind being checked")
+
 	keybindsCmd.AddCommand(keybindsListCmd)
 	keybindsCmd.AddCommand(keybindsShowCmd)
 	keybindsCmd.AddCommand(keybindsSetCmd)
 	keybindsCmd.AddCommand(keybindsRemoveCmd)
+	keybindsCmd.AddCommand(keybindsMigrateCmd)
+	keybindsCmd.AddCommand(keybindsCheckCmd)
 
 	keybinds.SetJSONProviderFactory(func(filePath string) (keybinds.Provider, error) {
 		return providers.NewJSONFileProvider(filePath)
@@ -114,6 +135,11 @@ func initializeProviders() {
 		}
 	}
 
+	labwcProvider := providers.NewLabwcProvider("")
+	if err := registry.Register(labwcProvider); err != nil {
+		log.Warnf("Failed to register labwc provider: %v", err)
+	}
+
 	niriProvider := providers.NewNiriProvider("")
 	if err := registry.Register(niriProvider); err != nil {
 		log.Warnf("Failed to register Niri provider: %v", err)
@@ -160,6 +186,8 @@ func makeProviderWithPath(name, path string) keybinds.Provider {
 		return providers.NewMiracleProvider(path)
 	case "niri":
 		return providers.NewNiriProvider(path)
+	case "labwc":
+		return providers.NewLabwcProvider(path)
 	default:
 		return nil
 	}
@@ -263,3 +291,53 @@ func runKeybindsRemove(_ *cobra.Command, args []string) {
 	}, "", "  ")
 	fmt.Fprintln(os.Stdout, string(output))
 }
+
+func runKeybindsMigrate(cmd *cobra.Command, args []string) {
+	if err := providers.CheckMigration(args[0], args[1]); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	from, err := keybinds.GetDefaultRegistry().Get(args[0])
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	to := getWritableProvider(args[1])
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	result, err := providers.Migrate(from, to, dryRun)
+	if err != nil {
+		log.Fatalf("Error migrating keybinds: %v", err)
+	}
+
+	output, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Fatalf("Error generating JSON: %v", err)
+	}
+	fmt.Fprintln(os.Stdout, string(output))
+}
+
+func runKeybindsCheck(cmd *cobra.Command, args []string) {
+	provider, err := keybinds.GetDefaultRegistry().Get(args[0])
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	analyzer := keybinds.NewConflictAnalyzer(provider, keybinds.ApplicationProviders(nil))
+
+	var report *keybinds.ConflictReport
+	switch len(args) {
+	case 2:
+		action, _ := cmd.Flags().GetString("action")
+		report, err = analyzer.CheckBind(args[1], action)
+	default:
+		report, err = analyzer.Analyze()
+	}
+	if err != nil {
+		log.Fatalf("Error checking keybinds: %v", err)
+	}
+
+	output, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("Error generating JSON: %v", err)
+	}
+	fmt.Fprintln(os.Stdout, string(output))
+}
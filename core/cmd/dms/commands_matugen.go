@@ -17,7 +17,7 @@ import (
 var matugenCmd = &cobra.Command{
 	Use:   "matugen",
 	Short: "Generate Material Design themes",
-	Long:  "Generate Material Design themes using matugen with dank16 color integration",
+	Long:  "Generate Material Design themes with dank16 color integration, using matugen or the built-in generator",
 }
 
 var matugenGenerateCmd = &cobra.Command{
@@ -57,6 +57,7 @@ func init() {
 		cmd.Flags().Bool("sync-mode-with-portal", false, "Sync color scheme with GNOME portal")
 		cmd.Flags().Bool("terminals-always-dark", false, "Force terminal themes to dark variant")
 		cmd.Flags().String("skip-templates", "", "Comma-separated list of templates to skip")
+		cmd.Flags().String("backend", matugen.BackendAuto, "Color generator: auto, matugen or native")
 	}
 
 	matugenQueueCmd.Flags().Bool("wait", true, "Wait for completion")
@@ -77,6 +78,7 @@ func buildMatugenOptions(cmd *cobra.Command) matugen.Options {
 	syncModeWithPortal, _ := cmd.Flags().GetBool("sync-mode-with-portal")
 	terminalsAlwaysDark, _ := cmd.Flags().GetBool("terminals-always-dark")
 	skipTemplates, _ := cmd.Flags().GetString("skip-templates")
+	backend, _ := cmd.Flags().GetString("backend")
 
 	return matugen.Options{
 		StateDir:            stateDir,
@@ -92,6 +94,7 @@ func buildMatugenOptions(cmd *cobra.Command) matugen.Options {
 		SyncModeWithPortal:  syncModeWithPortal,
 		TerminalsAlwaysDark: terminalsAlwaysDark,
 		SkipTemplates:       skipTemplates,
+		Backend:             backend,
 	}
 }
 
@@ -128,6 +131,7 @@ func runMatugenQueue(cmd *cobra.Command, args []string) {
 			"syncModeWithPortal":  opts.SyncModeWithPortal,
 			"terminalsAlwaysDark": opts.TerminalsAlwaysDark,
 			"skipTemplates":       opts.SkipTemplates,
+			"backend":             opts.Backend,
 			"wait":                wait,
 		},
 	}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recOutputName string
+	recCursor     string
+	recFormat     string
+	recFPS        int
+	recOutputDir  string
+	recFilename   string
+	recEncoder    string
+	recDuration   time.Duration
+	recNoNotify   bool
+	recBackground bool
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record the screen to an animated image",
+	Long: `Record a region or output to an animated GIF, APNG or WebP, or pipe raw
+frames to an encoder of your choice. Frames are only captured when the screen
+changes, so idle periods cost nothing.
+
+Modes:
+  region      - Select a region interactively (default)
+  full        - Record the focused output
+  output      - Record a specific output by name
+  window      - Record the area of the focused window (Hyprland/DWL)
+  last        - Record the last selected region
+
+Output format (--format):
+  gif         - Animated GIF (default)
+  apng        - Animated PNG, lossless
+  webp        - Animated lossless WebP
+  pipe        - Raw RGBA frames on stdin of --encoder; {width}, {height},
+                {fps} and {file} are substituted in the command
+
+A foreground recording stops on Ctrl+C or SIGTERM; SIGUSR1 pauses and resumes.
+With --background the shell records instead, controlled by the stop, pause,
+resume and toggle subcommands.
+
+Examples:
+  dms record                          # Select a region, record a GIF
+  dms record full -f webp             # Focused output as animated WebP
+  dms record last --duration 10s      # Last region for ten seconds
+  dms record -b                       # Record in the shell, e.g. from a keybind
+  dms record stop                     # Stop a background recording
+  dms record -f pipe --encoder 'ffmpeg -f rawvideo -pix_fmt rgba -s {width}x{height} -r {fps} -i - -y {file}'`,
+}
+
+var recRegionCmd = &cobra.Command{
+	Use:   "region",
+	Short: "Select a region interactively",
+	Run:   func(cmd *cobra.Command, args []string) { runRecord(screenshot.ModeRegion) },
+}
+
+var recFullCmd = &cobra.Command{
+	Use:   "full",
+	Short: "Record the focused output",
+	Run:   func(cmd *cobra.Command, args []string) { runRecord(screenshot.ModeFullScreen) },
+}
+
+var recOutputCmd = &cobra.Command{
+	Use:   "output",
+	Short: "Record a specific output",
+	Run: func(cmd *cobra.Command, args []string) {
+		if recOutputName == "" && len(args) > 0 {
+			recOutputName = args[0]
+		}
+		if recOutputName == "" {
+			fmt.Fprintln(os.Stderr, "Error: output name required (use -o or provide as argument)")
+			os.Exit(1)
+		}
+		runRecord(screenshot.ModeOutput)
+	},
+}
+
+var recWindowCmd = &cobra.Command{
+	Use:   "window",
+	Short: "Record the area of the focused window",
+	Run:   func(cmd *cobra.Command, args []string) { runRecord(screenshot.ModeWindow) },
+}
+
+var recLastCmd = &cobra.Command{
+	Use:   "last",
+	Short: "Record the last selected region",
+	Run:   func(cmd *cobra.Command, args []string) { runRecord(screenshot.ModeLastRegion) },
+}
+
+var recStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the background recording",
+	Run:   func(cmd *cobra.Command, args []string) { runRecordControl("screenshot.record.stop") },
+}
+
+var recPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the background recording",
+	Run:   func(cmd *cobra.Command, args []string) { runRecordControl("screenshot.record.pause") },
+}
+
+var recResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the background recording",
+	Run:   func(cmd *cobra.Command, args []string) { runRecordControl("screenshot.record.resume") },
+}
+
+var recToggleCmd = &cobra.Command{
+	Use:   "toggle",
+	Short: "Pause or resume the background recording",
+	Run:   func(cmd *cobra.Command, args []string) { runRecordControl("screenshot.record.toggle") },
+}
+
+var recStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the background recording state",
+	Run:   func(cmd *cobra.Command, args []string) { runRecordControl("screenshot.record.getState") },
+}
+
+func init() {
+	recordCmd.PersistentFlags().StringVarP(&recOutputName, "output", "o", "", "Output name for 'output' mode")
+	recordCmd.PersistentFlags().StringVar(&recCursor, "cursor", "off", "Include cursor in recording (on/off)")
+	recordCmd.PersistentFlags().StringVarP(&recFormat, "format", "f", "gif", "Output format (gif, apng, webp, pipe)")
+	recordCmd.PersistentFlags().IntVar(&recFPS, "fps", screenshot.DefaultRecordFPS, "Maximum frames per second")
+	recordCmd.PersistentFlags().StringVarP(&recOutputDir, "dir", "d", "", "Output directory")
+	recordCmd.PersistentFlags().StringVar(&recFilename, "filename", "", "Output filename (auto-generated if empty)")
+	recordCmd.PersistentFlags().StringVar(&recEncoder, "encoder", "", "Encoder command for the pipe format")
+	recordCmd.PersistentFlags().DurationVar(&recDuration, "duration", 0, "Stop after this long (e.g. 30s)")
+	recordCmd.PersistentFlags().BoolVar(&recNoNotify, "no-notify", false, "Don't show notification")
+	recordCmd.PersistentFlags().BoolVarP(&recBackground, "background", "b", false, "Record in the running shell instead of the foreground")
+
+	recordCmd.AddCommand(recRegionCmd, recFullCmd, recOutputCmd, recWindowCmd, recLastCmd)
+	recordCmd.AddCommand(recStopCmd, recPauseCmd, recResumeCmd, recToggleCmd, recStatusCmd)
+
+	recordCmd.Run = func(cmd *cobra.Command, args []string) { runRecord(screenshot.ModeRegion) }
+}
+
+func getRecordConfig(mode screenshot.Mode) (screenshot.RecordConfig, error) {
+	config := screenshot.DefaultRecordConfig()
+	config.Mode = mode
+	config.OutputName = recOutputName
+	if strings.EqualFold(recCursor, "on") {
+		config.Cursor = screenshot.CursorOn
+	}
+
+	format, err := screenshot.ParseRecordFormat(recFormat)
+	if err != nil {
+		return config, err
+	}
+	config.Format = format
+	config.Encoder = recEncoder
+	if format == screenshot.RecordPipe && recEncoder == "" {
+		return config, fmt.Errorf("--encoder is required for the pipe format")
+	}
+
+	if recFPS < 1 || recFPS > screenshot.MaxRecordFPS {
+		return config, fmt.Errorf("--fps must be between 1 and %d", screenshot.MaxRecordFPS)
+	}
+	config.FPS = recFPS
+	config.OutputDir = recOutputDir
+	config.Filename = recFilename
+	config.MaxDuration = recDuration
+
+	return config, nil
+}
+
+func runRecord(mode screenshot.Mode) {
+	config, err := getRecordConfig(mode)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if recBackground {
+		startBackgroundRecording(config)
+		return
+	}
+
+	rec := screenshot.NewRecorder(config)
+	rec.OnState(printRecordState)
+
+	if err := rec.Start(); err != nil {
+		if errors.Is(err, screenshot.ErrRecordCancelled) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGUSR1)
+	defer signal.Stop(sigs)
+
+	go func() {
+		for sig := range sigs {
+			if sig != syscall.SIGUSR1 {
+				rec.Stop()
+				continue
+			}
+			if rec.State().Paused {
+				rec.Resume()
+			} else {
+				rec.Pause()
+			}
+		}
+	}()
+
+	path, err := rec.Wait()
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(path)
+
+	if !recNoNotify {
+		screenshot.SendNotification(screenshot.NotifyResult{Summary: "Recording saved", FilePath: path})
+	}
+}
+
+func printRecordState(state screenshot.RecordState) {
+	if !state.Recording {
+		return
+	}
+	status := "Recording"
+	if state.Paused {
+		status = "Paused   "
+	}
+	fmt.Fprintf(os.Stderr, "\r%s %s", status, formatElapsed(state.Elapsed))
+}
+
+func formatElapsed(ms int64) string {
+	d := time.Duration(ms) * time.Millisecond
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+func startBackgroundRecording(config screenshot.RecordConfig) {
+	params := map[string]any{
+		"mode":        config.Mode.String(),
+		"format":      config.Format.String(),
+		"fps":         config.FPS,
+		"cursor":      config.Cursor == screenshot.CursorOn,
+		"maxDuration": config.MaxDuration.Seconds(),
+	}
+	for key, value := range map[string]string{
+		"output":   config.OutputName,
+		"dir":      config.OutputDir,
+		"filename": config.Filename,
+		"encoder":  config.Encoder,
+	} {
+		if value != "" {
+			params[key] = value
+		}
+	}
+
+	resp, err := sendServerRequest(models.Request{ID: 1, Method: "screenshot.record.start", Params: params})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if resp.Error != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", resp.Error)
+		os.Exit(1)
+	}
+	if result, ok := (*resp.Result).(map[string]any); ok {
+		if path, ok := result["path"].(string); ok {
+			fmt.Println(path)
+		}
+	}
+}
+
+func runRecordControl(method string) {
+	resp, err := sendServerRequest(models.Request{ID: 1, Method: method})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if resp.Error != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", resp.Error)
+		os.Exit(1)
+	}
+
+	result, ok := (*resp.Result).(map[string]any)
+	if !ok {
+		return
+	}
+
+	switch method {
+	case "screenshot.record.stop":
+		if path, ok := result["path"].(string); ok {
+			fmt.Println(path)
+		}
+	case "screenshot.record.getState":
+		recording, _ := result["recording"].(bool)
+		paused, _ := result["paused"].(bool)
+		elapsed, _ := result["elapsed"].(float64)
+		path, _ := result["path"].(string)
+		switch {
+		case paused:
+			fmt.Printf("paused %s %s\n", formatElapsed(int64(elapsed)), path)
+		case recording:
+			fmt.Printf("recording %s %s\n", formatElapsed(int64(elapsed)), path)
+		default:
+			fmt.Println("idle")
+		}
+	}
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
@@ -23,6 +28,15 @@ var (
 	ssNoFile      bool
 	ssNoNotify    bool
 	ssStdout      bool
+	ssAnnotate    bool
+	ssNoHistory   bool
+	ssDelay       string
+	ssNoCountdown bool
+	ssInterval    string
+	ssCount       int
+	ssCompression string
+	ssPalette     string
+	ssNoMetadata  bool
 )
 
 var screenshotCmd = &cobra.Command{
@@ -42,6 +56,11 @@ Output format (--format):
   png         - PNG format (default)
   jpg/jpeg    - JPEG format
   ppm         - PPM format
+  webp        - Lossless WebP, much smaller than PNG for large captures
+  qoi         - QOI format, fast lossless encoding
+
+PNG and WebP files carry the capture time, output name and, in window mode,
+the app id of the captured window as metadata (--no-metadata to skip).
 
 Examples:
   dms screenshot                     # Region select, save file + clipboard
@@ -53,7 +72,15 @@ Examples:
   dms screenshot --no-clipboard      # Save file only
   dms screenshot --no-file           # Clipboard only
   dms screenshot --cursor=on         # Include cursor
-  dms screenshot -f jpg -q 85        # JPEG with quality 85`,
+  dms screenshot --annotate          # Annotate the region before saving
+  dms screenshot -f jpg -q 85        # JPEG with quality 85
+  dms screenshot all -f webp         # Small lossless multi-monitor capture
+  dms screenshot --palette auto      # 8-bit PNG when lossless (UI shots)
+  dms screenshot history             # Recent captures
+  dms screenshot full --delay 5      # Countdown, then capture
+  dms screenshot last --interval 30s # Time-lapse of the last region
+  dms screenshot script flow.json    # Named regions/outputs in one pass
+  dms screenshot decode              # Read a QR code or barcode`,
 }
 
 var ssRegionCmd = &cobra.Command{
@@ -112,7 +139,7 @@ var notifyActionCmd = &cobra.Command{
 func init() {
 	screenshotCmd.PersistentFlags().StringVarP(&ssOutputName, "output", "o", "", "Output name for 'output' mode")
 	screenshotCmd.PersistentFlags().StringVar(&ssCursor, "cursor", "off", "Include cursor in screenshot (on/off)")
-	screenshotCmd.PersistentFlags().StringVarP(&ssFormat, "format", "f", "png", "Output format (png, jpg, ppm)")
+	screenshotCmd.PersistentFlags().StringVarP(&ssFormat, "format", "f", "png", "Output format (png, jpg, ppm, webp, qoi)")
 	screenshotCmd.PersistentFlags().IntVarP(&ssQuality, "quality", "q", 90, "JPEG quality (1-100)")
 	screenshotCmd.PersistentFlags().StringVarP(&ssOutputDir, "dir", "d", "", "Output directory")
 	screenshotCmd.PersistentFlags().StringVar(&ssFilename, "filename", "", "Output filename (auto-generated if empty)")
@@ -120,6 +147,15 @@ func init() {
 	screenshotCmd.PersistentFlags().BoolVar(&ssNoFile, "no-file", false, "Don't save to file")
 	screenshotCmd.PersistentFlags().BoolVar(&ssNoNotify, "no-notify", false, "Don't show notification")
 	screenshotCmd.PersistentFlags().BoolVar(&ssStdout, "stdout", false, "Output image to stdout (for piping to swappy, etc.)")
+	screenshotCmd.PersistentFlags().BoolVarP(&ssAnnotate, "annotate", "a", false, "Annotate the selected region before saving (region mode)")
+	screenshotCmd.PersistentFlags().BoolVar(&ssNoHistory, "no-history", false, "Don't add the capture to the screenshot history")
+	screenshotCmd.PersistentFlags().StringVar(&ssDelay, "delay", "", "Wait before capturing, in seconds or as a duration (e.g. 5, 1m)")
+	screenshotCmd.PersistentFlags().BoolVar(&ssNoCountdown, "no-countdown", false, "Don't show the on-screen countdown during --delay")
+	screenshotCmd.PersistentFlags().StringVar(&ssInterval, "interval", "", "Capture repeatedly at this interval until stopped (time-lapse)")
+	screenshotCmd.PersistentFlags().IntVar(&ssCount, "count", 0, "Stop after this many --interval captures (0 for no limit)")
+	screenshotCmd.PersistentFlags().StringVar(&ssCompression, "png-compression", "fast", "PNG compression level (fast, default, best, none)")
+	screenshotCmd.PersistentFlags().StringVar(&ssPalette, "palette", "off", "Write 8-bit palette PNGs: off, auto (only when lossless), on (dither)")
+	screenshotCmd.PersistentFlags().BoolVar(&ssNoMetadata, "no-metadata", false, "Don't embed capture metadata in PNG and WebP files")
 
 	screenshotCmd.AddCommand(ssRegionCmd)
 	screenshotCmd.AddCommand(ssFullCmd)
@@ -143,6 +179,17 @@ func getScreenshotConfig(mode screenshot.Mode) screenshot.Config {
 	config.SaveFile = !ssNoFile
 	config.Notify = !ssNoNotify
 	config.Stdout = ssStdout
+	config.Annotate = ssAnnotate
+	config.History = !ssNoHistory
+	config.Countdown = !ssNoCountdown
+	if ssDelay != "" {
+		delay, err := parseSeconds(ssDelay)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --delay: %v\n", err)
+			os.Exit(1)
+		}
+		config.Delay = delay
+	}
 
 	if ssOutputDir != "" {
 		config.OutputDir = ssOutputDir
@@ -151,14 +198,22 @@ func getScreenshotConfig(mode screenshot.Mode) screenshot.Config {
 		config.Filename = ssFilename
 	}
 
-	switch strings.ToLower(ssFormat) {
-	case "jpg", "jpeg":
-		config.Format = screenshot.FormatJPEG
-	case "ppm":
-		config.Format = screenshot.FormatPPM
-	default:
-		config.Format = screenshot.FormatPNG
+	format, err := screenshot.ParseFormat(ssFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	config.Format = format
+
+	if config.Compression, err = screenshot.ParsePNGCompression(ssCompression); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if config.Palette, err = screenshot.ParsePaletteMode(ssPalette); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
+	config.Metadata = !ssNoMetadata
 
 	if ssQuality < 1 {
 		ssQuality = 1
@@ -172,6 +227,11 @@ func getScreenshotConfig(mode screenshot.Mode) screenshot.Config {
 }
 
 func runScreenshot(config screenshot.Config) {
+	if ssInterval != "" {
+		runScreenshotInterval(config)
+		return
+	}
+
 	sc := screenshot.New(config)
 	result, err := sc.Run()
 	if err != nil {
@@ -190,7 +250,7 @@ func runScreenshot(config screenshot.Config) {
 	}
 
 	if config.Stdout {
-		if err := writeImageToStdout(result.Buffer, config.Format, config.Quality, result.Format); err != nil {
+		if err := writeImageToStdout(result.Buffer, config.EncodeOptions(result), result.Format); err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing to stdout: %v\n", err)
 			os.Exit(1)
 		}
@@ -211,7 +271,7 @@ func runScreenshot(config screenshot.Config) {
 		}
 
 		filePath = filepath.Join(outputDir, filename)
-		if err := screenshot.WriteToFileWithFormat(result.Buffer, filePath, config.Format, config.Quality, result.Format); err != nil {
+		if err := screenshot.WriteToFileWithOptions(result.Buffer, filePath, result.Format, config.EncodeOptions(result)); err != nil {
 			fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
 			os.Exit(1)
 		}
@@ -228,6 +288,10 @@ func runScreenshot(config screenshot.Config) {
 		}
 	}
 
+	if config.History {
+		addToScreenshotHistory(config, result, filePath)
+	}
+
 	if config.Notify {
 		thumbData, thumbW, thumbH := bufferToRGBThumbnail(result.Buffer, 256, result.Format)
 		screenshot.SendNotification(screenshot.NotifyResult{
@@ -240,6 +304,101 @@ func runScreenshot(config screenshot.Config) {
 	}
 }
 
+// runScreenshotInterval saves numbered captures until --count is reached or
+// the command is interrupted. Clipboard, history and per-capture
+// notifications are skipped; a single notification reports the run.
+func runScreenshotInterval(config screenshot.Config) {
+	every, err := parseSeconds(ssInterval)
+	if err != nil || every <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid --interval: %s\n", ssInterval)
+		os.Exit(1)
+	}
+	if config.Stdout || !config.SaveFile {
+		fmt.Fprintln(os.Stderr, "Error: --interval saves files and cannot be combined with --stdout or --no-file")
+		os.Exit(1)
+	}
+
+	outputDir := config.OutputDir
+	if outputDir == "" {
+		outputDir = screenshot.GetOutputDir()
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	stop := make(chan struct{})
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	go func() {
+		<-sigs
+		close(stop)
+	}()
+
+	start := time.Now()
+	var lastPath string
+	taken, err := screenshot.RunInterval(config, every, ssCount, stop, func(n int, result *screenshot.CaptureResult) error {
+		defer result.Buffer.Close()
+		if result.YInverted {
+			result.Buffer.FlipVertical()
+		}
+
+		path := filepath.Join(outputDir, screenshot.GenerateSequenceFilename(config.Format, start, n))
+		if err := screenshot.WriteToFileWithOptions(result.Buffer, path, result.Format, config.EncodeOptions(result)); err != nil {
+			return err
+		}
+		fmt.Println(path)
+		lastPath = path
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if config.Notify && taken > 0 {
+		screenshot.SendNotification(screenshot.NotifyResult{
+			Summary:  fmt.Sprintf("Time-lapse saved (%d captures)", taken),
+			FilePath: lastPath,
+		})
+	}
+}
+
+// parseSeconds accepts a plain number of seconds or a Go duration.
+func parseSeconds(s string) (time.Duration, error) {
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		if secs < 0 {
+			return 0, fmt.Errorf("must not be negative")
+		}
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err == nil && d < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	return d, err
+}
+
+func addToScreenshotHistory(config screenshot.Config, result *screenshot.CaptureResult, filePath string) {
+	output := result.Region.Output
+	if output == "" {
+		output = config.OutputName
+	}
+
+	img := screenshot.BufferToImageWithFormat(result.Buffer, result.Format)
+	_, err := screenshot.DefaultHistory().Add(screenshot.HistoryEntry{
+		Path:   filePath,
+		Mode:   config.Mode.String(),
+		Output: output,
+		Region: result.Region,
+		Copied: config.Clipboard,
+	}, img)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to add screenshot to history: %v\n", err)
+	}
+}
+
 func copyImageToClipboard(buf *screenshot.ShmBuffer, format screenshot.Format, quality int, pixelFormat uint32) error {
 	var mimeType string
 	var data bytes.Buffer
@@ -262,15 +421,13 @@ func copyImageToClipboard(buf *screenshot.ShmBuffer, format screenshot.Format, q
 	return clipboard.Copy(data.Bytes(), mimeType)
 }
 
-func writeImageToStdout(buf *screenshot.ShmBuffer, format screenshot.Format, quality int, pixelFormat uint32) error {
+func writeImageToStdout(buf *screenshot.ShmBuffer, opts screenshot.EncodeOptions, pixelFormat uint32) error {
 	img := screenshot.BufferToImageWithFormat(buf, pixelFormat)
-
-	switch format {
-	case screenshot.FormatJPEG:
-		return screenshot.EncodeJPEG(os.Stdout, img, quality)
-	default:
-		return screenshot.EncodePNG(os.Stdout, img)
+	w := bufio.NewWriter(os.Stdout)
+	if err := screenshot.Encode(w, img, opts); err != nil {
+		return err
 	}
+	return w.Flush()
 }
 
 func bufferToRGBThumbnail(buf *screenshot.ShmBuffer, maxSize int, pixelFormat uint32) ([]byte, int, int) {
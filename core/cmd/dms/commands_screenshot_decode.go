@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/barcode"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	"github.com/spf13/cobra"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ssDecodeFile string
+	ssDecodeJSON bool
+	ssDecodeJoin bool
+)
+
+var ssDecodeCmd = &cobra.Command{
+	Use:   "decode [mode]",
+	Short: "Read QR codes and barcodes from the screen",
+	Long: `Capture the screen and decode the QR codes and barcodes in it. The mode is
+the same as for a screenshot (region by default); --file decodes an image
+instead.
+
+Supported symbols: QR codes, EAN-13, EAN-8, UPC-A and Code 128.
+
+The decoded text is printed and copied to the clipboard. When a QR code
+holds a Wi-Fi network (WIFI:...), dms offers to join it: on a terminal it
+asks, otherwise it shows a notification with a Join action. --join connects
+without asking.
+
+Examples:
+  dms screenshot decode               # Select a region around a code
+  dms screenshot decode full          # Decode everything on the focused output
+  dms screenshot decode --join        # Join the Wi-Fi network in the code
+  dms screenshot decode --file qr.png # Decode an image file
+  dms screenshot decode --json        # Format, text and Wi-Fi details as JSON`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runScreenshotDecode,
+}
+
+func init() {
+	ssDecodeCmd.Flags().StringVar(&ssDecodeFile, "file", "", "Decode an image file instead of capturing")
+	ssDecodeCmd.Flags().BoolVar(&ssDecodeJSON, "json", false, "Print the results as JSON")
+	ssDecodeCmd.Flags().BoolVar(&ssDecodeJoin, "join", false, "Join a scanned Wi-Fi network without asking")
+	screenshotCmd.AddCommand(ssDecodeCmd)
+}
+
+type decodeOutput struct {
+	Format barcode.Format `json:"format"`
+	Text   string         `json:"text"`
+	WiFi   *barcode.WiFi  `json:"wifi,omitempty"`
+}
+
+func runScreenshotDecode(cmd *cobra.Command, args []string) {
+	img, err := decodeSource(args)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if img == nil {
+		os.Exit(0)
+	}
+
+	results, err := barcode.Decode(img)
+	switch {
+	case errors.Is(err, barcode.ErrNotFound):
+		if !ssNoNotify && ssDecodeFile == "" {
+			screenshot.SendNotification(screenshot.NotifyResult{Summary: "No QR code or barcode found"})
+		}
+		fmt.Fprintln(os.Stderr, "No QR code or barcode found")
+		os.Exit(1)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	outputs := make([]decodeOutput, len(results))
+	texts := make([]string, len(results))
+	for i, r := range results {
+		outputs[i] = decodeOutput{Format: r.Format, Text: r.Text}
+		if r.Format == barcode.FormatQR && barcode.IsWiFi(r.Text) {
+			outputs[i].WiFi, _ = barcode.ParseWiFi(r.Text)
+		}
+		texts[i] = r.Text
+	}
+
+	if ssDecodeJSON {
+		data, _ := json.MarshalIndent(outputs, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		for _, o := range outputs {
+			fmt.Println(o.Text)
+		}
+	}
+
+	var wifi *barcode.WiFi
+	for _, o := range outputs {
+		if o.WiFi != nil {
+			wifi = o.WiFi
+			break
+		}
+	}
+
+	// a Wi-Fi payload is not useful on the clipboard, the password is
+	if wifi == nil && !ssNoClipboard {
+		if err := clipboard.CopyText(strings.Join(texts, "\n")); err != nil {
+			fmt.Fprintf(os.Stderr, "Error copying to clipboard: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	switch {
+	case wifi != nil:
+		offerWiFiJoin(wifi)
+	case !ssNoNotify && ssDecodeFile == "":
+		summary := "Code copied to clipboard"
+		if ssNoClipboard {
+			summary = "Code decoded"
+		}
+		screenshot.SendNotification(screenshot.NotifyResult{Summary: summary})
+	}
+}
+
+// decodeSource loads --file or captures the requested mode. A nil image
+// means the selection was cancelled.
+func decodeSource(args []string) (image.Image, error) {
+	if ssDecodeFile != "" {
+		path, err := utils.ExpandPath(ssDecodeFile)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		img, _, err := image.Decode(f)
+		return img, err
+	}
+
+	mode := screenshot.ModeRegion
+	if len(args) > 0 {
+		var err error
+		if mode, err = screenshot.ParseMode(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	if mode == screenshot.ModeOutput && ssOutputName == "" {
+		return nil, fmt.Errorf("output name required (use -o)")
+	}
+
+	config := getScreenshotConfig(mode)
+	config.Annotate = false
+	result, err := screenshot.New(config).Run()
+	if err != nil || result == nil {
+		return nil, err
+	}
+	defer result.Buffer.Close()
+
+	if result.YInverted {
+		result.Buffer.FlipVertical()
+	}
+	return screenshot.BufferToImageWithFormat(result.Buffer, result.Format), nil
+}
+
+func offerWiFiJoin(wifi *barcode.WiFi) {
+	join := ssDecodeJoin
+	if !join && !ssDecodeJSON {
+		if stat, err := os.Stdin.Stat(); err == nil && stat.Mode()&os.ModeCharDevice != 0 {
+			fmt.Printf("Join Wi-Fi network %q? [y/N] ", wifi.SSID)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			join = answer == "y" || answer == "yes"
+		} else if !ssNoNotify {
+			action, err := screenshot.NotifyAction(
+				"Wi-Fi network found",
+				fmt.Sprintf("Join %s?", wifi.SSID),
+				[]string{"default", "Join"},
+				30*time.Second,
+			)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			join = action != ""
+		}
+	}
+	if !join {
+		return
+	}
+
+	if err := connectWiFi(wifi); err != nil {
+		if !ssNoNotify {
+			screenshot.SendNotification(screenshot.NotifyResult{Summary: "Failed to join " + wifi.SSID})
+		}
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Connecting to %s\n", wifi.SSID)
+}
+
+func connectWiFi(wifi *barcode.WiFi) error {
+	params := map[string]any{
+		"ssid":        wifi.SSID,
+		"hidden":      wifi.Hidden,
+		"interactive": false,
+	}
+	if wifi.Password != "" {
+		params["password"] = wifi.Password
+	}
+	if wifi.EAPMethod != "" {
+		params["eapMethod"] = strings.ToLower(wifi.EAPMethod)
+		params["username"] = wifi.Identity
+		params["anonymousIdentity"] = wifi.Anonymous
+		params["phase2Auth"] = strings.ToLower(wifi.Phase2)
+	}
+
+	resp, err := sendServerRequest(models.Request{
+		ID:     1,
+		Method: "network.wifi.connect",
+		Params: params,
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
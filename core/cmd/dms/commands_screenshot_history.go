@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ssHistoryJSON       bool
+	ssHistoryLimit      int
+	ssHistoryDeleteFile bool
+	ssPruneOlderThan    string
+	ssPruneMaxSize      string
+	ssPruneDeleteFiles  bool
+)
+
+var ssHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse recent captures",
+	Long: `List recent screenshots, newest first. Every capture is recorded with its
+path, mode, output, region and whether it was copied. Captures that were only
+copied to the clipboard keep a copy in the history so they can be re-copied.
+
+Entries are referred to by id prefix; commands that take an optional id
+default to the latest capture.
+
+Examples:
+  dms screenshot history                        # List recent captures
+  dms screenshot history copy                   # Copy the latest capture again
+  dms screenshot history open 3fa2              # Open a capture by id
+  dms screenshot history delete 3fa2 --file     # Forget a capture and delete its file
+  dms screenshot history prune --older-than 30d --max-size 500M`,
+	Args: cobra.NoArgs,
+	Run:  runScreenshotHistory,
+}
+
+var ssHistoryCopyCmd = &cobra.Command{
+	Use:   "copy [id]",
+	Short: "Copy a capture to the clipboard again",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runScreenshotHistoryCopy,
+}
+
+var ssHistoryOpenCmd = &cobra.Command{
+	Use:   "open [id]",
+	Short: "Open a capture with the default image viewer",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runScreenshotHistoryOpen,
+}
+
+var ssHistoryDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Remove a capture from the history",
+	Args:  cobra.ExactArgs(1),
+	Run:   runScreenshotHistoryDelete,
+}
+
+var ssHistoryPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Drop old captures from the history",
+	Long: `Drop captures whose file no longer exists, captures older than --older-than
+and then the oldest captures until the total size fits --max-size.
+Screenshot files are kept unless --files is given.`,
+	Args: cobra.NoArgs,
+	Run:  runScreenshotHistoryPrune,
+}
+
+func init() {
+	ssHistoryCmd.Flags().BoolVar(&ssHistoryJSON, "json", false, "Output as JSON")
+	ssHistoryCmd.Flags().IntVarP(&ssHistoryLimit, "limit", "n", 20, "Number of captures to show (0 for all)")
+	ssHistoryDeleteCmd.Flags().BoolVar(&ssHistoryDeleteFile, "file", false, "Also delete the screenshot file")
+	ssHistoryPruneCmd.Flags().StringVar(&ssPruneOlderThan, "older-than", "", "Drop captures older than this (e.g. 12h, 30d, 2w)")
+	ssHistoryPruneCmd.Flags().StringVar(&ssPruneMaxSize, "max-size", "", "Keep the total size below this (e.g. 500M, 2G)")
+	ssHistoryPruneCmd.Flags().BoolVar(&ssPruneDeleteFiles, "files", false, "Also delete the screenshot files")
+
+	ssHistoryCmd.AddCommand(ssHistoryCopyCmd, ssHistoryOpenCmd, ssHistoryDeleteCmd, ssHistoryPruneCmd)
+	screenshotCmd.AddCommand(ssHistoryCmd)
+}
+
+func runScreenshotHistory(cmd *cobra.Command, args []string) {
+	entries, err := screenshot.DefaultHistory().List()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if ssHistoryLimit > 0 && len(entries) > ssHistoryLimit {
+		entries = entries[:ssHistoryLimit]
+	}
+
+	if ssHistoryJSON {
+		if entries == nil {
+			entries = []screenshot.HistoryEntry{}
+		}
+		out, _ := json.MarshalIndent(entries, "", "  ")
+		fmt.Println(string(out))
+		return
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No screenshots in history")
+		return
+	}
+
+	for _, e := range entries {
+		flags := ""
+		if e.Copied {
+			flags += " copied"
+		}
+		if e.Missing {
+			flags += " missing"
+		}
+		fmt.Printf("%s  %s  %-6s %4dx%-4d %8s  %s%s\n",
+			e.ID, e.Time.Local().Format("2006-01-02 15:04:05"), e.Mode,
+			e.Width, e.Height, formatByteSize(e.Size), e.Path, flags)
+	}
+}
+
+func historyRef(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return ""
+}
+
+func runScreenshotHistoryCopy(cmd *cobra.Command, args []string) {
+	history := screenshot.DefaultHistory()
+	entry, data, mimeType, err := history.ReadImage(historyRef(args))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := clipboard.Copy(data, mimeType); err != nil {
+		fmt.Fprintf(os.Stderr, "Error copying to clipboard: %v\n", err)
+		os.Exit(1)
+	}
+	if err := history.MarkCopied(entry.ID); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	fmt.Println("Copied to clipboard")
+}
+
+func runScreenshotHistoryOpen(cmd *cobra.Command, args []string) {
+	entry, err := screenshot.DefaultHistory().Get(historyRef(args))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if entry.Missing {
+		fmt.Fprintf(os.Stderr, "Error: %s no longer exists\n", entry.Path)
+		os.Exit(1)
+	}
+	screenshot.OpenFile(entry.Path)
+}
+
+func runScreenshotHistoryDelete(cmd *cobra.Command, args []string) {
+	entry, err := screenshot.DefaultHistory().Delete(args[0], ssHistoryDeleteFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Removed %s (%s)\n", entry.ID, entry.Path)
+}
+
+func runScreenshotHistoryPrune(cmd *cobra.Command, args []string) {
+	var opts screenshot.PruneOptions
+	var err error
+
+	if ssPruneOlderThan != "" {
+		if opts.MaxAge, err = parseAge(ssPruneOlderThan); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --older-than: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if ssPruneMaxSize != "" {
+		if opts.MaxSize, err = parseByteSize(ssPruneMaxSize); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --max-size: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	opts.DeleteFiles = ssPruneDeleteFiles
+
+	removed, err := screenshot.DefaultHistory().Prune(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var freed int64
+	for _, e := range removed {
+		if e.Stored || opts.DeleteFiles {
+			freed += e.Size
+		}
+	}
+	fmt.Printf("Pruned %d captures, freed %s\n", len(removed), formatByteSize(freed))
+}
+
+// parseAge extends time.ParseDuration with d (days) and w (weeks).
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, err
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+func parseByteSize(s string) (int64, error) {
+	units := map[string]int64{"K": 1 << 10, "M": 1 << 20, "G": 1 << 30}
+	s = strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+
+	mult := int64(1)
+	if n := len(s); n > 0 {
+		if u, ok := units[s[n-1:]]; ok {
+			mult = u
+			s = s[:n-1]
+		}
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("not a size: %q", s)
+	}
+	return int64(v * float64(mult)), nil
+}
+
+func formatByteSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fG", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fM", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fK", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var ssScriptJSON bool
+
+var ssScriptCmd = &cobra.Command{
+	Use:   "script <file|->",
+	Short: "Capture a list of named regions and outputs in one pass",
+	Long: `Capture every entry of a JSON script over a single compositor connection and
+save each one as <name>.<format> in the output directory.
+
+Each capture has a name and a mode (region, output, full, all, window). The
+mode can be left out when a region or output is given. Regions are in global
+layout coordinates, or relative to the output when both are given. The script
+can set a delay in seconds (shown as a countdown) and an output directory;
+--delay and -d override them.
+
+  {
+    "delay": 3,
+    "dir": "~/qa/login-flow",
+    "captures": [
+      {"name": "toolbar", "region": {"x": 0, "y": 0, "width": 1920, "height": 48}},
+      {"name": "sidebar", "output": "DP-2", "region": {"x": 0, "y": 48, "width": 320, "height": 1032}},
+      {"name": "second-screen", "output": "HDMI-A-1"},
+      {"name": "focused", "mode": "window"}
+    ]
+  }
+
+A bare list of captures is accepted too. Use - to read the script from stdin.`,
+	Args: cobra.ExactArgs(1),
+	Run:  runScreenshotScript,
+}
+
+func init() {
+	ssScriptCmd.Flags().BoolVar(&ssScriptJSON, "json", false, "Print the results as JSON")
+	screenshotCmd.AddCommand(ssScriptCmd)
+}
+
+type scriptOutput struct {
+	Name  string `json:"name"`
+	Path  string `json:"path,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func runScreenshotScript(cmd *cobra.Command, args []string) {
+	var data []byte
+	var err error
+	if args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	script, err := screenshot.ParseScript(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	config := getScreenshotConfig(screenshot.ModeRegion)
+	if ssDelay == "" {
+		config.Delay = script.DelayDuration()
+	}
+	script.Delay = config.Delay.Seconds()
+
+	outputDir := config.OutputDir
+	if outputDir == "" && script.Dir != "" {
+		if outputDir, err = utils.ExpandPath(script.Dir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if outputDir == "" {
+		outputDir = screenshot.GetOutputDir()
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	results, err := screenshot.New(config).RunScript(script)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	outputs := make([]scriptOutput, 0, len(results))
+	failed := 0
+	for i, r := range results {
+		out := scriptOutput{Name: r.Name}
+		if r.Err == nil {
+			captureConfig := config
+			captureConfig.Mode, _ = screenshot.ParseMode(script.Captures[i].Mode)
+			out.Path, r.Err = saveScriptCapture(captureConfig, r, outputDir)
+		}
+		if r.Err != nil {
+			out.Error = r.Err.Error()
+			failed++
+		}
+		outputs = append(outputs, out)
+	}
+
+	if ssScriptJSON {
+		encoded, _ := json.MarshalIndent(outputs, "", "  ")
+		fmt.Println(string(encoded))
+	} else {
+		for _, out := range outputs {
+			if out.Error != "" {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", out.Name, out.Error)
+				continue
+			}
+			fmt.Printf("%s: %s\n", out.Name, out.Path)
+		}
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func saveScriptCapture(config screenshot.Config, r screenshot.ScriptResult, outputDir string) (string, error) {
+	defer r.Result.Buffer.Close()
+	if r.Result.YInverted {
+		r.Result.Buffer.FlipVertical()
+	}
+
+	path := filepath.Join(outputDir, r.Name+"."+config.Format.Extension())
+	if err := screenshot.WriteToFileWithOptions(r.Result.Buffer, path, r.Result.Format, config.EncodeOptions(r.Result)); err != nil {
+		return "", err
+	}
+	if config.History {
+		addToScreenshotHistory(config, r.Result, path)
+	}
+	return path, nil
+}
@@ -9,8 +9,8 @@ import (
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/config"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/deps"
-	"github.com/AvengeMedia/DankMaterialShell/core/internal/greeter"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
 	"github.com/spf13/cobra"
 )
@@ -96,60 +96,81 @@ var setupWindowrulesCmd = &cobra.Command{
 	},
 }
 
-type dmsConfigSpec struct {
-	niriFile    string
-	hyprFile    string
-	niriContent func(terminal string) string
-	hyprContent func(terminal string) string
+type dmsConfigFile struct {
+	name    string
+	content func(terminal string) string
 }
 
-var dmsConfigSpecs = map[string]dmsConfigSpec{
+func withTerminal(template string) func(string) string {
+	return func(t string) string {
+		return strings.ReplaceAll(template, "{{TERMINAL_COMMAND}}", t)
+	}
+}
+
+func static(content string) func(string) string {
+	return func(_ string) string { return content }
+}
+
+// dmsConfigSpecs lists, per dms config, the file deployed for each compositor
+// that supports it.
+var dmsConfigSpecs = map[string]map[string]dmsConfigFile{
 	"binds": {
-		niriFile: "binds.kdl",
-		hyprFile: "binds.conf",
-		niriContent: func(t string) string {
-			return strings.ReplaceAll(config.NiriBindsConfig, "{{TERMINAL_COMMAND}}", t)
-		},
-		hyprContent: func(t string) string {
-			return strings.ReplaceAll(config.HyprBindsConfig, "{{TERMINAL_COMMAND}}", t)
-		},
+		"niri":     {"binds.kdl", withTerminal(config.NiriBindsConfig)},
+		"hyprland": {"binds.conf", withTerminal(config.HyprBindsConfig)},
+		"sway":     {"binds.conf", withTerminal(config.SwayBindsConfig)},
+		"mangowc":  {"binds.conf", withTerminal(config.MangoWCBindsConfig)},
+		"miracle":  {"binds.yaml", withTerminal(config.MiracleBindsConfig)},
 	},
 	"layout": {
-		niriFile:    "layout.kdl",
-		hyprFile:    "layout.conf",
-		niriContent: func(_ string) string { return config.NiriLayoutConfig },
-		hyprContent: func(_ string) string { return config.HyprLayoutConfig },
+		"niri":     {"layout.kdl", static(config.NiriLayoutConfig)},
+		"hyprland": {"layout.conf", static(config.HyprLayoutConfig)},
+		"sway":     {"layout.conf", static(config.SwayLayoutConfig)},
+		"mangowc":  {"layout.conf", static(config.MangoWCLayoutConfig)},
+		"miracle":  {"layout.yaml", static(config.MiracleLayoutConfig)},
 	},
 	"colors": {
-		niriFile:    "colors.kdl",
-		hyprFile:    "colors.conf",
-		niriContent: func(_ string) string { return config.NiriColorsConfig },
-		hyprContent: func(_ string) string { return config.HyprColorsConfig },
+		"niri":     {"colors.kdl", static(config.NiriColorsConfig)},
+		"hyprland": {"colors.conf", static(config.HyprColorsConfig)},
+		"sway":     {"colors.conf", static(config.SwayColorsConfig)},
+		"mangowc":  {"colors.conf", static(config.MangoWCColorsConfig)},
+		"miracle":  {"colors.yaml", static(config.MiracleColorsConfig)},
 	},
 	"alttab": {
-		niriFile:    "alttab.kdl",
-		niriContent: func(_ string) string { return config.NiriAlttabConfig },
+		"niri": {"alttab.kdl", static(config.NiriAlttabConfig)},
 	},
 	"outputs": {
-		niriFile:    "outputs.kdl",
-		hyprFile:    "outputs.conf",
-		niriContent: func(_ string) string { return "" },
-		hyprContent: func(_ string) string { return "" },
+		"niri":     {"outputs.kdl", static("")},
+		"hyprland": {"outputs.conf", static("")},
+		"sway":     {"outputs.conf", static("")},
+		"mangowc":  {"outputs.conf", static("")},
 	},
 	"cursor": {
-		niriFile:    "cursor.kdl",
-		hyprFile:    "cursor.conf",
-		niriContent: func(_ string) string { return "" },
-		hyprContent: func(_ string) string { return "" },
+		"niri":     {"cursor.kdl", static("")},
+		"hyprland": {"cursor.conf", static("")},
+		"sway":     {"cursor.conf", static("")},
+		"mangowc":  {"cursor.conf", static("")},
 	},
 	"windowrules": {
-		niriFile:    "windowrules.kdl",
-		hyprFile:    "windowrules.conf",
-		niriContent: func(_ string) string { return "" },
-		hyprContent: func(_ string) string { return "" },
+		"niri":     {"windowrules.kdl", static("")},
+		"hyprland": {"windowrules.conf", static("")},
+		"sway":     {"windowrules.conf", static("")},
+		"mangowc":  {"windowrules.conf", static("")},
 	},
 }
 
+// setupCompositors are the compositors dms setup can deploy single configs for.
+var setupCompositors = []struct {
+	name      string
+	command   string
+	configDir string
+}{
+	{"niri", "niri", "niri"},
+	{"hyprland", "Hyprland", "hypr"},
+	{"sway", "sway", "sway"},
+	{"mangowc", "mango", "mango"},
+	{"miracle", "miracle-wm", "miracle-wm"},
+}
+
 func detectTerminal() (string, error) {
 	terminals := []string{"ghostty", "foot", "kitty", "alacritty"}
 	var found []string
@@ -185,20 +206,36 @@ func detectTerminal() (string, error) {
 }
 
 func detectCompositorForSetup() (string, error) {
-	compositors := greeter.DetectCompositors()
+	var found []string
+	for _, c := range setupCompositors {
+		if utils.CommandExists(c.command) {
+			found = append(found, c.name)
+		}
+	}
 
-	switch len(compositors) {
+	switch len(found) {
 	case 0:
-		return "", fmt.Errorf("no supported compositors found (niri or Hyprland required)")
+		return "", fmt.Errorf("no supported compositors found (niri, Hyprland, sway, MangoWC or miracle-wm required)")
 	case 1:
-		return strings.ToLower(compositors[0]), nil
+		return found[0], nil
 	}
 
-	selected, err := greeter.PromptCompositorChoice(compositors)
-	if err != nil {
-		return "", err
+	fmt.Println("Multiple compositors detected:")
+	for i, c := range found {
+		fmt.Printf("%d) %s\n", i+1, c)
 	}
-	return strings.ToLower(selected), nil
+	fmt.Printf("\nChoice (1-%d): ", len(found))
+
+	var response string
+	fmt.Scanln(&response)
+	response = strings.TrimSpace(response)
+
+	choice := 0
+	fmt.Sscanf(response, "%d", &choice)
+	if choice < 1 || choice > len(found) {
+		return "", fmt.Errorf("invalid choice")
+	}
+	return found[choice-1], nil
 }
 
 func runSetupDmsConfig(name string) error {
@@ -212,51 +249,38 @@ func runSetupDmsConfig(name string) error {
 		return err
 	}
 
-	var filename string
-	var contentFn func(string) string
-	switch compositor {
-	case "niri":
-		filename = spec.niriFile
-		contentFn = spec.niriContent
-	case "hyprland":
-		filename = spec.hyprFile
-		contentFn = spec.hyprContent
-	default:
-		return fmt.Errorf("unsupported compositor: %s", compositor)
-	}
-
-	if filename == "" {
+	file, ok := spec[compositor]
+	if !ok {
 		return fmt.Errorf("%s is not supported for %s", name, compositor)
 	}
 
 	var dmsDir string
-	switch compositor {
-	case "niri":
-		dmsDir = filepath.Join(os.Getenv("HOME"), ".config", "niri", "dms")
-	case "hyprland":
-		dmsDir = filepath.Join(os.Getenv("HOME"), ".config", "hypr", "dms")
+	for _, c := range setupCompositors {
+		if c.name == compositor {
+			dmsDir = filepath.Join(os.Getenv("HOME"), ".config", c.configDir, "dms")
+		}
 	}
 
 	if err := os.MkdirAll(dmsDir, 0o755); err != nil {
 		return fmt.Errorf("failed to create dms directory: %w", err)
 	}
 
-	path := filepath.Join(dmsDir, filename)
+	path := filepath.Join(dmsDir, file.name)
 	if info, err := os.Stat(path); err == nil && info.Size() > 0 {
 		return fmt.Errorf("%s already exists and is not empty: %s", name, path)
 	}
 
 	terminal := "ghostty"
-	if contentFn != nil && name == "binds" {
+	if name == "binds" {
 		terminal, err = detectTerminal()
 		if err != nil {
 			return err
 		}
 	}
 
-	content := contentFn(terminal)
-	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
-		return fmt.Errorf("failed to write %s: %w", filename, err)
+	content := file.content(terminal)
+	if err := snapshot.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file.name, err)
 	}
 
 	fmt.Printf("Deployed %s to %s\n", name, path)
@@ -268,13 +292,24 @@ func runSetup() error {
 
 	wm, wmSelected := promptCompositor()
 	terminal, terminalSelected := promptTerminal()
-	useSystemd := promptSystemd()
 
 	if !wmSelected && !terminalSelected {
 		fmt.Println("No configurations selected. Exiting.")
 		return nil
 	}
 
+	// Redeploying only the compositor config renders its template with the
+	// choices of the previous deployment, otherwise every section mentioning
+	// the terminal would differ from the merge base.
+	deployTerminal := terminal
+	var useSystemd bool
+	if recordedTerminal, recordedSystemd, ok := config.NewConfigDeployer(nil).DeployedChoices(wm); wmSelected && !terminalSelected && ok {
+		deployTerminal, useSystemd = recordedTerminal, recordedSystemd
+		fmt.Println("\nKeeping the terminal and systemd choice of the previous deployment.")
+	} else {
+		useSystemd = promptSystemd()
+	}
+
 	if wmSelected || terminalSelected {
 		willBackup := checkExistingConfigs(wm, wmSelected, terminal, terminalSelected)
 		if willBackup {
@@ -296,6 +331,14 @@ func runSetup() error {
 	logChan := make(chan string, 100)
 	deployer := config.NewConfigDeployer(logChan)
 
+	if wmSelected {
+		conflicts, err := deployer.PlanMerges(wm, deployTerminal, useSystemd)
+		if err != nil {
+			return fmt.Errorf("failed to compare with deployed template: %w", err)
+		}
+		deployer.SetMergeResolutions(promptMergeConflicts(conflicts))
+	}
+
 	go func() {
 		for msg := range logChan {
 			fmt.Println("  " + msg)
@@ -309,7 +352,7 @@ func runSetup() error {
 	if wmSelected && terminalSelected {
 		results, err = deployer.DeployConfigurationsWithSystemd(ctx, wm, terminal, useSystemd)
 	} else if wmSelected {
-		results, err = deployer.DeployConfigurationsWithSystemd(ctx, wm, deps.TerminalGhostty, useSystemd)
+		results, err = deployer.DeployConfigurationsWithSystemd(ctx, wm, deployTerminal, useSystemd)
 		if len(results) > 1 {
 			results = results[:1]
 		}
@@ -333,20 +376,59 @@ func runSetup() error {
 			if result.BackupPath != "" {
 				fmt.Printf("  Backup: %s\n", result.BackupPath)
 			}
+			if len(result.Conflicts) > 0 {
+				fmt.Printf("  Merged with your edits, %d conflicting section(s)\n", len(result.Conflicts))
+			}
 		}
 	}
 
 	return nil
 }
 
+func promptMergeConflicts(conflicts []config.MergeConflict) map[string]bool {
+	resolutions := make(map[string]bool)
+	if len(conflicts) == 0 {
+		return resolutions
+	}
+
+	fmt.Printf("\n%d section(s) were changed both by you and by the updated template.\n", len(conflicts))
+	for _, c := range conflicts {
+		fmt.Printf("\n--- %s: %s\n", c.Path, c.Section)
+		fmt.Println("Yours:")
+		printIndented(c.Ours)
+		fmt.Println("Template:")
+		printIndented(c.Theirs)
+
+		fmt.Print("Keep (m)ine or take (t)emplate? [m]: ")
+		var response string
+		fmt.Scanln(&response)
+		resolutions[c.ID] = strings.HasPrefix(strings.ToLower(strings.TrimSpace(response)), "t")
+	}
+	return resolutions
+}
+
+func printIndented(text string) {
+	if strings.TrimSpace(text) == "" {
+		fmt.Println("    (removed)")
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		fmt.Println("    " + line)
+	}
+}
+
 func promptCompositor() (deps.WindowManager, bool) {
 	fmt.Println("Select compositor:")
 	fmt.Println("1) Niri")
 	fmt.Println("2) Hyprland")
-	fmt.Println("3) None")
+	fmt.Println("3) labwc")
+	fmt.Println("4) sway")
+	fmt.Println("5) MangoWC")
+	fmt.Println("6) miracle-wm")
+	fmt.Println("7) None")
 
 	var response string
-	fmt.Print("\nChoice (1-3): ")
+	fmt.Print("\nChoice (1-7): ")
 	fmt.Scanln(&response)
 	response = strings.TrimSpace(response)
 
@@ -355,6 +437,14 @@ func promptCompositor() (deps.WindowManager, bool) {
 		return deps.WindowManagerNiri, true
 	case "2":
 		return deps.WindowManagerHyprland, true
+	case "3":
+		return deps.WindowManagerLabwc, true
+	case "4":
+		return deps.WindowManagerSway, true
+	case "5":
+		return deps.WindowManagerMangoWC, true
+	case "6":
+		return deps.WindowManagerMiracle, true
 	default:
 		return deps.WindowManagerNiri, false
 	}
@@ -408,6 +498,10 @@ func checkExistingConfigs(wm deps.WindowManager, wmSelected bool, terminal deps.
 			configPath = filepath.Join(homeDir, ".config", "niri", "config.kdl")
 		case deps.WindowManagerHyprland:
 			configPath = filepath.Join(homeDir, ".config", "hypr", "hyprland.conf")
+		case deps.WindowManagerLabwc:
+			configPath = filepath.Join(homeDir, ".config", "labwc", "rc.xml")
+		default:
+			_, configPath, _ = config.NewConfigDeployer(nil).MainConfig(wm)
 		}
 
 		if _, err := os.Stat(configPath); err == nil {
@@ -8,7 +8,10 @@ import (
 	"time"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/toplevel"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowlist"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowrules"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowrules/providers"
 	"github.com/spf13/cobra"
@@ -26,7 +29,7 @@ var windowrulesListCmd = &cobra.Command{
 	Args:  cobra.MaximumNArgs(1),
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
-			return []string{"niri"}, cobra.ShellCompDirectiveNoFileComp
+			return []string{"niri", "labwc", "sway", "scroll", "mangowc"}, cobra.ShellCompDirectiveNoFileComp
 		}
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	},
@@ -41,7 +44,7 @@ var windowrulesAddCmd = &cobra.Command{
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
 			// ! disabled hyprland return []string{"hyprland", "niri"}, cobra.ShellCompDirectiveNoFileComp
-			return []string{"niri"}, cobra.ShellCompDirectiveNoFileComp
+			return []string{"niri", "labwc", "sway", "scroll", "mangowc"}, cobra.ShellCompDirectiveNoFileComp
 		}
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	},
@@ -55,7 +58,7 @@ var windowrulesUpdateCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(3),
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
-			return []string{"niri"}, cobra.ShellCompDirectiveNoFileComp
+			return []string{"niri", "labwc", "sway", "scroll", "mangowc"}, cobra.ShellCompDirectiveNoFileComp
 		}
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	},
@@ -69,7 +72,7 @@ var windowrulesRemoveCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(2),
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
-			return []string{"niri"}, cobra.ShellCompDirectiveNoFileComp
+			return []string{"niri", "labwc", "sway", "scroll", "mangowc"}, cobra.ShellCompDirectiveNoFileComp
 		}
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	},
@@ -83,13 +86,27 @@ var windowrulesReorderCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(2),
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
-			return []string{"niri"}, cobra.ShellCompDirectiveNoFileComp
+			return []string{"niri", "labwc", "sway", "scroll", "mangowc"}, cobra.ShellCompDirectiveNoFileComp
 		}
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	},
 	Run: runWindowrulesReorder,
 }
 
+var windowrulesWhyCmd = &cobra.Command{
+	Use:   "why <window> [compositor]",
+	Short: "Show which rules apply to an open window",
+	Long:  "List the window rules matching an open window, in the order the compositor applies them. The window can be given as a window id, an app id or part of its title.",
+	Args:  cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 1 {
+			return []string{"niri", "labwc", "sway", "scroll", "mangowc"}, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: runWindowrulesWhy,
+}
+
 func init() {
 	configCmd.AddCommand(windowrulesCmd)
 	windowrulesCmd.AddCommand(windowrulesListCmd)
@@ -97,6 +114,7 @@ func init() {
 	windowrulesCmd.AddCommand(windowrulesUpdateCmd)
 	windowrulesCmd.AddCommand(windowrulesRemoveCmd)
 	windowrulesCmd.AddCommand(windowrulesReorderCmd)
+	windowrulesCmd.AddCommand(windowrulesWhyCmd)
 }
 
 type WindowRulesListResult struct {
@@ -118,6 +136,15 @@ func getCompositor(args []string) string {
 	if os.Getenv("NIRI_SOCKET") != "" {
 		return "niri"
 	}
+	if os.Getenv("LABWC_PID") != "" {
+		return "labwc"
+	}
+	if os.Getenv("SCROLLSOCK") != "" {
+		return "scroll"
+	}
+	if os.Getenv("SWAYSOCK") != "" {
+		return "sway"
+	}
 	// if os.Getenv("HYPRLAND_INSTANCE_SIGNATURE") != "" {
 	// 	return "hyprland"
 	// }
@@ -140,7 +167,7 @@ func writeRuleSuccess(id, path string) {
 func runWindowrulesList(cmd *cobra.Command, args []string) {
 	compositor := getCompositor(args)
 	if compositor == "" {
-		log.Fatalf("Could not detect compositor. Please specify: hyprland or niri")
+		log.Fatalf("Could not detect compositor. Please specify: hyprland, niri, labwc, sway, scroll or mangowc")
 	}
 
 	var result WindowRulesListResult
@@ -219,6 +246,20 @@ func runWindowrulesList(cmd *cobra.Command, args []string) {
 		result.Rules = allRules
 		result.DMSStatus = parseResult.DMSStatus
 
+	case "labwc", "sway", "scroll", "mangowc":
+		provider := getWindowRulesProvider(compositor)
+		if provider == nil {
+			log.Fatalf("Failed to expand %s config path", compositor)
+		}
+
+		ruleSet, err := provider.GetRuleSet()
+		if err != nil {
+			log.Fatalf("Failed to parse %s window rules: %v", compositor, err)
+		}
+
+		result.Rules = ruleSet.Rules
+		result.DMSStatus = ruleSet.DMSStatus
+
 	default:
 		log.Fatalf("Unknown compositor: %s", compositor)
 	}
@@ -314,6 +355,70 @@ func runWindowrulesReorder(cmd *cobra.Command, args []string) {
 	writeRuleSuccess("", provider.GetOverridePath())
 }
 
+func runWindowrulesWhy(cmd *cobra.Command, args []string) {
+	compositor := getCompositor(args[1:])
+	if compositor == "" {
+		log.Fatalf("Could not detect compositor. Please specify: hyprland, niri, labwc, sway, scroll or mangowc")
+	}
+
+	provider := getWindowRulesProvider(compositor)
+	if provider == nil {
+		log.Fatalf("Unknown compositor: %s", compositor)
+	}
+
+	ruleSet, err := provider.GetRuleSet()
+	if err != nil {
+		log.Fatalf("Failed to parse %s window rules: %v", compositor, err)
+	}
+
+	_, windows, err := windowrules.LiveWindows(serverToplevelWindows)
+	if err != nil {
+		log.Fatalf("Failed to list windows: %v", err)
+	}
+
+	matched := windowrules.FindWindows(windows, args[0])
+	if len(matched) == 0 {
+		log.Fatalf("No open window matches %q", args[0])
+	}
+
+	results := make([]*windowrules.WhyResult, 0, len(matched))
+	for _, w := range matched {
+		result, err := windowrules.RulesForWindow(compositor, ruleSet.Rules, windows, w)
+		if err != nil {
+			log.Fatalf("Failed to evaluate rules: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	output, _ := json.Marshal(results)
+	fmt.Fprintln(os.Stdout, string(output))
+}
+
+// serverToplevelWindows asks the running DMS server for its foreign toplevel
+// list, the only window source on labwc and mangowc.
+func serverToplevelWindows() ([]windowlist.Window, error) {
+	resp, err := sendServerRequest(models.Request{ID: 1, Method: "toplevels.getState"})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s", resp.Error)
+	}
+	if resp.Result == nil {
+		return nil, fmt.Errorf("empty toplevel state")
+	}
+
+	data, err := json.Marshal(*resp.Result)
+	if err != nil {
+		return nil, err
+	}
+	var state toplevel.State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid toplevel state: %w", err)
+	}
+	return state.Windows(), nil
+}
+
 func getWindowRulesProvider(compositor string) windowrules.WritableProvider {
 	switch compositor {
 	case "niri":
@@ -328,6 +433,24 @@ func getWindowRulesProvider(compositor string) windowrules.WritableProvider {
 			return nil
 		}
 		return providers.NewHyprlandWritableProvider(configDir)
+	case "labwc":
+		configDir, err := utils.ExpandPath("$HOME/.config/labwc")
+		if err != nil {
+			return nil
+		}
+		return providers.NewLabwcWritableProvider(configDir)
+	case "sway", "scroll":
+		configDir, err := utils.ExpandPath("$HOME/.config/" + compositor)
+		if err != nil {
+			return nil
+		}
+		return providers.NewSwayWritableProvider(configDir)
+	case "mangowc":
+		configDir, err := utils.ExpandPath("$HOME/.config/mango")
+		if err != nil {
+			return nil
+		}
+		return providers.NewMangoWCWritableProvider(configDir)
 	default:
 		return nil
 	}
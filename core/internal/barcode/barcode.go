@@ -0,0 +1,70 @@
+package barcode
+
+import (
+	"errors"
+	"image"
+	"sort"
+)
+
+type Format string
+
+const (
+	FormatQR      Format = "qr"
+	FormatEAN13   Format = "ean13"
+	FormatEAN8    Format = "ean8"
+	FormatUPCA    Format = "upca"
+	FormatCode128 Format = "code128"
+)
+
+type Result struct {
+	Format Format          `json:"format"`
+	Text   string          `json:"text"`
+	Bounds image.Rectangle `json:"-"`
+}
+
+var ErrNotFound = errors.New("no barcode found")
+
+// Decode finds and decodes every QR code and 1D barcode in img, ordered top
+// to bottom and left to right. Light-on-dark codes are tried when nothing
+// is found in the normal polarity.
+func Decode(img image.Image) ([]Result, error) {
+	bm := binarize(img)
+
+	results := decodeBitmap(bm)
+	if len(results) == 0 {
+		bm.invert()
+		results = decodeBitmap(bm)
+	}
+	if len(results) == 0 {
+		return nil, ErrNotFound
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i].Bounds.Min, results[j].Bounds.Min
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.X < b.X
+	})
+	return results, nil
+}
+
+func decodeBitmap(bm *bitmap) []Result {
+	var results []Result
+	seen := make(map[Result]bool)
+	add := func(r Result) {
+		key := Result{Format: r.Format, Text: r.Text}
+		if !seen[key] {
+			seen[key] = true
+			results = append(results, r)
+		}
+	}
+
+	for _, r := range decodeQR(bm) {
+		add(r)
+	}
+	for _, r := range decodeLinear(bm) {
+		add(r)
+	}
+	return results
+}
@@ -0,0 +1,121 @@
+package barcode
+
+import (
+	"image"
+)
+
+// bitmap is a binarized image; dark pixels are true.
+type bitmap struct {
+	w, h int
+	bits []bool
+}
+
+func (b *bitmap) get(x, y int) bool {
+	if x < 0 || y < 0 || x >= b.w || y >= b.h {
+		return false
+	}
+	return b.bits[y*b.w+x]
+}
+
+func (b *bitmap) invert() {
+	for i := range b.bits {
+		b.bits[i] = !b.bits[i]
+	}
+}
+
+func luminance(img image.Image) ([]uint8, int, int) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	lum := make([]uint8, w*h)
+
+	if rgba, ok := img.(*image.RGBA); ok {
+		for y := 0; y < h; y++ {
+			row := rgba.Pix[rgba.PixOffset(bounds.Min.X, bounds.Min.Y+y):]
+			for x := 0; x < w; x++ {
+				p := row[x*4 : x*4+3]
+				lum[y*w+x] = uint8((299*int(p[0]) + 587*int(p[1]) + 114*int(p[2])) / 1000)
+			}
+		}
+		return lum, w, h
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			lum[y*w+x] = uint8((299*r + 587*g + 114*b) / 1000 >> 8)
+		}
+	}
+	return lum, w, h
+}
+
+const (
+	blockSize     = 8
+	minDynamicRng = 24
+)
+
+// binarize thresholds img against the local average of 5x5 blocks of 8x8
+// pixels, so a code on a gradient or next to a dark panel still separates
+// cleanly. Flat blocks borrow the threshold of their neighbours.
+func binarize(img image.Image) *bitmap {
+	lum, w, h := luminance(img)
+	bm := &bitmap{w: w, h: h, bits: make([]bool, w*h)}
+	if w == 0 || h == 0 {
+		return bm
+	}
+
+	bw := (w + blockSize - 1) / blockSize
+	bh := (h + blockSize - 1) / blockSize
+	avg := make([]int, bw*bh)
+
+	for by := 0; by < bh; by++ {
+		for bx := 0; bx < bw; bx++ {
+			sum, n, lo, hi := 0, 0, 255, 0
+			for y := by * blockSize; y < min((by+1)*blockSize, h); y++ {
+				for x := bx * blockSize; x < min((bx+1)*blockSize, w); x++ {
+					v := int(lum[y*w+x])
+					sum += v
+					n++
+					lo = min(lo, v)
+					hi = max(hi, v)
+				}
+			}
+
+			a := sum / n
+			if hi-lo <= minDynamicRng {
+				// flat block: assume background unless the neighbours say otherwise
+				a = lo / 2
+				if bx > 0 && by > 0 {
+					neighbours := (avg[(by-1)*bw+bx] + 2*avg[by*bw+bx-1] + avg[(by-1)*bw+bx-1]) / 4
+					if lo < neighbours {
+						a = neighbours
+					}
+				}
+			}
+			avg[by*bw+bx] = a
+		}
+	}
+
+	for by := 0; by < bh; by++ {
+		for bx := 0; bx < bw; bx++ {
+			sum, n := 0, 0
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					nx, ny := bx+dx, by+dy
+					if nx < 0 || ny < 0 || nx >= bw || ny >= bh {
+						continue
+					}
+					sum += avg[ny*bw+nx]
+					n++
+				}
+			}
+			threshold := uint8(sum / n)
+
+			for y := by * blockSize; y < min((by+1)*blockSize, h); y++ {
+				for x := bx * blockSize; x < min((bx+1)*blockSize, w); x++ {
+					bm.bits[y*w+x] = lum[y*w+x] <= threshold
+				}
+			}
+		}
+	}
+	return bm
+}
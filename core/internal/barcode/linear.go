@@ -0,0 +1,446 @@
+package barcode
+
+import (
+	"image"
+	"math"
+	"strings"
+)
+
+// 1D barcodes are read from the run lengths of sampled rows. Every row is
+// also tried reversed so upside-down codes decode too.
+
+// maxRowsScanned bounds the work on tall captures; codes are usually far
+// taller than the step this leaves between rows.
+const maxRowsScanned = 256
+
+type run struct {
+	start, length int
+	dark          bool
+}
+
+func rowRuns(bm *bitmap, y int) []run {
+	var runs []run
+	for x := 0; x < bm.w; {
+		start, dark := x, bm.bits[y*bm.w+x]
+		for x < bm.w && bm.bits[y*bm.w+x] == dark {
+			x++
+		}
+		runs = append(runs, run{start: start, length: x - start, dark: dark})
+	}
+	return runs
+}
+
+func decodeLinear(bm *bitmap) []Result {
+	type hit struct {
+		Result
+		minY, maxY int
+	}
+	var hits []*hit
+	found := make(map[Result]*hit)
+
+	step := max(1, bm.h/maxRowsScanned)
+	for y := 0; y < bm.h; y += step {
+		runs := rowRuns(bm, y)
+		reversed := make([]run, len(runs))
+		for i, r := range runs {
+			reversed[len(runs)-1-i] = r
+		}
+
+		for _, rs := range [][]run{runs, reversed} {
+			for _, r := range scanRow(rs) {
+				key := Result{Format: r.Format, Text: r.Text}
+				if h, ok := found[key]; ok {
+					h.maxY = y
+					h.Bounds = h.Bounds.Union(r.Bounds)
+					continue
+				}
+				h := &hit{Result: r, minY: y, maxY: y}
+				found[key] = h
+				hits = append(hits, h)
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(hits))
+	for _, h := range hits {
+		h.Bounds.Min.Y, h.Bounds.Max.Y = h.minY, h.maxY+1
+		results = append(results, h.Result)
+	}
+	return results
+}
+
+// scanRow tries every dark run that follows a quiet zone as the start of a
+// barcode.
+func scanRow(runs []run) []Result {
+	var results []Result
+	for i := 0; i < len(runs); i++ {
+		if !runs[i].dark {
+			continue
+		}
+		if r, n, ok := decodeEAN(runs, i); ok {
+			results = append(results, r)
+			i += n - 1
+			continue
+		}
+		if r, n, ok := decodeCode128(runs, i); ok {
+			results = append(results, r)
+			i += n - 1
+		}
+	}
+	return results
+}
+
+func runsBounds(runs []run) image.Rectangle {
+	lo, hi := runs[0].start, runs[0].start+runs[0].length
+	for _, r := range runs {
+		lo = min(lo, r.start)
+		hi = max(hi, r.start+r.length)
+	}
+	return image.Rect(lo, 0, hi, 1)
+}
+
+func runLengths(runs []run) []int {
+	lengths := make([]int, len(runs))
+	for i, r := range runs {
+		lengths[i] = r.length
+	}
+	return lengths
+}
+
+// patternVariance compares measured run lengths with a pattern in modules
+// and returns the average deviation per module, or +Inf when a single run
+// is off by more than maxIndividual modules.
+func patternVariance(counts, pattern []int, maxIndividual float64) float64 {
+	total, modules := 0, 0
+	for i := range counts {
+		total += counts[i]
+		modules += pattern[i]
+	}
+	if total < modules {
+		return math.Inf(1)
+	}
+	unit := float64(total) / float64(modules)
+	variance := 0.0
+	for i := range counts {
+		d := math.Abs(float64(counts[i]) - float64(pattern[i])*unit)
+		if d > maxIndividual*unit {
+			return math.Inf(1)
+		}
+		variance += d
+	}
+	return variance / float64(total)
+}
+
+const (
+	maxAvgVariance        = 0.48
+	maxIndividualVariance = 0.7
+)
+
+// quietBefore and quietAfter check for a light margin of the given number
+// of modules around the symbol starting at or ending before run i. A margin
+// cut off by the edge of the capture is accepted.
+func quietBefore(runs []run, i int, moduleWidth float64, modules int) bool {
+	if i == 0 {
+		return true
+	}
+	prev := runs[i-1]
+	return !prev.dark && (i == 1 || float64(prev.length) >= moduleWidth*float64(modules))
+}
+
+func quietAfter(runs []run, i int, moduleWidth float64, modules int) bool {
+	if i >= len(runs) {
+		return true
+	}
+	next := runs[i]
+	return !next.dark && (i == len(runs)-1 || float64(next.length) >= moduleWidth*float64(modules))
+}
+
+// EAN-13, EAN-8 and UPC-A
+
+var (
+	eanStartGuard  = []int{1, 1, 1}
+	eanMiddleGuard = []int{1, 1, 1, 1, 1}
+
+	// L codes as light, dark, light, dark widths; R codes use the same
+	// widths starting dark and G codes are the L codes reversed
+	eanLCodes = [10][]int{
+		{3, 2, 1, 1}, {2, 2, 2, 1}, {2, 1, 2, 2}, {1, 4, 1, 1}, {1, 1, 3, 2},
+		{1, 2, 3, 1}, {1, 1, 1, 4}, {1, 3, 1, 2}, {1, 2, 1, 3}, {3, 1, 1, 2},
+	}
+
+	// parity of the six left digits (bit set for G) encodes the first digit
+	eanFirstDigitParity = [10]int{0x00, 0x0b, 0x0d, 0x0e, 0x13, 0x19, 0x1c, 0x15, 0x16, 0x1a}
+)
+
+func eanDigit(counts []int, allowG bool) (digit int, g bool, ok bool) {
+	best := maxAvgVariance
+	digit = -1
+	for d, code := range eanLCodes {
+		if v := patternVariance(counts, code, maxIndividualVariance); v < best {
+			best, digit, g = v, d, false
+		}
+		if !allowG {
+			continue
+		}
+		rev := []int{code[3], code[2], code[1], code[0]}
+		if v := patternVariance(counts, rev, maxIndividualVariance); v < best {
+			best, digit, g = v, d, true
+		}
+	}
+	return digit, g, digit >= 0
+}
+
+func decodeEAN(runs []run, i int) (Result, int, bool) {
+	for _, digits := range []int{13, 8} {
+		half := digits / 2
+		n := 3 + 4*half + 5 + 4*half + 3
+		if i+n > len(runs) {
+			continue
+		}
+		counts := runLengths(runs[i : i+n])
+
+		if patternVariance(counts[:3], eanStartGuard, maxIndividualVariance) > maxAvgVariance {
+			return Result{}, 0, false
+		}
+		module := float64(counts[0]+counts[1]+counts[2]) / 3
+		if !quietBefore(runs, i, module, 5) {
+			return Result{}, 0, false
+		}
+
+		var text strings.Builder
+		parity := 0
+		pos := 3
+		valid := true
+		for k := 0; k < half && valid; k++ {
+			d, g, ok := eanDigit(counts[pos:pos+4], digits == 13)
+			valid = ok
+			if g {
+				parity |= 1 << (half - 1 - k)
+			}
+			text.WriteByte(byte('0' + d))
+			pos += 4
+		}
+		if !valid || patternVariance(counts[pos:pos+5], eanMiddleGuard, maxIndividualVariance) > maxAvgVariance {
+			continue
+		}
+		pos += 5
+		for k := 0; k < half && valid; k++ {
+			d, _, ok := eanDigit(counts[pos:pos+4], false)
+			valid = ok
+			text.WriteByte(byte('0' + d))
+			pos += 4
+		}
+		if !valid || patternVariance(counts[pos:pos+3], eanStartGuard, maxIndividualVariance) > maxAvgVariance {
+			continue
+		}
+		if !quietAfter(runs, i+n, module, 5) {
+			continue
+		}
+
+		code := text.String()
+		format := FormatEAN8
+		if digits == 13 {
+			first := -1
+			for d, p := range eanFirstDigitParity {
+				if p == parity {
+					first = d
+				}
+			}
+			if first < 0 {
+				continue
+			}
+			code = string(rune('0'+first)) + code
+			format = FormatEAN13
+		}
+		if !eanChecksumValid(code) {
+			continue
+		}
+		if format == FormatEAN13 && code[0] == '0' {
+			format, code = FormatUPCA, code[1:]
+		}
+		return Result{Format: format, Text: code, Bounds: runsBounds(runs[i : i+n])}, n, true
+	}
+	return Result{}, 0, false
+}
+
+// eanChecksumValid checks the trailing check digit: weights alternate 3 and
+// 1 starting from the digit before it.
+func eanChecksumValid(code string) bool {
+	sum := 0
+	for i := len(code) - 2; i >= 0; i-- {
+		d := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
+
+// Code 128
+
+var code128Patterns = [107][]int{
+	{2, 1, 2, 2, 2, 2}, {2, 2, 2, 1, 2, 2}, {2, 2, 2, 2, 2, 1}, {1, 2, 1, 2, 2, 3}, {1, 2, 1, 3, 2, 2},
+	{1, 3, 1, 2, 2, 2}, {1, 2, 2, 2, 1, 3}, {1, 2, 2, 3, 1, 2}, {1, 3, 2, 2, 1, 2}, {2, 2, 1, 2, 1, 3},
+	{2, 2, 1, 3, 1, 2}, {2, 3, 1, 2, 1, 2}, {1, 1, 2, 2, 3, 2}, {1, 2, 2, 1, 3, 2}, {1, 2, 2, 2, 3, 1},
+	{1, 1, 3, 2, 2, 2}, {1, 2, 3, 1, 2, 2}, {1, 2, 3, 2, 2, 1}, {2, 2, 3, 2, 1, 1}, {2, 2, 1, 1, 3, 2},
+	{2, 2, 1, 2, 3, 1}, {2, 1, 3, 2, 1, 2}, {2, 2, 3, 1, 1, 2}, {3, 1, 2, 1, 3, 1}, {3, 1, 1, 2, 2, 2},
+	{3, 2, 1, 1, 2, 2}, {3, 2, 1, 2, 2, 1}, {3, 1, 2, 2, 1, 2}, {3, 2, 2, 1, 1, 2}, {3, 2, 2, 2, 1, 1},
+	{2, 1, 2, 1, 2, 3}, {2, 1, 2, 3, 2, 1}, {2, 3, 2, 1, 2, 1}, {1, 1, 1, 3, 2, 3}, {1, 3, 1, 1, 2, 3},
+	{1, 3, 1, 3, 2, 1}, {1, 1, 2, 3, 1, 3}, {1, 3, 2, 1, 1, 3}, {1, 3, 2, 3, 1, 1}, {2, 1, 1, 3, 1, 3},
+	{2, 3, 1, 1, 1, 3}, {2, 3, 1, 3, 1, 1}, {1, 1, 2, 1, 3, 3}, {1, 1, 2, 3, 3, 1}, {1, 3, 2, 1, 3, 1},
+	{1, 1, 3, 1, 2, 3}, {1, 1, 3, 3, 2, 1}, {1, 3, 3, 1, 2, 1}, {3, 1, 3, 1, 2, 1}, {2, 1, 1, 3, 3, 1},
+	{2, 3, 1, 1, 3, 1}, {2, 1, 3, 1, 1, 3}, {2, 1, 3, 3, 1, 1}, {2, 1, 3, 1, 3, 1}, {3, 1, 1, 1, 2, 3},
+	{3, 1, 1, 3, 2, 1}, {3, 3, 1, 1, 2, 1}, {3, 1, 2, 1, 1, 3}, {3, 1, 2, 3, 1, 1}, {3, 3, 2, 1, 1, 1},
+	{3, 1, 4, 1, 1, 1}, {2, 2, 1, 4, 1, 1}, {4, 3, 1, 1, 1, 1}, {1, 1, 1, 2, 2, 4}, {1, 1, 1, 4, 2, 2},
+	{1, 2, 1, 1, 2, 4}, {1, 2, 1, 4, 2, 1}, {1, 4, 1, 1, 2, 2}, {1, 4, 1, 2, 2, 1}, {1, 1, 2, 2, 1, 4},
+	{1, 1, 2, 4, 1, 2}, {1, 2, 2, 1, 1, 4}, {1, 2, 2, 4, 1, 1}, {1, 4, 2, 1, 1, 2}, {1, 4, 2, 2, 1, 1},
+	{2, 4, 1, 2, 1, 1}, {2, 2, 1, 1, 1, 4}, {4, 1, 3, 1, 1, 1}, {2, 4, 1, 1, 1, 2}, {1, 3, 4, 1, 1, 1},
+	{1, 1, 1, 2, 4, 2}, {1, 2, 1, 1, 4, 2}, {1, 2, 1, 2, 4, 1}, {1, 1, 4, 2, 1, 2}, {1, 2, 4, 1, 1, 2},
+	{1, 2, 4, 2, 1, 1}, {4, 1, 1, 2, 1, 2}, {4, 2, 1, 1, 1, 2}, {4, 2, 1, 2, 1, 1}, {2, 1, 2, 1, 4, 1},
+	{2, 1, 4, 1, 2, 1}, {4, 1, 2, 1, 2, 1}, {1, 1, 1, 1, 4, 3}, {1, 1, 1, 3, 4, 1}, {1, 3, 1, 1, 4, 1},
+	{1, 1, 4, 1, 1, 3}, {1, 1, 4, 3, 1, 1}, {4, 1, 1, 1, 1, 3}, {4, 1, 1, 3, 1, 1}, {1, 1, 3, 1, 4, 1},
+	{1, 1, 4, 1, 3, 1}, {3, 1, 1, 1, 4, 1}, {4, 1, 1, 1, 3, 1}, {2, 1, 1, 4, 1, 2}, {2, 1, 1, 2, 1, 4},
+	{2, 1, 1, 2, 3, 2},
+	{2, 3, 3, 1, 1, 1}, // stop, followed by a two module bar
+}
+
+const (
+	code128FNC3   = 96
+	code128FNC2   = 97
+	code128Shift  = 98
+	code128CodeC  = 99
+	code128CodeB  = 100
+	code128CodeA  = 101
+	code128FNC1   = 102
+	code128StartA = 103
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+func code128Symbol(counts []int) (int, bool) {
+	best, symbol := maxAvgVariance, -1
+	for s, p := range code128Patterns {
+		if v := patternVariance(counts, p, maxIndividualVariance); v < best {
+			best, symbol = v, s
+		}
+	}
+	return symbol, symbol >= 0
+}
+
+func decodeCode128(runs []run, i int) (Result, int, bool) {
+	if i+6 > len(runs) {
+		return Result{}, 0, false
+	}
+	start, ok := code128Symbol(runLengths(runs[i : i+6]))
+	if !ok || start < code128StartA || start > code128StartC {
+		return Result{}, 0, false
+	}
+	counts := runLengths(runs[i : i+6])
+	module := float64(counts[0]+counts[1]+counts[2]+counts[3]+counts[4]+counts[5]) / 11
+	if !quietBefore(runs, i, module, 5) {
+		return Result{}, 0, false
+	}
+
+	symbols := []int{start}
+	pos := i + 6
+	for {
+		if pos+6 > len(runs) {
+			return Result{}, 0, false
+		}
+		s, ok := code128Symbol(runLengths(runs[pos : pos+6]))
+		if !ok || s >= code128StartA && s <= code128StartC {
+			return Result{}, 0, false
+		}
+		pos += 6
+		if s == code128Stop {
+			// the stop pattern ends with a seventh, two module bar
+			if pos >= len(runs) || !runs[pos].dark {
+				return Result{}, 0, false
+			}
+			pos++
+			break
+		}
+		symbols = append(symbols, s)
+	}
+	if len(symbols) < 2 {
+		return Result{}, 0, false
+	}
+
+	check := symbols[len(symbols)-1]
+	sum := symbols[0]
+	for k, s := range symbols[1 : len(symbols)-1] {
+		sum += (k + 1) * s
+	}
+	if sum%103 != check {
+		return Result{}, 0, false
+	}
+
+	text, ok := code128Text(symbols[:len(symbols)-1])
+	if !ok || text == "" {
+		return Result{}, 0, false
+	}
+	n := pos - i
+	return Result{Format: FormatCode128, Text: text, Bounds: runsBounds(runs[i:pos])}, n, true
+}
+
+// code128Text expands the symbol values in code sets A, B and C. FNC1 is
+// dropped in first position (GS1-128) and becomes a group separator after.
+func code128Text(symbols []int) (string, bool) {
+	var b strings.Builder
+	set := symbols[0] - code128StartA // 0 A, 1 B, 2 C
+	shift := false
+
+	for k, s := range symbols[1:] {
+		cur := set
+		if shift {
+			cur = 1 - set
+			shift = false
+		}
+
+		if cur == 2 {
+			switch {
+			case s < 100:
+				b.WriteByte(byte('0' + s/10))
+				b.WriteByte(byte('0' + s%10))
+			case s == code128CodeB:
+				set = 1
+			case s == code128CodeA:
+				set = 0
+			case s == code128FNC1:
+				if k > 0 {
+					b.WriteByte(0x1d)
+				}
+			default:
+				return "", false
+			}
+			continue
+		}
+
+		switch {
+		case s < 64:
+			b.WriteByte(byte(' ' + s))
+		case s < 96 && cur == 1:
+			b.WriteByte(byte(' ' + s))
+		case s < 96:
+			b.WriteByte(byte(s - 64))
+		case s == code128Shift:
+			shift = true
+		case s == code128CodeC:
+			set = 2
+		case s == code128FNC1:
+			if k > 0 {
+				b.WriteByte(0x1d)
+			}
+		case cur == 0 && s == code128CodeB, cur == 1 && s == code128CodeA:
+			set = 1 - cur
+		case s == code128FNC3, s == code128FNC2, s == code128CodeA, s == code128CodeB:
+			// function codes without text; FNC4 extended Latin-1 is not
+			// supported
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
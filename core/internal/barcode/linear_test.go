@@ -0,0 +1,163 @@
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// widths appends alternating bars and spaces, starting with dark.
+func widths(modules []bool, dark bool, pattern ...int) []bool {
+	for _, w := range pattern {
+		for i := 0; i < w; i++ {
+			modules = append(modules, dark)
+		}
+		dark = !dark
+	}
+	return modules
+}
+
+func eanModules(code string) []bool {
+	half := len(code) / 2
+	left, right := code[:half], code[half:]
+	parity := 0
+	if len(code) == 13 {
+		parity = eanFirstDigitParity[code[0]-'0']
+		left, right = code[1:7], code[7:]
+	}
+
+	m := widths(nil, true, eanStartGuard...)
+	for i, c := range left {
+		p := eanLCodes[c-'0']
+		if parity&(1<<(len(left)-1-i)) != 0 {
+			p = []int{p[3], p[2], p[1], p[0]}
+		}
+		m = widths(m, false, p...)
+	}
+	m = widths(m, false, eanMiddleGuard...)
+	for _, c := range right {
+		m = widths(m, true, eanLCodes[c-'0']...)
+	}
+	return widths(m, true, eanStartGuard...)
+}
+
+func code128Modules(symbols ...int) []bool {
+	sum := symbols[0]
+	for i, s := range symbols[1:] {
+		sum += (i + 1) * s
+	}
+	symbols = append(symbols, sum%103, code128Stop)
+
+	var m []bool
+	for _, s := range symbols {
+		m = widths(m, true, code128Patterns[s]...)
+	}
+	return widths(m, true, 2)
+}
+
+// barImage draws modules as vertical bars with a ten module quiet zone.
+func barImage(modules []bool, size, height int) *image.RGBA {
+	img := canvas((len(modules)+20)*size, height, white)
+	for i, on := range modules {
+		if !on {
+			continue
+		}
+		for x := (i + 10) * size; x < (i+11)*size; x++ {
+			for y := 10; y < height-10; y++ {
+				img.SetRGBA(x, y, black)
+			}
+		}
+	}
+	return img
+}
+
+func TestDecodeEAN(t *testing.T) {
+	for _, tc := range []struct {
+		code   string
+		format Format
+		text   string
+	}{
+		{"4006381333931", FormatEAN13, "4006381333931"},
+		{"9780201379624", FormatEAN13, "9780201379624"},
+		{"0036000291452", FormatUPCA, "036000291452"},
+		{"96385074", FormatEAN8, "96385074"},
+	} {
+		t.Run(tc.code, func(t *testing.T) {
+			r := decodeOne(t, barImage(eanModules(tc.code), 2, 80))
+			assert.Equal(t, tc.format, r.Format)
+			assert.Equal(t, tc.text, r.Text)
+		})
+	}
+}
+
+func TestDecodeEANRejectsBadChecksum(t *testing.T) {
+	_, err := Decode(barImage(eanModules("4006381333932"), 2, 80))
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDecodeCode128(t *testing.T) {
+	for name, tc := range map[string]struct {
+		symbols []int
+		text    string
+	}{
+		"set B": {[]int{code128StartB, 'd' - ' ', 'm' - ' ', 's' - ' ', '-' - ' ', '1' - ' '}, "dms-1"},
+		"set C": {[]int{code128StartC, 12, 34, 56, code128CodeB, 'x' - ' '}, "123456x"},
+		"set A": {[]int{code128StartA, 'A' - ' ', code128Shift, 'b' - ' ', 'C' - ' '}, "AbC"},
+		"GS1":   {[]int{code128StartC, code128FNC1, 1, 23, code128FNC1, 45}, "0123\x1d45"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := decodeOne(t, barImage(code128Modules(tc.symbols...), 2, 60))
+			assert.Equal(t, FormatCode128, r.Format)
+			assert.Equal(t, tc.text, r.Text)
+		})
+	}
+}
+
+func TestDecodeLinearUpsideDownAndInverted(t *testing.T) {
+	modules := code128Modules(code128StartB, 'o'-' ', 'k'-' ')
+	reversed := make([]bool, len(modules))
+	for i, on := range modules {
+		reversed[len(modules)-1-i] = on
+	}
+	img := barImage(reversed, 3, 50)
+	r := decodeOne(t, img)
+	assert.Equal(t, "ok", r.Text)
+
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2] = 255-img.Pix[i], 255-img.Pix[i+1], 255-img.Pix[i+2]
+	}
+	r = decodeOne(t, img)
+	assert.Equal(t, "ok", r.Text)
+}
+
+func TestDecodeLinearTolerance(t *testing.T) {
+	// a soft, non-integer scale as left behind by fractional display scaling
+	modules := eanModules("4006381333931")
+	const scale = 2.6
+	img := canvas(int(float64(len(modules)+20)*scale), 60, white)
+	for x := 0; x < img.Rect.Dx(); x++ {
+		i := int(float64(x)/scale) - 10
+		if i < 0 || i >= len(modules) || !modules[i] {
+			continue
+		}
+		for y := 5; y < 55; y++ {
+			img.SetRGBA(x, y, color.RGBA{R: 40, G: 40, B: 40, A: 255})
+		}
+	}
+	assert.Equal(t, "4006381333931", decodeOne(t, img).Text)
+}
+
+func TestCode128Patterns(t *testing.T) {
+	for s, p := range code128Patterns {
+		require.Len(t, p, 6, "symbol %d", s)
+		sum := 0
+		for _, w := range p {
+			sum += w
+		}
+		assert.Equal(t, 11, sum, "symbol %d", s)
+		assert.Zero(t, (p[0]+p[2]+p[4])%2, "symbol %d bars must be even", s)
+	}
+}
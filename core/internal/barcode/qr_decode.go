@@ -0,0 +1,432 @@
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/japanese"
+)
+
+var (
+	errQRNoSymbol = errors.New("qr: no symbol")
+	errQRFormat   = errors.New("qr: unreadable format information")
+	errQRData     = errors.New("qr: invalid data")
+)
+
+// versionMismatchError carries the version read from the version
+// information when it disagrees with the sampled dimension.
+type versionMismatchError int
+
+func (v versionMismatchError) Error() string {
+	return fmt.Sprintf("qr: symbol is version %d", int(v))
+}
+
+// the 32 valid format codewords, already XORed with the format mask
+var qrFormatCodes = func() [32]uint32 {
+	var codes [32]uint32
+	for d := range codes {
+		rem := uint32(d) << 10
+		for i := 14; i >= 10; i-- {
+			if rem&(1<<i) != 0 {
+				rem ^= 0x537 << (i - 10)
+			}
+		}
+		codes[d] = (uint32(d)<<10 | rem) ^ 0x5412
+	}
+	return codes
+}()
+
+// version information codewords for versions 7-40
+var qrVersionCodes = func() [41]uint32 {
+	var codes [41]uint32
+	for v := 7; v <= 40; v++ {
+		rem := uint32(v) << 12
+		for i := 17; i >= 12; i-- {
+			if rem&(1<<i) != 0 {
+				rem ^= 0x1f25 << (i - 12)
+			}
+		}
+		codes[v] = uint32(v)<<12 | rem
+	}
+	return codes
+}()
+
+func readQRFormat(m *bitmap) (qrECLevel, int, bool) {
+	dim := m.w
+	var first, second uint32
+	bit := func(v uint32, x, y int) uint32 {
+		v <<= 1
+		if m.get(x, y) {
+			v |= 1
+		}
+		return v
+	}
+
+	for x := 0; x < 6; x++ {
+		first = bit(first, x, 8)
+	}
+	first = bit(first, 7, 8)
+	first = bit(first, 8, 8)
+	first = bit(first, 8, 7)
+	for y := 5; y >= 0; y-- {
+		first = bit(first, 8, y)
+	}
+
+	for y := dim - 1; y >= dim-7; y-- {
+		second = bit(second, 8, y)
+	}
+	for x := dim - 8; x < dim; x++ {
+		second = bit(second, x, 8)
+	}
+
+	best, bestDist := 0, 16
+	for d, code := range qrFormatCodes {
+		for _, read := range []uint32{first, second} {
+			if dist := bits.OnesCount32(read ^ code); dist < bestDist {
+				best, bestDist = d, dist
+			}
+		}
+	}
+	if bestDist > 3 {
+		return 0, 0, false
+	}
+	return qrECLevelBits[best>>3], best & 7, true
+}
+
+func readQRVersion(m *bitmap) (int, bool) {
+	dim := m.w
+	var first, second uint32
+	for j := 5; j >= 0; j-- {
+		for i := dim - 9; i >= dim-11; i-- {
+			first <<= 1
+			if m.get(i, j) {
+				first |= 1
+			}
+			second <<= 1
+			if m.get(j, i) {
+				second |= 1
+			}
+		}
+	}
+
+	best, bestDist := 0, 19
+	for v := 7; v <= 40; v++ {
+		for _, read := range []uint32{first, second} {
+			if dist := bits.OnesCount32(read ^ qrVersionCodes[v]); dist < bestDist {
+				best, bestDist = v, dist
+			}
+		}
+	}
+	return best, bestDist <= 3
+}
+
+func qrMasked(mask, y, x int) bool {
+	switch mask {
+	case 0:
+		return (y+x)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (y+x)%3 == 0
+	case 4:
+		return (y/2+x/3)%2 == 0
+	case 5:
+		return (y*x)%2+(y*x)%3 == 0
+	case 6:
+		return ((y*x)%2+(y*x)%3)%2 == 0
+	default:
+		return ((y+x)%2+(y*x)%3)%2 == 0
+	}
+}
+
+// decodeQRMatrix decodes a sampled module grid.
+func decodeQRMatrix(m *bitmap) (string, error) {
+	dim := m.w
+	version := (dim - 17) / 4
+	if version < 1 || version > 40 || qrDimension(version) != dim {
+		return "", errQRNoSymbol
+	}
+	if version >= 7 {
+		if v, ok := readQRVersion(m); ok && v != version {
+			return "", versionMismatchError(v)
+		}
+	}
+
+	level, mask, ok := readQRFormat(m)
+	if !ok {
+		return "", errQRFormat
+	}
+
+	function := qrFunctionMask(version)
+	codewords := make([]uint8, 0, qrCodewords(version))
+	var cur uint8
+	n := 0
+	up := true
+	for x := dim - 1; x > 0; x -= 2 {
+		if x == 6 {
+			// the vertical timing pattern shifts the column pairs
+			x--
+		}
+		for i := 0; i < dim; i++ {
+			y := i
+			if up {
+				y = dim - 1 - i
+			}
+			for col := 0; col < 2; col++ {
+				xx := x - col
+				if function.bits[y*dim+xx] {
+					continue
+				}
+				cur <<= 1
+				if m.bits[y*dim+xx] != qrMasked(mask, y, xx) {
+					cur |= 1
+				}
+				if n++; n == 8 {
+					if len(codewords) < cap(codewords) {
+						codewords = append(codewords, cur)
+					}
+					cur, n = 0, 0
+				}
+			}
+		}
+		up = !up
+	}
+
+	blocks, err := qrSplitBlocks(codewords, version, level)
+	if err != nil {
+		return "", err
+	}
+	var data []uint8
+	for _, b := range blocks {
+		if err := rsCorrect(b.data, b.ec); err != nil {
+			return "", err
+		}
+		data = append(data, b.data[:len(b.data)-b.ec]...)
+	}
+	return parseQRData(data, version)
+}
+
+type bitReader struct {
+	data []uint8
+	pos  int
+}
+
+func (r *bitReader) available() int {
+	return len(r.data)*8 - r.pos
+}
+
+func (r *bitReader) read(n int) (int, bool) {
+	if n > r.available() {
+		return 0, false
+	}
+	v := 0
+	for i := 0; i < n; i++ {
+		v <<= 1
+		if r.data[r.pos/8]&(0x80>>(r.pos%8)) != 0 {
+			v |= 1
+		}
+		r.pos++
+	}
+	return v, true
+}
+
+const (
+	qrModeTerminator = 0x0
+	qrModeNumeric    = 0x1
+	qrModeAlnum      = 0x2
+	qrModeAppend     = 0x3
+	qrModeByte       = 0x4
+	qrModeFNC1First  = 0x5
+	qrModeECI        = 0x7
+	qrModeKanji      = 0x8
+	qrModeFNC1Second = 0x9
+)
+
+const qrAlnumChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+func qrCountBits(mode, version int) int {
+	i := 0
+	switch {
+	case version >= 27:
+		i = 2
+	case version >= 10:
+		i = 1
+	}
+	switch mode {
+	case qrModeNumeric:
+		return [3]int{10, 12, 14}[i]
+	case qrModeAlnum:
+		return [3]int{9, 11, 13}[i]
+	case qrModeByte:
+		return [3]int{8, 16, 16}[i]
+	default:
+		return [3]int{8, 10, 12}[i]
+	}
+}
+
+// ECI assignments for the character sets seen in practice
+const (
+	eciLatin1   = 3
+	eciShiftJIS = 20
+	eciUTF8     = 26
+)
+
+func parseQRData(data []uint8, version int) (string, error) {
+	r := &bitReader{data: data}
+	var out strings.Builder
+	eci := -1
+
+	for r.available() >= 4 {
+		mode, _ := r.read(4)
+		switch mode {
+		case qrModeTerminator:
+			return out.String(), nil
+		case qrModeFNC1First:
+		case qrModeFNC1Second:
+			if _, ok := r.read(8); !ok {
+				return "", errQRData
+			}
+		case qrModeAppend:
+			if _, ok := r.read(16); !ok {
+				return "", errQRData
+			}
+		case qrModeECI:
+			v, ok := readECI(r)
+			if !ok {
+				return "", errQRData
+			}
+			eci = v
+		case qrModeNumeric, qrModeAlnum, qrModeByte, qrModeKanji:
+			count, ok := r.read(qrCountBits(mode, version))
+			if !ok {
+				return "", errQRData
+			}
+			var segment string
+			switch mode {
+			case qrModeNumeric:
+				segment, ok = readNumeric(r, count)
+			case qrModeAlnum:
+				segment, ok = readAlnum(r, count)
+			case qrModeByte:
+				segment, ok = readBytes(r, count, eci)
+			default:
+				segment, ok = readKanji(r, count)
+			}
+			if !ok {
+				return "", errQRData
+			}
+			out.WriteString(segment)
+		default:
+			return "", errQRData
+		}
+	}
+	return out.String(), nil
+}
+
+func readECI(r *bitReader) (int, bool) {
+	first, ok := r.read(8)
+	switch {
+	case !ok:
+		return 0, false
+	case first&0x80 == 0:
+		return first, true
+	case first&0xc0 == 0x80:
+		rest, ok := r.read(8)
+		return (first&0x3f)<<8 | rest, ok
+	case first&0xe0 == 0xc0:
+		rest, ok := r.read(16)
+		return (first&0x1f)<<16 | rest, ok
+	}
+	return 0, false
+}
+
+func readNumeric(r *bitReader, count int) (string, bool) {
+	var b strings.Builder
+	for count > 0 {
+		digits, width := 3, 10
+		switch count {
+		case 1:
+			digits, width = 1, 4
+		case 2:
+			digits, width = 2, 7
+		}
+		v, ok := r.read(width)
+		if !ok || v >= [4]int{0, 10, 100, 1000}[digits] {
+			return "", false
+		}
+		fmt.Fprintf(&b, "%0*d", digits, v)
+		count -= digits
+	}
+	return b.String(), true
+}
+
+func readAlnum(r *bitReader, count int) (string, bool) {
+	var b strings.Builder
+	for count >= 2 {
+		v, ok := r.read(11)
+		if !ok || v >= 45*45 {
+			return "", false
+		}
+		b.WriteByte(qrAlnumChars[v/45])
+		b.WriteByte(qrAlnumChars[v%45])
+		count -= 2
+	}
+	if count == 1 {
+		v, ok := r.read(6)
+		if !ok || v >= 45 {
+			return "", false
+		}
+		b.WriteByte(qrAlnumChars[v])
+	}
+	return b.String(), true
+}
+
+// readBytes decodes a byte segment. Without an ECI the standard says
+// Latin-1, but nearly every generator writes UTF-8, so valid UTF-8 wins.
+func readBytes(r *bitReader, count, eci int) (string, bool) {
+	raw := make([]byte, count)
+	for i := range raw {
+		v, ok := r.read(8)
+		if !ok {
+			return "", false
+		}
+		raw[i] = byte(v)
+	}
+
+	switch {
+	case eci == eciShiftJIS:
+		decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(raw)
+		return string(decoded), err == nil
+	case eci == eciUTF8 || (eci != eciLatin1 && utf8.Valid(raw)):
+		return string(raw), true
+	}
+	runes := make([]rune, len(raw))
+	for i, c := range raw {
+		runes[i] = rune(c)
+	}
+	return string(runes), true
+}
+
+func readKanji(r *bitReader, count int) (string, bool) {
+	raw := make([]byte, 0, 2*count)
+	for i := 0; i < count; i++ {
+		v, ok := r.read(13)
+		if !ok {
+			return "", false
+		}
+		c := (v/0xc0)<<8 | v%0xc0
+		if c < 0x1f00 {
+			c += 0x8140
+		} else {
+			c += 0xc140
+		}
+		raw = append(raw, byte(c>>8), byte(c))
+	}
+	decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(raw)
+	return string(decoded), err == nil
+}
@@ -0,0 +1,490 @@
+package barcode
+
+import (
+	"errors"
+	"image"
+	"math"
+	"sort"
+)
+
+type point struct{ x, y float64 }
+
+func distance(a, b point) float64 {
+	return math.Hypot(a.x-b.x, a.y-b.y)
+}
+
+type finderPattern struct {
+	point
+	module float64
+	count  int
+}
+
+const (
+	maxFinderCandidates = 24
+	maxQRAttempts       = 64
+)
+
+// decodeQR locates finder patterns, groups them in threes and decodes the
+// symbol each group describes. A group that does not decode is skipped so
+// stray matches in surrounding UI cannot hide a real code.
+func decodeQR(bm *bitmap) []Result {
+	finders := findFinderPatterns(bm)
+	if len(finders) < 3 {
+		return nil
+	}
+
+	var results []Result
+	used := make([]bool, len(finders))
+	attempts := 0
+	for _, t := range finderTriples(finders) {
+		if used[t.tl] || used[t.tr] || used[t.bl] {
+			continue
+		}
+		if attempts++; attempts > maxQRAttempts {
+			break
+		}
+
+		text, corners, err := decodeQRAt(bm, finders[t.tl], finders[t.tr], finders[t.bl])
+		if err != nil {
+			continue
+		}
+		used[t.tl], used[t.tr], used[t.bl] = true, true, true
+		results = append(results, Result{Format: FormatQR, Text: text, Bounds: boundsOf(corners)})
+	}
+	return results
+}
+
+func boundsOf(pts []point) image.Rectangle {
+	r := image.Rect(int(pts[0].x), int(pts[0].y), int(pts[0].x), int(pts[0].y))
+	for _, p := range pts[1:] {
+		r = r.Union(image.Rect(int(p.x), int(p.y), int(p.x)+1, int(p.y)+1))
+	}
+	return r
+}
+
+// finderRatio reports whether five runs look like the 1:1:3:1:1 dark,
+// light, dark, light, dark cross-section of a finder pattern.
+func finderRatio(counts [5]int) bool {
+	total := 0
+	for _, c := range counts {
+		if c == 0 {
+			return false
+		}
+		total += c
+	}
+	if total < 7 {
+		return false
+	}
+	module := float64(total) / 7
+	variance := module / 2
+	return math.Abs(module-float64(counts[0])) < variance &&
+		math.Abs(module-float64(counts[1])) < variance &&
+		math.Abs(3*module-float64(counts[2])) < 3*variance &&
+		math.Abs(module-float64(counts[3])) < variance &&
+		math.Abs(module-float64(counts[4])) < variance
+}
+
+func findFinderPatterns(bm *bitmap) []finderPattern {
+	var found []finderPattern
+	add := func(c finderPattern) {
+		for i := range found {
+			f := &found[i]
+			if math.Abs(c.x-f.x) <= f.module && math.Abs(c.y-f.y) <= f.module &&
+				math.Abs(c.module-f.module) <= math.Max(1, f.module/2) {
+				n := float64(f.count)
+				f.x = (f.x*n + c.x) / (n + 1)
+				f.y = (f.y*n + c.y) / (n + 1)
+				f.module = (f.module*n + c.module) / (n + 1)
+				f.count++
+				return
+			}
+		}
+		found = append(found, c)
+	}
+
+	check := func(counts [5]int, end, y int) {
+		if !finderRatio(counts) {
+			return
+		}
+		total := counts[0] + counts[1] + counts[2] + counts[3] + counts[4]
+		cx := float64(end-counts[4]-counts[3]) - float64(counts[2])/2
+
+		cy, vTotal, ok := crossCheck(bm, int(cx), y, 0, 1, counts[2], total)
+		if !ok {
+			return
+		}
+		cx, hTotal, ok := crossCheck(bm, int(cx), int(cy), 1, 0, counts[2], total)
+		if !ok {
+			return
+		}
+		add(finderPattern{point: point{cx, cy}, module: float64(vTotal+hTotal) / 14, count: 1})
+	}
+
+	for y := 0; y < bm.h; y++ {
+		var counts [5]int
+		state := 0
+		for x := 0; x < bm.w; x++ {
+			if bm.bits[y*bm.w+x] {
+				if state == 1 || state == 3 {
+					state++
+				}
+				counts[state]++
+				continue
+			}
+
+			switch {
+			case state == 0 && counts[0] == 0:
+				// light pixels before the first dark run
+			case state == 1 || state == 3:
+				counts[state]++
+			case state == 4:
+				check(counts, x, y)
+				counts = [5]int{counts[2], counts[3], counts[4], 1, 0}
+				state = 3
+			default:
+				state++
+				counts[state]++
+			}
+		}
+		if state == 4 {
+			check(counts, bm.w, y)
+		}
+	}
+
+	confirmed := found[:0:0]
+	for _, f := range found {
+		if f.count >= 2 {
+			confirmed = append(confirmed, f)
+		}
+	}
+	if len(confirmed) >= 3 {
+		found = confirmed
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].count > found[j].count })
+	if len(found) > maxFinderCandidates {
+		found = found[:maxFinderCandidates]
+	}
+	return found
+}
+
+// crossCheck re-measures a finder pattern through (cx, cy) along (dx, dy)
+// and returns the refined center coordinate on that axis and the pattern
+// width.
+func crossCheck(bm *bitmap, cx, cy, dx, dy, maxCount, originalTotal int) (float64, int, bool) {
+	var counts [5]int
+	dark := func(i int) bool { return bm.get(cx+dx*i, cy+dy*i) }
+	limit := bm.w
+	pos := cx
+	if dy != 0 {
+		limit = bm.h
+		pos = cy
+	}
+
+	i := 0
+	for pos+i >= 0 && dark(i) {
+		counts[2]++
+		i--
+	}
+	if pos+i < 0 {
+		return 0, 0, false
+	}
+	for pos+i >= 0 && !dark(i) && counts[1] <= maxCount {
+		counts[1]++
+		i--
+	}
+	if pos+i < 0 || counts[1] > maxCount {
+		return 0, 0, false
+	}
+	for pos+i >= 0 && dark(i) && counts[0] <= maxCount {
+		counts[0]++
+		i--
+	}
+	if counts[0] > maxCount {
+		return 0, 0, false
+	}
+
+	i = 1
+	for pos+i < limit && dark(i) {
+		counts[2]++
+		i++
+	}
+	if pos+i >= limit {
+		return 0, 0, false
+	}
+	for pos+i < limit && !dark(i) && counts[3] <= maxCount {
+		counts[3]++
+		i++
+	}
+	if pos+i >= limit || counts[3] > maxCount {
+		return 0, 0, false
+	}
+	for pos+i < limit && dark(i) && counts[4] <= maxCount {
+		counts[4]++
+		i++
+	}
+	if counts[4] > maxCount {
+		return 0, 0, false
+	}
+
+	total := counts[0] + counts[1] + counts[2] + counts[3] + counts[4]
+	if 5*abs(total-originalTotal) >= 2*originalTotal || !finderRatio(counts) {
+		return 0, 0, false
+	}
+	end := pos + i
+	return float64(end-counts[4]-counts[3]) - float64(counts[2])/2, total, true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+type finderTriple struct {
+	tl, tr, bl int
+	score      float64
+}
+
+// finderTriples returns the groups of three finder patterns that could be
+// the corners of one symbol, most square first.
+func finderTriples(finders []finderPattern) []finderTriple {
+	var triples []finderTriple
+	for i := 0; i < len(finders); i++ {
+		for j := i + 1; j < len(finders); j++ {
+			for k := j + 1; k < len(finders); k++ {
+				if t, ok := makeTriple(finders, i, j, k); ok {
+					triples = append(triples, t)
+				}
+			}
+		}
+	}
+	sort.SliceStable(triples, func(a, b int) bool { return triples[a].score < triples[b].score })
+	return triples
+}
+
+func makeTriple(finders []finderPattern, i, j, k int) (finderTriple, bool) {
+	a, b, c := finders[i], finders[j], finders[k]
+	lo := math.Min(a.module, math.Min(b.module, c.module))
+	hi := math.Max(a.module, math.Max(b.module, c.module))
+	if hi > 1.5*lo {
+		return finderTriple{}, false
+	}
+
+	// the corner is opposite the longest side
+	ab, ac, bc := distance(a.point, b.point), distance(a.point, c.point), distance(b.point, c.point)
+	corner, p, q := i, j, k
+	leg1, leg2, hyp := ab, ac, bc
+	switch {
+	case ab >= ac && ab >= bc:
+		corner, p, q = k, i, j
+		leg1, leg2, hyp = ac, bc, ab
+	case ac >= ab && ac >= bc:
+		corner, p, q = j, i, k
+		leg1, leg2, hyp = ab, bc, ac
+	}
+
+	module := (a.module + b.module + c.module) / 3
+	if math.Min(leg1, leg2) < 13*module || math.Max(leg1, leg2) > 180*module {
+		return finderTriple{}, false
+	}
+	legRatio := math.Max(leg1, leg2) / math.Min(leg1, leg2)
+	pythagoras := math.Abs(1 - hyp*hyp/(leg1*leg1+leg2*leg2))
+	if legRatio > 1.4 || pythagoras > 0.25 {
+		return finderTriple{}, false
+	}
+
+	// order the other two clockwise from the corner: top right, bottom left
+	o, u, v := finders[corner].point, finders[p].point, finders[q].point
+	if (u.x-o.x)*(v.y-o.y)-(u.y-o.y)*(v.x-o.x) < 0 {
+		p, q = q, p
+	}
+	return finderTriple{tl: corner, tr: p, bl: q, score: legRatio - 1 + pythagoras + (hi-lo)/lo}, true
+}
+
+// decodeQRAt samples and decodes the symbol whose finder patterns are tl,
+// tr and bl. The dimension estimated from their distance is refined by the
+// version information when the first guess is off.
+func decodeQRAt(bm *bitmap, tl, tr, bl finderPattern) (string, []point, error) {
+	module := (tl.module + tr.module + bl.module) / 3
+	estimate := (distance(tl.point, tr.point)+distance(tl.point, bl.point))/(2*module) + 7
+
+	base := int(math.Round((estimate - 17) / 4))
+	tried := make(map[int]bool)
+	candidates := []int{base, base + 1, base - 1}
+	lastErr := errQRNoSymbol
+	for len(candidates) > 0 {
+		version := candidates[0]
+		candidates = candidates[1:]
+		if version < 1 || version > 40 || tried[version] {
+			continue
+		}
+		tried[version] = true
+
+		grid, corners := sampleQR(bm, tl, tr, bl, module, version)
+		text, err := decodeQRMatrix(grid)
+		if err == nil {
+			return text, corners, nil
+		}
+		var mismatch versionMismatchError
+		if errors.As(err, &mismatch) {
+			candidates = append([]int{int(mismatch)}, candidates...)
+		}
+		lastErr = err
+	}
+	return "", nil, lastErr
+}
+
+// sampleQR maps the module grid of a symbol of the given version onto the
+// image and reads one pixel at the center of every module.
+func sampleQR(bm *bitmap, tl, tr, bl finderPattern, module float64, version int) (*bitmap, []point) {
+	dim := qrDimension(version)
+	d := float64(dim)
+
+	br := point{tr.x - tl.x + bl.x, tr.y - tl.y + bl.y}
+	brModule := point{d - 3.5, d - 3.5}
+	if version >= 2 {
+		correction := 1 - 3/(d-7)
+		est := point{tl.x + correction*(br.x-tl.x), tl.y + correction*(br.y-tl.y)}
+		for _, allowance := range []float64{4, 8, 16} {
+			if p, ok := findAlignment(bm, est, module, allowance*module); ok {
+				br, brModule = p, point{d - 6.5, d - 6.5}
+				break
+			}
+		}
+	}
+
+	h, ok := solveHomography(
+		[4]point{{3.5, 3.5}, {d - 3.5, 3.5}, {3.5, d - 3.5}, brModule},
+		[4]point{tl.point, tr.point, bl.point, br},
+	)
+	grid := &bitmap{w: dim, h: dim, bits: make([]bool, dim*dim)}
+	if !ok {
+		return grid, nil
+	}
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			p := h.apply(point{float64(x) + 0.5, float64(y) + 0.5})
+			grid.bits[y*dim+x] = bm.get(int(math.Floor(p.x)), int(math.Floor(p.y)))
+		}
+	}
+
+	corners := []point{h.apply(point{0, 0}), h.apply(point{d, 0}), h.apply(point{0, d}), h.apply(point{d, d})}
+	return grid, corners
+}
+
+// findAlignment looks for the bottom-right alignment pattern within radius
+// of est: a dark module inside a light ring inside a dark ring.
+func findAlignment(bm *bitmap, est point, module, radius float64) (point, bool) {
+	x0, x1 := int(est.x-radius), int(est.x+radius)
+	y0, y1 := int(est.y-radius), int(est.y+radius)
+	x0, y0 = max(x0, 0), max(y0, 0)
+	x1, y1 = min(x1, bm.w-1), min(y1, bm.h-1)
+	if x1-x0 < int(3*module) || y1-y0 < int(3*module) {
+		return point{}, false
+	}
+
+	near := func(n int) bool { return math.Abs(float64(n)-module) <= math.Max(1, module/2) }
+	best, bestDist := point{}, math.Inf(1)
+	for y := y0; y <= y1; y++ {
+		// runs of the row inside the window: start index and length
+		var runs [][2]int
+		for x := x0; x <= x1; {
+			start, dark := x, bm.get(x, y)
+			for x <= x1 && bm.get(x, y) == dark {
+				x++
+			}
+			runs = append(runs, [2]int{start, x - start})
+		}
+
+		for i := 2; i+2 < len(runs); i++ {
+			if !bm.get(runs[i][0], y) || !near(runs[i][1]) || !near(runs[i-1][1]) || !near(runs[i+1][1]) {
+				continue
+			}
+			cx := float64(runs[i][0]) + float64(runs[i][1])/2
+			cy, ok := alignmentColumn(bm, int(cx), y, near)
+			if !ok {
+				continue
+			}
+			p := point{cx, cy}
+			if d := distance(p, est); d < bestDist {
+				best, bestDist = p, d
+			}
+		}
+	}
+	return best, !math.IsInf(bestDist, 1)
+}
+
+func alignmentColumn(bm *bitmap, x, y int, near func(int) bool) (float64, bool) {
+	top := y
+	for top > 0 && bm.get(x, top-1) {
+		top--
+	}
+	bottom := y
+	for bottom < bm.h-1 && bm.get(x, bottom+1) {
+		bottom++
+	}
+	if !near(bottom - top + 1) {
+		return 0, false
+	}
+
+	above := 0
+	for top-above-1 >= 0 && !bm.get(x, top-above-1) {
+		above++
+	}
+	below := 0
+	for bottom+below+1 < bm.h && !bm.get(x, bottom+below+1) {
+		below++
+	}
+	if !near(above) || !near(below) || top-above-1 < 0 || bottom+below+1 >= bm.h {
+		return 0, false
+	}
+	return float64(top) + float64(bottom-top+1)/2, true
+}
+
+// homography maps (x, y) to ((h0x + h1y + h2) / w, (h3x + h4y + h5) / w)
+// with w = h6x + h7y + 1.
+type homography [8]float64
+
+func (h homography) apply(p point) point {
+	w := h[6]*p.x + h[7]*p.y + 1
+	return point{(h[0]*p.x + h[1]*p.y + h[2]) / w, (h[3]*p.x + h[4]*p.y + h[5]) / w}
+}
+
+func solveHomography(src, dst [4]point) (homography, bool) {
+	var a [8][9]float64
+	for i := 0; i < 4; i++ {
+		s, d := src[i], dst[i]
+		a[2*i] = [9]float64{s.x, s.y, 1, 0, 0, 0, -s.x * d.x, -s.y * d.x, d.x}
+		a[2*i+1] = [9]float64{0, 0, 0, s.x, s.y, 1, -s.x * d.y, -s.y * d.y, d.y}
+	}
+
+	for col := 0; col < 8; col++ {
+		pivot := col
+		for r := col + 1; r < 8; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return homography{}, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		for r := 0; r < 8; r++ {
+			if r == col {
+				continue
+			}
+			f := a[r][col] / a[col][col]
+			for c := col; c < 9; c++ {
+				a[r][c] -= f * a[col][c]
+			}
+		}
+	}
+
+	var h homography
+	for i := range h {
+		h[i] = a[i][8] / a[i][i]
+	}
+	return h, true
+}
@@ -0,0 +1,236 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	qrcode "github.com/yeqown/go-qrcode/v2"
+)
+
+type matrixWriter struct{ modules [][]bool }
+
+func (w *matrixWriter) Write(mat qrcode.Matrix) error {
+	w.modules = mat.Bitmap()
+	return nil
+}
+
+func (w *matrixWriter) Close() error { return nil }
+
+func qrModules(t *testing.T, text string, opts ...qrcode.EncodeOption) [][]bool {
+	t.Helper()
+	qrc, err := qrcode.NewWith(text, opts...)
+	require.NoError(t, err)
+	w := &matrixWriter{}
+	require.NoError(t, qrc.Save(w))
+	return w.modules
+}
+
+// drawModules paints the modules at (x, y) on img with the given module size.
+func drawModules(img *image.RGBA, modules [][]bool, x, y, size int, dark color.RGBA) {
+	for my, row := range modules {
+		for mx, on := range row {
+			if !on {
+				continue
+			}
+			for py := 0; py < size; py++ {
+				for px := 0; px < size; px++ {
+					img.SetRGBA(x+mx*size+px, y+my*size+py, dark)
+				}
+			}
+		}
+	}
+}
+
+func canvas(w, h int, bg color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = bg.R, bg.G, bg.B, 255
+	}
+	return img
+}
+
+var (
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	black = color.RGBA{A: 255}
+)
+
+func qrImage(modules [][]bool, size int) *image.RGBA {
+	dim := len(modules)
+	img := canvas((dim+8)*size, (dim+8)*size, white)
+	drawModules(img, modules, 4*size, 4*size, size, black)
+	return img
+}
+
+func decodeOne(t *testing.T, img image.Image) Result {
+	t.Helper()
+	results, err := Decode(img)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	return results[0]
+}
+
+func TestDecodeQRAllVersions(t *testing.T) {
+	levels := []struct {
+		name  string
+		level qrcode.EncodeOption
+	}{
+		{"L", qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionLow)},
+		{"M", qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionMedium)},
+		{"Q", qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionQuart)},
+		{"H", qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionHighest)},
+	}
+
+	for version := 1; version <= 40; version++ {
+		for _, l := range levels {
+			t.Run(fmt.Sprintf("%d-%s", version, l.name), func(t *testing.T) {
+				// short enough for 1-H, the smallest symbol
+				text := fmt.Sprintf("v%d-%s", version, l.name)
+				modules := qrModules(t, text, qrcode.WithVersion(version), l.level,
+					qrcode.WithEncodingMode(qrcode.EncModeByte))
+				require.Len(t, modules, qrDimension(version))
+
+				r := decodeOne(t, qrImage(modules, 3))
+				assert.Equal(t, FormatQR, r.Format)
+				assert.Equal(t, text, r.Text)
+			})
+		}
+	}
+}
+
+func TestDecodeQRModes(t *testing.T) {
+	for name, tc := range map[string]struct {
+		text string
+		mode qrcode.EncodeOption
+	}{
+		"numeric":      {"0123456789012345678", qrcode.WithEncodingMode(qrcode.EncModeNumeric)},
+		"alphanumeric": {"HELLO WORLD $%*+-./:", qrcode.WithEncodingMode(qrcode.EncModeAlphanumeric)},
+		"utf8 bytes":   {"Grüße, 世界", qrcode.WithEncodingMode(qrcode.EncModeByte)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := decodeOne(t, qrImage(qrModules(t, tc.text, tc.mode), 4))
+			assert.Equal(t, tc.text, r.Text)
+		})
+	}
+}
+
+func TestDecodeQRCorrectsDamage(t *testing.T) {
+	text := "WIFI:T:WPA;S:Dank Home;P:correct horse battery staple;;"
+	modules := qrModules(t, text, qrcode.WithErrorCorrectionLevel(qrcode.ErrorCorrectionHighest))
+	dim := len(modules)
+
+	// flip data modules in the middle, away from the finder patterns
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < dim; i++ {
+		x, y := 10+rng.Intn(dim-20), 10+rng.Intn(dim-20)
+		modules[y][x] = !modules[y][x]
+	}
+
+	r := decodeOne(t, qrImage(modules, 4))
+	assert.Equal(t, text, r.Text)
+}
+
+func TestDecodeQRInScreen(t *testing.T) {
+	// a scaled, light-on-dark code next to other UI on a dark theme
+	screen := canvas(900, 500, color.RGBA{R: 30, G: 30, B: 46, A: 255})
+	for y := 40; y < 60; y++ {
+		for x := 20; x < 400; x++ {
+			screen.SetRGBA(x, y, color.RGBA{R: 205, G: 214, B: 244, A: 255})
+		}
+	}
+	modules := qrModules(t, "otpauth://totp/dms?secret=JBSWY3DPEHPK3PXP")
+	drawModules(screen, modules, 500, 120, 5, color.RGBA{R: 205, G: 214, B: 244, A: 255})
+
+	r := decodeOne(t, screen)
+	assert.Equal(t, "otpauth://totp/dms?secret=JBSWY3DPEHPK3PXP", r.Text)
+	assert.True(t, r.Bounds.Overlaps(image.Rect(500, 120, 600, 220)))
+}
+
+func TestDecodeQRRotatedAndMultiple(t *testing.T) {
+	a := qrModules(t, "first code")
+	b := qrModules(t, "second code")
+
+	// the second code is turned by 90 degrees
+	dim := len(b)
+	rotated := make([][]bool, dim)
+	for y := range rotated {
+		rotated[y] = make([]bool, dim)
+		for x := range rotated[y] {
+			rotated[y][x] = b[dim-1-x][y]
+		}
+	}
+
+	img := canvas(700, 300, white)
+	drawModules(img, a, 30, 40, 6, black)
+	drawModules(img, rotated, 400, 60, 5, black)
+
+	results, err := Decode(img)
+	require.NoError(t, err)
+	var texts []string
+	for _, r := range results {
+		texts = append(texts, r.Text)
+	}
+	assert.Equal(t, []string{"first code", "second code"}, texts)
+}
+
+func TestDecodeNothing(t *testing.T) {
+	img := canvas(200, 200, white)
+	drawModules(img, [][]bool{{true, false, true}}, 50, 50, 20, black)
+	_, err := Decode(img)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestRSCorrect(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, nsym := range []int{7, 10, 22, 30} {
+		for trial := 0; trial < 50; trial++ {
+			msg := make([]uint8, 20+rng.Intn(100))
+			rng.Read(msg)
+			block := rsEncode(msg, nsym)
+
+			errs := rng.Intn(nsym/2 + 1)
+			damaged := append([]uint8(nil), block...)
+			for _, p := range rng.Perm(len(block))[:errs] {
+				damaged[p] ^= uint8(1 + rng.Intn(255))
+			}
+			require.NoError(t, rsCorrect(damaged, nsym), "nsym %d errors %d", nsym, errs)
+			require.Equal(t, block, damaged)
+		}
+	}
+
+	block := rsEncode([]uint8(strings.Repeat("x", 30)), 10)
+	for i := 0; i < 8; i++ {
+		block[i] ^= 0xff
+	}
+	assert.Error(t, rsCorrect(block, 10), "more errors than the code can fix")
+}
+
+// rsEncode appends nsym error correction bytes the way QR blocks do.
+func rsEncode(msg []uint8, nsym int) []uint8 {
+	gen := []uint8{1}
+	for i := 0; i < nsym; i++ {
+		next := make([]uint8, len(gen)+1)
+		for j, g := range gen {
+			next[j] ^= g
+			next[j+1] ^= gfMul(g, gfExp[i])
+		}
+		gen = next
+	}
+
+	rem := append(append([]uint8(nil), msg...), make([]uint8, nsym)...)
+	for i := range msg {
+		coef := rem[i]
+		if coef == 0 {
+			continue
+		}
+		for j, g := range gen {
+			rem[i+j] ^= gfMul(g, coef)
+		}
+	}
+	return append(append([]uint8(nil), msg...), rem[len(msg):]...)
+}
@@ -0,0 +1,149 @@
+package barcode
+
+type qrECLevel int
+
+const (
+	qrECLow qrECLevel = iota
+	qrECMedium
+	qrECQuartile
+	qrECHigh
+)
+
+// format information stores the level as L=01, M=00, Q=11, H=10
+var qrECLevelBits = [4]qrECLevel{qrECMedium, qrECLow, qrECHigh, qrECQuartile}
+
+// per version 1-40 (index 0 unused)
+var qrECCodewordsPerBlock = [4][41]int{
+	{0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+var qrECBlocks = [4][41]int{
+	{0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+func qrDimension(version int) int {
+	return 17 + 4*version
+}
+
+// qrAlignmentCenters lists the row/column coordinates of the alignment
+// pattern centers of a version.
+func qrAlignmentCenters(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	n := version/7 + 2
+	step := 26
+	if version != 32 {
+		step = (version*4 + n*2 + 1) / (n*2 - 2) * 2
+	}
+	centers := make([]int, n)
+	centers[0] = 6
+	for i, pos := n-1, version*4+10; i >= 1; i, pos = i-1, pos-step {
+		centers[i] = pos
+	}
+	return centers
+}
+
+// qrCodewords returns the number of data and error correction codewords of
+// a version, after removing the function patterns and remainder bits.
+func qrCodewords(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		n := version/7 + 2
+		modules -= (25*n-10)*n - 55
+		if version >= 7 {
+			modules -= 36
+		}
+	}
+	return modules / 8
+}
+
+// qrFunctionMask marks the modules of finder, timing, alignment, format
+// and version patterns, which carry no data.
+func qrFunctionMask(version int) *bitmap {
+	dim := qrDimension(version)
+	m := &bitmap{w: dim, h: dim, bits: make([]bool, dim*dim)}
+	fill := func(x, y, w, h int) {
+		for j := y; j < y+h; j++ {
+			for i := x; i < x+w; i++ {
+				m.bits[j*dim+i] = true
+			}
+		}
+	}
+
+	fill(0, 0, 9, 9)
+	fill(dim-8, 0, 8, 9)
+	fill(0, dim-8, 9, 8)
+
+	centers := qrAlignmentCenters(version)
+	last := len(centers) - 1
+	for i, cx := range centers {
+		for j, cy := range centers {
+			if (i == 0 && (j == 0 || j == last)) || (i == last && j == 0) {
+				continue
+			}
+			fill(cx-2, cy-2, 5, 5)
+		}
+	}
+
+	fill(6, 9, 1, dim-17)
+	fill(9, 6, dim-17, 1)
+
+	if version >= 7 {
+		fill(dim-11, 0, 3, 6)
+		fill(0, dim-11, 6, 3)
+	}
+	return m
+}
+
+type qrBlock struct {
+	data []uint8
+	ec   int
+}
+
+// qrSplitBlocks de-interleaves the codewords read from the symbol into its
+// error correction blocks.
+func qrSplitBlocks(codewords []uint8, version int, level qrECLevel) ([]qrBlock, error) {
+	numBlocks := qrECBlocks[level][version]
+	ec := qrECCodewordsPerBlock[level][version]
+	total := qrCodewords(version)
+	if len(codewords) != total {
+		return nil, errTooManyErrors
+	}
+
+	shortLen := total / numBlocks
+	numShort := numBlocks - total%numBlocks
+	shortData := shortLen - ec
+
+	blocks := make([]qrBlock, numBlocks)
+	for i := range blocks {
+		n := shortLen
+		if i >= numShort {
+			n++
+		}
+		blocks[i] = qrBlock{data: make([]uint8, n), ec: ec}
+	}
+
+	k := 0
+	for i := 0; i <= shortData; i++ {
+		for j := range blocks {
+			if i < shortData || j >= numShort {
+				blocks[j].data[i] = codewords[k]
+				k++
+			}
+		}
+	}
+	for i := 0; i < ec; i++ {
+		for j := range blocks {
+			blocks[j].data[len(blocks[j].data)-ec+i] = codewords[k]
+			k++
+		}
+	}
+	return blocks, nil
+}
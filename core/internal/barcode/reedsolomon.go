@@ -0,0 +1,146 @@
+package barcode
+
+import "errors"
+
+// GF(256) with the QR code polynomial x^8 + x^4 + x^3 + x^2 + 1.
+var gfExp, gfLog = func() ([512]uint8, [256]uint8) {
+	var exp [512]uint8
+	var log [256]uint8
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = uint8(x)
+		log[x] = uint8(i)
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= 0x11d
+		}
+	}
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+	return exp, log
+}()
+
+var errTooManyErrors = errors.New("too many errors to correct")
+
+func gfMul(a, b uint8) uint8 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b uint8) uint8 {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+func gfInv(a uint8) uint8 {
+	return gfExp[255-int(gfLog[a])]
+}
+
+// polyEval evaluates p, lowest degree first, at x.
+func polyEval(p []uint8, x uint8) uint8 {
+	var y uint8
+	for i := len(p) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ p[i]
+	}
+	return y
+}
+
+// rsCorrect fixes up to nsym/2 corrupted bytes of a codeword in place. The
+// first byte is the coefficient of the highest power, as stored in a QR
+// block.
+func rsCorrect(block []uint8, nsym int) error {
+	n := len(block)
+	synd := make([]uint8, nsym)
+	clean := true
+	for j := range synd {
+		// r(α^j) with r read highest degree first
+		var s uint8
+		x := gfExp[j]
+		for _, c := range block {
+			s = gfMul(s, x) ^ c
+		}
+		synd[j] = s
+		clean = clean && s == 0
+	}
+	if clean {
+		return nil
+	}
+
+	// Berlekamp-Massey for the error locator Λ, lowest degree first
+	lambda := []uint8{1}
+	prev := []uint8{1}
+	l, m, b := 0, 1, uint8(1)
+	for k := 0; k < nsym; k++ {
+		d := synd[k]
+		for i := 1; i <= l && i < len(lambda); i++ {
+			d ^= gfMul(lambda[i], synd[k-i])
+		}
+		if d == 0 {
+			m++
+			continue
+		}
+
+		next := make([]uint8, max(len(lambda), len(prev)+m))
+		copy(next, lambda)
+		coef := gfDiv(d, b)
+		for i, p := range prev {
+			next[i+m] ^= gfMul(coef, p)
+		}
+
+		if 2*l <= k {
+			prev = lambda
+			l = k + 1 - l
+			b = d
+			m = 1
+		} else {
+			m++
+		}
+		lambda = next
+	}
+	if l > nsym/2 {
+		return errTooManyErrors
+	}
+
+	// Chien search: byte i holds the coefficient of x^(n-1-i)
+	var positions []int
+	for i := 0; i < n; i++ {
+		e := n - 1 - i
+		if polyEval(lambda, gfExp[(255-e%255)%255]) == 0 {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) != l {
+		return errTooManyErrors
+	}
+
+	// Forney: Ω = S·Λ mod x^nsym, e = X·Ω(X⁻¹) / Λ'(X⁻¹)
+	omega := make([]uint8, nsym)
+	for i, s := range synd {
+		for j, c := range lambda {
+			if i+j < nsym {
+				omega[i+j] ^= gfMul(s, c)
+			}
+		}
+	}
+	deriv := make([]uint8, len(lambda))
+	for i := 1; i < len(lambda); i += 2 {
+		deriv[i-1] = lambda[i]
+	}
+
+	for _, i := range positions {
+		e := n - 1 - i
+		x := gfExp[e%255]
+		xInv := gfInv(x)
+		denom := polyEval(deriv, xInv)
+		if denom == 0 {
+			return errTooManyErrors
+		}
+		block[i] ^= gfMul(x, gfDiv(polyEval(omega, xInv), denom))
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WiFi holds the network described by a WIFI: payload, the format phones
+// use to share networks:
+//
+//	WIFI:T:WPA;S:My Network;P:secret;H:false;;
+//
+// Special characters in values are escaped with a backslash.
+type WiFi struct {
+	SSID      string `json:"ssid"`
+	Password  string `json:"password,omitempty"`
+	Security  string `json:"security,omitempty"`
+	Hidden    bool   `json:"hidden,omitempty"`
+	EAPMethod string `json:"eapMethod,omitempty"`
+	Identity  string `json:"identity,omitempty"`
+	Anonymous string `json:"anonymousIdentity,omitempty"`
+	Phase2    string `json:"phase2Auth,omitempty"`
+}
+
+func IsWiFi(text string) bool {
+	return len(text) > 5 && strings.EqualFold(text[:5], "WIFI:")
+}
+
+func ParseWiFi(text string) (*WiFi, error) {
+	if !IsWiFi(text) {
+		return nil, fmt.Errorf("not a WIFI: payload")
+	}
+
+	var w WiFi
+	for _, field := range splitEscaped(text[5:], ';') {
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid wifi field %q", field)
+		}
+		value = unescapeWiFi(value)
+
+		switch strings.ToUpper(key) {
+		case "S":
+			w.SSID = value
+		case "P":
+			w.Password = value
+		case "T":
+			w.Security = value
+		case "H":
+			w.Hidden = strings.EqualFold(value, "true")
+		case "E":
+			w.EAPMethod = value
+		case "I":
+			w.Identity = value
+		case "A":
+			w.Anonymous = value
+		case "PH2":
+			w.Phase2 = value
+		}
+	}
+
+	if w.SSID == "" {
+		return nil, fmt.Errorf("wifi payload has no SSID")
+	}
+	if strings.EqualFold(w.Security, "nopass") {
+		w.Security, w.Password = "", ""
+	}
+	return &w, nil
+}
+
+func splitEscaped(s string, sep byte) []string {
+	var fields []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			fields = append(fields, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(fields, s[start:])
+}
+
+func unescapeWiFi(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
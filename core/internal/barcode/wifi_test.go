@@ -0,0 +1,65 @@
+package barcode
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWiFi(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    WiFi
+	}{
+		{
+			name:    "wpa",
+			payload: "WIFI:T:WPA;S:Dank Home;P:hunter22;;",
+			want:    WiFi{SSID: "Dank Home", Password: "hunter22", Security: "WPA"},
+		},
+		{
+			name:    "escaped and hidden",
+			payload: `WIFI:S:semi\;colon\:net;T:WPA;P:pa\\ss\;word;H:true;;`,
+			want:    WiFi{SSID: "semi;colon:net", Password: `pa\ss;word`, Security: "WPA", Hidden: true},
+		},
+		{
+			name:    "open network",
+			payload: "wifi:T:nopass;S:Cafe;P:;;",
+			want:    WiFi{SSID: "Cafe"},
+		},
+		{
+			name:    "quoted",
+			payload: `WIFI:S:"Quoted";T:WPA;P:"secret";;`,
+			want:    WiFi{SSID: "Quoted", Password: "secret", Security: "WPA"},
+		},
+		{
+			name:    "enterprise",
+			payload: "WIFI:T:WPA2-EAP;S:corp;E:PEAP;PH2:MSCHAPV2;A:anon;I:me@corp;P:pw;;",
+			want: WiFi{
+				SSID: "corp", Password: "pw", Security: "WPA2-EAP",
+				EAPMethod: "PEAP", Phase2: "MSCHAPV2", Anonymous: "anon", Identity: "me@corp",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, IsWiFi(tt.payload))
+			w, err := ParseWiFi(tt.payload)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *w)
+		})
+	}
+}
+
+func TestParseWiFiErrors(t *testing.T) {
+	for _, payload := range []string{
+		"https://example.com",
+		"WIFI:T:WPA;P:nossid;;",
+		"WIFI:T:WPA;S:net;garbage;;",
+	} {
+		_, err := ParseWiFi(payload)
+		assert.Error(t, err, payload)
+	}
+}
@@ -277,6 +277,14 @@ type ColorJSON struct {
 }
 
 func (c Color) ToJSON() (string, error) {
+	bytes, err := json.MarshalIndent(c.JSON(), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+func (c Color) JSON() ColorJSON {
 	h, s, l := rgbToHSL(c.R, c.G, c.B)
 	hv, sv, v := rgbToHSV(c.R, c.G, c.B)
 	cy, m, y, k := rgbToCMYK(c.R, c.G, c.B)
@@ -297,10 +305,5 @@ func (c Color) ToJSON() (string, error) {
 	data.CMYK.M = m
 	data.CMYK.Y = y
 	data.CMYK.K = k
-
-	bytes, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return "", err
-	}
-	return string(bytes), nil
+	return data
 }
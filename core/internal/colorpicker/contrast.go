@@ -0,0 +1,83 @@
+package colorpicker
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/dank16"
+)
+
+// WCAG 2 contrast thresholds for normal and large text
+const (
+	wcagAA      = 4.5
+	wcagAALarge = 3.0
+	wcagAAA     = 7.0
+)
+
+// Contrast compares a foreground (text) color against a background. Lc is
+// the APCA-style lightness contrast from dank16.DeltaPhiStar, negative
+// polarity (light on dark) included.
+type Contrast struct {
+	Foreground string  `json:"foreground"`
+	Background string  `json:"background"`
+	Ratio      float64 `json:"ratio"`
+	AA         bool    `json:"aa"`
+	AALarge    bool    `json:"aaLarge"`
+	AAA        bool    `json:"aaa"`
+	AAALarge   bool    `json:"aaaLarge"`
+	Lc         float64 `json:"lc"`
+}
+
+func ContrastOf(fg, bg Color) Contrast {
+	fgHex, bgHex := fg.ToHex(false), bg.ToHex(false)
+	ratio := dank16.ContrastRatio(fgHex, bgHex)
+	negative := dank16.Luminance(fgHex) > dank16.Luminance(bgHex)
+
+	return Contrast{
+		Foreground: fgHex,
+		Background: bgHex,
+		Ratio:      math.Round(ratio*100) / 100,
+		AA:         ratio >= wcagAA,
+		AALarge:    ratio >= wcagAALarge,
+		AAA:        ratio >= wcagAAA,
+		AAALarge:   ratio >= wcagAA,
+		Lc:         math.Round(math.Max(dank16.DeltaPhiStar(fgHex, bgHex, negative), 0)),
+	}
+}
+
+// Level is the best WCAG level the pair passes: AAA, AA, AA LG (large text
+// only) or FAIL.
+func (c Contrast) Level() string {
+	switch {
+	case c.AAA:
+		return "AAA"
+	case c.AA:
+		return "AA"
+	case c.AALarge:
+		return "AA LG"
+	default:
+		return "FAIL"
+	}
+}
+
+func (c Contrast) String() string {
+	return fmt.Sprintf("%.2f:1 %s LC %.0f", c.Ratio, c.Level(), c.Lc)
+}
+
+// ParseHex reads #RGB or #RRGGBB, with or without the hash.
+func ParseHex(s string) (Color, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %q", s)
+	}
+	return Color{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
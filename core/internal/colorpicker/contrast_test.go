@@ -0,0 +1,54 @@
+package colorpicker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContrastOf(t *testing.T) {
+	black := Color{A: 255}
+	white := Color{R: 255, G: 255, B: 255, A: 255}
+
+	c := ContrastOf(black, white)
+	assert.Equal(t, 21.0, c.Ratio)
+	assert.True(t, c.AAA)
+	assert.Equal(t, "AAA", c.Level())
+	assert.Greater(t, c.Lc, 90.0)
+
+	// the ratio is symmetric, the lightness contrast depends on polarity
+	inv := ContrastOf(white, black)
+	assert.Equal(t, c.Ratio, inv.Ratio)
+	assert.Greater(t, inv.Lc, 90.0)
+
+	gray := Color{R: 0x76, G: 0x76, B: 0x76, A: 255}
+	c = ContrastOf(gray, white)
+	assert.InDelta(t, 4.54, c.Ratio, 0.01)
+	assert.Equal(t, "AA", c.Level())
+
+	c = ContrastOf(Color{R: 0x88, G: 0x88, B: 0x88, A: 255}, white)
+	assert.Equal(t, "AA LG", c.Level())
+
+	c = ContrastOf(white, white)
+	assert.Equal(t, 1.0, c.Ratio)
+	assert.Equal(t, "FAIL", c.Level())
+	assert.Zero(t, c.Lc)
+}
+
+func TestParseHex(t *testing.T) {
+	for in, want := range map[string]Color{
+		"#1E66F5": {R: 0x1e, G: 0x66, B: 0xf5, A: 255},
+		"1e66f5":  {R: 0x1e, G: 0x66, B: 0xf5, A: 255},
+		"#fff":    {R: 255, G: 255, B: 255, A: 255},
+	} {
+		c, err := ParseHex(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, c, in)
+	}
+
+	for _, in := range []string{"", "#12345", "#gggggg"} {
+		_, err := ParseHex(in)
+		assert.Error(t, err, in)
+	}
+}
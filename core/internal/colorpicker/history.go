@@ -0,0 +1,185 @@
+package colorpicker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/fileindex"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+)
+
+const maxPaletteEntries = 500
+
+// PaletteEntry is one picked color. Picking a color that is already in the
+// palette moves it to the top and keeps its name.
+type PaletteEntry struct {
+	ID   string    `json:"id"`
+	Hex  string    `json:"hex"`
+	Name string    `json:"name,omitempty"`
+	Time time.Time `json:"time"`
+}
+
+func (e PaletteEntry) Color() Color {
+	c, _ := ParseHex(e.Hex)
+	return c
+}
+
+type Palette struct {
+	dir   string
+	index *fileindex.Index[PaletteEntry]
+}
+
+func DefaultPaletteDir() string {
+	return filepath.Join(utils.XDGStateHome(), "DankMaterialShell", "colors")
+}
+
+func DefaultPalette() *Palette {
+	return NewPalette(DefaultPaletteDir())
+}
+
+func NewPalette(dir string) *Palette {
+	return &Palette{dir: dir, index: fileindex.New[PaletteEntry](dir, "palette.json")}
+}
+
+func (p *Palette) Dir() string {
+	return p.dir
+}
+
+func (p *Palette) IndexPath() string {
+	return p.index.Path()
+}
+
+// Add records picked colors in order and returns their entries.
+func (p *Palette) Add(colors ...Color) ([]PaletteEntry, error) {
+	added := make([]PaletteEntry, 0, len(colors))
+	err := p.index.Update(func(entries []PaletteEntry) ([]PaletteEntry, error) {
+		now := time.Now()
+		for i, c := range colors {
+			entry := PaletteEntry{Hex: c.ToHex(false), Time: now.Add(time.Duration(i))}
+			entry.ID = fileindex.ID(entry.Time, entry.Hex)
+
+			kept := entries[:0]
+			for _, e := range entries {
+				if e.Hex == entry.Hex {
+					entry.Name = e.Name
+					continue
+				}
+				kept = append(kept, e)
+			}
+			entries = append(kept, entry)
+			added = append(added, entry)
+		}
+
+		if len(entries) > maxPaletteEntries {
+			entries = entries[len(entries)-maxPaletteEntries:]
+		}
+		return entries, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// List returns all entries, newest first.
+func (p *Palette) List() ([]PaletteEntry, error) {
+	entries, err := p.index.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PaletteEntry, len(entries))
+	for i, e := range entries {
+		result[len(entries)-1-i] = e
+	}
+	return result, nil
+}
+
+// Get finds an entry by unique id prefix, hex value or name. An empty ref or
+// "latest" returns the most recent pick.
+func (p *Palette) Get(ref string) (PaletteEntry, error) {
+	entries, err := p.List()
+	if err != nil {
+		return PaletteEntry{}, err
+	}
+	if len(entries) == 0 {
+		return PaletteEntry{}, fmt.Errorf("no colors in history")
+	}
+
+	if ref == "" || ref == "latest" {
+		return entries[0], nil
+	}
+
+	if c, err := ParseHex(ref); err == nil && strings.HasPrefix(ref, "#") {
+		for _, e := range entries {
+			if e.Hex == c.ToHex(false) {
+				return e, nil
+			}
+		}
+	}
+	for _, e := range entries {
+		if e.Name != "" && strings.EqualFold(e.Name, ref) {
+			return e, nil
+		}
+	}
+
+	var found *PaletteEntry
+	for i := range entries {
+		if !strings.HasPrefix(entries[i].ID, ref) {
+			continue
+		}
+		if found != nil {
+			return PaletteEntry{}, fmt.Errorf("color %q is ambiguous", ref)
+		}
+		found = &entries[i]
+	}
+	if found == nil {
+		return PaletteEntry{}, fmt.Errorf("color %q not found", ref)
+	}
+	return *found, nil
+}
+
+func (p *Palette) Rename(ref, name string) (PaletteEntry, error) {
+	entry, err := p.Get(ref)
+	if err != nil {
+		return entry, err
+	}
+	entry.Name = strings.TrimSpace(name)
+
+	err = p.index.Update(func(entries []PaletteEntry) ([]PaletteEntry, error) {
+		for i := range entries {
+			if entries[i].ID == entry.ID {
+				entries[i].Name = entry.Name
+				return entries, nil
+			}
+		}
+		return nil, fmt.Errorf("color %q not found", ref)
+	})
+	return entry, err
+}
+
+func (p *Palette) Delete(ref string) (PaletteEntry, error) {
+	entry, err := p.Get(ref)
+	if err != nil {
+		return entry, err
+	}
+
+	err = p.index.Update(func(entries []PaletteEntry) ([]PaletteEntry, error) {
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.ID != entry.ID {
+				kept = append(kept, e)
+			}
+		}
+		return kept, nil
+	})
+	return entry, err
+}
+
+func (p *Palette) Clear() error {
+	return p.index.Update(func([]PaletteEntry) ([]PaletteEntry, error) {
+		return nil, nil
+	})
+}
@@ -0,0 +1,92 @@
+package colorpicker
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaletteAddAndList(t *testing.T) {
+	p := NewPalette(filepath.Join(t.TempDir(), "colors"))
+
+	added, err := p.Add(Color{R: 0x11, G: 0x22, B: 0x33, A: 255}, Color{R: 0xff, G: 0xff, B: 0xff, A: 255})
+	require.NoError(t, err)
+	require.Len(t, added, 2)
+	assert.Equal(t, "#112233", added[0].Hex)
+
+	entries, err := p.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "#FFFFFF", entries[0].Hex, "newest first")
+
+	_, err = p.Rename(added[0].ID, "Ink")
+	require.NoError(t, err)
+
+	// picking a known color again moves it to the top and keeps the name
+	_, err = p.Add(Color{R: 0x11, G: 0x22, B: 0x33, A: 255})
+	require.NoError(t, err)
+	entries, err = p.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "#112233", entries[0].Hex)
+	assert.Equal(t, "Ink", entries[0].Name)
+}
+
+func TestPaletteGet(t *testing.T) {
+	p := NewPalette(filepath.Join(t.TempDir(), "colors"))
+
+	_, err := p.Get("")
+	assert.Error(t, err)
+
+	added, err := p.Add(Color{R: 0xab, G: 0xcd, B: 0xef, A: 255}, Color{R: 1, G: 2, B: 3, A: 255})
+	require.NoError(t, err)
+	_, err = p.Rename(added[0].ID, "Sky")
+	require.NoError(t, err)
+
+	latest, err := p.Get("latest")
+	require.NoError(t, err)
+	assert.Equal(t, added[1].ID, latest.ID)
+
+	for _, ref := range []string{added[0].ID[:6], "#abcdef", "#ABCDEF", "sky"} {
+		e, err := p.Get(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, added[0].ID, e.ID, ref)
+	}
+
+	_, err = p.Get("#000000")
+	assert.Error(t, err)
+}
+
+func TestPaletteDeleteAndClear(t *testing.T) {
+	p := NewPalette(filepath.Join(t.TempDir(), "colors"))
+	added, err := p.Add(Color{R: 1, A: 255}, Color{R: 2, A: 255}, Color{R: 3, A: 255})
+	require.NoError(t, err)
+
+	_, err = p.Delete(added[1].ID)
+	require.NoError(t, err)
+	entries, err := p.List()
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	require.NoError(t, p.Clear())
+	entries, err = p.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPaletteLimit(t *testing.T) {
+	p := NewPalette(filepath.Join(t.TempDir(), "colors"))
+	colors := make([]Color, maxPaletteEntries+10)
+	for i := range colors {
+		colors[i] = Color{R: uint8(i), G: uint8(i >> 8), A: 255}
+	}
+	_, err := p.Add(colors...)
+	require.NoError(t, err)
+
+	entries, err := p.List()
+	require.NoError(t, err)
+	assert.Len(t, entries, maxPaletteEntries)
+	assert.Equal(t, colors[len(colors)-1].ToHex(false), entries[0].Hex)
+}
@@ -76,6 +76,8 @@ type Picker struct {
 
 	running     bool
 	pickedColor *Color
+	multiPick   bool
+	picks       []Color
 	err         error
 }
 
@@ -87,46 +89,64 @@ func New(config Config) *Picker {
 }
 
 func (p *Picker) Run() (*Color, error) {
+	if err := p.run(); err != nil {
+		return nil, err
+	}
+	return p.pickedColor, nil
+}
+
+// RunMulti keeps the overlay open and collects colors until the user
+// finishes with Enter, Escape or a right click. The last two picks are
+// compared in an on-screen contrast readout.
+func (p *Picker) RunMulti() ([]Color, error) {
+	p.multiPick = true
+	if err := p.run(); err != nil {
+		return nil, err
+	}
+	return p.picks, nil
+}
+
+func (p *Picker) run() error {
 	if err := p.connect(); err != nil {
-		return nil, fmt.Errorf("wayland connect: %w", err)
+		return fmt.Errorf("wayland connect: %w", err)
 	}
 	defer p.cleanup()
 
 	if err := p.setupRegistry(); err != nil {
-		return nil, fmt.Errorf("registry setup: %w", err)
+		return fmt.Errorf("registry setup: %w", err)
 	}
 
 	if err := p.roundtrip(); err != nil {
-		return nil, fmt.Errorf("roundtrip: %w", err)
+		return fmt.Errorf("roundtrip: %w", err)
 	}
 
 	if p.screencopy == nil {
-		return nil, fmt.Errorf("compositor does not support wlr-screencopy-unstable-v1")
+		return fmt.Errorf("compositor does not support wlr-screencopy-unstable-v1")
 	}
 
 	if p.layerShell == nil {
-		return nil, fmt.Errorf("compositor does not support wlr-layer-shell-unstable-v1")
+		return fmt.Errorf("compositor does not support wlr-layer-shell-unstable-v1")
 	}
 
 	if p.seat == nil {
-		return nil, fmt.Errorf("no seat available")
+		return fmt.Errorf("no seat available")
 	}
 
 	if err := p.roundtrip(); err != nil {
-		return nil, fmt.Errorf("roundtrip: %w", err)
+		return fmt.Errorf("roundtrip: %w", err)
 	}
 
 	// Extra roundtrip to ensure pointer/keyboard from seat capabilities are registered
 	if err := p.roundtrip(); err != nil {
-		return nil, fmt.Errorf("roundtrip after seat: %w", err)
+		return fmt.Errorf("roundtrip after seat: %w", err)
 	}
 
 	if err := p.createSurfaces(); err != nil {
-		return nil, fmt.Errorf("create surfaces: %w", err)
+		return fmt.Errorf("create surfaces: %w", err)
 	}
 
 	if err := p.roundtrip(); err != nil {
-		return nil, fmt.Errorf("roundtrip: %w", err)
+		return fmt.Errorf("roundtrip: %w", err)
 	}
 
 	p.running = true
@@ -139,14 +159,15 @@ func (p *Picker) Run() (*Color, error) {
 		p.checkDone()
 	}
 
-	if p.err != nil {
-		return nil, p.err
-	}
-
-	return p.pickedColor, nil
+	return p.err
 }
 
 func (p *Picker) checkDone() {
+	if p.multiPick {
+		p.checkMultiPick()
+		return
+	}
+
 	for _, ls := range p.surfaces {
 		picked, cancelled := ls.state.IsDone()
 		switch {
@@ -164,6 +185,35 @@ func (p *Picker) checkDone() {
 	}
 }
 
+func (p *Picker) checkMultiPick() {
+	for _, ls := range p.surfaces {
+		picked, cancelled := ls.state.IsDone()
+		if picked {
+			ls.state.ClearPicked()
+			if color, ok := ls.state.PickColor(); ok {
+				p.picks = append(p.picks, color)
+				p.updateCompare()
+				p.redrawSurface(ls)
+			}
+		}
+		if cancelled || ls.state.IsFinished() {
+			p.running = false
+			return
+		}
+	}
+}
+
+// updateCompare shows the previous pick as foreground against the last one.
+func (p *Picker) updateCompare() {
+	if len(p.picks) < 2 {
+		return
+	}
+	pair := p.picks[len(p.picks)-2:]
+	for _, ls := range p.surfaces {
+		ls.state.SetCompare(pair)
+	}
+}
+
 func (p *Picker) connect() error {
 	display, err := client.Connect("")
 	if err != nil {
@@ -345,6 +395,7 @@ func (p *Picker) createLayerSurface(output *Output) (*LayerSurface, error) {
 		layerSurf: layerSurf,
 		hidden:    true, // Start hidden, will show overlay when pointer enters
 	}
+	ls.state.SetMultiPick(p.multiPick)
 
 	if p.viewporter != nil {
 		vp, err := p.viewporter.GetViewport(surface)
@@ -46,6 +46,12 @@ type SurfaceState struct {
 	readyForDisplay bool
 	colorPicked     bool
 	cancelled       bool
+
+	// multi-pick keeps the overlay open: picks are collected until Enter,
+	// Escape or a right click finishes the session
+	multiPick bool
+	finished  bool
+	compare   []Color
 }
 
 func NewSurfaceState(format OutputFormat, lowercase bool) *SurfaceState {
@@ -56,6 +62,20 @@ func NewSurfaceState(format OutputFormat, lowercase bool) *SurfaceState {
 	}
 }
 
+func (s *SurfaceState) SetMultiPick(multi bool) {
+	s.mu.Lock()
+	s.multiPick = multi
+	s.mu.Unlock()
+}
+
+// SetCompare sets the foreground and background shown in the contrast
+// readout; fewer than two colors hide it.
+func (s *SurfaceState) SetCompare(colors []Color) {
+	s.mu.Lock()
+	s.compare = append(s.compare[:0], colors...)
+	s.mu.Unlock()
+}
+
 func (s *SurfaceState) SetScale(scale int32) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -235,6 +255,10 @@ func (s *SurfaceState) OnPointerButton(button, state uint32) {
 		if s.readyForDisplay && s.screenBuf != nil {
 			s.colorPicked = true
 		}
+	case 0x111: // BTN_RIGHT
+		if s.multiPick {
+			s.finished = true
+		}
 	}
 }
 
@@ -250,7 +274,13 @@ func (s *SurfaceState) OnKey(key, state uint32) {
 	case 1: // KEY_ESC
 		s.cancelled = true
 	case 28: // KEY_ENTER
-		if s.readyForDisplay && s.screenBuf != nil {
+		if s.multiPick {
+			s.finished = true
+		} else if s.readyForDisplay && s.screenBuf != nil {
+			s.colorPicked = true
+		}
+	case 57: // KEY_SPACE
+		if s.multiPick && s.readyForDisplay && s.screenBuf != nil {
 			s.colorPicked = true
 		}
 	}
@@ -262,6 +292,20 @@ func (s *SurfaceState) IsDone() (picked, cancelled bool) {
 	return s.colorPicked, s.cancelled
 }
 
+// IsFinished reports whether a multi-pick session was ended by the user.
+func (s *SurfaceState) IsFinished() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.finished
+}
+
+// ClearPicked re-arms the state for the next pick in multi-pick mode.
+func (s *SurfaceState) ClearPicked() {
+	s.mu.Lock()
+	s.colorPicked = false
+	s.mu.Unlock()
+}
+
 func (s *SurfaceState) IsReady() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -316,6 +360,10 @@ func (s *SurfaceState) Redraw() *ShmBuffer {
 
 	drawColorPreview(dst.Data(), dst.Stride, dst.Width, dst.Height, px, py, picked, s.displayFormat, s.lowercase, s.screenFormat)
 
+	if len(s.compare) == 2 {
+		drawContrastReadout(dst.Data(), dst.Stride, dst.Width, dst.Height, s.compare[0], s.compare[1], s.screenFormat)
+	}
+
 	return dst
 }
 
@@ -1024,6 +1072,48 @@ var fontGlyphs = map[rune][fontH]uint8{
 		0b00000000,
 		0b00000000,
 	},
+	'.': {
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00011000,
+		0b00011000,
+		0b00000000,
+		0b00000000,
+	},
+	':': {
+		0b00000000,
+		0b00000000,
+		0b00011000,
+		0b00011000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+		0b00011000,
+		0b00011000,
+		0b00000000,
+		0b00000000,
+		0b00000000,
+	},
+	'I': {
+		0b00111100,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00011000,
+		0b00111100,
+		0b00000000,
+		0b00000000,
+	},
 	' ': {
 		0b00000000,
 		0b00000000,
@@ -1087,6 +1177,32 @@ func drawColorPreview(data []byte, stride, width, height int, cx, cy int, c Colo
 	drawText(data, stride, width, height, x+paddingX, y+paddingY, text, fg, pixelFormat)
 }
 
+// drawContrastReadout draws a text sample in the foreground color on the
+// background, followed by both colors and their contrast, at the top center
+// of the surface.
+func drawContrastReadout(data []byte, stride, width, height int, fg, bg Color, pixelFormat PixelFormat) {
+	c := ContrastOf(fg, bg)
+	sample := "AA"
+	text := fmt.Sprintf("%s %s  %s", c.Foreground, c.Background, c)
+
+	const (
+		padding = 8
+		space   = 2
+		top     = 24
+	)
+
+	sampleW := len(sample)*(fontW+space) - space + 2*padding
+	textW := len(text)*(fontW+space) - space + 2*padding
+	boxH := fontH + 2*padding
+	x := max((width-sampleW-textW)/2, 0)
+
+	panel := Color{R: 30, G: 30, B: 30, A: 255}
+	drawFilledRect(data, stride, width, height, x, top, sampleW, boxH, bg, pixelFormat)
+	drawText(data, stride, width, height, x+padding, top+padding, sample, fg, pixelFormat)
+	drawFilledRect(data, stride, width, height, x+sampleW, top, textW, boxH, panel, pixelFormat)
+	drawText(data, stride, width, height, x+sampleW+padding, top+padding, text, Color{R: 255, G: 255, B: 255, A: 255}, pixelFormat)
+}
+
 func formatColorForPreview(c Color, format OutputFormat, lowercase bool) string {
 	switch format {
 	case FormatRGB:
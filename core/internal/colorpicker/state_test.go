@@ -312,3 +312,42 @@ func TestBlendColors(t *testing.T) {
 	result = blendColors(bg, fg, 2.0)
 	assert.Equal(t, fg.R, result.R)
 }
+
+func TestSurfaceState_MultiPick(t *testing.T) {
+	s := NewSurfaceState(FormatHex, false)
+	s.SetMultiPick(true)
+	s.readyForDisplay = true
+	buf, err := CreateShmBuffer(4, 4, 16)
+	if err != nil {
+		t.Skipf("shm not available: %v", err)
+	}
+	s.screenBuf = buf
+	defer s.Destroy()
+
+	s.OnPointerButton(0x110, 1)
+	picked, cancelled := s.IsDone()
+	assert.True(t, picked)
+	assert.False(t, cancelled)
+	assert.False(t, s.IsFinished())
+
+	s.ClearPicked()
+	s.OnKey(57, 1)
+	picked, _ = s.IsDone()
+	assert.True(t, picked, "space picks in multi-pick mode")
+
+	s.ClearPicked()
+	s.OnKey(28, 1)
+	picked, _ = s.IsDone()
+	assert.False(t, picked, "enter finishes instead of picking")
+	assert.True(t, s.IsFinished())
+}
+
+func TestSurfaceState_RightClickFinishesMultiPick(t *testing.T) {
+	s := NewSurfaceState(FormatHex, false)
+	s.OnPointerButton(0x111, 1)
+	assert.False(t, s.IsFinished(), "single pick ignores right click")
+
+	s.SetMultiPick(true)
+	s.OnPointerButton(0x111, 1)
+	assert.True(t, s.IsFinished())
+}
@@ -0,0 +1,129 @@
+// Package compositor detects which Wayland compositor dms is running under.
+package compositor
+
+import (
+	"os"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/dwl_ipc"
+	wlhelpers "github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/client"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+// Compositor identifies the Wayland compositor dms is running under.
+type Compositor int
+
+const (
+	Unknown Compositor = iota
+	Hyprland
+	Sway
+	Niri
+	DWL
+	Scroll
+	Miracle
+	Labwc
+)
+
+var detectedCompositor Compositor = -1
+
+func (c Compositor) String() string {
+	switch c {
+	case Hyprland:
+		return "hyprland"
+	case Sway:
+		return "sway"
+	case Niri:
+		return "niri"
+	case DWL:
+		return "dwl"
+	case Scroll:
+		return "scroll"
+	case Miracle:
+		return "miracle-wm"
+	case Labwc:
+		return "labwc"
+	default:
+		return "unknown"
+	}
+}
+
+// Detect returns the running compositor. The result is cached for the life
+// of the process.
+func Detect() Compositor {
+	if detectedCompositor >= 0 {
+		return detectedCompositor
+	}
+
+	hyprlandSig := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	niriSocket := os.Getenv("NIRI_SOCKET")
+	swaySocket := os.Getenv("SWAYSOCK")
+	scrollSocket := os.Getenv("SCROLLSOCK")
+	miracleSocket := os.Getenv("MIRACLESOCK")
+
+	switch {
+	case niriSocket != "":
+		if _, err := os.Stat(niriSocket); err == nil {
+			detectedCompositor = Niri
+			return detectedCompositor
+		}
+	case scrollSocket != "":
+		if _, err := os.Stat(scrollSocket); err == nil {
+			detectedCompositor = Scroll
+			return detectedCompositor
+		}
+	case miracleSocket != "":
+		if _, err := os.Stat(miracleSocket); err == nil {
+			detectedCompositor = Miracle
+			return detectedCompositor
+		}
+	case swaySocket != "":
+		if _, err := os.Stat(swaySocket); err == nil {
+			detectedCompositor = Sway
+			return detectedCompositor
+		}
+	case hyprlandSig != "":
+		detectedCompositor = Hyprland
+		return detectedCompositor
+	case os.Getenv("LABWC_PID") != "":
+		detectedCompositor = Labwc
+		return detectedCompositor
+	}
+
+	if detectDWLProtocol() {
+		detectedCompositor = DWL
+		return detectedCompositor
+	}
+
+	detectedCompositor = Unknown
+	return detectedCompositor
+}
+
+func detectDWLProtocol() bool {
+	display, err := client.Connect("")
+	if err != nil {
+		return false
+	}
+	ctx := display.Context()
+	defer ctx.Close()
+
+	registry, err := display.GetRegistry()
+	if err != nil {
+		return false
+	}
+
+	found := false
+	registry.SetGlobalHandler(func(e client.RegistryGlobalEvent) {
+		if e.Interface == dwl_ipc.ZdwlIpcManagerV2InterfaceName {
+			found = true
+		}
+	})
+
+	if err := wlhelpers.Roundtrip(display, ctx); err != nil {
+		return false
+	}
+
+	return found
+}
+
+func SetDWL() {
+	detectedCompositor = DWL
+}
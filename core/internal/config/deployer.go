@@ -2,18 +2,24 @@ package config
 
 import (
 	"context"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/deps"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
 )
 
 type ConfigDeployer struct {
-	logChan chan<- string
+	logChan     chan<- string
+	stateDir    string
+	resolutions map[string]bool
 }
 
 type DeploymentResult struct {
@@ -21,6 +27,7 @@ type DeploymentResult struct {
 	Path       string
 	BackupPath string
 	Deployed   bool
+	Conflicts  []MergeConflict
 	Error      error
 }
 
@@ -87,6 +94,23 @@ func (cd *ConfigDeployer) deployConfigurationsInternal(ctx context.Context, wm d
 				return results, fmt.Errorf("failed to deploy Hyprland config: %w", err)
 			}
 		}
+	case deps.WindowManagerLabwc:
+		if shouldReplaceConfig("labwc") {
+			result, err := cd.deployLabwcConfig(terminal, useSystemd)
+			results = append(results, result)
+			if err != nil {
+				return results, fmt.Errorf("failed to deploy labwc config: %w", err)
+			}
+		}
+	case deps.WindowManagerSway, deps.WindowManagerMangoWC, deps.WindowManagerMiracle:
+		c, _ := cd.deployedConfigFor(wm)
+		if shouldReplaceConfig(c.configType) {
+			result, err := cd.deployIncludeConfig(wm, terminal, useSystemd)
+			results = append(results, result)
+			if err != nil {
+				return results, fmt.Errorf("failed to deploy %s config: %w", c.configType, err)
+			}
+		}
 	}
 
 	switch terminal {
@@ -157,27 +181,16 @@ func (cd *ConfigDeployer) deployNiriConfig(terminal deps.Terminal, useSystemd bo
 		cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
 	}
 
-	var terminalCommand string
-	switch terminal {
-	case deps.TerminalGhostty:
-		terminalCommand = "ghostty"
-	case deps.TerminalKitty:
-		terminalCommand = "kitty"
-	case deps.TerminalAlacritty:
-		terminalCommand = "alacritty"
-	default:
-		terminalCommand = "ghostty"
-	}
-
-	newConfig := strings.ReplaceAll(NiriConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
-
-	if !useSystemd {
-		newConfig = cd.transformNiriConfigForNonSystemd(newConfig, terminalCommand)
-	}
+	terminalCommand := terminalCommandFor(terminal)
+	template := cd.niriTemplate(terminalCommand, useSystemd)
+	newConfig := template
 
 	if existingConfig != "" {
-		mergedConfig, err := cd.mergeNiriOutputSections(newConfig, existingConfig, dmsDir)
-		if err != nil {
+		if merged, conflicts, ok := cd.mergeWithTemplate(result.ConfigType, result.Path, existingConfig, template); ok {
+			newConfig = merged
+			result.Conflicts = conflicts
+			cd.logMerge(conflicts)
+		} else if mergedConfig, err := cd.mergeNiriOutputSections(newConfig, existingConfig, dmsDir); err != nil {
 			cd.log(fmt.Sprintf("Warning: Failed to merge output sections: %v", err))
 		} else {
 			newConfig = mergedConfig
@@ -185,12 +198,15 @@ func (cd *ConfigDeployer) deployNiriConfig(terminal deps.Terminal, useSystemd bo
 		}
 	}
 
-	if err := os.WriteFile(result.Path, []byte(newConfig), 0o644); err != nil {
+	if err := snapshot.WriteFile(result.Path, []byte(newConfig), 0o644); err != nil {
 		result.Error = fmt.Errorf("failed to write config: %w", err)
 		return result, result.Error
 	}
+	if err := cd.recordDeployment(result.Path, result.ConfigType, template, newConfig, terminal, useSystemd); err != nil {
+		cd.log(fmt.Sprintf("Warning: Failed to record deployed template: %v", err))
+	}
 
-	if err := cd.deployNiriDmsConfigs(dmsDir, terminalCommand); err != nil {
+	if err := cd.deployDmsFiles(dmsDir, niriDmsFiles(terminalCommand)); err != nil {
 		result.Error = fmt.Errorf("failed to deploy dms configs: %w", err)
 		return result, result.Error
 	}
@@ -200,34 +216,15 @@ func (cd *ConfigDeployer) deployNiriConfig(terminal deps.Terminal, useSystemd bo
 	return result, nil
 }
 
-func (cd *ConfigDeployer) deployNiriDmsConfigs(dmsDir, terminalCommand string) error {
-	configs := []struct {
-		name    string
-		content string
-	}{
-		{"colors.kdl", NiriColorsConfig},
-		{"layout.kdl", NiriLayoutConfig},
-		{"alttab.kdl", NiriAlttabConfig},
-		{"binds.kdl", strings.ReplaceAll(NiriBindsConfig, "{{TERMINAL_COMMAND}}", terminalCommand)},
-		{"outputs.kdl", ""},
-		{"cursor.kdl", ""},
-		{"windowrules.kdl", ""},
-	}
-
-	for _, cfg := range configs {
-		path := filepath.Join(dmsDir, cfg.name)
-		// Skip if file already exists and is not empty to preserve user modifications
-		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
-			cd.log(fmt.Sprintf("Skipping %s (already exists)", cfg.name))
-			continue
-		}
-		if err := os.WriteFile(path, []byte(cfg.content), 0o644); err != nil {
-			return fmt.Errorf("failed to write %s: %w", cfg.name, err)
+func (cd *ConfigDeployer) logMerge(conflicts []MergeConflict) {
+	cd.log("Merged your changes with the updated template")
+	for _, c := range conflicts {
+		if cd.resolutions[c.ID] {
+			cd.log(fmt.Sprintf("Conflict in %s: took the updated template", c.Section))
+		} else {
+			cd.log(fmt.Sprintf("Conflict in %s: kept your version", c.Section))
 		}
-		cd.log(fmt.Sprintf("Deployed %s", cfg.name))
 	}
-
-	return nil
 }
 
 func (cd *ConfigDeployer) deployGhosttyConfig() ([]DeploymentResult, error) {
@@ -262,7 +259,7 @@ func (cd *ConfigDeployer) deployGhosttyConfig() ([]DeploymentResult, error) {
 		cd.log(fmt.Sprintf("Backed up existing config to %s", mainResult.BackupPath))
 	}
 
-	if err := os.WriteFile(mainResult.Path, []byte(GhosttyConfig), 0o644); err != nil {
+	if err := snapshot.WriteFile(mainResult.Path, []byte(GhosttyConfig), 0o644); err != nil {
 		mainResult.Error = fmt.Errorf("failed to write config: %w", err)
 		return []DeploymentResult{mainResult}, mainResult.Error
 	}
@@ -282,7 +279,7 @@ func (cd *ConfigDeployer) deployGhosttyConfig() ([]DeploymentResult, error) {
 		return []DeploymentResult{mainResult}, mainResult.Error
 	}
 
-	if err := os.WriteFile(colorResult.Path, []byte(GhosttyColorConfig), 0o644); err != nil {
+	if err := snapshot.WriteFile(colorResult.Path, []byte(GhosttyColorConfig), 0o644); err != nil {
 		colorResult.Error = fmt.Errorf("failed to write color config: %w", err)
 		return results, colorResult.Error
 	}
@@ -326,7 +323,7 @@ func (cd *ConfigDeployer) deployKittyConfig() ([]DeploymentResult, error) {
 		cd.log(fmt.Sprintf("Backed up existing config to %s", mainResult.BackupPath))
 	}
 
-	if err := os.WriteFile(mainResult.Path, []byte(KittyConfig), 0o644); err != nil {
+	if err := snapshot.WriteFile(mainResult.Path, []byte(KittyConfig), 0o644); err != nil {
 		mainResult.Error = fmt.Errorf("failed to write config: %w", err)
 		return []DeploymentResult{mainResult}, mainResult.Error
 	}
@@ -340,7 +337,7 @@ func (cd *ConfigDeployer) deployKittyConfig() ([]DeploymentResult, error) {
 		Path:       filepath.Join(os.Getenv("HOME"), ".config", "kitty", "dank-theme.conf"),
 	}
 
-	if err := os.WriteFile(themeResult.Path, []byte(KittyThemeConfig), 0o644); err != nil {
+	if err := snapshot.WriteFile(themeResult.Path, []byte(KittyThemeConfig), 0o644); err != nil {
 		themeResult.Error = fmt.Errorf("failed to write theme config: %w", err)
 		return results, themeResult.Error
 	}
@@ -354,7 +351,7 @@ func (cd *ConfigDeployer) deployKittyConfig() ([]DeploymentResult, error) {
 		Path:       filepath.Join(os.Getenv("HOME"), ".config", "kitty", "dank-tabs.conf"),
 	}
 
-	if err := os.WriteFile(tabsResult.Path, []byte(KittyTabsConfig), 0o644); err != nil {
+	if err := snapshot.WriteFile(tabsResult.Path, []byte(KittyTabsConfig), 0o644); err != nil {
 		tabsResult.Error = fmt.Errorf("failed to write tabs config: %w", err)
 		return results, tabsResult.Error
 	}
@@ -398,7 +395,7 @@ func (cd *ConfigDeployer) deployAlacrittyConfig() ([]DeploymentResult, error) {
 		cd.log(fmt.Sprintf("Backed up existing config to %s", mainResult.BackupPath))
 	}
 
-	if err := os.WriteFile(mainResult.Path, []byte(AlacrittyConfig), 0o644); err != nil {
+	if err := snapshot.WriteFile(mainResult.Path, []byte(AlacrittyConfig), 0o644); err != nil {
 		mainResult.Error = fmt.Errorf("failed to write config: %w", err)
 		return []DeploymentResult{mainResult}, mainResult.Error
 	}
@@ -412,7 +409,7 @@ func (cd *ConfigDeployer) deployAlacrittyConfig() ([]DeploymentResult, error) {
 		Path:       filepath.Join(os.Getenv("HOME"), ".config", "alacritty", "dank-theme.toml"),
 	}
 
-	if err := os.WriteFile(themeResult.Path, []byte(AlacrittyThemeConfig), 0o644); err != nil {
+	if err := snapshot.WriteFile(themeResult.Path, []byte(AlacrittyThemeConfig), 0o644); err != nil {
 		themeResult.Error = fmt.Errorf("failed to write theme config: %w", err)
 		return results, themeResult.Error
 	}
@@ -439,7 +436,7 @@ func (cd *ConfigDeployer) mergeNiriOutputSections(newConfig, existingConfig, dms
 			outputsContent.WriteString(output)
 			outputsContent.WriteString("\n\n")
 		}
-		if err := os.WriteFile(outputsPath, []byte(outputsContent.String()), 0o644); err != nil {
+		if err := snapshot.WriteFile(outputsPath, []byte(outputsContent.String()), 0o644); err != nil {
 			cd.log(fmt.Sprintf("Warning: Failed to migrate outputs to %s: %v", outputsPath, err))
 		} else {
 			cd.log("Migrated output sections to dms/outputs.kdl")
@@ -511,27 +508,16 @@ func (cd *ConfigDeployer) deployHyprlandConfig(terminal deps.Terminal, useSystem
 		cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
 	}
 
-	var terminalCommand string
-	switch terminal {
-	case deps.TerminalGhostty:
-		terminalCommand = "ghostty"
-	case deps.TerminalKitty:
-		terminalCommand = "kitty"
-	case deps.TerminalAlacritty:
-		terminalCommand = "alacritty"
-	default:
-		terminalCommand = "ghostty"
-	}
-
-	newConfig := strings.ReplaceAll(HyprlandConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
-
-	if !useSystemd {
-		newConfig = cd.transformHyprlandConfigForNonSystemd(newConfig, terminalCommand)
-	}
+	terminalCommand := terminalCommandFor(terminal)
+	template := cd.hyprlandTemplate(terminalCommand, useSystemd)
+	newConfig := template
 
 	if existingConfig != "" {
-		mergedConfig, err := cd.mergeHyprlandMonitorSections(newConfig, existingConfig, dmsDir)
-		if err != nil {
+		if merged, conflicts, ok := cd.mergeWithTemplate(result.ConfigType, result.Path, existingConfig, template); ok {
+			newConfig = merged
+			result.Conflicts = conflicts
+			cd.logMerge(conflicts)
+		} else if mergedConfig, err := cd.mergeHyprlandMonitorSections(newConfig, existingConfig, dmsDir); err != nil {
 			cd.log(fmt.Sprintf("Warning: Failed to merge monitor sections: %v", err))
 		} else {
 			newConfig = mergedConfig
@@ -539,12 +525,15 @@ func (cd *ConfigDeployer) deployHyprlandConfig(terminal deps.Terminal, useSystem
 		}
 	}
 
-	if err := os.WriteFile(result.Path, []byte(newConfig), 0o644); err != nil {
+	if err := snapshot.WriteFile(result.Path, []byte(newConfig), 0o644); err != nil {
 		result.Error = fmt.Errorf("failed to write config: %w", err)
 		return result, result.Error
 	}
+	if err := cd.recordDeployment(result.Path, result.ConfigType, template, newConfig, terminal, useSystemd); err != nil {
+		cd.log(fmt.Sprintf("Warning: Failed to record deployed template: %v", err))
+	}
 
-	if err := cd.deployHyprlandDmsConfigs(dmsDir, terminalCommand); err != nil {
+	if err := cd.deployDmsFiles(dmsDir, hyprlandDmsFiles(terminalCommand)); err != nil {
 		result.Error = fmt.Errorf("failed to deploy dms configs: %w", err)
 		return result, result.Error
 	}
@@ -554,35 +543,6 @@ func (cd *ConfigDeployer) deployHyprlandConfig(terminal deps.Terminal, useSystem
 	return result, nil
 }
 
-func (cd *ConfigDeployer) deployHyprlandDmsConfigs(dmsDir string, terminalCommand string) error {
-	configs := []struct {
-		name    string
-		content string
-	}{
-		{"colors.conf", HyprColorsConfig},
-		{"layout.conf", HyprLayoutConfig},
-		{"binds.conf", strings.ReplaceAll(HyprBindsConfig, "{{TERMINAL_COMMAND}}", terminalCommand)},
-		{"outputs.conf", ""},
-		{"cursor.conf", ""},
-		{"windowrules.conf", ""},
-	}
-
-	for _, cfg := range configs {
-		path := filepath.Join(dmsDir, cfg.name)
-		// Skip if file already exists and is not empty to preserve user modifications
-		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
-			cd.log(fmt.Sprintf("Skipping %s (already exists)", cfg.name))
-			continue
-		}
-		if err := os.WriteFile(path, []byte(cfg.content), 0o644); err != nil {
-			return fmt.Errorf("failed to write %s: %w", cfg.name, err)
-		}
-		cd.log(fmt.Sprintf("Deployed %s", cfg.name))
-	}
-
-	return nil
-}
-
 func (cd *ConfigDeployer) mergeHyprlandMonitorSections(newConfig, existingConfig, dmsDir string) (string, error) {
 	monitorRegex := regexp.MustCompile(`(?m)^#?\s*monitor\s*=.*$`)
 	existingMonitors := monitorRegex.FindAllString(existingConfig, -1)
@@ -598,7 +558,7 @@ func (cd *ConfigDeployer) mergeHyprlandMonitorSections(newConfig, existingConfig
 			outputsContent.WriteString(monitor)
 			outputsContent.WriteString("\n")
 		}
-		if err := os.WriteFile(outputsPath, []byte(outputsContent.String()), 0o644); err != nil {
+		if err := snapshot.WriteFile(outputsPath, []byte(outputsContent.String()), 0o644); err != nil {
 			cd.log(fmt.Sprintf("Warning: Failed to migrate monitors to %s: %v", outputsPath, err))
 		} else {
 			cd.log("Migrated monitor sections to dms/outputs.conf")
@@ -697,3 +657,601 @@ func (cd *ConfigDeployer) transformNiriConfigForNonSystemd(config, terminalComma
 
 	return config
 }
+
+// deployLabwcConfig deploys rc.xml, autostart and environment. labwc has no
+// include mechanism, so existing settings are merged into the new files
+// rather than split out into a dms directory.
+func (cd *ConfigDeployer) deployLabwcConfig(terminal deps.Terminal, useSystemd bool) (DeploymentResult, error) {
+	result := DeploymentResult{
+		ConfigType: "labwc",
+		Path:       filepath.Join(os.Getenv("HOME"), ".config", "labwc", "rc.xml"),
+	}
+
+	configDir := filepath.Dir(result.Path)
+	if err := os.MkdirAll(configDir, 0o755); err != nil {
+		result.Error = fmt.Errorf("failed to create config directory: %w", err)
+		return result, result.Error
+	}
+
+	var terminalCommand string
+	switch terminal {
+	case deps.TerminalGhostty:
+		terminalCommand = "ghostty"
+	case deps.TerminalKitty:
+		terminalCommand = "kitty"
+	case deps.TerminalAlacritty:
+		terminalCommand = "alacritty"
+	default:
+		terminalCommand = "ghostty"
+	}
+
+	autostart := LabwcAutostartConfig
+	if !useSystemd {
+		autostart = cd.transformLabwcAutostartForNonSystemd(autostart)
+	}
+
+	files := []struct {
+		name     string
+		content  string
+		merge    func(newConfig, existingConfig string) (string, error)
+		isRcFile bool
+	}{
+		{"rc.xml", strings.ReplaceAll(LabwcRcConfig, "{{TERMINAL_COMMAND}}", terminalCommand), cd.mergeLabwcRcConfig, true},
+		{"autostart", autostart, mergeLabwcAutostart, false},
+		{"environment", strings.ReplaceAll(LabwcEnvironmentConfig, "{{TERMINAL_COMMAND}}", terminalCommand), mergeLabwcEnvironment, false},
+	}
+
+	for _, file := range files {
+		path := filepath.Join(configDir, file.name)
+		newConfig := file.content
+
+		if existingData, err := os.ReadFile(path); err == nil {
+			cd.log(fmt.Sprintf("Found existing labwc %s", file.name))
+
+			timestamp := time.Now().Format("2006-01-02_15-04-05")
+			backupPath := path + ".backup." + timestamp
+			if err := os.WriteFile(backupPath, existingData, 0o644); err != nil {
+				result.Error = fmt.Errorf("failed to create backup of %s: %w", file.name, err)
+				return result, result.Error
+			}
+			cd.log(fmt.Sprintf("Backed up existing %s to %s", file.name, backupPath))
+			if file.isRcFile {
+				result.BackupPath = backupPath
+			}
+
+			merged, err := file.merge(newConfig, string(existingData))
+			if err != nil {
+				cd.log(fmt.Sprintf("Warning: Failed to merge existing %s: %v", file.name, err))
+			} else {
+				newConfig = merged
+				cd.log(fmt.Sprintf("Successfully merged existing %s", file.name))
+			}
+		}
+
+		if err := snapshot.WriteFile(path, []byte(newConfig), 0o644); err != nil {
+			result.Error = fmt.Errorf("failed to write %s: %w", file.name, err)
+			return result, result.Error
+		}
+	}
+
+	result.Deployed = true
+	cd.log("Successfully deployed labwc configuration")
+	return result, nil
+}
+
+func (cd *ConfigDeployer) transformLabwcAutostartForNonSystemd(config string) string {
+	var result []string
+	for _, line := range strings.Split(config, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "dbus-update-activation-environment"):
+			continue
+		case strings.HasPrefix(trimmed, "systemctl --user start"):
+			result = append(result, "dms run &")
+			continue
+		}
+		result = append(result, line)
+	}
+	return strings.Join(result, "\n")
+}
+
+// labwcElement is a direct child of an XML root element, with its byte range
+// in the source so it can be copied over verbatim.
+type labwcElement struct {
+	name  string
+	key   string
+	start int
+	end   int
+}
+
+func splitLabwcElements(content string) ([]labwcElement, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	depth := 0
+	var elements []labwcElement
+
+	for {
+		start := int(decoder.InputOffset())
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				depth++
+				continue
+			}
+			el := labwcElement{name: t.Name.Local, start: start}
+			for _, attr := range t.Attr {
+				if attr.Name.Local == "key" {
+					el.key = labwcKeyID(attr.Value)
+				}
+			}
+			if err := decoder.Skip(); err != nil {
+				return nil, err
+			}
+			el.end = int(decoder.InputOffset())
+			elements = append(elements, el)
+		case xml.EndElement:
+			depth--
+		}
+	}
+
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced XML document")
+	}
+	return elements, nil
+}
+
+// labwcKeyID gives a comparable form of a labwc key, ignoring modifier
+// order and keysym case.
+func labwcKeyID(key string) string {
+	parts := strings.Split(key, "-")
+	mods := parts[:len(parts)-1]
+	sort.Strings(mods)
+	return strings.ToLower(strings.Join(append(mods, parts[len(parts)-1]), "-"))
+}
+
+// mergeLabwcRcConfig keeps the user's top-level sections over the template's,
+// except for the keyboard where the DMS binds win and the user's binds on
+// other keys are carried over.
+func (cd *ConfigDeployer) mergeLabwcRcConfig(newConfig, existingConfig string) (string, error) {
+	existing, err := splitLabwcElements(existingConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse existing rc.xml: %w", err)
+	}
+	template, err := splitLabwcElements(newConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse rc.xml template: %w", err)
+	}
+
+	existingByName := make(map[string]labwcElement)
+	for _, el := range existing {
+		if _, ok := existingByName[el.name]; !ok {
+			existingByName[el.name] = el
+		}
+	}
+	templateNames := make(map[string]bool)
+	for _, el := range template {
+		templateNames[el.name] = true
+	}
+
+	var builder strings.Builder
+	pos := 0
+	for _, el := range template {
+		builder.WriteString(newConfig[pos:el.start])
+		pos = el.end
+
+		user, ok := existingByName[el.name]
+		switch {
+		case !ok:
+			builder.WriteString(newConfig[el.start:el.end])
+		case el.name == "keyboard":
+			keyboard, err := mergeLabwcKeyboard(newConfig[el.start:el.end], existingConfig[user.start:user.end])
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(keyboard)
+		default:
+			builder.WriteString(existingConfig[user.start:user.end])
+		}
+	}
+
+	closing := strings.LastIndex(newConfig, "</labwc_config>")
+	if closing < pos {
+		return "", fmt.Errorf("could not find </labwc_config> in template")
+	}
+	builder.WriteString(newConfig[pos:closing])
+
+	var extra []string
+	for _, el := range existing {
+		if !templateNames[el.name] {
+			extra = append(extra, "  "+existingConfig[el.start:el.end])
+		}
+	}
+	if len(extra) > 0 {
+		builder.WriteString("  <!-- Sections from existing configuration -->\n")
+		builder.WriteString(strings.Join(extra, "\n\n"))
+		builder.WriteString("\n\n")
+	}
+
+	builder.WriteString(newConfig[closing:])
+	return builder.String(), nil
+}
+
+func mergeLabwcKeyboard(newKeyboard, existingKeyboard string) (string, error) {
+	template, err := splitLabwcElements(newKeyboard)
+	if err != nil {
+		return "", err
+	}
+	existing, err := splitLabwcElements(existingKeyboard)
+	if err != nil {
+		return "", err
+	}
+
+	dmsKeys := make(map[string]bool)
+	for _, el := range template {
+		if el.name == "keybind" {
+			dmsKeys[el.key] = true
+		}
+	}
+
+	var carried []string
+	for _, el := range existing {
+		switch {
+		case el.name == "default":
+			// labwc's built-in binds would shadow the DMS ones
+			continue
+		case el.name == "keybind" && dmsKeys[el.key]:
+			continue
+		}
+		carried = append(carried, "    "+existingKeyboard[el.start:el.end])
+	}
+	if len(carried) == 0 {
+		return newKeyboard, nil
+	}
+
+	closing := strings.LastIndex(newKeyboard, "</keyboard>")
+	insertPos := strings.LastIndex(newKeyboard[:closing], "\n") + 1
+
+	var builder strings.Builder
+	builder.WriteString(newKeyboard[:insertPos])
+	builder.WriteString("\n    <!-- Keybinds from existing configuration -->\n")
+	builder.WriteString(strings.Join(carried, "\n"))
+	builder.WriteString("\n")
+	builder.WriteString(newKeyboard[insertPos:])
+	return builder.String(), nil
+}
+
+func isLabwcDMSLaunch(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "dms run") || strings.HasPrefix(trimmed, "systemctl --user start dms")
+}
+
+// mergeLabwcAutostart keeps the user's autostart and appends the DMS
+// commands it does not already run. A DMS launch line for the other session
+// mode is dropped so the shell is not started twice.
+func mergeLabwcAutostart(newConfig, existingConfig string) (string, error) {
+	wanted := make(map[string]bool)
+	for _, line := range strings.Split(newConfig, "\n") {
+		wanted[strings.TrimSpace(line)] = true
+	}
+
+	var kept []string
+	present := make(map[string]bool)
+	for _, line := range strings.Split(existingConfig, "\n") {
+		if isLabwcDMSLaunch(line) && !wanted[strings.TrimSpace(line)] {
+			continue
+		}
+		kept = append(kept, line)
+		present[strings.TrimSpace(line)] = true
+	}
+	existingConfig = strings.Join(kept, "\n")
+
+	var missing []string
+	for _, line := range strings.Split(newConfig, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || present[trimmed] {
+			continue
+		}
+		missing = append(missing, line)
+	}
+	if len(missing) == 0 {
+		return existingConfig, nil
+	}
+
+	merged := strings.TrimRight(existingConfig, "\n")
+	if merged != "" {
+		merged += "\n\n"
+	}
+	merged += "# DankMaterialShell\n" + strings.Join(missing, "\n") + "\n"
+	return merged, nil
+}
+
+// mergeLabwcEnvironment keeps every variable the user already sets and adds
+// the DMS defaults for the rest.
+func mergeLabwcEnvironment(newConfig, existingConfig string) (string, error) {
+	present := make(map[string]bool)
+	for _, line := range strings.Split(existingConfig, "\n") {
+		if name, _, ok := strings.Cut(strings.TrimSpace(line), "="); ok && !strings.HasPrefix(name, "#") {
+			present[strings.TrimSpace(name)] = true
+		}
+	}
+
+	var missing []string
+	for _, line := range strings.Split(newConfig, "\n") {
+		name, _, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || strings.HasPrefix(name, "#") || present[name] {
+			continue
+		}
+		missing = append(missing, line)
+	}
+	if len(missing) == 0 {
+		return existingConfig, nil
+	}
+
+	merged := strings.TrimRight(existingConfig, "\n")
+	if merged != "" {
+		merged += "\n"
+	}
+	return merged + strings.Join(missing, "\n") + "\n", nil
+}
+
+type dmsConfigFile struct {
+	name    string
+	content string
+}
+
+func niriDmsFiles(terminalCommand string) []dmsConfigFile {
+	return []dmsConfigFile{
+		{"colors.kdl", NiriColorsConfig},
+		{"layout.kdl", NiriLayoutConfig},
+		{"alttab.kdl", NiriAlttabConfig},
+		{"binds.kdl", strings.ReplaceAll(NiriBindsConfig, "{{TERMINAL_COMMAND}}", terminalCommand)},
+		{"outputs.kdl", ""},
+		{"cursor.kdl", ""},
+		{"windowrules.kdl", ""},
+	}
+}
+
+func hyprlandDmsFiles(terminalCommand string) []dmsConfigFile {
+	return []dmsConfigFile{
+		{"colors.conf", HyprColorsConfig},
+		{"layout.conf", HyprLayoutConfig},
+		{"binds.conf", strings.ReplaceAll(HyprBindsConfig, "{{TERMINAL_COMMAND}}", terminalCommand)},
+		{"outputs.conf", ""},
+		{"cursor.conf", ""},
+		{"windowrules.conf", ""},
+	}
+}
+
+// includeDeployment describes a compositor whose main config includes DMS
+// managed files from a dms directory next to it.
+type includeDeployment struct {
+	files func(terminalCommand string) []dmsConfigFile
+	// sections of an existing config moved to outputsFile when there is no
+	// recorded template to merge against
+	outputsFile   string
+	outputsPrefix string
+}
+
+var includeDeployments = map[deps.WindowManager]includeDeployment{
+	deps.WindowManagerSway: {
+		files: func(terminalCommand string) []dmsConfigFile {
+			return []dmsConfigFile{
+				{"colors.conf", SwayColorsConfig},
+				{"layout.conf", SwayLayoutConfig},
+				{"binds.conf", strings.ReplaceAll(SwayBindsConfig, "{{TERMINAL_COMMAND}}", terminalCommand)},
+				{"outputs.conf", ""},
+				{"cursor.conf", ""},
+				{"windowrules.conf", ""},
+			}
+		},
+		outputsFile:   "outputs.conf",
+		outputsPrefix: "output ",
+	},
+	deps.WindowManagerMangoWC: {
+		files: func(terminalCommand string) []dmsConfigFile {
+			return []dmsConfigFile{
+				{"colors.conf", MangoWCColorsConfig},
+				{"layout.conf", MangoWCLayoutConfig},
+				{"binds.conf", strings.ReplaceAll(MangoWCBindsConfig, "{{TERMINAL_COMMAND}}", terminalCommand)},
+				{"outputs.conf", ""},
+				{"cursor.conf", ""},
+				{"windowrules.conf", ""},
+			}
+		},
+		outputsFile:   "outputs.conf",
+		outputsPrefix: "monitorrule",
+	},
+	deps.WindowManagerMiracle: {
+		files: func(terminalCommand string) []dmsConfigFile {
+			return []dmsConfigFile{
+				{"colors.yaml", MiracleColorsConfig},
+				{"layout.yaml", MiracleLayoutConfig},
+				{"binds.yaml", strings.ReplaceAll(MiracleBindsConfig, "{{TERMINAL_COMMAND}}", terminalCommand)},
+			}
+		},
+	},
+}
+
+// deployIncludeConfig deploys the main config of sway, MangoWC or miracle-wm
+// along with the DMS managed files it includes.
+func (cd *ConfigDeployer) deployIncludeConfig(wm deps.WindowManager, terminal deps.Terminal, useSystemd bool) (DeploymentResult, error) {
+	c, ok := cd.deployedConfigFor(wm)
+	spec, hasSpec := includeDeployments[wm]
+	if !ok || !hasSpec {
+		return DeploymentResult{}, fmt.Errorf("no config template for window manager %d", wm)
+	}
+
+	result := DeploymentResult{
+		ConfigType: c.configType,
+		Path:       c.path,
+	}
+
+	configDir := filepath.Dir(result.Path)
+	dmsDir := filepath.Join(configDir, "dms")
+	if err := os.MkdirAll(dmsDir, 0o755); err != nil {
+		result.Error = fmt.Errorf("failed to create dms directory: %w", err)
+		return result, result.Error
+	}
+
+	var existingConfig string
+	if existingData, err := os.ReadFile(result.Path); err == nil {
+		cd.log(fmt.Sprintf("Found existing %s configuration", c.configType))
+		existingConfig = string(existingData)
+
+		timestamp := time.Now().Format("2006-01-02_15-04-05")
+		result.BackupPath = result.Path + ".backup." + timestamp
+		if err := os.WriteFile(result.BackupPath, existingData, 0o644); err != nil {
+			result.Error = fmt.Errorf("failed to create backup: %w", err)
+			return result, result.Error
+		}
+		cd.log(fmt.Sprintf("Backed up existing config to %s", result.BackupPath))
+	}
+
+	terminalCommand := terminalCommandFor(terminal)
+	template := c.template(terminalCommand, useSystemd)
+	newConfig := template
+
+	if existingConfig != "" {
+		if merged, conflicts, ok := cd.mergeWithTemplate(c.configType, result.Path, existingConfig, template); ok {
+			newConfig = merged
+			result.Conflicts = conflicts
+			cd.logMerge(conflicts)
+		} else if spec.outputsFile != "" {
+			cd.migrateOutputSections(c.split(existingConfig), spec.outputsPrefix, filepath.Join(dmsDir, spec.outputsFile))
+		}
+	}
+
+	if err := snapshot.WriteFile(result.Path, []byte(newConfig), 0o644); err != nil {
+		result.Error = fmt.Errorf("failed to write config: %w", err)
+		return result, result.Error
+	}
+	if err := cd.recordDeployment(result.Path, result.ConfigType, template, newConfig, terminal, useSystemd); err != nil {
+		cd.log(fmt.Sprintf("Warning: Failed to record deployed template: %v", err))
+	}
+
+	if err := cd.deployDmsFiles(dmsDir, spec.files(terminalCommand)); err != nil {
+		result.Error = fmt.Errorf("failed to deploy dms configs: %w", err)
+		return result, result.Error
+	}
+
+	result.Deployed = true
+	cd.log(fmt.Sprintf("Successfully deployed %s configuration", c.configType))
+	return result, nil
+}
+
+// migrateOutputSections keeps the output configuration of a config that is
+// being replaced by moving it to the dms outputs file, unless that already
+// exists.
+func (cd *ConfigDeployer) migrateOutputSections(sections []configSection, prefix, outputsPath string) {
+	if _, err := os.Stat(outputsPath); err == nil {
+		return
+	}
+
+	var outputs strings.Builder
+	for _, s := range sections {
+		if !strings.HasPrefix(s.Key, prefix) {
+			continue
+		}
+		outputs.WriteString(strings.TrimLeft(s.Text, "\n"))
+		if !strings.HasSuffix(s.Text, "\n") {
+			outputs.WriteString("\n")
+		}
+	}
+	if outputs.Len() == 0 {
+		return
+	}
+
+	if err := snapshot.WriteFile(outputsPath, []byte(outputs.String()), 0o644); err != nil {
+		cd.log(fmt.Sprintf("Warning: Failed to migrate outputs to %s: %v", outputsPath, err))
+		return
+	}
+	cd.log(fmt.Sprintf("Migrated output sections to dms/%s", filepath.Base(outputsPath)))
+}
+
+func (cd *ConfigDeployer) deployDmsFiles(dmsDir string, files []dmsConfigFile) error {
+	for _, cfg := range files {
+		path := filepath.Join(dmsDir, cfg.name)
+		// Skip if file already exists and is not empty to preserve user modifications
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			cd.log(fmt.Sprintf("Skipping %s (already exists)", cfg.name))
+			continue
+		}
+		if err := snapshot.WriteFile(path, []byte(cfg.content), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", cfg.name, err)
+		}
+		cd.log(fmt.Sprintf("Deployed %s", cfg.name))
+	}
+	return nil
+}
+
+func (cd *ConfigDeployer) niriTemplate(terminalCommand string, useSystemd bool) string {
+	config := strings.ReplaceAll(NiriConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
+	if !useSystemd {
+		config = cd.transformNiriConfigForNonSystemd(config, terminalCommand)
+	}
+	return config
+}
+
+func (cd *ConfigDeployer) hyprlandTemplate(terminalCommand string, useSystemd bool) string {
+	config := strings.ReplaceAll(HyprlandConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
+	if !useSystemd {
+		config = cd.transformHyprlandConfigForNonSystemd(config, terminalCommand)
+	}
+	return config
+}
+
+func (cd *ConfigDeployer) swayTemplate(terminalCommand string, useSystemd bool) string {
+	config := strings.ReplaceAll(SwayConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
+	if !useSystemd {
+		config = replaceSystemdStartup(config, "exec ", "exec dms run")
+	}
+	return config
+}
+
+func (cd *ConfigDeployer) mangoTemplate(terminalCommand string, useSystemd bool) string {
+	config := strings.ReplaceAll(MangoWCConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
+	if !useSystemd {
+		config = replaceSystemdStartup(config, "exec-once=",
+			"exec-once=dms run",
+			"env=QT_QPA_PLATFORM,wayland;xcb",
+			"env=ELECTRON_OZONE_PLATFORM_HINT,auto",
+			"env=QT_QPA_PLATFORMTHEME,gtk3",
+			"env=QT_QPA_PLATFORMTHEME_QT6,gtk3",
+			"env=TERMINAL,"+terminalCommand,
+		)
+	}
+	return config
+}
+
+func (cd *ConfigDeployer) miracleTemplate(terminalCommand string, useSystemd bool) string {
+	config := strings.ReplaceAll(MiracleConfig, "{{TERMINAL_COMMAND}}", terminalCommand)
+	if !useSystemd {
+		config = replaceSystemdStartup(config, "- command: ", "  - command: dms run")
+	}
+	return config
+}
+
+// replaceSystemdStartup swaps the lines importing the environment into systemd
+// and starting the dms unit for the given lines launching dms directly.
+func replaceSystemdStartup(config, prefix string, run ...string) string {
+	var result []string
+	for _, line := range strings.Split(config, "\n") {
+		command, ok := strings.CutPrefix(strings.TrimSpace(line), prefix)
+		switch {
+		case ok && strings.HasPrefix(command, "dbus-update-activation-environment"):
+			continue
+		case ok && strings.HasPrefix(command, "systemctl --user start"):
+			result = append(result, run...)
+		default:
+			result = append(result, line)
+		}
+	}
+	return strings.Join(result, "\n")
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/deps"
@@ -624,3 +625,219 @@ func TestAlacrittyConfigDeployment(t *testing.T) {
 		assert.Contains(t, string(newContent), "decorations = \"None\"")
 	})
 }
+
+func TestLabwcConfigStructure(t *testing.T) {
+	assert.Contains(t, LabwcRcConfig, "<labwc_config>")
+	assert.Contains(t, LabwcRcConfig, `command="{{TERMINAL_COMMAND}}"`)
+	assert.Contains(t, LabwcRcConfig, "dms ipc call spotlight toggle")
+	assert.Contains(t, LabwcRcConfig, "<windowRules>")
+	assert.Contains(t, LabwcAutostartConfig, "systemctl --user start dms")
+	assert.Contains(t, LabwcEnvironmentConfig, "TERMINAL={{TERMINAL_COMMAND}}")
+
+	_, err := splitLabwcElements(LabwcRcConfig)
+	assert.NoError(t, err)
+}
+
+func TestMergeLabwcRcConfig(t *testing.T) {
+	cd := &ConfigDeployer{}
+	newConfig := strings.ReplaceAll(LabwcRcConfig, "{{TERMINAL_COMMAND}}", "foot")
+
+	existing := `<?xml version="1.0"?>
+<labwc_config>
+  <theme>
+    <name>Nightmare</name>
+  </theme>
+  <keyboard>
+    <default />
+    <repeatRate>40</repeatRate>
+    <keybind key="W-space"><action name="Execute" command="fuzzel" /></keybind>
+    <keybind key="S-W-b"><action name="Execute" command="firefox" /></keybind>
+  </keyboard>
+  <libinput>
+    <device category="touchpad"><naturalScroll>yes</naturalScroll></device>
+  </libinput>
+  <windowRules>
+    <windowRule identifier="mpv"><action name="Maximize" /></windowRule>
+  </windowRules>
+</labwc_config>
+`
+
+	merged, err := cd.mergeLabwcRcConfig(newConfig, existing)
+	require.NoError(t, err)
+
+	assert.Contains(t, merged, "<name>Nightmare</name>")
+	assert.NotContains(t, merged, "<cornerRadius>12</cornerRadius>")
+	assert.Contains(t, merged, "<repeatRate>40</repeatRate>")
+	assert.Contains(t, merged, `command="firefox"`)
+	assert.NotContains(t, merged, `command="fuzzel"`)
+	assert.NotContains(t, merged, "<default />")
+	assert.Contains(t, merged, "dms ipc call spotlight toggle")
+	assert.Contains(t, merged, "<naturalScroll>yes</naturalScroll>")
+	assert.Contains(t, merged, `<windowRule identifier="mpv">`)
+	assert.Contains(t, merged, "<gap>4</gap>")
+
+	elements, err := splitLabwcElements(merged)
+	require.NoError(t, err)
+	names := make(map[string]int)
+	for _, el := range elements {
+		names[el.name]++
+	}
+	assert.Equal(t, 1, names["keyboard"])
+	assert.Equal(t, 1, names["windowRules"])
+	assert.Equal(t, 1, names["libinput"])
+}
+
+func TestMergeLabwcAutostartAndEnvironment(t *testing.T) {
+	autostart, err := mergeLabwcAutostart(LabwcAutostartConfig, "swaybg -i ~/wall.png &\nsystemctl --user start dms\n")
+	require.NoError(t, err)
+	assert.Contains(t, autostart, "swaybg -i ~/wall.png &")
+	assert.Equal(t, 1, strings.Count(autostart, "systemctl --user start dms"))
+	assert.Contains(t, autostart, "dbus-update-activation-environment --systemd --all")
+
+	env, err := mergeLabwcEnvironment(strings.ReplaceAll(LabwcEnvironmentConfig, "{{TERMINAL_COMMAND}}", "kitty"), "XKB_DEFAULT_LAYOUT=de\nTERMINAL=foot\n")
+	require.NoError(t, err)
+	assert.Contains(t, env, "XKB_DEFAULT_LAYOUT=de")
+	assert.Contains(t, env, "TERMINAL=foot")
+	assert.NotContains(t, env, "TERMINAL=kitty")
+	assert.Contains(t, env, "QT_QPA_PLATFORMTHEME=gtk3")
+}
+
+func TestLabwcConfigDeployment(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	cd := NewConfigDeployer(make(chan string, 100))
+
+	t.Run("deploy labwc config to empty directory", func(t *testing.T) {
+		result, err := cd.deployLabwcConfig(deps.TerminalKitty, false)
+		require.NoError(t, err)
+
+		assert.Equal(t, "labwc", result.ConfigType)
+		assert.True(t, result.Deployed)
+		assert.Empty(t, result.BackupPath)
+
+		content, err := os.ReadFile(result.Path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), `command="kitty"`)
+
+		autostart, err := os.ReadFile(filepath.Join(filepath.Dir(result.Path), "autostart"))
+		require.NoError(t, err)
+		assert.Contains(t, string(autostart), "dms run &")
+		assert.NotContains(t, string(autostart), "systemctl")
+	})
+
+	t.Run("redeploy keeps user settings", func(t *testing.T) {
+		envPath := filepath.Join(tempDir, ".config", "labwc", "environment")
+		require.NoError(t, os.WriteFile(envPath, []byte("XKB_DEFAULT_LAYOUT=us\n"), 0o644))
+
+		result, err := cd.deployLabwcConfig(deps.TerminalGhostty, true)
+		require.NoError(t, err)
+		assert.NotEmpty(t, result.BackupPath)
+		assert.FileExists(t, result.BackupPath)
+
+		env, err := os.ReadFile(envPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(env), "XKB_DEFAULT_LAYOUT=us")
+		assert.Contains(t, string(env), "TERMINAL=ghostty")
+
+		autostart, err := os.ReadFile(filepath.Join(tempDir, ".config", "labwc", "autostart"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(autostart), "dms run &")
+		assert.Contains(t, string(autostart), "systemctl --user start dms")
+	})
+}
+
+func TestIncludeConfigDeployment(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	cd := NewConfigDeployer(make(chan string, 100))
+	cd.stateDir = filepath.Join(tempDir, "state")
+
+	tests := []struct {
+		wm       deps.WindowManager
+		dir      string
+		main     string
+		include  string
+		dmsFiles []string
+	}{
+		{deps.WindowManagerSway, "sway", "config", "include ./dms/binds.conf", []string{"binds.conf", "colors.conf", "layout.conf", "outputs.conf"}},
+		{deps.WindowManagerMangoWC, "mango", "config.conf", "source=./dms/binds.conf", []string{"binds.conf", "colors.conf", "layout.conf", "outputs.conf"}},
+		{deps.WindowManagerMiracle, "miracle-wm", "config.yaml", "- dms/binds.yaml", []string{"binds.yaml", "colors.yaml", "layout.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir, func(t *testing.T) {
+			result, err := cd.deployIncludeConfig(tt.wm, deps.TerminalKitty, true)
+			require.NoError(t, err)
+			assert.True(t, result.Deployed)
+			assert.Equal(t, filepath.Join(tempDir, ".config", tt.dir, tt.main), result.Path)
+
+			content, err := os.ReadFile(result.Path)
+			require.NoError(t, err)
+			assert.Contains(t, string(content), tt.include)
+			assert.Contains(t, string(content), "systemctl --user start dms")
+
+			for _, name := range tt.dmsFiles {
+				assert.FileExists(t, filepath.Join(tempDir, ".config", tt.dir, "dms", name))
+			}
+			binds, err := os.ReadFile(filepath.Join(tempDir, ".config", tt.dir, "dms", tt.dmsFiles[0]))
+			require.NoError(t, err)
+			assert.Contains(t, string(binds), "kitty")
+			assert.NotContains(t, string(binds), "{{TERMINAL_COMMAND}}")
+		})
+	}
+
+	t.Run("replaced sway config keeps its outputs", func(t *testing.T) {
+		home := t.TempDir()
+		t.Setenv("HOME", home)
+		cd := NewConfigDeployer(make(chan string, 100))
+		cd.stateDir = filepath.Join(home, "state")
+
+		configPath := filepath.Join(home, ".config", "sway", "config")
+		require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0o755))
+		existing := "set $mod Mod1\noutput DP-1 mode 3840x2160@60Hz scale 2\nbindsym $mod+Return exec foot\n"
+		require.NoError(t, os.WriteFile(configPath, []byte(existing), 0o644))
+
+		result, err := cd.deployIncludeConfig(deps.WindowManagerSway, deps.TerminalGhostty, true)
+		require.NoError(t, err)
+		assert.FileExists(t, result.BackupPath)
+
+		outputs, err := os.ReadFile(filepath.Join(home, ".config", "sway", "dms", "outputs.conf"))
+		require.NoError(t, err)
+		assert.Equal(t, "output DP-1 mode 3840x2160@60Hz scale 2\n", string(outputs))
+
+		content, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "set $mod Mod4")
+	})
+
+	t.Run("redeploy merges user edits", func(t *testing.T) {
+		configPath := filepath.Join(tempDir, ".config", "miracle-wm", "config.yaml")
+		content, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		edited := strings.Replace(string(content), "action_key: meta", "action_key: alt", 1)
+		require.NoError(t, os.WriteFile(configPath, []byte(edited), 0o644))
+
+		statuses, err := cd.Status()
+		require.NoError(t, err)
+		var found bool
+		for _, s := range statuses {
+			if s.Path == configPath {
+				found = true
+				assert.Equal(t, DeployStateModified, s.State)
+				assert.Equal(t, []string{"action_key"}, s.DriftedSections)
+			}
+		}
+		assert.True(t, found)
+
+		result, err := cd.deployIncludeConfig(deps.WindowManagerMiracle, deps.TerminalKitty, false)
+		require.NoError(t, err)
+		assert.Empty(t, result.Conflicts)
+
+		merged, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		assert.Contains(t, string(merged), "action_key: alt")
+		assert.Contains(t, string(merged), "  - command: dms run")
+	})
+}
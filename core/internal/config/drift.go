@@ -0,0 +1,353 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/deps"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+)
+
+// DeployRecord remembers the pristine template DMS rendered for a deployed
+// file, which serves as the merge base on the next deploy.
+type DeployRecord struct {
+	Path       string    `json:"path"`
+	ConfigType string    `json:"configType"`
+	Template   string    `json:"template"`
+	Written    string    `json:"written"`
+	Terminal   string    `json:"terminal"`
+	UseSystemd bool      `json:"useSystemd"`
+	DeployedAt time.Time `json:"deployedAt"`
+}
+
+type DeployedFileStatus struct {
+	Path            string    `json:"path"`
+	ConfigType      string    `json:"configType"`
+	DeployedAt      time.Time `json:"deployedAt"`
+	State           string    `json:"state"`
+	DriftedSections []string  `json:"driftedSections,omitempty"`
+	UpstreamChanged bool      `json:"upstreamChanged"`
+}
+
+const (
+	DeployStateClean    = "clean"
+	DeployStateModified = "modified"
+	DeployStateMissing  = "missing"
+)
+
+func defaultDeployStateDir() string {
+	return filepath.Join(utils.XDGStateHome(), "DankMaterialShell", "deployed")
+}
+
+func (cd *ConfigDeployer) deployStateDir() string {
+	if cd.stateDir != "" {
+		return cd.stateDir
+	}
+	return defaultDeployStateDir()
+}
+
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func (cd *ConfigDeployer) loadDeployRecords() (map[string]DeployRecord, error) {
+	records := make(map[string]DeployRecord)
+	data, err := os.ReadFile(filepath.Join(cd.deployStateDir(), "manifest.json"))
+	switch {
+	case os.IsNotExist(err):
+		return records, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("corrupt deploy manifest: %w", err)
+	}
+	return records, nil
+}
+
+// pristineTemplate returns the template deployed to path last time, if DMS
+// recorded one.
+func (cd *ConfigDeployer) pristineTemplate(path string) (string, bool) {
+	records, err := cd.loadDeployRecords()
+	if err != nil {
+		return "", false
+	}
+	record, ok := records[path]
+	if !ok {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(cd.deployStateDir(), "templates", record.Template))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func (cd *ConfigDeployer) recordDeployment(path, configType, template, written string, terminal deps.Terminal, useSystemd bool) error {
+	dir := cd.deployStateDir()
+	templatesDir := filepath.Join(dir, "templates")
+	if err := os.MkdirAll(templatesDir, 0o700); err != nil {
+		return err
+	}
+
+	templateHash := contentHash(template)
+	if err := os.WriteFile(filepath.Join(templatesDir, templateHash), []byte(template), 0o600); err != nil {
+		return err
+	}
+
+	records, err := cd.loadDeployRecords()
+	if err != nil {
+		return err
+	}
+	records[path] = DeployRecord{
+		Path:       path,
+		ConfigType: configType,
+		Template:   templateHash,
+		Written:    contentHash(written),
+		Terminal:   terminalCommandFor(terminal),
+		UseSystemd: useSystemd,
+		DeployedAt: time.Now(),
+	}
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0o600); err != nil {
+		return err
+	}
+
+	// drop templates no deployment refers to anymore
+	inUse := make(map[string]bool)
+	for _, r := range records {
+		inUse[r.Template] = true
+	}
+	entries, _ := os.ReadDir(templatesDir)
+	for _, e := range entries {
+		if !inUse[e.Name()] {
+			os.Remove(filepath.Join(templatesDir, e.Name()))
+		}
+	}
+	return nil
+}
+
+func terminalCommandFor(terminal deps.Terminal) string {
+	switch terminal {
+	case deps.TerminalKitty:
+		return "kitty"
+	case deps.TerminalAlacritty:
+		return "alacritty"
+	default:
+		return "ghostty"
+	}
+}
+
+func terminalFromCommand(command string) deps.Terminal {
+	switch command {
+	case "kitty":
+		return deps.TerminalKitty
+	case "alacritty":
+		return deps.TerminalAlacritty
+	default:
+		return deps.TerminalGhostty
+	}
+}
+
+// DeployedChoices returns the terminal and systemd choice the compositor
+// config for wm was last deployed with, so a redeploy renders the same
+// template the merge base was rendered from.
+func (cd *ConfigDeployer) DeployedChoices(wm deps.WindowManager) (deps.Terminal, bool, bool) {
+	c, ok := cd.deployedConfigFor(wm)
+	if !ok {
+		return deps.TerminalGhostty, false, false
+	}
+	records, err := cd.loadDeployRecords()
+	if err != nil {
+		return deps.TerminalGhostty, false, false
+	}
+	record, ok := records[c.path]
+	if !ok {
+		return deps.TerminalGhostty, false, false
+	}
+	return terminalFromCommand(record.Terminal), record.UseSystemd, true
+}
+
+// deployedConfig is the main config file DMS deploys for a compositor.
+type deployedConfig struct {
+	wm         deps.WindowManager
+	configType string
+	path       string
+	template   func(terminalCommand string, useSystemd bool) string
+	split      func(string) []configSection
+}
+
+func (cd *ConfigDeployer) deployedConfigs() []deployedConfig {
+	configDir := filepath.Join(os.Getenv("HOME"), ".config")
+	return []deployedConfig{
+		{deps.WindowManagerNiri, "Niri", filepath.Join(configDir, "niri", "config.kdl"), cd.niriTemplate, splitNiriSections},
+		{deps.WindowManagerHyprland, "Hyprland", filepath.Join(configDir, "hypr", "hyprland.conf"), cd.hyprlandTemplate, splitHyprlandSections},
+		{deps.WindowManagerSway, "Sway", filepath.Join(configDir, "sway", "config"), cd.swayTemplate, splitSwaySections},
+		{deps.WindowManagerMangoWC, "MangoWC", filepath.Join(configDir, "mango", "config.conf"), cd.mangoTemplate, splitMangoSections},
+		{deps.WindowManagerMiracle, "Miracle", filepath.Join(configDir, "miracle-wm", "config.yaml"), cd.miracleTemplate, splitYAMLSections},
+	}
+}
+
+func (cd *ConfigDeployer) deployedConfigFor(wm deps.WindowManager) (deployedConfig, bool) {
+	for _, c := range cd.deployedConfigs() {
+		if c.wm == wm {
+			return c, true
+		}
+	}
+	return deployedConfig{}, false
+}
+
+// MainConfig returns the config type and path of the main config file DMS
+// deploys for wm.
+func (cd *ConfigDeployer) MainConfig(wm deps.WindowManager) (string, string, bool) {
+	c, ok := cd.deployedConfigFor(wm)
+	return c.configType, c.path, ok
+}
+
+func (cd *ConfigDeployer) deployedConfigOfType(configType string) (deployedConfig, bool) {
+	for _, c := range cd.deployedConfigs() {
+		if c.configType == configType {
+			return c, true
+		}
+	}
+	return deployedConfig{}, false
+}
+
+func (cd *ConfigDeployer) sectionSplitter(configType string) func(string) []configSection {
+	if c, ok := cd.deployedConfigOfType(configType); ok {
+		return c.split
+	}
+	return nil
+}
+
+func (cd *ConfigDeployer) renderTemplate(record DeployRecord) (string, bool) {
+	c, ok := cd.deployedConfigOfType(record.ConfigType)
+	if !ok {
+		return "", false
+	}
+	return c.template(record.Terminal, record.UseSystemd), true
+}
+
+// derivedFrom reports whether content still shares at least half of its
+// sections with the deployed template. A file replaced wholesale is not
+// merged section by section, as that would discard the whole template.
+func derivedFrom(template, content []configSection) bool {
+	if len(template) == 0 {
+		return false
+	}
+	contentMap := sectionMap(content)
+	shared := 0
+	for _, s := range template {
+		if c, ok := contentMap[s.Key]; ok && sameSection(s.Text, c, true, true) {
+			shared++
+		}
+	}
+	return shared*2 >= len(template)
+}
+
+// mergeWithTemplate runs a three-way merge of the user's file against the new
+// template when a pristine copy of the previous template was recorded.
+func (cd *ConfigDeployer) mergeWithTemplate(configType, path, existing, template string) (string, []MergeConflict, bool) {
+	base, ok := cd.pristineTemplate(path)
+	split := cd.sectionSplitter(configType)
+	if !ok || split == nil {
+		return "", nil, false
+	}
+
+	baseSections, existingSections := split(base), split(existing)
+	if !derivedFrom(baseSections, existingSections) {
+		return "", nil, false
+	}
+
+	merged, conflicts := threeWayMerge(baseSections, existingSections, split(template), path, cd.resolutions)
+	for i := range conflicts {
+		conflicts[i].ConfigType = configType
+	}
+	return merged, conflicts, true
+}
+
+// SetMergeResolutions decides conflicts found by PlanMerges: true takes the
+// new template's section, false keeps the user's. Unresolved conflicts keep
+// the user's version.
+func (cd *ConfigDeployer) SetMergeResolutions(resolutions map[string]bool) {
+	cd.resolutions = resolutions
+}
+
+// PlanMerges reports the sections that would conflict when redeploying the
+// compositor config for wm, without writing anything.
+func (cd *ConfigDeployer) PlanMerges(wm deps.WindowManager, terminal deps.Terminal, useSystemd bool) ([]MergeConflict, error) {
+	c, ok := cd.deployedConfigFor(wm)
+	if !ok {
+		return nil, nil
+	}
+	configType, path := c.configType, c.path
+	template := c.template(terminalCommandFor(terminal), useSystemd)
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	_, conflicts, _ := cd.mergeWithTemplate(configType, path, string(existing), template)
+	return conflicts, nil
+}
+
+// Status reports how every recorded deployment compares to what DMS wrote
+// and to the template it came from.
+func (cd *ConfigDeployer) Status() ([]DeployedFileStatus, error) {
+	records, err := cd.loadDeployRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]DeployedFileStatus, 0, len(records))
+	for _, record := range records {
+		status := DeployedFileStatus{
+			Path:       record.Path,
+			ConfigType: record.ConfigType,
+			DeployedAt: record.DeployedAt,
+			State:      DeployStateClean,
+		}
+
+		if current, ok := cd.renderTemplate(record); ok {
+			status.UpstreamChanged = contentHash(current) != record.Template
+		}
+
+		data, err := os.ReadFile(record.Path)
+		switch {
+		case os.IsNotExist(err):
+			status.State = DeployStateMissing
+			statuses = append(statuses, status)
+			continue
+		case err != nil:
+			return nil, err
+		}
+
+		if contentHash(string(data)) != record.Written {
+			status.State = DeployStateModified
+		}
+		if template, ok := cd.pristineTemplate(record.Path); ok {
+			if split := cd.sectionSplitter(record.ConfigType); split != nil {
+				status.DriftedSections = driftedSections(split(template), split(string(data)))
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Path < statuses[j].Path })
+	return statuses, nil
+}
@@ -0,0 +1,12 @@
+package config
+
+import _ "embed"
+
+//go:embed embedded/labwc-rc.xml
+var LabwcRcConfig string
+
+//go:embed embedded/labwc-autostart
+var LabwcAutostartConfig string
+
+//go:embed embedded/labwc-environment
+var LabwcEnvironmentConfig string
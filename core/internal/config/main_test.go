@@ -0,0 +1,13 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
+)
+
+func TestMain(m *testing.M) {
+	snapshot.SetRecorder(snapshot.Discard)
+	os.Exit(m.Run())
+}
@@ -0,0 +1,15 @@
+package config
+
+import _ "embed"
+
+//go:embed embedded/mangowc.conf
+var MangoWCConfig string
+
+//go:embed embedded/mangowc-colors.conf
+var MangoWCColorsConfig string
+
+//go:embed embedded/mangowc-layout.conf
+var MangoWCLayoutConfig string
+
+//go:embed embedded/mangowc-binds.conf
+var MangoWCBindsConfig string
@@ -0,0 +1,560 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// configSection is one top level unit of a compositor config: a KDL node for
+// niri, a keyword line or category block for Hyprland. Text carries the
+// comments and blank lines leading up to it, so joining every section of a
+// file reproduces it exactly.
+type configSection struct {
+	Key  string
+	Text string
+}
+
+type MergeConflict struct {
+	ID         string `json:"id"`
+	ConfigType string `json:"configType"`
+	Path       string `json:"path"`
+	Section    string `json:"section"`
+	Base       string `json:"base,omitempty"`
+	Ours       string `json:"ours,omitempty"`
+	Theirs     string `json:"theirs,omitempty"`
+}
+
+// niri nodes that may appear more than once and are told apart by their
+// arguments rather than by position.
+var niriKeyedByArgs = map[string]int{
+	"output":              1,
+	"workspace":           1,
+	"include":             -1,
+	"spawn-at-startup":    -1,
+	"spawn-sh-at-startup": -1,
+}
+
+var hyprlandKeyedByValue = []string{
+	"bind", "unbind", "exec", "exec-once", "exec-shutdown", "env", "windowrule",
+	"layerrule", "monitor", "source", "workspace", "submap", "gesture", "plugin",
+	"permission",
+}
+
+var mangoKeyedByValue = []string{
+	"bind", "mousebind", "axisbind", "gesturebind", "switchbind", "exec", "env",
+	"source", "monitorrule", "windowrule", "tagrule", "layerrule",
+}
+
+// sway directives that may repeat, keyed by that many leading arguments (-1
+// for all of them).
+var swayKeyedByArgs = map[string]int{
+	"set":         1,
+	"gaps":        1,
+	"output":      1,
+	"input":       1,
+	"seat":        1,
+	"mode":        1,
+	"bar":         1,
+	"bindsym":     -1,
+	"bindcode":    -1,
+	"bindswitch":  -1,
+	"bindgesture": -1,
+	"exec":        -1,
+	"exec_always": -1,
+	"include":     -1,
+	"for_window":  -1,
+	"assign":      -1,
+	"no_focus":    -1,
+	"workspace":   -1,
+}
+
+const trailerKey = ""
+
+// splitNiriSections splits a KDL document into its top level nodes.
+func splitNiriSections(content string) []configSection {
+	var sections []configSection
+	counts := make(map[string]int)
+
+	start := 0
+	i := 0
+	n := len(content)
+	for i < n {
+		// skip leading whitespace and comments, which belong to the next node
+		nodeStart := -1
+		for i < n {
+			switch {
+			case content[i] == ' ' || content[i] == '\t' || content[i] == '\n' || content[i] == '\r':
+				i++
+			case strings.HasPrefix(content[i:], "//"):
+				for i < n && content[i] != '\n' {
+					i++
+				}
+			case strings.HasPrefix(content[i:], "/*"):
+				i = skipKDLBlockComment(content, i)
+			default:
+				nodeStart = i
+			}
+			if nodeStart >= 0 {
+				break
+			}
+		}
+		if nodeStart < 0 {
+			break
+		}
+
+		end := scanKDLNode(content, nodeStart)
+		header := kdlNodeHeader(content[nodeStart:end])
+		sections = append(sections, configSection{
+			Key:  niriSectionKey(header, counts),
+			Text: content[start:end],
+		})
+		start = end
+		i = end
+	}
+
+	if start < n {
+		sections = append(sections, configSection{Key: trailerKey, Text: content[start:]})
+	}
+	return sections
+}
+
+func skipKDLBlockComment(content string, i int) int {
+	depth := 0
+	for i < len(content) {
+		switch {
+		case strings.HasPrefix(content[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(content[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return i
+}
+
+// scanKDLNode returns the offset just past the node starting at i, including
+// its terminating newline.
+func scanKDLNode(content string, i int) int {
+	depth := 0
+	n := len(content)
+	for i < n {
+		c := content[i]
+		switch {
+		case c == '"':
+			i++
+			for i < n && content[i] != '"' {
+				if content[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case c == 'r' && i+1 < n && (content[i+1] == '"' || content[i+1] == '#'):
+			j := i + 1
+			hashes := 0
+			for j < n && content[j] == '#' {
+				hashes++
+				j++
+			}
+			if j >= n || content[j] != '"' {
+				i++
+				continue
+			}
+			closing := "\"" + strings.Repeat("#", hashes)
+			if idx := strings.Index(content[j+1:], closing); idx >= 0 {
+				i = j + 1 + idx + len(closing)
+			} else {
+				i = n
+			}
+		case strings.HasPrefix(content[i:], "//"):
+			for i < n && content[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(content[i:], "/*"):
+			i = skipKDLBlockComment(content, i)
+		case c == '\\':
+			// line continuation
+			i++
+			for i < n && content[i] != '\n' {
+				i++
+			}
+			i++
+		case c == '{':
+			depth++
+			i++
+		case c == '}':
+			depth--
+			i++
+			if depth == 0 {
+				return endOfLine(content, i)
+			}
+		case (c == '\n' || c == ';') && depth == 0:
+			return endOfLine(content, i)
+		default:
+			i++
+		}
+	}
+	return n
+}
+
+func endOfLine(content string, i int) int {
+	if idx := strings.IndexByte(content[i:], '\n'); idx >= 0 {
+		return i + idx + 1
+	}
+	return len(content)
+}
+
+func kdlNodeHeader(node string) string {
+	if idx := strings.IndexByte(node, '{'); idx >= 0 {
+		node = node[:idx]
+	}
+	if idx := strings.Index(node, "//"); idx >= 0 {
+		node = node[:idx]
+	}
+	return strings.Join(strings.Fields(strings.TrimRight(node, ";\n")), " ")
+}
+
+func niriSectionKey(header string, counts map[string]int) string {
+	fields := strings.Fields(header)
+	if len(fields) == 0 {
+		return uniqueKey(header, counts)
+	}
+
+	name := fields[0]
+	switch argc, ok := niriKeyedByArgs[strings.TrimPrefix(name, "/-")]; {
+	case ok && argc < 0:
+		return uniqueKey(header, counts)
+	case ok && len(fields) > argc:
+		return uniqueKey(strings.Join(fields[:argc+1], " "), counts)
+	default:
+		return uniqueKey(name, counts)
+	}
+}
+
+// keywordSyntax describes a line based config format made of keyword lines
+// and brace delimited blocks.
+type keywordSyntax struct {
+	comment      string
+	stripComment func(string) string
+	lineKey      func(string) string
+}
+
+var hyprlandSyntax = keywordSyntax{
+	comment:      "#",
+	stripComment: stripHyprlandComment,
+	lineKey: func(line string) string {
+		return keywordLineKey(line, hyprlandKeyedByValue)
+	},
+}
+
+var mangoSyntax = keywordSyntax{
+	comment:      "#",
+	stripComment: stripHyprlandComment,
+	lineKey: func(line string) string {
+		return keywordLineKey(line, mangoKeyedByValue)
+	},
+}
+
+// sway has no trailing comments, a # only starts one at the beginning of a line
+var swaySyntax = keywordSyntax{
+	comment:      "#",
+	stripComment: func(line string) string { return line },
+	lineKey:      swayLineKey,
+}
+
+func splitHyprlandSections(content string) []configSection {
+	return splitKeywordSections(content, hyprlandSyntax)
+}
+
+func splitMangoSections(content string) []configSection {
+	return splitKeywordSections(content, mangoSyntax)
+}
+
+func splitSwaySections(content string) []configSection {
+	return splitKeywordSections(content, swaySyntax)
+}
+
+// splitKeywordSections splits a config into keyword lines and blocks.
+func splitKeywordSections(content string, syntax keywordSyntax) []configSection {
+	var sections []configSection
+	counts := make(map[string]int)
+
+	lines := strings.SplitAfter(content, "\n")
+	var pending strings.Builder
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, syntax.comment) {
+			pending.WriteString(line)
+			continue
+		}
+
+		text := line
+		header := strings.TrimSpace(syntax.stripComment(trimmed))
+		if strings.HasSuffix(header, "{") {
+			header = strings.TrimSpace(strings.TrimSuffix(header, "{"))
+			depth := 1
+			for depth > 0 && i+1 < len(lines) {
+				i++
+				text += lines[i]
+				inner := syntax.stripComment(lines[i])
+				depth += strings.Count(inner, "{") - strings.Count(inner, "}")
+			}
+		}
+
+		sections = append(sections, configSection{
+			Key:  uniqueKey(syntax.lineKey(header), counts),
+			Text: pending.String() + text,
+		})
+		pending.Reset()
+	}
+
+	if pending.Len() > 0 {
+		sections = append(sections, configSection{Key: trailerKey, Text: pending.String()})
+	}
+	return sections
+}
+
+func stripHyprlandComment(line string) string {
+	// ## escapes a literal # in Hyprland values
+	for i := 0; i < len(line); i++ {
+		if line[i] != '#' {
+			continue
+		}
+		if i+1 < len(line) && line[i+1] == '#' {
+			i++
+			continue
+		}
+		return line[:i]
+	}
+	return line
+}
+
+func keywordLineKey(line string, keyedByValue []string) string {
+	keyword, value, found := strings.Cut(line, "=")
+	keyword = strings.TrimSpace(keyword)
+	if !found {
+		return strings.Join(strings.Fields(line), " ")
+	}
+	for _, prefix := range keyedByValue {
+		if strings.HasPrefix(keyword, prefix) {
+			return keyword + " = " + strings.Join(strings.Fields(value), " ")
+		}
+	}
+	return keyword
+}
+
+func swayLineKey(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return line
+	}
+	switch argc, ok := swayKeyedByArgs[fields[0]]; {
+	case ok && argc < 0:
+		return strings.Join(fields, " ")
+	case ok && len(fields) > argc:
+		return strings.Join(fields[:argc+1], " ")
+	default:
+		return fields[0]
+	}
+}
+
+// splitYAMLSections splits a YAML document into its top level keys.
+func splitYAMLSections(content string) []configSection {
+	var sections []configSection
+	counts := make(map[string]int)
+
+	lines := strings.SplitAfter(content, "\n")
+	var pending strings.Builder
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			pending.WriteString(line)
+		case line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(line, "-"):
+			// nested content, along with any comments inside it
+			if len(sections) == 0 {
+				pending.WriteString(line)
+				continue
+			}
+			sections[len(sections)-1].Text += pending.String() + line
+			pending.Reset()
+		default:
+			key, _, _ := strings.Cut(trimmed, ":")
+			sections = append(sections, configSection{
+				Key:  uniqueKey(strings.TrimSpace(key), counts),
+				Text: pending.String() + line,
+			})
+			pending.Reset()
+		}
+	}
+
+	if pending.Len() > 0 {
+		sections = append(sections, configSection{Key: trailerKey, Text: pending.String()})
+	}
+	return sections
+}
+
+func uniqueKey(key string, counts map[string]int) string {
+	counts[key]++
+	if counts[key] == 1 {
+		return key
+	}
+	return fmt.Sprintf("%s #%d", key, counts[key])
+}
+
+func sectionMap(sections []configSection) map[string]string {
+	m := make(map[string]string, len(sections))
+	for _, s := range sections {
+		m[s.Key] = s.Text
+	}
+	return m
+}
+
+func sameSection(a, b string, aok, bok bool) bool {
+	if aok != bok {
+		return false
+	}
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
+
+func conflictID(path, key string) string {
+	return path + "\x00" + key
+}
+
+// threeWayMerge merges the user's edits (ours) with a new template (theirs)
+// against the template that was originally deployed (base). Sections changed
+// on only one side take that side; sections changed on both are conflicts,
+// settled by resolutions (true takes the template) and otherwise left as the
+// user has them.
+func threeWayMerge(base, ours, theirs []configSection, path string, resolutions map[string]bool) (string, []MergeConflict) {
+	baseMap, oursMap, theirsMap := sectionMap(base), sectionMap(ours), sectionMap(theirs)
+
+	result := make(map[string]string)
+	decided := make(map[string]bool)
+	var conflicts []MergeConflict
+
+	decide := func(key string) {
+		if decided[key] {
+			return
+		}
+		decided[key] = true
+		b, bok := baseMap[key]
+		o, ook := oursMap[key]
+		t, tok := theirsMap[key]
+
+		var text string
+		var keep bool
+		switch {
+		case sameSection(o, t, ook, tok):
+			text, keep = o, ook
+		case sameSection(o, b, ook, bok):
+			text, keep = t, tok
+		case sameSection(t, b, tok, bok):
+			text, keep = o, ook
+		default:
+			id := conflictID(path, key)
+			conflicts = append(conflicts, MergeConflict{
+				ID:      id,
+				Path:    path,
+				Section: key,
+				Base:    b,
+				Ours:    o,
+				Theirs:  t,
+			})
+			if resolutions[id] {
+				text, keep = t, tok
+			} else {
+				text, keep = o, ook
+			}
+		}
+		if keep {
+			result[key] = text
+		}
+	}
+
+	for _, s := range theirs {
+		decide(s.Key)
+	}
+	for _, s := range ours {
+		decide(s.Key)
+	}
+	for _, s := range base {
+		decide(s.Key)
+	}
+
+	var order []string
+	inOrder := make(map[string]bool)
+	for _, s := range theirs {
+		if _, ok := result[s.Key]; ok && s.Key != trailerKey {
+			order = append(order, s.Key)
+			inOrder[s.Key] = true
+		}
+	}
+
+	// sections only the user has go after whatever preceded them in their file
+	prev := ""
+	for _, s := range ours {
+		if s.Key == trailerKey {
+			continue
+		}
+		if _, ok := result[s.Key]; ok && !inOrder[s.Key] {
+			pos := 0
+			if prev != "" {
+				for i, k := range order {
+					if k == prev {
+						pos = i + 1
+						break
+					}
+				}
+			}
+			order = append(order[:pos], append([]string{s.Key}, order[pos:]...)...)
+			inOrder[s.Key] = true
+		}
+		if inOrder[s.Key] {
+			prev = s.Key
+		}
+	}
+
+	var sb strings.Builder
+	for _, key := range order {
+		text := result[key]
+		sb.WriteString(text)
+		if !strings.HasSuffix(text, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	if trailer, ok := result[trailerKey]; ok {
+		sb.WriteString(trailer)
+	}
+
+	return sb.String(), conflicts
+}
+
+// driftedSections lists the sections where content differs from template.
+func driftedSections(template, content []configSection) []string {
+	templateMap, contentMap := sectionMap(template), sectionMap(content)
+
+	var drifted []string
+	seen := make(map[string]bool)
+	for _, sections := range [][]configSection{template, content} {
+		for _, s := range sections {
+			if seen[s.Key] || s.Key == trailerKey {
+				continue
+			}
+			seen[s.Key] = true
+			t, tok := templateMap[s.Key]
+			c, cok := contentMap[s.Key]
+			if !sameSection(t, c, tok, cok) {
+				drifted = append(drifted, s.Key)
+			}
+		}
+	}
+	return drifted
+}
@@ -0,0 +1,282 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/deps"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func joinSections(sections []configSection) string {
+	var sb strings.Builder
+	for _, s := range sections {
+		sb.WriteString(s.Text)
+	}
+	return sb.String()
+}
+
+func sectionKeys(sections []configSection) []string {
+	keys := make([]string, len(sections))
+	for i, s := range sections {
+		keys[i] = s.Key
+	}
+	return keys
+}
+
+func TestSplitSectionsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		split   func(string) []configSection
+	}{
+		{"niri", NiriConfig, splitNiriSections},
+		{"hyprland", HyprlandConfig, splitHyprlandSections},
+		{"sway", SwayConfig, splitSwaySections},
+		{"mangowc", MangoWCConfig, splitMangoSections},
+		{"miracle", MiracleConfig, splitYAMLSections},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections := tt.split(tt.content)
+			assert.Greater(t, len(sections), 5)
+			assert.Equal(t, tt.content, joinSections(sections))
+		})
+	}
+}
+
+func TestSplitNiriSections(t *testing.T) {
+	content := `// header
+input {
+    keyboard { xkb { layout "us"; } }
+}
+
+output "eDP-1" {
+    scale 2 // "}" in a comment
+}
+/-output "HDMI-A-1" { off; }
+spawn-at-startup "waybar"
+spawn-at-startup "mako"
+prefer-no-csd
+window-rule {
+    match app-id=r#"^org\.gnome\."#
+}
+window-rule { open-floating true; }
+// trailing comment
+`
+	sections := splitNiriSections(content)
+	assert.Equal(t, []string{
+		"input",
+		`output "eDP-1"`,
+		`/-output "HDMI-A-1"`,
+		`spawn-at-startup "waybar"`,
+		`spawn-at-startup "mako"`,
+		"prefer-no-csd",
+		"window-rule",
+		"window-rule #2",
+		trailerKey,
+	}, sectionKeys(sections))
+	assert.Equal(t, content, joinSections(sections))
+	assert.True(t, strings.HasPrefix(sections[0].Text, "// header\n"))
+}
+
+func TestSplitHyprlandSections(t *testing.T) {
+	content := `$mod = SUPER
+# MONITOR CONFIG
+monitor = DP-1, 2560x1440, 0x0, 1
+monitor = eDP-1, preferred, auto, 2
+
+general {
+    gaps_in = 5
+    col.active_border = rgb(ffffff) # comment
+}
+bind = $mod, Q, killactive
+bind = $mod, T, exec, kitty
+`
+	sections := splitHyprlandSections(content)
+	assert.Equal(t, []string{
+		"$mod",
+		"monitor = DP-1, 2560x1440, 0x0, 1",
+		"monitor = eDP-1, preferred, auto, 2",
+		"general",
+		"bind = $mod, Q, killactive",
+		"bind = $mod, T, exec, kitty",
+	}, sectionKeys(sections))
+	assert.Equal(t, content, joinSections(sections))
+}
+
+func TestSplitSwaySections(t *testing.T) {
+	content := `set $mod Mod4
+# outputs
+output * bg #000000 solid_color
+output eDP-1 scale 1.5
+input type:keyboard {
+    xkb_layout us
+}
+bindsym $mod+t exec kitty
+gaps inner 4
+gaps outer 4
+`
+	sections := splitSwaySections(content)
+	assert.Equal(t, []string{
+		"set $mod", "output *", "output eDP-1", "input type:keyboard",
+		"bindsym $mod+t exec kitty", "gaps inner", "gaps outer",
+	}, sectionKeys(sections))
+	assert.Equal(t, "# outputs\noutput * bg #000000 solid_color\n", sections[1].Text)
+}
+
+func TestSplitMangoSections(t *testing.T) {
+	content := `borderpx=2
+monitorrule=eDP-1,0.55,1,tile,0,1,0,0,2560,1600,240
+bind=SUPER,t,spawn,kitty # terminal
+bindl=NONE,XF86AudioMute,spawn,dms ipc call audio mute
+exec-once=dms run
+`
+	assert.Equal(t, []string{
+		"borderpx",
+		"monitorrule = eDP-1,0.55,1,tile,0,1,0,0,2560,1600,240",
+		"bind = SUPER,t,spawn,kitty",
+		"bindl = NONE,XF86AudioMute,spawn,dms ipc call audio mute",
+		"exec-once = dms run",
+	}, sectionKeys(splitMangoSections(content)))
+}
+
+func TestSplitYAMLSections(t *testing.T) {
+	content := `# header
+terminal: kitty
+startup_apps:
+  # launched once
+  - command: dms run
+includes:
+- dms/binds.yaml
+
+# end
+`
+	sections := splitYAMLSections(content)
+	assert.Equal(t, []string{"terminal", "startup_apps", "includes", ""}, sectionKeys(sections))
+	assert.Equal(t, "# header\nterminal: kitty\n", sections[0].Text)
+	assert.Equal(t, "startup_apps:\n  # launched once\n  - command: dms run\n", sections[1].Text)
+	assert.Equal(t, "includes:\n- dms/binds.yaml\n", sections[2].Text)
+}
+
+func TestReplaceSystemdStartup(t *testing.T) {
+	sway := (&ConfigDeployer{}).swayTemplate("foot", false)
+	assert.Contains(t, sway, "exec dms run")
+	assert.NotContains(t, sway, "systemctl")
+	assert.NotContains(t, sway, "dbus-update-activation-environment")
+	assert.Contains(t, sway, "set $term foot")
+
+	mango := (&ConfigDeployer{}).mangoTemplate("kitty", false)
+	assert.Contains(t, mango, "exec-once=dms run\nenv=QT_QPA_PLATFORM,wayland;xcb")
+	assert.Contains(t, mango, "env=TERMINAL,kitty")
+	assert.NotContains(t, mango, "systemctl")
+
+	miracle := (&ConfigDeployer{}).miracleTemplate("kitty", false)
+	assert.Contains(t, miracle, "startup_apps:\n  - command: dms run\n")
+	assert.NotContains(t, miracle, "systemctl")
+
+	assert.Contains(t, (&ConfigDeployer{}).swayTemplate("foot", true), "exec systemctl --user start dms")
+}
+
+func TestThreeWayMerge(t *testing.T) {
+	base := "input {\n    touchpad { tap; }\n}\nlayout {\n    gaps 8\n}\nprefer-no-csd\nbinds {\n    Mod+Q { close-window; }\n}\n"
+	ours := "input {\n    touchpad { tap; natural-scroll; }\n}\noutput \"DP-1\" {\n    scale 1.5\n}\nlayout {\n    gaps 8\n}\nbinds {\n    Mod+Q { close-window; }\n    Mod+W { spawn \"firefox\"; }\n}\n"
+	theirs := "input {\n    touchpad { tap; }\n}\nlayout {\n    gaps 12\n}\nprefer-no-csd\nbinds {\n    Mod+Q { close-window; }\n    Mod+Space { spawn \"dms\"; }\n}\nhotkey-overlay {\n    skip-at-startup\n}\n"
+
+	merged, conflicts := threeWayMerge(splitNiriSections(base), splitNiriSections(ours), splitNiriSections(theirs), "/config.kdl", nil)
+
+	assert.Contains(t, merged, "natural-scroll", "user change kept")
+	assert.Contains(t, merged, "gaps 12", "template change taken")
+	assert.Contains(t, merged, "hotkey-overlay", "template addition taken")
+	assert.NotContains(t, merged, "prefer-no-csd", "user removal kept")
+	assert.Less(t, strings.Index(merged, "input {"), strings.Index(merged, `output "DP-1"`))
+	assert.Less(t, strings.Index(merged, `output "DP-1"`), strings.Index(merged, "layout {"), "user addition stays where the user put it")
+
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "binds", conflicts[0].Section)
+	assert.Contains(t, merged, "firefox", "unresolved conflict keeps the user's version")
+
+	merged, _ = threeWayMerge(splitNiriSections(base), splitNiriSections(ours), splitNiriSections(theirs), "/config.kdl", map[string]bool{conflicts[0].ID: true})
+	assert.Contains(t, merged, "Mod+Space")
+	assert.NotContains(t, merged, "firefox")
+}
+
+func TestDriftedSections(t *testing.T) {
+	template := "$mod = SUPER\ngeneral {\n    gaps_in = 5\n}\nbind = $mod, Q, killactive\n"
+	content := "$mod = SUPER\ngeneral {\n    gaps_in = 10\n}\nbind = $mod, Q, killactive\nbind = $mod, B, exec, firefox\n"
+
+	drifted := driftedSections(splitHyprlandSections(template), splitHyprlandSections(content))
+	assert.Equal(t, []string{"general", "bind = $mod, B, exec, firefox"}, drifted)
+}
+
+func TestNiriRedeployMergesWithTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	cd := NewConfigDeployer(make(chan string, 100))
+	cd.stateDir = filepath.Join(tempDir, "state")
+
+	result, err := cd.deployNiriConfig(deps.TerminalGhostty, true)
+	require.NoError(t, err)
+
+	statuses, err := cd.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, DeployStateClean, statuses[0].State)
+	assert.False(t, statuses[0].UpstreamChanged)
+	assert.Empty(t, statuses[0].DriftedSections)
+
+	// pretend the previous deploy used an older template without hotkey-overlay
+	template := cd.niriTemplate("ghostty", true)
+	oldTemplate := strings.Replace(template, "prefer-no-csd", "prefer-no-csd\nscreenshot-path \"~/old.png\"", 1)
+	require.NoError(t, cd.recordDeployment(result.Path, "Niri", oldTemplate, oldTemplate, deps.TerminalGhostty, true))
+
+	userConfig := oldTemplate + "\noutput \"DP-1\" {\n    scale 1.25\n}\n"
+	require.NoError(t, os.WriteFile(result.Path, []byte(userConfig), 0o644))
+
+	statuses, err = cd.Status()
+	require.NoError(t, err)
+	assert.Equal(t, DeployStateModified, statuses[0].State)
+	assert.True(t, statuses[0].UpstreamChanged)
+	assert.Equal(t, []string{`output "DP-1"`}, statuses[0].DriftedSections)
+
+	conflicts, err := cd.PlanMerges(deps.WindowManagerNiri, deps.TerminalGhostty, true)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	result, err = cd.deployNiriConfig(deps.TerminalGhostty, true)
+	require.NoError(t, err)
+	assert.Empty(t, result.Conflicts)
+
+	content, err := os.ReadFile(result.Path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "scale 1.25")
+	assert.NotContains(t, string(content), "~/old.png")
+}
+
+func TestDeployedChoices(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	cd := NewConfigDeployer(make(chan string, 100))
+	cd.stateDir = filepath.Join(tempDir, "state")
+
+	_, _, ok := cd.DeployedChoices(deps.WindowManagerNiri)
+	assert.False(t, ok)
+
+	_, err := cd.deployNiriConfig(deps.TerminalKitty, false)
+	require.NoError(t, err)
+
+	terminal, useSystemd, ok := cd.DeployedChoices(deps.WindowManagerNiri)
+	require.True(t, ok)
+	assert.Equal(t, deps.TerminalKitty, terminal)
+	assert.False(t, useSystemd)
+
+	conflicts, err := cd.PlanMerges(deps.WindowManagerNiri, terminal, useSystemd)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
@@ -0,0 +1,15 @@
+package config
+
+import _ "embed"
+
+//go:embed embedded/miracle.yaml
+var MiracleConfig string
+
+//go:embed embedded/miracle-colors.yaml
+var MiracleColorsConfig string
+
+//go:embed embedded/miracle-layout.yaml
+var MiracleLayoutConfig string
+
+//go:embed embedded/miracle-binds.yaml
+var MiracleBindsConfig string
@@ -0,0 +1,301 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
+)
+
+// OutputSettings is the compositor independent state of one output that
+// RenderOutputs persists.
+type OutputSettings struct {
+	Name         string  `json:"name"`
+	Enabled      bool    `json:"enabled"`
+	Width        int32   `json:"width"`
+	Height       int32   `json:"height"`
+	Refresh      int32   `json:"refresh"`
+	X            int32   `json:"x"`
+	Y            int32   `json:"y"`
+	Scale        float64 `json:"scale"`
+	Transform    int32   `json:"transform"`
+	AdaptiveSync bool    `json:"adaptiveSync"`
+}
+
+type OutputsPreview struct {
+	Compositor string `json:"compositor"`
+	Path       string `json:"path"`
+	Current    string `json:"current"`
+	Content    string `json:"content"`
+	Diff       string `json:"diff"`
+	Changed    bool   `json:"changed"`
+}
+
+type outputsFormat struct {
+	configDir string
+	file      string
+	comment   string
+	split     func(string) []configSection
+	name      func(configSection) string
+	render    func(OutputSettings) string
+}
+
+const outputsHeader = "Auto-generated by DMS - do not edit manually"
+
+var niriOutputName = regexp.MustCompile(`^output\s+"((?:[^"\\]|\\.)*)"`)
+
+var outputsFormats = map[string]outputsFormat{
+	"niri": {
+		configDir: "niri",
+		file:      "outputs.kdl",
+		comment:   "//",
+		split:     splitNiriSections,
+		name: func(s configSection) string {
+			m := niriOutputName.FindStringSubmatch(strings.TrimSpace(stripLeadingComments(s.Text, "//")))
+			if m == nil {
+				return ""
+			}
+			name, err := strconv.Unquote(`"` + m[1] + `"`)
+			if err != nil {
+				return m[1]
+			}
+			return name
+		},
+		render: renderNiriOutput,
+	},
+	"hyprland": {
+		configDir: "hypr",
+		file:      "outputs.conf",
+		comment:   "#",
+		split:     splitHyprlandSections,
+		name: func(s configSection) string {
+			value, ok := strings.CutPrefix(s.Key, "monitor = ")
+			if !ok {
+				return ""
+			}
+			name, _, _ := strings.Cut(value, ",")
+			return strings.TrimSpace(name)
+		},
+		render: renderHyprlandOutput,
+	},
+	"sway": {
+		configDir: "sway",
+		file:      "outputs.conf",
+		comment:   "#",
+		split:     splitSwaySections,
+		name: func(s configSection) string {
+			name, ok := strings.CutPrefix(s.Key, "output ")
+			if !ok {
+				return ""
+			}
+			// sway allows several output lines per name, keyed "NAME #2" etc.
+			name, _, _ = strings.Cut(name, " #")
+			return name
+		},
+		render: renderSwayOutput,
+	},
+}
+
+// OutputsPath returns the DMS managed outputs file of a compositor, the one
+// created by `dms setup outputs`.
+func OutputsPath(compositor string) (string, error) {
+	format, ok := outputsFormats[compositor]
+	if !ok {
+		return "", fmt.Errorf("writing outputs is not supported for %s", compositor)
+	}
+	return filepath.Join(os.Getenv("HOME"), ".config", format.configDir, "dms", format.file), nil
+}
+
+// RenderOutputs renders outputs in the compositor's native format. Everything
+// in existing that does not describe one of outputs, such as a monitor that
+// is currently unplugged, is carried over.
+func RenderOutputs(compositor string, outputs []OutputSettings, existing string) (string, error) {
+	format, ok := outputsFormats[compositor]
+	if !ok {
+		return "", fmt.Errorf("writing outputs is not supported for %s", compositor)
+	}
+
+	sorted := make([]OutputSettings, len(outputs))
+	copy(sorted, outputs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].X != sorted[j].X {
+			return sorted[i].X < sorted[j].X
+		}
+		return sorted[i].Y < sorted[j].Y
+	})
+
+	current := make(map[string]bool, len(sorted))
+	var sb strings.Builder
+	sb.WriteString(format.comment + " " + outputsHeader + "\n")
+	for _, o := range sorted {
+		current[o.Name] = true
+		sb.WriteString("\n")
+		sb.WriteString(format.render(o))
+	}
+
+	for _, s := range format.split(existing) {
+		if current[format.name(s)] {
+			continue
+		}
+		text := strings.TrimLeft(stripLeadingComments(s.Text, format.comment+" "+outputsHeader), "\n")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		sb.WriteString("\n")
+		sb.WriteString(text)
+		if !strings.HasSuffix(text, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// PreviewOutputs renders outputs for compositor and compares the result with
+// the outputs file on disk.
+func PreviewOutputs(compositor string, outputs []OutputSettings) (*OutputsPreview, error) {
+	path, err := OutputsPath(compositor)
+	if err != nil {
+		return nil, err
+	}
+
+	current, exists := snapshot.ReadExisting(path)
+	content, err := RenderOutputs(compositor, outputs, string(current))
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := snapshot.UnifiedDiff(path, current, exists, []byte(content), true)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutputsPreview{
+		Compositor: compositor,
+		Path:       path,
+		Current:    string(current),
+		Content:    content,
+		Diff:       diff,
+		Changed:    !exists || string(current) != content,
+	}, nil
+}
+
+// WriteOutputs persists outputs to the compositor's outputs file.
+func WriteOutputs(compositor string, outputs []OutputSettings) (*OutputsPreview, error) {
+	preview, err := PreviewOutputs(compositor, outputs)
+	if err != nil {
+		return nil, err
+	}
+	if !preview.Changed {
+		return preview, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(preview.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dms directory: %w", err)
+	}
+	if err := snapshot.WriteFile(preview.Path, []byte(preview.Content), 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", preview.Path, err)
+	}
+	return preview, nil
+}
+
+// stripLeadingComments drops the comment and blank lines in front of a
+// section that start with prefix.
+func stripLeadingComments(text, prefix string) string {
+	lines := strings.SplitAfter(text, "\n")
+	i := 0
+	for i < len(lines) {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed != "" && !strings.HasPrefix(trimmed, prefix) {
+			break
+		}
+		i++
+	}
+	return strings.Join(lines[i:], "")
+}
+
+// wl_output transforms as spelled by niri and sway
+var transformNames = []string{"normal", "90", "180", "270", "flipped", "flipped-90", "flipped-180", "flipped-270"}
+
+func transformName(transform int32) string {
+	if transform < 0 || int(transform) >= len(transformNames) {
+		return transformNames[0]
+	}
+	return transformNames[transform]
+}
+
+func formatScale(scale float64) string {
+	if scale <= 0 {
+		scale = 1
+	}
+	return strconv.FormatFloat(scale, 'f', -1, 64)
+}
+
+func formatMode(o OutputSettings) string {
+	return fmt.Sprintf("%dx%d@%.3f", o.Width, o.Height, float64(o.Refresh)/1000)
+}
+
+func renderNiriOutput(o OutputSettings) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "output %s {\n", strconv.Quote(o.Name))
+	if !o.Enabled {
+		sb.WriteString("    off\n}\n")
+		return sb.String()
+	}
+	if o.Width > 0 && o.Height > 0 {
+		fmt.Fprintf(&sb, "    mode %q\n", formatMode(o))
+	}
+	fmt.Fprintf(&sb, "    scale %s\n", formatScale(o.Scale))
+	if o.Transform != 0 {
+		fmt.Fprintf(&sb, "    transform %q\n", transformName(o.Transform))
+	}
+	fmt.Fprintf(&sb, "    position x=%d y=%d\n", o.X, o.Y)
+	if o.AdaptiveSync {
+		sb.WriteString("    variable-refresh-rate\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func renderHyprlandOutput(o OutputSettings) string {
+	if !o.Enabled {
+		return fmt.Sprintf("monitor = %s, disable\n", o.Name)
+	}
+	resolution := "preferred"
+	if o.Width > 0 && o.Height > 0 {
+		resolution = formatMode(o)
+	}
+	line := fmt.Sprintf("monitor = %s, %s, %dx%d, %s", o.Name, resolution, o.X, o.Y, formatScale(o.Scale))
+	if o.Transform != 0 {
+		line += fmt.Sprintf(", transform, %d", o.Transform)
+	}
+	if o.AdaptiveSync {
+		line += ", vrr, 1"
+	}
+	return line + "\n"
+}
+
+func renderSwayOutput(o OutputSettings) string {
+	if !o.Enabled {
+		return fmt.Sprintf("output %s disable\n", o.Name)
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "output %s {\n", o.Name)
+	if o.Width > 0 && o.Height > 0 {
+		fmt.Fprintf(&sb, "    mode %sHz\n", formatMode(o))
+	}
+	fmt.Fprintf(&sb, "    position %d %d\n", o.X, o.Y)
+	fmt.Fprintf(&sb, "    scale %s\n", formatScale(o.Scale))
+	fmt.Fprintf(&sb, "    transform %s\n", transformName(o.Transform))
+	if o.AdaptiveSync {
+		sb.WriteString("    adaptive_sync on\n")
+	} else {
+		sb.WriteString("    adaptive_sync off\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
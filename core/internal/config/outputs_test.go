@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOutputs = []OutputSettings{
+	{Name: "HDMI-A-1", Enabled: true, Width: 1920, Height: 1080, Refresh: 60000, X: 2560, Scale: 1, Transform: 1},
+	{Name: "DP-1", Enabled: true, Width: 2560, Height: 1440, Refresh: 143912, Scale: 1.25, AdaptiveSync: true},
+	{Name: "eDP-1", Enabled: false},
+}
+
+func TestRenderOutputs(t *testing.T) {
+	tests := []struct {
+		compositor string
+		expected   string
+	}{
+		{"niri", `// Auto-generated by DMS - do not edit manually
+
+output "DP-1" {
+    mode "2560x1440@143.912"
+    scale 1.25
+    position x=0 y=0
+    variable-refresh-rate
+}
+
+output "eDP-1" {
+    off
+}
+
+output "HDMI-A-1" {
+    mode "1920x1080@60.000"
+    scale 1
+    transform "90"
+    position x=2560 y=0
+}
+`},
+		{"hyprland", `# Auto-generated by DMS - do not edit manually
+
+monitor = DP-1, 2560x1440@143.912, 0x0, 1.25, vrr, 1
+
+monitor = eDP-1, disable
+
+monitor = HDMI-A-1, 1920x1080@60.000, 2560x0, 1, transform, 1
+`},
+		{"sway", `# Auto-generated by DMS - do not edit manually
+
+output DP-1 {
+    mode 2560x1440@143.912Hz
+    position 0 0
+    scale 1.25
+    transform normal
+    adaptive_sync on
+}
+
+output eDP-1 disable
+
+output HDMI-A-1 {
+    mode 1920x1080@60.000Hz
+    position 2560 0
+    scale 1
+    transform 90
+    adaptive_sync off
+}
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.compositor, func(t *testing.T) {
+			content, err := RenderOutputs(tt.compositor, testOutputs, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, content)
+
+			again, err := RenderOutputs(tt.compositor, testOutputs, content)
+			require.NoError(t, err)
+			assert.Equal(t, content, again, "rendering over its own output is stable")
+		})
+	}
+
+	_, err := RenderOutputs("labwc", testOutputs, "")
+	assert.Error(t, err)
+}
+
+func TestRenderOutputsKeepsOtherEntries(t *testing.T) {
+	existing := `// Auto-generated by DMS - do not edit manually
+
+output "DP-1" {
+    scale 2
+}
+
+// docking station
+output "DP-5" {
+    scale 1.5
+}
+`
+	content, err := RenderOutputs("niri", testOutputs[1:2], existing)
+	require.NoError(t, err)
+	assert.NotContains(t, content, "scale 2")
+	assert.Contains(t, content, "// docking station\noutput \"DP-5\" {\n    scale 1.5\n}\n")
+
+	existing = "# Auto-generated by DMS - do not edit manually\n\nmonitor = DP-1, preferred, 0x0, 2\nmonitorv2 {\n    output = DP-1\n    supports_hdr = true\n}\n"
+	content, err = RenderOutputs("hyprland", testOutputs[1:2], existing)
+	require.NoError(t, err)
+	assert.NotContains(t, content, "preferred")
+	assert.Contains(t, content, "supports_hdr = true")
+
+	existing = "output DP-1 scale 2\noutput DP-1 pos 0 0\n\n# projector\noutput HDMI-A-2 disable\noutput * bg ~/wall.png fill\n"
+	content, err = RenderOutputs("sway", testOutputs[1:2], existing)
+	require.NoError(t, err)
+	assert.NotContains(t, content, "scale 2")
+	assert.NotContains(t, content, "pos 0 0")
+	assert.Contains(t, content, "# projector\noutput HDMI-A-2 disable\n")
+	assert.Contains(t, content, "output * bg ~/wall.png fill\n")
+}
+
+func TestWriteOutputs(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	preview, err := PreviewOutputs("sway", testOutputs)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tempDir, ".config", "sway", "dms", "outputs.conf"), preview.Path)
+	assert.True(t, preview.Changed)
+	assert.Contains(t, preview.Diff, "--- /dev/null")
+	assert.Contains(t, preview.Diff, "+output eDP-1 disable")
+	assert.NoFileExists(t, preview.Path, "preview does not write")
+
+	written, err := WriteOutputs("sway", testOutputs)
+	require.NoError(t, err)
+	data, err := os.ReadFile(written.Path)
+	require.NoError(t, err)
+	assert.Equal(t, written.Content, string(data))
+
+	preview, err = PreviewOutputs("sway", testOutputs)
+	require.NoError(t, err)
+	assert.False(t, preview.Changed)
+	assert.Empty(t, preview.Diff)
+}
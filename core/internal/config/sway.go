@@ -0,0 +1,15 @@
+package config
+
+import _ "embed"
+
+//go:embed embedded/sway.conf
+var SwayConfig string
+
+//go:embed embedded/sway-colors.conf
+var SwayColorsConfig string
+
+//go:embed embedded/sway-layout.conf
+var SwayLayoutConfig string
+
+//go:embed embedded/sway-binds.conf
+var SwayBindsConfig string
@@ -189,7 +189,8 @@ func getLstar(hex string) float64 {
 	rgb := HexToRGB(hex)
 	col := colorful.Color{R: rgb.R, G: rgb.G, B: rgb.B}
 	L, _, _ := col.Lab()
-	return L * 100.0 // go-colorful uses 0-1, we need 0-100 for DPS
+	// pure black comes out as a tiny negative L, which DPS cannot raise to a power
+	return math.Max(L*100.0, 0) // go-colorful uses 0-1, we need 0-100 for DPS
 }
 
 // Lab to hex, clamping if needed
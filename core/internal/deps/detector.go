@@ -35,6 +35,10 @@ type WindowManager int
 const (
 	WindowManagerHyprland WindowManager = iota
 	WindowManagerNiri
+	WindowManagerLabwc
+	WindowManagerSway
+	WindowManagerMangoWC
+	WindowManagerMiracle
 )
 
 type Terminal int
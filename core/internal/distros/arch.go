@@ -162,6 +162,12 @@ func (a *ArchDistribution) GetPackageMappingWithVariants(wm deps.WindowManager,
 	case deps.WindowManagerNiri:
 		packages["niri"] = a.getNiriMapping(variants["niri"])
 		packages["xwayland-satellite"] = PackageMapping{Name: "xwayland-satellite", Repository: RepoTypeSystem}
+	case deps.WindowManagerSway:
+		packages["sway"] = PackageMapping{Name: "sway", Repository: RepoTypeSystem}
+	case deps.WindowManagerMangoWC:
+		packages["mangowc"] = PackageMapping{Name: "mangowc-git", Repository: RepoTypeAUR}
+	case deps.WindowManagerMiracle:
+		packages["miracle-wm"] = PackageMapping{Name: "miracle-wm", Repository: RepoTypeAUR}
 	}
 
 	return packages
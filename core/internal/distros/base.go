@@ -357,6 +357,12 @@ func (b *BaseDistribution) detectWindowManager(wm deps.WindowManager) deps.Depen
 			Variant:     variant,
 			CanToggle:   true,
 		}
+	case deps.WindowManagerSway:
+		return b.detectSimpleWindowManager("sway", "sway", "i3-compatible tiling Wayland compositor", []string{"--version"}, `sway version (\d+\.\d+(?:\.\d+)?)`)
+	case deps.WindowManagerMangoWC:
+		return b.detectSimpleWindowManager("mangowc", "mango", "dwl based tiling Wayland compositor", []string{"-v"}, `(\d+\.\d+\.\d+)`)
+	case deps.WindowManagerMiracle:
+		return b.detectSimpleWindowManager("miracle-wm", "miracle-wm", "Mir based tiling Wayland compositor", []string{"--version"}, `(\d+\.\d+\.\d+)`)
 	default:
 		return deps.Dependency{
 			Name:        "unknown-wm",
@@ -367,6 +373,29 @@ func (b *BaseDistribution) detectWindowManager(wm deps.WindowManager) deps.Depen
 	}
 }
 
+func (b *BaseDistribution) detectSimpleWindowManager(name, command, description string, versionArgs []string, versionPattern string) deps.Dependency {
+	status := deps.StatusMissing
+	version := ""
+
+	if b.commandExists(command) {
+		status = deps.StatusInstalled
+		if output, err := exec.Command(command, versionArgs...).CombinedOutput(); err == nil {
+			if matches := regexp.MustCompile(versionPattern).FindStringSubmatch(string(output)); len(matches) > 1 {
+				version = matches[1]
+			}
+		}
+	}
+
+	return deps.Dependency{
+		Name:        name,
+		Status:      status,
+		Version:     version,
+		Description: description,
+		Required:    true,
+		Variant:     deps.VariantStable,
+	}
+}
+
 // Version comparison helper
 func (b *BaseDistribution) versionCompare(v1, v2 string) int {
 	parts1 := strings.Split(v1, ".")
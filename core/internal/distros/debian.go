@@ -119,10 +119,13 @@ func (d *DebianDistribution) GetPackageMappingWithVariants(wm deps.WindowManager
 		"ghostty":                 {Name: "ghostty", Repository: RepoTypeOBS, RepoURL: "home:AvengeMedia:danklinux"},
 	}
 
-	if wm == deps.WindowManagerNiri {
+	switch wm {
+	case deps.WindowManagerNiri:
 		niriVariant := variants["niri"]
 		packages["niri"] = d.getNiriMapping(niriVariant)
 		packages["xwayland-satellite"] = d.getXwaylandSatelliteMapping(niriVariant)
+	case deps.WindowManagerSway:
+		packages["sway"] = PackageMapping{Name: "sway", Repository: RepoTypeSystem}
 	}
 
 	return packages
@@ -138,6 +138,10 @@ func (f *FedoraDistribution) GetPackageMappingWithVariants(wm deps.WindowManager
 	case deps.WindowManagerNiri:
 		packages["niri"] = f.getNiriMapping(variants["niri"])
 		packages["xwayland-satellite"] = PackageMapping{Name: "xwayland-satellite", Repository: RepoTypeSystem}
+	case deps.WindowManagerSway:
+		packages["sway"] = PackageMapping{Name: "sway", Repository: RepoTypeSystem}
+	case deps.WindowManagerMiracle:
+		packages["miracle-wm"] = PackageMapping{Name: "miracle-wm", Repository: RepoTypeSystem}
 	}
 
 	return packages
@@ -161,6 +161,8 @@ func (g *GentooDistribution) GetPackageMappingWithVariants(wm deps.WindowManager
 	case deps.WindowManagerNiri:
 		packages["niri"] = g.getNiriMapping(variants["niri"])
 		packages["xwayland-satellite"] = PackageMapping{Name: "gui-apps/xwayland-satellite", Repository: RepoTypeGURU, AcceptKeywords: archKeyword}
+	case deps.WindowManagerSway:
+		packages["sway"] = PackageMapping{Name: "gui-wm/sway", Repository: RepoTypeSystem, UseFlags: "X"}
 	}
 
 	return packages
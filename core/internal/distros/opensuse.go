@@ -137,6 +137,8 @@ func (o *OpenSUSEDistribution) GetPackageMappingWithVariants(wm deps.WindowManag
 		niriVariant := variants["niri"]
 		packages["niri"] = o.getNiriMapping(niriVariant)
 		packages["xwayland-satellite"] = o.getXwaylandSatelliteMapping(niriVariant)
+	case deps.WindowManagerSway:
+		packages["sway"] = PackageMapping{Name: "sway", Repository: RepoTypeSystem}
 	}
 
 	return packages
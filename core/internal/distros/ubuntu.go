@@ -137,6 +137,8 @@ func (u *UbuntuDistribution) GetPackageMappingWithVariants(wm deps.WindowManager
 		niriVariant := variants["niri"]
 		packages["niri"] = u.getNiriMapping(niriVariant)
 		packages["xwayland-satellite"] = u.getXwaylandSatelliteMapping(niriVariant)
+	case deps.WindowManagerSway:
+		packages["sway"] = PackageMapping{Name: "sway", Repository: RepoTypeSystem}
 	}
 
 	return packages
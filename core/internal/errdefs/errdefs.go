@@ -13,6 +13,7 @@ const (
 	ErrTypeInvalidGamma
 	ErrTypeInvalidLocation
 	ErrTypeInvalidManualTimes
+	ErrTypeInvalidOutputConfig
 	ErrTypeNoWaylandDisplay
 	ErrTypeNoGammaControl
 	ErrTypeNotInitialized
@@ -56,6 +57,9 @@ var (
 	ErrInvalidGamma          = NewCustomError(ErrTypeInvalidGamma, "gamma must be between 0 and 10")
 	ErrInvalidLocation       = NewCustomError(ErrTypeInvalidLocation, "invalid latitude/longitude")
 	ErrInvalidManualTimes    = NewCustomError(ErrTypeInvalidManualTimes, "both sunrise and sunset must be set or neither")
+	ErrInvalidOutputMode     = NewCustomError(ErrTypeInvalidOutputConfig, "output mode must be follow, static or off")
+	ErrInvalidGain           = NewCustomError(ErrTypeInvalidOutputConfig, "channel gain must be between 0 and 2")
+	ErrInvalidBrightness     = NewCustomError(ErrTypeInvalidOutputConfig, "brightness must be between 0.1 and 1")
 	ErrNoWaylandDisplay      = NewCustomError(ErrTypeNoWaylandDisplay, "no wayland display available")
 	ErrNoGammaControl        = NewCustomError(ErrTypeNoGammaControl, "compositor does not support gamma control")
 	ErrNotInitialized        = NewCustomError(ErrTypeNotInitialized, "manager not initialized")
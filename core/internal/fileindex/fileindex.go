@@ -0,0 +1,156 @@
+// Package fileindex stores small JSON indexes that the dms CLI and the daemon
+// update concurrently, such as the config, screenshot and color histories.
+package fileindex
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Index is a JSON array of T kept in a file inside dir. Updates are
+// serialised within the process by a mutex and between processes by an
+// flock on dir/lock.
+type Index[T any] struct {
+	dir  string
+	name string
+	mu   sync.Mutex
+}
+
+func New[T any](dir, name string) *Index[T] {
+	return &Index[T]{dir: dir, name: name}
+}
+
+func (ix *Index[T]) Dir() string {
+	return ix.dir
+}
+
+func (ix *Index[T]) Path() string {
+	return filepath.Join(ix.dir, ix.name)
+}
+
+// List reads the entries without taking the file lock; the atomic writes
+// guarantee it never sees a partial index.
+func (ix *Index[T]) List() ([]T, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	return ix.Load()
+}
+
+// Update applies fn to the entries under both locks and saves the result.
+func (ix *Index[T]) Update(fn func([]T) ([]T, error)) error {
+	unlock, err := ix.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := ix.Load()
+	if err != nil {
+		return err
+	}
+	entries, err = fn(entries)
+	if err != nil {
+		return err
+	}
+	return ix.Save(entries)
+}
+
+// Lock takes both locks for callers that need to do more than Update
+// allows, e.g. write files the index refers to before saving it.
+func (ix *Index[T]) Lock() (func(), error) {
+	ix.mu.Lock()
+
+	if err := os.MkdirAll(ix.dir, 0o700); err != nil {
+		ix.mu.Unlock()
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(ix.dir, "lock"), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		ix.mu.Unlock()
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		ix.mu.Unlock()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+		ix.mu.Unlock()
+	}, nil
+}
+
+// Load reads the entries; a missing index is empty. Callers hold Lock when
+// they go on to Save.
+func (ix *Index[T]) Load() ([]T, error) {
+	data, err := os.ReadFile(ix.Path())
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var entries []T
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("corrupt index %s: %w", ix.Path(), err)
+	}
+	return entries, nil
+}
+
+func (ix *Index[T]) Save(entries []T) error {
+	if entries == nil {
+		entries = []T{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteAtomic(ix.Path(), data, 0o600)
+}
+
+// WriteAtomic writes data through a uniquely named temp file in the target
+// directory, so the CLI and the daemon never share a temp file. Temp files
+// end in ".tmp".
+func WriteAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// ID derives a short entry id from its creation time, the process and the
+// given parts, unique enough to tell entries of one index apart.
+func ID(t time.Time, parts ...string) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%d\x00%d\x00%s", t.UnixNano(), os.Getpid(), strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,83 @@
+package fileindex
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type entry struct {
+	ID string `json:"id"`
+}
+
+func TestIndexUpdate(t *testing.T) {
+	ix := New[entry](filepath.Join(t.TempDir(), "history"), "index.json")
+
+	entries, err := ix.List()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected an empty index, got %v, %v", entries, err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := ix.Update(func(entries []entry) ([]entry, error) {
+				return append(entries, entry{ID: ID(time.Now())}), nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err = ix.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 10 {
+		t.Errorf("Expected 10 entries, got %d", len(entries))
+	}
+
+	failed := errors.New("failed")
+	if err := ix.Update(func([]entry) ([]entry, error) { return nil, failed }); !errors.Is(err, failed) {
+		t.Errorf("Expected the update error, got %v", err)
+	}
+	if entries, _ := ix.List(); len(entries) != 10 {
+		t.Errorf("A failed update should not save, got %d entries", len(entries))
+	}
+}
+
+func TestIndexCorrupt(t *testing.T) {
+	ix := New[entry](t.TempDir(), "index.json")
+	if err := os.WriteFile(ix.Path(), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ix.List(); err == nil {
+		t.Error("Expected an error for a corrupt index")
+	}
+}
+
+func TestWriteAtomicLeavesNoTempFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "index.json")
+
+	for i := 0; i < 3; i++ {
+		if err := WriteAtomic(path, []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the target file, got %d entries", len(entries))
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
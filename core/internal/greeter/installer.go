@@ -12,6 +12,7 @@ import (
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/config"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/distros"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
 	"github.com/sblinch/kdl-go"
 	"github.com/sblinch/kdl-go/document"
@@ -584,7 +585,7 @@ func SyncDMSConfigs(dmsPath, compositor string, logFunc func(string), sudoPasswo
 		}
 
 		if _, err := os.Stat(link.source); os.IsNotExist(err) {
-			if err := os.WriteFile(link.source, []byte("{}"), 0o644); err != nil {
+			if err := snapshot.WriteFile(link.source, []byte("{}"), 0o644); err != nil {
 				logFunc(fmt.Sprintf("⚠ Warning: Could not create %s: %v", link.source, err))
 				continue
 			}
@@ -681,12 +682,14 @@ func syncNiriGreeterConfig(logFunc func(string), sudoPassword string) error {
 	}
 
 	dmsPath := filepath.Join(greeterDir, "dms.kdl")
+	dmsBefore, dmsExisted := snapshot.ReadExisting(dmsPath)
 	if err := backupFileIfExists(sudoPassword, dmsPath, ".backup"); err != nil {
 		return fmt.Errorf("failed to backup %s: %w", dmsPath, err)
 	}
 	if err := runSudoCmd(sudoPassword, "install", "-o", "root", "-g", greeterGroup, "-m", "0644", dmsTemp.Name(), dmsPath); err != nil {
 		return fmt.Errorf("failed to install greetd niri dms config: %w", err)
 	}
+	recordChange(logFunc, dmsPath, dmsBefore, dmsExisted, content)
 
 	mainContent := fmt.Sprintf("%s\ninclude \"%s\"\n", config.NiriGreeterConfig, dmsPath)
 	mainTemp, err := os.CreateTemp("", "dms-greeter-niri-main-*.kdl")
@@ -704,12 +707,14 @@ func syncNiriGreeterConfig(logFunc func(string), sudoPassword string) error {
 	}
 
 	mainPath := filepath.Join(greeterDir, "config.kdl")
+	mainBefore, mainExisted := snapshot.ReadExisting(mainPath)
 	if err := backupFileIfExists(sudoPassword, mainPath, ".backup"); err != nil {
 		return fmt.Errorf("failed to backup %s: %w", mainPath, err)
 	}
 	if err := runSudoCmd(sudoPassword, "install", "-o", "root", "-g", greeterGroup, "-m", "0644", mainTemp.Name(), mainPath); err != nil {
 		return fmt.Errorf("failed to install greetd niri main config: %w", err)
 	}
+	recordChange(logFunc, mainPath, mainBefore, mainExisted, mainContent)
 
 	if err := ensureGreetdNiriConfig(logFunc, sudoPassword, mainPath); err != nil {
 		logFunc(fmt.Sprintf("⚠ Warning: Failed to update greetd config for niri: %v", err))
@@ -789,11 +794,18 @@ func ensureGreetdNiriConfig(logFunc func(string), sudoPassword string, niriConfi
 	if err := runSudoCmd(sudoPassword, "mv", tmpFile.Name(), configPath); err != nil {
 		return fmt.Errorf("failed to update greetd config: %w", err)
 	}
+	recordChange(logFunc, configPath, data, true, strings.Join(lines, "\n"))
 
 	logFunc(fmt.Sprintf("✓ Updated greetd config to use niri config %s", niriConfigPath))
 	return nil
 }
 
+func recordChange(logFunc func(string), path string, before []byte, existed bool, after string) {
+	if err := snapshot.Record(path, before, existed, []byte(after)); err != nil {
+		logFunc(fmt.Sprintf("⚠ Warning: Could not record config history for %s: %v", path, err))
+	}
+}
+
 func backupFileIfExists(sudoPassword string, path string, suffix string) error {
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return nil
@@ -959,8 +971,9 @@ func ConfigureGreetd(dmsPath, compositor string, logFunc func(string), sudoPassw
 	greeterUser := DetectGreeterGroup()
 
 	var configContent string
-	if data, err := os.ReadFile(configPath); err == nil {
-		configContent = string(data)
+	existingData, existed := snapshot.ReadExisting(configPath)
+	if existed {
+		configContent = string(existingData)
 	} else {
 		configContent = fmt.Sprintf(`[terminal]
 vt = 1
@@ -1032,6 +1045,7 @@ user = "%s"
 	if err := runSudoCmd(sudoPassword, "mv", tmpFile, configPath); err != nil {
 		return fmt.Errorf("failed to move config to /etc/greetd: %w", err)
 	}
+	recordChange(logFunc, configPath, existingData, existed, newConfig)
 
 	cmdDesc := fmt.Sprintf("%s --command %s", wrapperCmd, compositorLower)
 	if dmsPath != "" {
@@ -0,0 +1,243 @@
+package keybinds
+
+import (
+	"sort"
+	"strings"
+)
+
+type BindLayer string
+
+const (
+	LayerDMS         BindLayer = "dms"
+	LayerCompositor  BindLayer = "compositor"
+	LayerApplication BindLayer = "application"
+)
+
+// precedence orders layers by who sees a key press first: DMS overrides
+// replace compositor config binds, and the compositor grabs keys before
+// they ever reach an application.
+func (l BindLayer) precedence() int {
+	switch l {
+	case LayerDMS:
+		return 0
+	case LayerCompositor:
+		return 1
+	default:
+		return 2
+	}
+}
+
+type BindRef struct {
+	Provider    string    `json:"provider"`
+	Layer       BindLayer `json:"layer"`
+	Key         string    `json:"key"`
+	Action      string    `json:"action,omitempty"`
+	Description string    `json:"desc,omitempty"`
+	File        string    `json:"file,omitempty"`
+	Line        int       `json:"line,omitempty"`
+}
+
+type ConflictKind string
+
+const (
+	// ConflictExact is the same key bound more than once within one layer
+	// of one provider, where the winner depends on file order.
+	ConflictExact ConflictKind = "exact"
+	// ConflictShadow is a key bound in a higher layer that hides binds for
+	// the same key in lower layers.
+	ConflictShadow ConflictKind = "shadow"
+)
+
+// Conflict groups binds resolving to the same normalized key. For shadow
+// conflicts the first bind is the one that receives the key.
+type Conflict struct {
+	Kind  ConflictKind `json:"kind"`
+	Key   string       `json:"key"`
+	Binds []BindRef    `json:"binds"`
+}
+
+type ConflictReport struct {
+	Provider  string     `json:"provider"`
+	Checked   int        `json:"checked"`
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+func conflictKey(key string) string {
+	return strings.ToLower(NormalizeKey(key))
+}
+
+type ConflictAnalyzer struct {
+	compositor   Provider
+	applications []Provider
+}
+
+// NewConflictAnalyzer checks the compositor's binds, including its DMS
+// override file, against the application cheatsheets.
+func NewConflictAnalyzer(compositor Provider, applications []Provider) *ConflictAnalyzer {
+	return &ConflictAnalyzer{
+		compositor:   compositor,
+		applications: applications,
+	}
+}
+
+func (a *ConflictAnalyzer) Analyze() (*ConflictReport, error) {
+	binds, err := a.collect()
+	if err != nil {
+		return nil, err
+	}
+	return a.report(binds, ""), nil
+}
+
+// CheckBind reports the conflicts that saving key as a DMS override would
+// introduce. An existing DMS bind on the same key is replaced, so it is not
+// reported against the candidate.
+func (a *ConflictAnalyzer) CheckBind(key, action string) (*ConflictReport, error) {
+	binds, err := a.collect()
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := conflictKey(key)
+	filtered := make([]BindRef, 0, len(binds)+1)
+	for _, b := range binds {
+		if b.Layer == LayerDMS && conflictKey(b.Key) == normalized {
+			continue
+		}
+		filtered = append(filtered, b)
+	}
+
+	candidate := BindRef{
+		Provider: a.compositor.Name(),
+		Layer:    LayerDMS,
+		Key:      key,
+		Action:   action,
+	}
+	if writable, ok := a.compositor.(WritableProvider); ok {
+		candidate.File = writable.GetOverridePath()
+	}
+
+	return a.report(append(filtered, candidate), normalized), nil
+}
+
+func (a *ConflictAnalyzer) collect() ([]BindRef, error) {
+	sheet, err := a.compositor.GetCheatSheet()
+	if err != nil {
+		return nil, err
+	}
+
+	var binds []BindRef
+	name := a.compositor.Name()
+	for _, kb := range flattenBinds(sheet) {
+		layer := LayerCompositor
+		if kb.Source == "dms" {
+			layer = LayerDMS
+		}
+		binds = append(binds, newBindRef(name, layer, kb))
+		if kb.Conflict != nil {
+			binds = append(binds, newBindRef(name, LayerCompositor, *kb.Conflict))
+		}
+	}
+
+	for _, app := range a.applications {
+		sheet, err := app.GetCheatSheet()
+		if err != nil {
+			continue
+		}
+		for _, kb := range flattenBinds(sheet) {
+			binds = append(binds, newBindRef(app.Name(), LayerApplication, kb))
+		}
+	}
+
+	return binds, nil
+}
+
+func (a *ConflictAnalyzer) report(binds []BindRef, only string) *ConflictReport {
+	groups := make(map[string][]BindRef)
+	for _, b := range binds {
+		key := conflictKey(b.Key)
+		if key == "" || (only != "" && key != only) {
+			continue
+		}
+		groups[key] = append(groups[key], b)
+	}
+
+	result := &ConflictReport{
+		Provider:  a.compositor.Name(),
+		Checked:   len(binds),
+		Conflicts: []Conflict{},
+	}
+
+	for _, group := range groups {
+		sort.SliceStable(group, func(i, j int) bool {
+			if pi, pj := group[i].Layer.precedence(), group[j].Layer.precedence(); pi != pj {
+				return pi < pj
+			}
+			if group[i].File != group[j].File {
+				return group[i].File < group[j].File
+			}
+			return group[i].Line < group[j].Line
+		})
+		display := NormalizeKey(group[0].Key)
+
+		bySource := make(map[string][]BindRef)
+		var sources []string
+		for _, b := range group {
+			source := string(b.Layer) + "\x00" + b.Provider
+			if _, ok := bySource[source]; !ok {
+				sources = append(sources, source)
+			}
+			bySource[source] = append(bySource[source], b)
+		}
+		for _, source := range sources {
+			if len(bySource[source]) > 1 {
+				result.Conflicts = append(result.Conflicts, Conflict{Kind: ConflictExact, Key: display, Binds: bySource[source]})
+			}
+		}
+
+		top := group[0]
+		shadow := []BindRef{top}
+		for _, b := range group[1:] {
+			if b.Layer.precedence() > top.Layer.precedence() {
+				shadow = append(shadow, b)
+			}
+		}
+		if len(shadow) > 1 {
+			result.Conflicts = append(result.Conflicts, Conflict{Kind: ConflictShadow, Key: display, Binds: shadow})
+		}
+	}
+
+	sort.SliceStable(result.Conflicts, func(i, j int) bool {
+		if result.Conflicts[i].Key != result.Conflicts[j].Key {
+			return result.Conflicts[i].Key < result.Conflicts[j].Key
+		}
+		return result.Conflicts[i].Kind < result.Conflicts[j].Kind
+	})
+
+	return result
+}
+
+func newBindRef(provider string, layer BindLayer, kb Keybind) BindRef {
+	return BindRef{
+		Provider:    provider,
+		Layer:       layer,
+		Key:         kb.Key,
+		Action:      kb.Action,
+		Description: kb.Description,
+		File:        kb.File,
+		Line:        kb.Line,
+	}
+}
+
+func flattenBinds(sheet *CheatSheet) []Keybind {
+	categories := make([]string, 0, len(sheet.Binds))
+	for category := range sheet.Binds {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var binds []Keybind
+	for _, category := range categories {
+		binds = append(binds, sheet.Binds[category]...)
+	}
+	return binds
+}
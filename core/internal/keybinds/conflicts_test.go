@@ -0,0 +1,125 @@
+package keybinds
+
+import (
+	"testing"
+)
+
+type sheetProvider struct {
+	name  string
+	binds []Keybind
+}
+
+func (s *sheetProvider) Name() string {
+	return s.name
+}
+
+func (s *sheetProvider) GetCheatSheet() (*CheatSheet, error) {
+	return &CheatSheet{
+		Provider: s.name,
+		Binds:    map[string][]Keybind{"All": s.binds},
+	}, nil
+}
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"SUPER+SHIFT+q", "Super+Shift+q"},
+		{"Mod+Shift+Q", "Super+Shift+q"},
+		{"Shift+Mod4+q", "Super+Shift+q"},
+		{"Logo+Control+Return", "Super+Ctrl+Return"},
+		{"Ctrl+Enter", "Ctrl+Return"},
+		{"Mod1+Esc", "Alt+Escape"},
+		{"XF86AudioRaiseVolume", "XF86AudioRaiseVolume"},
+		{"xf86_audioraisevolume", "XF86Audioraisevolume"},
+		{"Ctrl++", "Ctrl+plus"},
+		{"Ctrl + Shift + T", "Ctrl+Shift+t"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeKey(tt.input); got != tt.expected {
+			t.Errorf("NormalizeKey(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+
+	if conflictKey("XF86_AudioRaiseVolume") != conflictKey("XF86AudioRaiseVolume") {
+		t.Error("XF86 spellings should compare equal")
+	}
+}
+
+func TestConflictAnalyzerAnalyze(t *testing.T) {
+	compositor := &sheetProvider{name: "niri", binds: []Keybind{
+		{Key: "Mod+T", Action: "spawn kitty", Source: "config", File: "/cfg/config.kdl", Line: 3},
+		{Key: "Super+t", Action: "spawn foot", Source: "config", File: "/cfg/config.kdl", Line: 9},
+		{
+			Key: "Mod+Q", Action: "close-window", Source: "dms", File: "/cfg/dms/binds.kdl", Line: 2,
+			Conflict: &Keybind{Key: "Mod+Q", Action: "quit", Source: "config", File: "/cfg/config.kdl", Line: 12},
+		},
+		{Key: "Mod+F", Action: "fullscreen-window", Source: "config"},
+	}}
+	app := &sheetProvider{name: "firefox", binds: []Keybind{
+		{Key: "SUPER+q", Description: "Quit", File: "/sheets/firefox.json"},
+		{Key: "Ctrl+t", Description: "New tab", File: "/sheets/firefox.json"},
+	}}
+
+	report, err := NewConflictAnalyzer(compositor, []Provider{app}).Analyze()
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if report.Checked != 7 {
+		t.Errorf("Checked = %d, want 7", report.Checked)
+	}
+	if len(report.Conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %+v", report.Conflicts)
+	}
+
+	quit := report.Conflicts[0]
+	if quit.Kind != ConflictShadow || quit.Key != "Super+q" {
+		t.Errorf("Expected shadow on Super+q, got %s on %s", quit.Kind, quit.Key)
+	}
+	if len(quit.Binds) != 3 || quit.Binds[0].Layer != LayerDMS || quit.Binds[1].Line != 12 || quit.Binds[2].Provider != "firefox" {
+		t.Errorf("Unexpected shadow binds: %+v", quit.Binds)
+	}
+
+	dup := report.Conflicts[1]
+	if dup.Kind != ConflictExact || dup.Key != "Super+t" || len(dup.Binds) != 2 {
+		t.Errorf("Expected exact conflict on Super+t, got %+v", dup)
+	}
+	if dup.Binds[0].Line != 3 || dup.Binds[1].Line != 9 {
+		t.Errorf("Expected binds ordered by line, got %+v", dup.Binds)
+	}
+}
+
+func TestConflictAnalyzerCheckBind(t *testing.T) {
+	compositor := &sheetProvider{name: "hyprland", binds: []Keybind{
+		{Key: "SUPER+Return", Action: "exec kitty", Source: "config"},
+		{Key: "SUPER+e", Action: "exec nautilus", Source: "dms"},
+	}}
+	app := &sheetProvider{name: "terminal", binds: []Keybind{
+		{Key: "Super+Enter", Description: "New window"},
+	}}
+	analyzer := NewConflictAnalyzer(compositor, []Provider{app})
+
+	report, err := analyzer.CheckBind("Mod+Return", "exec foot")
+	if err != nil {
+		t.Fatalf("CheckBind failed: %v", err)
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0].Kind != ConflictShadow {
+		t.Fatalf("Expected one shadow conflict, got %+v", report.Conflicts)
+	}
+	binds := report.Conflicts[0].Binds
+	if len(binds) != 3 || binds[0].Action != "exec foot" || binds[0].Layer != LayerDMS {
+		t.Errorf("Expected candidate to shadow config and app binds, got %+v", binds)
+	}
+
+	report, err = analyzer.CheckBind("SUPER+E", "exec thunar")
+	if err != nil {
+		t.Fatalf("CheckBind failed: %v", err)
+	}
+	if len(report.Conflicts) != 0 {
+		t.Errorf("Replacing a DMS bind should not conflict, got %+v", report.Conflicts)
+	}
+}
@@ -105,3 +105,30 @@ func AutoDiscoverProviders(registry *Registry, config *DiscoveryConfig) error {
 
 	return nil
 }
+
+// ApplicationProviders loads every JSON cheatsheet found by config without
+// registering it, skipping files that fail to load.
+func ApplicationProviders(config *DiscoveryConfig) []Provider {
+	if config == nil {
+		config = DefaultDiscoveryConfig()
+	}
+
+	if jsonProviderFactory == nil {
+		return nil
+	}
+
+	files, err := config.FindJSONFiles()
+	if err != nil {
+		return nil
+	}
+
+	var providers []Provider
+	for _, file := range files {
+		provider, err := jsonProviderFactory(file)
+		if err != nil {
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
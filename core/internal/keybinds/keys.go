@@ -0,0 +1,128 @@
+package keybinds
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var modifierAliases = map[string]string{
+	"super":            "Super",
+	"mod":              "Super",
+	"mod4":             "Super",
+	"logo":             "Super",
+	"win":              "Super",
+	"meta":             "Super",
+	"ctrl":             "Ctrl",
+	"control":          "Ctrl",
+	"primary":          "Ctrl",
+	"alt":              "Alt",
+	"mod1":             "Alt",
+	"shift":            "Shift",
+	"mod5":             "AltGr",
+	"altgr":            "AltGr",
+	"iso_level3_shift": "AltGr",
+	"mod3":             "Hyper",
+	"hyper":            "Hyper",
+}
+
+var modifierOrder = []string{"Super", "Hyper", "Ctrl", "Alt", "AltGr", "Shift"}
+
+var keyAliases = map[string]string{
+	"return":      "Return",
+	"enter":       "Return",
+	"esc":         "Escape",
+	"escape":      "Escape",
+	"del":         "Delete",
+	"delete":      "Delete",
+	"backspace":   "BackSpace",
+	"space":       "space",
+	"tab":         "Tab",
+	"print":       "Print",
+	"printscreen": "Print",
+	"prior":       "Page_Up",
+	"pageup":      "Page_Up",
+	"page_up":     "Page_Up",
+	"next":        "Page_Down",
+	"pagedown":    "Page_Down",
+	"page_down":   "Page_Down",
+	"plus":        "plus",
+	"+":           "plus",
+	"minus":       "minus",
+	"-":           "minus",
+	"equal":       "equal",
+	"=":           "equal",
+	"comma":       "comma",
+	",":           "comma",
+	"period":      "period",
+	".":           "period",
+	"slash":       "slash",
+	"/":           "slash",
+}
+
+// NormalizeKey rewrites a key combo into one spelling shared by every
+// provider, so "SUPER+SHIFT+q", "Mod+Shift+Q" and "Mod4+Shift+q" compare
+// equal. Modifiers are put in a fixed order and XF86 keysyms lose the
+// optional underscore some configs use.
+func NormalizeKey(key string) string {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return ""
+	}
+
+	var parts []string
+	if rest, ok := strings.CutSuffix(key, "++"); ok {
+		parts = append(strings.Split(rest, "+"), "+")
+	} else {
+		parts = strings.Split(key, "+")
+	}
+
+	out, unknown := CanonicalModifiers(parts[:len(parts)-1])
+	sort.Strings(unknown)
+	out = append(out, unknown...)
+
+	return strings.Join(append(out, normalizeKeyName(strings.TrimSpace(parts[len(parts)-1]))), "+")
+}
+
+// CanonicalModifiers maps modifiers as spelled by any provider to their
+// canonical names (Super, Hyper, Ctrl, Alt, AltGr, Shift), in that order and
+// without duplicates. Parts that are not modifiers are returned in unknown.
+func CanonicalModifiers(parts []string) (mods, unknown []string) {
+	present := make(map[string]bool)
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if mod, ok := modifierAliases[strings.ToLower(part)]; ok {
+			present[mod] = true
+			continue
+		}
+		unknown = append(unknown, part)
+	}
+
+	for _, mod := range modifierOrder {
+		if present[mod] {
+			mods = append(mods, mod)
+		}
+	}
+	return mods, unknown
+}
+
+func normalizeKeyName(name string) string {
+	if alias, ok := keyAliases[strings.ToLower(name)]; ok {
+		return alias
+	}
+
+	if len(name) > 4 && strings.EqualFold(name[:4], "xf86") {
+		rest := strings.TrimPrefix(name[4:], "_")
+		r, size := utf8.DecodeRuneInString(rest)
+		return "XF86" + string(unicode.ToUpper(r)) + rest[size:]
+	}
+
+	if utf8.RuneCountInString(name) == 1 {
+		return strings.ToLower(name)
+	}
+	return name
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
 )
 
@@ -153,6 +154,8 @@ func (h *HyprlandProvider) convertKeybind(kb *HyprlandKeyBinding, subcategory st
 		Action:      rawAction,
 		Subcategory: subcategory,
 		Source:      source,
+		File:        kb.Source,
+		Line:        kb.Line,
 		Flags:       kb.Flags,
 	}
 
@@ -164,6 +167,8 @@ func (h *HyprlandProvider) convertKeybind(kb *HyprlandKeyBinding, subcategory st
 				Description: conflictKb.Comment,
 				Action:      h.formatRawAction(conflictKb.Dispatcher, conflictKb.Params),
 				Source:      "config",
+				File:        conflictKb.Source,
+				Line:        conflictKb.Line,
 			}
 		}
 	}
@@ -398,7 +403,7 @@ func (h *HyprlandProvider) getBindSortPriority(action string) int {
 func (h *HyprlandProvider) writeOverrideBinds(binds map[string]*hyprlandOverrideBind) error {
 	overridePath := h.GetOverridePath()
 	content := h.generateBindsContent(binds)
-	return os.WriteFile(overridePath, []byte(content), 0o644)
+	return snapshot.WriteFile(overridePath, []byte(content), 0o644)
 }
 
 func (h *HyprlandProvider) generateBindsContent(binds map[string]*hyprlandOverrideBind) string {
@@ -24,6 +24,7 @@ type HyprlandKeyBinding struct {
 	Params     string   `json:"params"`
 	Comment    string   `json:"comment"`
 	Source     string   `json:"source"`
+	Line       int      `json:"line,omitempty"`
 	Flags      string   `json:"flags"` // Bind flags: l=locked, r=release, e=repeat, n=non-consuming, m=mouse, t=transparent, i=ignore-mods, s=separate, d=description, o=long-press
 }
 
@@ -413,7 +414,7 @@ func (p *HyprlandParser) parseFileWithSource(filePath, sectionName string) (*Hyp
 	section := &HyprlandSection{Name: sectionName}
 	lines := strings.Split(string(data), "\n")
 
-	for _, line := range lines {
+	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
 		if strings.HasPrefix(trimmed, "source") {
@@ -430,6 +431,7 @@ func (p *HyprlandParser) parseFileWithSource(filePath, sectionName string) (*Hyp
 			continue
 		}
 		kb.Source = p.currentSource
+		kb.Line = i + 1
 		if p.addBind(kb) {
 			section.Keybinds = append(section.Keybinds, *kb)
 		}
@@ -483,7 +485,7 @@ func (p *HyprlandParser) parseDMSBindsDirectly(dmsBindsPath string, section *Hyp
 	p.currentSource = dmsBindsPath
 
 	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
+	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
 		if !strings.HasPrefix(trimmed, "bind") {
 			continue
@@ -494,6 +496,7 @@ func (p *HyprlandParser) parseDMSBindsDirectly(dmsBindsPath string, section *Hyp
 			continue
 		}
 		kb.Source = dmsBindsPath
+		kb.Line = i + 1
 		if p.addBind(kb) {
 			section.Keybinds = append(section.Keybinds, *kb)
 		}
@@ -111,6 +111,12 @@ func (j *JSONFileProvider) GetCheatSheet() (*keybinds.CheatSheet, error) {
 		return nil, fmt.Errorf("'binds' must be either an object (categorized) or array (flat)")
 	}
 
+	for _, binds := range categorizedBinds {
+		for i := range binds {
+			binds[i].File = j.filePath
+		}
+	}
+
 	return &keybinds.CheatSheet{
 		Title:    title,
 		Provider: provider,
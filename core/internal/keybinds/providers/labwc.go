@@ -0,0 +1,97 @@
+package providers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
+)
+
+type LabwcProvider struct {
+	configPath string
+}
+
+func NewLabwcProvider(configPath string) *LabwcProvider {
+	if configPath == "" {
+		configDir, err := os.UserConfigDir()
+		if err == nil {
+			configPath = filepath.Join(configDir, "labwc")
+		}
+	}
+	return &LabwcProvider{configPath: configPath}
+}
+
+func (l *LabwcProvider) Name() string {
+	return "labwc"
+}
+
+func (l *LabwcProvider) GetCheatSheet() (*keybinds.CheatSheet, error) {
+	bindings, err := ParseLabwcKeys(l.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse labwc config: %w", err)
+	}
+
+	categorizedBinds := make(map[string][]keybinds.Keybind)
+	for _, kb := range bindings {
+		action := l.formatActions(kb.Actions)
+		desc := kb.Comment
+		if desc == "" {
+			desc = action
+		}
+		bind := keybinds.Keybind{
+			Key:         l.formatKey(kb),
+			Description: desc,
+			Action:      action,
+			Source:      "config",
+			File:        kb.Source,
+			Line:        kb.Line,
+		}
+		category := l.categorizeAction(kb.Actions)
+		categorizedBinds[category] = append(categorizedBinds[category], bind)
+	}
+
+	return &keybinds.CheatSheet{
+		Title:    "labwc Keybinds",
+		Provider: l.Name(),
+		Binds:    categorizedBinds,
+	}, nil
+}
+
+func (l *LabwcProvider) formatKey(kb LabwcKeyBinding) string {
+	parts := make([]string, 0, len(kb.Mods)+1)
+	parts = append(parts, kb.Mods...)
+	parts = append(parts, kb.Key)
+	return strings.Join(parts, "+")
+}
+
+func (l *LabwcProvider) formatActions(actions []LabwcAction) string {
+	parts := make([]string, 0, len(actions))
+	for _, a := range actions {
+		parts = append(parts, formatLabwcAction(a))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (l *LabwcProvider) categorizeAction(actions []LabwcAction) string {
+	if len(actions) == 0 {
+		return "Other"
+	}
+
+	switch name := actions[0].Name; {
+	case strings.EqualFold(name, "Execute"):
+		return "Execute"
+	case strings.Contains(name, "Desktop"):
+		return "Workspace"
+	case name == "Exit", name == "Reconfigure", name == "ShowMenu", name == "ToggleKeybinds":
+		return "System"
+	case strings.Contains(name, "Window"), strings.HasPrefix(name, "Toggle"), strings.Contains(name, "Edge"),
+		strings.Contains(name, "Maximize"), strings.Contains(name, "Output"),
+		name == "Close", name == "Kill", name == "Iconify", name == "Move", name == "Resize",
+		name == "MoveTo", name == "ResizeTo", name == "Focus", name == "Raise", name == "Lower", name == "SetDecorations":
+		return "Window"
+	default:
+		return "Other"
+	}
+}
@@ -0,0 +1,189 @@
+package providers
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+)
+
+type LabwcAction struct {
+	Name   string
+	Params [][2]string
+}
+
+type LabwcKeyBinding struct {
+	Mods    []string
+	Key     string
+	Actions []LabwcAction
+	Comment string
+	Source  string
+	Line    int
+}
+
+var labwcModifiers = map[string]string{
+	"S": "Shift",
+	"C": "Ctrl",
+	"A": "Alt",
+	"W": "Super",
+	"M": "Mod5",
+	"H": "Hyper",
+}
+
+// labwcConfigFile resolves a labwc config directory or file to its rc.xml.
+func labwcConfigFile(configPath string) (string, error) {
+	expanded, err := utils.ExpandPath(configPath)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(expanded)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return filepath.Join(expanded, "rc.xml"), nil
+	}
+	return expanded, nil
+}
+
+// parseLabwcKey splits a labwc key such as "W-S-Return" into modifiers and
+// the keysym.
+func parseLabwcKey(key string) ([]string, string) {
+	parts := strings.Split(key, "-")
+	var mods []string
+	for len(parts) > 1 {
+		mod, ok := labwcModifiers[parts[0]]
+		if !ok {
+			break
+		}
+		mods = append(mods, mod)
+		parts = parts[1:]
+	}
+	return mods, strings.Join(parts, "-")
+}
+
+func ParseLabwcKeys(configPath string) ([]LabwcKeyBinding, error) {
+	rcFile, err := labwcConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(rcFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseLabwcKeybinds(f, rcFile)
+}
+
+func parseLabwcKeybinds(r io.Reader, source string) ([]LabwcKeyBinding, error) {
+	decoder := xml.NewDecoder(r)
+
+	var binds []LabwcKeyBinding
+	var stack []string
+	var current *LabwcKeyBinding
+	var action *LabwcAction
+	var param string
+	var comment string
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			stack = append(stack, name)
+
+			switch {
+			case name == "keybind" && parent == "keyboard":
+				line, _ := decoder.InputPos()
+				mods, key := parseLabwcKey(xmlAttr(t, "key"))
+				current = &LabwcKeyBinding{
+					Mods:    mods,
+					Key:     key,
+					Comment: comment,
+					Source:  source,
+					Line:    line,
+				}
+			case name == "action" && current != nil && action == nil:
+				action = &LabwcAction{Name: xmlAttr(t, "name")}
+				for _, attr := range t.Attr {
+					if attr.Name.Local != "name" {
+						action.Params = append(action.Params, [2]string{attr.Name.Local, attr.Value})
+					}
+				}
+			case action != nil && parent == "action":
+				param = name
+			}
+			comment = ""
+
+		case xml.EndElement:
+			name := t.Name.Local
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+
+			switch {
+			case name == "keybind" && current != nil:
+				binds = append(binds, *current)
+				current = nil
+			case name == "action" && action != nil && len(stack) > 0 && stack[len(stack)-1] == "keybind":
+				current.Actions = append(current.Actions, *action)
+				action = nil
+			case name == param:
+				param = ""
+			}
+			comment = ""
+
+		case xml.CharData:
+			if action != nil && param != "" {
+				if text := strings.TrimSpace(string(t)); text != "" {
+					action.Params = append(action.Params, [2]string{param, text})
+				}
+			}
+
+		case xml.Comment:
+			if len(stack) > 0 && stack[len(stack)-1] == "keyboard" {
+				comment = strings.TrimSpace(string(t))
+			}
+		}
+	}
+
+	return binds, nil
+}
+
+func xmlAttr(el xml.StartElement, name string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func formatLabwcAction(a LabwcAction) string {
+	parts := []string{a.Name}
+	for _, p := range a.Params {
+		switch p[0] {
+		case "command", "execute":
+			parts = append(parts, p[1])
+		default:
+			parts = append(parts, p[0]+"="+p[1])
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,93 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const labwcTestConfig = `<?xml version="1.0"?>
+<labwc_config>
+  <keyboard>
+    <!-- Open terminal -->
+    <keybind key="W-Return">
+      <action name="Execute" command="foot" />
+    </keybind>
+    <keybind key="W-S-q"><action name="Close" /></keybind>
+    <keybind key="W-2">
+      <action name="GoToDesktop"><to>2</to></action>
+    </keybind>
+    <keybind key="C-A-Delete">
+      <action name="Execute"><command>dms ipc call processlist toggle</command></action>
+      <action name="Raise" />
+    </keybind>
+  </keyboard>
+  <mouse>
+    <context name="Frame">
+      <mousebind button="W-Left" action="Drag"><action name="Move" /></mousebind>
+    </context>
+  </mouse>
+</labwc_config>
+`
+
+func TestParseLabwcKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		wantMods int
+		wantKey  string
+	}{
+		{"W-S-Return", 2, "Return"},
+		{"A-Tab", 1, "Tab"},
+		{"XF86AudioMute", 0, "XF86AudioMute"},
+		{"C-minus", 1, "minus"},
+	}
+
+	for _, tt := range tests {
+		mods, key := parseLabwcKey(tt.key)
+		if len(mods) != tt.wantMods || key != tt.wantKey {
+			t.Errorf("parseLabwcKey(%q) = %v %q, want %d mods and %q", tt.key, mods, key, tt.wantMods, tt.wantKey)
+		}
+	}
+}
+
+func TestLabwcProviderGetCheatSheet(t *testing.T) {
+	tmpDir := t.TempDir()
+	rcFile := filepath.Join(tmpDir, "rc.xml")
+	if err := os.WriteFile(rcFile, []byte(labwcTestConfig), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	sheet, err := NewLabwcProvider(tmpDir).GetCheatSheet()
+	if err != nil {
+		t.Fatalf("GetCheatSheet failed: %v", err)
+	}
+
+	total := 0
+	for _, binds := range sheet.Binds {
+		total += len(binds)
+	}
+	if total != 4 {
+		t.Fatalf("Expected 4 keybinds, got %d: %+v", total, sheet.Binds)
+	}
+
+	term := sheet.Binds["Execute"][0]
+	if term.Key != "Super+Return" || term.Action != "Execute foot" || term.Description != "Open terminal" {
+		t.Errorf("Unexpected terminal bind: %+v", term)
+	}
+	if term.File != rcFile || term.Line != 5 {
+		t.Errorf("Expected %s:5, got %s:%d", rcFile, term.File, term.Line)
+	}
+
+	if ws := sheet.Binds["Workspace"]; len(ws) != 1 || ws[0].Action != "GoToDesktop to=2" {
+		t.Errorf("Unexpected workspace binds: %+v", ws)
+	}
+
+	if win := sheet.Binds["Window"]; len(win) != 1 || win[0].Key != "Super+Shift+q" {
+		t.Errorf("Unexpected window binds: %+v", win)
+	}
+
+	multi := sheet.Binds["Execute"][1]
+	if multi.Action != "Execute dms ipc call processlist toggle; Raise" {
+		t.Errorf("Unexpected multi-action bind: %+v", multi)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
 )
 
@@ -149,6 +150,8 @@ func (m *MangoWCProvider) convertKeybind(kb *MangoWCKeyBinding, conflicts map[st
 		Description: desc,
 		Action:      rawAction,
 		Source:      source,
+		File:        kb.Source,
+		Line:        kb.Line,
 	}
 
 	if source == "dms" && conflicts != nil {
@@ -159,6 +162,8 @@ func (m *MangoWCProvider) convertKeybind(kb *MangoWCKeyBinding, conflicts map[st
 				Description: conflictKb.Comment,
 				Action:      m.formatRawAction(conflictKb.Command, conflictKb.Params),
 				Source:      "config",
+				File:        conflictKb.Source,
+				Line:        conflictKb.Line,
 			}
 		}
 	}
@@ -360,7 +365,7 @@ func (m *MangoWCProvider) getBindSortPriority(action string) int {
 func (m *MangoWCProvider) writeOverrideBinds(binds map[string]*mangowcOverrideBind) error {
 	overridePath := m.GetOverridePath()
 	content := m.generateBindsContent(binds)
-	return os.WriteFile(overridePath, []byte(content), 0o644)
+	return snapshot.WriteFile(overridePath, []byte(content), 0o644)
 }
 
 func (m *MangoWCProvider) generateBindsContent(binds map[string]*mangowcOverrideBind) string {
@@ -22,6 +22,7 @@ type MangoWCKeyBinding struct {
 	Params  string   `json:"params"`
 	Comment string   `json:"comment"`
 	Source  string   `json:"source"`
+	Line    int      `json:"line,omitempty"`
 }
 
 type MangoWCParser struct {
@@ -478,6 +479,7 @@ func (p *MangoWCParser) parseFileWithSource(filePath string) ([]MangoWCKeyBindin
 			continue
 		}
 		kb.Source = p.currentSource
+		kb.Line = lineNum + 1
 		p.addBind(kb)
 		keybinds = append(keybinds, *kb)
 	}
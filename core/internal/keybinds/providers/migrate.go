@@ -0,0 +1,770 @@
+package providers
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
+)
+
+type ActionKind string
+
+const (
+	ActionSpawn           ActionKind = "spawn"
+	ActionDMS             ActionKind = "dms"
+	ActionClose           ActionKind = "close"
+	ActionFocus           ActionKind = "focus"
+	ActionMove            ActionKind = "move"
+	ActionWorkspace       ActionKind = "workspace"
+	ActionMoveToWorkspace ActionKind = "moveToWorkspace"
+	ActionFullscreen      ActionKind = "fullscreen"
+	ActionFloating        ActionKind = "floating"
+	ActionExit            ActionKind = "exit"
+	ActionReload          ActionKind = "reload"
+)
+
+// CanonicalAction is the compositor-neutral form of a bind action. Arg is
+// the command for spawn/dms, a direction (left, right, up, down) for
+// focus/move, and a 1-based number for workspace actions.
+type CanonicalAction struct {
+	Kind ActionKind `json:"kind"`
+	Arg  string     `json:"arg,omitempty"`
+}
+
+type MigratedBind struct {
+	Key          string     `json:"key"`
+	Action       string     `json:"action"`
+	Description  string     `json:"desc,omitempty"`
+	Kind         ActionKind `json:"kind"`
+	SourceKey    string     `json:"sourceKey"`
+	SourceAction string     `json:"sourceAction"`
+}
+
+type UntranslatableBind struct {
+	Key    string `json:"key"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+type MigrationResult struct {
+	From           string               `json:"from"`
+	To             string               `json:"to"`
+	Path           string               `json:"path"`
+	DryRun         bool                 `json:"dryRun"`
+	Migrated       []MigratedBind       `json:"migrated"`
+	Untranslatable []UntranslatableBind `json:"untranslatable"`
+}
+
+// syntaxFamily maps provider names onto the bind syntax they share.
+func syntaxFamily(provider string) string {
+	switch provider {
+	case "scroll":
+		return "sway"
+	default:
+		return provider
+	}
+}
+
+func supportsMigration(provider string) bool {
+	switch syntaxFamily(provider) {
+	case "hyprland", "niri", "sway", "mangowc", "miracle":
+		return true
+	}
+	return false
+}
+
+// CheckMigration reports the first of the named providers whose binds cannot
+// be translated, such as labwc whose XML actions have no catalogue entries.
+func CheckMigration(providers ...string) error {
+	for _, name := range providers {
+		if !supportsMigration(name) {
+			return fmt.Errorf("%s not supported for migration", name)
+		}
+	}
+	return nil
+}
+
+var directionAliases = map[string]string{
+	"l": "left", "left": "left",
+	"r": "right", "right": "right",
+	"u": "up", "up": "up",
+	"d": "down", "down": "down",
+}
+
+func spawnAction(cmd string) (CanonicalAction, bool) {
+	cmd = strings.TrimSpace(cmd)
+	if cmd == "" {
+		return CanonicalAction{}, false
+	}
+	if strings.HasPrefix(cmd, "dms ") {
+		return CanonicalAction{Kind: ActionDMS, Arg: cmd}, true
+	}
+	return CanonicalAction{Kind: ActionSpawn, Arg: cmd}, true
+}
+
+func workspaceArg(s string) (string, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 1 {
+		return "", false
+	}
+	return strconv.Itoa(n), true
+}
+
+// ParseAction converts a provider's raw action string into its canonical
+// form; ok is false for actions outside the shared catalogue.
+func ParseAction(provider, action string) (CanonicalAction, bool) {
+	action = strings.TrimSpace(action)
+	head, rest, _ := strings.Cut(action, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch syntaxFamily(provider) {
+	case "hyprland":
+		return parseHyprlandAction(head, rest)
+	case "niri":
+		return parseNiriAction(head, rest)
+	case "sway":
+		return parseSwayAction(head, rest)
+	case "mangowc":
+		return parseMangoWCAction(head, rest)
+	case "miracle":
+		return parseMiracleAction(action)
+	}
+	return CanonicalAction{}, false
+}
+
+func parseHyprlandAction(dispatcher, params string) (CanonicalAction, bool) {
+	switch dispatcher {
+	case "exec":
+		return spawnAction(params)
+	case "killactive":
+		return CanonicalAction{Kind: ActionClose}, true
+	case "movefocus", "movewindow":
+		dir, ok := directionAliases[params]
+		if !ok {
+			return CanonicalAction{}, false
+		}
+		kind := ActionFocus
+		if dispatcher == "movewindow" {
+			kind = ActionMove
+		}
+		return CanonicalAction{Kind: kind, Arg: dir}, true
+	case "workspace", "movetoworkspace":
+		n, ok := workspaceArg(params)
+		if !ok {
+			return CanonicalAction{}, false
+		}
+		kind := ActionWorkspace
+		if dispatcher == "movetoworkspace" {
+			kind = ActionMoveToWorkspace
+		}
+		return CanonicalAction{Kind: kind, Arg: n}, true
+	case "fullscreen":
+		if params != "" && params != "0" {
+			return CanonicalAction{}, false
+		}
+		return CanonicalAction{Kind: ActionFullscreen}, true
+	case "togglefloating":
+		return CanonicalAction{Kind: ActionFloating}, true
+	case "exit":
+		return CanonicalAction{Kind: ActionExit}, true
+	}
+	return CanonicalAction{}, false
+}
+
+func parseNiriAction(name, args string) (CanonicalAction, bool) {
+	switch name {
+	case "spawn":
+		if cmd, ok := strings.CutPrefix(args, "sh -c "); ok {
+			return spawnAction(unquoteNiriArg(cmd))
+		}
+		if cmd, ok := strings.CutPrefix(args, "bash -c "); ok {
+			return spawnAction(unquoteNiriArg(cmd))
+		}
+		return spawnAction(args)
+	case "spawn-sh":
+		return spawnAction(unquoteNiriArg(args))
+	case "close-window":
+		return CanonicalAction{Kind: ActionClose}, true
+	case "focus-column-left", "focus-column-right", "focus-window-up", "focus-window-down":
+		return CanonicalAction{Kind: ActionFocus, Arg: name[strings.LastIndex(name, "-")+1:]}, true
+	case "move-column-left", "move-column-right", "move-window-up", "move-window-down":
+		return CanonicalAction{Kind: ActionMove, Arg: name[strings.LastIndex(name, "-")+1:]}, true
+	case "focus-workspace", "move-column-to-workspace", "move-window-to-workspace":
+		n, ok := workspaceArg(args)
+		if !ok {
+			return CanonicalAction{}, false
+		}
+		kind := ActionWorkspace
+		if name != "focus-workspace" {
+			kind = ActionMoveToWorkspace
+		}
+		return CanonicalAction{Kind: kind, Arg: n}, true
+	case "fullscreen-window":
+		return CanonicalAction{Kind: ActionFullscreen}, true
+	case "toggle-window-floating":
+		return CanonicalAction{Kind: ActionFloating}, true
+	case "quit":
+		return CanonicalAction{Kind: ActionExit}, true
+	}
+	return CanonicalAction{}, false
+}
+
+func unquoteNiriArg(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = strings.ReplaceAll(s[1:len(s)-1], `\"`, `"`)
+	}
+	return s
+}
+
+func parseSwayAction(command, args string) (CanonicalAction, bool) {
+	switch command {
+	case "exec":
+		return spawnAction(strings.TrimPrefix(args, "--no-startup-id "))
+	case "kill":
+		return CanonicalAction{Kind: ActionClose}, true
+	case "focus":
+		if dir, ok := directionAliases[args]; ok && len(args) > 1 {
+			return CanonicalAction{Kind: ActionFocus, Arg: dir}, true
+		}
+	case "move":
+		if dir, ok := directionAliases[args]; ok && len(args) > 1 {
+			return CanonicalAction{Kind: ActionMove, Arg: dir}, true
+		}
+		for _, prefix := range []string{"container to workspace number ", "container to workspace ", "window to workspace number ", "window to workspace "} {
+			if ws, ok := strings.CutPrefix(args, prefix); ok {
+				if n, ok := workspaceArg(ws); ok {
+					return CanonicalAction{Kind: ActionMoveToWorkspace, Arg: n}, true
+				}
+			}
+		}
+	case "workspace":
+		if n, ok := workspaceArg(strings.TrimPrefix(args, "number ")); ok {
+			return CanonicalAction{Kind: ActionWorkspace, Arg: n}, true
+		}
+	case "fullscreen":
+		if args == "" || args == "toggle" {
+			return CanonicalAction{Kind: ActionFullscreen}, true
+		}
+	case "floating":
+		if args == "toggle" {
+			return CanonicalAction{Kind: ActionFloating}, true
+		}
+	case "exit":
+		return CanonicalAction{Kind: ActionExit}, true
+	case "reload":
+		return CanonicalAction{Kind: ActionReload}, true
+	}
+	return CanonicalAction{}, false
+}
+
+func parseMangoWCAction(command, params string) (CanonicalAction, bool) {
+	switch command {
+	case "spawn", "spawn_shell":
+		return spawnAction(params)
+	case "killclient":
+		return CanonicalAction{Kind: ActionClose}, true
+	case "focusdir", "exchange_client":
+		dir, ok := directionAliases[params]
+		if !ok {
+			return CanonicalAction{}, false
+		}
+		kind := ActionFocus
+		if command == "exchange_client" {
+			kind = ActionMove
+		}
+		return CanonicalAction{Kind: kind, Arg: dir}, true
+	case "view", "tag":
+		n, ok := workspaceArg(params)
+		if !ok {
+			return CanonicalAction{}, false
+		}
+		kind := ActionWorkspace
+		if command == "tag" {
+			kind = ActionMoveToWorkspace
+		}
+		return CanonicalAction{Kind: kind, Arg: n}, true
+	case "togglefullscreen":
+		return CanonicalAction{Kind: ActionFullscreen}, true
+	case "togglefloating":
+		return CanonicalAction{Kind: ActionFloating}, true
+	case "quit":
+		return CanonicalAction{Kind: ActionExit}, true
+	case "reload_config":
+		return CanonicalAction{Kind: ActionReload}, true
+	}
+	return CanonicalAction{}, false
+}
+
+func parseMiracleAction(action string) (CanonicalAction, bool) {
+	switch action {
+	case "quit_active_window":
+		return CanonicalAction{Kind: ActionClose}, true
+	case "select_left", "select_right", "select_up", "select_down":
+		return CanonicalAction{Kind: ActionFocus, Arg: strings.TrimPrefix(action, "select_")}, true
+	case "move_left", "move_right", "move_up", "move_down":
+		return CanonicalAction{Kind: ActionMove, Arg: strings.TrimPrefix(action, "move_")}, true
+	case "fullscreen":
+		return CanonicalAction{Kind: ActionFullscreen}, true
+	case "toggle_floating":
+		return CanonicalAction{Kind: ActionFloating}, true
+	case "quit_compositor":
+		return CanonicalAction{Kind: ActionExit}, true
+	}
+
+	for prefix, kind := range map[string]ActionKind{"select_workspace_": ActionWorkspace, "move_to_workspace_": ActionMoveToWorkspace} {
+		if idx, ok := strings.CutPrefix(action, prefix); ok {
+			n, err := strconv.Atoi(idx)
+			if err != nil || n < 0 {
+				return CanonicalAction{}, false
+			}
+			return CanonicalAction{Kind: kind, Arg: strconv.Itoa(n + 1)}, true
+		}
+	}
+
+	if isMiracleBuiltinAction(action) {
+		return CanonicalAction{}, false
+	}
+	return spawnAction(action)
+}
+
+var hyprlandDirections = map[string]string{"left": "l", "right": "r", "up": "u", "down": "d"}
+
+// FormatAction renders a canonical action in a provider's raw syntax.
+func FormatAction(provider string, a CanonicalAction) (string, error) {
+	switch syntaxFamily(provider) {
+	case "hyprland":
+		return formatHyprlandAction(a)
+	case "niri":
+		return formatNiriAction(a)
+	case "sway":
+		return formatSwayAction(a)
+	case "mangowc":
+		return formatMangoWCAction(a)
+	case "miracle":
+		return formatMiracleAction(a)
+	}
+	return "", fmt.Errorf("provider %s does not support migration", provider)
+}
+
+func unsupported(provider string, a CanonicalAction) error {
+	return fmt.Errorf("%s has no equivalent for %s", provider, a.Kind)
+}
+
+func formatHyprlandAction(a CanonicalAction) (string, error) {
+	switch a.Kind {
+	case ActionSpawn, ActionDMS:
+		return "exec " + a.Arg, nil
+	case ActionClose:
+		return "killactive", nil
+	case ActionFocus:
+		return "movefocus " + hyprlandDirections[a.Arg], nil
+	case ActionMove:
+		return "movewindow " + hyprlandDirections[a.Arg], nil
+	case ActionWorkspace:
+		return "workspace " + a.Arg, nil
+	case ActionMoveToWorkspace:
+		return "movetoworkspace " + a.Arg, nil
+	case ActionFullscreen:
+		return "fullscreen 0", nil
+	case ActionFloating:
+		return "togglefloating", nil
+	case ActionExit:
+		return "exit", nil
+	case ActionReload:
+		return "exec hyprctl reload", nil
+	}
+	return "", unsupported("hyprland", a)
+}
+
+func formatNiriAction(a CanonicalAction) (string, error) {
+	horizontal := a.Arg == "left" || a.Arg == "right"
+	switch a.Kind {
+	case ActionSpawn, ActionDMS:
+		if strings.ContainsAny(a.Arg, "|&;<>$`*\"'(){}") {
+			return fmt.Sprintf(`spawn sh -c "%s"`, strings.ReplaceAll(a.Arg, `"`, `\"`)), nil
+		}
+		return "spawn " + a.Arg, nil
+	case ActionClose:
+		return "close-window", nil
+	case ActionFocus:
+		if horizontal {
+			return "focus-column-" + a.Arg, nil
+		}
+		return "focus-window-" + a.Arg, nil
+	case ActionMove:
+		if horizontal {
+			return "move-column-" + a.Arg, nil
+		}
+		return "move-window-" + a.Arg, nil
+	case ActionWorkspace:
+		return "focus-workspace " + a.Arg, nil
+	case ActionMoveToWorkspace:
+		return "move-column-to-workspace " + a.Arg, nil
+	case ActionFullscreen:
+		return "fullscreen-window", nil
+	case ActionFloating:
+		return "toggle-window-floating", nil
+	case ActionExit:
+		return "quit", nil
+	}
+	return "", unsupported("niri", a)
+}
+
+func formatSwayAction(a CanonicalAction) (string, error) {
+	switch a.Kind {
+	case ActionSpawn, ActionDMS:
+		return "exec " + a.Arg, nil
+	case ActionClose:
+		return "kill", nil
+	case ActionFocus:
+		return "focus " + a.Arg, nil
+	case ActionMove:
+		return "move " + a.Arg, nil
+	case ActionWorkspace:
+		return "workspace number " + a.Arg, nil
+	case ActionMoveToWorkspace:
+		return "move container to workspace number " + a.Arg, nil
+	case ActionFullscreen:
+		return "fullscreen toggle", nil
+	case ActionFloating:
+		return "floating toggle", nil
+	case ActionExit:
+		return "exit", nil
+	case ActionReload:
+		return "reload", nil
+	}
+	return "", unsupported("sway", a)
+}
+
+func formatMangoWCAction(a CanonicalAction) (string, error) {
+	switch a.Kind {
+	case ActionSpawn, ActionDMS:
+		if strings.ContainsAny(a.Arg, "|&;<>$`*") {
+			return "spawn_shell " + a.Arg, nil
+		}
+		return "spawn " + a.Arg, nil
+	case ActionClose:
+		return "killclient", nil
+	case ActionFocus:
+		return "focusdir " + a.Arg, nil
+	case ActionMove:
+		return "exchange_client " + a.Arg, nil
+	case ActionWorkspace:
+		return "view " + a.Arg, nil
+	case ActionMoveToWorkspace:
+		return "tag " + a.Arg, nil
+	case ActionFullscreen:
+		return "togglefullscreen", nil
+	case ActionFloating:
+		return "togglefloating", nil
+	case ActionExit:
+		return "quit", nil
+	case ActionReload:
+		return "reload_config", nil
+	}
+	return "", unsupported("mangowc", a)
+}
+
+func formatMiracleAction(a CanonicalAction) (string, error) {
+	switch a.Kind {
+	case ActionSpawn, ActionDMS:
+		return a.Arg, nil
+	case ActionClose:
+		return "quit_active_window", nil
+	case ActionFocus:
+		return "select_" + a.Arg, nil
+	case ActionMove:
+		return "move_" + a.Arg, nil
+	case ActionWorkspace, ActionMoveToWorkspace:
+		n, _ := strconv.Atoi(a.Arg)
+		if n < 1 || n > 10 {
+			return "", fmt.Errorf("miracle has no workspace %s binding", a.Arg)
+		}
+		if a.Kind == ActionWorkspace {
+			return fmt.Sprintf("select_workspace_%d", n-1), nil
+		}
+		return fmt.Sprintf("move_to_workspace_%d", n-1), nil
+	case ActionFullscreen:
+		return "fullscreen", nil
+	case ActionFloating:
+		return "toggle_floating", nil
+	case ActionExit:
+		return "quit_compositor", nil
+	}
+	return "", unsupported("miracle", a)
+}
+
+// TranslateKey rewrites a key combo from one provider's spelling to
+// another's, e.g. "SUPER+SHIFT+q" to "Mod+Shift+Q" for niri.
+func TranslateKey(provider, key string) (string, error) {
+	parts := strings.FieldsFunc(key, func(r rune) bool { return r == '+' || r == ' ' })
+	if len(parts) == 0 {
+		return "", fmt.Errorf("empty key")
+	}
+
+	mods, unknown := keybinds.CanonicalModifiers(parts[:len(parts)-1])
+	if len(unknown) > 0 {
+		return "", fmt.Errorf("unknown modifier %q", unknown[0])
+	}
+
+	name := parts[len(parts)-1]
+	family := syntaxFamily(provider)
+	if pointer, ok := pointerKeys[strings.ToLower(name)]; ok {
+		formatted, err := formatPointerKey(family, provider, pointer)
+		if err != nil {
+			return "", err
+		}
+		name = formatted
+	} else if len([]rune(name)) == 1 {
+		if family == "niri" {
+			name = strings.ToUpper(name)
+		} else {
+			name = strings.ToLower(name)
+		}
+	}
+
+	out := make([]string, 0, len(parts))
+	for _, mod := range mods {
+		formatted, ok := formatModifier(family, mod)
+		if !ok {
+			return "", fmt.Errorf("%s has no %s modifier", provider, mod)
+		}
+		out = append(out, formatted)
+	}
+	return strings.Join(append(out, name), "+"), nil
+}
+
+// pointerKeys maps the wheel and mouse button names of every provider onto
+// niri's, which serve as the canonical ones.
+var pointerKeys = map[string]string{
+	"wheelscrollup":       "WheelScrollUp",
+	"wheelscrolldown":     "WheelScrollDown",
+	"wheelscrollleft":     "WheelScrollLeft",
+	"wheelscrollright":    "WheelScrollRight",
+	"touchpadscrollup":    "TouchpadScrollUp",
+	"touchpadscrolldown":  "TouchpadScrollDown",
+	"touchpadscrollleft":  "TouchpadScrollLeft",
+	"touchpadscrollright": "TouchpadScrollRight",
+	"mouseleft":           "MouseLeft",
+	"mouseright":          "MouseRight",
+	"mousemiddle":         "MouseMiddle",
+	"mouseback":           "MouseBack",
+	"mouseforward":        "MouseForward",
+	"mouse_up":            "WheelScrollUp",
+	"mouse_down":          "WheelScrollDown",
+	"mouse_left":          "WheelScrollLeft",
+	"mouse_right":         "WheelScrollRight",
+	"mouse:272":           "MouseLeft",
+	"mouse:273":           "MouseRight",
+	"mouse:274":           "MouseMiddle",
+	"mouse:275":           "MouseBack",
+	"mouse:276":           "MouseForward",
+	"button1":             "MouseLeft",
+	"button2":             "MouseMiddle",
+	"button3":             "MouseRight",
+	"button4":             "WheelScrollUp",
+	"button5":             "WheelScrollDown",
+	"button6":             "WheelScrollLeft",
+	"button7":             "WheelScrollRight",
+	"button8":             "MouseBack",
+	"button9":             "MouseForward",
+}
+
+var hyprlandPointerKeys = map[string]string{
+	"WheelScrollUp":    "mouse_up",
+	"WheelScrollDown":  "mouse_down",
+	"WheelScrollLeft":  "mouse_left",
+	"WheelScrollRight": "mouse_right",
+	"MouseLeft":        "mouse:272",
+	"MouseRight":       "mouse:273",
+	"MouseMiddle":      "mouse:274",
+	"MouseBack":        "mouse:275",
+	"MouseForward":     "mouse:276",
+}
+
+// formatPointerKey spells a canonical wheel or mouse button name for a
+// provider family. Families that bind the pointer outside their key binds
+// report the bind as untranslatable.
+func formatPointerKey(family, provider, name string) (string, error) {
+	switch family {
+	case "niri":
+		return name, nil
+	case "hyprland":
+		if key, ok := hyprlandPointerKeys[name]; ok {
+			return key, nil
+		}
+	case "sway":
+		return "", fmt.Errorf("%s only binds %s over windows with --whole-window", provider, name)
+	case "mangowc":
+		return "", fmt.Errorf("%s binds %s with axisbind or mousebind", provider, name)
+	}
+	return "", fmt.Errorf("%s has no %s key", provider, name)
+}
+
+// formatModifier spells a canonical modifier for a provider family. Hyper
+// and AltGr only exist where the config can name the raw xkb modifier.
+func formatModifier(family, mod string) (string, bool) {
+	switch family {
+	case "hyprland":
+		switch mod {
+		case "Ctrl":
+			return "CTRL", true
+		case "Hyper":
+			return "MOD3", true
+		case "AltGr":
+			return "MOD5", true
+		}
+		return strings.ToUpper(mod), true
+	case "mangowc":
+		switch mod {
+		case "Ctrl":
+			return "CTRL", true
+		case "Hyper", "AltGr":
+			return "", false
+		}
+		return strings.ToUpper(mod), true
+	case "niri":
+		switch mod {
+		case "Super":
+			return "Mod", true
+		case "AltGr":
+			return "ISO_Level3_Shift", true
+		case "Hyper":
+			return "", false
+		}
+	case "sway":
+		switch mod {
+		case "Super":
+			return "Mod4", true
+		case "Ctrl":
+			return "Control", true
+		case "Hyper":
+			return "Mod3", true
+		case "AltGr":
+			return "Mod5", true
+		}
+	default:
+		if mod == "Hyper" || mod == "AltGr" {
+			return "", false
+		}
+	}
+	return mod, true
+}
+
+func cheatSheetBinds(sheet *keybinds.CheatSheet) []keybinds.Keybind {
+	var all []keybinds.Keybind
+	for _, binds := range sheet.Binds {
+		all = append(all, binds...)
+	}
+	return all
+}
+
+// Migrate translates every bind from one provider and, unless dryRun is set,
+// writes the translations through the target's override file.
+func Migrate(from keybinds.Provider, to keybinds.WritableProvider, dryRun bool) (*MigrationResult, error) {
+	if err := CheckMigration(from.Name(), to.Name()); err != nil {
+		return nil, err
+	}
+
+	sheet, err := from.GetCheatSheet()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MigrationResult{
+		From:           from.Name(),
+		To:             to.Name(),
+		Path:           to.GetOverridePath(),
+		DryRun:         dryRun,
+		Migrated:       []MigratedBind{},
+		Untranslatable: []UntranslatableBind{},
+	}
+
+	binds := cheatSheetBinds(sheet)
+	sortKeybinds(binds)
+
+	seen := make(map[string]bool)
+	for _, kb := range binds {
+		canonical, ok := ParseAction(from.Name(), kb.Action)
+		if !ok {
+			result.Untranslatable = append(result.Untranslatable, UntranslatableBind{
+				Key: kb.Key, Action: kb.Action, Reason: "action not in the migration catalogue",
+			})
+			continue
+		}
+
+		action, err := FormatAction(to.Name(), canonical)
+		if err != nil {
+			result.Untranslatable = append(result.Untranslatable, UntranslatableBind{Key: kb.Key, Action: kb.Action, Reason: err.Error()})
+			continue
+		}
+
+		key, err := TranslateKey(to.Name(), kb.Key)
+		if err != nil {
+			result.Untranslatable = append(result.Untranslatable, UntranslatableBind{Key: kb.Key, Action: kb.Action, Reason: err.Error()})
+			continue
+		}
+		if seen[strings.ToLower(key)] {
+			continue
+		}
+		seen[strings.ToLower(key)] = true
+
+		desc := kb.Description
+		if desc == kb.Action {
+			desc = ""
+		}
+
+		if !dryRun {
+			if err := to.SetBind(key, action, desc, bindOptions(kb)); err != nil {
+				result.Untranslatable = append(result.Untranslatable, UntranslatableBind{Key: kb.Key, Action: kb.Action, Reason: err.Error()})
+				continue
+			}
+		}
+
+		result.Migrated = append(result.Migrated, MigratedBind{
+			Key:          key,
+			Action:       action,
+			Description:  desc,
+			Kind:         canonical.Kind,
+			SourceKey:    kb.Key,
+			SourceAction: kb.Action,
+		})
+	}
+
+	return result, nil
+}
+
+// sortKeybinds orders DMS binds first so they claim their keys before
+// config binds they override, keeping the rest ordered by key.
+func sortKeybinds(binds []keybinds.Keybind) {
+	sort.SliceStable(binds, func(i, j int) bool {
+		iDMS, jDMS := binds[i].Source == "dms", binds[j].Source == "dms"
+		if iDMS != jDMS {
+			return iDMS
+		}
+		return binds[i].Key < binds[j].Key
+	})
+}
+
+func bindOptions(kb keybinds.Keybind) map[string]any {
+	options := make(map[string]any)
+	if kb.AllowWhenLocked {
+		options["allow-when-locked"] = true
+	}
+	if kb.AllowInhibiting != nil {
+		options["allow-inhibiting"] = *kb.AllowInhibiting
+	}
+	if kb.Repeat != nil {
+		options["repeat"] = *kb.Repeat
+	}
+	if kb.Flags != "" {
+		options["flags"] = kb.Flags
+	}
+	if len(options) == 0 {
+		return nil
+	}
+	return options
+}
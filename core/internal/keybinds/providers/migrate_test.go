@@ -0,0 +1,217 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseAndFormatAction(t *testing.T) {
+	tests := []struct {
+		provider string
+		action   string
+		want     CanonicalAction
+	}{
+		{"hyprland", "exec kitty", CanonicalAction{Kind: ActionSpawn, Arg: "kitty"}},
+		{"hyprland", "movefocus l", CanonicalAction{Kind: ActionFocus, Arg: "left"}},
+		{"hyprland", "movetoworkspace 3", CanonicalAction{Kind: ActionMoveToWorkspace, Arg: "3"}},
+		{"niri", `spawn sh -c "dms ipc call spotlight toggle"`, CanonicalAction{Kind: ActionDMS, Arg: "dms ipc call spotlight toggle"}},
+		{"niri", "focus-window-down", CanonicalAction{Kind: ActionFocus, Arg: "down"}},
+		{"niri", "move-column-to-workspace 2", CanonicalAction{Kind: ActionMoveToWorkspace, Arg: "2"}},
+		{"sway", "workspace number 4", CanonicalAction{Kind: ActionWorkspace, Arg: "4"}},
+		{"scroll", "move container to workspace number 5", CanonicalAction{Kind: ActionMoveToWorkspace, Arg: "5"}},
+		{"sway", "floating toggle", CanonicalAction{Kind: ActionFloating}},
+		{"mangowc", "exchange_client right", CanonicalAction{Kind: ActionMove, Arg: "right"}},
+		{"mangowc", "view 1", CanonicalAction{Kind: ActionWorkspace, Arg: "1"}},
+		{"miracle", "select_workspace_0", CanonicalAction{Kind: ActionWorkspace, Arg: "1"}},
+		{"miracle", "firefox", CanonicalAction{Kind: ActionSpawn, Arg: "firefox"}},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseAction(tt.provider, tt.action)
+		if !ok {
+			t.Errorf("ParseAction(%s, %q) not ok", tt.provider, tt.action)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAction(%s, %q) = %+v, want %+v", tt.provider, tt.action, got, tt.want)
+			continue
+		}
+
+		formatted, err := FormatAction(tt.provider, got)
+		if err != nil {
+			t.Errorf("FormatAction(%s, %+v) error: %v", tt.provider, got, err)
+			continue
+		}
+		again, ok := ParseAction(tt.provider, formatted)
+		if !ok || again != got {
+			t.Errorf("round trip %s %q -> %q -> %+v", tt.provider, tt.action, formatted, again)
+		}
+	}
+
+	for _, bad := range []struct{ provider, action string }{
+		{"hyprland", "pseudo"},
+		{"niri", "consume-window-into-column"},
+		{"sway", "layout tabbed"},
+		{"miracle", "toggle_resize"},
+	} {
+		if _, ok := ParseAction(bad.provider, bad.action); ok {
+			t.Errorf("ParseAction(%s, %q) should not translate", bad.provider, bad.action)
+		}
+	}
+
+	if _, err := FormatAction("niri", CanonicalAction{Kind: ActionReload}); err == nil {
+		t.Error("niri reload should be untranslatable")
+	}
+	if _, err := FormatAction("miracle", CanonicalAction{Kind: ActionWorkspace, Arg: "11"}); err == nil {
+		t.Error("miracle workspace 11 should be untranslatable")
+	}
+}
+
+func TestTranslateKey(t *testing.T) {
+	tests := []struct {
+		provider string
+		key      string
+		want     string
+	}{
+		{"niri", "SUPER+SHIFT+q", "Mod+Shift+Q"},
+		{"hyprland", "Mod+Ctrl+Return", "SUPER+CTRL+Return"},
+		{"sway", "SUPER+ALT+h", "Mod4+Alt+h"},
+		{"mangowc", "Mod4+Control+1", "SUPER+CTRL+1"},
+		{"miracle", "Mod+Shift+T", "Super+Shift+t"},
+		{"sway", "XF86AudioMute", "XF86AudioMute"},
+		{"sway", "Hyper+Mod5+a", "Mod3+Mod5+a"},
+		{"hyprland", "ISO_Level3_Shift+x", "MOD5+x"},
+		{"niri", "AltGr+x", "ISO_Level3_Shift+X"},
+		{"hyprland", "Mod+Ctrl+Shift+WheelScrollDown", "SUPER+CTRL+SHIFT+mouse_down"},
+		{"hyprland", "Mod+WheelScrollRight", "SUPER+mouse_right"},
+		{"niri", "SUPER+mouse:272", "Mod+MouseLeft"},
+		{"niri", "Mod4+button4", "Mod+WheelScrollUp"},
+	}
+
+	for _, tt := range tests {
+		got, err := TranslateKey(tt.provider, tt.key)
+		if err != nil {
+			t.Errorf("TranslateKey(%s, %q) error: %v", tt.provider, tt.key, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("TranslateKey(%s, %q) = %q, want %q", tt.provider, tt.key, got, tt.want)
+		}
+	}
+
+	if _, err := TranslateKey("niri", "Hyper+q"); err == nil {
+		t.Error("expected error for a modifier niri cannot express")
+	}
+	if _, err := TranslateKey("sway", "Fn+q"); err == nil {
+		t.Error("expected error for unknown modifier")
+	}
+	for _, provider := range []string{"sway", "mangowc", "miracle"} {
+		if _, err := TranslateKey(provider, "Mod+WheelScrollDown"); err == nil {
+			t.Errorf("expected %s to report wheel binds as untranslatable", provider)
+		}
+	}
+	if _, err := TranslateKey("hyprland", "Mod+TouchpadScrollDown"); err == nil {
+		t.Error("expected error for a touchpad scroll bind hyprland cannot express")
+	}
+}
+
+func TestMigrateWheelBinds(t *testing.T) {
+	niriDir := t.TempDir()
+	niriConfig := `binds {
+    Mod+Return { spawn "kitty"; }
+    Mod+WheelScrollRight { focus-column-right; }
+    Mod+Ctrl+Shift+WheelScrollDown cooldown-ms=150 { move-column-right; }
+}
+`
+	if err := os.WriteFile(filepath.Join(niriDir, "config.kdl"), []byte(niriConfig), 0o644); err != nil {
+		t.Fatalf("Failed to write niri config: %v", err)
+	}
+	source := NewNiriProvider(niriDir)
+
+	result, err := Migrate(source, NewHyprlandProvider(t.TempDir()), true)
+	if err != nil {
+		t.Fatalf("Migrate to hyprland failed: %v", err)
+	}
+	keys := make(map[string]string)
+	for _, bind := range result.Migrated {
+		keys[bind.Key] = bind.Action
+	}
+	if keys["SUPER+mouse_right"] != "movefocus r" {
+		t.Errorf("Expected wheel right to map to mouse_right, got %+v", result.Migrated)
+	}
+	if keys["SUPER+CTRL+SHIFT+mouse_down"] != "movewindow r" {
+		t.Errorf("Expected wheel down to map to mouse_down, got %+v", result.Migrated)
+	}
+
+	result, err = Migrate(source, NewSwayProvider(t.TempDir()), true)
+	if err != nil {
+		t.Fatalf("Migrate to sway failed: %v", err)
+	}
+	if len(result.Migrated) != 1 || len(result.Untranslatable) != 2 {
+		t.Errorf("Expected only the spawn bind to migrate to sway, got %+v / %+v", result.Migrated, result.Untranslatable)
+	}
+	for _, bind := range result.Untranslatable {
+		if !strings.Contains(bind.Reason, "--whole-window") {
+			t.Errorf("Unexpected reason for %s: %s", bind.Key, bind.Reason)
+		}
+	}
+}
+
+func TestCheckMigration(t *testing.T) {
+	if err := CheckMigration("scroll", "niri"); err != nil {
+		t.Errorf("Expected scroll to niri to be supported: %v", err)
+	}
+	err := CheckMigration("sway", "labwc")
+	if err == nil || err.Error() != "labwc not supported for migration" {
+		t.Errorf("Expected labwc to be reported as unsupported, got %v", err)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	swayDir := t.TempDir()
+	swayConfig := `set $mod Mod4
+bindsym $mod+Return exec kitty
+bindsym $mod+Shift+q kill
+bindsym $mod+Left focus left
+bindsym $mod+1 workspace number 1
+bindsym $mod+Shift+1 move container to workspace number 1
+bindsym $mod+w layout tabbed
+`
+	if err := os.WriteFile(filepath.Join(swayDir, "config"), []byte(swayConfig), 0o644); err != nil {
+		t.Fatalf("Failed to write sway config: %v", err)
+	}
+
+	hyprDir := t.TempDir()
+	source := NewSwayProvider(swayDir)
+	target := NewHyprlandProvider(hyprDir)
+
+	result, err := Migrate(source, target, true)
+	if err != nil {
+		t.Fatalf("Migrate dry run failed: %v", err)
+	}
+	if len(result.Migrated) != 5 {
+		t.Errorf("Expected 5 migrated binds, got %d: %+v", len(result.Migrated), result.Migrated)
+	}
+	if len(result.Untranslatable) != 1 || result.Untranslatable[0].Action != "layout tabbed" {
+		t.Errorf("Expected layout tabbed to be untranslatable, got %+v", result.Untranslatable)
+	}
+	if _, err := os.Stat(target.GetOverridePath()); !os.IsNotExist(err) {
+		t.Error("Dry run should not write the override file")
+	}
+
+	if _, err := Migrate(source, target, false); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	data, err := os.ReadFile(target.GetOverridePath())
+	if err != nil {
+		t.Fatalf("Failed to read override file: %v", err)
+	}
+	content := string(data)
+	for _, want := range []string{"exec, kitty", "killactive", "movefocus, l", "movetoworkspace, 1"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Override file missing %q:\n%s", want, content)
+		}
+	}
+}
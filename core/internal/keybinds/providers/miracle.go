@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	"gopkg.in/yaml.v3"
 )
 
 type MiracleProvider struct {
@@ -34,7 +37,13 @@ func (m *MiracleProvider) GetCheatSheet() (*keybinds.CheatSheet, error) {
 		return nil, fmt.Errorf("failed to parse miracle-wm config: %w", err)
 	}
 
-	bindings := MiracleConfigToBindings(config)
+	overrides, err := m.loadOverrideBinds()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dms binds: %w", err)
+	}
+
+	status := m.buildDMSStatus(config, overrides)
+	bindings, conflicts := m.mergeBindings(config, overrides)
 	categorizedBinds := make(map[string][]keybinds.Keybind)
 
 	for _, kb := range bindings {
@@ -43,25 +52,158 @@ func (m *MiracleProvider) GetCheatSheet() (*keybinds.CheatSheet, error) {
 			Key:         m.formatKey(kb),
 			Description: kb.Comment,
 			Action:      kb.Action,
+			Source:      kb.Source,
+			File:        kb.File,
+		}
+		if conflict, ok := conflicts[strings.ToLower(bind.Key)]; ok && kb.Source == "dms" {
+			bind.Conflict = &keybinds.Keybind{
+				Key:         m.formatKey(conflict),
+				Description: conflict.Comment,
+				Action:      conflict.Action,
+				Source:      "config",
+				File:        conflict.File,
+			}
 		}
 		categorizedBinds[category] = append(categorizedBinds[category], bind)
 	}
 
 	return &keybinds.CheatSheet{
-		Title:    "Miracle WM Keybinds",
-		Provider: m.Name(),
-		Binds:    categorizedBinds,
+		Title:            "Miracle WM Keybinds",
+		Provider:         m.Name(),
+		Binds:            categorizedBinds,
+		DMSBindsIncluded: status.Included,
+		DMSStatus:        status,
 	}, nil
 }
 
-func (m *MiracleProvider) GetOverridePath() string {
+// mergeBindings layers the DMS override file over the config bindings. DMS
+// binds replace defaults with the same action or key; explicit config binds
+// on a DMS key are reported as conflicts.
+func (m *MiracleProvider) mergeBindings(config *MiracleConfig, overrides []*miracleOverrideBind) ([]MiracleKeyBinding, map[string]MiracleKeyBinding) {
+	configBinds := MiracleConfigToBindings(config)
+	configFile := m.configFile()
+	for i := range configBinds {
+		if configBinds[i].Source != "default" {
+			configBinds[i].File = configFile
+		}
+	}
+	if len(overrides) == 0 {
+		for i := range configBinds {
+			configBinds[i].Source = "config"
+		}
+		return configBinds, nil
+	}
+
+	dmsKeys := make(map[string]bool)
+	dmsActions := make(map[string]bool)
+	var dmsBinds []MiracleKeyBinding
+	for _, o := range overrides {
+		kb := o.binding(config.ActionKey)
+		kb.File = m.GetOverridePath()
+		dmsKeys[strings.ToLower(m.formatKey(kb))] = true
+		if o.Builtin {
+			dmsActions[o.Action] = true
+		}
+		dmsBinds = append(dmsBinds, kb)
+	}
+
+	conflicts := make(map[string]MiracleKeyBinding)
+	var merged []MiracleKeyBinding
+	for _, kb := range configBinds {
+		key := strings.ToLower(m.formatKey(kb))
+		isDefault := kb.Source == "default"
+		switch {
+		case dmsKeys[key] && !isDefault:
+			conflicts[key] = kb
+			continue
+		case dmsKeys[key], dmsActions[kb.Action] && isDefault:
+			continue
+		}
+		kb.Source = "config"
+		merged = append(merged, kb)
+	}
+
+	return append(merged, dmsBinds...), conflicts
+}
+
+func (m *MiracleProvider) configFile() string {
 	expanded, err := utils.ExpandPath(m.configPath)
 	if err != nil {
-		return filepath.Join(m.configPath, "config.yaml")
+		expanded = m.configPath
+	}
+	if info, err := os.Stat(expanded); err == nil && !info.IsDir() {
+		return expanded
 	}
 	return filepath.Join(expanded, "config.yaml")
 }
 
+func (m *MiracleProvider) buildDMSStatus(config *MiracleConfig, overrides []*miracleOverrideBind) *keybinds.DMSBindsStatus {
+	overridePath := m.GetOverridePath()
+	status := &keybinds.DMSBindsStatus{
+		IncludePosition: -1,
+		TotalIncludes:   len(config.Includes),
+	}
+
+	if _, err := os.Stat(overridePath); err == nil {
+		status.Exists = true
+	}
+
+	baseDir := filepath.Dir(filepath.Dir(overridePath))
+	for i, include := range config.Includes {
+		expanded, err := utils.ExpandPath(include)
+		if err != nil {
+			continue
+		}
+		if !filepath.IsAbs(expanded) {
+			expanded = filepath.Join(baseDir, expanded)
+		}
+		if filepath.Clean(expanded) == overridePath {
+			status.Included = true
+			status.IncludePosition = i + 1
+		}
+	}
+
+	dmsKeys := make(map[string]bool)
+	for _, o := range overrides {
+		dmsKeys[strings.ToLower(m.formatKey(o.binding(config.ActionKey)))] = true
+	}
+	for _, kb := range MiracleConfigToBindings(config) {
+		if kb.Source != "default" && dmsKeys[strings.ToLower(m.formatKey(kb))] {
+			status.BindsAfterDMS++
+		}
+	}
+
+	switch {
+	case !status.Exists:
+		status.StatusMessage = "dms/binds.yaml does not exist"
+	case !status.Included:
+		status.StatusMessage = "dms/binds.yaml is not listed in config includes"
+	case status.BindsAfterDMS > 0:
+		status.Effective = true
+		status.OverriddenBy = status.BindsAfterDMS
+		status.StatusMessage = "Some DMS binds may be overridden by config binds"
+	default:
+		status.Effective = true
+		status.StatusMessage = "DMS binds are active"
+	}
+
+	return status
+}
+
+func (m *MiracleProvider) GetOverridePath() string {
+	expanded, err := utils.ExpandPath(m.configPath)
+	if err != nil {
+		return filepath.Join(m.configPath, "dms", "binds.yaml")
+	}
+	if info, err := os.Stat(expanded); err == nil && !info.IsDir() {
+		expanded = filepath.Dir(expanded)
+	}
+	if abs, err := filepath.Abs(expanded); err == nil {
+		expanded = abs
+	}
+	return filepath.Join(expanded, "dms", "binds.yaml")
+}
+
 func (m *MiracleProvider) formatKey(kb MiracleKeyBinding) string {
 	parts := make([]string, 0, len(kb.Mods)+1)
 	parts = append(parts, kb.Mods...)
@@ -93,3 +235,274 @@ func (m *MiracleProvider) categorizeAction(action string) string {
 		return "Execute"
 	}
 }
+
+type miracleOverrideBind struct {
+	Mods        []string
+	KeyCode     string
+	Action      string
+	Description string
+	Builtin     bool
+	Trigger     string
+}
+
+func (o *miracleOverrideBind) binding(actionKey string) MiracleKeyBinding {
+	mods := make([]string, 0, len(o.Mods))
+	for _, mod := range o.Mods {
+		mods = append(mods, resolveMiracleModifier(mod, actionKey))
+	}
+	desc := o.Description
+	if desc == "" {
+		desc = miracleActionDescription(o.Action)
+	}
+	return MiracleKeyBinding{
+		Mods:    mods,
+		Key:     miracleKeyCodeToName(o.KeyCode),
+		Action:  o.Action,
+		Comment: desc,
+		Source:  "dms",
+	}
+}
+
+// isMiracleBuiltinAction reports whether action names a miracle-wm default
+// action rather than a shell command.
+func isMiracleBuiltinAction(action string) bool {
+	return miracleActionDescription(action) != action
+}
+
+var miracleModifierNames = map[string]string{
+	"super": "meta", "mod": "meta", "mod4": "meta", "meta": "meta", "logo": "meta", "win": "meta",
+	"shift": "shift",
+	"ctrl":  "ctrl", "control": "ctrl",
+	"alt": "alt", "mod1": "alt",
+	"primary": "primary",
+}
+
+var miracleKeyCodes = map[string]string{
+	"return": "KEY_ENTER", "enter": "KEY_ENTER", "space": "KEY_SPACE", "tab": "KEY_TAB",
+	"up": "KEY_UP", "down": "KEY_DOWN", "left": "KEY_LEFT", "right": "KEY_RIGHT",
+	"escape": "KEY_ESC", "esc": "KEY_ESC", "delete": "KEY_DELETE", "backspace": "KEY_BACKSPACE",
+	"home": "KEY_HOME", "end": "KEY_END", "page_up": "KEY_PAGEUP", "page_down": "KEY_PAGEDOWN",
+	"print": "KEY_PRINT", "pause": "KEY_PAUSE",
+	"xf86audioraisevolume": "KEY_VOLUMEUP", "xf86audiolowervolume": "KEY_VOLUMEDOWN",
+	"xf86audiomute": "KEY_MUTE", "xf86audiomicmute": "KEY_MICMUTE",
+	"xf86monbrightnessup": "KEY_BRIGHTNESSUP", "xf86monbrightnessdown": "KEY_BRIGHTNESSDOWN",
+	"xf86kbdbrightnessup": "KEY_KBDILLUMUP", "xf86kbdbrightnessdown": "KEY_KBDILLUMDOWN",
+}
+
+func miracleKeyNameToCode(name string) string {
+	if code, ok := miracleKeyCodes[strings.ToLower(name)]; ok {
+		return code
+	}
+	return "KEY_" + strings.ToUpper(name)
+}
+
+// parseMiracleKey splits a key string such as "Super+Shift+t" into
+// miracle-wm modifier names and a KEY_ code.
+func parseMiracleKey(key string) ([]string, string, error) {
+	parts := strings.Split(key, "+")
+	name := strings.TrimSpace(parts[len(parts)-1])
+	if name == "" {
+		return nil, "", fmt.Errorf("invalid key: %s", key)
+	}
+
+	mods := make([]string, 0, len(parts)-1)
+	for _, part := range parts[:len(parts)-1] {
+		mod, ok := miracleModifierNames[strings.ToLower(strings.TrimSpace(part))]
+		if !ok {
+			return nil, "", fmt.Errorf("unknown modifier %q in key %s", part, key)
+		}
+		mods = append(mods, mod)
+	}
+	return mods, miracleKeyNameToCode(name), nil
+}
+
+func miracleCanonicalKey(mods []string, keyCode string) string {
+	sorted := append([]string(nil), mods...)
+	sort.Strings(sorted)
+	return strings.ToLower(strings.Join(append(sorted, keyCode), "+"))
+}
+
+func (m *MiracleProvider) SetBind(key, action, description string, options map[string]any) error {
+	action = strings.TrimSpace(action)
+	if action == "" {
+		return fmt.Errorf("action cannot be empty")
+	}
+
+	mods, keyCode, err := parseMiracleKey(key)
+	if err != nil {
+		return err
+	}
+
+	overridePath := m.GetOverridePath()
+	if err := os.MkdirAll(filepath.Dir(overridePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create dms directory: %w", err)
+	}
+
+	binds, err := m.loadOverrideBinds()
+	if err != nil {
+		binds = nil
+	}
+
+	trigger := "down"
+	if v, ok := options["flags"].(string); ok && strings.Contains(v, "r") {
+		trigger = "up"
+	}
+
+	newBind := &miracleOverrideBind{
+		Mods:        mods,
+		KeyCode:     keyCode,
+		Action:      action,
+		Description: description,
+		Builtin:     isMiracleBuiltinAction(action),
+		Trigger:     trigger,
+	}
+
+	canonical := miracleCanonicalKey(mods, keyCode)
+	kept := binds[:0]
+	for _, b := range binds {
+		if miracleCanonicalKey(b.Mods, b.KeyCode) == canonical {
+			continue
+		}
+		if newBind.Builtin && b.Builtin && b.Action == action {
+			continue
+		}
+		kept = append(kept, b)
+	}
+
+	return m.writeOverrideBinds(append(kept, newBind))
+}
+
+func (m *MiracleProvider) RemoveBind(key string) error {
+	binds, err := m.loadOverrideBinds()
+	if err != nil {
+		return nil
+	}
+
+	mods, keyCode, err := parseMiracleKey(key)
+	if err != nil {
+		return err
+	}
+
+	canonical := miracleCanonicalKey(mods, keyCode)
+	kept := binds[:0]
+	for _, b := range binds {
+		if miracleCanonicalKey(b.Mods, b.KeyCode) != canonical {
+			kept = append(kept, b)
+		}
+	}
+	return m.writeOverrideBinds(kept)
+}
+
+func miracleComment(node *yaml.Node) string {
+	comment := strings.TrimSpace(node.HeadComment)
+	return strings.TrimSpace(strings.TrimPrefix(comment, "#"))
+}
+
+func (m *MiracleProvider) loadOverrideBinds() ([]*miracleOverrideBind, error) {
+	data, err := os.ReadFile(m.GetOverridePath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var binds []*miracleOverrideBind
+	doc := root.Content[0]
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		section, items := doc.Content[i].Value, doc.Content[i+1]
+		if items.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, item := range items.Content {
+			switch section {
+			case "default_action_overrides":
+				var o MiracleActionOverride
+				if err := item.Decode(&o); err != nil {
+					return nil, err
+				}
+				binds = append(binds, &miracleOverrideBind{
+					Mods: o.Modifiers, KeyCode: o.Key, Action: o.Name,
+					Description: miracleComment(item), Builtin: true, Trigger: o.Action,
+				})
+			case "custom_actions":
+				var c MiracleCustomAction
+				if err := item.Decode(&c); err != nil {
+					return nil, err
+				}
+				binds = append(binds, &miracleOverrideBind{
+					Mods: c.Modifiers, KeyCode: c.Key, Action: c.Command,
+					Description: miracleComment(item), Trigger: c.Action,
+				})
+			}
+		}
+	}
+
+	return binds, nil
+}
+
+func (m *MiracleProvider) writeOverrideBinds(binds []*miracleOverrideBind) error {
+	content, err := m.generateBindsContent(binds)
+	if err != nil {
+		return err
+	}
+	return snapshot.WriteFile(m.GetOverridePath(), content, 0o644)
+}
+
+func (m *MiracleProvider) generateBindsContent(binds []*miracleOverrideBind) ([]byte, error) {
+	if len(binds) == 0 {
+		return []byte{}, nil
+	}
+
+	sorted := append([]*miracleOverrideBind(nil), binds...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return miracleCanonicalKey(sorted[i].Mods, sorted[i].KeyCode) < miracleCanonicalKey(sorted[j].Mods, sorted[j].KeyCode)
+	})
+
+	overrides := &yaml.Node{Kind: yaml.SequenceNode}
+	customs := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, b := range sorted {
+		trigger := b.Trigger
+		if trigger == "" {
+			trigger = "down"
+		}
+
+		var item yaml.Node
+		var err error
+		if b.Builtin {
+			err = item.Encode(MiracleActionOverride{Name: b.Action, Action: trigger, Modifiers: b.Mods, Key: b.KeyCode})
+		} else {
+			err = item.Encode(MiracleCustomAction{Command: b.Action, Action: trigger, Modifiers: b.Mods, Key: b.KeyCode})
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b.Description != "" {
+			item.HeadComment = b.Description
+		}
+
+		if b.Builtin {
+			overrides.Content = append(overrides.Content, &item)
+		} else {
+			customs.Content = append(customs.Content, &item)
+		}
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	if len(overrides.Content) > 0 {
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "default_action_overrides"}, overrides)
+	}
+	if len(customs.Content) > 0 {
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "custom_actions"}, customs)
+	}
+
+	return yaml.Marshal(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{doc}})
+}
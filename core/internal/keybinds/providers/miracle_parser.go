@@ -14,6 +14,7 @@ type MiracleConfig struct {
 	ActionKey              string                  `yaml:"action_key"`
 	DefaultActionOverrides []MiracleActionOverride `yaml:"default_action_overrides"`
 	CustomActions          []MiracleCustomAction   `yaml:"custom_actions"`
+	Includes               []string                `yaml:"includes"`
 }
 
 type MiracleActionOverride struct {
@@ -35,6 +36,8 @@ type MiracleKeyBinding struct {
 	Key     string
 	Action  string
 	Comment string
+	Source  string
+	File    string
 }
 
 var miracleDefaultBinds = []MiracleKeyBinding{
@@ -235,6 +238,7 @@ func MiracleConfigToBindings(config *MiracleConfig) []MiracleKeyBinding {
 		if overridden[def.Action] {
 			continue
 		}
+		def.Source = "default"
 		bindings = append(bindings, def)
 	}
 
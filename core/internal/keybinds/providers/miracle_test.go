@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
+)
+
+func writeMiracleConfig(t *testing.T, content string) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	return tmpDir
+}
+
+func findMiracleBind(sheet *keybinds.CheatSheet, key string) *keybinds.Keybind {
+	for _, binds := range sheet.Binds {
+		for i := range binds {
+			if binds[i].Key == key {
+				return &binds[i]
+			}
+		}
+	}
+	return nil
+}
+
+func TestParseMiracleKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		wantMods []string
+		wantCode string
+		wantErr  bool
+	}{
+		{"Super+Shift+t", []string{"meta", "shift"}, "KEY_T", false},
+		{"Mod4+Return", []string{"meta"}, "KEY_ENTER", false},
+		{"Ctrl+Alt+Delete", []string{"ctrl", "alt"}, "KEY_DELETE", false},
+		{"XF86AudioRaiseVolume", []string{}, "KEY_VOLUMEUP", false},
+		{"Hyper+t", nil, "", true},
+		{"Super+", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			mods, code, err := parseMiracleKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMiracleKey(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if strings.Join(mods, ",") != strings.Join(tt.wantMods, ",") || code != tt.wantCode {
+				t.Errorf("parseMiracleKey(%q) = %v %q, want %v %q", tt.key, mods, code, tt.wantMods, tt.wantCode)
+			}
+			if name := miracleKeyCodeToName(code); !strings.EqualFold(name, tt.key[strings.LastIndex(tt.key, "+")+1:]) {
+				t.Errorf("round trip of %q gave %q", code, name)
+			}
+		})
+	}
+}
+
+func TestMiracleSetAndRemoveBind(t *testing.T) {
+	dir := writeMiracleConfig(t, "action_key: meta\nincludes:\n  - dms/binds.yaml\n")
+	provider := NewMiracleProvider(dir)
+
+	if err := provider.SetBind("Super+Shift+t", "kitty", "Terminal", nil); err != nil {
+		t.Fatalf("SetBind failed: %v", err)
+	}
+	if err := provider.SetBind("Super+g", "fullscreen", "", nil); err != nil {
+		t.Fatalf("SetBind failed: %v", err)
+	}
+
+	data, err := os.ReadFile(provider.GetOverridePath())
+	if err != nil {
+		t.Fatalf("Failed to read override file: %v", err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "default_action_overrides:") || !strings.Contains(content, "name: fullscreen") {
+		t.Errorf("Expected fullscreen override in:\n%s", content)
+	}
+	if !strings.Contains(content, "# Terminal") || !strings.Contains(content, "command: kitty") {
+		t.Errorf("Expected commented custom action in:\n%s", content)
+	}
+
+	sheet, err := provider.GetCheatSheet()
+	if err != nil {
+		t.Fatalf("GetCheatSheet failed: %v", err)
+	}
+	if !sheet.DMSBindsIncluded || !sheet.DMSStatus.Effective {
+		t.Errorf("status = %+v, want included and effective", sheet.DMSStatus)
+	}
+
+	terminal := findMiracleBind(sheet, "Super+Shift+t")
+	if terminal == nil || terminal.Source != "dms" || terminal.Description != "Terminal" {
+		t.Errorf("terminal bind = %+v", terminal)
+	}
+	if findMiracleBind(sheet, "Super+f") != nil {
+		t.Error("Default fullscreen bind should be replaced by the DMS override")
+	}
+	if bind := findMiracleBind(sheet, "Super+g"); bind == nil || bind.Action != "fullscreen" {
+		t.Errorf("fullscreen bind = %+v", bind)
+	}
+
+	if err := provider.RemoveBind("super+shift+T"); err != nil {
+		t.Fatalf("RemoveBind failed: %v", err)
+	}
+	binds, err := provider.loadOverrideBinds()
+	if err != nil {
+		t.Fatalf("loadOverrideBinds failed: %v", err)
+	}
+	if len(binds) != 1 || binds[0].Action != "fullscreen" {
+		t.Errorf("Expected only fullscreen override to remain, got %+v", binds)
+	}
+}
+
+func TestMiracleDMSStatus(t *testing.T) {
+	dir := writeMiracleConfig(t, `custom_actions:
+  - command: foot
+    action: down
+    modifiers: [meta]
+    key: KEY_T
+`)
+	provider := NewMiracleProvider(dir)
+
+	sheet, err := provider.GetCheatSheet()
+	if err != nil {
+		t.Fatalf("GetCheatSheet failed: %v", err)
+	}
+	if sheet.DMSStatus.Exists {
+		t.Error("Override file should not exist yet")
+	}
+
+	if err := provider.SetBind("Super+t", "kitty", "", nil); err != nil {
+		t.Fatalf("SetBind failed: %v", err)
+	}
+	sheet, err = provider.GetCheatSheet()
+	if err != nil {
+		t.Fatalf("GetCheatSheet failed: %v", err)
+	}
+	if !sheet.DMSStatus.Exists || sheet.DMSStatus.Included {
+		t.Errorf("status = %+v, want existing but not included", sheet.DMSStatus)
+	}
+	if sheet.DMSStatus.BindsAfterDMS != 1 {
+		t.Errorf("BindsAfterDMS = %d, want 1", sheet.DMSStatus.BindsAfterDMS)
+	}
+
+	bind := findMiracleBind(sheet, "Super+t")
+	if bind == nil || bind.Source != "dms" || bind.Conflict == nil || bind.Conflict.Action != "foot" {
+		t.Errorf("Expected DMS bind conflicting with config, got %+v", bind)
+	}
+}
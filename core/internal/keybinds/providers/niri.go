@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
 	"github.com/sblinch/kdl-go"
 	"github.com/sblinch/kdl-go/document"
 )
@@ -159,6 +160,8 @@ func (n *NiriProvider) convertKeybind(kb *NiriKeyBinding, subcategory string, co
 		Action:          rawAction,
 		Subcategory:     subcategory,
 		Source:          source,
+		File:            kb.Source,
+		Line:            kb.Line,
 		HideOnOverlay:   kb.HideOnOverlay,
 		CooldownMs:      kb.CooldownMs,
 		AllowWhenLocked: kb.AllowWhenLocked,
@@ -173,6 +176,8 @@ func (n *NiriProvider) convertKeybind(kb *NiriKeyBinding, subcategory string, co
 				Description: conflictKb.Description,
 				Action:      n.formatRawAction(conflictKb.Action, conflictKb.Args),
 				Source:      "config",
+				File:        conflictKb.Source,
+				Line:        conflictKb.Line,
 			}
 		}
 	}
@@ -495,7 +500,7 @@ func (n *NiriProvider) writeOverrideBinds(binds map[string]*overrideBind) error
 		return err
 	}
 
-	return os.WriteFile(overridePath, []byte(content), 0o644)
+	return snapshot.WriteFile(overridePath, []byte(content), 0o644)
 }
 
 func (n *NiriProvider) getBindSortPriority(action string) int {
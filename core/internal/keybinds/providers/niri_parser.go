@@ -23,6 +23,7 @@ type NiriKeyBinding struct {
 	AllowInhibiting *bool
 	Repeat          *bool
 	Source          string
+	Line            int
 }
 
 type NiriSection struct {
@@ -37,6 +38,8 @@ type NiriParser struct {
 	bindMap            map[string]*NiriKeyBinding
 	bindOrder          []string
 	currentSource      string
+	currentLines       []string
+	lineCursor         int
 	dmsBindsIncluded   bool
 	dmsBindsExists     bool
 	includeCount       int
@@ -96,11 +99,10 @@ func (p *NiriParser) parseDMSBindsDirectly(dmsBindsPath string, section *NiriSec
 		return
 	}
 
-	prevSource := p.currentSource
-	p.currentSource = dmsBindsPath
+	restore := p.enterSource(dmsBindsPath, data)
 	baseDir := filepath.Dir(dmsBindsPath)
 	p.processNodes(doc.Nodes, section, baseDir)
-	p.currentSource = prevSource
+	restore()
 	p.dmsProcessed = true
 }
 
@@ -168,15 +170,47 @@ func (p *NiriParser) parseFile(filePath, sectionName string) (*NiriSection, erro
 		Name: sectionName,
 	}
 
-	prevSource := p.currentSource
-	p.currentSource = absPath
+	restore := p.enterSource(absPath, data)
 	baseDir := filepath.Dir(absPath)
 	p.processNodes(doc.Nodes, section, baseDir)
-	p.currentSource = prevSource
+	restore()
 
 	return section, nil
 }
 
+// enterSource makes path the file new binds are attributed to and returns a
+// func restoring the previous one, so includes nest correctly.
+func (p *NiriParser) enterSource(path string, data []byte) func() {
+	prevSource, prevLines, prevCursor := p.currentSource, p.currentLines, p.lineCursor
+	p.currentSource = path
+	p.currentLines = strings.Split(string(data), "\n")
+	p.lineCursor = 0
+	return func() {
+		p.currentSource, p.currentLines, p.lineCursor = prevSource, prevLines, prevCursor
+	}
+}
+
+// locateLine finds the 1-based line of the next node named keyCombo. The KDL
+// parser drops positions, but binds are visited in file order so a forward
+// scan from the previous match is enough.
+func (p *NiriParser) locateLine(keyCombo string) int {
+	for i := p.lineCursor; i < len(p.currentLines); i++ {
+		line := strings.TrimSpace(p.currentLines[i])
+		rest, ok := strings.CutPrefix(line, keyCombo)
+		if !ok {
+			rest, ok = strings.CutPrefix(line, `"`+keyCombo+`"`)
+		}
+		if !ok {
+			continue
+		}
+		if rest == "" || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '{' {
+			p.lineCursor = i + 1
+			return i + 1
+		}
+	}
+	return 0
+}
+
 func (p *NiriParser) processNodes(nodes []*document.Node, section *NiriSection, baseDir string) {
 	for _, node := range nodes {
 		name := node.Name.String()
@@ -323,6 +357,7 @@ func (p *NiriParser) parseKeybindNode(node *document.Node, _ string) *NiriKeyBin
 		AllowInhibiting: allowInhibiting,
 		Repeat:          repeat,
 		Source:          p.currentSource,
+		Line:            p.locateLine(keyCombo),
 	}
 }
 
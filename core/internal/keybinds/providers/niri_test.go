@@ -659,3 +659,62 @@ func TestNiriGenerateWorkspaceBindsRoundTrip(t *testing.T) {
 		t.Error("set-column-width -10% not found after round-trip")
 	}
 }
+
+func TestNiriBindLocations(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, "dms"), 0o755); err != nil {
+		t.Fatalf("Failed to create dms dir: %v", err)
+	}
+
+	config := `include "dms/binds.kdl"
+
+input {
+    focus-follows-mouse
+}
+
+binds {
+    Mod+T { spawn "kitty"; }
+    Mod+Q { close-window; }
+}
+`
+	dmsBinds := `binds {
+    Mod+Q { quit; }
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.kdl"), []byte(config), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	dmsPath := filepath.Join(tmpDir, "dms", "binds.kdl")
+	if err := os.WriteFile(dmsPath, []byte(dmsBinds), 0o644); err != nil {
+		t.Fatalf("Failed to write dms binds: %v", err)
+	}
+
+	sheet, err := NewNiriProvider(tmpDir).GetCheatSheet()
+	if err != nil {
+		t.Fatalf("GetCheatSheet failed: %v", err)
+	}
+
+	found := 0
+	for _, binds := range sheet.Binds {
+		for _, bind := range binds {
+			switch bind.Key {
+			case "Mod+T":
+				found++
+				if bind.Line != 8 || filepath.Base(bind.File) != "config.kdl" {
+					t.Errorf("Mod+T at %s:%d, want config.kdl:8", bind.File, bind.Line)
+				}
+			case "Mod+Q":
+				found++
+				if bind.Line != 2 || bind.File != dmsPath {
+					t.Errorf("Mod+Q at %s:%d, want %s:2", bind.File, bind.Line, dmsPath)
+				}
+				if bind.Conflict == nil || bind.Conflict.Line != 9 {
+					t.Errorf("Expected config conflict at line 9, got %+v", bind.Conflict)
+				}
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("Expected to find 2 binds, found %d", found)
+	}
+}
@@ -4,14 +4,19 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
 )
 
 type SwayProvider struct {
-	configPath string
-	isScroll   bool
+	configPath       string
+	isScroll         bool
+	dmsBindsIncluded bool
+	parsed           bool
 }
 
 func NewSwayProvider(configPath string) *SwayProvider {
@@ -58,13 +63,16 @@ func (s *SwayProvider) Name() string {
 }
 
 func (s *SwayProvider) GetCheatSheet() (*keybinds.CheatSheet, error) {
-	section, err := ParseSwayKeys(s.configPath)
+	result, err := ParseSwayKeysWithDMS(s.configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse sway config: %w", err)
 	}
 
+	s.dmsBindsIncluded = result.DMSBindsIncluded
+	s.parsed = true
+
 	categorizedBinds := make(map[string][]keybinds.Keybind)
-	s.convertSection(section, "", categorizedBinds)
+	s.convertSection(result.Section, "", categorizedBinds, result.ConflictingConfigs)
 
 	cheatSheetTitle := "Sway Keybinds"
 	if s != nil && s.isScroll {
@@ -72,13 +80,30 @@ func (s *SwayProvider) GetCheatSheet() (*keybinds.CheatSheet, error) {
 	}
 
 	return &keybinds.CheatSheet{
-		Title:    cheatSheetTitle,
-		Provider: s.Name(),
-		Binds:    categorizedBinds,
+		Title:            cheatSheetTitle,
+		Provider:         s.Name(),
+		Binds:            categorizedBinds,
+		DMSBindsIncluded: result.DMSBindsIncluded,
+		DMSStatus:        result.DMSStatus,
 	}, nil
 }
 
-func (s *SwayProvider) convertSection(section *SwaySection, subcategory string, categorizedBinds map[string][]keybinds.Keybind) {
+func (s *SwayProvider) HasDMSBindsIncluded() bool {
+	if s.parsed {
+		return s.dmsBindsIncluded
+	}
+
+	result, err := ParseSwayKeysWithDMS(s.configPath)
+	if err != nil {
+		return false
+	}
+
+	s.dmsBindsIncluded = result.DMSBindsIncluded
+	s.parsed = true
+	return s.dmsBindsIncluded
+}
+
+func (s *SwayProvider) convertSection(section *SwaySection, subcategory string, categorizedBinds map[string][]keybinds.Keybind, conflicts map[string]*SwayKeyBinding) {
 	currentSubcat := subcategory
 	if section.Name != "" {
 		currentSubcat = section.Name
@@ -87,11 +112,23 @@ func (s *SwayProvider) convertSection(section *SwaySection, subcategory string,
 	for _, kb := range section.Keybinds {
 		category := s.categorizeByCommand(kb.Command)
 		bind := s.convertKeybind(&kb, currentSubcat)
+		if bind.Source == "dms" {
+			if conflict, ok := conflicts[swayBindKey(&kb)]; ok {
+				bind.Conflict = &keybinds.Keybind{
+					Key:         s.formatKey(conflict),
+					Description: conflict.Comment,
+					Action:      conflict.Command,
+					Source:      "config",
+					File:        conflict.Source,
+					Line:        conflict.Line,
+				}
+			}
+		}
 		categorizedBinds[category] = append(categorizedBinds[category], bind)
 	}
 
 	for _, child := range section.Children {
-		s.convertSection(&child, currentSubcat, categorizedBinds)
+		s.convertSection(&child, currentSubcat, categorizedBinds, conflicts)
 	}
 }
 
@@ -134,12 +171,21 @@ func (s *SwayProvider) convertKeybind(kb *SwayKeyBinding, subcategory string) ke
 		desc = kb.Command
 	}
 
-	return keybinds.Keybind{
+	bind := keybinds.Keybind{
 		Key:         key,
 		Description: desc,
 		Action:      kb.Command,
 		Subcategory: subcategory,
 	}
+	if kb.Source != "" {
+		bind.File = kb.Source
+		bind.Line = kb.Line
+		bind.Source = "config"
+		if kb.Source == s.GetOverridePath() {
+			bind.Source = "dms"
+		}
+	}
+	return bind
 }
 
 func (s *SwayProvider) formatKey(kb *SwayKeyBinding) string {
@@ -148,3 +194,199 @@ func (s *SwayProvider) formatKey(kb *SwayKeyBinding) string {
 	parts = append(parts, kb.Key)
 	return strings.Join(parts, "+")
 }
+
+func (s *SwayProvider) GetOverridePath() string {
+	expanded, err := utils.ExpandPath(s.configPath)
+	if err != nil {
+		return filepath.Join(s.configPath, "dms", "binds.conf")
+	}
+	if info, err := os.Stat(expanded); err == nil && !info.IsDir() {
+		expanded = filepath.Dir(expanded)
+	}
+	if abs, err := filepath.Abs(expanded); err == nil {
+		expanded = abs
+	}
+	return filepath.Join(expanded, "dms", "binds.conf")
+}
+
+type swayOverrideBind struct {
+	Key         string
+	Action      string
+	Description string
+	Flags       []string
+}
+
+func (s *SwayProvider) validateAction(action string) error {
+	action = strings.TrimSpace(action)
+	switch {
+	case action == "":
+		return fmt.Errorf("action cannot be empty")
+	case action == "exec" || action == "exec --no-startup-id":
+		return fmt.Errorf("exec command requires arguments")
+	case strings.Contains(action, "\n"):
+		return fmt.Errorf("action cannot span multiple lines")
+	}
+	return nil
+}
+
+func swayFlagsFromOptions(options map[string]any) []string {
+	var flags []string
+	if v, ok := options["allow-when-locked"].(bool); ok && v {
+		flags = append(flags, "--locked")
+	}
+	if v, ok := options["allow-inhibiting"].(bool); ok && !v {
+		flags = append(flags, "--inhibited")
+	}
+	if v, ok := options["repeat"].(bool); ok && !v {
+		flags = append(flags, "--no-repeat")
+	}
+	if v, ok := options["flags"].(string); ok && strings.Contains(v, "r") {
+		flags = append(flags, "--release")
+	}
+	return flags
+}
+
+func (s *SwayProvider) SetBind(key, action, description string, options map[string]any) error {
+	if err := s.validateAction(action); err != nil {
+		return err
+	}
+
+	overridePath := s.GetOverridePath()
+	if err := os.MkdirAll(filepath.Dir(overridePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create dms directory: %w", err)
+	}
+
+	existingBinds, err := s.loadOverrideBinds()
+	if err != nil {
+		existingBinds = make(map[string]*swayOverrideBind)
+	}
+
+	existingBinds[strings.ToLower(key)] = &swayOverrideBind{
+		Key:         key,
+		Action:      strings.TrimSpace(action),
+		Description: description,
+		Flags:       swayFlagsFromOptions(options),
+	}
+
+	return s.writeOverrideBinds(existingBinds)
+}
+
+func (s *SwayProvider) RemoveBind(key string) error {
+	existingBinds, err := s.loadOverrideBinds()
+	if err != nil {
+		return nil
+	}
+
+	delete(existingBinds, strings.ToLower(key))
+	return s.writeOverrideBinds(existingBinds)
+}
+
+func (s *SwayProvider) loadOverrideBinds() (map[string]*swayOverrideBind, error) {
+	binds := make(map[string]*swayOverrideBind)
+
+	data, err := os.ReadFile(s.GetOverridePath())
+	if os.IsNotExist(err) {
+		return binds, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pendingDesc string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if desc, ok := strings.CutPrefix(line, "# "); ok {
+			pendingDesc = strings.TrimSpace(desc)
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(line, "bindsym ")
+		if !ok {
+			pendingDesc = ""
+			continue
+		}
+
+		fields := strings.Fields(rest)
+		var flags []string
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+			flags = append(flags, fields[0])
+			fields = fields[1:]
+		}
+		if len(fields) < 2 {
+			pendingDesc = ""
+			continue
+		}
+
+		binds[strings.ToLower(fields[0])] = &swayOverrideBind{
+			Key:         fields[0],
+			Action:      strings.Join(fields[1:], " "),
+			Description: pendingDesc,
+			Flags:       flags,
+		}
+		pendingDesc = ""
+	}
+
+	return binds, nil
+}
+
+func (s *SwayProvider) getBindSortPriority(action string) int {
+	switch {
+	case strings.HasPrefix(action, "exec") && strings.Contains(action, "dms"):
+		return 0
+	case strings.Contains(action, "workspace"):
+		return 1
+	case strings.HasPrefix(action, "focus") || strings.HasPrefix(action, "move") || strings.HasPrefix(action, "resize"):
+		return 2
+	case strings.Contains(action, "output"):
+		return 3
+	case strings.HasPrefix(action, "exec"):
+		return 4
+	case action == "exit" || action == "reload":
+		return 5
+	default:
+		return 6
+	}
+}
+
+func (s *SwayProvider) writeOverrideBinds(binds map[string]*swayOverrideBind) error {
+	return snapshot.WriteFile(s.GetOverridePath(), []byte(s.generateBindsContent(binds)), 0o644)
+}
+
+func (s *SwayProvider) generateBindsContent(binds map[string]*swayOverrideBind) string {
+	if len(binds) == 0 {
+		return ""
+	}
+
+	bindList := make([]*swayOverrideBind, 0, len(binds))
+	for _, bind := range binds {
+		bindList = append(bindList, bind)
+	}
+
+	sort.Slice(bindList, func(i, j int) bool {
+		pi, pj := s.getBindSortPriority(bindList[i].Action), s.getBindSortPriority(bindList[j].Action)
+		if pi != pj {
+			return pi < pj
+		}
+		return bindList[i].Key < bindList[j].Key
+	})
+
+	var sb strings.Builder
+	for _, bind := range bindList {
+		if bind.Description != "" {
+			sb.WriteString("# ")
+			sb.WriteString(bind.Description)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("bindsym ")
+		for _, flag := range bind.Flags {
+			sb.WriteString(flag)
+			sb.WriteString(" ")
+		}
+		sb.WriteString(bind.Key)
+		sb.WriteString(" ")
+		sb.WriteString(bind.Action)
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
 )
 
@@ -21,6 +22,8 @@ type SwayKeyBinding struct {
 	Key     string   `json:"key"`
 	Command string   `json:"command"`
 	Comment string   `json:"comment"`
+	Source  string   `json:"source,omitempty"`
+	Line    int      `json:"line,omitempty"`
 }
 
 type SwaySection struct {
@@ -30,16 +33,26 @@ type SwaySection struct {
 }
 
 type SwayParser struct {
-	contentLines []string
-	readingLine  int
-	variables    map[string]string
+	contentLines     []string
+	lineSources      []string
+	lineNumbers      []int
+	readingLine      int
+	variables        map[string]string
+	dmsBindsPath     string
+	dmsBindsExists   bool
+	dmsBindsIncluded bool
+	includeCount     int
+	dmsIncludePos    int
+	processedFiles   map[string]bool
 }
 
 func NewSwayParser() *SwayParser {
 	return &SwayParser{
-		contentLines: []string{},
-		readingLine:  0,
-		variables:    make(map[string]string),
+		contentLines:   []string{},
+		readingLine:    0,
+		variables:      make(map[string]string),
+		dmsIncludePos:  -1,
+		processedFiles: make(map[string]bool),
 	}
 }
 
@@ -54,37 +67,110 @@ func (p *SwayParser) ReadContent(path string) error {
 		return err
 	}
 
-	var files []string
+	mainConfig := expandedPath
 	if info.IsDir() {
-		mainConfig := filepath.Join(expandedPath, "config")
-		if fileInfo, err := os.Stat(mainConfig); err == nil && fileInfo.Mode().IsRegular() {
-			files = []string{mainConfig}
-		} else {
+		mainConfig = filepath.Join(expandedPath, "config")
+		if fileInfo, err := os.Stat(mainConfig); err != nil || !fileInfo.Mode().IsRegular() {
 			return os.ErrNotExist
 		}
-	} else {
-		files = []string{expandedPath}
 	}
 
-	var combinedContent []string
-	for _, file := range files {
-		data, err := os.ReadFile(file)
-		if err != nil {
-			return err
-		}
-		combinedContent = append(combinedContent, string(data))
+	p.dmsBindsPath = filepath.Join(filepath.Dir(mainConfig), "dms", "binds.conf")
+	if abs, err := filepath.Abs(p.dmsBindsPath); err == nil {
+		p.dmsBindsPath = abs
 	}
-
-	if len(combinedContent) == 0 {
-		return os.ErrNotExist
+	if _, err := os.Stat(p.dmsBindsPath); err == nil {
+		p.dmsBindsExists = true
 	}
 
-	fullContent := strings.Join(combinedContent, "\n")
-	p.contentLines = strings.Split(fullContent, "\n")
+	p.contentLines = nil
+	p.lineSources = nil
+	p.lineNumbers = nil
+	if err := p.readFile(mainConfig); err != nil {
+		return err
+	}
 	p.parseVariables()
 	return nil
 }
 
+// readFile appends the lines of path to the parser content, splicing in
+// files pulled in by include directives at the point they appear.
+func (p *SwayParser) readFile(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if p.processedFiles[absPath] {
+		return nil
+	}
+	p.processedFiles[absPath] = true
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return err
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		if target, ok := strings.CutPrefix(strings.TrimSpace(line), "include "); ok {
+			p.handleInclude(strings.TrimSpace(target), filepath.Dir(absPath))
+			continue
+		}
+		p.contentLines = append(p.contentLines, line)
+		p.lineSources = append(p.lineSources, absPath)
+		p.lineNumbers = append(p.lineNumbers, i+1)
+	}
+	return nil
+}
+
+func (p *SwayParser) handleInclude(target, baseDir string) {
+	p.includeCount++
+
+	expanded, err := utils.ExpandPath(p.expandVariables(strings.Trim(target, `"'`)))
+	if err != nil {
+		return
+	}
+	if !filepath.IsAbs(expanded) {
+		expanded = filepath.Join(baseDir, expanded)
+	}
+
+	matches, err := filepath.Glob(expanded)
+	if err != nil {
+		return
+	}
+	for _, match := range matches {
+		if p.isDMSSource(match) {
+			p.dmsBindsIncluded = true
+			p.dmsIncludePos = p.includeCount
+		}
+		_ = p.readFile(match)
+	}
+}
+
+func (p *SwayParser) isDMSSource(path string) bool {
+	if p.dmsBindsPath == "" {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	return absPath == p.dmsBindsPath
+}
+
+func (p *SwayParser) sourceAt(lineNumber int) string {
+	if lineNumber < 0 || lineNumber >= len(p.lineSources) {
+		return ""
+	}
+	return p.lineSources[lineNumber]
+}
+
+func (p *SwayParser) lineAt(lineNumber int) int {
+	if lineNumber < 0 || lineNumber >= len(p.lineNumbers) {
+		return 0
+	}
+	return p.lineNumbers[lineNumber]
+}
+
 func (p *SwayParser) parseVariables() {
 	setRegex := regexp.MustCompile(`^\s*set\s+\$(\w+)\s+(.+)$`)
 	for _, line := range p.contentLines {
@@ -269,6 +355,16 @@ func (p *SwayParser) getKeybindAtLine(lineNumber int) *SwayKeyBinding {
 		}
 	}
 
+	source := p.sourceAt(lineNumber)
+	if comment == "" && lineNumber > 0 && p.isDMSSource(source) && p.sourceAt(lineNumber-1) == source {
+		// Sway has no trailing comments, so the DMS file puts descriptions
+		// on the line above each bind.
+		prev := strings.TrimSpace(p.contentLines[lineNumber-1])
+		if desc, ok := strings.CutPrefix(prev, "# "); ok && !strings.HasPrefix(desc, SwayHideComment) {
+			comment = strings.TrimSpace(desc)
+		}
+	}
+
 	if comment == "" {
 		comment = swayAutogenerateComment(command)
 	}
@@ -280,6 +376,8 @@ func (p *SwayParser) getKeybindAtLine(lineNumber int) *SwayKeyBinding {
 		Key:     key,
 		Command: command,
 		Comment: comment,
+		Source:  source,
+		Line:    p.lineAt(lineNumber),
 	}
 }
 
@@ -362,3 +460,115 @@ func ParseSwayKeys(path string) (*SwaySection, error) {
 	}
 	return parser.ParseKeys(), nil
 }
+
+type SwayParseResult struct {
+	Section            *SwaySection
+	DMSBindsIncluded   bool
+	DMSStatus          *keybinds.DMSBindsStatus
+	ConflictingConfigs map[string]*SwayKeyBinding
+}
+
+func swayBindKey(kb *SwayKeyBinding) string {
+	parts := make([]string, 0, len(kb.Mods)+1)
+	parts = append(parts, kb.Mods...)
+	parts = append(parts, kb.Key)
+	return strings.ToLower(strings.Join(parts, "+"))
+}
+
+// resolveDMSBinds drops config binds for keys DMS also binds, recording the
+// ones that come after the DMS include since sway lets the last bind win.
+func (p *SwayParser) resolveDMSBinds(section *SwaySection) (map[string]*SwayKeyBinding, int) {
+	var flat []*SwayKeyBinding
+	var collect func(s *SwaySection)
+	collect = func(s *SwaySection) {
+		for i := range s.Keybinds {
+			flat = append(flat, &s.Keybinds[i])
+		}
+		for i := range s.Children {
+			collect(&s.Children[i])
+		}
+	}
+	collect(section)
+
+	dmsKeys := make(map[string]bool)
+	conflicts := make(map[string]*SwayKeyBinding)
+	bindsAfterDMS := 0
+	for _, kb := range flat {
+		key := swayBindKey(kb)
+		switch {
+		case p.isDMSSource(kb.Source):
+			dmsKeys[key] = true
+		case dmsKeys[key]:
+			bindsAfterDMS++
+			shadow := *kb
+			conflicts[key] = &shadow
+		}
+	}
+
+	var filter func(s *SwaySection)
+	filter = func(s *SwaySection) {
+		kept := s.Keybinds[:0]
+		for _, kb := range s.Keybinds {
+			if dmsKeys[swayBindKey(&kb)] && !p.isDMSSource(kb.Source) {
+				continue
+			}
+			kept = append(kept, kb)
+		}
+		s.Keybinds = kept
+		for i := range s.Children {
+			filter(&s.Children[i])
+		}
+	}
+	filter(section)
+
+	return conflicts, bindsAfterDMS
+}
+
+func (p *SwayParser) buildDMSStatus(bindsAfterDMS int) *keybinds.DMSBindsStatus {
+	status := &keybinds.DMSBindsStatus{
+		Exists:          p.dmsBindsExists,
+		Included:        p.dmsBindsIncluded,
+		IncludePosition: p.dmsIncludePos,
+		TotalIncludes:   p.includeCount,
+		BindsAfterDMS:   bindsAfterDMS,
+	}
+
+	switch {
+	case !p.dmsBindsExists:
+		status.StatusMessage = "dms/binds.conf does not exist"
+	case !p.dmsBindsIncluded:
+		status.StatusMessage = "dms/binds.conf is not included in config"
+	case bindsAfterDMS > 0:
+		status.Effective = true
+		status.OverriddenBy = bindsAfterDMS
+		status.StatusMessage = "Some DMS binds may be overridden by config binds"
+	default:
+		status.Effective = true
+		status.StatusMessage = "DMS binds are active"
+	}
+
+	return status
+}
+
+func ParseSwayKeysWithDMS(path string) (*SwayParseResult, error) {
+	parser := NewSwayParser()
+	if err := parser.ReadContent(path); err != nil {
+		return nil, err
+	}
+
+	if parser.dmsBindsExists && !parser.dmsBindsIncluded {
+		if err := parser.readFile(parser.dmsBindsPath); err == nil {
+			parser.parseVariables()
+		}
+	}
+
+	section := parser.ParseKeys()
+	conflicts, bindsAfterDMS := parser.resolveDMSBinds(section)
+
+	return &SwayParseResult{
+		Section:            section,
+		DMSBindsIncluded:   parser.dmsBindsIncluded,
+		DMSStatus:          parser.buildDMSStatus(bindsAfterDMS),
+		ConflictingConfigs: conflicts,
+	}, nil
+}
@@ -3,7 +3,10 @@ package providers
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
 )
 
 func TestSwayProviderName(t *testing.T) {
@@ -291,3 +294,116 @@ bindsym $mod+1 workspace number 1
 		t.Error("Did not find terminal keybind with correct key and description")
 	}
 }
+
+func findSwayBind(sheet *keybinds.CheatSheet, key string) *keybinds.Keybind {
+	for _, binds := range sheet.Binds {
+		for i := range binds {
+			if binds[i].Key == key {
+				return &binds[i]
+			}
+		}
+	}
+	return nil
+}
+
+func TestSwaySetAndRemoveBind(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "config"), []byte("include dms/binds.conf\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	provider := NewSwayProvider(tmpDir)
+	if err := provider.SetBind("Mod4+Shift+t", "exec kitty", "Terminal", map[string]any{"allow-when-locked": true}); err != nil {
+		t.Fatalf("SetBind failed: %v", err)
+	}
+	if err := provider.SetBind("Mod4+q", "kill", "", nil); err != nil {
+		t.Fatalf("SetBind failed: %v", err)
+	}
+	if err := provider.SetBind("Mod4+x", "", "", nil); err == nil {
+		t.Error("Expected error for empty action")
+	}
+
+	data, err := os.ReadFile(provider.GetOverridePath())
+	if err != nil {
+		t.Fatalf("Failed to read override file: %v", err)
+	}
+	if !strings.Contains(string(data), "# Terminal\nbindsym --locked Mod4+Shift+t exec kitty\n") {
+		t.Errorf("Unexpected override content:\n%s", data)
+	}
+
+	sheet, err := provider.GetCheatSheet()
+	if err != nil {
+		t.Fatalf("GetCheatSheet failed: %v", err)
+	}
+	bind := findSwayBind(sheet, "Mod4+Shift+t")
+	if bind == nil {
+		t.Fatal("DMS bind not found in cheatsheet")
+	}
+	if bind.Source != "dms" || bind.Description != "Terminal" {
+		t.Errorf("bind = %+v, want dms source with description", bind)
+	}
+
+	if err := provider.RemoveBind("mod4+shift+T"); err != nil {
+		t.Fatalf("RemoveBind failed: %v", err)
+	}
+	binds, err := provider.loadOverrideBinds()
+	if err != nil {
+		t.Fatalf("loadOverrideBinds failed: %v", err)
+	}
+	if len(binds) != 1 || binds["mod4+q"] == nil {
+		t.Errorf("Expected only Mod4+q to remain, got %v", binds)
+	}
+}
+
+func TestSwayDMSStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        string
+		writeDMS      bool
+		wantIncluded  bool
+		wantEffective bool
+		wantOverrides int
+	}{
+		{"missing", "bindsym Mod4+q kill\n", false, false, false, 0},
+		{"not_included", "bindsym Mod4+q kill\n", true, false, false, 0},
+		{"included", "bindsym Mod4+q kill\ninclude dms/binds.conf\n", true, true, true, 0},
+		{"overridden", "include ~/nonexistent/*.conf\ninclude dms/binds.conf\nbindsym Mod4+t exec foot\n", true, true, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(tmpDir, "config"), []byte(tt.config), 0o644); err != nil {
+				t.Fatalf("Failed to write test config: %v", err)
+			}
+			provider := NewSwayProvider(tmpDir)
+			if tt.writeDMS {
+				if err := provider.SetBind("Mod4+t", "exec kitty", "Terminal", nil); err != nil {
+					t.Fatalf("SetBind failed: %v", err)
+				}
+			}
+
+			sheet, err := provider.GetCheatSheet()
+			if err != nil {
+				t.Fatalf("GetCheatSheet failed: %v", err)
+			}
+			status := sheet.DMSStatus
+			if status.Exists != tt.writeDMS || status.Included != tt.wantIncluded || status.Effective != tt.wantEffective {
+				t.Errorf("status = %+v", status)
+			}
+			if status.OverriddenBy != tt.wantOverrides {
+				t.Errorf("OverriddenBy = %d, want %d", status.OverriddenBy, tt.wantOverrides)
+			}
+
+			if tt.wantOverrides > 0 {
+				bind := findSwayBind(sheet, "Mod4+t")
+				if bind == nil || bind.Conflict == nil || bind.Conflict.Action != "exec foot" {
+					t.Errorf("Expected DMS bind with conflict, got %+v", bind)
+				}
+				if status.IncludePosition != 2 || status.TotalIncludes != 2 {
+					t.Errorf("include position = %d/%d, want 2/2", status.IncludePosition, status.TotalIncludes)
+				}
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ type Keybind struct {
 	Action          string   `json:"action,omitempty"`
 	Subcategory     string   `json:"subcat,omitempty"`
 	Source          string   `json:"source,omitempty"`
+	File            string   `json:"file,omitempty"`
+	Line            int      `json:"line,omitempty"`
 	HideOnOverlay   bool     `json:"hideOnOverlay,omitempty"`
 	CooldownMs      int      `json:"cooldownMs,omitempty"`
 	Flags           string   `json:"flags,omitempty"` // Hyprland bind flags: e=repeat, l=locked, r=release, o=long-press
@@ -0,0 +1,200 @@
+// Package material implements the Material You color system: HCT, source
+// color extraction from images and the Material 3 dynamic schemes. It
+// follows Google's material-color-utilities, which matugen is built on, so
+// both produce the same palettes.
+package material
+
+import (
+	"fmt"
+	"math"
+)
+
+// ARGB is a color packed as 0xAARRGGBB.
+type ARGB uint32
+
+var (
+	srgbToXYZ = [3][3]float64{
+		{0.41233895, 0.35762064, 0.18051042},
+		{0.2126, 0.7152, 0.0722},
+		{0.01932141, 0.11916382, 0.95034478},
+	}
+	xyzToSRGB = [3][3]float64{
+		{3.2413774792388685, -1.5376652402851851, -0.49885366846268053},
+		{-0.9691452513005321, 1.8758853451067872, 0.04156585616912061},
+		{0.05562093689691305, -0.20395524564742123, 1.0571799111220335},
+	}
+	whitePointD65 = [3]float64{95.047, 100.0, 108.883}
+)
+
+func ARGBFromRGB(r, g, b uint8) ARGB {
+	return ARGB(0xff000000 | uint32(r)<<16 | uint32(g)<<8 | uint32(b))
+}
+
+// ParseHex reads #RRGGBB or #RGB.
+func ParseHex(s string) (ARGB, error) {
+	hex := s
+	if len(hex) > 0 && hex[0] == '#' {
+		hex = hex[1:]
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	var r, g, b uint8
+	if len(hex) != 6 {
+		return 0, fmt.Errorf("invalid color %q", s)
+	}
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return 0, fmt.Errorf("invalid color %q", s)
+	}
+	return ARGBFromRGB(r, g, b), nil
+}
+
+func (c ARGB) Alpha() uint8 { return uint8(c >> 24) }
+func (c ARGB) Red() uint8   { return uint8(c >> 16) }
+func (c ARGB) Green() uint8 { return uint8(c >> 8) }
+func (c ARGB) Blue() uint8  { return uint8(c) }
+
+func (c ARGB) Hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.Red(), c.Green(), c.Blue())
+}
+
+// linearized maps an sRGB component to linear RGB on a 0-100 scale.
+func linearized(component uint8) float64 {
+	normalized := float64(component) / 255.0
+	if normalized <= 0.040449936 {
+		return normalized / 12.92 * 100.0
+	}
+	return math.Pow((normalized+0.055)/1.055, 2.4) * 100.0
+}
+
+// delinearized maps a 0-100 linear component back to sRGB.
+func delinearized(component float64) uint8 {
+	normalized := component / 100.0
+	var d float64
+	if normalized <= 0.0031308 {
+		d = normalized * 12.92
+	} else {
+		d = 1.055*math.Pow(normalized, 1.0/2.4) - 0.055
+	}
+	return uint8(clampInt(0, 255, int(math.Round(d*255.0))))
+}
+
+func argbFromLinrgb(linrgb [3]float64) ARGB {
+	return ARGBFromRGB(delinearized(linrgb[0]), delinearized(linrgb[1]), delinearized(linrgb[2]))
+}
+
+func xyzFromARGB(c ARGB) [3]float64 {
+	return matrixMultiply([3]float64{linearized(c.Red()), linearized(c.Green()), linearized(c.Blue())}, srgbToXYZ)
+}
+
+func argbFromXYZ(x, y, z float64) ARGB {
+	return argbFromLinrgb(matrixMultiply([3]float64{x, y, z}, xyzToSRGB))
+}
+
+func labFromARGB(c ARGB) [3]float64 {
+	xyz := xyzFromARGB(c)
+	fx := labF(xyz[0] / whitePointD65[0])
+	fy := labF(xyz[1] / whitePointD65[1])
+	fz := labF(xyz[2] / whitePointD65[2])
+	return [3]float64{116.0*fy - 16, 500.0 * (fx - fy), 200.0 * (fy - fz)}
+}
+
+func argbFromLab(lab [3]float64) ARGB {
+	fy := (lab[0] + 16.0) / 116.0
+	fx := lab[1]/500.0 + fy
+	fz := fy - lab[2]/200.0
+	return argbFromXYZ(
+		labInvf(fx)*whitePointD65[0],
+		labInvf(fy)*whitePointD65[1],
+		labInvf(fz)*whitePointD65[2],
+	)
+}
+
+// LstarFromARGB is the CIE L* (perceptual lightness) of a color, which is
+// the HCT tone.
+func LstarFromARGB(c ARGB) float64 {
+	return 116.0*labF(xyzFromARGB(c)[1]/100.0) - 16.0
+}
+
+func argbFromLstar(lstar float64) ARGB {
+	component := delinearized(yFromLstar(lstar))
+	return ARGBFromRGB(component, component, component)
+}
+
+func yFromLstar(lstar float64) float64 {
+	return 100.0 * labInvf((lstar+16.0)/116.0)
+}
+
+func lstarFromY(y float64) float64 {
+	return labF(y/100.0)*116.0 - 16.0
+}
+
+func labF(t float64) float64 {
+	const e = 216.0 / 24389.0
+	const kappa = 24389.0 / 27.0
+	if t > e {
+		return math.Cbrt(t)
+	}
+	return (kappa*t + 16) / 116
+}
+
+func labInvf(ft float64) float64 {
+	const e = 216.0 / 24389.0
+	const kappa = 24389.0 / 27.0
+	ft3 := ft * ft * ft
+	if ft3 > e {
+		return ft3
+	}
+	return (116*ft - 16) / kappa
+}
+
+func matrixMultiply(row [3]float64, m [3][3]float64) [3]float64 {
+	return [3]float64{
+		row[0]*m[0][0] + row[1]*m[0][1] + row[2]*m[0][2],
+		row[0]*m[1][0] + row[1]*m[1][1] + row[2]*m[1][2],
+		row[0]*m[2][0] + row[1]*m[2][1] + row[2]*m[2][2],
+	}
+}
+
+func clampInt(lo, hi, v int) int {
+	return min(max(v, lo), hi)
+}
+
+func clampFloat(lo, hi, v float64) float64 {
+	return min(max(v, lo), hi)
+}
+
+func signum(v float64) float64 {
+	switch {
+	case v < 0:
+		return -1
+	case v == 0:
+		return 0
+	default:
+		return 1
+	}
+}
+
+func lerp(start, stop, amount float64) float64 {
+	return (1.0-amount)*start + amount*stop
+}
+
+func sanitizeDegreesInt(degrees int) int {
+	degrees %= 360
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
+
+func sanitizeDegrees(degrees float64) float64 {
+	degrees = math.Mod(degrees, 360.0)
+	if degrees < 0 {
+		degrees += 360.0
+	}
+	return degrees
+}
+
+func differenceDegrees(a, b float64) float64 {
+	return 180.0 - math.Abs(math.Abs(a-b)-180.0)
+}
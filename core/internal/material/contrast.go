@@ -0,0 +1,64 @@
+package material
+
+import "math"
+
+// ratioOfTones is the WCAG contrast ratio between two tones.
+func ratioOfTones(a, b float64) float64 {
+	return ratioOfYs(yFromLstar(clampFloat(0, 100, a)), yFromLstar(clampFloat(0, 100, b)))
+}
+
+func ratioOfYs(y1, y2 float64) float64 {
+	lighter, darker := math.Max(y1, y2), math.Min(y1, y2)
+	return (lighter + 5.0) / (darker + 5.0)
+}
+
+// lighterTone returns a tone >= tone that reaches ratio, or -1.
+func lighterTone(tone, ratio float64) float64 {
+	if tone < 0 || tone > 100 {
+		return -1
+	}
+	darkY := yFromLstar(tone)
+	lightY := ratio*(darkY+5.0) - 5.0
+	realContrast := ratioOfYs(lightY, darkY)
+	if realContrast < ratio && math.Abs(realContrast-ratio) > 0.04 {
+		return -1
+	}
+	// small offset so rounding to an sRGB color still meets the ratio
+	result := lstarFromY(lightY) + 0.4
+	if result < 0 || result > 100 {
+		return -1
+	}
+	return result
+}
+
+// darkerTone returns a tone <= tone that reaches ratio, or -1.
+func darkerTone(tone, ratio float64) float64 {
+	if tone < 0 || tone > 100 {
+		return -1
+	}
+	lightY := yFromLstar(tone)
+	darkY := (lightY+5.0)/ratio - 5.0
+	realContrast := ratioOfYs(lightY, darkY)
+	if realContrast < ratio && math.Abs(realContrast-ratio) > 0.04 {
+		return -1
+	}
+	result := lstarFromY(darkY) - 0.4
+	if result < 0 || result > 100 {
+		return -1
+	}
+	return result
+}
+
+func lighterToneUnsafe(tone, ratio float64) float64 {
+	if t := lighterTone(tone, ratio); t >= 0 {
+		return t
+	}
+	return 100
+}
+
+func darkerToneUnsafe(tone, ratio float64) float64 {
+	if t := darkerTone(tone, ratio); t >= 0 {
+		return t
+	}
+	return 0
+}
@@ -0,0 +1,236 @@
+package material
+
+import "math"
+
+// contrastCurve gives a required contrast ratio for the low, normal,
+// medium and high contrast levels (-1, 0, 0.5, 1).
+type contrastCurve struct {
+	low, normal, medium, high float64
+}
+
+func (c *contrastCurve) get(level float64) float64 {
+	switch {
+	case level <= -1:
+		return c.low
+	case level < 0:
+		return lerp(c.low, c.normal, level+1)
+	case level < 0.5:
+		return lerp(c.normal, c.medium, level/0.5)
+	case level < 1:
+		return lerp(c.medium, c.high, (level-0.5)/0.5)
+	default:
+		return c.high
+	}
+}
+
+type tonePolarity int
+
+const (
+	polarityDarker tonePolarity = iota
+	polarityLighter
+	polarityNearer
+	polarityFarther
+)
+
+// toneDeltaPair keeps two roles, such as primary and primary_container, a
+// minimum tone apart.
+type toneDeltaPair struct {
+	roleA, roleB *dynamicColor
+	delta        float64
+	polarity     tonePolarity
+	stayTogether bool
+}
+
+// dynamicColor is a color role: a palette, a preferred tone and the
+// contrast it must keep against its background.
+type dynamicColor struct {
+	name             string
+	palette          func(*Scheme) *TonalPalette
+	tone             func(*Scheme) float64
+	isBackground     bool
+	background       func(*Scheme) *dynamicColor
+	secondBackground func(*Scheme) *dynamicColor
+	contrastCurve    *contrastCurve
+	toneDeltaPair    func(*Scheme) toneDeltaPair
+}
+
+func (dc *dynamicColor) argb(s *Scheme) ARGB {
+	return dc.palette(s).Tone(dc.getTone(s))
+}
+
+func (dc *dynamicColor) getTone(s *Scheme) float64 {
+	decreasingContrast := s.ContrastLevel < 0
+
+	if dc.toneDeltaPair != nil {
+		pair := dc.toneDeltaPair(s)
+		bgTone := dc.background(s).getTone(s)
+
+		aIsNearer := pair.polarity == polarityNearer ||
+			(pair.polarity == polarityLighter && !s.IsDark) ||
+			(pair.polarity == polarityDarker && s.IsDark)
+		nearer, farther := pair.roleA, pair.roleB
+		if !aIsNearer {
+			nearer, farther = farther, nearer
+		}
+		amNearer := dc.name == nearer.name
+		expansionDir := -1.0
+		if s.IsDark {
+			expansionDir = 1.0
+		}
+
+		nContrast := nearer.contrastCurve.get(s.ContrastLevel)
+		fContrast := farther.contrastCurve.get(s.ContrastLevel)
+
+		nTone := nearer.tone(s)
+		if ratioOfTones(bgTone, nTone) < nContrast {
+			nTone = foregroundTone(bgTone, nContrast)
+		}
+		fTone := farther.tone(s)
+		if ratioOfTones(bgTone, fTone) < fContrast {
+			fTone = foregroundTone(bgTone, fContrast)
+		}
+		if decreasingContrast {
+			nTone = foregroundTone(bgTone, nContrast)
+			fTone = foregroundTone(bgTone, fContrast)
+		}
+
+		if (fTone-nTone)*expansionDir < pair.delta {
+			fTone = clampFloat(0, 100, nTone+pair.delta*expansionDir)
+			if (fTone-nTone)*expansionDir < pair.delta {
+				nTone = clampFloat(0, 100, fTone-pair.delta*expansionDir)
+			}
+		}
+
+		// avoid the 50-59 band, which contrasts poorly with both light and dark
+		switch {
+		case 50 <= nTone && nTone < 60:
+			if expansionDir > 0 {
+				nTone = 60
+				fTone = math.Max(fTone, nTone+pair.delta*expansionDir)
+			} else {
+				nTone = 49
+				fTone = math.Min(fTone, nTone+pair.delta*expansionDir)
+			}
+		case 50 <= fTone && fTone < 60:
+			switch {
+			case pair.stayTogether && expansionDir > 0:
+				nTone = 60
+				fTone = math.Max(fTone, nTone+pair.delta*expansionDir)
+			case pair.stayTogether:
+				nTone = 49
+				fTone = math.Min(fTone, nTone+pair.delta*expansionDir)
+			case expansionDir > 0:
+				fTone = 60
+			default:
+				fTone = 49
+			}
+		}
+
+		if amNearer {
+			return nTone
+		}
+		return fTone
+	}
+
+	answer := dc.tone(s)
+	if dc.background == nil {
+		return answer
+	}
+
+	bgTone := dc.background(s).getTone(s)
+	desiredRatio := dc.contrastCurve.get(s.ContrastLevel)
+	if ratioOfTones(bgTone, answer) < desiredRatio {
+		answer = foregroundTone(bgTone, desiredRatio)
+	}
+	if decreasingContrast {
+		answer = foregroundTone(bgTone, desiredRatio)
+	}
+	if dc.isBackground && 50 <= answer && answer < 60 {
+		if ratioOfTones(49, bgTone) >= desiredRatio {
+			answer = 49
+		} else {
+			answer = 60
+		}
+	}
+
+	if dc.secondBackground == nil {
+		return answer
+	}
+
+	bgTone1, bgTone2 := dc.background(s).getTone(s), dc.secondBackground(s).getTone(s)
+	upper, lower := math.Max(bgTone1, bgTone2), math.Min(bgTone1, bgTone2)
+	if ratioOfTones(upper, answer) >= desiredRatio && ratioOfTones(lower, answer) >= desiredRatio {
+		return answer
+	}
+
+	lightOption := lighterTone(upper, desiredRatio)
+	darkOption := darkerTone(lower, desiredRatio)
+	if tonePrefersLightForeground(bgTone1) || tonePrefersLightForeground(bgTone2) {
+		if lightOption < 0 {
+			return 100
+		}
+		return lightOption
+	}
+	if lightOption != -1 && darkOption == -1 {
+		return lightOption
+	}
+	if darkOption < 0 {
+		return 0
+	}
+	return darkOption
+}
+
+// foregroundTone picks the lighter or darker tone that best reaches ratio
+// against bgTone.
+func foregroundTone(bgTone, ratio float64) float64 {
+	lighter := lighterToneUnsafe(bgTone, ratio)
+	darker := darkerToneUnsafe(bgTone, ratio)
+	lighterRatio := ratioOfTones(lighter, bgTone)
+	darkerRatio := ratioOfTones(darker, bgTone)
+
+	if tonePrefersLightForeground(bgTone) {
+		negligible := math.Abs(lighterRatio-darkerRatio) < 0.1 && lighterRatio < ratio && darkerRatio < ratio
+		if lighterRatio >= ratio || lighterRatio >= darkerRatio || negligible {
+			return lighter
+		}
+		return darker
+	}
+	if darkerRatio >= ratio || darkerRatio >= lighterRatio {
+		return darker
+	}
+	return lighter
+}
+
+func tonePrefersLightForeground(tone float64) bool {
+	return math.Round(tone) < 60
+}
+
+// findDesiredChromaByTone walks the tone from the given one until the
+// palette can reach the requested chroma.
+func findDesiredChromaByTone(hue, chroma, tone float64, byDecreasingTone bool) float64 {
+	answer := tone
+	closest := HctFrom(hue, chroma, tone)
+	if closest.Chroma >= chroma {
+		return answer
+	}
+	chromaPeak := closest.Chroma
+	for closest.Chroma < chroma {
+		if byDecreasingTone {
+			answer--
+		} else {
+			answer++
+		}
+		potential := HctFrom(hue, chroma, answer)
+		if chromaPeak > potential.Chroma {
+			break
+		}
+		if math.Abs(potential.Chroma-chroma) < 0.4 {
+			break
+		}
+		if math.Abs(potential.Chroma-chroma) < math.Abs(closest.Chroma-chroma) {
+			closest = potential
+		}
+		chromaPeak = math.Max(chromaPeak, potential.Chroma)
+	}
+	return answer
+}
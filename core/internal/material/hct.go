@@ -0,0 +1,124 @@
+package material
+
+import "math"
+
+// viewingConditions are the CAM16 parameters for sRGB on a mid-gray
+// background, the defaults used throughout Material.
+type viewingConditions struct {
+	n, aw, nbb, ncb, c, nc float64
+	rgbD                   [3]float64
+	fl, flRoot, z          float64
+}
+
+var defaultViewing = newViewingConditions()
+
+func newViewingConditions() viewingConditions {
+	const surround = 2.0
+	adaptingLuminance := (200.0 / math.Pi) * yFromLstar(50.0) / 100.0
+	wp := whitePointD65
+
+	rW := wp[0]*0.401288 + wp[1]*0.650173 + wp[2]*-0.051461
+	gW := wp[0]*-0.250268 + wp[1]*1.204414 + wp[2]*0.045854
+	bW := wp[0]*-0.002079 + wp[1]*0.048952 + wp[2]*0.953127
+
+	f := 0.8 + surround/10.0
+	var c float64
+	if f >= 0.9 {
+		c = lerp(0.59, 0.69, (f-0.9)*10.0)
+	} else {
+		c = lerp(0.525, 0.59, (f-0.8)*10.0)
+	}
+	d := f * (1.0 - (1.0/3.6)*math.Exp((-adaptingLuminance-42.0)/92.0))
+	d = clampFloat(0, 1, d)
+
+	rgbD := [3]float64{d*(100.0/rW) + 1.0 - d, d*(100.0/gW) + 1.0 - d, d*(100.0/bW) + 1.0 - d}
+	k := 1.0 / (5.0*adaptingLuminance + 1.0)
+	k4 := k * k * k * k
+	k4F := 1.0 - k4
+	fl := k4*adaptingLuminance + 0.1*k4F*k4F*math.Cbrt(5.0*adaptingLuminance)
+	n := yFromLstar(50.0) / wp[1]
+	z := 1.48 + math.Sqrt(n)
+	nbb := 0.725 / math.Pow(n, 0.2)
+
+	var rgbA [3]float64
+	for i, w := range [3]float64{rW, gW, bW} {
+		factor := math.Pow(fl*rgbD[i]*w/100.0, 0.42)
+		rgbA[i] = 400.0 * factor / (factor + 27.13)
+	}
+	aw := (2.0*rgbA[0] + rgbA[1] + 0.05*rgbA[2]) * nbb
+
+	return viewingConditions{
+		n: n, aw: aw, nbb: nbb, ncb: nbb, c: c, nc: f,
+		rgbD: rgbD, fl: fl, flRoot: math.Pow(fl, 0.25), z: z,
+	}
+}
+
+// cam16HueChroma returns the CAM16 hue and chroma of a color.
+func cam16HueChroma(c ARGB) (hue, chroma float64) {
+	vc := defaultViewing
+	xyz := xyzFromARGB(c)
+
+	rC := 0.401288*xyz[0] + 0.650173*xyz[1] - 0.051461*xyz[2]
+	gC := -0.250268*xyz[0] + 1.204414*xyz[1] + 0.045854*xyz[2]
+	bC := -0.002079*xyz[0] + 0.048952*xyz[1] + 0.953127*xyz[2]
+
+	rA := chromaticAdaptation(vc.rgbD[0] * rC * vc.fl / 100.0)
+	gA := chromaticAdaptation(vc.rgbD[1] * gC * vc.fl / 100.0)
+	bA := chromaticAdaptation(vc.rgbD[2] * bC * vc.fl / 100.0)
+
+	a := (11.0*rA + -12.0*gA + bA) / 11.0
+	b := (rA + gA - 2.0*bA) / 9.0
+	u := (20.0*rA + 20.0*gA + 21.0*bA) / 20.0
+	p2 := (40.0*rA + 20.0*gA + bA) / 20.0
+
+	hue = sanitizeDegrees(math.Atan2(b, a) * 180.0 / math.Pi)
+
+	ac := p2 * vc.nbb
+	j := 100.0 * math.Pow(ac/vc.aw, vc.c*vc.z)
+
+	huePrime := hue
+	if hue < 20.14 {
+		huePrime += 360
+	}
+	eHue := 0.25 * (math.Cos(huePrime*math.Pi/180.0+2.0) + 3.8)
+	p1 := 50000.0 / 13.0 * eHue * vc.nc * vc.ncb
+	t := p1 * math.Hypot(a, b) / (u + 0.305)
+	alpha := math.Pow(t, 0.9) * math.Pow(1.64-math.Pow(0.29, vc.n), 0.73)
+	chroma = alpha * math.Sqrt(j/100.0)
+	return hue, chroma
+}
+
+func chromaticAdaptation(component float64) float64 {
+	af := math.Pow(math.Abs(component), 0.42)
+	return signum(component) * 400.0 * af / (af + 27.13)
+}
+
+func inverseChromaticAdaptation(adapted float64) float64 {
+	adaptedAbs := math.Abs(adapted)
+	base := math.Max(0, 27.13*adaptedAbs/(400.0-adaptedAbs))
+	return signum(adapted) * math.Pow(base, 1.0/0.42)
+}
+
+// Hct is a color in the hue, chroma, tone space: CAM16 hue and chroma with
+// L* as tone.
+type Hct struct {
+	Hue    float64
+	Chroma float64
+	Tone   float64
+	argb   ARGB
+}
+
+func HctFromARGB(c ARGB) Hct {
+	hue, chroma := cam16HueChroma(c)
+	return Hct{Hue: hue, Chroma: chroma, Tone: LstarFromARGB(c), argb: c}
+}
+
+// HctFrom returns the closest displayable color to the given hue, chroma
+// and tone. Tone is kept exact; chroma is reduced when out of gamut.
+func HctFrom(hue, chroma, tone float64) Hct {
+	return HctFromARGB(solveToARGB(hue, chroma, tone))
+}
+
+func (h Hct) ARGB() ARGB {
+	return h.argb
+}
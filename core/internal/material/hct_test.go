@@ -0,0 +1,91 @@
+package material
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHctFromARGB(t *testing.T) {
+	for _, tc := range []struct {
+		argb              ARGB
+		hue, chroma, tone float64
+	}{
+		{0xffff0000, 27.408, 113.358, 53.233},
+		{0xff00ff00, 142.139, 108.410, 87.737},
+		{0xff0000ff, 282.788, 87.231, 32.303},
+		{0xffffffff, 209.492, 2.869, 100.0},
+	} {
+		h := HctFromARGB(tc.argb)
+		assert.InDelta(t, tc.hue, h.Hue, 0.001, "%08x hue", uint32(tc.argb))
+		assert.InDelta(t, tc.chroma, h.Chroma, 0.001, "%08x chroma", uint32(tc.argb))
+		assert.InDelta(t, tc.tone, h.Tone, 0.001, "%08x tone", uint32(tc.argb))
+	}
+
+	black := HctFromARGB(0xff000000)
+	assert.Zero(t, black.Chroma)
+	assert.Zero(t, black.Tone)
+}
+
+func TestHctRoundTrip(t *testing.T) {
+	for _, c := range []ARGB{0xffff0000, 0xff00ff00, 0xff0000ff, 0xff4285f4, 0xff6750a4, 0xff7d5260, 0xff123456} {
+		h := HctFromARGB(c)
+		assert.Equal(t, c, HctFrom(h.Hue, h.Chroma, h.Tone).ARGB(), "%08x", uint32(c))
+	}
+}
+
+func TestHctFromKeepsTone(t *testing.T) {
+	for hue := 0.0; hue < 360; hue += 30 {
+		for _, tone := range []float64{10, 30, 50, 70, 90} {
+			h := HctFrom(hue, 200, tone)
+			assert.InDelta(t, tone, h.Tone, 0.5, "hue %v tone %v", hue, tone)
+		}
+	}
+}
+
+func TestTonalPalette(t *testing.T) {
+	p := TonalPaletteFromHct(HctFromARGB(0xff0000ff))
+	for tone, want := range map[float64]ARGB{
+		100: 0xffffffff,
+		95:  0xfff1efff,
+		90:  0xffe0e0ff,
+		80:  0xffbec2ff,
+		70:  0xff9da3ff,
+		60:  0xff7c84ff,
+		50:  0xff5a64ff,
+		40:  0xff343dff,
+		30:  0xff0000ef,
+		20:  0xff0001ac,
+		10:  0xff00006e,
+		0:   0xff000000,
+	} {
+		assert.Equal(t, want.Hex(), p.Tone(tone).Hex(), "tone %v", tone)
+	}
+}
+
+func TestContrast(t *testing.T) {
+	assert.InDelta(t, 21.0, ratioOfTones(0, 100), 0.001)
+	assert.InDelta(t, 1.0, ratioOfTones(50, 50), 0.001)
+
+	light := lighterTone(40, 4.5)
+	assert.GreaterOrEqual(t, ratioOfTones(40, light), 4.5)
+	assert.Equal(t, -1.0, lighterTone(90, 4.5))
+	assert.Equal(t, 100.0, lighterToneUnsafe(90, 4.5))
+
+	dark := darkerTone(60, 4.5)
+	assert.GreaterOrEqual(t, ratioOfTones(60, dark), 4.5)
+	assert.Equal(t, 0.0, darkerToneUnsafe(10, 4.5))
+}
+
+func TestParseHex(t *testing.T) {
+	c, err := ParseHex("#4285F4")
+	assert.NoError(t, err)
+	assert.Equal(t, ARGB(0xff4285f4), c)
+
+	c, err = ParseHex("fff")
+	assert.NoError(t, err)
+	assert.Equal(t, ARGB(0xffffffff), c)
+
+	_, err = ParseHex("#12345")
+	assert.Error(t, err)
+}
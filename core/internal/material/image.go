@@ -0,0 +1,35 @@
+package material
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// sourceImageSize is the edge the image is scaled down to before
+// quantizing, as matugen does.
+const sourceImageSize = 128
+
+// SourceColorsFromImage returns the theme source color candidates of an
+// image, best first.
+func SourceColorsFromImage(img image.Image) []ARGB {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w > sourceImageSize || h > sourceImageSize {
+		scale := float64(sourceImageSize) / float64(max(w, h))
+		w, h = max(1, int(float64(w)*scale)), max(1, int(float64(h)*scale))
+	}
+	scaled := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, b, draw.Src, nil)
+
+	pixels := make([]ARGB, 0, w*h)
+	for i := 0; i < len(scaled.Pix); i += 4 {
+		p := scaled.Pix[i : i+4 : i+4]
+		pixels = append(pixels, ARGB(uint32(p[3])<<24|uint32(p[0])<<16|uint32(p[1])<<8|uint32(p[2])))
+	}
+	return Score(QuantizeCelebi(pixels, 128), 4)
+}
+
+func SourceColorFromImage(img image.Image) ARGB {
+	return SourceColorsFromImage(img)[0]
+}
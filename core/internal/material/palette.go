@@ -0,0 +1,35 @@
+package material
+
+import "sync"
+
+// TonalPalette is every tone of a single hue and chroma.
+type TonalPalette struct {
+	Hue    float64
+	Chroma float64
+
+	mu    sync.Mutex
+	cache map[float64]ARGB
+}
+
+func NewTonalPalette(hue, chroma float64) *TonalPalette {
+	return &TonalPalette{Hue: hue, Chroma: chroma, cache: make(map[float64]ARGB)}
+}
+
+func TonalPaletteFromHct(h Hct) *TonalPalette {
+	return NewTonalPalette(h.Hue, h.Chroma)
+}
+
+func (p *TonalPalette) Tone(tone float64) ARGB {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c, ok := p.cache[tone]; ok {
+		return c
+	}
+	c := HctFrom(p.Hue, p.Chroma, tone).ARGB()
+	p.cache[tone] = c
+	return c
+}
+
+func (p *TonalPalette) Hct(tone float64) Hct {
+	return HctFromARGB(p.Tone(tone))
+}
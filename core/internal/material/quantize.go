@@ -0,0 +1,413 @@
+package material
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// QuantizeCelebi reduces pixels to at most maxColors colors with their
+// pixel counts: Wu's quantizer picks the starting clusters and weighted
+// k-means in L*a*b* refines them. Pixels that are not fully opaque are
+// ignored.
+func QuantizeCelebi(pixels []ARGB, maxColors int) map[ARGB]int {
+	opaque := make([]ARGB, 0, len(pixels))
+	for _, p := range pixels {
+		if p.Alpha() == 0xff {
+			opaque = append(opaque, p)
+		}
+	}
+	return quantizeWSMeans(opaque, quantizeWu(opaque, maxColors), maxColors)
+}
+
+const (
+	wuIndexBits  = 5
+	wuSideLength = 1<<wuIndexBits + 1
+	wuTotalSize  = wuSideLength * wuSideLength * wuSideLength
+)
+
+type wuBox struct {
+	r0, r1, g0, g1, b0, b1, vol int
+}
+
+type wuDirection int
+
+const (
+	wuRed wuDirection = iota
+	wuGreen
+	wuBlue
+)
+
+type wuQuantizer struct {
+	weights  []int
+	momentsR []int
+	momentsG []int
+	momentsB []int
+	moments  []float64
+	cubes    []wuBox
+}
+
+func wuIndex(r, g, b int) int {
+	return r<<(wuIndexBits*2) + r<<(wuIndexBits+1) + r + g<<wuIndexBits + g + b
+}
+
+func quantizeWu(pixels []ARGB, maxColors int) []ARGB {
+	q := &wuQuantizer{
+		weights:  make([]int, wuTotalSize),
+		momentsR: make([]int, wuTotalSize),
+		momentsG: make([]int, wuTotalSize),
+		momentsB: make([]int, wuTotalSize),
+		moments:  make([]float64, wuTotalSize),
+	}
+
+	counts := make(map[ARGB]int)
+	for _, p := range pixels {
+		counts[p]++
+	}
+	const bitsToRemove = 8 - wuIndexBits
+	for p, count := range counts {
+		r, g, b := int(p.Red()), int(p.Green()), int(p.Blue())
+		i := wuIndex(r>>bitsToRemove+1, g>>bitsToRemove+1, b>>bitsToRemove+1)
+		q.weights[i] += count
+		q.momentsR[i] += count * r
+		q.momentsG[i] += count * g
+		q.momentsB[i] += count * b
+		q.moments[i] += float64(count * (r*r + g*g + b*b))
+	}
+
+	q.computeMoments()
+	n := q.createBoxes(maxColors)
+
+	var colors []ARGB
+	for _, cube := range q.cubes[:n] {
+		weight := q.volume(cube, q.weights)
+		if weight <= 0 {
+			continue
+		}
+		r := math.Round(float64(q.volume(cube, q.momentsR)) / float64(weight))
+		g := math.Round(float64(q.volume(cube, q.momentsG)) / float64(weight))
+		b := math.Round(float64(q.volume(cube, q.momentsB)) / float64(weight))
+		colors = append(colors, ARGBFromRGB(uint8(r), uint8(g), uint8(b)))
+	}
+	return colors
+}
+
+func (q *wuQuantizer) computeMoments() {
+	for r := 1; r < wuSideLength; r++ {
+		var area, areaR, areaG, areaB [wuSideLength]int
+		var area2 [wuSideLength]float64
+		for g := 1; g < wuSideLength; g++ {
+			var line, lineR, lineG, lineB int
+			var line2 float64
+			for b := 1; b < wuSideLength; b++ {
+				i := wuIndex(r, g, b)
+				line += q.weights[i]
+				lineR += q.momentsR[i]
+				lineG += q.momentsG[i]
+				lineB += q.momentsB[i]
+				line2 += q.moments[i]
+
+				area[b] += line
+				areaR[b] += lineR
+				areaG[b] += lineG
+				areaB[b] += lineB
+				area2[b] += line2
+
+				prev := wuIndex(r-1, g, b)
+				q.weights[i] = q.weights[prev] + area[b]
+				q.momentsR[i] = q.momentsR[prev] + areaR[b]
+				q.momentsG[i] = q.momentsG[prev] + areaG[b]
+				q.momentsB[i] = q.momentsB[prev] + areaB[b]
+				q.moments[i] = q.moments[prev] + area2[b]
+			}
+		}
+	}
+}
+
+func (q *wuQuantizer) createBoxes(maxColors int) int {
+	q.cubes = make([]wuBox, maxColors)
+	variances := make([]float64, maxColors)
+	q.cubes[0] = wuBox{r1: wuSideLength - 1, g1: wuSideLength - 1, b1: wuSideLength - 1}
+
+	generated := maxColors
+	next := 0
+	for i := 1; i < maxColors; i++ {
+		if q.cut(&q.cubes[next], &q.cubes[i]) {
+			variances[next] = 0
+			if q.cubes[next].vol > 1 {
+				variances[next] = q.variance(q.cubes[next])
+			}
+			variances[i] = 0
+			if q.cubes[i].vol > 1 {
+				variances[i] = q.variance(q.cubes[i])
+			}
+		} else {
+			variances[next] = 0
+			i--
+		}
+
+		next = 0
+		temp := variances[0]
+		for j := 1; j <= i; j++ {
+			if variances[j] > temp {
+				temp = variances[j]
+				next = j
+			}
+		}
+		if temp <= 0 {
+			generated = i + 1
+			break
+		}
+	}
+	return generated
+}
+
+func (q *wuQuantizer) variance(c wuBox) float64 {
+	dr := float64(q.volume(c, q.momentsR))
+	dg := float64(q.volume(c, q.momentsG))
+	db := float64(q.volume(c, q.momentsB))
+	m := q.moments
+	xx := m[wuIndex(c.r1, c.g1, c.b1)] - m[wuIndex(c.r1, c.g1, c.b0)] -
+		m[wuIndex(c.r1, c.g0, c.b1)] + m[wuIndex(c.r1, c.g0, c.b0)] -
+		m[wuIndex(c.r0, c.g1, c.b1)] + m[wuIndex(c.r0, c.g1, c.b0)] +
+		m[wuIndex(c.r0, c.g0, c.b1)] - m[wuIndex(c.r0, c.g0, c.b0)]
+	hypotenuse := dr*dr + dg*dg + db*db
+	return xx - hypotenuse/float64(q.volume(c, q.weights))
+}
+
+func (q *wuQuantizer) cut(one, two *wuBox) bool {
+	wholeR := q.volume(*one, q.momentsR)
+	wholeG := q.volume(*one, q.momentsG)
+	wholeB := q.volume(*one, q.momentsB)
+	wholeW := q.volume(*one, q.weights)
+
+	cutR, maxR := q.maximize(*one, wuRed, one.r0+1, one.r1, wholeR, wholeG, wholeB, wholeW)
+	cutG, maxG := q.maximize(*one, wuGreen, one.g0+1, one.g1, wholeR, wholeG, wholeB, wholeW)
+	cutB, maxB := q.maximize(*one, wuBlue, one.b0+1, one.b1, wholeR, wholeG, wholeB, wholeW)
+
+	var direction wuDirection
+	switch {
+	case maxR >= maxG && maxR >= maxB:
+		if cutR < 0 {
+			return false
+		}
+		direction = wuRed
+	case maxG >= maxR && maxG >= maxB:
+		direction = wuGreen
+	default:
+		direction = wuBlue
+	}
+
+	two.r1, two.g1, two.b1 = one.r1, one.g1, one.b1
+	switch direction {
+	case wuRed:
+		one.r1 = cutR
+		two.r0, two.g0, two.b0 = one.r1, one.g0, one.b0
+	case wuGreen:
+		one.g1 = cutG
+		two.r0, two.g0, two.b0 = one.r0, one.g1, one.b0
+	case wuBlue:
+		one.b1 = cutB
+		two.r0, two.g0, two.b0 = one.r0, one.g0, one.b1
+	}
+	one.vol = (one.r1 - one.r0) * (one.g1 - one.g0) * (one.b1 - one.b0)
+	two.vol = (two.r1 - two.r0) * (two.g1 - two.g0) * (two.b1 - two.b0)
+	return true
+}
+
+func (q *wuQuantizer) maximize(c wuBox, d wuDirection, first, last, wholeR, wholeG, wholeB, wholeW int) (int, float64) {
+	bottomR := q.bottom(c, d, q.momentsR)
+	bottomG := q.bottom(c, d, q.momentsG)
+	bottomB := q.bottom(c, d, q.momentsB)
+	bottomW := q.bottom(c, d, q.weights)
+
+	best, cut := 0.0, -1
+	for i := first; i < last; i++ {
+		halfR := bottomR + q.top(c, d, i, q.momentsR)
+		halfG := bottomG + q.top(c, d, i, q.momentsG)
+		halfB := bottomB + q.top(c, d, i, q.momentsB)
+		halfW := bottomW + q.top(c, d, i, q.weights)
+		if halfW == 0 {
+			continue
+		}
+		temp := float64(halfR*halfR+halfG*halfG+halfB*halfB) / float64(halfW)
+
+		halfR, halfG, halfB, halfW = wholeR-halfR, wholeG-halfG, wholeB-halfB, wholeW-halfW
+		if halfW == 0 {
+			continue
+		}
+		temp += float64(halfR*halfR+halfG*halfG+halfB*halfB) / float64(halfW)
+
+		if temp > best {
+			best, cut = temp, i
+		}
+	}
+	return cut, best
+}
+
+func (q *wuQuantizer) volume(c wuBox, m []int) int {
+	return m[wuIndex(c.r1, c.g1, c.b1)] - m[wuIndex(c.r1, c.g1, c.b0)] -
+		m[wuIndex(c.r1, c.g0, c.b1)] + m[wuIndex(c.r1, c.g0, c.b0)] -
+		m[wuIndex(c.r0, c.g1, c.b1)] + m[wuIndex(c.r0, c.g1, c.b0)] +
+		m[wuIndex(c.r0, c.g0, c.b1)] - m[wuIndex(c.r0, c.g0, c.b0)]
+}
+
+func (q *wuQuantizer) bottom(c wuBox, d wuDirection, m []int) int {
+	switch d {
+	case wuRed:
+		return -m[wuIndex(c.r0, c.g1, c.b1)] + m[wuIndex(c.r0, c.g1, c.b0)] +
+			m[wuIndex(c.r0, c.g0, c.b1)] - m[wuIndex(c.r0, c.g0, c.b0)]
+	case wuGreen:
+		return -m[wuIndex(c.r1, c.g0, c.b1)] + m[wuIndex(c.r1, c.g0, c.b0)] +
+			m[wuIndex(c.r0, c.g0, c.b1)] - m[wuIndex(c.r0, c.g0, c.b0)]
+	default:
+		return -m[wuIndex(c.r1, c.g1, c.b0)] + m[wuIndex(c.r1, c.g0, c.b0)] +
+			m[wuIndex(c.r0, c.g1, c.b0)] - m[wuIndex(c.r0, c.g0, c.b0)]
+	}
+}
+
+func (q *wuQuantizer) top(c wuBox, d wuDirection, pos int, m []int) int {
+	switch d {
+	case wuRed:
+		return m[wuIndex(pos, c.g1, c.b1)] - m[wuIndex(pos, c.g1, c.b0)] -
+			m[wuIndex(pos, c.g0, c.b1)] + m[wuIndex(pos, c.g0, c.b0)]
+	case wuGreen:
+		return m[wuIndex(c.r1, pos, c.b1)] - m[wuIndex(c.r1, pos, c.b0)] -
+			m[wuIndex(c.r0, pos, c.b1)] + m[wuIndex(c.r0, pos, c.b0)]
+	default:
+		return m[wuIndex(c.r1, c.g1, pos)] - m[wuIndex(c.r1, c.g0, pos)] -
+			m[wuIndex(c.r0, c.g1, pos)] + m[wuIndex(c.r0, c.g0, pos)]
+	}
+}
+
+const (
+	wsmeansMaxIterations       = 10
+	wsmeansMinMovementDistance = 3.0
+)
+
+type distanceAndIndex struct {
+	distance float64
+	index    int
+}
+
+func labDistance(a, b [3]float64) float64 {
+	dl, da, db := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dl*dl + da*da + db*db
+}
+
+func quantizeWSMeans(pixels []ARGB, startingClusters []ARGB, maxColors int) map[ARGB]int {
+	// fixed seed so the same wallpaper always gives the same scheme
+	random := rand.New(rand.NewSource(0x42688))
+
+	countByPixel := make(map[ARGB]int)
+	var unique []ARGB
+	for _, p := range pixels {
+		if countByPixel[p] == 0 {
+			unique = append(unique, p)
+		}
+		countByPixel[p]++
+	}
+
+	points := make([][3]float64, len(unique))
+	counts := make([]int, len(unique))
+	for i, p := range unique {
+		points[i] = labFromARGB(p)
+		counts[i] = countByPixel[p]
+	}
+
+	clusterCount := min(maxColors, len(points))
+	if len(startingClusters) > 0 {
+		clusterCount = min(clusterCount, len(startingClusters))
+	}
+	if clusterCount == 0 {
+		return map[ARGB]int{}
+	}
+
+	clusters := make([][3]float64, 0, clusterCount)
+	for _, c := range startingClusters[:clusterCount] {
+		clusters = append(clusters, labFromARGB(c))
+	}
+	for len(clusters) < clusterCount {
+		clusters = append(clusters, points[random.Intn(len(points))])
+	}
+
+	clusterIndices := make([]int, len(points))
+	for i := range clusterIndices {
+		clusterIndices[i] = random.Intn(clusterCount)
+	}
+
+	distanceToIndex := make([][]distanceAndIndex, clusterCount)
+	for i := range distanceToIndex {
+		distanceToIndex[i] = make([]distanceAndIndex, clusterCount)
+	}
+
+	pixelCountSums := make([]int, clusterCount)
+	for iteration := range wsmeansMaxIterations {
+		for i := range clusterCount {
+			for j := i + 1; j < clusterCount; j++ {
+				d := labDistance(clusters[i], clusters[j])
+				distanceToIndex[j][i] = distanceAndIndex{d, i}
+				distanceToIndex[i][j] = distanceAndIndex{d, j}
+			}
+			sort.SliceStable(distanceToIndex[i], func(a, b int) bool {
+				return distanceToIndex[i][a].distance < distanceToIndex[i][b].distance
+			})
+		}
+
+		pointsMoved := 0
+		for i, point := range points {
+			previous := clusterIndices[i]
+			previousDistance := labDistance(point, clusters[previous])
+			minimumDistance := previousDistance
+			newCluster := -1
+			for j := range clusterCount {
+				// triangle inequality: clusters this far away can't be closer
+				if distanceToIndex[previous][j].distance >= 4*previousDistance {
+					continue
+				}
+				if d := labDistance(point, clusters[j]); d < minimumDistance {
+					minimumDistance = d
+					newCluster = j
+				}
+			}
+			if newCluster != -1 && math.Abs(math.Sqrt(minimumDistance)-math.Sqrt(previousDistance)) > wsmeansMinMovementDistance {
+				pointsMoved++
+				clusterIndices[i] = newCluster
+			}
+		}
+		if pointsMoved == 0 && iteration != 0 {
+			break
+		}
+
+		sums := make([][3]float64, clusterCount)
+		clear(pixelCountSums)
+		for i, point := range points {
+			c := clusterIndices[i]
+			pixelCountSums[c] += counts[i]
+			sums[c][0] += point[0] * float64(counts[i])
+			sums[c][1] += point[1] * float64(counts[i])
+			sums[c][2] += point[2] * float64(counts[i])
+		}
+		for i := range clusters {
+			count := float64(pixelCountSums[i])
+			if count == 0 {
+				clusters[i] = [3]float64{}
+				continue
+			}
+			clusters[i] = [3]float64{sums[i][0] / count, sums[i][1] / count, sums[i][2] / count}
+		}
+	}
+
+	result := make(map[ARGB]int)
+	for i, cluster := range clusters {
+		if pixelCountSums[i] == 0 {
+			continue
+		}
+		c := argbFromLab(cluster)
+		if _, ok := result[c]; ok {
+			continue
+		}
+		result[c] = pixelCountSums[i]
+	}
+	return result
+}
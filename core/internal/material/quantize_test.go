@@ -0,0 +1,73 @@
+package material
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantizeWu(t *testing.T) {
+	assert.Equal(t, []ARGB{0xffff0000}, quantizeWu([]ARGB{0xffff0000}, 128))
+
+	colors := quantizeWu([]ARGB{0xffff0000, 0xff00ff00, 0xff0000ff}, 128)
+	assert.ElementsMatch(t, []ARGB{0xffff0000, 0xff00ff00, 0xff0000ff}, colors)
+
+	assert.Len(t, quantizeWu([]ARGB{0xffff0000, 0xff00ff00, 0xff0000ff, 0xffffffff}, 2), 2)
+}
+
+func TestQuantizeCelebi(t *testing.T) {
+	pixels := make([]ARGB, 0, 100)
+	for range 70 {
+		pixels = append(pixels, 0xffff0000)
+	}
+	for range 30 {
+		pixels = append(pixels, 0xff0000ff)
+	}
+	pixels = append(pixels, 0x0000ff00)
+
+	result := QuantizeCelebi(pixels, 128)
+	assert.Equal(t, map[ARGB]int{0xffff0000: 70, 0xff0000ff: 30}, result)
+}
+
+func TestScore(t *testing.T) {
+	assert.Equal(t, []ARGB{FallbackColor}, Score(map[ARGB]int{}, 4))
+
+	// grays are filtered out
+	assert.Equal(t, []ARGB{FallbackColor}, Score(map[ARGB]int{0xff808080: 100, 0xff202020: 50}, 4))
+
+	ranked := Score(map[ARGB]int{0xffff0000: 1, 0xff00ff00: 1, 0xff0000ff: 1}, 4)
+	assert.Equal(t, []ARGB{0xffff0000, 0xff00ff00, 0xff0000ff}, ranked)
+
+	// a dominant hue wins over a slightly more chromatic minority
+	ranked = Score(map[ARGB]int{0xff4285f4: 90, 0xffea4335: 10}, 1)
+	assert.Equal(t, []ARGB{0xff4285f4}, ranked)
+
+	// near-duplicate hues collapse into one choice
+	ranked = Score(map[ARGB]int{0xffff0000: 50, 0xfff00000: 50, 0xff0000ff: 10}, 2)
+	require.Len(t, ranked, 2)
+	assert.Equal(t, ARGB(0xff0000ff), ranked[1])
+}
+
+func TestSourceColorFromImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for y := range 300 {
+		for x := range 400 {
+			c := color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}
+			if x > 100 && x < 300 && y > 50 && y < 250 {
+				c = color.RGBA{R: 0x1e, G: 0x88, B: 0xe5, A: 0xff}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	source := HctFromARGB(SourceColorFromImage(img))
+	want := HctFromARGB(0xff1e88e5)
+	assert.InDelta(t, want.Hue, source.Hue, 5)
+	assert.InDelta(t, want.Chroma, source.Chroma, 5)
+
+	gray := image.NewGray(image.Rect(0, 0, 64, 64))
+	assert.Equal(t, FallbackColor, SourceColorFromImage(gray))
+}
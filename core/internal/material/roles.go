@@ -0,0 +1,267 @@
+package material
+
+// The Material 3 color roles, as in material-color-utilities'
+// MaterialDynamicColors.
+
+var dynamicColors = materialDynamicColors()
+
+func pick[T any](cond bool, a, b T) T {
+	if cond {
+		return a
+	}
+	return b
+}
+
+func materialDynamicColors() []*dynamicColor {
+	primaryP := func(s *Scheme) *TonalPalette { return s.Primary }
+	secondaryP := func(s *Scheme) *TonalPalette { return s.Secondary }
+	tertiaryP := func(s *Scheme) *TonalPalette { return s.Tertiary }
+	errorP := func(s *Scheme) *TonalPalette { return s.Error }
+	neutralP := func(s *Scheme) *TonalPalette { return s.Neutral }
+	neutralVariantP := func(s *Scheme) *TonalPalette { return s.NeutralVariant }
+
+	darkLight := func(dark, light float64) func(*Scheme) float64 {
+		return func(s *Scheme) float64 { return pick(s.IsDark, dark, light) }
+	}
+	fixed := func(tone float64) func(*Scheme) float64 {
+		return func(*Scheme) float64 { return tone }
+	}
+
+	var (
+		background              = &dynamicColor{name: "background"}
+		onBackground            = &dynamicColor{name: "on_background"}
+		surface                 = &dynamicColor{name: "surface"}
+		surfaceDim              = &dynamicColor{name: "surface_dim"}
+		surfaceBright           = &dynamicColor{name: "surface_bright"}
+		surfaceContainerLowest  = &dynamicColor{name: "surface_container_lowest"}
+		surfaceContainerLow     = &dynamicColor{name: "surface_container_low"}
+		surfaceContainer        = &dynamicColor{name: "surface_container"}
+		surfaceContainerHigh    = &dynamicColor{name: "surface_container_high"}
+		surfaceContainerHighest = &dynamicColor{name: "surface_container_highest"}
+		onSurface               = &dynamicColor{name: "on_surface"}
+		surfaceVariant          = &dynamicColor{name: "surface_variant"}
+		onSurfaceVariant        = &dynamicColor{name: "on_surface_variant"}
+		inverseSurface          = &dynamicColor{name: "inverse_surface"}
+		inverseOnSurface        = &dynamicColor{name: "inverse_on_surface"}
+		outline                 = &dynamicColor{name: "outline"}
+		outlineVariant          = &dynamicColor{name: "outline_variant"}
+		shadow                  = &dynamicColor{name: "shadow"}
+		scrim                   = &dynamicColor{name: "scrim"}
+		surfaceTint             = &dynamicColor{name: "surface_tint"}
+
+		primary                = &dynamicColor{name: "primary"}
+		onPrimary              = &dynamicColor{name: "on_primary"}
+		primaryContainer       = &dynamicColor{name: "primary_container"}
+		onPrimaryContainer     = &dynamicColor{name: "on_primary_container"}
+		inversePrimary         = &dynamicColor{name: "inverse_primary"}
+		secondary              = &dynamicColor{name: "secondary"}
+		onSecondary            = &dynamicColor{name: "on_secondary"}
+		secondaryContainer     = &dynamicColor{name: "secondary_container"}
+		onSecondaryContainer   = &dynamicColor{name: "on_secondary_container"}
+		tertiary               = &dynamicColor{name: "tertiary"}
+		onTertiary             = &dynamicColor{name: "on_tertiary"}
+		tertiaryContainer      = &dynamicColor{name: "tertiary_container"}
+		onTertiaryContainer    = &dynamicColor{name: "on_tertiary_container"}
+		errorC                 = &dynamicColor{name: "error"}
+		onError                = &dynamicColor{name: "on_error"}
+		errorContainer         = &dynamicColor{name: "error_container"}
+		onErrorContainer       = &dynamicColor{name: "on_error_container"}
+		primaryFixed           = &dynamicColor{name: "primary_fixed"}
+		primaryFixedDim        = &dynamicColor{name: "primary_fixed_dim"}
+		onPrimaryFixed         = &dynamicColor{name: "on_primary_fixed"}
+		onPrimaryFixedVariant  = &dynamicColor{name: "on_primary_fixed_variant"}
+		secondaryFixed         = &dynamicColor{name: "secondary_fixed"}
+		secondaryFixedDim      = &dynamicColor{name: "secondary_fixed_dim"}
+		onSecondaryFixed       = &dynamicColor{name: "on_secondary_fixed"}
+		onSecondaryFixedVar    = &dynamicColor{name: "on_secondary_fixed_variant"}
+		tertiaryFixed          = &dynamicColor{name: "tertiary_fixed"}
+		tertiaryFixedDim       = &dynamicColor{name: "tertiary_fixed_dim"}
+		onTertiaryFixed        = &dynamicColor{name: "on_tertiary_fixed"}
+		onTertiaryFixedVariant = &dynamicColor{name: "on_tertiary_fixed_variant"}
+	)
+
+	highestSurface := func(s *Scheme) *dynamicColor { return pick(s.IsDark, surfaceBright, surfaceDim) }
+	on := func(dc *dynamicColor) func(*Scheme) *dynamicColor {
+		return func(*Scheme) *dynamicColor { return dc }
+	}
+	pair := func(a, b *dynamicColor, polarity tonePolarity, stayTogether bool) func(*Scheme) toneDeltaPair {
+		return func(*Scheme) toneDeltaPair {
+			return toneDeltaPair{roleA: a, roleB: b, delta: 10, polarity: polarity, stayTogether: stayTogether}
+		}
+	}
+
+	textCurve := &contrastCurve{4.5, 7, 11, 21}
+	accentCurve := &contrastCurve{3, 4.5, 7, 7}
+	containerCurve := &contrastCurve{1, 1, 3, 4.5}
+	variantTextCurve := &contrastCurve{3, 4.5, 7, 11}
+
+	surfaceRole := func(dc *dynamicColor, p func(*Scheme) *TonalPalette, tone func(*Scheme) float64) {
+		dc.palette, dc.tone, dc.isBackground = p, tone, true
+	}
+	surfaceRole(background, neutralP, darkLight(6, 98))
+	surfaceRole(surface, neutralP, darkLight(6, 98))
+	surfaceRole(surfaceDim, neutralP, darkLight(6, 87))
+	surfaceRole(surfaceBright, neutralP, darkLight(24, 98))
+	surfaceRole(surfaceContainerLowest, neutralP, darkLight(4, 100))
+	surfaceRole(surfaceContainerLow, neutralP, darkLight(10, 96))
+	surfaceRole(surfaceContainer, neutralP, darkLight(12, 94))
+	surfaceRole(surfaceContainerHigh, neutralP, darkLight(17, 92))
+	surfaceRole(surfaceContainerHighest, neutralP, darkLight(22, 90))
+	surfaceRole(surfaceVariant, neutralVariantP, darkLight(30, 90))
+	surfaceRole(surfaceTint, primaryP, darkLight(80, 40))
+
+	*onBackground = dynamicColor{name: onBackground.name, palette: neutralP, tone: darkLight(90, 10), background: on(background), contrastCurve: &contrastCurve{3, 3, 4.5, 7}}
+	*onSurface = dynamicColor{name: onSurface.name, palette: neutralP, tone: darkLight(90, 10), background: highestSurface, contrastCurve: textCurve}
+	*onSurfaceVariant = dynamicColor{name: onSurfaceVariant.name, palette: neutralVariantP, tone: darkLight(80, 30), background: highestSurface, contrastCurve: variantTextCurve}
+	*inverseSurface = dynamicColor{name: inverseSurface.name, palette: neutralP, tone: darkLight(90, 20)}
+	*inverseOnSurface = dynamicColor{name: inverseOnSurface.name, palette: neutralP, tone: darkLight(20, 95), background: on(inverseSurface), contrastCurve: textCurve}
+	*outline = dynamicColor{name: outline.name, palette: neutralVariantP, tone: darkLight(60, 50), background: highestSurface, contrastCurve: &contrastCurve{1.5, 3, 4.5, 7}}
+	*outlineVariant = dynamicColor{name: outlineVariant.name, palette: neutralVariantP, tone: darkLight(30, 80), background: highestSurface, contrastCurve: containerCurve}
+	*shadow = dynamicColor{name: shadow.name, palette: neutralP, tone: fixed(0)}
+	*scrim = dynamicColor{name: scrim.name, palette: neutralP, tone: fixed(0)}
+
+	accent := func(dc, container *dynamicColor, p func(*Scheme) *TonalPalette, tone func(*Scheme) float64) {
+		*dc = dynamicColor{
+			name: dc.name, palette: p, tone: tone, isBackground: true,
+			background: highestSurface, contrastCurve: accentCurve,
+			toneDeltaPair: pair(container, dc, polarityNearer, false),
+		}
+	}
+	container := func(dc, accentRole *dynamicColor, p func(*Scheme) *TonalPalette, tone func(*Scheme) float64) {
+		*dc = dynamicColor{
+			name: dc.name, palette: p, tone: tone, isBackground: true,
+			background: highestSurface, contrastCurve: containerCurve,
+			toneDeltaPair: pair(dc, accentRole, polarityNearer, false),
+		}
+	}
+	text := func(dc, bg *dynamicColor, p func(*Scheme) *TonalPalette, tone func(*Scheme) float64) {
+		*dc = dynamicColor{name: dc.name, palette: p, tone: tone, background: on(bg), contrastCurve: textCurve}
+	}
+
+	accent(primary, primaryContainer, primaryP, func(s *Scheme) float64 {
+		if s.isMonochrome() {
+			return pick(s.IsDark, 100.0, 0.0)
+		}
+		return pick(s.IsDark, 80.0, 40.0)
+	})
+	text(onPrimary, primary, primaryP, func(s *Scheme) float64 {
+		if s.isMonochrome() {
+			return pick(s.IsDark, 10.0, 90.0)
+		}
+		return pick(s.IsDark, 20.0, 100.0)
+	})
+	container(primaryContainer, primary, primaryP, func(s *Scheme) float64 {
+		switch {
+		case s.isFidelity():
+			return s.Source.Tone
+		case s.isMonochrome():
+			return pick(s.IsDark, 85.0, 25.0)
+		}
+		return pick(s.IsDark, 30.0, 90.0)
+	})
+	text(onPrimaryContainer, primaryContainer, primaryP, func(s *Scheme) float64 {
+		switch {
+		case s.isFidelity():
+			return foregroundTone(primaryContainer.tone(s), 4.5)
+		case s.isMonochrome():
+			return pick(s.IsDark, 0.0, 100.0)
+		}
+		return pick(s.IsDark, 90.0, 10.0)
+	})
+	*inversePrimary = dynamicColor{name: inversePrimary.name, palette: primaryP, tone: darkLight(40, 80), background: on(inverseSurface), contrastCurve: accentCurve}
+
+	accent(secondary, secondaryContainer, secondaryP, darkLight(80, 40))
+	text(onSecondary, secondary, secondaryP, func(s *Scheme) float64 {
+		if s.isMonochrome() {
+			return pick(s.IsDark, 10.0, 100.0)
+		}
+		return pick(s.IsDark, 20.0, 100.0)
+	})
+	container(secondaryContainer, secondary, secondaryP, func(s *Scheme) float64 {
+		initial := pick(s.IsDark, 30.0, 90.0)
+		switch {
+		case s.isMonochrome():
+			return pick(s.IsDark, 30.0, 85.0)
+		case !s.isFidelity():
+			return initial
+		}
+		return findDesiredChromaByTone(s.Secondary.Hue, s.Secondary.Chroma, initial, !s.IsDark)
+	})
+	text(onSecondaryContainer, secondaryContainer, secondaryP, func(s *Scheme) float64 {
+		if !s.isFidelity() {
+			return pick(s.IsDark, 90.0, 10.0)
+		}
+		return foregroundTone(secondaryContainer.tone(s), 4.5)
+	})
+
+	accent(tertiary, tertiaryContainer, tertiaryP, func(s *Scheme) float64 {
+		if s.isMonochrome() {
+			return pick(s.IsDark, 90.0, 25.0)
+		}
+		return pick(s.IsDark, 80.0, 40.0)
+	})
+	text(onTertiary, tertiary, tertiaryP, func(s *Scheme) float64 {
+		if s.isMonochrome() {
+			return pick(s.IsDark, 10.0, 90.0)
+		}
+		return pick(s.IsDark, 20.0, 100.0)
+	})
+	container(tertiaryContainer, tertiary, tertiaryP, func(s *Scheme) float64 {
+		switch {
+		case s.isMonochrome():
+			return pick(s.IsDark, 60.0, 49.0)
+		case !s.isFidelity():
+			return pick(s.IsDark, 30.0, 90.0)
+		}
+		return fixIfDisliked(s.Tertiary.Hct(s.Source.Tone)).Tone
+	})
+	text(onTertiaryContainer, tertiaryContainer, tertiaryP, func(s *Scheme) float64 {
+		switch {
+		case s.isMonochrome():
+			return pick(s.IsDark, 0.0, 100.0)
+		case !s.isFidelity():
+			return pick(s.IsDark, 90.0, 10.0)
+		}
+		return foregroundTone(tertiaryContainer.tone(s), 4.5)
+	})
+
+	accent(errorC, errorContainer, errorP, darkLight(80, 40))
+	text(onError, errorC, errorP, darkLight(20, 100))
+	container(errorContainer, errorC, errorP, darkLight(30, 90))
+	text(onErrorContainer, errorContainer, errorP, darkLight(90, 10))
+
+	fixedPair := func(
+		fixedRole, dimRole, onRole, onVariantRole *dynamicColor,
+		p func(*Scheme) *TonalPalette,
+		fixedTone, dimTone, onTone, onVariantTone [2]float64,
+	) {
+		mono := func(t [2]float64) func(*Scheme) float64 {
+			return func(s *Scheme) float64 { return pick(s.isMonochrome(), t[0], t[1]) }
+		}
+		tdp := pair(fixedRole, dimRole, polarityLighter, true)
+		*fixedRole = dynamicColor{name: fixedRole.name, palette: p, tone: mono(fixedTone), isBackground: true, background: highestSurface, contrastCurve: containerCurve, toneDeltaPair: tdp}
+		*dimRole = dynamicColor{name: dimRole.name, palette: p, tone: mono(dimTone), isBackground: true, background: highestSurface, contrastCurve: containerCurve, toneDeltaPair: tdp}
+		*onRole = dynamicColor{name: onRole.name, palette: p, tone: mono(onTone), background: on(dimRole), secondBackground: on(fixedRole), contrastCurve: textCurve}
+		*onVariantRole = dynamicColor{name: onVariantRole.name, palette: p, tone: mono(onVariantTone), background: on(dimRole), secondBackground: on(fixedRole), contrastCurve: variantTextCurve}
+	}
+	fixedPair(primaryFixed, primaryFixedDim, onPrimaryFixed, onPrimaryFixedVariant, primaryP,
+		[2]float64{40, 90}, [2]float64{30, 80}, [2]float64{100, 10}, [2]float64{90, 30})
+	fixedPair(secondaryFixed, secondaryFixedDim, onSecondaryFixed, onSecondaryFixedVar, secondaryP,
+		[2]float64{80, 90}, [2]float64{70, 80}, [2]float64{10, 10}, [2]float64{25, 30})
+	fixedPair(tertiaryFixed, tertiaryFixedDim, onTertiaryFixed, onTertiaryFixedVariant, tertiaryP,
+		[2]float64{40, 90}, [2]float64{30, 80}, [2]float64{100, 10}, [2]float64{90, 30})
+
+	return []*dynamicColor{
+		background, onBackground, surface, surfaceDim, surfaceBright,
+		surfaceContainerLowest, surfaceContainerLow, surfaceContainer, surfaceContainerHigh, surfaceContainerHighest,
+		onSurface, surfaceVariant, onSurfaceVariant, inverseSurface, inverseOnSurface,
+		outline, outlineVariant, shadow, scrim, surfaceTint,
+		primary, onPrimary, primaryContainer, onPrimaryContainer, inversePrimary,
+		secondary, onSecondary, secondaryContainer, onSecondaryContainer,
+		tertiary, onTertiary, tertiaryContainer, onTertiaryContainer,
+		errorC, onError, errorContainer, onErrorContainer,
+		primaryFixed, primaryFixedDim, onPrimaryFixed, onPrimaryFixedVariant,
+		secondaryFixed, secondaryFixedDim, onSecondaryFixed, onSecondaryFixedVar,
+		tertiaryFixed, tertiaryFixedDim, onTertiaryFixed, onTertiaryFixedVariant,
+	}
+}
@@ -0,0 +1,159 @@
+package material
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Variant is a Material 3 scheme style. The names match matugen's
+// --type values.
+type Variant string
+
+const (
+	VariantTonalSpot  Variant = "scheme-tonal-spot"
+	VariantContent    Variant = "scheme-content"
+	VariantExpressive Variant = "scheme-expressive"
+	VariantFidelity   Variant = "scheme-fidelity"
+	VariantFruitSalad Variant = "scheme-fruit-salad"
+	VariantMonochrome Variant = "scheme-monochrome"
+	VariantNeutral    Variant = "scheme-neutral"
+	VariantRainbow    Variant = "scheme-rainbow"
+	VariantVibrant    Variant = "scheme-vibrant"
+)
+
+var Variants = []Variant{
+	VariantTonalSpot, VariantContent, VariantExpressive, VariantFidelity, VariantFruitSalad,
+	VariantMonochrome, VariantNeutral, VariantRainbow, VariantVibrant,
+}
+
+// ParseVariant accepts matugen's names with or without the scheme- prefix.
+func ParseVariant(s string) (Variant, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if !strings.HasPrefix(name, "scheme-") {
+		name = "scheme-" + name
+	}
+	for _, v := range Variants {
+		if string(v) == name {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("unknown scheme type %q", s)
+}
+
+// Scheme is a source color resolved into the palettes of one variant and
+// mode.
+type Scheme struct {
+	Source        Hct
+	Variant       Variant
+	IsDark        bool
+	ContrastLevel float64
+
+	Primary        *TonalPalette
+	Secondary      *TonalPalette
+	Tertiary       *TonalPalette
+	Neutral        *TonalPalette
+	NeutralVariant *TonalPalette
+	Error          *TonalPalette
+}
+
+func NewScheme(source ARGB, variant Variant, isDark bool, contrastLevel float64) *Scheme {
+	src := HctFromARGB(source)
+	s := &Scheme{
+		Source:        src,
+		Variant:       variant,
+		IsDark:        isDark,
+		ContrastLevel: contrastLevel,
+		Error:         NewTonalPalette(25.0, 84.0),
+	}
+	hue, chroma := src.Hue, src.Chroma
+
+	switch variant {
+	case VariantContent, VariantFidelity:
+		s.Primary = NewTonalPalette(hue, chroma)
+		s.Secondary = NewTonalPalette(hue, math.Max(chroma-32.0, chroma*0.5))
+		tc := newTemperatureCache(src)
+		if variant == VariantContent {
+			s.Tertiary = TonalPaletteFromHct(fixIfDisliked(tc.analogous(3, 6)[2]))
+		} else {
+			s.Tertiary = TonalPaletteFromHct(fixIfDisliked(tc.complement()))
+		}
+		s.Neutral = NewTonalPalette(hue, chroma/8.0)
+		s.NeutralVariant = NewTonalPalette(hue, chroma/8.0+4.0)
+	case VariantExpressive:
+		hues := []float64{0, 21, 51, 121, 151, 191, 271, 321, 360}
+		s.Primary = NewTonalPalette(sanitizeDegrees(hue+240.0), 40.0)
+		s.Secondary = NewTonalPalette(rotatedHue(hue, hues, []float64{45, 95, 45, 20, 45, 90, 45, 45, 45}), 24.0)
+		s.Tertiary = NewTonalPalette(rotatedHue(hue, hues, []float64{120, 120, 20, 45, 20, 15, 20, 120, 120}), 32.0)
+		s.Neutral = NewTonalPalette(sanitizeDegrees(hue+15.0), 8.0)
+		s.NeutralVariant = NewTonalPalette(sanitizeDegrees(hue+15.0), 12.0)
+	case VariantFruitSalad:
+		s.Primary = NewTonalPalette(sanitizeDegrees(hue-50.0), 48.0)
+		s.Secondary = NewTonalPalette(sanitizeDegrees(hue-50.0), 36.0)
+		s.Tertiary = NewTonalPalette(hue, 36.0)
+		s.Neutral = NewTonalPalette(hue, 10.0)
+		s.NeutralVariant = NewTonalPalette(hue, 16.0)
+	case VariantMonochrome:
+		s.Primary = NewTonalPalette(hue, 0)
+		s.Secondary = NewTonalPalette(hue, 0)
+		s.Tertiary = NewTonalPalette(hue, 0)
+		s.Neutral = NewTonalPalette(hue, 0)
+		s.NeutralVariant = NewTonalPalette(hue, 0)
+	case VariantNeutral:
+		s.Primary = NewTonalPalette(hue, 12.0)
+		s.Secondary = NewTonalPalette(hue, 8.0)
+		s.Tertiary = NewTonalPalette(sanitizeDegrees(hue+60.0), 16.0)
+		s.Neutral = NewTonalPalette(hue, 2.0)
+		s.NeutralVariant = NewTonalPalette(hue, 2.0)
+	case VariantRainbow:
+		s.Primary = NewTonalPalette(hue, 48.0)
+		s.Secondary = NewTonalPalette(hue, 16.0)
+		s.Tertiary = NewTonalPalette(sanitizeDegrees(hue+60.0), 24.0)
+		s.Neutral = NewTonalPalette(hue, 0)
+		s.NeutralVariant = NewTonalPalette(hue, 0)
+	case VariantVibrant:
+		hues := []float64{0, 41, 61, 101, 131, 181, 251, 301, 360}
+		s.Primary = NewTonalPalette(hue, 200.0)
+		s.Secondary = NewTonalPalette(rotatedHue(hue, hues, []float64{18, 15, 10, 12, 15, 18, 15, 12, 12}), 24.0)
+		s.Tertiary = NewTonalPalette(rotatedHue(hue, hues, []float64{35, 30, 20, 25, 30, 35, 30, 25, 25}), 32.0)
+		s.Neutral = NewTonalPalette(hue, 10.0)
+		s.NeutralVariant = NewTonalPalette(hue, 12.0)
+	default:
+		s.Variant = VariantTonalSpot
+		s.Primary = NewTonalPalette(hue, 36.0)
+		s.Secondary = NewTonalPalette(hue, 16.0)
+		s.Tertiary = NewTonalPalette(sanitizeDegrees(hue+60.0), 24.0)
+		s.Neutral = NewTonalPalette(hue, 6.0)
+		s.NeutralVariant = NewTonalPalette(hue, 8.0)
+	}
+	return s
+}
+
+// rotatedHue rotates the source hue by the amount given for the hue range
+// it falls in.
+func rotatedHue(sourceHue float64, hues, rotations []float64) float64 {
+	for i := 0; i+1 < len(hues); i++ {
+		if hues[i] < sourceHue && sourceHue < hues[i+1] {
+			return sanitizeDegrees(sourceHue + rotations[i])
+		}
+	}
+	return sourceHue
+}
+
+func (s *Scheme) isFidelity() bool {
+	return s.Variant == VariantFidelity || s.Variant == VariantContent
+}
+
+func (s *Scheme) isMonochrome() bool {
+	return s.Variant == VariantMonochrome
+}
+
+// Colors resolves every color role, keyed by matugen's token names.
+func (s *Scheme) Colors() map[string]ARGB {
+	colors := make(map[string]ARGB, len(dynamicColors)+1)
+	for _, dc := range dynamicColors {
+		colors[dc.name] = dc.argb(s)
+	}
+	colors["source_color"] = s.Source.ARGB()
+	return colors
+}
@@ -0,0 +1,73 @@
+package material
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemeTonalSpot(t *testing.T) {
+	light := NewScheme(0xff0000ff, VariantTonalSpot, false, 0).Colors()
+	assert.Equal(t, "#555992", light["primary"].Hex())
+	assert.Equal(t, "#ffffff", light["on_primary"].Hex())
+	assert.Equal(t, "#e0e0ff", light["primary_container"].Hex())
+	assert.Equal(t, "#0000ff", light["source_color"].Hex())
+
+	dark := NewScheme(0xff0000ff, VariantTonalSpot, true, 0).Colors()
+	assert.Equal(t, "#bec2ff", dark["primary"].Hex())
+	assert.Equal(t, "#3e4278", dark["primary_container"].Hex())
+}
+
+func TestSchemeContent(t *testing.T) {
+	light := NewScheme(0xff0000ff, VariantContent, false, 0).Colors()
+	assert.Equal(t, "#0001bb", light["primary"].Hex())
+	assert.Equal(t, "#0000ff", light["primary_container"].Hex())
+}
+
+func TestSchemeMonochrome(t *testing.T) {
+	for _, dark := range []bool{false, true} {
+		for name, c := range NewScheme(0xff0000ff, VariantMonochrome, dark, 0).Colors() {
+			if name == "source_color" || name == "error" || name == "on_error" ||
+				name == "error_container" || name == "on_error_container" {
+				continue
+			}
+			assert.True(t, c.Red() == c.Green() && c.Green() == c.Blue(), "%s %s is not gray", name, c.Hex())
+		}
+	}
+}
+
+func TestSchemeContrast(t *testing.T) {
+	pairs := [][2]string{
+		{"on_primary", "primary"},
+		{"on_primary_container", "primary_container"},
+		{"on_secondary", "secondary"},
+		{"on_tertiary_container", "tertiary_container"},
+		{"on_error", "error"},
+		{"on_surface", "surface"},
+		{"on_primary_fixed", "primary_fixed"},
+	}
+	for _, v := range Variants {
+		for _, dark := range []bool{false, true} {
+			colors := NewScheme(0xff6750a4, v, dark, 0).Colors()
+			require.Len(t, colors, 50)
+			for _, p := range pairs {
+				ratio := ratioOfTones(LstarFromARGB(colors[p[0]]), LstarFromARGB(colors[p[1]]))
+				assert.GreaterOrEqual(t, ratio, 4.4, "%s dark=%v %s on %s", v, dark, p[0], p[1])
+			}
+		}
+	}
+}
+
+func TestParseVariant(t *testing.T) {
+	v, err := ParseVariant("scheme-fruit-salad")
+	require.NoError(t, err)
+	assert.Equal(t, VariantFruitSalad, v)
+
+	v, err = ParseVariant("Expressive")
+	require.NoError(t, err)
+	assert.Equal(t, VariantExpressive, v)
+
+	_, err = ParseVariant("scheme-nope")
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package material
+
+import (
+	"math"
+	"sort"
+)
+
+// FallbackColor is Google blue, used when an image has no suitable color.
+const FallbackColor ARGB = 0xff4285f4
+
+const (
+	scoreTargetChroma            = 48.0
+	scoreWeightProportion        = 0.7
+	scoreWeightChromaAbove       = 0.3
+	scoreWeightChromaBelow       = 0.1
+	scoreCutoffChroma            = 5.0
+	scoreCutoffExcitedProportion = 0.01
+)
+
+// Score ranks quantized colors as theme source colors. Colors win by
+// chroma and by how much of the image their hue region covers; the result
+// holds up to desired colors with distinct hues, best first.
+func Score(colorsToPopulation map[ARGB]int, desired int) []ARGB {
+	argbs := make([]ARGB, 0, len(colorsToPopulation))
+	for c := range colorsToPopulation {
+		argbs = append(argbs, c)
+	}
+	sort.Slice(argbs, func(i, j int) bool { return argbs[i] < argbs[j] })
+
+	var huePopulation [360]float64
+	populationSum := 0.0
+	hcts := make([]Hct, len(argbs))
+	for i, c := range argbs {
+		hcts[i] = HctFromARGB(c)
+		population := float64(colorsToPopulation[c])
+		huePopulation[int(math.Floor(hcts[i].Hue))%360] += population
+		populationSum += population
+	}
+
+	var hueExcitedProportions [360]float64
+	for hue := range 360 {
+		proportion := huePopulation[hue] / populationSum
+		for i := hue - 14; i < hue+16; i++ {
+			hueExcitedProportions[sanitizeDegreesInt(i)] += proportion
+		}
+	}
+
+	type scored struct {
+		hct   Hct
+		score float64
+	}
+	var candidates []scored
+	for _, h := range hcts {
+		proportion := hueExcitedProportions[sanitizeDegreesInt(int(math.Round(h.Hue)))]
+		if h.Chroma < scoreCutoffChroma || proportion <= scoreCutoffExcitedProportion {
+			continue
+		}
+		chromaWeight := scoreWeightChromaAbove
+		if h.Chroma < scoreTargetChroma {
+			chromaWeight = scoreWeightChromaBelow
+		}
+		score := proportion*100.0*scoreWeightProportion + (h.Chroma-scoreTargetChroma)*chromaWeight
+		candidates = append(candidates, scored{h, score})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	// pick distinct hues, relaxing the minimum distance until enough are found
+	var chosen []Hct
+	for minDistance := 90; minDistance >= 15; minDistance-- {
+		chosen = chosen[:0]
+		for _, c := range candidates {
+			duplicate := false
+			for _, ch := range chosen {
+				if differenceDegrees(c.hct.Hue, ch.Hue) < float64(minDistance) {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				chosen = append(chosen, c.hct)
+			}
+			if len(chosen) >= desired {
+				break
+			}
+		}
+		if len(chosen) >= desired {
+			break
+		}
+	}
+
+	if len(chosen) == 0 {
+		return []ARGB{FallbackColor}
+	}
+	result := make([]ARGB, len(chosen))
+	for i, h := range chosen {
+		result[i] = h.ARGB()
+	}
+	return result
+}
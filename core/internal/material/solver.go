@@ -0,0 +1,268 @@
+package material
+
+import "math"
+
+// The solver finds the sRGB color for an HCT triple: first by solving CAM16
+// directly for J, and when that lands out of gamut by bisecting along the
+// constant-Y plane of the RGB cube for the most chromatic color of that hue.
+
+var (
+	yFromLinrgb              = [3]float64{0.2126, 0.7152, 0.0722}
+	scaledDiscountFromLinrgb [3][3]float64
+	linrgbFromScaledDiscount [3][3]float64
+	criticalPlanes           [255]float64
+	tInnerCoeff              float64
+)
+
+func init() {
+	vc := defaultViewing
+	m16 := [3][3]float64{
+		{0.401288, 0.650173, -0.051461},
+		{-0.250268, 1.204414, 0.045854},
+		{-0.002079, 0.048952, 0.953127},
+	}
+	for i := range 3 {
+		for j := range 3 {
+			var sum float64
+			for k := range 3 {
+				sum += m16[i][k] * srgbToXYZ[k][j]
+			}
+			scaledDiscountFromLinrgb[i][j] = sum * vc.rgbD[i] * vc.fl / 100.0
+		}
+	}
+	linrgbFromScaledDiscount = invert3(scaledDiscountFromLinrgb)
+
+	for i := range criticalPlanes {
+		normalized := (float64(i) + 0.5) / 255.0
+		if normalized <= 0.040449936 {
+			criticalPlanes[i] = normalized / 12.92 * 100.0
+		} else {
+			criticalPlanes[i] = math.Pow((normalized+0.055)/1.055, 2.4) * 100.0
+		}
+	}
+
+	tInnerCoeff = 1.0 / math.Pow(1.64-math.Pow(0.29, vc.n), 0.73)
+}
+
+func invert3(m [3][3]float64) [3][3]float64 {
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	return [3][3]float64{
+		{
+			(m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det,
+			(m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det,
+			(m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det,
+		},
+		{
+			(m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det,
+			(m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det,
+			(m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det,
+		},
+		{
+			(m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det,
+			(m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det,
+			(m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det,
+		},
+	}
+}
+
+func solveToARGB(hueDegrees, chroma, lstar float64) ARGB {
+	if chroma < 0.0001 || lstar < 0.0001 || lstar > 99.9999 {
+		return argbFromLstar(lstar)
+	}
+	hueRadians := sanitizeDegrees(hueDegrees) / 180.0 * math.Pi
+	y := yFromLstar(lstar)
+	if exact := findResultByJ(hueRadians, chroma, y); exact != 0 {
+		return exact
+	}
+	return argbFromLinrgb(bisectToLimit(y, hueRadians))
+}
+
+func findResultByJ(hueRadians, chroma, y float64) ARGB {
+	vc := defaultViewing
+	j := math.Sqrt(y) * 11.0
+
+	eHue := 0.25 * (math.Cos(hueRadians+2.0) + 3.8)
+	p1 := eHue * (50000.0 / 13.0) * vc.nc * vc.ncb
+	hSin, hCos := math.Sin(hueRadians), math.Cos(hueRadians)
+
+	for iteration := range 5 {
+		jNormalized := j / 100.0
+		alpha := 0.0
+		if chroma != 0 && j != 0 {
+			alpha = chroma / math.Sqrt(jNormalized)
+		}
+		t := math.Pow(alpha*tInnerCoeff, 1.0/0.9)
+		ac := vc.aw * math.Pow(jNormalized, 1.0/vc.c/vc.z)
+		p2 := ac / vc.nbb
+		gamma := 23.0 * (p2 + 0.305) * t / (23.0*p1 + 11*t*hCos + 108.0*t*hSin)
+		a, b := gamma*hCos, gamma*hSin
+		rA := (460.0*p2 + 451.0*a + 288.0*b) / 1403.0
+		gA := (460.0*p2 - 891.0*a - 261.0*b) / 1403.0
+		bA := (460.0*p2 - 220.0*a - 6300.0*b) / 1403.0
+
+		linrgb := matrixMultiply([3]float64{
+			inverseChromaticAdaptation(rA),
+			inverseChromaticAdaptation(gA),
+			inverseChromaticAdaptation(bA),
+		}, linrgbFromScaledDiscount)
+		if linrgb[0] < 0 || linrgb[1] < 0 || linrgb[2] < 0 {
+			return 0
+		}
+		fnj := yFromLinrgb[0]*linrgb[0] + yFromLinrgb[1]*linrgb[1] + yFromLinrgb[2]*linrgb[2]
+		if fnj <= 0 {
+			return 0
+		}
+		if iteration == 4 || math.Abs(fnj-y) < 0.002 {
+			if linrgb[0] > 100.01 || linrgb[1] > 100.01 || linrgb[2] > 100.01 {
+				return 0
+			}
+			return argbFromLinrgb(linrgb)
+		}
+		// Newton step, using 2*fn(j)/j as the approximate derivative
+		j -= (fnj - y) * j / (2 * fnj)
+	}
+	return 0
+}
+
+func hueOf(linrgb [3]float64) float64 {
+	sd := matrixMultiply(linrgb, scaledDiscountFromLinrgb)
+	rA, gA, bA := chromaticAdaptation(sd[0]), chromaticAdaptation(sd[1]), chromaticAdaptation(sd[2])
+	a := (11.0*rA + -12.0*gA + bA) / 11.0
+	b := (rA + gA - 2.0*bA) / 9.0
+	return math.Atan2(b, a)
+}
+
+func sanitizeRadians(angle float64) float64 {
+	return math.Mod(angle+math.Pi*8, math.Pi*2)
+}
+
+func areInCyclicOrder(a, b, c float64) bool {
+	return sanitizeRadians(b-a) < sanitizeRadians(c-a)
+}
+
+func setCoordinate(source [3]float64, coordinate float64, target [3]float64, axis int) [3]float64 {
+	t := (coordinate - source[axis]) / (target[axis] - source[axis])
+	return [3]float64{
+		source[0] + (target[0]-source[0])*t,
+		source[1] + (target[1]-source[1])*t,
+		source[2] + (target[2]-source[2])*t,
+	}
+}
+
+func isBounded(x float64) bool {
+	return 0.0 <= x && x <= 100.0
+}
+
+// nthVertex returns the nth of the 12 possible intersections of the plane
+// of constant Y with the edges of the RGB cube, or -1s when out of bounds.
+func nthVertex(y float64, n int) [3]float64 {
+	kR, kG, kB := yFromLinrgb[0], yFromLinrgb[1], yFromLinrgb[2]
+	coordA := 100.0
+	if n%4 <= 1 {
+		coordA = 0.0
+	}
+	coordB := 100.0
+	if n%2 == 0 {
+		coordB = 0.0
+	}
+	none := [3]float64{-1, -1, -1}
+	switch {
+	case n < 4:
+		g, b := coordA, coordB
+		r := (y - g*kG - b*kB) / kR
+		if isBounded(r) {
+			return [3]float64{r, g, b}
+		}
+	case n < 8:
+		b, r := coordA, coordB
+		g := (y - r*kR - b*kB) / kG
+		if isBounded(g) {
+			return [3]float64{r, g, b}
+		}
+	default:
+		r, g := coordA, coordB
+		b := (y - r*kR - g*kG) / kB
+		if isBounded(b) {
+			return [3]float64{r, g, b}
+		}
+	}
+	return none
+}
+
+func bisectToSegment(y, targetHue float64) ([3]float64, [3]float64) {
+	left := [3]float64{-1, -1, -1}
+	right := left
+	var leftHue, rightHue float64
+	initialized, uncut := false, true
+	for n := range 12 {
+		mid := nthVertex(y, n)
+		if mid[0] < 0 {
+			continue
+		}
+		midHue := hueOf(mid)
+		if !initialized {
+			left, right = mid, mid
+			leftHue, rightHue = midHue, midHue
+			initialized = true
+			continue
+		}
+		if uncut || areInCyclicOrder(leftHue, midHue, rightHue) {
+			uncut = false
+			if areInCyclicOrder(leftHue, targetHue, midHue) {
+				right, rightHue = mid, midHue
+			} else {
+				left, leftHue = mid, midHue
+			}
+		}
+	}
+	return left, right
+}
+
+func trueDelinearized(component float64) float64 {
+	normalized := component / 100.0
+	if normalized <= 0.0031308 {
+		return normalized * 12.92 * 255.0
+	}
+	return (1.055*math.Pow(normalized, 1.0/2.4) - 0.055) * 255.0
+}
+
+func bisectToLimit(y, targetHue float64) [3]float64 {
+	left, right := bisectToSegment(y, targetHue)
+	leftHue := hueOf(left)
+	for axis := range 3 {
+		if left[axis] == right[axis] {
+			continue
+		}
+		var lPlane, rPlane int
+		if left[axis] < right[axis] {
+			lPlane = int(math.Floor(trueDelinearized(left[axis]) - 0.5))
+			rPlane = int(math.Ceil(trueDelinearized(right[axis]) - 0.5))
+		} else {
+			lPlane = int(math.Ceil(trueDelinearized(left[axis]) - 0.5))
+			rPlane = int(math.Floor(trueDelinearized(right[axis]) - 0.5))
+		}
+		for range 8 {
+			if abs(rPlane-lPlane) <= 1 {
+				break
+			}
+			mPlane := int(math.Floor(float64(lPlane+rPlane) / 2.0))
+			mid := setCoordinate(left, criticalPlanes[mPlane], right, axis)
+			midHue := hueOf(mid)
+			if areInCyclicOrder(leftHue, targetHue, midHue) {
+				right, rPlane = mid, mPlane
+			} else {
+				left, leftHue, lPlane = mid, midHue, mPlane
+			}
+		}
+	}
+	return [3]float64{(left[0] + right[0]) / 2, (left[1] + right[1]) / 2, (left[2] + right[2]) / 2}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,159 @@
+package material
+
+import (
+	"math"
+	"sort"
+)
+
+// isDisliked reports dark yellow-greens, which are universally disliked.
+func isDisliked(h Hct) bool {
+	hue := math.Round(h.Hue)
+	return hue >= 90 && hue <= 111 && math.Round(h.Chroma) > 16 && math.Round(h.Tone) < 65
+}
+
+// fixIfDisliked lightens disliked colors into a more pleasant tone.
+func fixIfDisliked(h Hct) Hct {
+	if isDisliked(h) {
+		return HctFrom(h.Hue, h.Chroma, 70)
+	}
+	return h
+}
+
+// temperatureCache orders the hues of a color's chroma and tone from cold
+// to warm, for picking complements and analogous colors.
+type temperatureCache struct {
+	input      Hct
+	hctsByHue  []Hct
+	hctsByTemp []Hct
+	temps      map[ARGB]float64
+}
+
+func newTemperatureCache(input Hct) *temperatureCache {
+	tc := &temperatureCache{input: input, temps: make(map[ARGB]float64)}
+	for hue := 0; hue <= 360; hue++ {
+		h := HctFrom(float64(hue), input.Chroma, input.Tone)
+		tc.hctsByHue = append(tc.hctsByHue, h)
+		tc.temps[h.ARGB()] = rawTemperature(h)
+	}
+	tc.temps[input.ARGB()] = rawTemperature(input)
+
+	tc.hctsByTemp = append(append([]Hct{}, tc.hctsByHue...), input)
+	sort.SliceStable(tc.hctsByTemp, func(i, j int) bool {
+		return tc.temps[tc.hctsByTemp[i].ARGB()] < tc.temps[tc.hctsByTemp[j].ARGB()]
+	})
+	return tc
+}
+
+// rawTemperature is based on Ou, Woodcock and Wright's color temperature
+// model, with warm colors positive.
+func rawTemperature(h Hct) float64 {
+	lab := labFromARGB(h.ARGB())
+	hue := sanitizeDegrees(math.Atan2(lab[2], lab[1]) * 180.0 / math.Pi)
+	chroma := math.Hypot(lab[1], lab[2])
+	return -0.5 + 0.02*math.Pow(chroma, 1.07)*math.Cos(sanitizeDegrees(hue-50.0)*math.Pi/180.0)
+}
+
+func (tc *temperatureCache) coldest() Hct { return tc.hctsByTemp[0] }
+func (tc *temperatureCache) warmest() Hct { return tc.hctsByTemp[len(tc.hctsByTemp)-1] }
+
+func (tc *temperatureCache) relativeTemperature(h Hct) float64 {
+	coldestTemp := tc.temps[tc.coldest().ARGB()]
+	r := tc.temps[tc.warmest().ARGB()] - coldestTemp
+	if r == 0 {
+		return 0.5
+	}
+	return (tc.temps[h.ARGB()] - coldestTemp) / r
+}
+
+func isBetween(angle, a, b float64) bool {
+	if a < b {
+		return a <= angle && angle <= b
+	}
+	return a <= angle || angle <= b
+}
+
+// complement is the color of opposite temperature, found by walking the
+// hue wheel from the warmest or coldest hue.
+func (tc *temperatureCache) complement() Hct {
+	coldestHue, coldestTemp := tc.coldest().Hue, tc.temps[tc.coldest().ARGB()]
+	warmestHue, warmestTemp := tc.warmest().Hue, tc.temps[tc.warmest().ARGB()]
+	r := warmestTemp - coldestTemp
+
+	startHue, endHue := coldestHue, warmestHue
+	if isBetween(tc.input.Hue, coldestHue, warmestHue) {
+		startHue, endHue = warmestHue, coldestHue
+	}
+
+	smallestError := 1000.0
+	answer := tc.hctsByHue[int(math.Round(tc.input.Hue))]
+	complementRelativeTemp := 1.0 - tc.relativeTemperature(tc.input)
+
+	for hueAddend := 0.0; hueAddend <= 360.0; hueAddend++ {
+		hue := sanitizeDegrees(startHue + hueAddend)
+		if !isBetween(hue, startHue, endHue) {
+			continue
+		}
+		possible := tc.hctsByHue[int(math.Round(hue))]
+		relativeTemp := (tc.temps[possible.ARGB()] - coldestTemp) / r
+		if e := math.Abs(complementRelativeTemp - relativeTemp); e < smallestError {
+			smallestError = e
+			answer = possible
+		}
+	}
+	return answer
+}
+
+// analogous returns count colors around the input, spread over divisions
+// equal temperature steps of the hue wheel.
+func (tc *temperatureCache) analogous(count, divisions int) []Hct {
+	startHue := int(math.Round(tc.input.Hue))
+	startHct := tc.hctsByHue[startHue]
+	lastTemp := tc.relativeTemperature(startHct)
+
+	allColors := []Hct{startHct}
+	absoluteTotalTempDelta := 0.0
+	for i := range 360 {
+		temp := tc.relativeTemperature(tc.hctsByHue[sanitizeDegreesInt(startHue+i)])
+		absoluteTotalTempDelta += math.Abs(temp - lastTemp)
+		lastTemp = temp
+	}
+
+	tempStep := absoluteTotalTempDelta / float64(divisions)
+	totalTempDelta := 0.0
+	lastTemp = tc.relativeTemperature(startHct)
+	for hueAddend := 1; len(allColors) < divisions; {
+		hct := tc.hctsByHue[sanitizeDegreesInt(startHue+hueAddend)]
+		temp := tc.relativeTemperature(hct)
+		totalTempDelta += math.Abs(temp - lastTemp)
+
+		desired := float64(len(allColors)) * tempStep
+		satisfied := totalTempDelta >= desired
+		for indexAddend := 1; satisfied && len(allColors) < divisions; indexAddend++ {
+			allColors = append(allColors, hct)
+			desired = float64(len(allColors)+indexAddend) * tempStep
+			satisfied = totalTempDelta >= desired
+		}
+		lastTemp = temp
+		hueAddend++
+		if hueAddend > 360 {
+			for len(allColors) < divisions {
+				allColors = append(allColors, hct)
+			}
+			break
+		}
+	}
+
+	wrap := func(i int) Hct {
+		n := len(allColors)
+		return allColors[((i%n)+n)%n]
+	}
+	answers := []Hct{tc.input}
+	increase := (count - 1) / 2
+	for i := 1; i <= increase; i++ {
+		answers = append([]Hct{wrap(-i)}, answers...)
+	}
+	for i := 1; i <= count-increase-1; i++ {
+		answers = append(answers, wrap(i))
+	}
+	return answers
+}
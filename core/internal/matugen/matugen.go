@@ -30,6 +30,15 @@ const (
 	ColorModeLight ColorMode = "light"
 )
 
+// Backends that generate the palette and render templates. BackendAuto
+// uses the matugen binary when installed and the native generator
+// otherwise.
+const (
+	BackendAuto    = "auto"
+	BackendMatugen = "matugen"
+	BackendNative  = "native"
+)
+
 type TemplateKind int
 
 const (
@@ -54,6 +63,8 @@ var templateRegistry = []TemplateDef{
 	{ID: "niri", Commands: []string{"niri"}, ConfigFile: "niri.toml"},
 	{ID: "hyprland", Commands: []string{"Hyprland"}, ConfigFile: "hyprland.toml"},
 	{ID: "mangowc", Commands: []string{"mango"}, ConfigFile: "mangowc.toml"},
+	{ID: "sway", Commands: []string{"sway"}, ConfigFile: "sway.toml"},
+	{ID: "miracle", Commands: []string{"miracle-wm"}, ConfigFile: "miracle.toml"},
 	{ID: "qt5ct", Commands: []string{"qt5ct"}, ConfigFile: "qt5ct.toml"},
 	{ID: "qt6ct", Commands: []string{"qt6ct"}, ConfigFile: "qt6ct.toml"},
 	{ID: "firefox", Commands: []string{"firefox"}, ConfigFile: "firefox.toml"},
@@ -103,6 +114,7 @@ type Options struct {
 	SyncModeWithPortal  bool
 	TerminalsAlwaysDark bool
 	SkipTemplates       string
+	Backend             string
 	AppChecker          utils.AppChecker
 }
 
@@ -157,6 +169,17 @@ func Run(opts Options) error {
 	if opts.AppChecker == nil {
 		opts.AppChecker = utils.DefaultAppChecker{}
 	}
+	switch opts.Backend {
+	case "", BackendAuto:
+		opts.Backend = BackendMatugen
+		if !utils.CommandExists("matugen") {
+			log.Info("matugen not found, using the native generator")
+			opts.Backend = BackendNative
+		}
+	case BackendMatugen, BackendNative:
+	default:
+		return fmt.Errorf("unknown backend %q", opts.Backend)
+	}
 
 	if err := os.MkdirAll(opts.StateDir, 0o755); err != nil {
 		return fmt.Errorf("failed to create state dir: %w", err)
@@ -224,18 +247,39 @@ func buildOnce(opts *Options) (bool, error) {
 		importData := fmt.Sprintf(`{"colors": %s, "dank16": %s}`, opts.StockColors, dank16JSON)
 		importArgs = []string{"--import-json-string", importData}
 
-		log.Info("Running matugen color hex with stock color overrides")
-		args := []string{"color", "hex", primaryDark, "-m", string(opts.Mode), "-t", opts.MatugenType, "-c", cfgFile.Name()}
-		args = append(args, importArgs...)
-		if err := runMatugen(args); err != nil {
-			return false, err
+		if opts.Backend == BackendNative {
+			log.Info("Rendering templates with stock color overrides")
+			theme, err := newNativeTheme("hex", primaryDark, opts.MatugenType)
+			if err != nil {
+				return false, err
+			}
+			if err := theme.render(cfgFile.Name(), opts.Mode, importData); err != nil {
+				return false, err
+			}
+		} else {
+			log.Info("Running matugen color hex with stock color overrides")
+			args := []string{"color", "hex", primaryDark, "-m", string(opts.Mode), "-t", opts.MatugenType, "-c", cfgFile.Name()}
+			args = append(args, importArgs...)
+			if err := runMatugen(args); err != nil {
+				return false, err
+			}
 		}
 	} else {
 		log.Infof("Using dynamic theme from %s: %s", opts.Kind, opts.Value)
 
-		matJSON, err := runMatugenDryRun(opts)
-		if err != nil {
-			return false, fmt.Errorf("matugen dry-run failed: %w", err)
+		var theme *nativeTheme
+		var matJSON string
+		if opts.Backend == BackendNative {
+			theme, err = newNativeTheme(opts.Kind, opts.Value, opts.MatugenType)
+			if err != nil {
+				return false, fmt.Errorf("native color generation failed: %w", err)
+			}
+			matJSON = theme.JSON()
+		} else {
+			matJSON, err = runMatugenDryRun(opts)
+			if err != nil {
+				return false, fmt.Errorf("matugen dry-run failed: %w", err)
+			}
 		}
 
 		primaryDark = extractMatugenColor(matJSON, "primary", "dark")
@@ -253,18 +297,25 @@ func buildOnce(opts *Options) (bool, error) {
 		importData := fmt.Sprintf(`{"dank16": %s}`, dank16JSON)
 		importArgs = []string{"--import-json-string", importData}
 
-		log.Infof("Running matugen %s with dank16 injection", opts.Kind)
-		var args []string
-		switch opts.Kind {
-		case "hex":
-			args = []string{"color", "hex", opts.Value}
-		default:
-			args = []string{opts.Kind, opts.Value}
-		}
-		args = append(args, "-m", string(opts.Mode), "-t", opts.MatugenType, "-c", cfgFile.Name())
-		args = append(args, importArgs...)
-		if err := runMatugen(args); err != nil {
-			return false, err
+		if theme != nil {
+			log.Info("Rendering templates with dank16 injection")
+			if err := theme.render(cfgFile.Name(), opts.Mode, importData); err != nil {
+				return false, err
+			}
+		} else {
+			log.Infof("Running matugen %s with dank16 injection", opts.Kind)
+			var args []string
+			switch opts.Kind {
+			case "hex":
+				args = []string{"color", "hex", opts.Value}
+			default:
+				args = []string{opts.Kind, opts.Value}
+			}
+			args = append(args, "-m", string(opts.Mode), "-t", opts.MatugenType, "-c", cfgFile.Name())
+			args = append(args, importArgs...)
+			if err := runMatugen(args); err != nil {
+				return false, err
+			}
 		}
 	}
 
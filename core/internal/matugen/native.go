@@ -0,0 +1,190 @@
+package matugen
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"os/exec"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/material"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// nativeTheme is the built-in replacement for matugen: the same Material
+// You schemes and template tokens, generated without the binary.
+type nativeTheme struct {
+	source material.ARGB
+	image  string
+	dark   map[string]material.ARGB
+	light  map[string]material.ARGB
+}
+
+func newNativeTheme(kind, value, matugenType string) (*nativeTheme, error) {
+	variant, err := material.ParseVariant(matugenType)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &nativeTheme{}
+	switch kind {
+	case "hex":
+		if t.source, err = material.ParseHex(value); err != nil {
+			return nil, err
+		}
+	case "image":
+		path, err := utils.ExpandPath(value)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		img, _, err := image.Decode(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+		}
+		t.source = material.SourceColorFromImage(img)
+		t.image = path
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", kind)
+	}
+
+	t.dark = material.NewScheme(t.source, variant, true, 0).Colors()
+	t.light = material.NewScheme(t.source, variant, false, 0).Colors()
+	log.Infof("Native %s scheme from source color %s", variant, t.source.Hex())
+	return t, nil
+}
+
+// JSON mirrors `matugen --json hex --old-json-output`.
+func (t *nativeTheme) JSON() string {
+	colors := make(map[string]map[string]string, len(t.dark))
+	for name, c := range t.dark {
+		colors[name] = map[string]string{"dark": c.Hex(), "light": t.light[name].Hex()}
+	}
+	data, _ := json.Marshal(map[string]any{"colors": colors})
+	return string(data)
+}
+
+// templateContext builds the variables templates see. importData is the
+// JSON matugen would get through --import-json-string; objects holding a
+// "color" become color tokens, and it overrides generated values.
+func (t *nativeTheme) templateContext(mode ColorMode, importData string) (map[string]any, error) {
+	colors := make(map[string]any, len(t.dark))
+	for name, dark := range t.dark {
+		def := dark
+		if mode == ColorModeLight {
+			def = t.light[name]
+		}
+		colors[name] = map[string]any{
+			"dark":    newTemplateColor(dark),
+			"light":   newTemplateColor(t.light[name]),
+			"default": newTemplateColor(def),
+		}
+	}
+
+	ctx := map[string]any{
+		"colors":       colors,
+		"image":        t.image,
+		"mode":         string(mode),
+		"is_dark_mode": mode != ColorModeLight,
+	}
+
+	if importData == "" {
+		return ctx, nil
+	}
+	var imported map[string]any
+	if err := json.Unmarshal([]byte(importData), &imported); err != nil {
+		return nil, fmt.Errorf("invalid import data: %w", err)
+	}
+	for k, v := range imported {
+		imported[k] = importedColors(v)
+	}
+	mergeContext(ctx, imported)
+	return ctx, nil
+}
+
+func importedColors(v any) any {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	if hex, ok := m["color"].(string); ok && len(m) == 1 {
+		if c, err := material.ParseHex(hex); err == nil {
+			return newTemplateColor(c)
+		}
+	}
+	for k, child := range m {
+		m[k] = importedColors(child)
+	}
+	return m
+}
+
+func mergeContext(dst, src map[string]any) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]any)
+		dstMap, dstIsMap := dst[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeContext(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// render writes every template in the matugen config, like `matugen -c`.
+// A failing template is logged and skipped, as with --continue-on-error.
+func (t *nativeTheme) render(cfgPath string, mode ColorMode, importData string) error {
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return err
+	}
+	ctx, err := t.templateContext(mode, importData)
+	if err != nil {
+		return err
+	}
+
+	entries := parseTemplateEntries(string(data))
+
+	var failed int
+	for _, entry := range entries {
+		if entry.PreHook != "" {
+			runTemplateHook(entry.Name, entry.PreHook, ctx)
+		}
+		if err := renderTemplateEntry(entry, ctx); err != nil {
+			log.Warnf("Template %s failed: %v", entry.Name, err)
+			failed++
+			continue
+		}
+		if entry.PostHook != "" {
+			runTemplateHook(entry.Name, entry.PostHook, ctx)
+		}
+	}
+	if failed > 0 && failed == len(entries) {
+		return fmt.Errorf("all %d templates failed", failed)
+	}
+	return nil
+}
+
+func runTemplateHook(name, hook string, ctx map[string]any) {
+	cmdline, err := renderTemplate(hook, ctx)
+	if err != nil {
+		log.Warnf("Template %s hook: %v", name, err)
+		return
+	}
+	cmd := exec.Command("sh", "-c", cmdline)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Warnf("Template %s hook failed: %v", name, err)
+	}
+}
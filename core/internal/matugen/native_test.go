@@ -0,0 +1,103 @@
+package matugen
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNativeThemeHex(t *testing.T) {
+	theme, err := newNativeTheme("hex", "#0000ff", "scheme-tonal-spot")
+	require.NoError(t, err)
+
+	matJSON := theme.JSON()
+	assert.Equal(t, "#bec2ff", extractMatugenColor(matJSON, "primary", "dark"))
+	assert.Equal(t, "#555992", extractMatugenColor(matJSON, "primary", "light"))
+	assert.Equal(t, "#0000ff", extractMatugenColor(matJSON, "source_color", "dark"))
+
+	_, err = newNativeTheme("hex", "#0000ff", "scheme-bogus")
+	assert.Error(t, err)
+	_, err = newNativeTheme("json", "{}", "scheme-tonal-spot")
+	assert.Error(t, err)
+}
+
+func TestNativeThemeImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for y := range 64 {
+		for x := range 64 {
+			img.SetRGBA(x, y, color.RGBA{R: 0xd3, G: 0x2f, B: 0x2f, A: 0xff})
+		}
+	}
+	path := filepath.Join(t.TempDir(), "wall.png")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, img))
+	f.Close()
+
+	theme, err := newNativeTheme("image", path, "scheme-content")
+	require.NoError(t, err)
+	assert.Equal(t, "#d32f2f", theme.source.Hex())
+	assert.Equal(t, path, theme.image)
+
+	_, err = newNativeTheme("image", filepath.Join(t.TempDir(), "missing.png"), "scheme-content")
+	assert.Error(t, err)
+}
+
+func TestNativeThemeStockOverrides(t *testing.T) {
+	theme, err := newNativeTheme("hex", "#0000ff", "scheme-tonal-spot")
+	require.NoError(t, err)
+
+	stock := `{"primary": {"dark": {"color": "#83a598"}, "light": {"color": "#076678"}, "default": {"color": "#076678"}}}`
+	ctx, err := theme.templateContext(ColorModeLight, `{"colors": `+stock+`, "dank16": {"color0": {"default": {"hex": "#282828"}}}}`)
+	require.NoError(t, err)
+
+	out, err := renderTemplate("{{colors.primary.dark.hex}} {{colors.primary.default.hex}} {{colors.secondary.default.hex}} {{dank16.color0.default.hex}}", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "#83a598 #076678 #5c5d72 #282828", out)
+}
+
+func TestNativeRender(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "colors.json")
+	require.NoError(t, os.WriteFile(tmpl, []byte(`{"primary": "{{colors.primary.default.hex}}", "mode": "{{mode}}"}`), 0o644))
+	broken := filepath.Join(dir, "broken.conf")
+	require.NoError(t, os.WriteFile(broken, []byte(`{{colors.nope.default.hex}}`), 0o644))
+
+	out := filepath.Join(dir, "out", "colors.json")
+	hookOut := filepath.Join(dir, "hook")
+	cfg := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(cfg, []byte(strings.Join([]string{
+		"[config]",
+		"[templates.broken]",
+		"input_path = '" + broken + "'",
+		"output_path = '" + filepath.Join(dir, "broken.out") + "'",
+		"[templates.colors]",
+		"input_path = '" + tmpl + "'",
+		"output_path = '" + out + "'",
+		"post_hook = 'echo {{colors.primary.default.hex_stripped}} > " + hookOut + "'",
+	}, "\n")), 0o644))
+
+	theme, err := newNativeTheme("hex", "#0000ff", "scheme-tonal-spot")
+	require.NoError(t, err)
+	require.NoError(t, theme.render(cfg, ColorModeDark, ""))
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	var rendered map[string]string
+	require.NoError(t, json.Unmarshal(data, &rendered))
+	assert.Equal(t, map[string]string{"primary": "#bec2ff", "mode": "dark"}, rendered)
+
+	hook, err := os.ReadFile(hookOut)
+	require.NoError(t, err)
+	assert.Equal(t, "bec2ff\n", string(hook))
+
+	assert.NoFileExists(t, filepath.Join(dir, "broken.out"))
+}
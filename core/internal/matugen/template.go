@@ -0,0 +1,528 @@
+package matugen
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/material"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// The native renderer understands the subset of matugen's template
+// language used by DMS and most user templates:
+//
+//	{{colors.primary.default.hex}}           color formats
+//	{{colors.primary.dark.rgba | set_alpha: 0.5}}
+//	<* if {{ is_dark_mode }} *>...<* else *>...<* endif *>
+//	<* for name, value in colors *>{{name}} {{value.default.hex}}<* endfor *>
+
+// templateColor is a color token; the last path element picks the format.
+type templateColor struct {
+	c     colorful.Color
+	alpha float64
+}
+
+func newTemplateColor(argb material.ARGB) templateColor {
+	return templateColor{
+		c:     colorful.Color{R: float64(argb.Red()) / 255, G: float64(argb.Green()) / 255, B: float64(argb.Blue()) / 255},
+		alpha: 1,
+	}
+}
+
+func (tc templateColor) rgb() (r, g, b uint8) {
+	return tc.c.Clamped().RGB255()
+}
+
+func (tc templateColor) format(name string) (string, error) {
+	r, g, b := tc.rgb()
+	h, s, l := tc.c.Clamped().Hsl()
+	alpha := strconv.FormatFloat(math.Round(tc.alpha*100)/100, 'f', -1, 64)
+
+	switch name {
+	case "hex", "hex_stripped":
+		hex := fmt.Sprintf("%02x%02x%02x", r, g, b)
+		if tc.alpha < 1 {
+			hex += fmt.Sprintf("%02x", uint8(math.Round(tc.alpha*255)))
+		}
+		if name == "hex" {
+			return "#" + hex, nil
+		}
+		return hex, nil
+	case "rgb":
+		return fmt.Sprintf("rgb(%d, %d, %d)", r, g, b), nil
+	case "rgba":
+		return fmt.Sprintf("rgba(%d, %d, %d, %s)", r, g, b, alpha), nil
+	case "hsl":
+		return fmt.Sprintf("hsl(%.0f, %.0f%%, %.0f%%)", h, s*100, l*100), nil
+	case "hsla":
+		return fmt.Sprintf("hsla(%.0f, %.0f%%, %.0f%%, %s)", h, s*100, l*100, alpha), nil
+	case "red":
+		return strconv.Itoa(int(r)), nil
+	case "green":
+		return strconv.Itoa(int(g)), nil
+	case "blue":
+		return strconv.Itoa(int(b)), nil
+	case "alpha":
+		return alpha, nil
+	case "hue":
+		return fmt.Sprintf("%.0f", h), nil
+	case "saturation":
+		return fmt.Sprintf("%.0f", s*100), nil
+	case "lightness":
+		return fmt.Sprintf("%.0f", l*100), nil
+	}
+	return "", fmt.Errorf("unknown color format %q", name)
+}
+
+// applyFilter runs a matugen color filter. Amounts are percentages except
+// for set_alpha, which takes 0-1.
+func (tc templateColor) applyFilter(name string, arg float64) (templateColor, bool) {
+	h, s, l := tc.c.Hsl()
+	switch name {
+	case "set_alpha":
+		tc.alpha = math.Max(0, math.Min(1, arg))
+	case "set_lightness":
+		tc.c = colorful.Hsl(h, s, clamp01(arg/100))
+	case "lighten":
+		tc.c = colorful.Hsl(h, s, clamp01(l+arg/100))
+	case "darken":
+		tc.c = colorful.Hsl(h, s, clamp01(l-arg/100))
+	case "set_saturation":
+		tc.c = colorful.Hsl(h, clamp01(arg/100), l)
+	case "saturate":
+		tc.c = colorful.Hsl(h, clamp01(s+arg/100), l)
+	case "desaturate":
+		tc.c = colorful.Hsl(h, clamp01(s-arg/100), l)
+	case "set_hue":
+		tc.c = colorful.Hsl(math.Mod(math.Mod(arg, 360)+360, 360), s, l)
+	case "grayscale":
+		tc.c = colorful.Hsl(h, 0, l)
+	case "invert":
+		tc.c = colorful.Color{R: 1 - tc.c.R, G: 1 - tc.c.G, B: 1 - tc.c.B}
+	default:
+		return tc, false
+	}
+	return tc, true
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+type nodeKind int
+
+const (
+	nodeText nodeKind = iota
+	nodeExpr
+	nodeIf
+	nodeFor
+)
+
+type templateNode struct {
+	kind     nodeKind
+	text     string
+	not      bool
+	body     []templateNode
+	elseBody []templateNode
+	keyVar   string
+	valVar   string
+}
+
+type templateParser struct {
+	src string
+	pos int
+}
+
+// parseTemplate parses a template into nodes. It stops at a block tag that
+// belongs to an enclosing if or for and returns that tag.
+func (p *templateParser) parse() ([]templateNode, string, error) {
+	var nodes []templateNode
+	for p.pos < len(p.src) {
+		rest := p.src[p.pos:]
+		exprAt := strings.Index(rest, "{{")
+		blockAt := strings.Index(rest, "<*")
+
+		next := exprAt
+		if next < 0 || (blockAt >= 0 && blockAt < next) {
+			next = blockAt
+		}
+		if next < 0 {
+			nodes = append(nodes, templateNode{kind: nodeText, text: rest})
+			p.pos = len(p.src)
+			break
+		}
+		if next > 0 {
+			nodes = append(nodes, templateNode{kind: nodeText, text: rest[:next]})
+		}
+		p.pos += next
+
+		if next == exprAt {
+			end := strings.Index(p.src[p.pos:], "}}")
+			if end < 0 {
+				return nil, "", fmt.Errorf("unclosed {{ at offset %d", p.pos)
+			}
+			nodes = append(nodes, templateNode{kind: nodeExpr, text: strings.TrimSpace(p.src[p.pos+2 : p.pos+end])})
+			p.pos += end + 2
+			continue
+		}
+
+		end := strings.Index(p.src[p.pos:], "*>")
+		if end < 0 {
+			return nil, "", fmt.Errorf("unclosed <* at offset %d", p.pos)
+		}
+		tag := strings.TrimSpace(p.src[p.pos+2 : p.pos+end])
+		p.pos += end + 2
+		fields := strings.Fields(tag)
+		if len(fields) == 0 {
+			return nil, "", fmt.Errorf("empty block tag")
+		}
+
+		switch fields[0] {
+		case "else", "endif", "endfor":
+			return nodes, fields[0], nil
+		case "if":
+			node, err := p.parseIf(strings.TrimSpace(strings.TrimPrefix(tag, "if")))
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, node)
+		case "for":
+			node, err := p.parseFor(tag)
+			if err != nil {
+				return nil, "", err
+			}
+			nodes = append(nodes, node)
+		default:
+			return nil, "", fmt.Errorf("unknown block <* %s *>", tag)
+		}
+	}
+	return nodes, "", nil
+}
+
+func (p *templateParser) parseIf(cond string) (templateNode, error) {
+	node := templateNode{kind: nodeIf}
+	if strings.HasPrefix(cond, "not ") {
+		node.not = true
+		cond = strings.TrimSpace(strings.TrimPrefix(cond, "not "))
+	}
+	cond = strings.TrimSuffix(strings.TrimPrefix(cond, "{{"), "}}")
+	node.text = strings.TrimSpace(cond)
+
+	body, end, err := p.parse()
+	if err != nil {
+		return node, err
+	}
+	node.body = body
+	if end == "else" {
+		if node.elseBody, end, err = p.parse(); err != nil {
+			return node, err
+		}
+	}
+	if end != "endif" {
+		return node, fmt.Errorf("if %q is missing <* endif *>", node.text)
+	}
+	return node, nil
+}
+
+func (p *templateParser) parseFor(tag string) (templateNode, error) {
+	node := templateNode{kind: nodeFor}
+	head, source, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(tag, "for")), " in ")
+	if !ok {
+		return node, fmt.Errorf("invalid loop <* %s *>", tag)
+	}
+	vars := strings.Split(head, ",")
+	node.keyVar = strings.TrimSpace(vars[0])
+	if len(vars) > 1 {
+		node.valVar = strings.TrimSpace(vars[1])
+	}
+	node.text = strings.TrimSpace(source)
+
+	body, end, err := p.parse()
+	if err != nil {
+		return node, err
+	}
+	if end != "endfor" {
+		return node, fmt.Errorf("loop over %q is missing <* endfor *>", node.text)
+	}
+	node.body = body
+	return node, nil
+}
+
+func renderTemplate(src string, ctx map[string]any) (string, error) {
+	p := &templateParser{src: src}
+	nodes, end, err := p.parse()
+	if err != nil {
+		return "", err
+	}
+	if end != "" {
+		return "", fmt.Errorf("unexpected <* %s *>", end)
+	}
+	var out strings.Builder
+	if err := renderNodes(&out, nodes, []map[string]any{ctx}); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func renderNodes(out *strings.Builder, nodes []templateNode, scopes []map[string]any) error {
+	for _, n := range nodes {
+		switch n.kind {
+		case nodeText:
+			out.WriteString(n.text)
+		case nodeExpr:
+			s, err := evalExpr(n.text, scopes)
+			if err != nil {
+				return err
+			}
+			out.WriteString(s)
+		case nodeIf:
+			v, err := lookup(n.text, scopes)
+			if err != nil {
+				return err
+			}
+			body := n.elseBody
+			if truthy(v) != n.not {
+				body = n.body
+			}
+			if err := renderNodes(out, body, scopes); err != nil {
+				return err
+			}
+		case nodeFor:
+			v, err := lookup(n.text, scopes)
+			if err != nil {
+				return err
+			}
+			m, ok := v.(map[string]any)
+			if !ok {
+				return fmt.Errorf("cannot loop over %q", n.text)
+			}
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				scope := map[string]any{n.keyVar: k}
+				if n.valVar != "" {
+					scope[n.valVar] = m[k]
+				}
+				if err := renderNodes(out, n.body, append(scopes, scope)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func evalExpr(expr string, scopes []map[string]any) (string, error) {
+	parts := strings.Split(expr, "|")
+	path := strings.TrimSpace(parts[0])
+
+	format := ""
+	v, err := lookup(path, scopes)
+	if err != nil {
+		// the format is the last path element of a color token
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return "", err
+		}
+		base, lookupErr := lookup(path[:i], scopes)
+		if _, ok := base.(templateColor); lookupErr != nil || !ok {
+			return "", err
+		}
+		v, format = base, path[i+1:]
+	}
+
+	for _, f := range parts[1:] {
+		name, arg, _ := strings.Cut(f, ":")
+		name, arg = strings.TrimSpace(name), strings.Trim(strings.TrimSpace(arg), `"'`)
+
+		if c, ok := v.(templateColor); ok {
+			amount, _ := strconv.ParseFloat(arg, 64)
+			if filtered, ok := c.applyFilter(name, amount); ok {
+				v = filtered
+				continue
+			}
+		}
+
+		s, err := stringify(v, format)
+		if err != nil {
+			return "", err
+		}
+		switch name {
+		case "lower_case":
+			v = strings.ToLower(s)
+		case "upper_case":
+			v = strings.ToUpper(s)
+		case "replace":
+			from, to, _ := strings.Cut(f[strings.Index(f, ":")+1:], ",")
+			v = strings.ReplaceAll(s, strings.Trim(strings.TrimSpace(from), `"'`), strings.Trim(strings.TrimSpace(to), `"'`))
+		default:
+			return "", fmt.Errorf("unknown filter %q", name)
+		}
+		format = ""
+	}
+	return stringify(v, format)
+}
+
+func stringify(v any, format string) (string, error) {
+	switch v := v.(type) {
+	case templateColor:
+		if format == "" {
+			format = "hex"
+		}
+		return v.format(format)
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("cannot render %T", v)
+}
+
+func lookup(path string, scopes []map[string]any) (any, error) {
+	keys := strings.Split(path, ".")
+	var v any
+	found := false
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if v, found = scopes[i][keys[0]]; found {
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown variable %q", path)
+	}
+	for _, k := range keys[1:] {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unknown variable %q", path)
+		}
+		if v, ok = m[k]; !ok {
+			return nil, fmt.Errorf("unknown variable %q", path)
+		}
+	}
+	return v, nil
+}
+
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v != "" && v != "false"
+	case nil:
+		return false
+	}
+	return true
+}
+
+// templateEntry is one [templates.<name>] section of a matugen config.
+type templateEntry struct {
+	Name       string
+	InputPath  string
+	OutputPath string
+	PreHook    string
+	PostHook   string
+}
+
+// parseTemplateEntries reads the template sections of a matugen config.
+// Only single-line string values are supported, which is all DMS and its
+// template configs use.
+func parseTemplateEntries(content string) []templateEntry {
+	var entries []templateEntry
+	var current *templateEntry
+
+	flush := func() {
+		if current != nil && current.InputPath != "" && current.OutputPath != "" {
+			entries = append(entries, *current)
+		}
+		current = nil
+	}
+
+	for _, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			flush()
+			section := strings.Trim(line, "[] ")
+			if name, ok := strings.CutPrefix(section, "templates."); ok {
+				current = &templateEntry{Name: strings.Trim(name, `"'`)}
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = unquoteTOML(strings.TrimSpace(value))
+		switch strings.TrimSpace(key) {
+		case "input_path":
+			current.InputPath = value
+		case "output_path":
+			current.OutputPath = value
+		case "pre_hook":
+			current.PreHook = value
+		case "post_hook":
+			current.PostHook = value
+		}
+	}
+	flush()
+	return entries
+}
+
+func unquoteTOML(v string) string {
+	if len(v) >= 2 && v[0] == '\'' {
+		if end := strings.IndexByte(v[1:], '\''); end >= 0 {
+			return v[1 : end+1]
+		}
+	}
+	if len(v) >= 2 && v[0] == '"' {
+		if s, err := strconv.Unquote(v[:strings.LastIndexByte(v, '"')+1]); err == nil {
+			return s
+		}
+	}
+	return v
+}
+
+// renderTemplateEntry renders one template to its output path.
+func renderTemplateEntry(entry templateEntry, ctx map[string]any) error {
+	inPath, err := utils.ExpandPath(entry.InputPath)
+	if err != nil {
+		return err
+	}
+	outPath, err := utils.ExpandPath(entry.OutputPath)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(inPath)
+	if err != nil {
+		return err
+	}
+	rendered, err := renderTemplate(string(data), ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", inPath, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(outPath, []byte(rendered), 0o644)
+}
@@ -0,0 +1,144 @@
+package matugen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/material"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testContext() map[string]any {
+	return map[string]any{
+		"colors": map[string]any{
+			"primary": map[string]any{
+				"dark":    newTemplateColor(0xffd0bcff),
+				"light":   newTemplateColor(0xff6750a4),
+				"default": newTemplateColor(0xffd0bcff),
+			},
+			"surface": map[string]any{
+				"default": newTemplateColor(0xff141218),
+			},
+		},
+		"dank16": map[string]any{
+			"color1": map[string]any{"default": map[string]any{"hex": "#ff5555", "hex_stripped": "ff5555"}},
+		},
+		"image":        "/wall.png",
+		"is_dark_mode": true,
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	for _, tc := range []struct {
+		name, in, out string
+	}{
+		{"hex", "a {{colors.primary.default.hex}} b", "a #d0bcff b"},
+		{"spaces", "{{ colors.primary.light.hex_stripped }}", "6750a4"},
+		{"channels", "{{colors.primary.dark.red}},{{colors.primary.dark.green}},{{colors.primary.dark.blue}}", "208,188,255"},
+		{"rgb", "{{colors.primary.default.rgb}}", "rgb(208, 188, 255)"},
+		{"rgba", "{{colors.primary.default.rgba}}", "rgba(208, 188, 255, 1)"},
+		{"set alpha", "{{colors.primary.default.rgba | set_alpha: 0.5}}", "rgba(208, 188, 255, 0.5)"},
+		{"hex alpha", "{{colors.primary.default.hex | set_alpha: 0.5}}", "#d0bcff80"},
+		{"upper", "{{colors.primary.default.hex | upper_case}}", "#D0BCFF"},
+		{"replace", "{{colors.primary.default.hex | replace: \"#\", \"0x\"}}", "0xd0bcff"},
+		{"imported", "{{dank16.color1.default.hex_stripped}}", "ff5555"},
+		{"image", "{{image}}", "/wall.png"},
+		{"if", "dim=<* if {{ is_dark_mode }} *>black<* else *>white<* endif *>", "dim=black"},
+		{"if not", "<* if not is_dark_mode *>light<* else *>dark<* endif *>", "dark"},
+		{"for", "<* for name, value in colors *>{{name}}={{value.default.hex}};<* endfor *>", "primary=#d0bcff;surface=#141218;"},
+		{"plain", "no tokens {here}", "no tokens {here}"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := renderTemplate(tc.in, testContext())
+			require.NoError(t, err)
+			assert.Equal(t, tc.out, out)
+		})
+	}
+}
+
+func TestRenderTemplateErrors(t *testing.T) {
+	for name, in := range map[string]string{
+		"unknown color":  "{{colors.nope.default.hex}}",
+		"unknown format": "{{colors.primary.default.cmyk}}",
+		"unknown filter": "{{colors.primary.default.hex | sparkle}}",
+		"unclosed":       "{{colors.primary.default.hex",
+		"missing endif":  "<* if is_dark_mode *>x",
+		"stray endfor":   "x<* endfor *>",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := renderTemplate(in, testContext())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestColorFilters(t *testing.T) {
+	c := newTemplateColor(0xff808080)
+
+	lighter, ok := c.applyFilter("lighten", 20)
+	require.True(t, ok)
+	l, _ := lighter.format("lightness")
+	assert.Equal(t, "70", l)
+
+	inverted, _ := newTemplateColor(0xff102030).applyFilter("invert", 0)
+	hex, _ := inverted.format("hex")
+	assert.Equal(t, "#efdfcf", hex)
+
+	_, ok = c.applyFilter("upper_case", 0)
+	assert.False(t, ok)
+}
+
+func TestParseTemplateEntries(t *testing.T) {
+	entries := parseTemplateEntries(`[config]
+reload_apps = true
+
+[templates.dank]
+input_path = '/shell/matugen/templates/dank.json'
+output_path = '/state/dms-colors.json'
+
+# comment
+[templates.dmssway]
+input_path = "/shell/matugen/templates/sway-colors.conf"
+output_path = '~/.config/sway/dank'
+post_hook = 'sh -c "swaymsg reload >/dev/null 2>&1 || true"'
+
+[templates.incomplete]
+input_path = '/x'
+`)
+	require.Len(t, entries, 2)
+	assert.Equal(t, templateEntry{
+		Name:       "dank",
+		InputPath:  "/shell/matugen/templates/dank.json",
+		OutputPath: "/state/dms-colors.json",
+	}, entries[0])
+	assert.Equal(t, "dmssway", entries[1].Name)
+	assert.Equal(t, "/shell/matugen/templates/sway-colors.conf", entries[1].InputPath)
+	assert.Equal(t, `sh -c "swaymsg reload >/dev/null 2>&1 || true"`, entries[1].PostHook)
+}
+
+// TestShellTemplatesRender checks that the native token set covers every
+// template DMS ships.
+func TestShellTemplatesRender(t *testing.T) {
+	templatesDir := filepath.Join("..", "..", "..", "quickshell", "matugen", "templates")
+	files, err := os.ReadDir(templatesDir)
+	if err != nil {
+		t.Skipf("shell templates not available: %v", err)
+	}
+
+	theme := &nativeTheme{source: 0xff6750a4}
+	theme.dark = material.NewScheme(theme.source, material.VariantTonalSpot, true, 0).Colors()
+	theme.light = material.NewScheme(theme.source, material.VariantTonalSpot, false, 0).Colors()
+	dank16JSON := generateDank16Variants(theme.dark["primary"].Hex(), theme.light["primary"].Hex(), theme.dark["surface"].Hex(), ColorModeDark)
+
+	ctx, err := theme.templateContext(ColorModeDark, `{"dank16": `+dank16JSON+`}`)
+	require.NoError(t, err)
+
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(templatesDir, f.Name()))
+		require.NoError(t, err)
+		_, err = renderTemplate(string(data), ctx)
+		assert.NoError(t, err, f.Name())
+	}
+}
@@ -0,0 +1,572 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+type AnnotationTool int
+
+const (
+	ToolArrow AnnotationTool = iota
+	ToolRectangle
+	ToolPen
+	ToolText
+	ToolStep
+	ToolHighlighter
+	ToolPixelate
+	ToolBlur
+)
+
+var annotationToolNames = []string{"arrow", "rect", "pen", "text", "step", "highlight", "pixelate", "blur"}
+
+func (t AnnotationTool) String() string {
+	if t < 0 || int(t) >= len(annotationToolNames) {
+		return "unknown"
+	}
+	return annotationToolNames[t]
+}
+
+type Annotation struct {
+	Tool   AnnotationTool
+	Points []image.Point
+	Color  color.NRGBA
+	Width  int
+	Text   string
+	Step   int
+}
+
+var annotationPalette = []color.NRGBA{
+	{R: 244, G: 67, B: 54, A: 255},
+	{R: 255, G: 193, B: 7, A: 255},
+	{R: 76, G: 175, B: 80, A: 255},
+	{R: 255, G: 255, B: 255, A: 255},
+	{R: 0, G: 0, B: 0, A: 255},
+}
+
+const (
+	minAnnotationWidth = 1
+	maxAnnotationWidth = 16
+	highlighterAlpha   = 100
+)
+
+// AnnotationEditor holds the annotations drawn over a captured region along
+// with the undo history. Points are in buffer pixels relative to the region.
+type AnnotationEditor struct {
+	Tool  AnnotationTool
+	Color color.NRGBA
+	Width int
+
+	palette []color.NRGBA
+	items   []Annotation
+	undone  []Annotation
+	current *Annotation
+}
+
+func NewAnnotationEditor(accent color.NRGBA) *AnnotationEditor {
+	return &AnnotationEditor{
+		Tool:    ToolArrow,
+		Color:   accent,
+		Width:   4,
+		palette: append([]color.NRGBA{accent}, annotationPalette...),
+	}
+}
+
+func (e *AnnotationEditor) Annotations() []Annotation {
+	return e.items
+}
+
+func (e *AnnotationEditor) Empty() bool {
+	return len(e.items) == 0 && e.current == nil
+}
+
+// Editing reports whether a text label is being typed.
+func (e *AnnotationEditor) Editing() bool {
+	return e.current != nil && e.current.Tool == ToolText
+}
+
+func (e *AnnotationEditor) Begin(p image.Point) {
+	if e.Editing() {
+		e.CommitText()
+	}
+
+	a := Annotation{Tool: e.Tool, Points: []image.Point{p}, Color: e.Color, Width: e.Width}
+	switch e.Tool {
+	case ToolStep:
+		a.Step = e.nextStep()
+		e.push(a)
+		return
+	case ToolHighlighter:
+		a.Color.A = highlighterAlpha
+	}
+	e.current = &a
+}
+
+func (e *AnnotationEditor) Move(p image.Point) {
+	if e.current == nil || e.current.Tool == ToolText {
+		return
+	}
+	switch e.current.Tool {
+	case ToolPen, ToolHighlighter:
+		if last := e.current.Points[len(e.current.Points)-1]; last != p {
+			e.current.Points = append(e.current.Points, p)
+		}
+	default:
+		e.current.Points = append(e.current.Points[:1], p)
+	}
+}
+
+// End finishes the annotation being dragged. Text labels stay open for
+// typing until CommitText.
+func (e *AnnotationEditor) End() {
+	if e.current == nil || e.current.Tool == ToolText {
+		return
+	}
+	a := *e.current
+	e.current = nil
+	if len(a.Points) < 2 && a.Tool != ToolPen && a.Tool != ToolHighlighter {
+		return
+	}
+	e.push(a)
+}
+
+func (e *AnnotationEditor) TypeRune(r rune) {
+	if e.Editing() {
+		e.current.Text += string(r)
+	}
+}
+
+func (e *AnnotationEditor) Backspace() {
+	if !e.Editing() || e.current.Text == "" {
+		return
+	}
+	runes := []rune(e.current.Text)
+	e.current.Text = string(runes[:len(runes)-1])
+}
+
+func (e *AnnotationEditor) CommitText() {
+	if !e.Editing() {
+		return
+	}
+	a := *e.current
+	e.current = nil
+	if a.Text != "" {
+		e.push(a)
+	}
+}
+
+func (e *AnnotationEditor) CancelText() {
+	if e.Editing() {
+		e.current = nil
+	}
+}
+
+func (e *AnnotationEditor) Undo() bool {
+	if e.current != nil {
+		e.current = nil
+		return true
+	}
+	if len(e.items) == 0 {
+		return false
+	}
+	last := e.items[len(e.items)-1]
+	e.items = e.items[:len(e.items)-1]
+	e.undone = append(e.undone, last)
+	return true
+}
+
+func (e *AnnotationEditor) Redo() bool {
+	if len(e.undone) == 0 {
+		return false
+	}
+	last := e.undone[len(e.undone)-1]
+	e.undone = e.undone[:len(e.undone)-1]
+	e.items = append(e.items, last)
+	return true
+}
+
+func (e *AnnotationEditor) CycleColor() {
+	for i, c := range e.palette {
+		if c == e.Color {
+			e.Color = e.palette[(i+1)%len(e.palette)]
+			return
+		}
+	}
+	e.Color = e.palette[0]
+}
+
+func (e *AnnotationEditor) AdjustWidth(delta int) {
+	e.Width = max(minAnnotationWidth, min(maxAnnotationWidth, e.Width+delta))
+}
+
+func (e *AnnotationEditor) push(a Annotation) {
+	e.items = append(e.items, a)
+	e.undone = nil
+}
+
+func (e *AnnotationEditor) nextStep() int {
+	step := 1
+	for _, a := range e.items {
+		if a.Tool == ToolStep {
+			step = a.Step + 1
+		}
+	}
+	return step
+}
+
+// Render draws every annotation onto dst, whose bounds are the captured
+// region. swapRB is set when dst holds BGRA pixels.
+func (e *AnnotationEditor) Render(dst *image.RGBA, swapRB bool) {
+	for i := range e.items {
+		renderAnnotation(dst, &e.items[i], swapRB, false)
+	}
+	if e.current != nil {
+		renderAnnotation(dst, e.current, swapRB, e.Editing())
+	}
+}
+
+func renderAnnotation(dst *image.RGBA, a *Annotation, swapRB, caret bool) {
+	origin := dst.Bounds().Min
+	points := make([]image.Point, len(a.Points))
+	for i, p := range a.Points {
+		points[i] = p.Add(origin)
+	}
+
+	c := a.Color
+	if swapRB {
+		c.R, c.B = c.B, c.R
+	}
+
+	first, last := points[0], points[len(points)-1]
+	switch a.Tool {
+	case ToolArrow:
+		drawArrow(dst, first, last, a.Width, c)
+	case ToolRectangle:
+		r := image.Rectangle{Min: first, Max: last}.Canon()
+		fillMask(dst, c, a.Width, func(m *shapeMask) {
+			m.line(r.Min, image.Pt(r.Max.X, r.Min.Y), a.Width)
+			m.line(image.Pt(r.Max.X, r.Min.Y), r.Max, a.Width)
+			m.line(r.Max, image.Pt(r.Min.X, r.Max.Y), a.Width)
+			m.line(image.Pt(r.Min.X, r.Max.Y), r.Min, a.Width)
+		}, points...)
+	case ToolPen:
+		drawPolyline(dst, points, a.Width, c)
+	case ToolHighlighter:
+		drawPolyline(dst, points, a.Width*4, c)
+	case ToolText:
+		drawLabel(dst, first, a.Text, textSize(a.Width), c, caret)
+	case ToolStep:
+		drawStep(dst, first, a.Step, a.Width, c)
+	case ToolPixelate:
+		pixelate(dst, image.Rectangle{Min: first, Max: last}.Canon(), max(8, a.Width*3))
+	case ToolBlur:
+		boxBlur(dst, image.Rectangle{Min: first, Max: last}.Canon(), max(4, a.Width*2))
+	}
+}
+
+// shapeMask collects the pixels of a shape so that overlapping strokes are
+// composited once, which keeps translucent highlighter strokes even.
+type shapeMask struct {
+	*image.Alpha
+}
+
+func (m *shapeMask) disc(c image.Point, radius int) {
+	r2 := radius * radius
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= r2 {
+				m.SetAlpha(c.X+x, c.Y+y, color.Alpha{A: 255})
+			}
+		}
+	}
+}
+
+func (m *shapeMask) line(p0, p1 image.Point, width int) {
+	radius := max(width/2, 0)
+	dx, dy := float64(p1.X-p0.X), float64(p1.Y-p0.Y)
+	steps := int(math.Max(math.Abs(dx), math.Abs(dy)))
+	if steps == 0 {
+		m.disc(p0, radius)
+		return
+	}
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		m.disc(image.Pt(p0.X+int(math.Round(dx*t)), p0.Y+int(math.Round(dy*t))), radius)
+	}
+}
+
+func (m *shapeMask) triangle(a, b, c image.Point) {
+	bounds := pointRect(a).Union(pointRect(b)).Union(pointRect(c)).Intersect(m.Bounds())
+
+	edge := func(p0, p1, p image.Point) int {
+		return (p1.X-p0.X)*(p.Y-p0.Y) - (p1.Y-p0.Y)*(p.X-p0.X)
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := image.Pt(x, y)
+			w0, w1, w2 := edge(b, c, p), edge(c, a, p), edge(a, b, p)
+			if (w0 >= 0 && w1 >= 0 && w2 >= 0) || (w0 <= 0 && w1 <= 0 && w2 <= 0) {
+				m.SetAlpha(x, y, color.Alpha{A: 255})
+			}
+		}
+	}
+}
+
+// pointRect is the one pixel rectangle at p; Union ignores empty rectangles.
+func pointRect(p image.Point) image.Rectangle {
+	return image.Rectangle{Min: p, Max: p.Add(image.Pt(1, 1))}
+}
+
+// fillMask rasterises a shape spanning points into a mask sized to its
+// bounding box and composites c through it.
+func fillMask(dst *image.RGBA, c color.NRGBA, pad int, shape func(*shapeMask), points ...image.Point) {
+	if len(points) == 0 {
+		return
+	}
+	bounds := pointRect(points[0])
+	for _, p := range points[1:] {
+		bounds = bounds.Union(pointRect(p))
+	}
+	bounds = bounds.Inset(-pad - 1).Intersect(dst.Bounds())
+	if bounds.Empty() {
+		return
+	}
+
+	m := &shapeMask{image.NewAlpha(bounds)}
+	shape(m)
+	compositeMask(dst, c, m.Alpha)
+}
+
+func compositeMask(dst *image.RGBA, c color.NRGBA, mask *image.Alpha) {
+	draw.DrawMask(dst, mask.Bounds(), image.NewUniform(c), image.Point{}, mask, mask.Bounds().Min, draw.Over)
+}
+
+func drawPolyline(dst *image.RGBA, points []image.Point, width int, c color.NRGBA) {
+	fillMask(dst, c, width, func(m *shapeMask) {
+		if len(points) == 1 {
+			m.disc(points[0], width/2)
+			return
+		}
+		for i := 1; i < len(points); i++ {
+			m.line(points[i-1], points[i], width)
+		}
+	}, points...)
+}
+
+func drawArrow(dst *image.RGBA, from, to image.Point, width int, c color.NRGBA) {
+	dx, dy := float64(to.X-from.X), float64(to.Y-from.Y)
+	length := math.Hypot(dx, dy)
+	if length < 1 {
+		return
+	}
+	ux, uy := dx/length, dy/length
+
+	head := math.Min(math.Max(12, float64(width)*4), length)
+	half := head * 0.55
+	base := image.Pt(to.X-int(math.Round(ux*head)), to.Y-int(math.Round(uy*head)))
+	left := image.Pt(base.X+int(math.Round(-uy*half)), base.Y+int(math.Round(ux*half)))
+	right := image.Pt(base.X-int(math.Round(-uy*half)), base.Y-int(math.Round(ux*half)))
+
+	fillMask(dst, c, width, func(m *shapeMask) {
+		m.line(from, base, width)
+		m.triangle(to, left, right)
+	}, from, to, left, right)
+}
+
+func drawStep(dst *image.RGBA, center image.Point, step, width int, c color.NRGBA) {
+	radius := 10 + width*2
+	fillMask(dst, c, radius, func(m *shapeMask) {
+		m.disc(center, radius)
+	}, center)
+
+	label := strconv.Itoa(step)
+	face := annotationFace(float64(radius) * 1.2)
+	if face == nil {
+		return
+	}
+	textColor := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	if luminance(c) > 0.6 {
+		textColor = color.NRGBA{A: 255}
+	}
+	metrics := face.Metrics()
+	width26 := font.MeasureString(face, label)
+	d := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(textColor),
+		Face: face,
+		Dot: fixed.Point26_6{
+			X: fixed.I(center.X) - width26/2,
+			Y: fixed.I(center.Y) + (metrics.Ascent-metrics.Descent)/2,
+		},
+	}
+	d.DrawString(label)
+}
+
+func drawLabel(dst *image.RGBA, at image.Point, text string, size float64, c color.NRGBA, caret bool) {
+	face := annotationFace(size)
+	if face == nil {
+		return
+	}
+	metrics := face.Metrics()
+	d := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.I(at.X), Y: fixed.I(at.Y) + metrics.Ascent},
+	}
+	d.DrawString(text)
+
+	if caret {
+		x := d.Dot.X.Ceil() + 1
+		top, bottom := image.Pt(x, at.Y), image.Pt(x, at.Y+(metrics.Ascent+metrics.Descent).Ceil())
+		fillMask(dst, c, 1, func(m *shapeMask) { m.line(top, bottom, 2) }, top, bottom)
+	}
+}
+
+func textSize(width int) float64 {
+	return float64(12 + width*4)
+}
+
+func luminance(c color.NRGBA) float64 {
+	return (0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)) / 255
+}
+
+// pixelate replaces each block of r with its average color.
+func pixelate(dst *image.RGBA, r image.Rectangle, block int) {
+	r = r.Intersect(dst.Bounds())
+	for by := r.Min.Y; by < r.Max.Y; by += block {
+		for bx := r.Min.X; bx < r.Max.X; bx += block {
+			cell := image.Rect(bx, by, bx+block, by+block).Intersect(r)
+			var sum [3]int
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				off := dst.PixOffset(cell.Min.X, y)
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					sum[0] += int(dst.Pix[off])
+					sum[1] += int(dst.Pix[off+1])
+					sum[2] += int(dst.Pix[off+2])
+					off += 4
+				}
+			}
+			n := cell.Dx() * cell.Dy()
+			if n == 0 {
+				continue
+			}
+			avg := [3]uint8{uint8(sum[0] / n), uint8(sum[1] / n), uint8(sum[2] / n)}
+			for y := cell.Min.Y; y < cell.Max.Y; y++ {
+				off := dst.PixOffset(cell.Min.X, y)
+				for x := cell.Min.X; x < cell.Max.X; x++ {
+					dst.Pix[off], dst.Pix[off+1], dst.Pix[off+2] = avg[0], avg[1], avg[2]
+					off += 4
+				}
+			}
+		}
+	}
+}
+
+// boxBlur blurs r with three passes of a separable box filter, which is close
+// enough to a gaussian to make text unreadable.
+func boxBlur(dst *image.RGBA, r image.Rectangle, radius int) {
+	r = r.Intersect(dst.Bounds())
+	if r.Empty() {
+		return
+	}
+	for range 3 {
+		blurPass(dst, r, radius, true)
+		blurPass(dst, r, radius, false)
+	}
+}
+
+func blurPass(dst *image.RGBA, r image.Rectangle, radius int, horizontal bool) {
+	outer, inner := r.Dy(), r.Dx()
+	if !horizontal {
+		outer, inner = inner, outer
+	}
+
+	line := make([][3]int, inner)
+	for o := 0; o < outer; o++ {
+		at := func(i int) int {
+			if horizontal {
+				return dst.PixOffset(r.Min.X+i, r.Min.Y+o)
+			}
+			return dst.PixOffset(r.Min.X+o, r.Min.Y+i)
+		}
+		for i := range line {
+			off := at(i)
+			line[i] = [3]int{int(dst.Pix[off]), int(dst.Pix[off+1]), int(dst.Pix[off+2])}
+		}
+
+		var sum [3]int
+		count := 0
+		for i := 0; i <= min(radius, inner-1); i++ {
+			for c := range sum {
+				sum[c] += line[i][c]
+			}
+			count++
+		}
+		for i := 0; i < inner; i++ {
+			off := at(i)
+			for c := range sum {
+				dst.Pix[off+c] = uint8(sum[c] / count)
+			}
+			if add := i + radius + 1; add < inner {
+				for c := range sum {
+					sum[c] += line[add][c]
+				}
+				count++
+			}
+			if drop := i - radius; drop >= 0 {
+				for c := range sum {
+					sum[c] -= line[drop][c]
+				}
+				count--
+			}
+		}
+	}
+}
+
+var (
+	annotationFontOnce sync.Once
+	annotationFont     *opentype.Font
+	annotationFaces    = map[float64]font.Face{}
+)
+
+func annotationFace(size float64) font.Face {
+	annotationFontOnce.Do(func() {
+		annotationFont, _ = opentype.Parse(gobold.TTF)
+	})
+	if annotationFont == nil {
+		return nil
+	}
+	if face, ok := annotationFaces[size]; ok {
+		return face
+	}
+	face, err := opentype.NewFace(annotationFont, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil
+	}
+	annotationFaces[size] = face
+	return face
+}
+
+// bufferImage wraps the pixels of buf without copying them.
+func bufferImage(buf *ShmBuffer) *image.RGBA {
+	return &image.RGBA{
+		Pix:    buf.Data(),
+		Stride: buf.Stride,
+		Rect:   image.Rect(0, 0, buf.Width, buf.Height),
+	}
+}
+
+// swapsRB reports whether pixels of format are stored blue first.
+func swapsRB(format uint32) bool {
+	return format != uint32(FormatABGR8888) && format != uint32(FormatXBGR8888)
+}
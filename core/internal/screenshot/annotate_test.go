@@ -0,0 +1,216 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAccent = color.NRGBA{R: 100, G: 180, B: 255, A: 255}
+
+func solidImage(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestAnnotationEditorUndoRedo(t *testing.T) {
+	e := NewAnnotationEditor(testAccent)
+	assert.True(t, e.Empty())
+
+	e.Begin(image.Pt(1, 1))
+	e.Move(image.Pt(20, 20))
+	e.End()
+
+	e.Tool = ToolRectangle
+	e.Begin(image.Pt(5, 5))
+	e.Move(image.Pt(10, 8))
+	e.Move(image.Pt(30, 30))
+	e.End()
+
+	require.Len(t, e.Annotations(), 2)
+	assert.Equal(t, []image.Point{{5, 5}, {30, 30}}, e.Annotations()[1].Points, "shapes keep only their end points")
+
+	assert.True(t, e.Undo())
+	require.Len(t, e.Annotations(), 1)
+	assert.True(t, e.Redo())
+	require.Len(t, e.Annotations(), 2)
+	assert.False(t, e.Redo())
+
+	e.Undo()
+	e.Tool = ToolPen
+	e.Begin(image.Pt(0, 0))
+	e.End()
+	assert.False(t, e.Redo(), "a new annotation clears the redo history")
+
+	e.Tool = ToolArrow
+	e.Begin(image.Pt(3, 3))
+	e.End()
+	assert.Len(t, e.Annotations(), 2, "a click without a drag adds no arrow")
+}
+
+func TestAnnotationEditorSteps(t *testing.T) {
+	e := NewAnnotationEditor(testAccent)
+	e.Tool = ToolStep
+	for i := 0; i < 3; i++ {
+		e.Begin(image.Pt(i*10, 0))
+		e.End()
+	}
+	require.Len(t, e.Annotations(), 3)
+	assert.Equal(t, 3, e.Annotations()[2].Step)
+
+	e.Undo()
+	e.Begin(image.Pt(50, 0))
+	assert.Equal(t, 3, e.Annotations()[2].Step, "numbering continues from the remaining markers")
+}
+
+func TestAnnotationEditorText(t *testing.T) {
+	e := NewAnnotationEditor(testAccent)
+	e.Tool = ToolText
+	e.Begin(image.Pt(4, 4))
+	e.End()
+	assert.True(t, e.Editing())
+
+	for _, r := range "Hix" {
+		e.TypeRune(r)
+	}
+	e.Backspace()
+	e.CommitText()
+
+	assert.False(t, e.Editing())
+	require.Len(t, e.Annotations(), 1)
+	assert.Equal(t, "Hi", e.Annotations()[0].Text)
+
+	e.Begin(image.Pt(10, 10))
+	e.CancelText()
+	assert.Len(t, e.Annotations(), 1, "an empty or cancelled label is dropped")
+}
+
+func TestAnnotationEditorStyle(t *testing.T) {
+	e := NewAnnotationEditor(testAccent)
+	e.CycleColor()
+	assert.Equal(t, annotationPalette[0], e.Color)
+	for range annotationPalette {
+		e.CycleColor()
+	}
+	assert.Equal(t, testAccent, e.Color, "colors wrap around to the accent")
+
+	e.AdjustWidth(100)
+	assert.Equal(t, maxAnnotationWidth, e.Width)
+	e.AdjustWidth(-100)
+	assert.Equal(t, minAnnotationWidth, e.Width)
+}
+
+func TestAnnotationRender(t *testing.T) {
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	t.Run("rectangle in region coordinates", func(t *testing.T) {
+		img := solidImage(100, 100, white)
+		region := img.SubImage(image.Rect(50, 50, 100, 100)).(*image.RGBA)
+
+		e := NewAnnotationEditor(color.NRGBA{R: 255, A: 255})
+		e.Tool = ToolRectangle
+		e.Begin(image.Pt(10, 10))
+		e.Move(image.Pt(40, 40))
+		e.End()
+		e.Render(region, false)
+
+		assert.Equal(t, color.RGBA{R: 255, A: 255}, img.RGBAAt(60, 60), "corner drawn offset by the region origin")
+		assert.Equal(t, white, img.RGBAAt(75, 75), "inside stays untouched")
+		assert.Equal(t, white, img.RGBAAt(10, 10), "nothing drawn outside the region")
+	})
+
+	t.Run("swapped channels", func(t *testing.T) {
+		img := solidImage(20, 20, white)
+		e := NewAnnotationEditor(color.NRGBA{R: 255, A: 255})
+		e.Tool = ToolPen
+		e.Width = 6
+		e.Begin(image.Pt(10, 10))
+		e.End()
+		e.Render(img, true)
+		assert.Equal(t, color.RGBA{B: 255, A: 255}, img.RGBAAt(10, 10))
+	})
+
+	t.Run("highlighter blends", func(t *testing.T) {
+		img := solidImage(40, 40, white)
+		e := NewAnnotationEditor(color.NRGBA{A: 255})
+		e.Tool = ToolHighlighter
+		e.Begin(image.Pt(5, 20))
+		e.Move(image.Pt(20, 20))
+		e.Move(image.Pt(35, 20))
+		e.End()
+		e.Render(img, false)
+
+		px := img.RGBAAt(20, 20)
+		assert.Less(t, px.R, uint8(255))
+		assert.Greater(t, px.R, uint8(100), "overlapping stamps are composited once")
+		assert.Equal(t, img.RGBAAt(10, 20), px)
+	})
+
+	t.Run("pixelate averages blocks", func(t *testing.T) {
+		img := solidImage(16, 16, white)
+		for y := 0; y < 16; y++ {
+			for x := 0; x < 16; x += 2 {
+				img.SetRGBA(x, y, color.RGBA{A: 255})
+			}
+		}
+		e := NewAnnotationEditor(testAccent)
+		e.Tool = ToolPixelate
+		e.Width = 1
+		e.Begin(image.Pt(0, 0))
+		e.Move(image.Pt(8, 8))
+		e.End()
+		e.Render(img, false)
+
+		assert.Equal(t, img.RGBAAt(0, 0), img.RGBAAt(7, 7))
+		assert.InDelta(t, 127, int(img.RGBAAt(3, 3).R), 1)
+		assert.Equal(t, uint8(0), img.RGBAAt(8, 9).R, "outside the redaction stays sharp")
+	})
+
+	t.Run("blur smooths edges", func(t *testing.T) {
+		img := solidImage(40, 10, white)
+		for y := 0; y < 10; y++ {
+			for x := 0; x < 20; x++ {
+				img.SetRGBA(x, y, color.RGBA{A: 255})
+			}
+		}
+		e := NewAnnotationEditor(testAccent)
+		e.Tool = ToolBlur
+		e.Begin(image.Pt(0, 0))
+		e.Move(image.Pt(40, 10))
+		e.End()
+		e.Render(img, false)
+
+		edge := img.RGBAAt(20, 5).R
+		assert.Greater(t, edge, uint8(50))
+		assert.Less(t, edge, uint8(205))
+	})
+
+	t.Run("text and steps draw", func(t *testing.T) {
+		img := solidImage(120, 60, white)
+		e := NewAnnotationEditor(color.NRGBA{A: 255})
+		e.Tool = ToolText
+		e.Begin(image.Pt(2, 2))
+		e.TypeRune('W')
+		e.CommitText()
+		e.Tool = ToolStep
+		e.Begin(image.Pt(90, 30))
+		e.Render(img, false)
+
+		assert.NotEqual(t, white, img.RGBAAt(90, 30-16), "step marker filled")
+		dark := 0
+		for y := 2; y < 30; y++ {
+			for x := 2; x < 30; x++ {
+				if img.RGBAAt(x, y).R < 128 {
+					dark++
+				}
+			}
+		}
+		assert.Greater(t, dark, 20, "label glyph rendered")
+	})
+}
@@ -3,106 +3,15 @@ package screenshot
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"os/exec"
 
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/compositor"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/dwl_ipc"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_output_management"
 	wlhelpers "github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/client"
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
 )
 
-type Compositor int
-
-const (
-	CompositorUnknown Compositor = iota
-	CompositorHyprland
-	CompositorSway
-	CompositorNiri
-	CompositorDWL
-	CompositorScroll
-	CompositorMiracle
-)
-
-var detectedCompositor Compositor = -1
-
-func DetectCompositor() Compositor {
-	if detectedCompositor >= 0 {
-		return detectedCompositor
-	}
-
-	hyprlandSig := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
-	niriSocket := os.Getenv("NIRI_SOCKET")
-	swaySocket := os.Getenv("SWAYSOCK")
-	scrollSocket := os.Getenv("SCROLLSOCK")
-	miracleSocket := os.Getenv("MIRACLESOCK")
-
-	switch {
-	case niriSocket != "":
-		if _, err := os.Stat(niriSocket); err == nil {
-			detectedCompositor = CompositorNiri
-			return detectedCompositor
-		}
-	case scrollSocket != "":
-		if _, err := os.Stat(scrollSocket); err == nil {
-			detectedCompositor = CompositorScroll
-			return detectedCompositor
-		}
-	case miracleSocket != "":
-		if _, err := os.Stat(miracleSocket); err == nil {
-			detectedCompositor = CompositorMiracle
-			return detectedCompositor
-		}
-	case swaySocket != "":
-		if _, err := os.Stat(swaySocket); err == nil {
-			detectedCompositor = CompositorSway
-			return detectedCompositor
-		}
-	case hyprlandSig != "":
-		detectedCompositor = CompositorHyprland
-		return detectedCompositor
-	}
-
-	if detectDWLProtocol() {
-		detectedCompositor = CompositorDWL
-		return detectedCompositor
-	}
-
-	detectedCompositor = CompositorUnknown
-	return detectedCompositor
-}
-
-func detectDWLProtocol() bool {
-	display, err := client.Connect("")
-	if err != nil {
-		return false
-	}
-	ctx := display.Context()
-	defer ctx.Close()
-
-	registry, err := display.GetRegistry()
-	if err != nil {
-		return false
-	}
-
-	found := false
-	registry.SetGlobalHandler(func(e client.RegistryGlobalEvent) {
-		if e.Interface == dwl_ipc.ZdwlIpcManagerV2InterfaceName {
-			found = true
-		}
-	})
-
-	if err := wlhelpers.Roundtrip(display, ctx); err != nil {
-		return false
-	}
-
-	return found
-}
-
-func SetCompositorDWL() {
-	detectedCompositor = CompositorDWL
-}
-
 type WindowGeometry struct {
 	X               int32
 	Y               int32
@@ -113,13 +22,14 @@ type WindowGeometry struct {
 	OutputX         int32
 	OutputY         int32
 	OutputTransform int32
+	AppID           string
 }
 
 func GetActiveWindow() (*WindowGeometry, error) {
-	switch DetectCompositor() {
-	case CompositorHyprland:
+	switch compositor.Detect() {
+	case compositor.Hyprland:
 		return getHyprlandActiveWindow()
-	case CompositorDWL:
+	case compositor.DWL:
 		return getDWLActiveWindow()
 	default:
 		return nil, fmt.Errorf("window capture requires Hyprland or DWL")
@@ -127,8 +37,9 @@ func GetActiveWindow() (*WindowGeometry, error) {
 }
 
 type hyprlandWindow struct {
-	At   [2]int32 `json:"at"`
-	Size [2]int32 `json:"size"`
+	At    [2]int32 `json:"at"`
+	Size  [2]int32 `json:"size"`
+	Class string   `json:"class"`
 }
 
 func getHyprlandActiveWindow() (*WindowGeometry, error) {
@@ -151,6 +62,7 @@ func getHyprlandActiveWindow() (*WindowGeometry, error) {
 		Y:      win.At[1],
 		Width:  win.Size[0],
 		Height: win.Size[1],
+		AppID:  win.Class,
 	}, nil
 }
 
@@ -286,6 +198,9 @@ func getMiracleFocusedMonitor() string {
 }
 
 type niriWorkspace struct {
+	ID        uint64 `json:"id"`
+	Idx       int    `json:"idx"`
+	Name      string `json:"name"`
 	Output    string `json:"output"`
 	IsFocused bool   `json:"is_focused"`
 }
@@ -425,18 +340,18 @@ func queryDWLActiveOutput() string {
 }
 
 func GetFocusedMonitor() string {
-	switch DetectCompositor() {
-	case CompositorHyprland:
+	switch compositor.Detect() {
+	case compositor.Hyprland:
 		return getHyprlandFocusedMonitor()
-	case CompositorSway:
+	case compositor.Sway:
 		return getSwayFocusedMonitor()
-	case CompositorScroll:
+	case compositor.Scroll:
 		return getScrollFocusedMonitor()
-	case CompositorMiracle:
+	case compositor.Miracle:
 		return getMiracleFocusedMonitor()
-	case CompositorNiri:
+	case compositor.Niri:
 		return getNiriFocusedMonitor()
-	case CompositorDWL:
+	case compositor.DWL:
 		return getDWLFocusedMonitor()
 	}
 	return ""
@@ -594,6 +509,7 @@ func getDWLActiveWindow() (*WindowGeometry, error) {
 		x, y        int32
 		w, h        int32
 		scalefactor uint32
+		appID       string
 		gotFrame    bool
 	}
 
@@ -624,6 +540,9 @@ func getDWLActiveWindow() (*WindowGeometry, error) {
 		dwlOut.SetScalefactorHandler(func(e dwl_ipc.ZdwlIpcOutputV2ScalefactorEvent) {
 			state.scalefactor = e.Scalefactor
 		})
+		dwlOut.SetAppidHandler(func(e dwl_ipc.ZdwlIpcOutputV2AppidEvent) {
+			state.appID = e.Appid
+		})
 		dwlOut.SetFrameHandler(func(e dwl_ipc.ZdwlIpcOutputV2FrameEvent) {
 			state.gotFrame = true
 		})
@@ -663,6 +582,7 @@ func getDWLActiveWindow() (*WindowGeometry, error) {
 			Height: state.h,
 			Output: state.name,
 			Scale:  scale,
+			AppID:  state.appID,
 		}
 
 		if info, ok := getOutputInfo(state.name); ok {
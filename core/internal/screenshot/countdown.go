@@ -0,0 +1,234 @@
+package screenshot
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_layer_shell"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+const (
+	countdownW     = 200
+	countdownH     = 140
+	countdownDigit = 6
+	// The overlay is taken down this long before the capture so the
+	// compositor has repainted without it.
+	countdownHideLead = 200 * time.Millisecond
+)
+
+type countdownSurface struct {
+	output    *WaylandOutput
+	wlSurface *client.Surface
+	layerSurf *wlr_layer_shell.ZwlrLayerSurfaceV1
+	scale     int
+
+	buf   *ShmBuffer
+	pool  *client.ShmPool
+	wlBuf *client.Buffer
+
+	configured bool
+}
+
+// wait sleeps for delay, showing the remaining seconds on every output when
+// the countdown is enabled and the compositor supports layer shell.
+func (s *Screenshoter) wait(delay time.Duration) {
+	deadline := time.Now().Add(delay)
+
+	if s.config.Countdown && s.layerShell != nil && s.compositor != nil && s.shm != nil {
+		if err := s.countdown(deadline); err != nil {
+			log.Debug("countdown overlay failed", "err", err)
+		}
+	}
+
+	time.Sleep(time.Until(deadline))
+}
+
+func (s *Screenshoter) countdown(deadline time.Time) error {
+	hideAt := deadline.Add(-countdownHideLead)
+	if !time.Now().Before(hideAt) {
+		return nil
+	}
+
+	var surfaces []*countdownSurface
+	defer func() {
+		for _, cs := range surfaces {
+			cs.destroy()
+		}
+		_ = s.roundtrip()
+	}()
+
+	for _, output := range s.GetOutputs() {
+		cs, err := s.createCountdownSurface(output)
+		if err != nil {
+			return fmt.Errorf("output %s: %w", output.name, err)
+		}
+		surfaces = append(surfaces, cs)
+	}
+
+	defer s.ctx.SetReadDeadline(time.Time{})
+	shown := 0
+	for {
+		now := time.Now()
+		if !now.Before(hideAt) {
+			return nil
+		}
+
+		seconds := int(math.Ceil(deadline.Sub(now).Seconds()))
+		if seconds != shown {
+			shown = seconds
+			for _, cs := range surfaces {
+				if err := s.drawCountdown(cs, seconds); err != nil {
+					return err
+				}
+			}
+		}
+
+		next := deadline.Add(-time.Duration(seconds-1) * time.Second)
+		if next.After(hideAt) {
+			next = hideAt
+		}
+		if err := s.ctx.SetReadDeadline(next); err != nil {
+			return fmt.Errorf("set read deadline: %w", err)
+		}
+		if err := s.ctx.Dispatch(); err != nil && !isTimeoutError(err) {
+			return fmt.Errorf("dispatch: %w", err)
+		}
+	}
+}
+
+func (s *Screenshoter) createCountdownSurface(output *WaylandOutput) (*countdownSurface, error) {
+	surface, err := s.compositor.CreateSurface()
+	if err != nil {
+		return nil, fmt.Errorf("create surface: %w", err)
+	}
+
+	cs := &countdownSurface{output: output, wlSurface: surface, scale: max(1, int(output.scale))}
+
+	layerSurf, err := s.layerShell.GetLayerSurface(
+		surface,
+		output.wlOutput,
+		uint32(wlr_layer_shell.ZwlrLayerShellV1LayerOverlay),
+		"dms-screenshot-countdown",
+	)
+	if err != nil {
+		surface.Destroy()
+		return nil, fmt.Errorf("get layer surface: %w", err)
+	}
+	cs.layerSurf = layerSurf
+
+	if err := layerSurf.SetSize(countdownW, countdownH); err != nil {
+		cs.destroy()
+		return nil, fmt.Errorf("set size: %w", err)
+	}
+	if err := layerSurf.SetExclusiveZone(-1); err != nil {
+		cs.destroy()
+		return nil, fmt.Errorf("set exclusive zone: %w", err)
+	}
+
+	// clicks go through to whatever is being set up for the capture
+	if region, err := s.compositor.CreateRegion(); err == nil {
+		_ = surface.SetInputRegion(region)
+		region.Destroy()
+	}
+
+	layerSurf.SetConfigureHandler(func(e wlr_layer_shell.ZwlrLayerSurfaceV1ConfigureEvent) {
+		if err := layerSurf.AckConfigure(e.Serial); err != nil {
+			log.Error("ack configure failed", "err", err)
+			return
+		}
+		cs.configured = true
+	})
+
+	if err := surface.Commit(); err != nil {
+		cs.destroy()
+		return nil, fmt.Errorf("surface commit: %w", err)
+	}
+	if err := s.roundtrip(); err != nil {
+		cs.destroy()
+		return nil, fmt.Errorf("roundtrip: %w", err)
+	}
+	return cs, nil
+}
+
+func (s *Screenshoter) drawCountdown(cs *countdownSurface, seconds int) error {
+	if !cs.configured {
+		return nil
+	}
+
+	w, h := countdownW*cs.scale, countdownH*cs.scale
+	buf, err := CreateShmBuffer(w, h, w*4)
+	if err != nil {
+		return fmt.Errorf("create buffer: %w", err)
+	}
+	renderCountdown(buf, seconds, cs.scale, LoadOverlayStyle())
+
+	pool, err := s.shm.CreatePool(buf.Fd(), int32(buf.Size()))
+	if err != nil {
+		buf.Close()
+		return fmt.Errorf("create pool: %w", err)
+	}
+	wlBuf, err := pool.CreateBuffer(0, int32(w), int32(h), int32(w*4), uint32(FormatARGB8888))
+	if err != nil {
+		pool.Destroy()
+		buf.Close()
+		return fmt.Errorf("create wl_buffer: %w", err)
+	}
+
+	_ = cs.wlSurface.SetBufferScale(int32(cs.scale))
+	_ = cs.wlSurface.Attach(wlBuf, 0, 0)
+	_ = cs.wlSurface.Damage(0, 0, countdownW, countdownH)
+	if err := cs.wlSurface.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	cs.releaseBuffer()
+	cs.buf, cs.pool, cs.wlBuf = buf, pool, wlBuf
+	return nil
+}
+
+func renderCountdown(buf *ShmBuffer, seconds, scale int, style OverlayStyle) {
+	data := buf.Data()
+	w, h := buf.Width, buf.Height
+	format := uint32(FormatARGB8888)
+
+	fillRect(data, buf.Stride, w, h, 0, 0, w, h,
+		style.BackgroundR, style.BackgroundG, style.BackgroundB, 255, format)
+
+	caption := "capture in"
+	captionW := (len(caption)*9 - 1) * scale
+	drawTextScaled(data, buf.Stride, w, h, (w-captionW)/2, 16*scale, caption, scale,
+		style.TextR, style.TextG, style.TextB, format)
+
+	digits := strconv.Itoa(seconds)
+	ds := countdownDigit * scale
+	digitsW := (len(digits)*9 - 1) * ds
+	drawTextScaled(data, buf.Stride, w, h, (w-digitsW)/2, 44*scale, digits, ds,
+		style.AccentR, style.AccentG, style.AccentB, format)
+}
+
+func (cs *countdownSurface) releaseBuffer() {
+	if cs.wlBuf != nil {
+		cs.wlBuf.Destroy()
+	}
+	if cs.pool != nil {
+		cs.pool.Destroy()
+	}
+	if cs.buf != nil {
+		cs.buf.Close()
+	}
+	cs.buf, cs.pool, cs.wlBuf = nil, nil, nil
+}
+
+func (cs *countdownSurface) destroy() {
+	if cs.layerSurf != nil {
+		cs.layerSurf.Destroy()
+	}
+	if cs.wlSurface != nil {
+		cs.wlSurface.Destroy()
+	}
+	cs.releaseBuffer()
+}
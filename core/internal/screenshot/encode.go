@@ -2,6 +2,7 @@ package screenshot
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"image"
 	"image/jpeg"
@@ -22,38 +23,49 @@ func BufferToImage(buf *ShmBuffer) *image.RGBA {
 
 func BufferToImageWithFormat(buf *ShmBuffer, format uint32) *image.RGBA {
 	img := image.NewRGBA(image.Rect(0, 0, buf.Width, buf.Height))
-	data := buf.Data()
+	copyBufferToImage(img, buf, image.Point{}, format, false)
+	return img
+}
 
-	var swapRB bool
-	switch format {
-	case uint32(FormatABGR8888), uint32(FormatXBGR8888):
-		swapRB = false
-	default:
-		swapRB = true
-	}
+// copyBufferToImage converts the pixels of buf starting at origin into dst,
+// forcing them opaque.
+func copyBufferToImage(dst *image.RGBA, buf *ShmBuffer, origin image.Point, format uint32, yInverted bool) {
+	data := buf.Data()
+	swapRB := swapsRB(format)
+	bounds := dst.Bounds()
 
-	for y := 0; y < buf.Height; y++ {
-		srcOff := y * buf.Stride
-		dstOff := y * img.Stride
-		for x := 0; x < buf.Width; x++ {
-			si := srcOff + x*4
+	for y := 0; y < bounds.Dy(); y++ {
+		srcY := origin.Y + y
+		if yInverted {
+			srcY = buf.Height - 1 - srcY
+		}
+		if srcY < 0 || srcY >= buf.Height {
+			continue
+		}
+		srcOff := srcY * buf.Stride
+		dstOff := dst.PixOffset(bounds.Min.X, bounds.Min.Y+y)
+		for x := 0; x < bounds.Dx(); x++ {
+			srcX := origin.X + x
+			if srcX < 0 || srcX >= buf.Width {
+				continue
+			}
+			si := srcOff + srcX*4
 			di := dstOff + x*4
-			if si+3 >= len(data) || di+3 >= len(img.Pix) {
+			if si+3 >= len(data) || di+3 >= len(dst.Pix) {
 				continue
 			}
 			if swapRB {
-				img.Pix[di+0] = data[si+2]
-				img.Pix[di+1] = data[si+1]
-				img.Pix[di+2] = data[si+0]
+				dst.Pix[di+0] = data[si+2]
+				dst.Pix[di+1] = data[si+1]
+				dst.Pix[di+2] = data[si+0]
 			} else {
-				img.Pix[di+0] = data[si+0]
-				img.Pix[di+1] = data[si+1]
-				img.Pix[di+2] = data[si+2]
+				dst.Pix[di+0] = data[si+0]
+				dst.Pix[di+1] = data[si+1]
+				dst.Pix[di+2] = data[si+2]
 			}
-			img.Pix[di+3] = 255
+			dst.Pix[di+3] = 255
 		}
 	}
-	return img
 }
 
 func EncodePNG(w io.Writer, img image.Image) error {
@@ -61,6 +73,82 @@ func EncodePNG(w io.Writer, img image.Image) error {
 	return enc.Encode(w, img)
 }
 
+// EncodeOptions selects the output format and its settings. Compression and
+// Palette only apply to PNG; Metadata is embedded in PNG and WebP when set.
+type EncodeOptions struct {
+	Format      Format
+	Quality     int
+	Compression PNGCompression
+	Palette     PaletteMode
+	Metadata    *Metadata
+}
+
+// EncodeOptions returns the options for saving result with this config.
+func (c Config) EncodeOptions(result *CaptureResult) EncodeOptions {
+	opts := EncodeOptions{
+		Format:      c.Format,
+		Quality:     c.Quality,
+		Compression: c.Compression,
+		Palette:     c.Palette,
+	}
+	if c.Metadata {
+		output := result.Region.Output
+		if output == "" {
+			output = c.OutputName
+		}
+		opts.Metadata = &Metadata{Time: time.Now(), Output: output, AppID: result.AppID}
+	}
+	return opts
+}
+
+func Encode(w io.Writer, img *image.RGBA, opts EncodeOptions) error {
+	switch opts.Format {
+	case FormatJPEG:
+		return EncodeJPEG(w, img, opts.Quality)
+	case FormatPPM:
+		return EncodePPM(w, img)
+	case FormatQOI:
+		return EncodeQOI(w, img)
+	case FormatWebP:
+		if opts.Metadata != nil {
+			return writeWebPMetadata(w, img, opts.Metadata)
+		}
+		return EncodeWebP(w, img)
+	default:
+		return encodePNGWithOptions(w, img, opts)
+	}
+}
+
+var pngCompressionLevels = map[PNGCompression]png.CompressionLevel{
+	PNGCompressionFast:    png.BestSpeed,
+	PNGCompressionDefault: png.DefaultCompression,
+	PNGCompressionBest:    png.BestCompression,
+	PNGCompressionNone:    png.NoCompression,
+}
+
+func encodePNGWithOptions(w io.Writer, img *image.RGBA, opts EncodeOptions) error {
+	var src image.Image = img
+	switch opts.Palette {
+	case PaletteAuto:
+		if pm := exactPalette(img, img.Bounds()); pm != nil {
+			src = pm
+		}
+	case PaletteOn:
+		src = quantizeFrame(img, img.Bounds())
+	}
+
+	enc := png.Encoder{CompressionLevel: pngCompressionLevels[opts.Compression]}
+	if opts.Metadata == nil {
+		return enc.Encode(w, src)
+	}
+
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, src); err != nil {
+		return err
+	}
+	return writePNGMetadata(w, buf.Bytes(), opts.Metadata)
+}
+
 func EncodeJPEG(w io.Writer, img image.Image, quality int) error {
 	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
 }
@@ -90,14 +178,13 @@ func EncodePPM(w io.Writer, img *image.RGBA) error {
 
 func GenerateFilename(format Format) string {
 	t := time.Now()
-	ext := "png"
-	switch format {
-	case FormatJPEG:
-		ext = "jpg"
-	case FormatPPM:
-		ext = "ppm"
-	}
-	return fmt.Sprintf("screenshot_%s.%s", t.Format("2006-01-02_15-04-05"), ext)
+	return fmt.Sprintf("screenshot_%s.%s", t.Format("2006-01-02_15-04-05"), format.Extension())
+}
+
+// GenerateSequenceFilename names the n-th capture of an interval run so the
+// files sort in capture order.
+func GenerateSequenceFilename(format Format, start time.Time, n int) string {
+	return fmt.Sprintf("timelapse_%s_%04d.%s", start.Format("2006-01-02_15-04-05"), n, format.Extension())
 }
 
 func GetOutputDir() string {
@@ -154,19 +241,23 @@ func WriteToFile(buf *ShmBuffer, path string, format Format, quality int) error
 }
 
 func WriteToFileWithFormat(buf *ShmBuffer, path string, format Format, quality int, pixelFormat uint32) error {
+	return WriteToFileWithOptions(buf, path, pixelFormat, EncodeOptions{Format: format, Quality: quality})
+}
+
+func WriteToFileWithOptions(buf *ShmBuffer, path string, pixelFormat uint32, opts EncodeOptions) error {
 	f, err := os.Create(path)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	img := BufferToImageWithFormat(buf, pixelFormat)
-	switch format {
-	case FormatJPEG:
-		return EncodeJPEG(f, img, quality)
-	case FormatPPM:
-		return EncodePPM(f, img)
-	default:
-		return EncodePNG(f, img)
+	bw := bufio.NewWriter(f)
+	if err := Encode(bw, BufferToImageWithFormat(buf, pixelFormat), opts); err != nil {
+		f.Close()
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
 	}
+	return f.Close()
 }
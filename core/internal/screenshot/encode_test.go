@@ -0,0 +1,220 @@
+package screenshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+// decodeQOI is a minimal reference decoder for the encoder tests.
+func decodeQOI(data []byte) (*image.RGBA, error) {
+	if len(data) < 14+8 || string(data[:4]) != "qoif" {
+		return nil, errors.New("not a qoi file")
+	}
+	w := int(binary.BigEndian.Uint32(data[4:]))
+	h := int(binary.BigEndian.Uint32(data[8:]))
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	var index [64]qoiPixel
+	px := qoiPixel{a: 255}
+	p, run := 14, 0
+	for i := 0; i < w*h; i++ {
+		switch {
+		case run > 0:
+			run--
+		case p >= len(data)-8:
+			return nil, errors.New("truncated")
+		default:
+			b := data[p]
+			p++
+			switch {
+			case b == qoiOpRGB:
+				px.r, px.g, px.b = data[p], data[p+1], data[p+2]
+				p += 3
+			case b == qoiOpRGBA:
+				px = qoiPixel{data[p], data[p+1], data[p+2], data[p+3]}
+				p += 4
+			case b&0xc0 == qoiOpIndex:
+				px = index[b]
+			case b&0xc0 == qoiOpDiff:
+				px.r += (b>>4)&3 - 2
+				px.g += (b>>2)&3 - 2
+				px.b += b&3 - 2
+			case b&0xc0 == qoiOpLuma:
+				dg := b&0x3f - 32
+				px.r += dg + data[p]>>4 - 8
+				px.g += dg
+				px.b += dg + data[p]&0x0f - 8
+				p++
+			default:
+				run = int(b & 0x3f)
+			}
+			index[px.hash()] = px
+		}
+		copy(img.Pix[i*4:], []uint8{px.r, px.g, px.b, px.a})
+	}
+	if !bytes.Equal(data[p:], qoiEndMarker) {
+		return nil, errors.New("missing end marker")
+	}
+	return img, nil
+}
+
+func TestEncodeQOI(t *testing.T) {
+	gradient := image.NewRGBA(image.Rect(0, 0, 256, 9))
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 256; x++ {
+			gradient.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(255 - x), B: uint8(x * y), A: 255})
+		}
+	}
+
+	tests := []struct {
+		name string
+		img  *image.RGBA
+	}{
+		{"flat ui", uiImage(200, 40)},
+		{"noise", noiseImage(33, 17)},
+		{"gradient", gradient},
+		{"sub image", uiImage(64, 40).SubImage(image.Rect(3, 3, 20, 30)).(*image.RGBA)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, EncodeQOI(&buf, tt.img))
+
+			decoded, err := decodeQOI(buf.Bytes())
+			require.NoError(t, err)
+			assert.Equal(t, decodeRGBA(t, tt.img).Pix, decoded.Pix)
+		})
+	}
+
+	t.Run("flat content compresses", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeQOI(&buf, uiImage(400, 300)))
+		assert.Less(t, buf.Len(), 5000)
+	})
+}
+
+func TestEncodePNGPalette(t *testing.T) {
+	ui := uiImage(64, 40)
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, ui, EncodeOptions{Format: FormatPNG, Palette: PaletteAuto}))
+	decoded, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.IsType(t, &image.Paletted{}, decoded)
+	assert.Equal(t, ui.Pix, decodeRGBA(t, decoded).Pix, "few colors stay lossless")
+
+	noise := noiseImage(40, 40)
+	buf.Reset()
+	require.NoError(t, Encode(&buf, noise, EncodeOptions{Format: FormatPNG, Palette: PaletteAuto}))
+	decoded, err = png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, noise.Pix, decodeRGBA(t, decoded).Pix, "auto keeps true color for photos")
+
+	buf.Reset()
+	require.NoError(t, Encode(&buf, noise, EncodeOptions{Format: FormatPNG, Palette: PaletteOn}))
+	decoded, err = png.Decode(&buf)
+	require.NoError(t, err)
+	assert.IsType(t, &image.Paletted{}, decoded)
+}
+
+func TestEncodePNGCompression(t *testing.T) {
+	img := uiImage(400, 300)
+	sizes := make(map[PNGCompression]int)
+	for _, c := range []PNGCompression{PNGCompressionNone, PNGCompressionFast, PNGCompressionBest} {
+		var buf bytes.Buffer
+		require.NoError(t, Encode(&buf, img, EncodeOptions{Format: FormatPNG, Compression: c}))
+		sizes[c] = buf.Len()
+	}
+	assert.Greater(t, sizes[PNGCompressionNone], sizes[PNGCompressionFast])
+	assert.LessOrEqual(t, sizes[PNGCompressionBest], sizes[PNGCompressionFast])
+}
+
+func pngTextChunks(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	require.True(t, bytes.HasPrefix(data, pngSignature))
+	data = data[len(pngSignature):]
+
+	chunks := make(map[string]string)
+	for len(data) >= 12 {
+		n := int(binary.BigEndian.Uint32(data))
+		typ, payload := string(data[4:8]), data[8:8+n]
+		switch typ {
+		case "tEXt":
+			key, value, _ := strings.Cut(string(payload), "\x00")
+			chunks[key] = value
+		case "iTXt":
+			key, rest, _ := strings.Cut(string(payload), "\x00")
+			chunks[key] = rest[4:]
+		}
+		data = data[12+n:]
+	}
+	return chunks
+}
+
+func TestEncodeMetadata(t *testing.T) {
+	img := uiImage(64, 40)
+	meta := &Metadata{
+		Time:   time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC),
+		Output: "DP-1",
+		AppID:  `org.example.<"Ünïcode">`,
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, img, EncodeOptions{Format: FormatPNG, Palette: PaletteAuto, Metadata: meta}))
+
+	chunks := pngTextChunks(t, buf.Bytes())
+	assert.Equal(t, "DankMaterialShell", chunks["Software"])
+	assert.Equal(t, "Sat, 14 Mar 2026 15:09:26 +0000", chunks["Creation Time"])
+	assert.Equal(t, "DP-1", chunks["Output"])
+	assert.NotContains(t, chunks, "App ID", "tEXt cannot hold the non-Latin-1 app id")
+
+	xmp := chunks["XML:com.adobe.xmp"]
+	assert.Contains(t, xmp, `xmp:CreateDate="2026-03-14T15:09:26Z"`)
+	assert.Contains(t, xmp, `dms:Output="DP-1"`)
+	assert.Contains(t, xmp, `dms:AppID="org.example.&lt;&#34;Ünïcode&#34;&gt;"`)
+
+	decoded, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, img.Pix, decodeRGBA(t, decoded).Pix)
+
+	buf.Reset()
+	require.NoError(t, Encode(&buf, img, EncodeOptions{Format: FormatWebP, Metadata: meta}))
+	assert.Equal(t, "VP8X", string(buf.Bytes()[12:16]))
+	assert.Equal(t, buf.Len()-8, int(binary.LittleEndian.Uint32(buf.Bytes()[4:])))
+	assert.Contains(t, buf.String(), "XMP ")
+
+	decoded, err = webp.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, img.Pix, decodeRGBA(t, decoded).Pix)
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{
+		"png": FormatPNG, "JPG": FormatJPEG, "jpeg": FormatJPEG,
+		"ppm": FormatPPM, "webp": FormatWebP, "qoi": FormatQOI,
+	} {
+		got, err := ParseFormat(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := ParseFormat("avif")
+	assert.ErrorContains(t, err, "cgo")
+	_, err = ParseFormat("bmp")
+	assert.Error(t, err)
+
+	assert.Equal(t, "image/webp", historyMimeType("/tmp/a.webp"))
+	assert.Equal(t, "image/png", historyMimeType("/tmp/a.tiff"))
+}
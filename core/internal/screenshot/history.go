@@ -0,0 +1,306 @@
+package screenshot
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/image/draw"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/fileindex"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+)
+
+const (
+	maxHistoryEntries = 200
+	historyThumbSize  = 256
+)
+
+// HistoryEntry describes one capture. Captures that were only copied to the
+// clipboard keep a copy of the image in the history dir (Stored) so they can
+// be re-copied later.
+type HistoryEntry struct {
+	ID        string    `json:"id"`
+	Time      time.Time `json:"time"`
+	Path      string    `json:"path"`
+	Mode      string    `json:"mode"`
+	Output    string    `json:"output,omitempty"`
+	Region    Region    `json:"region"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	Size      int64     `json:"size"`
+	Copied    bool      `json:"copied"`
+	Stored    bool      `json:"stored,omitempty"`
+	Thumbnail string    `json:"thumbnail,omitempty"`
+	Missing   bool      `json:"missing,omitempty"`
+}
+
+type PruneOptions struct {
+	MaxAge      time.Duration
+	MaxSize     int64
+	DeleteFiles bool
+}
+
+type History struct {
+	dir   string
+	index *fileindex.Index[HistoryEntry]
+}
+
+func DefaultHistoryDir() string {
+	return filepath.Join(utils.XDGStateHome(), "DankMaterialShell", "screenshots")
+}
+
+func DefaultHistory() *History {
+	return NewHistory(DefaultHistoryDir())
+}
+
+func NewHistory(dir string) *History {
+	return &History{dir: dir, index: fileindex.New[HistoryEntry](dir, "history.json")}
+}
+
+func (h *History) Dir() string {
+	return h.dir
+}
+
+func (h *History) IndexPath() string {
+	return h.index.Path()
+}
+
+// Add records a capture and writes its thumbnail. An entry without a path
+// gets the image stored in the history dir.
+func (h *History) Add(entry HistoryEntry, img image.Image) (HistoryEntry, error) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.ID = fileindex.ID(entry.Time, entry.Path)
+	entry.Width = img.Bounds().Dx()
+	entry.Height = img.Bounds().Dy()
+
+	unlock, err := h.index.Lock()
+	if err != nil {
+		return entry, err
+	}
+	defer unlock()
+
+	if entry.Path == "" {
+		entry.Path = filepath.Join(h.dir, "images", entry.ID+".png")
+		entry.Stored = true
+		if err := writeHistoryPNG(entry.Path, img); err != nil {
+			return entry, fmt.Errorf("store image: %w", err)
+		}
+	}
+	if info, err := os.Stat(entry.Path); err == nil {
+		entry.Size = info.Size()
+	}
+
+	entry.Thumbnail = filepath.Join(h.dir, "thumbs", entry.ID+".png")
+	if err := writeHistoryPNG(entry.Thumbnail, thumbnail(img, historyThumbSize)); err != nil {
+		entry.Thumbnail = ""
+	}
+
+	entries, err := h.index.Load()
+	if err != nil {
+		return entry, err
+	}
+	entries = append(entries, entry)
+
+	if len(entries) > maxHistoryEntries {
+		for _, e := range entries[:len(entries)-maxHistoryEntries] {
+			h.removeFiles(e, false)
+		}
+		entries = entries[len(entries)-maxHistoryEntries:]
+	}
+
+	return entry, h.index.Save(entries)
+}
+
+// List returns all entries, newest first.
+func (h *History) List() ([]HistoryEntry, error) {
+	entries, err := h.index.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]HistoryEntry, len(entries))
+	for i, e := range entries {
+		if _, err := os.Stat(e.Path); err != nil {
+			e.Missing = true
+		}
+		result[len(entries)-1-i] = e
+	}
+	return result, nil
+}
+
+// Get finds an entry by unique id prefix. An empty ref or "latest" returns
+// the most recent capture.
+func (h *History) Get(ref string) (HistoryEntry, error) {
+	entries, err := h.List()
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	if len(entries) == 0 {
+		return HistoryEntry{}, fmt.Errorf("no screenshots in history")
+	}
+
+	if ref == "" || ref == "latest" {
+		return entries[0], nil
+	}
+
+	var found *HistoryEntry
+	for i := range entries {
+		if !strings.HasPrefix(entries[i].ID, ref) {
+			continue
+		}
+		if found != nil {
+			return HistoryEntry{}, fmt.Errorf("screenshot %q is ambiguous", ref)
+		}
+		found = &entries[i]
+	}
+	if found == nil {
+		return HistoryEntry{}, fmt.Errorf("screenshot %q not found", ref)
+	}
+	return *found, nil
+}
+
+// ReadImage returns the image file of an entry with its mime type.
+func (h *History) ReadImage(ref string) (HistoryEntry, []byte, string, error) {
+	entry, err := h.Get(ref)
+	if err != nil {
+		return entry, nil, "", err
+	}
+	data, err := os.ReadFile(entry.Path)
+	if err != nil {
+		return entry, nil, "", err
+	}
+	return entry, data, historyMimeType(entry.Path), nil
+}
+
+func (h *History) MarkCopied(id string) error {
+	return h.index.Update(func(entries []HistoryEntry) ([]HistoryEntry, error) {
+		for i := range entries {
+			if entries[i].ID == id {
+				entries[i].Copied = true
+				return entries, nil
+			}
+		}
+		return nil, fmt.Errorf("screenshot %q not found", id)
+	})
+}
+
+// Delete removes an entry. The screenshot file itself is only removed when
+// deleteFile is set or the image lives in the history dir.
+func (h *History) Delete(ref string, deleteFile bool) (HistoryEntry, error) {
+	entry, err := h.Get(ref)
+	if err != nil {
+		return entry, err
+	}
+
+	err = h.index.Update(func(entries []HistoryEntry) ([]HistoryEntry, error) {
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.ID == entry.ID {
+				h.removeFiles(e, deleteFile)
+				continue
+			}
+			kept = append(kept, e)
+		}
+		return kept, nil
+	})
+	return entry, err
+}
+
+// Prune drops entries whose file is gone, entries older than MaxAge and then
+// the oldest entries until the total size fits MaxSize. It returns the
+// removed entries.
+func (h *History) Prune(opts PruneOptions) ([]HistoryEntry, error) {
+	var removed []HistoryEntry
+
+	err := h.index.Update(func(entries []HistoryEntry) ([]HistoryEntry, error) {
+		now := time.Now()
+		var kept []HistoryEntry
+		var total int64
+		for _, e := range entries {
+			_, statErr := os.Stat(e.Path)
+			if statErr != nil || (opts.MaxAge > 0 && now.Sub(e.Time) > opts.MaxAge) {
+				removed = append(removed, e)
+				continue
+			}
+			kept = append(kept, e)
+			total += e.Size
+		}
+
+		if opts.MaxSize > 0 {
+			for len(kept) > 0 && total > opts.MaxSize {
+				removed = append(removed, kept[0])
+				total -= kept[0].Size
+				kept = kept[1:]
+			}
+		}
+
+		for _, e := range removed {
+			h.removeFiles(e, opts.DeleteFiles)
+		}
+		return kept, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Time.After(removed[j].Time) })
+	return removed, nil
+}
+
+func (h *History) removeFiles(e HistoryEntry, deleteFile bool) {
+	if e.Thumbnail != "" {
+		os.Remove(e.Thumbnail)
+	}
+	if e.Stored || deleteFile {
+		os.Remove(e.Path)
+	}
+}
+
+func historyMimeType(path string) string {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	format, err := ParseFormat(ext)
+	if err != nil {
+		return "image/png"
+	}
+	return format.MimeType()
+}
+
+func writeHistoryPNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := EncodePNG(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func thumbnail(img image.Image, maxSize int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSize && h <= maxSize {
+		return img
+	}
+
+	if w > h {
+		w, h = maxSize, max(1, h*maxSize/w)
+	} else {
+		w, h = max(1, w*maxSize/h), maxSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
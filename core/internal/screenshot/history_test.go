@@ -0,0 +1,153 @@
+package screenshot
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCapture(t *testing.T, dir, name string, size int) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, make([]byte, size), 0o644))
+	return path
+}
+
+func TestHistoryAddAndGet(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHistory(filepath.Join(dir, "history"))
+	img := image.NewRGBA(image.Rect(0, 0, 800, 200))
+
+	saved := writeCapture(t, dir, "a.png", 100)
+	first, err := h.Add(HistoryEntry{Path: saved, Mode: "region", Output: "DP-1", Region: Region{X: 10, Y: 20, Width: 800, Height: 200}}, img)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), first.Size)
+	assert.Equal(t, 800, first.Width)
+	assert.False(t, first.Stored)
+
+	thumb, err := os.Open(first.Thumbnail)
+	require.NoError(t, err)
+	cfg, _, err := image.DecodeConfig(thumb)
+	thumb.Close()
+	require.NoError(t, err)
+	assert.Equal(t, historyThumbSize, cfg.Width)
+	assert.Equal(t, 64, cfg.Height)
+
+	second, err := h.Add(HistoryEntry{Mode: "full", Copied: true}, img)
+	require.NoError(t, err)
+	assert.True(t, second.Stored, "clipboard-only captures keep a copy")
+	assert.FileExists(t, second.Path)
+
+	entries, err := h.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, second.ID, entries[0].ID, "newest first")
+
+	latest, err := h.Get("")
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, latest.ID)
+
+	got, err := h.Get(first.ID[:6])
+	require.NoError(t, err)
+	assert.Equal(t, "DP-1", got.Output)
+
+	_, err = h.Get("zzzz")
+	assert.Error(t, err)
+
+	_, data, mimeType, err := h.ReadImage(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", mimeType)
+	assert.NotEmpty(t, data)
+
+	require.NoError(t, h.MarkCopied(first.ID))
+	got, _ = h.Get(first.ID)
+	assert.True(t, got.Copied)
+}
+
+func TestHistoryDelete(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHistory(filepath.Join(dir, "history"))
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+
+	kept := writeCapture(t, dir, "kept.png", 10)
+	removed := writeCapture(t, dir, "removed.png", 10)
+	a, err := h.Add(HistoryEntry{Path: kept}, img)
+	require.NoError(t, err)
+	b, err := h.Add(HistoryEntry{Path: removed}, img)
+	require.NoError(t, err)
+
+	_, err = h.Delete(a.ID, false)
+	require.NoError(t, err)
+	assert.FileExists(t, kept, "user files stay unless asked")
+	assert.NoFileExists(t, a.Thumbnail)
+
+	_, err = h.Delete(b.ID, true)
+	require.NoError(t, err)
+	assert.NoFileExists(t, removed)
+
+	entries, err := h.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestHistoryPrune(t *testing.T) {
+	dir := t.TempDir()
+	h := NewHistory(filepath.Join(dir, "history"))
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	now := time.Now()
+
+	add := func(name string, size int, age time.Duration) HistoryEntry {
+		e, err := h.Add(HistoryEntry{Path: writeCapture(t, dir, name, size), Time: now.Add(-age)}, img)
+		require.NoError(t, err)
+		return e
+	}
+
+	old := add("old.png", 100, 40*24*time.Hour)
+	gone := add("gone.png", 100, time.Hour)
+	big := add("big.png", 300, 2*time.Hour)
+	recent := add("recent.png", 200, time.Minute)
+	require.NoError(t, os.Remove(gone.Path))
+
+	removed, err := h.Prune(PruneOptions{MaxAge: 30 * 24 * time.Hour, MaxSize: 250})
+	require.NoError(t, err)
+
+	var ids []string
+	for _, e := range removed {
+		ids = append(ids, e.ID)
+	}
+	assert.ElementsMatch(t, []string{old.ID, gone.ID, big.ID}, ids)
+	assert.FileExists(t, old.Path)
+
+	entries, err := h.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, recent.ID, entries[0].ID)
+
+	_, err = h.Prune(PruneOptions{MaxSize: 1, DeleteFiles: true})
+	require.NoError(t, err)
+	assert.NoFileExists(t, recent.Path)
+}
+
+func TestHistoryLimit(t *testing.T) {
+	h := NewHistory(t.TempDir())
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+
+	var first HistoryEntry
+	for i := range maxHistoryEntries + 5 {
+		e, err := h.Add(HistoryEntry{Time: time.Unix(int64(i), 0)}, img)
+		require.NoError(t, err)
+		if i == 0 {
+			first = e
+		}
+	}
+
+	entries, err := h.List()
+	require.NoError(t, err)
+	assert.Len(t, entries, maxHistoryEntries)
+	assert.NoFileExists(t, first.Path, "stored images are dropped with their entry")
+}
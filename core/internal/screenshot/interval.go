@@ -0,0 +1,75 @@
+package screenshot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+// RunInterval captures every interval until count captures were taken (0 for
+// no limit) or stop is closed, and returns the number of captures. Only the
+// first capture honours the delay, and an interactively selected region is
+// reused for the following ones. onCapture owns the result buffer.
+func RunInterval(config Config, every time.Duration, count int, stop <-chan struct{}, onCapture func(n int, result *CaptureResult) error) (int, error) {
+	capture := func(cfg Config) (*CaptureResult, error) {
+		return New(cfg).Run()
+	}
+	return runInterval(config, every, count, stop, capture, onCapture)
+}
+
+func runInterval(config Config, every time.Duration, count int, stop <-chan struct{}, capture func(Config) (*CaptureResult, error), onCapture func(int, *CaptureResult) error) (int, error) {
+	if every <= 0 {
+		return 0, fmt.Errorf("interval must be positive")
+	}
+
+	var start time.Time
+	taken, slot := 0, 0
+	for {
+		result, err := capture(config)
+		switch {
+		case err != nil && taken == 0:
+			return 0, err
+		case err != nil:
+			// a window closing mid time-lapse should not end the run
+			log.Warn("interval capture failed", "err", err)
+		case result == nil && taken == 0:
+			return 0, nil
+		case result != nil:
+			taken++
+			if err := onCapture(taken, result); err != nil {
+				return taken, err
+			}
+		}
+
+		if count > 0 && taken >= count {
+			return taken, nil
+		}
+
+		if start.IsZero() {
+			start = time.Now()
+			config.Delay = 0
+			if config.Mode == ModeRegion {
+				config.Mode = ModeLastRegion
+			}
+		}
+
+		var next time.Time
+		next, slot = nextSlot(start, every, slot, time.Now())
+		select {
+		case <-stop:
+			return taken, nil
+		case <-time.After(time.Until(next)):
+		}
+	}
+}
+
+// nextSlot returns the next capture time on the start + n*every grid,
+// skipping slots that a slow capture already overran.
+func nextSlot(start time.Time, every time.Duration, slot int, now time.Time) (time.Time, int) {
+	slot++
+	if behind := int(now.Sub(start)/every) + 1; behind > slot {
+		slot = behind
+	}
+	return start.Add(time.Duration(slot) * every), slot
+}
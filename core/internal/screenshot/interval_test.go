@@ -0,0 +1,107 @@
+package screenshot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fakeCapture() *CaptureResult {
+	buf, _ := CreateShmBuffer(1, 1, 4)
+	return &CaptureResult{Buffer: buf}
+}
+
+func TestNextSlot(t *testing.T) {
+	start := time.Unix(1000, 0)
+	every := 10 * time.Second
+
+	next, slot := nextSlot(start, every, 0, start.Add(2*time.Second))
+	assert.Equal(t, 1, slot)
+	assert.Equal(t, start.Add(10*time.Second), next)
+
+	next, slot = nextSlot(start, every, 1, start.Add(35*time.Second))
+	assert.Equal(t, 4, slot, "slots overrun by a slow capture are skipped")
+	assert.Equal(t, start.Add(40*time.Second), next)
+}
+
+func TestRunInterval(t *testing.T) {
+	var configs []Config
+	capture := func(cfg Config) (*CaptureResult, error) {
+		configs = append(configs, cfg)
+		if len(configs) == 2 {
+			return nil, errors.New("window went away")
+		}
+		return fakeCapture(), nil
+	}
+
+	var seen []int
+	onCapture := func(n int, result *CaptureResult) error {
+		result.Buffer.Close()
+		seen = append(seen, n)
+		return nil
+	}
+
+	config := DefaultConfig()
+	config.Delay = time.Second
+	taken, err := runInterval(config, time.Millisecond, 3, nil, capture, onCapture)
+	require.NoError(t, err)
+	assert.Equal(t, 3, taken)
+	assert.Equal(t, []int{1, 2, 3}, seen)
+	require.Len(t, configs, 4)
+
+	assert.Equal(t, ModeRegion, configs[0].Mode)
+	assert.Equal(t, time.Second, configs[0].Delay)
+	for _, cfg := range configs[1:] {
+		assert.Equal(t, ModeLastRegion, cfg.Mode, "the selected region is reused")
+		assert.Zero(t, cfg.Delay)
+	}
+}
+
+func TestRunIntervalStops(t *testing.T) {
+	stop := make(chan struct{})
+	capture := func(Config) (*CaptureResult, error) { return fakeCapture(), nil }
+	onCapture := func(n int, result *CaptureResult) error {
+		result.Buffer.Close()
+		if n == 1 {
+			close(stop)
+		}
+		return nil
+	}
+
+	taken, err := runInterval(DefaultConfig(), time.Hour, 0, stop, capture, onCapture)
+	require.NoError(t, err)
+	assert.Equal(t, 1, taken, "stopping does not wait for the next slot")
+
+	_, err = runInterval(DefaultConfig(), time.Hour, 0, nil, func(Config) (*CaptureResult, error) {
+		return nil, errors.New("no screencopy")
+	}, onCapture)
+	assert.Error(t, err, "a failing first capture ends the run")
+
+	taken, err = runInterval(DefaultConfig(), time.Hour, 0, nil, func(Config) (*CaptureResult, error) {
+		return nil, nil
+	}, onCapture)
+	assert.NoError(t, err, "cancelling the selection is not an error")
+	assert.Zero(t, taken)
+}
+
+func TestRenderCountdown(t *testing.T) {
+	buf, err := CreateShmBuffer(countdownW, countdownH, countdownW*4)
+	require.NoError(t, err)
+	defer buf.Close()
+
+	style := DefaultOverlayStyle
+	renderCountdown(buf, 3, 1, style)
+
+	data := buf.Data()
+	accent := 0
+	for i := 0; i+3 < len(data); i += 4 {
+		if data[i] == style.AccentB && data[i+1] == style.AccentG && data[i+2] == style.AccentR {
+			accent++
+		}
+		assert.Equal(t, uint8(255), data[i+3])
+	}
+	assert.Greater(t, accent, 500, "the digit is drawn scaled up")
+}
@@ -0,0 +1,133 @@
+package screenshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"time"
+)
+
+const metadataSoftware = "DankMaterialShell"
+
+// Metadata describes a capture. It is embedded as PNG tEXt and XMP chunks
+// and as a WebP XMP chunk; the other formats have nowhere to put it.
+type Metadata struct {
+	Time   time.Time
+	Output string
+	AppID  string
+}
+
+func (m *Metadata) textChunks() [][2]string {
+	chunks := [][2]string{
+		{"Software", metadataSoftware},
+		{"Creation Time", m.Time.Format(time.RFC1123Z)},
+	}
+	if m.Output != "" {
+		chunks = append(chunks, [2]string{"Output", m.Output})
+	}
+	if m.AppID != "" {
+		chunks = append(chunks, [2]string{"App ID", m.AppID})
+	}
+	return chunks
+}
+
+func (m *Metadata) xmp() []byte {
+	var b bytes.Buffer
+	attr := func(name, value string) {
+		if value == "" {
+			return
+		}
+		fmt.Fprintf(&b, "\n    %s=\"", name)
+		xml.EscapeText(&b, []byte(value))
+		b.WriteByte('"')
+	}
+
+	b.WriteString("<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:dms="https://danklinux.com/ns/screenshot/1.0/"`)
+	attr("xmp:CreatorTool", metadataSoftware)
+	attr("xmp:CreateDate", m.Time.Format(time.RFC3339))
+	attr("dms:Output", m.Output)
+	attr("dms:AppID", m.AppID)
+	b.WriteString(`/>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="r"?>`)
+	return b.Bytes()
+}
+
+// writePNGMetadata copies a PNG stream to w with the metadata chunks
+// inserted after IHDR.
+func writePNGMetadata(w io.Writer, data []byte, m *Metadata) error {
+	if !bytes.HasPrefix(data, pngSignature) || len(data) < len(pngSignature)+25 {
+		return fmt.Errorf("png: not a png")
+	}
+	headerEnd := len(pngSignature) + 25 // IHDR is always first and 13 bytes long
+
+	if _, err := w.Write(data[:headerEnd]); err != nil {
+		return err
+	}
+	for _, kv := range m.textChunks() {
+		// tEXt is Latin-1; anything else is only in the XMP packet
+		if !isPrintableASCII(kv[1]) {
+			continue
+		}
+		if err := writePNGChunk(w, "tEXt", []byte(kv[0]+"\x00"+kv[1])); err != nil {
+			return err
+		}
+	}
+
+	// uncompressed iTXt with empty language and translated keyword
+	itxt := append([]byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00"), m.xmp()...)
+	if err := writePNGChunk(w, "iTXt", itxt); err != nil {
+		return err
+	}
+
+	_, err := w.Write(data[headerEnd:])
+	return err
+}
+
+// writeWebPMetadata writes a lossless WebP in the extended format so it can
+// carry an XMP chunk.
+func writeWebPMetadata(w io.Writer, img *image.RGBA, m *Metadata) error {
+	data, err := EncodeVP8L(img)
+	if err != nil {
+		return err
+	}
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x04 // XMP
+	putUint24(vp8x[4:], uint32(img.Bounds().Dx()-1))
+	putUint24(vp8x[7:], uint32(img.Bounds().Dy()-1))
+	xmp := m.xmp()
+
+	var hdr [12]byte
+	copy(hdr[:4], "RIFF")
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(4+riffChunkSize(vp8x)+riffChunkSize(data)+riffChunkSize(xmp)))
+	copy(hdr[8:], "WEBP")
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	if err := writeRIFFChunk(w, "VP8X", vp8x); err != nil {
+		return err
+	}
+	if err := writeRIFFChunk(w, "VP8L", data); err != nil {
+		return err
+	}
+	return writeRIFFChunk(w, "XMP ", xmp)
+}
+
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/godbus/dbus/v5"
@@ -19,6 +20,7 @@ const (
 )
 
 type NotifyResult struct {
+	Summary   string
 	FilePath  string
 	Clipboard bool
 	ImageData []byte
@@ -62,7 +64,10 @@ func SendNotification(result NotifyResult) {
 		hints["image_path"] = dbus.MakeVariant(result.FilePath)
 	}
 
-	summary := "Screenshot captured"
+	summary := result.Summary
+	if summary == "" {
+		summary = "Screenshot captured"
+	}
 	body := ""
 	if result.Clipboard && result.FilePath != "" {
 		body = fmt.Sprintf("Copied to clipboard\n%s", filepath.Base(result.FilePath))
@@ -135,43 +140,79 @@ func RunNotifyActionListener(args []string) {
 		return
 	}
 
+	if action, _ := waitNotificationAction(conn, uint32(notificationID)); action != "" {
+		OpenFile(filePath)
+	}
+}
+
+// NotifyAction shows a notification with the given action key/label pairs
+// and blocks until one is invoked or the notification is closed. It returns
+// the invoked action key, or "" when dismissed.
+func NotifyAction(summary, body string, actions []string, timeout time.Duration) (string, error) {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return "", err
+	}
+
+	call := conn.Object(notifyDest, notifyPath).Call(
+		notifyInterface+".Notify",
+		0,
+		"DMS",
+		uint32(0),
+		"",
+		summary,
+		body,
+		actions,
+		map[string]dbus.Variant{},
+		int32(timeout.Milliseconds()),
+	)
+	if call.Err != nil {
+		return "", call.Err
+	}
+
+	var notificationID uint32
+	if err := call.Store(&notificationID); err != nil {
+		return "", err
+	}
+	return waitNotificationAction(conn, notificationID)
+}
+
+func waitNotificationAction(conn *dbus.Conn, notificationID uint32) (string, error) {
 	if err := conn.AddMatchSignal(
 		dbus.WithMatchObjectPath(notifyPath),
 		dbus.WithMatchInterface(notifyInterface),
 	); err != nil {
-		return
+		return "", err
 	}
 
 	signals := make(chan *dbus.Signal, 10)
 	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
 
 	for sig := range signals {
+		if len(sig.Body) < 1 {
+			continue
+		}
+		id, ok := sig.Body[0].(uint32)
+		if !ok || id != notificationID {
+			continue
+		}
+
 		switch sig.Name {
 		case notifyInterface + ".ActionInvoked":
 			if len(sig.Body) < 2 {
 				continue
 			}
-			id, ok := sig.Body[0].(uint32)
-			if !ok || id != uint32(notificationID) {
-				continue
-			}
-			openFile(filePath)
-			return
-
+			action, _ := sig.Body[1].(string)
+			return action, nil
 		case notifyInterface + ".NotificationClosed":
-			if len(sig.Body) < 1 {
-				continue
-			}
-			id, ok := sig.Body[0].(uint32)
-			if !ok || id != uint32(notificationID) {
-				continue
-			}
-			return
+			return "", nil
 		}
 	}
+	return "", nil
 }
 
-func openFile(filePath string) {
+func OpenFile(filePath string) {
 	cmd := exec.Command("xdg-open", filePath)
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		Setsid: true,
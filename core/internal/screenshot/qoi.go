@@ -0,0 +1,96 @@
+package screenshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"image"
+	"io"
+)
+
+// QOI, the "Quite OK Image" format (https://qoiformat.org). It compresses
+// flat UI content about as well as fast PNG at a fraction of the cost.
+
+const (
+	qoiOpIndex = 0x00
+	qoiOpDiff  = 0x40
+	qoiOpLuma  = 0x80
+	qoiOpRun   = 0xc0
+	qoiOpRGB   = 0xfe
+	qoiOpRGBA  = 0xff
+)
+
+var qoiEndMarker = []byte{0, 0, 0, 0, 0, 0, 0, 1}
+
+type qoiPixel struct{ r, g, b, a uint8 }
+
+func (p qoiPixel) hash() int {
+	return (int(p.r)*3 + int(p.g)*5 + int(p.b)*7 + int(p.a)*11) % 64
+}
+
+// EncodeQOI writes img as an opaque sRGB QOI file.
+func EncodeQOI(w io.Writer, img *image.RGBA) error {
+	bounds := img.Bounds()
+	bw := bufio.NewWriter(w)
+
+	var hdr [14]byte
+	copy(hdr[:4], "qoif")
+	binary.BigEndian.PutUint32(hdr[4:], uint32(bounds.Dx()))
+	binary.BigEndian.PutUint32(hdr[8:], uint32(bounds.Dy()))
+	hdr[12] = 3 // RGB
+	hdr[13] = 0 // sRGB with linear alpha
+	bw.Write(hdr[:])
+
+	var index [64]qoiPixel
+	prev := qoiPixel{a: 255}
+	run := 0
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(bounds.Min.X, y):]
+		for x := 0; x < bounds.Dx(); x++ {
+			px := qoiPixel{row[x*4], row[x*4+1], row[x*4+2], 255}
+
+			if px == prev {
+				run++
+				if run == 62 {
+					bw.WriteByte(qoiOpRun | byte(run-1))
+					run = 0
+				}
+				continue
+			}
+			if run > 0 {
+				bw.WriteByte(qoiOpRun | byte(run-1))
+				run = 0
+			}
+
+			h := px.hash()
+			if index[h] == px {
+				bw.WriteByte(qoiOpIndex | byte(h))
+				prev = px
+				continue
+			}
+			index[h] = px
+
+			dr := int8(px.r - prev.r)
+			dg := int8(px.g - prev.g)
+			db := int8(px.b - prev.b)
+			drg, dbg := dr-dg, db-dg
+
+			switch {
+			case dr >= -2 && dr <= 1 && dg >= -2 && dg <= 1 && db >= -2 && db <= 1:
+				bw.WriteByte(qoiOpDiff | byte(dr+2)<<4 | byte(dg+2)<<2 | byte(db+2))
+			case dg >= -32 && dg <= 31 && drg >= -8 && drg <= 7 && dbg >= -8 && dbg <= 7:
+				bw.WriteByte(qoiOpLuma | byte(dg+32))
+				bw.WriteByte(byte(drg+8)<<4 | byte(dbg+8))
+			default:
+				bw.Write([]byte{qoiOpRGB, px.r, px.g, px.b})
+			}
+			prev = px
+		}
+	}
+	if run > 0 {
+		bw.WriteByte(qoiOpRun | byte(run-1))
+	}
+
+	bw.Write(qoiEndMarker)
+	return bw.Flush()
+}
@@ -0,0 +1,707 @@
+package screenshot
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_screencopy"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+type RecordFormat int
+
+const (
+	RecordGIF RecordFormat = iota
+	RecordAPNG
+	RecordWebP
+	RecordPipe
+)
+
+var recordFormatNames = map[RecordFormat]string{
+	RecordGIF:  "gif",
+	RecordAPNG: "apng",
+	RecordWebP: "webp",
+	RecordPipe: "pipe",
+}
+
+func (f RecordFormat) String() string {
+	if name, ok := recordFormatNames[f]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func (f RecordFormat) Extension() string {
+	switch f {
+	case RecordAPNG:
+		return "png"
+	case RecordWebP:
+		return "webp"
+	case RecordPipe:
+		return "mp4"
+	default:
+		return "gif"
+	}
+}
+
+func ParseRecordFormat(s string) (RecordFormat, error) {
+	for f, name := range recordFormatNames {
+		if strings.EqualFold(s, name) {
+			return f, nil
+		}
+	}
+	return RecordGIF, fmt.Errorf("unknown recording format: %s (gif, apng, webp, pipe)", s)
+}
+
+const (
+	DefaultRecordFPS = 15
+	MaxRecordFPS     = 60
+)
+
+type RecordConfig struct {
+	Mode        Mode
+	OutputName  string
+	Cursor      CursorMode
+	Format      RecordFormat
+	FPS         int
+	OutputDir   string
+	Filename    string
+	Encoder     string
+	MaxDuration time.Duration
+}
+
+func DefaultRecordConfig() RecordConfig {
+	return RecordConfig{
+		Mode:   ModeRegion,
+		Cursor: CursorOff,
+		Format: RecordGIF,
+		FPS:    DefaultRecordFPS,
+	}
+}
+
+type RecordState struct {
+	Recording bool   `json:"recording"`
+	Paused    bool   `json:"paused"`
+	Elapsed   int64  `json:"elapsed"`
+	Frames    int    `json:"frames"`
+	Format    string `json:"format,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Region    Region `json:"region"`
+	Error     string `json:"error,omitempty"`
+}
+
+var ErrRecordCancelled = errors.New("recording cancelled")
+
+var errRecordStopped = errors.New("recording stopped")
+
+// recordTarget describes what each frame captures. Regions on transformed
+// outputs are cut from the whole output after undoing the transform.
+type recordTarget struct {
+	output *WaylandOutput
+	region Region
+	whole  bool
+	local  image.Rectangle
+	crop   image.Rectangle
+}
+
+// Recorder streams wlr-screencopy frames of a region or output into a
+// frameSink. Once the first frame is in, frames are requested with damage
+// tracking so a static screen costs nothing and only changed areas are
+// encoded.
+type Recorder struct {
+	config RecordConfig
+	sc     *Screenshoter
+	target recordTarget
+	path   string
+
+	sink   frameSink
+	canvas *image.RGBA
+
+	buf   *ShmBuffer
+	pool  *client.ShmPool
+	wlBuf *client.Buffer
+
+	mu         sync.Mutex
+	state      RecordState
+	started    time.Time
+	pausedAt   time.Time
+	pausedFor  time.Duration
+	stop       bool
+	fullFrame  bool
+	lastSecond int64
+	onState    func(RecordState)
+
+	done chan struct{}
+	err  error
+}
+
+func NewRecorder(config RecordConfig) *Recorder {
+	if config.FPS <= 0 {
+		config.FPS = DefaultRecordFPS
+	}
+	config.FPS = min(config.FPS, MaxRecordFPS)
+
+	return &Recorder{
+		config: config,
+		sc:     New(Config{Mode: config.Mode, OutputName: config.OutputName, Cursor: config.Cursor}),
+		done:   make(chan struct{}),
+	}
+}
+
+// OnState registers a callback for state changes and elapsed time updates.
+// It must be set before Start.
+func (r *Recorder) OnState(fn func(RecordState)) {
+	r.onState = fn
+}
+
+// Start connects to the compositor, resolves the target (showing the region
+// selector if needed) and begins recording in the background.
+func (r *Recorder) Start() error {
+	s := r.sc
+	if err := s.connect(); err != nil {
+		return fmt.Errorf("wayland connect: %w", err)
+	}
+	if err := s.setupRegistry(); err != nil {
+		s.cleanup()
+		return fmt.Errorf("registry setup: %w", err)
+	}
+	for range 2 {
+		if err := s.roundtrip(); err != nil {
+			s.cleanup()
+			return fmt.Errorf("roundtrip: %w", err)
+		}
+	}
+	if s.screencopy == nil {
+		s.cleanup()
+		return fmt.Errorf("compositor does not support wlr-screencopy-unstable-v1")
+	}
+
+	target, err := r.resolveTarget()
+	if err != nil {
+		s.cleanup()
+		return err
+	}
+	r.target = target
+
+	dir := r.config.OutputDir
+	if dir == "" {
+		dir = GetOutputDir()
+	}
+	filename := r.config.Filename
+	if filename == "" {
+		filename = GenerateRecordingFilename(r.config.Format)
+	}
+	r.path = filepath.Join(dir, filename)
+
+	r.mu.Lock()
+	r.started = time.Now()
+	r.fullFrame = true
+	r.state = RecordState{
+		Recording: true,
+		Format:    r.config.Format.String(),
+		Path:      r.path,
+		Region:    target.region,
+	}
+	r.mu.Unlock()
+	r.notify()
+
+	go r.run()
+	return nil
+}
+
+func GenerateRecordingFilename(format RecordFormat) string {
+	return fmt.Sprintf("recording_%s.%s", time.Now().Format("2006-01-02_15-04-05"), format.Extension())
+}
+
+func (r *Recorder) resolveTarget() (recordTarget, error) {
+	s := r.sc
+	switch r.config.Mode {
+	case ModeOutput:
+		output := s.findOutputByName(r.config.OutputName)
+		if output == nil {
+			return recordTarget{}, fmt.Errorf("output %q not found", r.config.OutputName)
+		}
+		return outputTarget(output), nil
+	case ModeFullScreen:
+		output := s.findFocusedOutput()
+		if output == nil {
+			return recordTarget{}, fmt.Errorf("no output available")
+		}
+		return outputTarget(output), nil
+	case ModeAllScreens:
+		return recordTarget{}, fmt.Errorf("recording all screens is not supported")
+	case ModeWindow:
+		geom, err := GetActiveWindow()
+		if err != nil {
+			return recordTarget{}, err
+		}
+		return r.regionTarget(Region{X: geom.X, Y: geom.Y, Width: geom.Width, Height: geom.Height, Output: geom.Output})
+	case ModeLastRegion:
+		if region := GetLastRegion(); !region.IsEmpty() {
+			return r.regionTarget(region)
+		}
+	}
+
+	result, cancelled, err := NewRegionSelector(s).Run()
+	if err != nil {
+		return recordTarget{}, fmt.Errorf("region selection: %w", err)
+	}
+	if cancelled || result == nil {
+		return recordTarget{}, ErrRecordCancelled
+	}
+	result.Buffer.Close()
+	if err := SaveLastRegion(result.Region); err != nil {
+		log.Debug("failed to save last region", "err", err)
+	}
+	// let the overlay disappear before the first frame
+	if err := s.roundtrip(); err != nil {
+		return recordTarget{}, fmt.Errorf("roundtrip: %w", err)
+	}
+	return r.regionTarget(result.Region)
+}
+
+func outputTarget(output *WaylandOutput) recordTarget {
+	return recordTarget{
+		output: output,
+		whole:  true,
+		region: Region{X: output.x, Y: output.y, Width: output.width, Height: output.height, Output: output.name},
+	}
+}
+
+func (r *Recorder) regionTarget(region Region) (recordTarget, error) {
+	s := r.sc
+	var output *WaylandOutput
+	if region.Output != "" {
+		output = s.findOutputByName(region.Output)
+	}
+	if output == nil {
+		output = s.findOutputForRegion(region)
+	}
+	if output == nil {
+		return recordTarget{}, fmt.Errorf("could not find output for region")
+	}
+	region.Output = output.name
+
+	if output.transform == TransformNormal {
+		x, y, w, h := s.localRegion(output, region)
+		if w <= 0 || h <= 0 {
+			return recordTarget{}, fmt.Errorf("region not visible on output")
+		}
+		return recordTarget{
+			output: output,
+			region: region,
+			local:  image.Rect(int(x), int(y), int(x+w), int(y+h)),
+		}, nil
+	}
+
+	scale := s.outputScale(output)
+	x := int(float64(region.X-output.x) * scale)
+	y := int(float64(region.Y-output.y) * scale)
+	return recordTarget{
+		output: output,
+		region: region,
+		whole:  true,
+		crop:   image.Rect(x, y, x+int(float64(region.Width)*scale), y+int(float64(region.Height)*scale)),
+	}, nil
+}
+
+func (r *Recorder) Stop() {
+	r.mu.Lock()
+	r.stop = true
+	r.mu.Unlock()
+}
+
+func (r *Recorder) Pause() {
+	r.mu.Lock()
+	if !r.state.Recording || r.state.Paused {
+		r.mu.Unlock()
+		return
+	}
+	r.state.Paused = true
+	r.pausedAt = time.Now()
+	r.mu.Unlock()
+	r.notify()
+}
+
+func (r *Recorder) Resume() {
+	r.mu.Lock()
+	if !r.state.Paused {
+		r.mu.Unlock()
+		return
+	}
+	r.state.Paused = false
+	r.pausedFor += time.Since(r.pausedAt)
+	// damage is relative to the last copy, which may be long gone
+	r.fullFrame = true
+	r.mu.Unlock()
+	r.notify()
+}
+
+func (r *Recorder) State() RecordState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.state
+	if state.Recording {
+		state.Elapsed = r.elapsedLocked().Milliseconds()
+	}
+	return state
+}
+
+// Wait blocks until the recording has finished and returns the file path.
+func (r *Recorder) Wait() (string, error) {
+	<-r.done
+	return r.path, r.err
+}
+
+// Done is closed once the recording has finished.
+func (r *Recorder) Done() <-chan struct{} {
+	return r.done
+}
+
+func (r *Recorder) elapsedLocked() time.Duration {
+	end := time.Now()
+	if r.state.Paused {
+		end = r.pausedAt
+	}
+	return end.Sub(r.started) - r.pausedFor
+}
+
+func (r *Recorder) elapsed() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.elapsedLocked()
+}
+
+func (r *Recorder) paused() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state.Paused
+}
+
+func (r *Recorder) stopping() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stop || (r.config.MaxDuration > 0 && r.elapsedLocked() >= r.config.MaxDuration)
+}
+
+func (r *Recorder) notify() {
+	if r.onState != nil {
+		r.onState(r.State())
+	}
+}
+
+// tick publishes the elapsed time once per second.
+func (r *Recorder) tick() {
+	r.mu.Lock()
+	second := int64(r.elapsedLocked() / time.Second)
+	changed := second != r.lastSecond
+	r.lastSecond = second
+	r.mu.Unlock()
+
+	if changed {
+		r.notify()
+	}
+}
+
+func (r *Recorder) run() {
+	err := r.stream()
+
+	if r.sink != nil {
+		if closeErr := r.sink.Close(); err == nil {
+			err = closeErr
+		}
+	} else if err == nil {
+		err = fmt.Errorf("no frames recorded")
+	}
+	r.releaseBuffer()
+	r.sc.cleanup()
+
+	r.mu.Lock()
+	r.err = err
+	if r.state.Paused {
+		r.pausedFor += time.Since(r.pausedAt)
+	}
+	r.state.Elapsed = (time.Since(r.started) - r.pausedFor).Milliseconds()
+	r.state.Recording = false
+	r.state.Paused = false
+	if err != nil {
+		r.state.Error = err.Error()
+	}
+	state := r.state
+	r.mu.Unlock()
+
+	if r.onState != nil {
+		r.onState(state)
+	}
+	close(r.done)
+}
+
+func (r *Recorder) stream() error {
+	interval := time.Second / time.Duration(r.config.FPS)
+
+	var pending bool
+	var pendingAt time.Duration
+	var changed image.Rectangle
+	var next time.Time
+
+	flush := func(at time.Duration) error {
+		if !pending {
+			return nil
+		}
+		pending = false
+		return r.sink.AddFrame(r.canvas, changed, at-pendingAt)
+	}
+
+	for !r.stopping() {
+		r.tick()
+		if wait := time.Until(next); r.paused() || wait > 0 {
+			time.Sleep(min(max(wait, 0), 20*time.Millisecond) + time.Millisecond)
+			continue
+		}
+		next = time.Now().Add(interval)
+
+		r.mu.Lock()
+		full := r.fullFrame
+		r.fullFrame = false
+		r.mu.Unlock()
+
+		damage, format, yInverted, err := r.captureFrame(!full)
+		if errors.Is(err, errRecordStopped) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if r.paused() {
+			continue
+		}
+
+		at := r.elapsed()
+		if err := flush(at); err != nil {
+			return err
+		}
+		if err := r.updateCanvas(format, yInverted); err != nil {
+			return err
+		}
+		if full || damage.Empty() {
+			damage = r.canvas.Bounds()
+		}
+		changed = damage
+		pending = true
+		pendingAt = at
+
+		r.mu.Lock()
+		r.state.Frames++
+		r.mu.Unlock()
+	}
+
+	return flush(r.elapsed())
+}
+
+// captureFrame copies the next frame into r.buf. With damage it returns once
+// the compositor reports a change, along with the changed area.
+func (r *Recorder) captureFrame(withDamage bool) (image.Rectangle, uint32, bool, error) {
+	s := r.sc
+	t := r.target
+	cursor := int32(r.config.Cursor)
+	withDamage = withDamage && s.scVersion >= 2
+
+	var frame *wlr_screencopy.ZwlrScreencopyFrameV1
+	var err error
+	if t.whole {
+		frame, err = s.screencopy.CaptureOutput(cursor, t.output.wlOutput)
+	} else {
+		frame, err = s.screencopy.CaptureOutputRegion(cursor, t.output.wlOutput,
+			int32(t.local.Min.X), int32(t.local.Min.Y), int32(t.local.Dx()), int32(t.local.Dy()))
+	}
+	if err != nil {
+		return image.Rectangle{}, 0, false, fmt.Errorf("capture: %w", err)
+	}
+	defer frame.Destroy()
+
+	var format PixelFormat
+	var damage image.Rectangle
+	var yInverted, ready, failed bool
+	var setupErr error
+
+	frame.SetBufferHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1BufferEvent) {
+		format = PixelFormat(e.Format)
+		if int(e.Stride) < int(e.Width)*format.BytesPerPixel() {
+			setupErr = fmt.Errorf("invalid stride %d for width %d", e.Stride, e.Width)
+			return
+		}
+		if b := r.buf; b != nil && b.Width == int(e.Width) && b.Height == int(e.Height) && b.Stride == int(e.Stride) && b.Format == format {
+			return
+		}
+		r.releaseBuffer()
+		buf, err := CreateShmBuffer(int(e.Width), int(e.Height), int(e.Stride))
+		if err != nil {
+			setupErr = fmt.Errorf("create buffer: %w", err)
+			return
+		}
+		buf.Format = format
+		r.buf = buf
+	})
+
+	frame.SetFlagsHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1FlagsEvent) {
+		yInverted = (e.Flags & 1) != 0
+	})
+
+	frame.SetDamageHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1DamageEvent) {
+		damage = damage.Union(image.Rect(int(e.X), int(e.Y), int(e.X+e.Width), int(e.Y+e.Height)))
+	})
+
+	frame.SetBufferDoneHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1BufferDoneEvent) {
+		if r.buf == nil || setupErr != nil {
+			return
+		}
+		if r.wlBuf == nil {
+			pool, err := s.shm.CreatePool(r.buf.Fd(), int32(r.buf.Size()))
+			if err != nil {
+				setupErr = fmt.Errorf("create pool: %w", err)
+				return
+			}
+			wlBuf, err := pool.CreateBuffer(0, int32(r.buf.Width), int32(r.buf.Height), int32(r.buf.Stride), uint32(format))
+			if err != nil {
+				pool.Destroy()
+				setupErr = fmt.Errorf("create wl_buffer: %w", err)
+				return
+			}
+			r.pool, r.wlBuf = pool, wlBuf
+		}
+
+		copyFn := frame.Copy
+		if withDamage {
+			copyFn = frame.CopyWithDamage
+		}
+		if err := copyFn(r.wlBuf); err != nil {
+			setupErr = fmt.Errorf("copy frame: %w", err)
+		}
+	})
+
+	frame.SetReadyHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1ReadyEvent) {
+		ready = true
+	})
+
+	frame.SetFailedHandler(func(e wlr_screencopy.ZwlrScreencopyFrameV1FailedEvent) {
+		failed = true
+	})
+
+	defer s.ctx.SetReadDeadline(time.Time{})
+	for !ready && !failed && setupErr == nil {
+		// poll so stop requests are seen while waiting for damage
+		if r.stopping() {
+			return image.Rectangle{}, 0, false, errRecordStopped
+		}
+		r.tick()
+		if err := s.ctx.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+			return image.Rectangle{}, 0, false, fmt.Errorf("set read deadline: %w", err)
+		}
+		if err := s.ctx.Dispatch(); err != nil {
+			if isTimeoutError(err) {
+				continue
+			}
+			return image.Rectangle{}, 0, false, fmt.Errorf("dispatch: %w", err)
+		}
+	}
+
+	switch {
+	case setupErr != nil:
+		return image.Rectangle{}, 0, false, setupErr
+	case failed:
+		return image.Rectangle{}, 0, false, fmt.Errorf("frame capture failed")
+	}
+
+	if yInverted && !damage.Empty() {
+		h := r.buf.Height
+		damage = image.Rect(damage.Min.X, h-damage.Max.Y, damage.Max.X, h-damage.Min.Y)
+	}
+	if !withDamage || t.output.transform != TransformNormal {
+		damage = image.Rectangle{}
+	}
+	return damage, uint32(format), yInverted, nil
+}
+
+// updateCanvas converts the captured buffer into the recording canvas,
+// creating the canvas and the sink on the first frame.
+func (r *Recorder) updateCanvas(format uint32, yInverted bool) error {
+	buf := r.buf
+	if PixelFormat(format).Is24Bit() {
+		converted, newFormat, err := buf.ConvertTo32Bit(PixelFormat(format))
+		if err != nil {
+			return fmt.Errorf("convert 24-bit to 32-bit: %w", err)
+		}
+		if converted != buf {
+			defer converted.Close()
+		}
+		buf, format = converted, uint32(newFormat)
+	}
+
+	if transform := r.target.output.transform; r.target.whole && transform != TransformNormal {
+		if yInverted {
+			buf.FlipVertical()
+			yInverted = false
+		}
+		transformed, err := buf.ApplyTransform(InverseTransform(transform))
+		if err != nil {
+			return fmt.Errorf("apply transform: %w", err)
+		}
+		if transformed != buf {
+			defer transformed.Close()
+		}
+		buf = transformed
+	}
+
+	bounds := image.Rect(0, 0, buf.Width, buf.Height)
+	crop := bounds
+	if !r.target.crop.Empty() {
+		crop = r.target.crop.Intersect(bounds)
+	}
+	if crop.Empty() {
+		return fmt.Errorf("region not visible on output")
+	}
+
+	if r.canvas == nil {
+		r.canvas = image.NewRGBA(image.Rect(0, 0, crop.Dx(), crop.Dy()))
+		sink, err := newFrameSink(r.config.Format, r.path, r.config.Encoder, crop.Dx(), crop.Dy(), r.config.FPS)
+		if err != nil {
+			return err
+		}
+		r.sink = sink
+	} else if r.canvas.Rect.Dx() != crop.Dx() || r.canvas.Rect.Dy() != crop.Dy() {
+		return fmt.Errorf("frame size changed from %dx%d to %dx%d", r.canvas.Rect.Dx(), r.canvas.Rect.Dy(), crop.Dx(), crop.Dy())
+	}
+
+	copyBufferToImage(r.canvas, buf, crop.Min, format, yInverted)
+	return nil
+}
+
+func (r *Recorder) releaseBuffer() {
+	if r.wlBuf != nil {
+		r.wlBuf.Destroy()
+		r.wlBuf = nil
+	}
+	if r.pool != nil {
+		r.pool.Destroy()
+		r.pool = nil
+	}
+	if r.buf != nil {
+		r.buf.Close()
+		r.buf = nil
+	}
+}
+
+func isTimeoutError(err error) bool {
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	netErr, ok := err.(interface{ Timeout() bool })
+	return ok && netErr.Timeout()
+}
@@ -0,0 +1,564 @@
+package screenshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/lzw"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// frameSink receives recorded frames. frame always covers the whole
+// recording, changed is the part that differs from the previous frame and
+// delay is how long the frame stays on screen.
+type frameSink interface {
+	AddFrame(frame *image.RGBA, changed image.Rectangle, delay time.Duration) error
+	Close() error
+}
+
+// delayClock converts frame delays to a coarser timebase without letting
+// rounding errors add up over a long recording.
+type delayClock struct {
+	unit    time.Duration
+	elapsed time.Duration
+	emitted int64
+}
+
+func (c *delayClock) next(delay time.Duration) int64 {
+	c.elapsed += delay
+	total := int64((c.elapsed + c.unit/2) / c.unit)
+	ticks := total - c.emitted
+	c.emitted = total
+	return ticks
+}
+
+func newFrameSink(format RecordFormat, path, encoder string, width, height, fps int) (frameSink, error) {
+	if format == RecordPipe {
+		return newPipeSink(encoder, path, width, height, fps)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var sink frameSink
+	switch format {
+	case RecordGIF:
+		sink, err = newGIFSink(f, width, height)
+	case RecordAPNG:
+		sink, err = newAPNGSink(f, width, height)
+	case RecordWebP:
+		sink, err = newWebPSink(f, width, height)
+	default:
+		err = fmt.Errorf("unsupported recording format: %v", format)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return sink, nil
+}
+
+// GIF
+
+type gifSink struct {
+	f     *os.File
+	w     *bufio.Writer
+	clock delayClock
+}
+
+func newGIFSink(f *os.File, width, height int) (*gifSink, error) {
+	if width > 0xffff || height > 0xffff {
+		return nil, fmt.Errorf("gif: image too large %dx%d", width, height)
+	}
+	s := &gifSink{f: f, w: bufio.NewWriter(f), clock: delayClock{unit: 10 * time.Millisecond}}
+
+	var hdr [13]byte
+	copy(hdr[:], "GIF89a")
+	binary.LittleEndian.PutUint16(hdr[6:], uint16(width))
+	binary.LittleEndian.PutUint16(hdr[8:], uint16(height))
+	s.w.Write(hdr[:])
+	// loop forever
+	s.w.Write([]byte{0x21, 0xff, 0x0b})
+	s.w.WriteString("NETSCAPE2.0")
+	_, err := s.w.Write([]byte{0x03, 0x01, 0x00, 0x00, 0x00})
+	return s, err
+}
+
+func (s *gifSink) AddFrame(frame *image.RGBA, changed image.Rectangle, delay time.Duration) error {
+	changed = changed.Intersect(frame.Bounds())
+	if changed.Empty() {
+		changed = image.Rect(0, 0, 1, 1)
+	}
+
+	pm := quantizeFrame(frame, changed)
+	paletteBits := 1
+	for 1<<paletteBits < len(pm.Palette) {
+		paletteBits++
+	}
+
+	delayCS := min(s.clock.next(delay), 0xffff)
+	var gce [8]byte
+	gce[0], gce[1], gce[2] = 0x21, 0xf9, 0x04
+	gce[3] = 1 << 2 // leave the frame in place for the next one
+	binary.LittleEndian.PutUint16(gce[4:], uint16(delayCS))
+	s.w.Write(gce[:])
+
+	var desc [10]byte
+	desc[0] = 0x2c
+	binary.LittleEndian.PutUint16(desc[1:], uint16(changed.Min.X))
+	binary.LittleEndian.PutUint16(desc[3:], uint16(changed.Min.Y))
+	binary.LittleEndian.PutUint16(desc[5:], uint16(changed.Dx()))
+	binary.LittleEndian.PutUint16(desc[7:], uint16(changed.Dy()))
+	desc[9] = 0x80 | byte(paletteBits-1)
+	s.w.Write(desc[:])
+
+	table := make([]byte, 3<<paletteBits)
+	for i, c := range pm.Palette {
+		r, g, b, _ := c.RGBA()
+		table[i*3], table[i*3+1], table[i*3+2] = byte(r>>8), byte(g>>8), byte(b>>8)
+	}
+	s.w.Write(table)
+
+	litWidth := max(paletteBits, 2)
+	s.w.WriteByte(byte(litWidth))
+	blocks := &gifBlockWriter{w: s.w}
+	lw := lzw.NewWriter(blocks, lzw.LSB, litWidth)
+	for y := 0; y < changed.Dy(); y++ {
+		if _, err := lw.Write(pm.Pix[y*pm.Stride : y*pm.Stride+changed.Dx()]); err != nil {
+			return err
+		}
+	}
+	if err := lw.Close(); err != nil {
+		return err
+	}
+	return blocks.close()
+}
+
+func (s *gifSink) Close() error {
+	s.w.WriteByte(0x3b)
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
+
+// gifBlockWriter splits image data into GIF sub-blocks.
+type gifBlockWriter struct {
+	w   *bufio.Writer
+	buf [255]byte
+	n   int
+}
+
+func (b *gifBlockWriter) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		n := copy(b.buf[b.n:], p)
+		b.n += n
+		p = p[n:]
+		if b.n == len(b.buf) {
+			if err := b.flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (b *gifBlockWriter) flush() error {
+	if b.n == 0 {
+		return nil
+	}
+	b.w.WriteByte(byte(b.n))
+	_, err := b.w.Write(b.buf[:b.n])
+	b.n = 0
+	return err
+}
+
+func (b *gifBlockWriter) close() error {
+	if err := b.flush(); err != nil {
+		return err
+	}
+	return b.w.WriteByte(0)
+}
+
+// quantizeFrame maps r of img to a palette. UI content usually has few
+// enough colors for an exact palette; anything else is dithered to Plan 9.
+func quantizeFrame(img *image.RGBA, r image.Rectangle) *image.Paletted {
+	if pm := exactPalette(img, r); pm != nil {
+		return pm
+	}
+
+	bounds := image.Rect(0, 0, r.Dx(), r.Dy())
+	pm := image.NewPaletted(bounds, palette.Plan9)
+	draw.FloydSteinberg.Draw(pm, bounds, img, r.Min)
+	return pm
+}
+
+// exactPalette maps r of img to a palette of its own colors, or returns nil
+// when it has more than 256 of them.
+func exactPalette(img *image.RGBA, r image.Rectangle) *image.Paletted {
+	bounds := image.Rect(0, 0, r.Dx(), r.Dy())
+	index := make(map[uint32]uint8, 256)
+	pal := make(color.Palette, 0, 256)
+	pix := make([]uint8, r.Dx()*r.Dy())
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(r.Min.X, y):]
+		for x := 0; x < r.Dx(); x++ {
+			p := row[x*4 : x*4+3]
+			key := uint32(p[0])<<16 | uint32(p[1])<<8 | uint32(p[2])
+			i, ok := index[key]
+			if !ok {
+				if len(pal) == 256 {
+					return nil
+				}
+				i = uint8(len(pal))
+				index[key] = i
+				pal = append(pal, color.RGBA{R: p[0], G: p[1], B: p[2], A: 255})
+			}
+			pix[(y-r.Min.Y)*r.Dx()+x] = i
+		}
+	}
+
+	return &image.Paletted{Pix: pix, Stride: r.Dx(), Rect: bounds, Palette: pal}
+}
+
+// APNG
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+type apngSink struct {
+	f         *os.File
+	w         *bufio.Writer
+	width     int
+	height    int
+	colorType byte
+	actlAt    int64
+	offset    int64
+	frames    uint32
+	seq       uint32
+	enc       png.Encoder
+}
+
+func newAPNGSink(f *os.File, width, height int) (*apngSink, error) {
+	return &apngSink{
+		f:      f,
+		w:      bufio.NewWriter(f),
+		width:  width,
+		height: height,
+		enc:    png.Encoder{CompressionLevel: png.BestSpeed},
+	}, nil
+}
+
+func (s *apngSink) writeChunk(typ string, data []byte) error {
+	s.offset += int64(12 + len(data))
+	return writePNGChunk(s.w, typ, data)
+}
+
+func writePNGChunk(w io.Writer, typ string, data []byte) error {
+	var hdr [8]byte
+	binary.BigEndian.PutUint32(hdr[:4], uint32(len(data)))
+	copy(hdr[4:], typ)
+	crc := crc32.NewIEEE()
+	crc.Write(hdr[4:])
+	crc.Write(data)
+
+	w.Write(hdr[:])
+	w.Write(data)
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	_, err := w.Write(sum[:])
+	return err
+}
+
+func apngACTL(frames uint32) []byte {
+	var actl [8]byte
+	binary.BigEndian.PutUint32(actl[:4], frames)
+	return actl[:]
+}
+
+func (s *apngSink) AddFrame(frame *image.RGBA, changed image.Rectangle, delay time.Duration) error {
+	if s.frames == 0 {
+		// the first frame doubles as the static fallback image
+		changed = frame.Bounds()
+	}
+	changed = changed.Intersect(frame.Bounds())
+	if changed.Empty() {
+		changed = image.Rect(0, 0, 1, 1)
+	}
+
+	var buf bytes.Buffer
+	if err := s.enc.Encode(&buf, frame.SubImage(changed)); err != nil {
+		return err
+	}
+	ihdr, idat, err := splitPNG(buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if s.frames == 0 {
+		s.colorType = ihdr[9]
+		s.w.Write(pngSignature)
+		s.offset = int64(len(pngSignature))
+		s.writeChunk("IHDR", ihdr)
+		s.actlAt = s.offset
+		s.writeChunk("acTL", apngACTL(0))
+	} else if ihdr[9] != s.colorType {
+		return fmt.Errorf("apng: frame color type %d differs from %d", ihdr[9], s.colorType)
+	}
+
+	var fctl [26]byte
+	binary.BigEndian.PutUint32(fctl[0:], s.seq)
+	binary.BigEndian.PutUint32(fctl[4:], uint32(changed.Dx()))
+	binary.BigEndian.PutUint32(fctl[8:], uint32(changed.Dy()))
+	binary.BigEndian.PutUint32(fctl[12:], uint32(changed.Min.X))
+	binary.BigEndian.PutUint32(fctl[16:], uint32(changed.Min.Y))
+	binary.BigEndian.PutUint16(fctl[20:], uint16(min(delay.Milliseconds(), 0xffff)))
+	binary.BigEndian.PutUint16(fctl[22:], 1000)
+	s.seq++
+	if err := s.writeChunk("fcTL", fctl[:]); err != nil {
+		return err
+	}
+
+	if s.frames == 0 {
+		err = s.writeChunk("IDAT", idat)
+	} else {
+		fdat := make([]byte, 4+len(idat))
+		binary.BigEndian.PutUint32(fdat, s.seq)
+		copy(fdat[4:], idat)
+		s.seq++
+		err = s.writeChunk("fdAT", fdat)
+	}
+	s.frames++
+	return err
+}
+
+func (s *apngSink) Close() error {
+	if s.frames == 0 {
+		s.f.Close()
+		return fmt.Errorf("apng: no frames recorded")
+	}
+
+	s.writeChunk("IEND", nil)
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return err
+	}
+
+	// patch the frame count now that it is known
+	s.w.Reset(s.f)
+	if _, err := s.f.Seek(s.actlAt, io.SeekStart); err != nil {
+		s.f.Close()
+		return err
+	}
+	s.writeChunk("acTL", apngACTL(s.frames))
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
+
+// splitPNG returns the IHDR payload and the concatenated IDAT payloads.
+func splitPNG(data []byte) ([]byte, []byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, nil, fmt.Errorf("apng: not a png")
+	}
+	data = data[len(pngSignature):]
+
+	var ihdr, idat []byte
+	for len(data) >= 12 {
+		n := int(binary.BigEndian.Uint32(data))
+		if 12+n > len(data) {
+			return nil, nil, fmt.Errorf("apng: truncated chunk")
+		}
+		typ, payload := string(data[4:8]), data[8:8+n]
+		switch typ {
+		case "IHDR":
+			ihdr = payload
+		case "IDAT":
+			idat = append(idat, payload...)
+		}
+		data = data[12+n:]
+	}
+	if len(ihdr) != 13 || idat == nil {
+		return nil, nil, fmt.Errorf("apng: missing IHDR or IDAT")
+	}
+	return ihdr, idat, nil
+}
+
+// animated WebP
+
+type webpSink struct {
+	f      *os.File
+	w      *bufio.Writer
+	size   int64
+	frames int
+}
+
+func newWebPSink(f *os.File, width, height int) (*webpSink, error) {
+	if width > vp8lMaxDimension || height > vp8lMaxDimension {
+		return nil, fmt.Errorf("webp: image too large %dx%d", width, height)
+	}
+	s := &webpSink{f: f, w: bufio.NewWriter(f)}
+
+	var hdr [12]byte
+	copy(hdr[:4], "RIFF")
+	copy(hdr[8:], "WEBP")
+	s.w.Write(hdr[:])
+
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x02 // animation
+	putUint24(vp8x[4:], uint32(width-1))
+	putUint24(vp8x[7:], uint32(height-1))
+	writeRIFFChunk(s.w, "VP8X", vp8x)
+
+	anim := make([]byte, 6) // transparent background, loop forever
+	err := writeRIFFChunk(s.w, "ANIM", anim)
+
+	s.size = 4 + int64(riffChunkSize(vp8x)) + int64(riffChunkSize(anim))
+	return s, err
+}
+
+func (s *webpSink) AddFrame(frame *image.RGBA, changed image.Rectangle, delay time.Duration) error {
+	// frame offsets are stored halved
+	changed.Min.X &^= 1
+	changed.Min.Y &^= 1
+	changed = changed.Intersect(frame.Bounds())
+	if s.frames == 0 || changed.Empty() {
+		changed = frame.Bounds()
+	}
+
+	data, err := EncodeVP8L(frame.SubImage(changed).(*image.RGBA))
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	hdr := make([]byte, 16)
+	putUint24(hdr[0:], uint32(changed.Min.X/2))
+	putUint24(hdr[3:], uint32(changed.Min.Y/2))
+	putUint24(hdr[6:], uint32(changed.Dx()-1))
+	putUint24(hdr[9:], uint32(changed.Dy()-1))
+	putUint24(hdr[12:], uint32(min(delay.Milliseconds(), 1<<24-1)))
+	hdr[15] = 0x02 // replace instead of blending
+	buf.Write(hdr)
+	writeRIFFChunk(&buf, "VP8L", data)
+
+	s.size += int64(riffChunkSize(buf.Bytes()))
+	s.frames++
+	return writeRIFFChunk(s.w, "ANMF", buf.Bytes())
+}
+
+func (s *webpSink) Close() error {
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return err
+	}
+	if s.frames == 0 {
+		s.f.Close()
+		return fmt.Errorf("webp: no frames recorded")
+	}
+
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(s.size))
+	if _, err := s.f.WriteAt(size[:], 4); err != nil {
+		s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
+
+// external encoder
+
+// pipeSink feeds raw RGBA frames at a constant rate to an encoder command.
+// The command sees {width}, {height}, {fps} and {file} substituted.
+type pipeSink struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stderr bytes.Buffer
+	clock  delayClock
+}
+
+func newPipeSink(encoder, path string, width, height, fps int) (*pipeSink, error) {
+	if strings.TrimSpace(encoder) == "" {
+		return nil, fmt.Errorf("no encoder command given")
+	}
+	command := strings.NewReplacer(
+		"{width}", strconv.Itoa(width),
+		"{height}", strconv.Itoa(height),
+		"{fps}", strconv.Itoa(fps),
+		"{file}", shellQuote(path),
+	).Replace(encoder)
+
+	s := &pipeSink{
+		cmd:   exec.Command("sh", "-c", command),
+		clock: delayClock{unit: time.Second / time.Duration(fps)},
+	}
+	s.cmd.Stderr = &s.stderr
+
+	stdin, err := s.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	s.stdin = stdin
+	if err := s.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start encoder: %w", err)
+	}
+	return s, nil
+}
+
+func (s *pipeSink) AddFrame(frame *image.RGBA, _ image.Rectangle, delay time.Duration) error {
+	b := frame.Bounds()
+	for n := s.clock.next(delay); n > 0; n-- {
+		if frame.Stride == b.Dx()*4 {
+			if _, err := s.stdin.Write(frame.Pix[:b.Dy()*frame.Stride]); err != nil {
+				return s.encoderError(err)
+			}
+			continue
+		}
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			off := frame.PixOffset(b.Min.X, y)
+			if _, err := s.stdin.Write(frame.Pix[off : off+b.Dx()*4]); err != nil {
+				return s.encoderError(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *pipeSink) Close() error {
+	s.stdin.Close()
+	return s.encoderError(s.cmd.Wait())
+}
+
+func (s *pipeSink) encoderError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if msg := strings.TrimSpace(s.stderr.String()); msg != "" {
+		lines := strings.Split(msg, "\n")
+		return fmt.Errorf("encoder: %w: %s", err, lines[len(lines)-1])
+	}
+	return fmt.Errorf("encoder: %w", err)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,254 @@
+package screenshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+func uiImage(w, h int) *image.RGBA {
+	img := solidImage(w, h, color.RGBA{R: 30, G: 30, B: 46, A: 255})
+	for y := 4; y < 12; y++ {
+		for x := 4; x < w-4; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: 137, G: 180, B: 250, A: 255})
+		}
+	}
+	return img
+}
+
+func noiseImage(w, h int) *image.RGBA {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	rng.Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	return img
+}
+
+func decodeRGBA(t *testing.T, img image.Image) *image.RGBA {
+	t.Helper()
+	b := img.Bounds()
+	out := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			out.Set(x, y, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return out
+}
+
+func TestEncodeWebP(t *testing.T) {
+	gradient := image.NewRGBA(image.Rect(0, 0, 300, 7))
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 300; x++ {
+			gradient.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(x / 2), B: uint8(y * 30), A: 255})
+		}
+	}
+	translucent := uiImage(20, 20)
+	for x := 0; x < 20; x++ {
+		translucent.SetRGBA(x, 0, color.RGBA{})
+	}
+
+	tests := []struct {
+		name string
+		img  *image.RGBA
+	}{
+		{"flat ui", uiImage(64, 40)},
+		{"noise", noiseImage(33, 17)},
+		{"gradient", gradient},
+		{"single pixel", solidImage(1, 1, color.RGBA{R: 1, G: 2, B: 3, A: 255})},
+		{"sub image", uiImage(64, 40).SubImage(image.Rect(3, 3, 20, 30)).(*image.RGBA)},
+		{"alpha", translucent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, EncodeWebP(&buf, tt.img))
+
+			decoded, err := webp.Decode(&buf)
+			require.NoError(t, err)
+			assert.Equal(t, decodeRGBA(t, tt.img).Pix, decodeRGBA(t, decoded).Pix)
+		})
+	}
+
+	t.Run("flat content compresses", func(t *testing.T) {
+		data, err := EncodeVP8L(uiImage(400, 300))
+		require.NoError(t, err)
+		assert.Less(t, len(data), 1000)
+	})
+
+	_, err := EncodeVP8L(image.NewRGBA(image.Rect(0, 0, 0, 5)))
+	assert.Error(t, err)
+}
+
+func recordFrames() []*image.RGBA {
+	first := uiImage(40, 30)
+	second := uiImage(40, 30)
+	for y := 20; y < 26; y++ {
+		for x := 10; x < 16; x++ {
+			second.SetRGBA(x, y, color.RGBA{R: 243, G: 139, B: 168, A: 255})
+		}
+	}
+	return []*image.RGBA{first, second}
+}
+
+func writeRecording(t *testing.T, format RecordFormat) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rec."+format.Extension())
+	sink, err := newFrameSink(format, path, "", 40, 30, 10)
+	require.NoError(t, err)
+
+	frames := recordFrames()
+	require.NoError(t, sink.AddFrame(frames[0], frames[0].Bounds(), 120*time.Millisecond))
+	require.NoError(t, sink.AddFrame(frames[1], image.Rect(9, 19, 16, 26), 500*time.Millisecond))
+	require.NoError(t, sink.Close())
+	return path
+}
+
+func TestRecordGIF(t *testing.T) {
+	f, err := os.Open(writeRecording(t, RecordGIF))
+	require.NoError(t, err)
+	defer f.Close()
+
+	g, err := gif.DecodeAll(f)
+	require.NoError(t, err)
+	require.Len(t, g.Image, 2)
+	assert.Equal(t, []int{12, 50}, g.Delay)
+	assert.Equal(t, 0, g.LoopCount)
+	assert.Equal(t, image.Rect(9, 19, 16, 26), g.Image[1].Bounds(), "only the changed area is stored")
+
+	frames := recordFrames()
+	assert.Equal(t, frames[0].Pix, decodeRGBA(t, g.Image[0]).Pix, "ui frames keep exact colors")
+	assert.Equal(t, frames[1].At(12, 22), g.Image[1].At(12, 22))
+
+	t.Run("many colors are dithered", func(t *testing.T) {
+		pm := quantizeFrame(noiseImage(32, 32), image.Rect(0, 0, 32, 32))
+		assert.LessOrEqual(t, len(pm.Palette), 256)
+	})
+}
+
+func TestRecordAPNG(t *testing.T) {
+	data, err := os.ReadFile(writeRecording(t, RecordAPNG))
+	require.NoError(t, err)
+
+	img, err := png.Decode(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, recordFrames()[0].Pix, decodeRGBA(t, img).Pix, "plain decoders show the first frame")
+
+	var chunks []string
+	var fctl [][]byte
+	rest := data[len(pngSignature):]
+	for len(rest) >= 12 {
+		n := int(binary.BigEndian.Uint32(rest))
+		typ := string(rest[4:8])
+		chunks = append(chunks, typ)
+		switch typ {
+		case "acTL":
+			assert.Equal(t, uint32(2), binary.BigEndian.Uint32(rest[8:]), "frame count is patched on close")
+		case "fcTL":
+			fctl = append(fctl, rest[8:8+n])
+		}
+		rest = rest[12+n:]
+	}
+	assert.Equal(t, []string{"IHDR", "acTL", "fcTL", "IDAT", "fcTL", "fdAT", "IEND"}, chunks)
+	require.Len(t, fctl, 2)
+	assert.Equal(t, uint32(7), binary.BigEndian.Uint32(fctl[1][4:]), "width of the changed area")
+	assert.Equal(t, uint32(19), binary.BigEndian.Uint32(fctl[1][16:]), "y offset")
+	assert.Equal(t, uint16(500), binary.BigEndian.Uint16(fctl[1][20:]))
+}
+
+func TestRecordWebP(t *testing.T) {
+	data, err := os.ReadFile(writeRecording(t, RecordWebP))
+	require.NoError(t, err)
+
+	require.Equal(t, "RIFF", string(data[:4]))
+	assert.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:]))
+
+	var frames [][]byte
+	rest := data[12:]
+	for len(rest) >= 8 {
+		n := int(binary.LittleEndian.Uint32(rest[4:]))
+		if string(rest[:4]) == "ANMF" {
+			frames = append(frames, rest[8:8+n])
+		}
+		rest = rest[8+n+n%2:]
+	}
+	require.Len(t, frames, 2)
+
+	want := recordFrames()
+	for i, frame := range frames {
+		x := int(frame[0]) | int(frame[1])<<8 | int(frame[2])<<16
+		y := int(frame[3]) | int(frame[4])<<8 | int(frame[5])<<16
+		w := int(frame[6]) | int(frame[7])<<8 | int(frame[8])<<16
+		h := int(frame[9]) | int(frame[10])<<8 | int(frame[11])<<16
+		rect := image.Rect(x*2, y*2, x*2+w+1, y*2+h+1)
+
+		var still bytes.Buffer
+		still.WriteString("RIFF")
+		binary.Write(&still, binary.LittleEndian, uint32(4+len(frame)-16))
+		still.WriteString("WEBP")
+		still.Write(frame[16:])
+
+		decoded, err := webp.Decode(&still)
+		require.NoError(t, err)
+		assert.Equal(t, decodeRGBA(t, want[i].SubImage(rect)).Pix, decodeRGBA(t, decoded).Pix)
+		if i == 1 {
+			assert.Equal(t, image.Rect(8, 18, 16, 26), rect, "offsets are rounded down to even")
+		}
+	}
+}
+
+func TestRecordPipe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raw")
+	sink, err := newFrameSink(RecordPipe, path, "cat > {file}; echo {width}x{height}@{fps} > {file}.info", 40, 30, 10)
+	require.NoError(t, err)
+
+	frames := recordFrames()
+	require.NoError(t, sink.AddFrame(frames[0], frames[0].Bounds(), 140*time.Millisecond))
+	require.NoError(t, sink.AddFrame(frames[1], image.Rect(9, 19, 16, 26), 220*time.Millisecond))
+	require.NoError(t, sink.Close())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	frameSize := 40 * 30 * 4
+	require.Len(t, raw, 4*frameSize, "frames repeat to keep a constant rate")
+	assert.Equal(t, frames[0].Pix, raw[:frameSize])
+	assert.Equal(t, frames[1].Pix, raw[3*frameSize:])
+
+	info, err := os.ReadFile(path + ".info")
+	require.NoError(t, err)
+	assert.Equal(t, "40x30@10\n", string(info))
+
+	failing, err := newFrameSink(RecordPipe, path, "echo broken >&2; exit 3", 40, 30, 10)
+	require.NoError(t, err)
+	err = failing.Close()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken")
+
+	_, err = newFrameSink(RecordPipe, path, " ", 40, 30, 10)
+	assert.Error(t, err)
+}
+
+func TestParseRecordFormat(t *testing.T) {
+	f, err := ParseRecordFormat("APNG")
+	require.NoError(t, err)
+	assert.Equal(t, RecordAPNG, f)
+	assert.Equal(t, "png", f.Extension())
+
+	_, err = ParseRecordFormat("mp4")
+	assert.Error(t, err)
+}
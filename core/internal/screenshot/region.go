@@ -2,6 +2,7 @@ package screenshot
 
 import (
 	"fmt"
+	"image"
 	"sync"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
@@ -94,6 +95,12 @@ type RegionSelector struct {
 	preSelect          Region
 	showCapturedCursor bool
 	shiftHeld          bool
+	ctrlHeld           bool
+
+	annotating     bool
+	annotateRect   image.Rectangle
+	annotations    *AnnotationEditor
+	annotationDrag bool
 
 	running   bool
 	cancelled bool
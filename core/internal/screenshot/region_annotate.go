@@ -0,0 +1,285 @@
+package screenshot
+
+import (
+	"image"
+	"image/color"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// evdev key codes used by the annotation editor
+const (
+	keyEsc        = 1
+	keyBackspace  = 14
+	keyEnter      = 28
+	keyY          = 21
+	keyZ          = 44
+	keyC          = 46
+	keySpace      = 57
+	keyKPEnter    = 96
+	keyLeftBrace  = 26
+	keyRightBrace = 27
+)
+
+var annotationToolKeys = map[uint32]AnnotationTool{
+	2: ToolArrow,
+	3: ToolRectangle,
+	4: ToolPen,
+	5: ToolText,
+	6: ToolStep,
+	7: ToolHighlighter,
+	8: ToolPixelate,
+	9: ToolBlur,
+}
+
+// US layout characters for text labels, unshifted and shifted.
+var keyRunes = map[uint32][2]rune{
+	2: {'1', '!'}, 3: {'2', '@'}, 4: {'3', '#'}, 5: {'4', '$'}, 6: {'5', '%'},
+	7: {'6', '^'}, 8: {'7', '&'}, 9: {'8', '*'}, 10: {'9', '('}, 11: {'0', ')'},
+	12: {'-', '_'}, 13: {'=', '+'},
+	16: {'q', 'Q'}, 17: {'w', 'W'}, 18: {'e', 'E'}, 19: {'r', 'R'}, 20: {'t', 'T'},
+	21: {'y', 'Y'}, 22: {'u', 'U'}, 23: {'i', 'I'}, 24: {'o', 'O'}, 25: {'p', 'P'},
+	26: {'[', '{'}, 27: {']', '}'},
+	30: {'a', 'A'}, 31: {'s', 'S'}, 32: {'d', 'D'}, 33: {'f', 'F'}, 34: {'g', 'G'},
+	35: {'h', 'H'}, 36: {'j', 'J'}, 37: {'k', 'K'}, 38: {'l', 'L'},
+	39: {';', ':'}, 40: {'\'', '"'}, 41: {'`', '~'}, 43: {'\\', '|'},
+	44: {'z', 'Z'}, 45: {'x', 'X'}, 46: {'c', 'C'}, 47: {'v', 'V'}, 48: {'b', 'B'},
+	49: {'n', 'N'}, 50: {'m', 'M'},
+	51: {',', '<'}, 52: {'.', '>'}, 53: {'/', '?'},
+	57: {' ', ' '},
+}
+
+func keyRune(key uint32, shift bool) (rune, bool) {
+	runes, ok := keyRunes[key]
+	if !ok {
+		return 0, false
+	}
+	if shift {
+		return runes[1], true
+	}
+	return runes[0], true
+}
+
+// startAnnotating keeps the overlay open after a region was chosen so it can
+// be annotated. bounds is the region in buffer pixels of the selection surface.
+func (r *RegionSelector) startAnnotating(bounds image.Rectangle) {
+	style := LoadOverlayStyle()
+	r.annotating = true
+	r.annotateRect = bounds
+	r.annotations = NewAnnotationEditor(color.NRGBA{R: style.AccentR, G: style.AccentG, B: style.AccentB, A: 255})
+	r.redrawAll()
+}
+
+func (r *RegionSelector) redrawAll() {
+	for _, os := range r.surfaces {
+		r.redrawSurface(os)
+	}
+}
+
+// annotationPoint maps surface coordinates to a point within the annotated
+// region.
+func (r *RegionSelector) annotationPoint(os *OutputSurface, x, y float64) image.Point {
+	srcBuf := r.getSourceBuffer(os)
+	scaleX, scaleY := 1.0, 1.0
+	if srcBuf != nil && os.logicalW > 0 {
+		scaleX = float64(srcBuf.Width) / float64(os.logicalW)
+		scaleY = float64(srcBuf.Height) / float64(os.logicalH)
+	}
+	return image.Pt(int(x*scaleX), int(y*scaleY)).Sub(r.annotateRect.Min)
+}
+
+func (r *RegionSelector) handleAnnotatePointerButton(button, state uint32) {
+	if r.activeSurface != r.selection.surface || button != 0x110 {
+		return
+	}
+
+	switch state {
+	case 1:
+		r.annotations.Begin(r.annotationPoint(r.activeSurface, r.pointerX, r.pointerY))
+		r.annotationDrag = true
+	case 0:
+		r.annotations.End()
+		r.annotationDrag = false
+	}
+	r.redrawSurface(r.selection.surface)
+}
+
+func (r *RegionSelector) handleAnnotatePointerMotion() {
+	if !r.annotationDrag || r.activeSurface != r.selection.surface {
+		return
+	}
+	r.annotations.Move(r.annotationPoint(r.activeSurface, r.pointerX, r.pointerY))
+	r.redrawSurface(r.selection.surface)
+}
+
+func (r *RegionSelector) handleAnnotateKey(key uint32) {
+	e := r.annotations
+
+	if e.Editing() && !r.ctrlHeld {
+		switch key {
+		case keyEsc:
+			e.CancelText()
+		case keyEnter, keyKPEnter:
+			e.CommitText()
+		case keyBackspace:
+			e.Backspace()
+		default:
+			ch, ok := keyRune(key, r.shiftHeld)
+			if !ok {
+				return
+			}
+			e.TypeRune(ch)
+		}
+		r.redrawSurface(r.selection.surface)
+		return
+	}
+
+	switch {
+	case key == keyEsc:
+		r.cancelled = true
+		r.running = false
+		return
+	case key == keyEnter || key == keyKPEnter || key == keySpace:
+		e.CommitText()
+		r.finishSelection()
+		return
+	case r.ctrlHeld && key == keyZ && r.shiftHeld, r.ctrlHeld && key == keyY:
+		e.Redo()
+	case r.ctrlHeld && key == keyZ:
+		e.Undo()
+	case key == keyC:
+		e.CycleColor()
+	case key == keyLeftBrace:
+		e.AdjustWidth(-1)
+	case key == keyRightBrace:
+		e.AdjustWidth(1)
+	default:
+		tool, ok := annotationToolKeys[key]
+		if !ok {
+			return
+		}
+		e.CommitText()
+		e.Tool = tool
+	}
+	r.redrawSurface(r.selection.surface)
+}
+
+func (r *RegionSelector) drawAnnotateOverlay(os *OutputSurface, renderBuf *ShmBuffer) {
+	data := renderBuf.Data()
+	stride := renderBuf.Stride
+	w, h := renderBuf.Width, renderBuf.Height
+	format := os.screenFormat
+
+	dimBuffer(data, stride, w, h)
+	if os != r.selection.surface {
+		return
+	}
+
+	rect := r.annotateRect.Intersect(image.Rect(0, 0, w, h))
+	r.copySourceRect(os, data, stride, rect)
+
+	canvas := bufferImage(renderBuf).SubImage(rect).(*image.RGBA)
+	r.annotations.Render(canvas, swapsRB(format))
+
+	r.drawBorder(data, stride, w, h, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), format)
+	r.drawAnnotateHUD(renderBuf, format)
+}
+
+func (r *RegionSelector) drawAnnotateHUD(renderBuf *ShmBuffer, format uint32) {
+	style := LoadOverlayStyle()
+	const padding, itemSpacing = 12, 18
+
+	e := r.annotations
+	face := basicfont.Face7x13
+
+	type hudItem struct {
+		key, desc string
+		active    bool
+	}
+	items := make([]hudItem, 0, len(annotationToolNames)+5)
+	for key := uint32(2); key <= 9; key++ {
+		tool := annotationToolKeys[key]
+		items = append(items, hudItem{string(keyRunes[key][0]), tool.String(), tool == e.Tool})
+	}
+	items = append(items,
+		hudItem{"C", "color", false},
+		hudItem{"[ ]", "size", false},
+		hudItem{"Ctrl+Z", "undo", false},
+		hudItem{"Enter", "save", false},
+		hudItem{"Esc", "cancel", false},
+	)
+	if e.Editing() {
+		items = []hudItem{
+			{"Type", "label text", false},
+			{"Enter", "done", false},
+			{"Esc", "discard", false},
+		}
+	}
+
+	const swatch = 13
+	totalW := swatch + itemSpacing
+	for _, item := range items {
+		totalW += font.MeasureString(face, item.key+" "+item.desc).Ceil() + itemSpacing
+	}
+	totalW -= itemSpacing
+
+	bufW, bufH := renderBuf.Width, renderBuf.Height
+	hudW := totalW + padding*2
+	hudH := face.Height + padding*2
+	hudX := (bufW - hudW) / 2
+	hudY := bufH - hudH - 20
+
+	data := renderBuf.Data()
+	fillRect(data, renderBuf.Stride, bufW, bufH, hudX, hudY, hudW, hudH,
+		style.BackgroundR, style.BackgroundG, style.BackgroundB, style.BackgroundA, format)
+
+	swap := swapsRB(format)
+	c := e.Color
+	c.A = 255
+	fillRect(data, renderBuf.Stride, bufW, bufH, hudX+padding, hudY+padding, swatch, swatch, c.R, c.G, c.B, 255, format)
+
+	img := bufferImage(renderBuf)
+	textColor := func(red, green, blue uint8) *image.Uniform {
+		if swap {
+			red, blue = blue, red
+		}
+		return image.NewUniform(color.NRGBA{R: red, G: green, B: blue, A: 255})
+	}
+	accent := textColor(style.AccentR, style.AccentG, style.AccentB)
+	text := textColor(style.TextR, style.TextG, style.TextB)
+
+	d := font.Drawer{
+		Dst:  img,
+		Face: face,
+		Dot:  fixed.P(hudX+padding+swatch+itemSpacing, hudY+padding+face.Ascent),
+	}
+	for _, item := range items {
+		start := d.Dot.X
+		d.Src = accent
+		d.DrawString(item.key + " ")
+		if !item.active {
+			d.Src = text
+		}
+		d.DrawString(item.desc)
+		if item.active {
+			underline := hudY + padding + face.Height
+			fillRect(data, renderBuf.Stride, bufW, bufH, start.Ceil(), underline, (d.Dot.X - start).Ceil(), 2,
+				style.AccentR, style.AccentG, style.AccentB, 255, format)
+		}
+		d.Dot.X += fixed.I(itemSpacing)
+	}
+}
+
+// applyAnnotations renders the annotations onto the final cropped capture.
+func (r *RegionSelector) applyAnnotations(cropped *ShmBuffer, format uint32, yInverted bool) {
+	if r.annotations == nil || r.annotations.Empty() {
+		return
+	}
+	if yInverted {
+		cropped.FlipVertical()
+		defer cropped.FlipVertical()
+	}
+	r.annotations.Render(bufferImage(cropped), swapsRB(format))
+}
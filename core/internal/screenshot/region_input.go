@@ -1,6 +1,8 @@
 package screenshot
 
 import (
+	"image"
+
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
 )
 
@@ -51,6 +53,11 @@ func (r *RegionSelector) setupPointerHandlers() {
 		r.pointerX = e.SurfaceX
 		r.pointerY = e.SurfaceY
 
+		if r.annotating {
+			r.handleAnnotatePointerMotion()
+			return
+		}
+
 		if !r.selection.dragging {
 			return
 		}
@@ -94,6 +101,11 @@ func (r *RegionSelector) setupPointerHandlers() {
 			return
 		}
 
+		if r.annotating {
+			r.handleAnnotatePointerButton(e.Button, e.State)
+			return
+		}
+
 		switch e.Button {
 		case 0x110: // BTN_LEFT
 			switch e.State {
@@ -125,6 +137,7 @@ func (r *RegionSelector) setupPointerHandlers() {
 func (r *RegionSelector) setupKeyboardHandlers() {
 	r.keyboard.SetModifiersHandler(func(e client.KeyboardModifiersEvent) {
 		r.shiftHeld = e.ModsDepressed&1 != 0
+		r.ctrlHeld = e.ModsDepressed&4 != 0
 	})
 
 	r.keyboard.SetKeyHandler(func(e client.KeyboardKeyEvent) {
@@ -132,6 +145,11 @@ func (r *RegionSelector) setupKeyboardHandlers() {
 			return
 		}
 
+		if r.annotating {
+			r.handleAnnotateKey(e.Key)
+			return
+		}
+
 		switch e.Key {
 		case 1:
 			r.cancelled = true
@@ -162,55 +180,20 @@ func (r *RegionSelector) finishSelection() {
 		return
 	}
 
-	x1, y1 := r.selection.anchorX, r.selection.anchorY
-	x2, y2 := r.selection.currentX, r.selection.currentY
-
-	if x1 > x2 {
-		x1, x2 = x2, x1
-	}
-	if y1 > y2 {
-		y1, y2 = y2, y1
+	var bounds image.Rectangle
+	if r.annotating {
+		bounds = r.annotateRect
+	} else {
+		bounds = r.selectionBounds(os, srcBuf)
 	}
 
-	scaleX, scaleY := 1.0, 1.0
-	if os.logicalW > 0 {
-		scaleX = float64(srcBuf.Width) / float64(os.logicalW)
-		scaleY = float64(srcBuf.Height) / float64(os.logicalH)
+	if r.screenshoter.config.Annotate && !r.annotating {
+		r.startAnnotating(bounds)
+		return
 	}
 
-	bx1 := int(x1 * scaleX)
-	by1 := int(y1 * scaleY)
-	bx2 := int(x2 * scaleX)
-	by2 := int(y2 * scaleY)
-
-	// Clamp to buffer bounds
-	if bx1 < 0 {
-		bx1 = 0
-	}
-	if by1 < 0 {
-		by1 = 0
-	}
-	if bx2 > srcBuf.Width {
-		bx2 = srcBuf.Width
-	}
-	if by2 > srcBuf.Height {
-		by2 = srcBuf.Height
-	}
-
-	w, h := bx2-bx1+1, by2-by1+1
-	if r.shiftHeld && w != h {
-		if w < h {
-			h = w
-		} else {
-			w = h
-		}
-	}
-	if w < 1 {
-		w = 1
-	}
-	if h < 1 {
-		h = 1
-	}
+	bx1, by1 := bounds.Min.X, bounds.Min.Y
+	w, h := bounds.Dx(), bounds.Dy()
 
 	// Create cropped buffer and copy pixels directly
 	cropped, err := CreateShmBuffer(w, h, w*4)
@@ -249,6 +232,8 @@ func (r *RegionSelector) finishSelection() {
 		}
 	}
 
+	r.applyAnnotations(cropped, os.screenFormat, os.yInverted)
+
 	r.capturedBuffer = cropped
 	r.capturedRegion = Region{
 		X:      int32(bx1),
@@ -269,3 +254,58 @@ func (r *RegionSelector) finishSelection() {
 
 	r.running = false
 }
+
+// selectionBounds returns the selected region in buffer pixels of os.
+func (r *RegionSelector) selectionBounds(os *OutputSurface, srcBuf *ShmBuffer) image.Rectangle {
+	x1, y1 := r.selection.anchorX, r.selection.anchorY
+	x2, y2 := r.selection.currentX, r.selection.currentY
+
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+
+	scaleX, scaleY := 1.0, 1.0
+	if os.logicalW > 0 {
+		scaleX = float64(srcBuf.Width) / float64(os.logicalW)
+		scaleY = float64(srcBuf.Height) / float64(os.logicalH)
+	}
+
+	bx1 := int(x1 * scaleX)
+	by1 := int(y1 * scaleY)
+	bx2 := int(x2 * scaleX)
+	by2 := int(y2 * scaleY)
+
+	// Clamp to buffer bounds
+	if bx1 < 0 {
+		bx1 = 0
+	}
+	if by1 < 0 {
+		by1 = 0
+	}
+	if bx2 > srcBuf.Width {
+		bx2 = srcBuf.Width
+	}
+	if by2 > srcBuf.Height {
+		by2 = srcBuf.Height
+	}
+
+	w, h := bx2-bx1+1, by2-by1+1
+	if r.shiftHeld && w != h {
+		if w < h {
+			h = w
+		} else {
+			w = h
+		}
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	return image.Rect(bx1, by1, bx1+w, by1+h)
+}
@@ -1,6 +1,9 @@
 package screenshot
 
-import "fmt"
+import (
+	"fmt"
+	"image"
+)
 
 var fontGlyphs = map[rune][12]uint8{
 	'0': {0x3C, 0x66, 0x66, 0x6E, 0x76, 0x66, 0x66, 0x66, 0x66, 0x3C, 0x00, 0x00},
@@ -57,20 +60,13 @@ func (r *RegionSelector) drawOverlay(os *OutputSurface, renderBuf *ShmBuffer) {
 	w, h := renderBuf.Width, renderBuf.Height
 	format := os.screenFormat
 
-	// Dim the entire buffer
-	for y := 0; y < h; y++ {
-		off := y * stride
-		for x := 0; x < w; x++ {
-			i := off + x*4
-			if i+3 >= len(data) {
-				continue
-			}
-			data[i+0] = uint8(int(data[i+0]) * 3 / 5)
-			data[i+1] = uint8(int(data[i+1]) * 3 / 5)
-			data[i+2] = uint8(int(data[i+2]) * 3 / 5)
-		}
+	if r.annotating {
+		r.drawAnnotateOverlay(os, renderBuf)
+		return
 	}
 
+	dimBuffer(data, stride, w, h)
+
 	r.drawHUD(data, stride, w, h, format)
 
 	if !r.selection.hasSelection || r.selection.surface != os {
@@ -97,11 +93,42 @@ func (r *RegionSelector) drawOverlay(os *OutputSurface, renderBuf *ShmBuffer) {
 	bx2 = clamp(bx2, 0, w-1)
 	by2 = clamp(by2, 0, h-1)
 
+	r.copySourceRect(os, data, stride, image.Rect(bx1, by1, bx2+1, by2+1))
+
+	selW, selH := bx2-bx1+1, by2-by1+1
+	if r.shiftHeld && selW != selH {
+		if selW < selH {
+			selH = selW
+		} else {
+			selW = selH
+		}
+	}
+	r.drawBorder(data, stride, w, h, bx1, by1, selW, selH, format)
+	r.drawDimensions(data, stride, w, h, bx1, by1, selW, selH, format)
+}
+
+func dimBuffer(data []byte, stride, w, h int) {
+	for y := 0; y < h; y++ {
+		off := y * stride
+		for x := 0; x < w; x++ {
+			i := off + x*4
+			if i+3 >= len(data) {
+				continue
+			}
+			data[i+0] = uint8(int(data[i+0]) * 3 / 5)
+			data[i+1] = uint8(int(data[i+1]) * 3 / 5)
+			data[i+2] = uint8(int(data[i+2]) * 3 / 5)
+		}
+	}
+}
+
+// copySourceRect restores the undimmed screen content of rect.
+func (r *RegionSelector) copySourceRect(os *OutputSurface, data []byte, stride int, rect image.Rectangle) {
 	srcBuf := r.getSourceBuffer(os)
 	srcData := srcBuf.Data()
-	for y := by1; y <= by2; y++ {
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
 		rowOff := y * stride
-		for x := bx1; x <= bx2; x++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
 			si := y*srcBuf.Stride + x*4
 			di := rowOff + x*4
 			if si+3 >= len(srcData) || di+3 >= len(data) {
@@ -113,17 +140,6 @@ func (r *RegionSelector) drawOverlay(os *OutputSurface, renderBuf *ShmBuffer) {
 			data[di+3] = srcData[si+3]
 		}
 	}
-
-	selW, selH := bx2-bx1+1, by2-by1+1
-	if r.shiftHeld && selW != selH {
-		if selW < selH {
-			selH = selW
-		} else {
-			selW = selH
-		}
-	}
-	r.drawBorder(data, stride, w, h, bx1, by1, selW, selH, format)
-	r.drawDimensions(data, stride, w, h, bx1, by1, selW, selH, format)
 }
 
 func (r *RegionSelector) drawHUD(data []byte, stride, bufW, bufH int, format uint32) {
@@ -139,8 +155,13 @@ func (r *RegionSelector) drawHUD(data []byte, stride, bufW, bufH int, format uin
 		cursorLabel = "show"
 	}
 
+	captureLabel := "capture"
+	if r.screenshoter.config.Annotate {
+		captureLabel = "annotate"
+	}
+
 	items := []struct{ key, desc string }{
-		{"Space/Enter", "capture"},
+		{"Space/Enter", captureLabel},
 		{"P", cursorLabel + " cursor"},
 		{"Esc", "cancel"},
 	}
@@ -158,16 +179,16 @@ func (r *RegionSelector) drawHUD(data []byte, stride, bufW, bufH int, format uin
 	hudX := (bufW - hudW) / 2
 	hudY := bufH - hudH - 20
 
-	r.fillRect(data, stride, bufW, bufH, hudX, hudY, hudW, hudH,
+	fillRect(data, stride, bufW, bufH, hudX, hudY, hudW, hudH,
 		style.BackgroundR, style.BackgroundG, style.BackgroundB, style.BackgroundA, format)
 
 	tx, ty := hudX+padding, hudY+padding
 	for i, item := range items {
-		r.drawText(data, stride, bufW, bufH, tx, ty, item.key,
+		drawText(data, stride, bufW, bufH, tx, ty, item.key,
 			style.AccentR, style.AccentG, style.AccentB, format)
 		tx += len(item.key) * (charW + 1)
 
-		r.drawText(data, stride, bufW, bufH, tx, ty, " "+item.desc,
+		drawText(data, stride, bufW, bufH, tx, ty, " "+item.desc,
 			style.TextR, style.TextG, style.TextB, format)
 		tx += (1 + len(item.desc)) * (charW + 1)
 
@@ -237,11 +258,11 @@ func (r *RegionSelector) drawDimensions(data []byte, stride, bufW, bufH, x, y, w
 	}
 	tx = clamp(tx, 0, bufW-textW)
 
-	r.fillRect(data, stride, bufW, bufH, tx-4, ty-2, textW+8, textH+4, 0, 0, 0, 200, format)
-	r.drawText(data, stride, bufW, bufH, tx, ty, text, 255, 255, 255, format)
+	fillRect(data, stride, bufW, bufH, tx-4, ty-2, textW+8, textH+4, 0, 0, 0, 200, format)
+	drawText(data, stride, bufW, bufH, tx, ty, text, 255, 255, 255, format)
 }
 
-func (r *RegionSelector) fillRect(data []byte, stride, bufW, bufH, x, y, w, h int, cr, cg, cb, ca uint8, format uint32) {
+func fillRect(data []byte, stride, bufW, bufH, x, y, w, h int, cr, cg, cb, ca uint8, format uint32) {
 	alpha := float64(ca) / 255.0
 	invAlpha := 1.0 - alpha
 
@@ -270,13 +291,18 @@ func (r *RegionSelector) fillRect(data []byte, stride, bufW, bufH, x, y, w, h in
 	}
 }
 
-func (r *RegionSelector) drawText(data []byte, stride, bufW, bufH, x, y int, text string, cr, cg, cb uint8, format uint32) {
+func drawText(data []byte, stride, bufW, bufH, x, y int, text string, cr, cg, cb uint8, format uint32) {
+	drawTextScaled(data, stride, bufW, bufH, x, y, text, 1, cr, cg, cb, format)
+}
+
+// drawTextScaled draws text with every glyph pixel blown up to scale×scale.
+func drawTextScaled(data []byte, stride, bufW, bufH, x, y int, text string, scale int, cr, cg, cb uint8, format uint32) {
 	for i, ch := range text {
-		r.drawChar(data, stride, bufW, bufH, x+i*9, y, ch, cr, cg, cb, format)
+		drawChar(data, stride, bufW, bufH, x+i*9*scale, y, ch, scale, cr, cg, cb, format)
 	}
 }
 
-func (r *RegionSelector) drawChar(data []byte, stride, bufW, bufH, x, y int, ch rune, cr, cg, cb uint8, format uint32) {
+func drawChar(data []byte, stride, bufW, bufH, x, y int, ch rune, scale int, cr, cg, cb uint8, format uint32) {
 	glyph, ok := fontGlyphs[ch]
 	if !ok {
 		return
@@ -287,14 +313,14 @@ func (r *RegionSelector) drawChar(data []byte, stride, bufW, bufH, x, y int, ch
 		c0, c2 = cr, cb
 	}
 
-	for row := 0; row < 12; row++ {
+	for row := 0; row < 12*scale; row++ {
 		py := y + row
 		if py < 0 || py >= bufH {
 			continue
 		}
-		bits := glyph[row]
-		for col := 0; col < 8; col++ {
-			if (bits & (1 << (7 - col))) == 0 {
+		bits := glyph[row/scale]
+		for col := 0; col < 8*scale; col++ {
+			if (bits & (1 << (7 - col/scale))) == 0 {
 				continue
 			}
 			px := x + col
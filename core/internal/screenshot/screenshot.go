@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/compositor"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_layer_shell"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_screencopy"
 	wlhelpers "github.com/AvengeMedia/DankMaterialShell/core/internal/wayland/client"
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
@@ -27,6 +29,7 @@ type CaptureResult struct {
 	Region    Region
 	YInverted bool
 	Format    uint32
+	AppID     string
 }
 
 type Screenshoter struct {
@@ -39,6 +42,8 @@ type Screenshoter struct {
 	compositor *client.Compositor
 	shm        *client.Shm
 	screencopy *wlr_screencopy.ZwlrScreencopyManagerV1
+	scVersion  uint32
+	layerShell *wlr_layer_shell.ZwlrLayerShellV1
 
 	outputs   map[uint32]*WaylandOutput
 	outputsMu sync.Mutex
@@ -52,25 +57,13 @@ func New(config Config) *Screenshoter {
 }
 
 func (s *Screenshoter) Run() (*CaptureResult, error) {
-	if err := s.connect(); err != nil {
-		return nil, fmt.Errorf("wayland connect: %w", err)
+	if err := s.setup(); err != nil {
+		return nil, err
 	}
 	defer s.cleanup()
 
-	if err := s.setupRegistry(); err != nil {
-		return nil, fmt.Errorf("registry setup: %w", err)
-	}
-
-	if err := s.roundtrip(); err != nil {
-		return nil, fmt.Errorf("roundtrip: %w", err)
-	}
-
-	if s.screencopy == nil {
-		return nil, fmt.Errorf("compositor does not support wlr-screencopy-unstable-v1")
-	}
-
-	if err := s.roundtrip(); err != nil {
-		return nil, fmt.Errorf("roundtrip: %w", err)
+	if s.config.Delay > 0 {
+		s.wait(s.config.Delay)
 	}
 
 	switch s.config.Mode {
@@ -91,6 +84,33 @@ func (s *Screenshoter) Run() (*CaptureResult, error) {
 	}
 }
 
+func (s *Screenshoter) setup() error {
+	if err := s.connect(); err != nil {
+		return fmt.Errorf("wayland connect: %w", err)
+	}
+
+	if err := s.setupRegistry(); err != nil {
+		s.cleanup()
+		return fmt.Errorf("registry setup: %w", err)
+	}
+
+	if err := s.roundtrip(); err != nil {
+		s.cleanup()
+		return fmt.Errorf("roundtrip: %w", err)
+	}
+
+	if s.screencopy == nil {
+		s.cleanup()
+		return fmt.Errorf("compositor does not support wlr-screencopy-unstable-v1")
+	}
+
+	if err := s.roundtrip(); err != nil {
+		s.cleanup()
+		return fmt.Errorf("roundtrip: %w", err)
+	}
+	return nil
+}
+
 func (s *Screenshoter) captureLastRegion() (*CaptureResult, error) {
 	lastRegion := GetLastRegion()
 	if lastRegion.IsEmpty() {
@@ -146,14 +166,19 @@ func (s *Screenshoter) captureWindow() (*CaptureResult, error) {
 		return nil, fmt.Errorf("could not find output for window")
 	}
 
-	switch DetectCompositor() {
-	case CompositorHyprland:
-		return s.captureAndCrop(output, region)
-	case CompositorDWL:
-		return s.captureDWLWindow(output, region, geom)
+	var result *CaptureResult
+	switch compositor.Detect() {
+	case compositor.Hyprland:
+		result, err = s.captureAndCrop(output, region)
+	case compositor.DWL:
+		result, err = s.captureDWLWindow(output, region, geom)
 	default:
-		return s.captureRegionOnOutput(output, region)
+		result, err = s.captureRegionOnOutput(output, region)
+	}
+	if result != nil {
+		result.AppID = geom.AppID
 	}
+	return result, err
 }
 
 func (s *Screenshoter) captureDWLWindow(output *WaylandOutput, region Region, geom *WindowGeometry) (*CaptureResult, error) {
@@ -324,15 +349,15 @@ func (s *Screenshoter) captureAllScreens() (*CaptureResult, error) {
 
 		outX, outY := output.x, output.y
 		scale := float64(output.scale)
-		switch DetectCompositor() {
-		case CompositorHyprland:
+		switch compositor.Detect() {
+		case compositor.Hyprland:
 			if hx, hy, _, _, ok := GetHyprlandMonitorGeometry(output.name); ok {
 				outX, outY = hx, hy
 			}
 			if s := GetHyprlandMonitorScale(output.name); s > 0 {
 				scale = s
 			}
-		case CompositorDWL:
+		case compositor.DWL:
 			if info, ok := getOutputInfo(output.name); ok {
 				outX, outY = info.x, info.y
 			}
@@ -580,8 +605,22 @@ func (s *Screenshoter) captureRegionOnOutput(output *WaylandOutput, region Regio
 		return s.captureRegionOnTransformedOutput(output, region)
 	}
 
+	localX, localY, w, h := s.localRegion(output, region)
+
+	cursor := int32(s.config.Cursor)
+
+	frame, err := s.screencopy.CaptureOutputRegion(cursor, output.wlOutput, localX, localY, w, h)
+	if err != nil {
+		return nil, fmt.Errorf("capture region: %w", err)
+	}
+
+	return s.processFrame(frame, region)
+}
+
+// outputScale returns the factor between logical and buffer pixels of output.
+func (s *Screenshoter) outputScale(output *WaylandOutput) float64 {
 	scale := output.fractionalScale
-	if scale <= 0 && DetectCompositor() == CompositorHyprland {
+	if scale <= 0 && compositor.Detect() == compositor.Hyprland {
 		scale = GetHyprlandMonitorScale(output.name)
 	}
 	if scale <= 0 {
@@ -590,13 +629,20 @@ func (s *Screenshoter) captureRegionOnOutput(output *WaylandOutput, region Regio
 	if scale <= 0 {
 		scale = 1.0
 	}
+	return scale
+}
+
+// localRegion converts region to buffer pixels relative to an untransformed
+// output.
+func (s *Screenshoter) localRegion(output *WaylandOutput, region Region) (x, y, w, h int32) {
+	scale := s.outputScale(output)
 
 	localX := int32(float64(region.X-output.x) * scale)
 	localY := int32(float64(region.Y-output.y) * scale)
-	w := int32(float64(region.Width) * scale)
-	h := int32(float64(region.Height) * scale)
+	w = int32(float64(region.Width) * scale)
+	h = int32(float64(region.Height) * scale)
 
-	if DetectCompositor() == CompositorDWL {
+	if compositor.Detect() == compositor.DWL {
 		scaledOutW := int32(float64(output.width) * scale)
 		scaledOutH := int32(float64(output.height) * scale)
 		if localX >= scaledOutW {
@@ -621,14 +667,7 @@ func (s *Screenshoter) captureRegionOnOutput(output *WaylandOutput, region Regio
 		}
 	}
 
-	cursor := int32(s.config.Cursor)
-
-	frame, err := s.screencopy.CaptureOutputRegion(cursor, output.wlOutput, localX, localY, w, h)
-	if err != nil {
-		return nil, fmt.Errorf("capture region: %w", err)
-	}
-
-	return s.processFrame(frame, region)
+	return localX, localY, w, h
 }
 
 func (s *Screenshoter) captureRegionOnTransformedOutput(output *WaylandOutput, region Region) (*CaptureResult, error) {
@@ -637,16 +676,7 @@ func (s *Screenshoter) captureRegionOnTransformedOutput(output *WaylandOutput, r
 		return nil, err
 	}
 
-	scale := output.fractionalScale
-	if scale <= 0 && DetectCompositor() == CompositorHyprland {
-		scale = GetHyprlandMonitorScale(output.name)
-	}
-	if scale <= 0 {
-		scale = float64(output.scale)
-	}
-	if scale <= 0 {
-		scale = 1.0
-	}
+	scale := s.outputScale(output)
 
 	localX := int(float64(region.X-output.x) * scale)
 	localY := int(float64(region.Y-output.y) * scale)
@@ -826,7 +856,7 @@ func (s *Screenshoter) findOutputForRegion(region Region) *WaylandOutput {
 
 	for _, o := range s.outputs {
 		x, y, w, h := o.x, o.y, o.width, o.height
-		if DetectCompositor() == CompositorHyprland {
+		if compositor.Detect() == compositor.Hyprland {
 			if hx, hy, hw, hh, ok := GetHyprlandMonitorGeometry(o.name); ok {
 				x, y, w, h = hx, hy, hw, hh
 			}
@@ -838,7 +868,7 @@ func (s *Screenshoter) findOutputForRegion(region Region) *WaylandOutput {
 
 	for _, o := range s.outputs {
 		x, y, w, h := o.x, o.y, o.width, o.height
-		if DetectCompositor() == CompositorHyprland {
+		if compositor.Detect() == compositor.Hyprland {
 			if hx, hy, hw, hh, ok := GetHyprlandMonitorGeometry(o.name); ok {
 				x, y, w, h = hx, hy, hw, hh
 			}
@@ -944,6 +974,17 @@ func (s *Screenshoter) handleGlobal(e client.RegistryGlobalEvent) {
 		}
 		if err := s.registry.Bind(e.Name, e.Interface, version, sc); err == nil {
 			s.screencopy = sc
+			s.scVersion = version
+		}
+
+	case wlr_layer_shell.ZwlrLayerShellV1InterfaceName:
+		ls := wlr_layer_shell.NewZwlrLayerShellV1(s.ctx)
+		version := e.Version
+		if version > 4 {
+			version = 4
+		}
+		if err := s.registry.Bind(e.Name, e.Interface, version, ls); err == nil {
+			s.layerShell = ls
 		}
 	}
 }
@@ -991,6 +1032,9 @@ func (s *Screenshoter) cleanup() {
 	if s.screencopy != nil {
 		s.screencopy.Destroy()
 	}
+	if s.layerShell != nil {
+		s.layerShell.Destroy()
+	}
 	if s.display != nil {
 		s.ctx.Close()
 	}
@@ -1026,7 +1070,7 @@ func ListOutputs() ([]Output, error) {
 	sc.outputsMu.Lock()
 	defer sc.outputsMu.Unlock()
 
-	compositor := DetectCompositor()
+	comp := compositor.Detect()
 	result := make([]Output, 0, len(sc.outputs))
 	for _, o := range sc.outputs {
 		out := Output{
@@ -1040,8 +1084,8 @@ func ListOutputs() ([]Output, error) {
 			Transform:       o.transform,
 		}
 
-		switch compositor {
-		case CompositorHyprland:
+		switch comp {
+		case compositor.Hyprland:
 			if hx, hy, hw, hh, ok := GetHyprlandMonitorGeometry(o.name); ok {
 				out.X, out.Y = hx, hy
 				out.Width, out.Height = hw, hh
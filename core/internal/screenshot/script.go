@@ -0,0 +1,156 @@
+package screenshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ScriptCapture is one named capture of a script. A region is in global
+// layout coordinates, or relative to Output when both are given.
+type ScriptCapture struct {
+	Name   string  `json:"name"`
+	Mode   string  `json:"mode,omitempty"`
+	Output string  `json:"output,omitempty"`
+	Region *Region `json:"region,omitempty"`
+}
+
+// Script lists captures taken in one pass over a single compositor
+// connection. Delay is in seconds.
+type Script struct {
+	Delay    float64         `json:"delay,omitempty"`
+	Dir      string          `json:"dir,omitempty"`
+	Captures []ScriptCapture `json:"captures"`
+}
+
+type ScriptResult struct {
+	Name   string
+	Result *CaptureResult
+	Err    error
+}
+
+// ParseScript accepts either a Script object or a bare list of captures.
+func ParseScript(data []byte) (*Script, error) {
+	var script Script
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &script.Captures); err != nil {
+			return nil, fmt.Errorf("parse script: %w", err)
+		}
+	} else if err := json.Unmarshal(trimmed, &script); err != nil {
+		return nil, fmt.Errorf("parse script: %w", err)
+	}
+
+	if len(script.Captures) == 0 {
+		return nil, fmt.Errorf("script has no captures")
+	}
+	if script.Delay < 0 {
+		return nil, fmt.Errorf("delay must not be negative")
+	}
+
+	seen := make(map[string]bool)
+	for i := range script.Captures {
+		c := &script.Captures[i]
+		switch {
+		case c.Name == "":
+			return nil, fmt.Errorf("capture %d has no name", i+1)
+		case strings.ContainsAny(c.Name, "/\\") || c.Name == "." || c.Name == "..":
+			return nil, fmt.Errorf("capture name %q is not a valid file name", c.Name)
+		case seen[c.Name]:
+			return nil, fmt.Errorf("capture name %q is used twice", c.Name)
+		}
+		seen[c.Name] = true
+
+		if err := c.resolveMode(); err != nil {
+			return nil, fmt.Errorf("capture %q: %w", c.Name, err)
+		}
+	}
+
+	return &script, nil
+}
+
+func (c *ScriptCapture) resolveMode() error {
+	if c.Mode == "" {
+		switch {
+		case c.Region != nil:
+			c.Mode = "region"
+		case c.Output != "":
+			c.Mode = "output"
+		default:
+			return fmt.Errorf("needs a mode, region or output")
+		}
+	}
+
+	switch c.Mode {
+	case "region":
+		if c.Region == nil || c.Region.IsEmpty() {
+			return fmt.Errorf("region mode needs a non-empty region")
+		}
+	case "output":
+		if c.Output == "" {
+			return fmt.Errorf("output mode needs an output name")
+		}
+	case "full", "all", "window":
+	default:
+		return fmt.Errorf("unsupported mode %q (region, output, full, all, window)", c.Mode)
+	}
+	return nil
+}
+
+func (s *Script) DelayDuration() time.Duration {
+	return time.Duration(s.Delay * float64(time.Second))
+}
+
+// RunScript takes every capture of the script in order. A failed capture
+// is reported in its result and does not stop the others.
+func (s *Screenshoter) RunScript(script *Script) ([]ScriptResult, error) {
+	if err := s.setup(); err != nil {
+		return nil, err
+	}
+	defer s.cleanup()
+
+	if delay := script.DelayDuration(); delay > 0 {
+		s.wait(delay)
+	}
+
+	results := make([]ScriptResult, 0, len(script.Captures))
+	for _, c := range script.Captures {
+		result, err := s.captureScripted(c)
+		results = append(results, ScriptResult{Name: c.Name, Result: result, Err: err})
+	}
+	return results, nil
+}
+
+func (s *Screenshoter) captureScripted(c ScriptCapture) (*CaptureResult, error) {
+	switch c.Mode {
+	case "output":
+		return s.captureOutput(c.Output)
+	case "full":
+		return s.captureFullScreen()
+	case "all":
+		return s.captureAllScreens()
+	case "window":
+		return s.captureWindow()
+	}
+
+	region := *c.Region
+	var output *WaylandOutput
+	if c.Output != "" {
+		output = s.findOutputByName(c.Output)
+		if output == nil {
+			return nil, fmt.Errorf("output %q not found", c.Output)
+		}
+		region.X += output.x
+		region.Y += output.y
+		region.Output = output.name
+	} else {
+		output = s.findOutputForRegion(region)
+		if output == nil {
+			return nil, fmt.Errorf("region %dx%d+%d+%d is not on any output", region.Width, region.Height, region.X, region.Y)
+		}
+	}
+	return s.captureRegionOnOutput(output, region)
+}
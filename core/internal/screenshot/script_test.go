@@ -0,0 +1,54 @@
+package screenshot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScript(t *testing.T) {
+	script, err := ParseScript([]byte(`{
+		"delay": 1.5,
+		"dir": "~/qa",
+		"captures": [
+			{"name": "toolbar", "region": {"x": 0, "y": 0, "width": 100, "height": 20}},
+			{"name": "side", "output": "DP-2", "region": {"x": 0, "y": 20, "width": 50, "height": 80}},
+			{"name": "screen", "output": "HDMI-A-1"},
+			{"name": "focused", "mode": "window"}
+		]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, script.DelayDuration())
+	assert.Equal(t, "~/qa", script.Dir)
+
+	var modes []string
+	for _, c := range script.Captures {
+		modes = append(modes, c.Mode)
+	}
+	assert.Equal(t, []string{"region", "region", "output", "window"}, modes)
+
+	bare, err := ParseScript([]byte(`[{"name": "all", "mode": "all"}]`))
+	require.NoError(t, err)
+	require.Len(t, bare.Captures, 1)
+	assert.Equal(t, "all", bare.Captures[0].Mode)
+}
+
+func TestParseScriptErrors(t *testing.T) {
+	for name, input := range map[string]string{
+		"not json":       `captures:`,
+		"empty":          `{"captures": []}`,
+		"negative delay": `{"delay": -1, "captures": [{"name": "a", "mode": "full"}]}`,
+		"no name":        `[{"mode": "full"}]`,
+		"path in name":   `[{"name": "../a", "mode": "full"}]`,
+		"duplicate":      `[{"name": "a", "mode": "full"}, {"name": "a", "mode": "all"}]`,
+		"no target":      `[{"name": "a"}]`,
+		"empty region":   `[{"name": "a", "region": {"x": 0, "y": 0, "width": 0, "height": 10}}]`,
+		"interactive":    `[{"name": "a", "mode": "last"}]`,
+		"output no name": `[{"name": "a", "mode": "output"}]`,
+	} {
+		_, err := ParseScript([]byte(input))
+		assert.Error(t, err, name)
+	}
+}
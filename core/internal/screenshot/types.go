@@ -1,5 +1,11 @@
 package screenshot
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type Mode int
 
 const (
@@ -11,14 +17,157 @@ const (
 	ModeLastRegion
 )
 
+var modeNames = map[Mode]string{
+	ModeRegion:     "region",
+	ModeWindow:     "window",
+	ModeFullScreen: "full",
+	ModeAllScreens: "all",
+	ModeOutput:     "output",
+	ModeLastRegion: "last",
+}
+
+func (m Mode) String() string {
+	if name, ok := modeNames[m]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func ParseMode(s string) (Mode, error) {
+	for m, name := range modeNames {
+		if s == name {
+			return m, nil
+		}
+	}
+	return ModeRegion, fmt.Errorf("unknown mode: %s (region, window, full, all, output, last)", s)
+}
+
 type Format int
 
 const (
 	FormatPNG Format = iota
 	FormatJPEG
 	FormatPPM
+	FormatWebP
+	FormatQOI
+)
+
+func (f Format) Extension() string {
+	switch f {
+	case FormatJPEG:
+		return "jpg"
+	case FormatPPM:
+		return "ppm"
+	case FormatWebP:
+		return "webp"
+	case FormatQOI:
+		return "qoi"
+	default:
+		return "png"
+	}
+}
+
+func (f Format) MimeType() string {
+	switch f {
+	case FormatJPEG:
+		return "image/jpeg"
+	case FormatPPM:
+		return "image/x-portable-pixmap"
+	case FormatWebP:
+		return "image/webp"
+	case FormatQOI:
+		return "image/qoi"
+	default:
+		return "image/png"
+	}
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "", "png":
+		return FormatPNG, nil
+	case "jpg", "jpeg":
+		return FormatJPEG, nil
+	case "ppm":
+		return FormatPPM, nil
+	case "webp":
+		return FormatWebP, nil
+	case "qoi":
+		return FormatQOI, nil
+	case "avif":
+		// there is no pure Go AV1 encoder; use webp for small lossless files
+		return FormatPNG, fmt.Errorf("avif is not supported: it needs an AV1 encoder that is only available through cgo (use webp)")
+	}
+	return FormatPNG, fmt.Errorf("unknown format: %s (png, jpg, ppm, webp, qoi)", s)
+}
+
+// PNGCompression trades encoding time for file size. The zero value keeps
+// the fast default so interactive captures stay responsive.
+type PNGCompression int
+
+const (
+	PNGCompressionFast PNGCompression = iota
+	PNGCompressionDefault
+	PNGCompressionBest
+	PNGCompressionNone
+)
+
+var pngCompressionNames = map[PNGCompression]string{
+	PNGCompressionFast:    "fast",
+	PNGCompressionDefault: "default",
+	PNGCompressionBest:    "best",
+	PNGCompressionNone:    "none",
+}
+
+func (c PNGCompression) String() string {
+	if name, ok := pngCompressionNames[c]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func ParsePNGCompression(s string) (PNGCompression, error) {
+	for c, name := range pngCompressionNames {
+		if strings.EqualFold(s, name) {
+			return c, nil
+		}
+	}
+	return PNGCompressionFast, fmt.Errorf("unknown png compression: %s (fast, default, best, none)", s)
+}
+
+// PaletteMode controls whether PNGs are written with an 8-bit palette.
+// PaletteAuto only does so when the image has at most 256 colors, which
+// keeps the file lossless; PaletteOn dithers everything else.
+type PaletteMode int
+
+const (
+	PaletteOff PaletteMode = iota
+	PaletteAuto
+	PaletteOn
 )
 
+var paletteModeNames = map[PaletteMode]string{
+	PaletteOff:  "off",
+	PaletteAuto: "auto",
+	PaletteOn:   "on",
+}
+
+func (p PaletteMode) String() string {
+	if name, ok := paletteModeNames[p]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+func ParsePaletteMode(s string) (PaletteMode, error) {
+	for p, name := range paletteModeNames {
+		if strings.EqualFold(s, name) {
+			return p, nil
+		}
+	}
+	return PaletteOff, fmt.Errorf("unknown palette mode: %s (off, auto, on)", s)
+}
+
 type CursorMode int
 
 const (
@@ -60,6 +209,14 @@ type Config struct {
 	SaveFile   bool
 	Notify     bool
 	Stdout     bool
+	Annotate   bool
+	History    bool
+	Delay      time.Duration
+	Countdown  bool
+
+	Compression PNGCompression
+	Palette     PaletteMode
+	Metadata    bool
 }
 
 func DefaultConfig() Config {
@@ -73,5 +230,8 @@ func DefaultConfig() Config {
 		Clipboard: true,
 		SaveFile:  true,
 		Notify:    true,
+		History:   true,
+		Countdown: true,
+		Metadata:  true,
 	}
 }
@@ -0,0 +1,458 @@
+package screenshot
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// Lossless WebP (VP8L) encoder. It applies the subtract-green transform and
+// LZ77 references to the pixel on the left and the one above, which covers
+// the flat areas and repeated rows typical of UI captures, and entropy codes
+// the result with one set of prefix codes for the whole image.
+
+const (
+	vp8lSignature     = 0x2f
+	vp8lMaxDimension  = 1 << 14
+	vp8lMaxCodeLength = 15
+	vp8lMaxCopyLength = 4096
+	vp8lMinCopyLength = 3
+	vp8lNumLength     = 24
+	vp8lNumDistance   = 40
+	vp8lTransformSubG = 2
+)
+
+// distance codes for the neighbouring pixels, see the VP8L distance map
+const (
+	vp8lDistAbove = 1
+	vp8lDistLeft  = 2
+)
+
+var vp8lCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+type vp8lBitWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (b *vp8lBitWriter) write(v uint32, n uint) {
+	b.acc |= uint64(v) << b.nacc
+	b.nacc += n
+	for b.nacc >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nacc -= 8
+	}
+}
+
+func (b *vp8lBitWriter) bytes() []byte {
+	if b.nacc > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nacc = 0, 0
+	}
+	return b.buf
+}
+
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint16
+}
+
+func (c *prefixCode) write(b *vp8lBitWriter, symbol int) {
+	b.write(uint32(c.codes[symbol]), uint(c.lengths[symbol]))
+}
+
+type vp8lToken struct {
+	argb   uint32
+	length int
+	dist   int
+}
+
+// vp8lPrefix splits a length or distance code into its prefix symbol and the
+// extra bits following it.
+func vp8lPrefix(v int) (symbol int, extraBits uint, extra uint32) {
+	d := v - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+	h := bits.Len(uint(d)) - 1
+	second := (d >> (h - 1)) & 1
+	extraBits = uint(h - 1)
+	return 2*h + second, extraBits, uint32(d & (1<<extraBits - 1))
+}
+
+// EncodeVP8L encodes img as a VP8L bitstream without the RIFF container.
+func EncodeVP8L(img *image.RGBA) ([]byte, error) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= 0 || h <= 0 || w > vp8lMaxDimension || h > vp8lMaxDimension {
+		return nil, fmt.Errorf("webp: invalid image size %dx%d", w, h)
+	}
+
+	argb := make([]uint32, w*h)
+	hasAlpha := false
+	for y := 0; y < h; y++ {
+		row := img.Pix[(y+bounds.Min.Y-img.Rect.Min.Y)*img.Stride+(bounds.Min.X-img.Rect.Min.X)*4:]
+		for x := 0; x < w; x++ {
+			p := row[x*4 : x*4+4]
+			r, g, b, a := uint32(p[0]), uint32(p[1]), uint32(p[2]), uint32(p[3])
+			if a != 255 {
+				hasAlpha = true
+				// image.RGBA is premultiplied, VP8L is not
+				if a != 0 {
+					r, g, b = r*255/a, g*255/a, b*255/a
+				}
+			}
+			argb[y*w+x] = a<<24 | ((r-g)&0xff)<<16 | g<<8 | (b-g)&0xff
+		}
+	}
+
+	tokens := vp8lTokens(argb, w)
+
+	var histos [5][]int
+	histos[0] = make([]int, 256+vp8lNumLength)
+	histos[1] = make([]int, 256)
+	histos[2] = make([]int, 256)
+	histos[3] = make([]int, 256)
+	histos[4] = make([]int, vp8lNumDistance)
+	for _, t := range tokens {
+		if t.length == 0 {
+			histos[0][t.argb>>8&0xff]++
+			histos[1][t.argb>>16&0xff]++
+			histos[2][t.argb&0xff]++
+			histos[3][t.argb>>24]++
+			continue
+		}
+		sym, _, _ := vp8lPrefix(t.length)
+		histos[0][256+sym]++
+		sym, _, _ = vp8lPrefix(t.dist)
+		histos[4][sym]++
+	}
+
+	bw := &vp8lBitWriter{}
+	bw.write(vp8lSignature, 8)
+	bw.write(uint32(w-1), 14)
+	bw.write(uint32(h-1), 14)
+	if hasAlpha {
+		bw.write(1, 1)
+	} else {
+		bw.write(0, 1)
+	}
+	bw.write(0, 3)
+
+	bw.write(1, 1)
+	bw.write(vp8lTransformSubG, 2)
+	bw.write(0, 1)
+
+	bw.write(0, 1) // no color cache
+	bw.write(0, 1) // no meta prefix codes
+
+	var codes [5]*prefixCode
+	for i, histo := range histos {
+		codes[i] = writePrefixCode(bw, histo)
+	}
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			codes[0].write(bw, int(t.argb>>8&0xff))
+			codes[1].write(bw, int(t.argb>>16&0xff))
+			codes[2].write(bw, int(t.argb&0xff))
+			codes[3].write(bw, int(t.argb>>24))
+			continue
+		}
+		sym, n, extra := vp8lPrefix(t.length)
+		codes[0].write(bw, 256+sym)
+		bw.write(extra, n)
+		sym, n, extra = vp8lPrefix(t.dist)
+		codes[4].write(bw, sym)
+		bw.write(extra, n)
+	}
+
+	return bw.bytes(), nil
+}
+
+// vp8lTokens greedily replaces runs that repeat the left or the upper
+// neighbour with backward references.
+func vp8lTokens(argb []uint32, w int) []vp8lToken {
+	tokens := make([]vp8lToken, 0, len(argb)/4)
+	matchLen := func(i, dist int) int {
+		if i < dist {
+			return 0
+		}
+		n := 0
+		for i+n < len(argb) && n < vp8lMaxCopyLength && argb[i+n] == argb[i+n-dist] {
+			n++
+		}
+		return n
+	}
+
+	for i := 0; i < len(argb); {
+		left := matchLen(i, 1)
+		above := matchLen(i, w)
+		switch {
+		case above >= left && above >= vp8lMinCopyLength:
+			tokens = append(tokens, vp8lToken{length: above, dist: vp8lDistAbove})
+			i += above
+		case left >= vp8lMinCopyLength:
+			tokens = append(tokens, vp8lToken{length: left, dist: vp8lDistLeft})
+			i += left
+		default:
+			tokens = append(tokens, vp8lToken{argb: argb[i]})
+			i++
+		}
+	}
+	return tokens
+}
+
+// writePrefixCode picks the cheapest representation of the code built from
+// histo, writes it and returns it for encoding symbols.
+func writePrefixCode(bw *vp8lBitWriter, histo []int) *prefixCode {
+	var used []int
+	for sym, n := range histo {
+		if n > 0 {
+			used = append(used, sym)
+		}
+	}
+
+	code := &prefixCode{lengths: make([]uint8, len(histo)), codes: make([]uint16, len(histo))}
+
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		bw.write(1, 1)
+		bw.write(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(used[0]), 1)
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			bw.write(uint32(used[1]), 8)
+			code.lengths[used[0]], code.lengths[used[1]] = 1, 1
+			code.codes[used[1]] = 1
+		}
+		return code
+	}
+
+	lengths := huffmanLengths(histo, vp8lMaxCodeLength)
+	writeCodeLengths(bw, lengths)
+
+	if len(used) == 1 {
+		// a lone symbol is decoded without reading any bits
+		return code
+	}
+	copy(code.lengths, lengths)
+	code.codes = canonicalCodes(lengths)
+	return code
+}
+
+func writeCodeLengths(bw *vp8lBitWriter, lengths []uint8) {
+	type rle struct {
+		sym   int
+		extra uint32
+	}
+	var tokens []rle
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, rle{sym: int(lengths[i])})
+			i++
+			continue
+		}
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == 0 && run < 138 {
+			run++
+		}
+		switch {
+		case run >= 11:
+			tokens = append(tokens, rle{sym: 18, extra: uint32(run - 11)})
+		case run >= 3:
+			tokens = append(tokens, rle{sym: 17, extra: uint32(run - 3)})
+		default:
+			run = 1
+			tokens = append(tokens, rle{sym: 0})
+		}
+		i += run
+	}
+
+	histo := make([]int, len(vp8lCodeLengthOrder))
+	for _, t := range tokens {
+		histo[t.sym]++
+	}
+	clLengths := huffmanLengths(histo, 7)
+	clCode := &prefixCode{lengths: clLengths, codes: canonicalCodes(clLengths)}
+
+	n := len(vp8lCodeLengthOrder)
+	for n > 4 && clLengths[vp8lCodeLengthOrder[n-1]] == 0 {
+		n--
+	}
+
+	bw.write(0, 1)
+	bw.write(uint32(n-4), 4)
+	for _, sym := range vp8lCodeLengthOrder[:n] {
+		bw.write(uint32(clLengths[sym]), 3)
+	}
+	bw.write(0, 1) // max_symbol is the alphabet size
+
+	single := 0
+	for _, l := range clLengths {
+		if l > 0 {
+			single++
+		}
+	}
+	for _, t := range tokens {
+		if single > 1 {
+			clCode.write(bw, t.sym)
+		}
+		switch t.sym {
+		case 17:
+			bw.write(t.extra, 3)
+		case 18:
+			bw.write(t.extra, 7)
+		}
+	}
+}
+
+// huffmanLengths returns code lengths no longer than maxBits. When the
+// optimal tree is too deep the smallest counts are raised and it is rebuilt.
+func huffmanLengths(histo []int, maxBits int) []uint8 {
+	lengths := make([]uint8, len(histo))
+
+	type node struct {
+		count       int
+		left, right int
+	}
+
+	for minCount := 1; ; minCount *= 2 {
+		nodes := make([]node, 0, 2*len(histo))
+		var leaves []int
+		for sym, n := range histo {
+			if n == 0 {
+				continue
+			}
+			nodes = append(nodes, node{count: max(n, minCount), left: -1, right: sym})
+			leaves = append(leaves, len(nodes)-1)
+		}
+		switch len(leaves) {
+		case 0:
+			return lengths
+		case 1:
+			lengths[nodes[0].right] = 1
+			return lengths
+		}
+
+		sort.SliceStable(leaves, func(i, j int) bool { return nodes[leaves[i]].count < nodes[leaves[j]].count })
+
+		// two-queue construction over the sorted leaves
+		var merged []int
+		pop := func() int {
+			if len(merged) == 0 || (len(leaves) > 0 && nodes[leaves[0]].count <= nodes[merged[0]].count) {
+				n := leaves[0]
+				leaves = leaves[1:]
+				return n
+			}
+			n := merged[0]
+			merged = merged[1:]
+			return n
+		}
+		for len(leaves)+len(merged) > 1 {
+			a, b := pop(), pop()
+			nodes = append(nodes, node{count: nodes[a].count + nodes[b].count, left: a, right: b})
+			merged = append(merged, len(nodes)-1)
+		}
+
+		clear(lengths)
+		tooDeep := false
+		var walk func(n, depth int)
+		walk = func(n, depth int) {
+			if nodes[n].left < 0 {
+				if depth > maxBits {
+					tooDeep = true
+				}
+				lengths[nodes[n].right] = uint8(depth)
+				return
+			}
+			walk(nodes[n].left, depth+1)
+			walk(nodes[n].right, depth+1)
+		}
+		walk(merged[0], 0)
+		if !tooDeep {
+			return lengths
+		}
+	}
+}
+
+// canonicalCodes assigns canonical codes to lengths, bit reversed for the
+// LSB-first bit writer.
+func canonicalCodes(lengths []uint8) []uint16 {
+	var count [vp8lMaxCodeLength + 1]int
+	for _, l := range lengths {
+		count[l]++
+	}
+	count[0] = 0
+
+	var next [vp8lMaxCodeLength + 2]int
+	code := 0
+	for l := 1; l <= vp8lMaxCodeLength; l++ {
+		code = (code + count[l-1]) << 1
+		next[l] = code
+	}
+
+	codes := make([]uint16, len(lengths))
+	for sym, l := range lengths {
+		if l == 0 {
+			continue
+		}
+		codes[sym] = uint16(bits.Reverse16(uint16(next[l])) >> (16 - l))
+		next[l]++
+	}
+	return codes
+}
+
+func writeRIFFChunk(w io.Writer, fourcc string, data []byte) error {
+	var hdr [8]byte
+	copy(hdr[:4], fourcc)
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(len(data)))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if len(data)%2 == 1 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+func riffChunkSize(data []byte) int {
+	return 8 + len(data) + len(data)%2
+}
+
+// EncodeWebP writes img as a lossless WebP file.
+func EncodeWebP(w io.Writer, img *image.RGBA) error {
+	data, err := EncodeVP8L(img)
+	if err != nil {
+		return err
+	}
+
+	var hdr [12]byte
+	copy(hdr[:4], "RIFF")
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(4+riffChunkSize(data)))
+	copy(hdr[8:], "WEBP")
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	return writeRIFFChunk(w, "VP8L", data)
+}
+
+func putUint24(b []byte, v uint32) {
+	b[0], b[1], b[2] = byte(v), byte(v>>8), byte(v>>16)
+}
@@ -0,0 +1,196 @@
+package colorhistory
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/colorpicker"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "color history manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "color.history.list":
+		models.Respond(conn, req.ID, manager.List(params.IntOpt(req.Params, "limit", 0)))
+	case "color.history.get":
+		handleGet(conn, req, manager)
+	case "color.history.add":
+		handleAdd(conn, req, manager)
+	case "color.history.copy":
+		handleCopy(conn, req, manager)
+	case "color.history.rename":
+		handleRename(conn, req, manager)
+	case "color.history.delete":
+		handleDelete(conn, req, manager)
+	case "color.history.clear":
+		handleClear(conn, req, manager)
+	case "color.history.subscribe":
+		handleSubscribe(conn, req, manager)
+	case "color.contrast":
+		handleContrast(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGet(conn net.Conn, req models.Request, manager *Manager) {
+	entry, err := manager.Get(params.StringOpt(req.Params, "id", ""))
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, entry)
+}
+
+func handleAdd(conn net.Conn, req models.Request, manager *Manager) {
+	raw, ok := params.Any(req.Params, "colors")
+	list, isList := raw.([]any)
+	if !ok || !isList || len(list) == 0 {
+		models.RespondError(conn, req.ID, "missing or invalid 'colors' parameter")
+		return
+	}
+
+	colors := make([]colorpicker.Color, 0, len(list))
+	for _, v := range list {
+		s, _ := v.(string)
+		c, err := colorpicker.ParseHex(s)
+		if err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		colors = append(colors, c)
+	}
+
+	added, err := manager.Add(colors...)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	if name := params.StringOpt(req.Params, "name", ""); name != "" && len(added) == 1 {
+		if added[0], err = manager.Rename(added[0].ID, name); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+	}
+	models.Respond(conn, req.ID, added)
+}
+
+func handleCopy(conn net.Conn, req models.Request, manager *Manager) {
+	format := colorpicker.ParseFormat(params.StringOpt(req.Params, "format", "hex"))
+	text, err := manager.Copy(params.StringOpt(req.Params, "id", ""), format, params.BoolOpt(req.Params, "lowercase", false))
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "copied to clipboard", Value: text})
+}
+
+func handleRename(conn net.Conn, req models.Request, manager *Manager) {
+	id, err := params.StringNonEmpty(req.Params, "id")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	entry, err := manager.Rename(id, params.StringOpt(req.Params, "name", ""))
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, entry)
+}
+
+func handleDelete(conn net.Conn, req models.Request, manager *Manager) {
+	id, err := params.StringNonEmpty(req.Params, "id")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	entry, err := manager.Delete(id)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "deleted", Value: entry.ID})
+}
+
+func handleClear(conn net.Conn, req models.Request, manager *Manager) {
+	if err := manager.Clear(); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "cleared"})
+}
+
+// handleContrast compares two colors given as hex values or history
+// references; without them the last two picks are compared.
+func handleContrast(conn net.Conn, req models.Request, manager *Manager) {
+	fgRef := params.StringOpt(req.Params, "foreground", "")
+	bgRef := params.StringOpt(req.Params, "background", "")
+
+	if fgRef == "" && bgRef == "" {
+		entries := manager.List(2)
+		if len(entries) < 2 {
+			models.RespondError(conn, req.ID, "need two colors in history to compare")
+			return
+		}
+		fgRef, bgRef = entries[1].ID, entries[0].ID
+	}
+
+	fg, err := resolveColor(manager, fgRef)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	bg, err := resolveColor(manager, bgRef)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, colorpicker.ContrastOf(fg, bg))
+}
+
+func resolveColor(manager *Manager, ref string) (colorpicker.Color, error) {
+	if strings.HasPrefix(ref, "#") {
+		return colorpicker.ParseHex(ref)
+	}
+	if ref == "" {
+		return colorpicker.Color{}, fmt.Errorf("missing color")
+	}
+	entry, err := manager.Get(ref)
+	if err != nil {
+		return colorpicker.Color{}, err
+	}
+	return entry.Color(), nil
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
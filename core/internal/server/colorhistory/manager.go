@@ -0,0 +1,198 @@
+package colorhistory
+
+import (
+	"os"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/colorpicker"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+// picks are added by short-lived dms processes; a burst of index writes is
+// published as one update
+const reloadDelay = 100 * time.Millisecond
+
+func NewManager(palette *colorpicker.Palette, copyFn CopyFunc) *Manager {
+	m := &Manager{
+		palette:  palette,
+		copy:     copyFn,
+		stopChan: make(chan struct{}),
+	}
+	m.reload()
+
+	if watcher := m.newWatcher(); watcher != nil {
+		m.wg.Add(1)
+		go m.watch(watcher)
+	}
+	return m
+}
+
+func (m *Manager) newWatcher() *fsnotify.Watcher {
+	if err := os.MkdirAll(m.palette.Dir(), 0o700); err != nil {
+		log.Warnf("Color history: failed to create %s: %v", m.palette.Dir(), err)
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Warnf("Color history: failed to create watcher: %v", err)
+		return nil
+	}
+
+	if err := watcher.Add(m.palette.Dir()); err != nil {
+		log.Warnf("Color history: failed to watch %s: %v", m.palette.Dir(), err)
+		watcher.Close()
+		return nil
+	}
+	return watcher
+}
+
+func (m *Manager) watch(watcher *fsnotify.Watcher) {
+	defer m.wg.Done()
+	defer watcher.Close()
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Name != m.palette.IndexPath() {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove) == 0 {
+				continue
+			}
+			if pending == nil {
+				pending = time.After(reloadDelay)
+			}
+		case <-pending:
+			pending = nil
+			m.reload()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("Color history watcher error: %v", err)
+		}
+	}
+}
+
+func (m *Manager) reload() {
+	entries, err := m.palette.List()
+	if err != nil {
+		log.Warnf("Color history: %v", err)
+		return
+	}
+	if entries == nil {
+		entries = []colorpicker.PaletteEntry{}
+	}
+
+	m.stateMutex.Lock()
+	m.state = State{Entries: entries}
+	state := m.state
+	m.stateMutex.Unlock()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		select {
+		case ch <- state:
+		default:
+			log.Warn("Color history: subscriber channel full, dropping update")
+		}
+		return true
+	})
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	return m.state
+}
+
+func (m *Manager) List(limit int) []colorpicker.PaletteEntry {
+	entries := m.GetState().Entries
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+func (m *Manager) Get(ref string) (colorpicker.PaletteEntry, error) {
+	return m.palette.Get(ref)
+}
+
+func (m *Manager) Add(colors ...colorpicker.Color) ([]colorpicker.PaletteEntry, error) {
+	added, err := m.palette.Add(colors...)
+	if err != nil {
+		return nil, err
+	}
+	m.reload()
+	return added, nil
+}
+
+// Copy puts a color on the clipboard in the given format.
+func (m *Manager) Copy(ref string, format colorpicker.OutputFormat, lowercase bool) (string, error) {
+	entry, err := m.palette.Get(ref)
+	if err != nil {
+		return "", err
+	}
+	text := entry.Color().Format(format, lowercase, "")
+	if err := m.copy([]byte(text), "text/plain;charset=utf-8"); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+func (m *Manager) Rename(ref, name string) (colorpicker.PaletteEntry, error) {
+	entry, err := m.palette.Rename(ref, name)
+	if err != nil {
+		return entry, err
+	}
+	m.reload()
+	return entry, nil
+}
+
+func (m *Manager) Delete(ref string) (colorpicker.PaletteEntry, error) {
+	entry, err := m.palette.Delete(ref)
+	if err != nil {
+		return entry, err
+	}
+	m.reload()
+	return entry, nil
+}
+
+func (m *Manager) Clear() error {
+	if err := m.palette.Clear(); err != nil {
+		return err
+	}
+	m.reload()
+	return nil
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if ch, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(ch)
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,60 @@
+package colorhistory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/colorpicker"
+)
+
+func TestManagerPicksUpExternalPicks(t *testing.T) {
+	palette := colorpicker.NewPalette(t.TempDir())
+	m := NewManager(palette, func([]byte, string) error { return nil })
+	defer m.Close()
+
+	assert.Empty(t, m.GetState().Entries)
+	updates := m.Subscribe("test")
+
+	// dms color pick runs as a separate process
+	other := colorpicker.NewPalette(palette.Dir())
+	added, err := other.Add(colorpicker.Color{R: 0x1e, G: 0x66, B: 0xf5, A: 255})
+	require.NoError(t, err)
+
+	select {
+	case state := <-updates:
+		require.Len(t, state.Entries, 1)
+		assert.Equal(t, added[0].ID, state.Entries[0].ID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update after the index changed")
+	}
+	assert.Len(t, m.List(0), 1)
+}
+
+func TestManagerCopyRenameDelete(t *testing.T) {
+	palette := colorpicker.NewPalette(t.TempDir())
+	var copied string
+	m := NewManager(palette, func(data []byte, mimeType string) error {
+		copied = string(data)
+		return nil
+	})
+	defer m.Close()
+
+	added, err := m.Add(colorpicker.Color{R: 255, G: 0, B: 0, A: 255})
+	require.NoError(t, err)
+
+	text, err := m.Copy("", colorpicker.FormatRGB, false)
+	require.NoError(t, err)
+	assert.Equal(t, "255 0 0", text)
+	assert.Equal(t, text, copied)
+
+	_, err = m.Rename(added[0].ID, "Alert")
+	require.NoError(t, err)
+	assert.Equal(t, "Alert", m.GetState().Entries[0].Name)
+
+	_, err = m.Delete("alert")
+	require.NoError(t, err)
+	assert.Empty(t, m.GetState().Entries)
+}
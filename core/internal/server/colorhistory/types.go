@@ -0,0 +1,28 @@
+package colorhistory
+
+import (
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/colorpicker"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+type State struct {
+	Entries []colorpicker.PaletteEntry `json:"entries"`
+}
+
+// CopyFunc puts text on the clipboard, normally through the clipboard
+// manager when it is running.
+type CopyFunc func(data []byte, mimeType string) error
+
+type Manager struct {
+	palette *colorpicker.Palette
+	copy    CopyFunc
+
+	stateMutex sync.RWMutex
+	state      State
+
+	subscribers syncmap.Map[string, chan State]
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+}
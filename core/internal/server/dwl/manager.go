@@ -8,6 +8,7 @@ import (
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/dwl_ipc"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/workspacemeta"
 )
 
 func NewManager(display wlclient.WaylandDisplay) (*Manager, error) {
@@ -309,6 +310,13 @@ func (m *Manager) updateState() {
 
 		tagsCopy := make([]TagState, len(out.tags))
 		copy(tagsCopy, out.tags)
+		for i := range tagsCopy {
+			if meta, ok := m.lookupMetadata(name, []uint32{tagsCopy[i].Tag}); ok {
+				tagsCopy[i].Label = meta.Label
+				tagsCopy[i].Icon = meta.Icon
+				tagsCopy[i].Color = meta.Color
+			}
+		}
 
 		outputs[name] = &OutputState{
 			Name:         name,
@@ -342,6 +350,31 @@ func (m *Manager) updateState() {
 	m.notifySubscribers()
 }
 
+// SetMetadata attaches a metadata source merged into every state update.
+func (m *Manager) SetMetadata(src MetadataSource) {
+	m.metaMutex.Lock()
+	m.metadata = src
+	m.metaMutex.Unlock()
+	m.RefreshMetadata()
+}
+
+// RefreshMetadata rebuilds the state after the metadata source changed.
+func (m *Manager) RefreshMetadata() {
+	m.post(func() {
+		m.updateState()
+	})
+}
+
+func (m *Manager) lookupMetadata(output string, coordinates []uint32) (workspacemeta.Meta, bool) {
+	m.metaMutex.RLock()
+	src := m.metadata
+	m.metaMutex.RUnlock()
+	if src == nil {
+		return workspacemeta.Meta{}, false
+	}
+	return src.Lookup(output, coordinates)
+}
+
 func (m *Manager) notifier() {
 	defer m.notifierWg.Done()
 	const minGap = 100 * time.Millisecond
@@ -431,6 +464,28 @@ func (m *Manager) SetTags(outputName string, tagmask uint32, toggleTagset uint32
 	return err
 }
 
+// ActivateCoordinates shows the tag coordinates[0] on output, or on the
+// active output when output is empty or workspacemeta.AnyOutput.
+func (m *Manager) ActivateCoordinates(output string, coordinates []uint32) error {
+	if len(coordinates) == 0 {
+		return fmt.Errorf("missing tag coordinate")
+	}
+
+	state := m.GetState()
+	if output == "" || output == workspacemeta.AnyOutput {
+		output = state.ActiveOutput
+	}
+	if output == "" {
+		return fmt.Errorf("no active output")
+	}
+
+	tag := coordinates[0]
+	if tag >= 32 || (state.TagCount > 0 && tag >= state.TagCount) {
+		return fmt.Errorf("tag out of range: %d", tag)
+	}
+	return m.SetTags(output, 1<<tag, 0)
+}
+
 func (m *Manager) SetClientTags(outputName string, andTags uint32, xorTags uint32) error {
 	var targetOut *outputState
 	m.outputs.Range(func(key uint32, out *outputState) bool {
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	mocks_wlclient "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/wlclient"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/workspacemeta"
 )
 
 func TestStateChanged_BothNil(t *testing.T) {
@@ -364,3 +365,36 @@ func TestNewManager_GetRegistryError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get registry")
 }
+
+type fakeMetadata map[string]workspacemeta.Meta
+
+func (f fakeMetadata) Lookup(output string, coordinates []uint32) (workspacemeta.Meta, bool) {
+	meta, ok := f[workspacemeta.Key(output, coordinates)]
+	return meta, ok
+}
+
+func TestManager_UpdateStateMergesMetadata(t *testing.T) {
+	m := &Manager{
+		dirty:    make(chan struct{}, 1),
+		metadata: fakeMetadata{"eDP-1/1": {Label: "web", Icon: "globe", Color: "#ff0000"}},
+	}
+	m.outputs.Store(1, &outputState{
+		id:   1,
+		name: "eDP-1",
+		tags: []TagState{{Tag: 0, State: 1}, {Tag: 1, Clients: 2}},
+	})
+
+	m.updateState()
+	tags := m.GetState().Outputs["eDP-1"].Tags
+
+	assert.Empty(t, tags[0].Label)
+	assert.Equal(t, "web", tags[1].Label)
+	assert.Equal(t, "globe", tags[1].Icon)
+	assert.Equal(t, "#ff0000", tags[1].Color)
+
+	old := m.GetState()
+	m.metadata = fakeMetadata{}
+	m.updateState()
+	newState := m.GetState()
+	assert.True(t, stateChanged(&old, &newState))
+}
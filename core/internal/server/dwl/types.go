@@ -3,6 +3,7 @@ package dwl
 import (
 	"sync"
 
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/workspacemeta"
 	wlclient "github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
 )
@@ -12,6 +13,9 @@ type TagState struct {
 	State   uint32 `json:"state"`
 	Clients uint32 `json:"clients"`
 	Focused uint32 `json:"focused"`
+	Label   string `json:"label,omitempty"`
+	Icon    string `json:"icon,omitempty"`
+	Color   string `json:"color,omitempty"`
 }
 
 type OutputState struct {
@@ -33,6 +37,12 @@ type State struct {
 	ActiveOutput string                  `json:"activeOutput"`
 }
 
+// MetadataSource supplies user-assigned labels, icons and colors; dwl tags
+// are looked up by output name and the coordinate [tag].
+type MetadataSource interface {
+	Lookup(output string, coordinates []uint32) (workspacemeta.Meta, bool)
+}
+
 type cmd struct {
 	fn func()
 }
@@ -61,6 +71,9 @@ type Manager struct {
 
 	stateMutex sync.RWMutex
 	state      *State
+
+	metaMutex sync.RWMutex
+	metadata  MetadataSource
 }
 
 type outputState struct {
@@ -165,8 +178,7 @@ func stateChanged(old, new *State) bool {
 				return true
 			}
 			oldTag := oldOut.Tags[i]
-			if oldTag.Tag != newTag.Tag || oldTag.State != newTag.State ||
-				oldTag.Clients != newTag.Clients || oldTag.Focused != newTag.Focused {
+			if oldTag != newTag {
 				return true
 			}
 		}
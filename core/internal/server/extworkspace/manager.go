@@ -2,10 +2,14 @@ package extworkspace
 
 import (
 	"fmt"
+	"slices"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/ext_workspace"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/workspacemeta"
 	wlclient "github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
 )
 
@@ -332,6 +336,12 @@ func (m *Manager) updateState() {
 			}
 		}
 
+		sort.Strings(outputs)
+		metaOutput := ""
+		if len(outputs) > 0 {
+			metaOutput = outputs[0]
+		}
+
 		workspaces := make([]*Workspace, 0)
 		for _, wsID := range group.workspaceIDs {
 			ws, exists := m.workspaces.Load(wsID)
@@ -346,11 +356,18 @@ func (m *Manager) updateState() {
 				ID:          ws.workspaceID,
 				Name:        ws.name,
 				Coordinates: ws.coordinates,
+				MetaKey:     metaKey(ws),
 				State:       ws.state,
 				Active:      ws.state&uint32(ext_workspace.ExtWorkspaceHandleV1StateActive) != 0,
 				Urgent:      ws.state&uint32(ext_workspace.ExtWorkspaceHandleV1StateUrgent) != 0,
 				Hidden:      ws.state&uint32(ext_workspace.ExtWorkspaceHandleV1StateHidden) != 0,
 			}
+
+			if meta, ok := m.lookupMetadata(metaOutput, workspace.MetaKey); ok {
+				workspace.Label = meta.Label
+				workspace.Icon = meta.Icon
+				workspace.Color = meta.Color
+			}
 			workspaces = append(workspaces, workspace)
 		}
 
@@ -374,6 +391,47 @@ func (m *Manager) updateState() {
 	m.notifySubscribers()
 }
 
+// SetMetadata attaches a metadata source merged into every state update.
+func (m *Manager) SetMetadata(src MetadataSource) {
+	m.metaMutex.Lock()
+	m.metadata = src
+	m.metaMutex.Unlock()
+	m.RefreshMetadata()
+}
+
+// RefreshMetadata rebuilds the state after the metadata source changed.
+func (m *Manager) RefreshMetadata() {
+	m.post(func() {
+		m.updateState()
+	})
+}
+
+// metaKey returns the coordinates workspace metadata and assignments are keyed
+// on. Workspaces without coordinates fall back to their name or id when that
+// is a number, which unlike their list position survives reordering; other
+// workspaces cannot carry metadata.
+func metaKey(ws *workspaceState) []uint32 {
+	if len(ws.coordinates) > 0 {
+		return ws.coordinates
+	}
+	for _, s := range []string{ws.name, ws.workspaceID} {
+		if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+			return []uint32{uint32(n)}
+		}
+	}
+	return nil
+}
+
+func (m *Manager) lookupMetadata(output string, coordinates []uint32) (workspacemeta.Meta, bool) {
+	m.metaMutex.RLock()
+	src := m.metadata
+	m.metaMutex.RUnlock()
+	if src == nil || len(coordinates) == 0 {
+		return workspacemeta.Meta{}, false
+	}
+	return src.Lookup(output, coordinates)
+}
+
 func (m *Manager) notifier() {
 	defer m.notifierWg.Done()
 	const minGap = 100 * time.Millisecond
@@ -420,6 +478,34 @@ func (m *Manager) notifier() {
 	}
 }
 
+// ActivateCoordinates activates the workspace with the given coordinates in
+// the group showing output, or in any group when output is empty or
+// workspacemeta.AnyOutput.
+func (m *Manager) ActivateCoordinates(output string, coordinates []uint32) error {
+	groupID, workspaceID, ok := m.findWorkspace(output, coordinates)
+	if !ok {
+		return fmt.Errorf("no workspace at %s", workspacemeta.Key(output, coordinates))
+	}
+	return m.ActivateWorkspace(groupID, workspaceID)
+}
+
+// findWorkspace resolves a metadata key to the group and workspace ids
+// ActivateWorkspace takes.
+func (m *Manager) findWorkspace(output string, coordinates []uint32) (string, string, bool) {
+	anyOutput := output == "" || output == workspacemeta.AnyOutput
+	for _, group := range m.GetState().Groups {
+		if !anyOutput && !slices.Contains(group.Outputs, output) {
+			continue
+		}
+		for _, ws := range group.Workspaces {
+			if len(ws.MetaKey) > 0 && slices.Equal(ws.MetaKey, coordinates) {
+				return group.ID, ws.ID, true
+			}
+		}
+	}
+	return "", "", false
+}
+
 func (m *Manager) ActivateWorkspace(groupID, workspaceID string) error {
 	errChan := make(chan error, 1)
 
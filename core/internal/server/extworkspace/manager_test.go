@@ -7,8 +7,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	mocks_wlclient "github.com/AvengeMedia/DankMaterialShell/core/internal/mocks/wlclient"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/workspacemeta"
 )
 
 func TestStateChanged_BothNil(t *testing.T) {
@@ -390,3 +392,60 @@ func TestNewManager_GetRegistryError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to get registry")
 }
+
+type fakeMetadata map[string]workspacemeta.Meta
+
+func (f fakeMetadata) Lookup(output string, coordinates []uint32) (workspacemeta.Meta, bool) {
+	if meta, ok := f[workspacemeta.Key(output, coordinates)]; ok {
+		return meta, true
+	}
+	meta, ok := f[workspacemeta.Key(workspacemeta.AnyOutput, coordinates)]
+	return meta, ok
+}
+
+func TestManager_UpdateStateMergesMetadata(t *testing.T) {
+	m := &Manager{
+		dirty: make(chan struct{}, 1),
+		metadata: fakeMetadata{
+			"DP-1/0,1": {Label: "code", Color: "#00ff00"},
+			"*/2":      {Icon: "terminal"},
+		},
+	}
+	m.outputNames.Store(10, "DP-1")
+	m.groups.Store(1, &workspaceGroupState{id: 1, outputIDs: map[uint32]bool{10: true}, workspaceIDs: []uint32{1, 2, 3, 4}})
+	m.workspaces.Store(1, &workspaceState{id: 1, workspaceID: "a", coordinates: []uint32{0, 0}})
+	m.workspaces.Store(2, &workspaceState{id: 2, workspaceID: "b", coordinates: []uint32{0, 1}})
+	m.workspaces.Store(3, &workspaceState{id: 3, workspaceID: "c", name: "web"})
+	m.workspaces.Store(4, &workspaceState{id: 4, workspaceID: "d", name: "2"})
+
+	m.updateState()
+	workspaces := m.GetState().Groups[0].Workspaces
+	require.Len(t, workspaces, 4)
+
+	assert.Empty(t, workspaces[0].Label)
+	assert.Equal(t, "code", workspaces[1].Label)
+	assert.Equal(t, "#00ff00", workspaces[1].Color)
+	assert.Nil(t, workspaces[2].MetaKey)
+	assert.Empty(t, workspaces[2].Icon)
+	assert.Equal(t, []uint32{2}, workspaces[3].MetaKey)
+	assert.Equal(t, "terminal", workspaces[3].Icon)
+}
+
+func TestManager_FindWorkspaceWithoutCoordinates(t *testing.T) {
+	m := &Manager{dirty: make(chan struct{}, 1)}
+	m.outputNames.Store(10, "DP-1")
+	m.groups.Store(1, &workspaceGroupState{id: 1, outputIDs: map[uint32]bool{10: true}, workspaceIDs: []uint32{1, 2}})
+	m.workspaces.Store(1, &workspaceState{id: 1, workspaceID: "a", name: "web"})
+	m.workspaces.Store(2, &workspaceState{id: 2, workspaceID: "b", name: "3"})
+	m.updateState()
+
+	groupID, workspaceID, ok := m.findWorkspace("DP-1", []uint32{3})
+	require.True(t, ok)
+	assert.Equal(t, "group-1", groupID)
+	assert.Equal(t, "b", workspaceID)
+
+	_, _, ok = m.findWorkspace(workspacemeta.AnyOutput, []uint32{0})
+	assert.False(t, ok)
+	_, _, ok = m.findWorkspace("HDMI-A-1", []uint32{3})
+	assert.False(t, ok)
+}
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/ext_workspace"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/workspacemeta"
 	wlclient "github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
 )
@@ -12,10 +13,14 @@ type Workspace struct {
 	ID          string   `json:"id"`
 	Name        string   `json:"name"`
 	Coordinates []uint32 `json:"coordinates"`
+	MetaKey     []uint32 `json:"metaKey,omitempty"`
 	State       uint32   `json:"state"`
 	Active      bool     `json:"active"`
 	Urgent      bool     `json:"urgent"`
 	Hidden      bool     `json:"hidden"`
+	Label       string   `json:"label,omitempty"`
+	Icon        string   `json:"icon,omitempty"`
+	Color       string   `json:"color,omitempty"`
 }
 
 type WorkspaceGroup struct {
@@ -28,6 +33,12 @@ type State struct {
 	Groups []*WorkspaceGroup `json:"groups"`
 }
 
+// MetadataSource supplies user-assigned labels, icons and colors keyed by
+// output and workspace coordinates.
+type MetadataSource interface {
+	Lookup(output string, coordinates []uint32) (workspacemeta.Meta, bool)
+}
+
 type cmd struct {
 	fn func()
 }
@@ -56,6 +67,9 @@ type Manager struct {
 
 	stateMutex sync.RWMutex
 	state      *State
+
+	metaMutex sync.RWMutex
+	metadata  MetadataSource
 }
 
 type workspaceGroupState struct {
@@ -151,6 +165,9 @@ func stateChanged(old, new *State) bool {
 			if oldWs.Active != newWs.Active || oldWs.Urgent != newWs.Urgent || oldWs.Hidden != newWs.Hidden {
 				return true
 			}
+			if oldWs.Label != newWs.Label || oldWs.Icon != newWs.Icon || oldWs.Color != newWs.Color {
+				return true
+			}
 			if len(oldWs.Coordinates) != len(newWs.Coordinates) {
 				return true
 			}
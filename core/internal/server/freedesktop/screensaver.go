@@ -179,31 +179,61 @@ func (h *screensaverHandler) Inhibit(sender dbus.Sender, appName, reason string)
 }
 
 func (h *screensaverHandler) UnInhibit(sender dbus.Sender, cookie uint32) *dbus.Error {
-	h.manager.stateMutex.Lock()
-	defer h.manager.stateMutex.Unlock()
+	inh, ok := h.manager.removeScreensaverInhibitor(cookie)
+	if !ok {
+		log.Debugf("UnInhibit: no match for cookie %08X", cookie)
+		return nil
+	}
 
-	found := false
-	inhibitors := h.manager.state.Screensaver.Inhibitors
+	log.Infof("Screensaver uninhibited by %s (%s) cookie %08X", inh.AppName, sender, cookie)
+
+	go h.manager.NotifyScreensaverSubscribers()
+
+	return nil
+}
+
+func (m *Manager) removeScreensaverInhibitor(cookie uint32) (ScreensaverInhibitor, bool) {
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	inhibitors := m.state.Screensaver.Inhibitors
 	for i, inh := range inhibitors {
 		if inh.Cookie != cookie {
 			continue
 		}
-		log.Infof("Screensaver uninhibited by %s (%s) cookie %08X", inh.AppName, sender, cookie)
-		h.manager.state.Screensaver.Inhibitors = append(inhibitors[:i], inhibitors[i+1:]...)
-		found = true
-		break
+		m.state.Screensaver.Inhibitors = append(inhibitors[:i], inhibitors[i+1:]...)
+		m.state.Screensaver.Inhibited = len(m.state.Screensaver.Inhibitors) > 0
+		return inh, true
 	}
+	return ScreensaverInhibitor{}, false
+}
 
-	if !found {
-		log.Debugf("UnInhibit: no match for cookie %08X", cookie)
-		return nil
+// ReleaseScreensaverInhibitor drops an inhibitor on behalf of its owner. The
+// owner is not told; a later UnInhibit for the cookie is a no-op.
+func (m *Manager) ReleaseScreensaverInhibitor(cookie uint32) bool {
+	inh, ok := m.removeScreensaverInhibitor(cookie)
+	if !ok {
+		return false
 	}
 
-	h.manager.state.Screensaver.Inhibited = len(h.manager.state.Screensaver.Inhibitors) > 0
+	log.Infof("Screensaver inhibitor from %s (cookie %08X) released", inh.AppName, cookie)
 
-	go h.manager.NotifyScreensaverSubscribers()
+	go m.NotifyScreensaverSubscribers()
 
-	return nil
+	return true
+}
+
+func (m *Manager) ScreensaverPeerPID(peer string) (uint32, error) {
+	if m.sessionConn == nil {
+		return 0, fmt.Errorf("no session bus connection")
+	}
+
+	var pid uint32
+	err := m.sessionConn.BusObject().Call("org.freedesktop.DBus.GetConnectionUnixProcessID", 0, peer).Store(&pid)
+	if err != nil {
+		return 0, err
+	}
+	return pid, nil
 }
 
 func (m *Manager) watchPeerDisconnects() {
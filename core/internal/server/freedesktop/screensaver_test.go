@@ -100,3 +100,38 @@ func TestScreensaverState_ActiveDefaultsFalse(t *testing.T) {
 	state := ScreensaverState{}
 	assert.False(t, state.Active)
 }
+
+func TestReleaseScreensaverInhibitor(t *testing.T) {
+	ch := make(chan ScreensaverState, 64)
+	manager := &Manager{
+		state: &FreedeskState{
+			Screensaver: ScreensaverState{
+				Available: true,
+				Inhibited: true,
+				Inhibitors: []ScreensaverInhibitor{
+					{Cookie: 1, AppName: "firefox", Reason: "video"},
+					{Cookie: 2, AppName: "mpv", Reason: "playing"},
+				},
+			},
+		},
+		stateMutex: sync.RWMutex{},
+	}
+	manager.screensaverSubscribers.Store("test", ch)
+	defer manager.screensaverSubscribers.Delete("test")
+
+	assert.True(t, manager.ReleaseScreensaverInhibitor(1))
+	state := manager.GetScreensaverState()
+	assert.True(t, state.Inhibited)
+	assert.Len(t, state.Inhibitors, 1)
+	assert.Equal(t, uint32(2), state.Inhibitors[0].Cookie)
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("expected notification after release")
+	}
+
+	assert.False(t, manager.ReleaseScreensaverInhibitor(1))
+	assert.True(t, manager.ReleaseScreensaverInhibitor(2))
+	assert.False(t, manager.GetScreensaverState().Inhibited)
+}
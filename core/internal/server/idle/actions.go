@@ -0,0 +1,155 @@
+package idle
+
+import (
+	"fmt"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_output_power"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/brightness"
+)
+
+func (m *Manager) runAction(stage Stage) error {
+	switch stage.Action {
+	case ActionDim:
+		return m.dim(stage.DimPercent)
+	case ActionLock:
+		session := m.getSession()
+		if session == nil {
+			return fmt.Errorf("loginctl not available")
+		}
+		return session.Lock()
+	case ActionDPMS:
+		return m.setDPMS(false)
+	case ActionSuspend:
+		session := m.getSession()
+		if session == nil {
+			return fmt.Errorf("loginctl not available")
+		}
+		return session.Suspend()
+	default:
+		return fmt.Errorf("unknown action: %s", stage.Action)
+	}
+}
+
+func (m *Manager) undoAction(stage Stage) {
+	switch stage.Action {
+	case ActionDim:
+		m.undim()
+	case ActionDPMS:
+		if err := m.setDPMS(true); err != nil {
+			log.Warnf("Idle: failed to turn outputs back on: %v", err)
+		}
+	}
+}
+
+func (m *Manager) dim(percent int) error {
+	b := m.getBrightness()
+	if b == nil {
+		return fmt.Errorf("brightness manager not available")
+	}
+
+	for _, dev := range b.GetState().Devices {
+		if dev.Class == brightness.ClassLED {
+			continue
+		}
+		if dev.CurrentPercent <= percent {
+			continue
+		}
+		if err := b.SetBrightness(dev.ID, percent); err != nil {
+			log.Warnf("Idle: failed to dim %s: %v", dev.ID, err)
+			continue
+		}
+		if _, ok := m.dimmed[dev.ID]; !ok {
+			m.dimmed[dev.ID] = dev.CurrentPercent
+		}
+	}
+	return nil
+}
+
+func (m *Manager) undim() {
+	b := m.getBrightness()
+	if b == nil {
+		return
+	}
+
+	for id, percent := range m.dimmed {
+		if err := b.SetBrightness(id, percent); err != nil {
+			log.Warnf("Idle: failed to restore brightness of %s: %v", id, err)
+		}
+		delete(m.dimmed, id)
+	}
+}
+
+func (m *Manager) setDPMS(on bool) error {
+	if m.powerMgr == nil {
+		return fmt.Errorf("compositor does not support %s", wlr_output_power.ZwlrOutputPowerManagerV1InterfaceName)
+	}
+	if m.dpmsOff != on {
+		return nil
+	}
+	m.dpmsOff = !on
+
+	mode := uint32(wlr_output_power.ZwlrOutputPowerV1ModeOff)
+	if on {
+		mode = uint32(wlr_output_power.ZwlrOutputPowerV1ModeOn)
+	}
+
+	m.post(func() {
+		m.outputs.Range(func(key uint32, op *outputPower) bool {
+			if op.control == nil {
+				control, err := m.powerMgr.GetOutputPower(op.output)
+				if err != nil {
+					log.Warnf("Idle: failed to get output power control: %v", err)
+					return true
+				}
+				control.SetFailedHandler(func(e wlr_output_power.ZwlrOutputPowerV1FailedEvent) {
+					if op.control == control {
+						op.control = nil
+					}
+					control.Destroy()
+				})
+				op.control = control
+			}
+			if err := op.control.SetMode(mode); err != nil {
+				log.Warnf("Idle: failed to set output power mode: %v", err)
+			}
+			return true
+		})
+	})
+	return nil
+}
+
+func (m *Manager) SetEnabled(enabled bool) error {
+	m.configMutex.Lock()
+	m.config.Enabled = enabled
+	config := m.config
+	m.configMutex.Unlock()
+
+	m.post(m.rebuildNotifications)
+	return SaveConfig(config)
+}
+
+func (m *Manager) SetStages(stages []Stage) error {
+	validated, err := ValidateStages(stages)
+	if err != nil {
+		return err
+	}
+
+	m.configMutex.Lock()
+	m.config.Stages = validated
+	config := m.config
+	m.configMutex.Unlock()
+
+	m.post(m.rebuildNotifications)
+	return SaveConfig(config)
+}
+
+func (m *Manager) SetRespectInhibitors(respect bool) error {
+	m.configMutex.Lock()
+	m.config.RespectInhibitors = respect
+	config := m.config
+	m.configMutex.Unlock()
+
+	m.queueEvent(event{kind: eventRefresh})
+	return SaveConfig(config)
+}
@@ -0,0 +1,129 @@
+package idle
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "idle manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "idle.getState":
+		handleGetState(conn, req, manager)
+	case "idle.setEnabled":
+		handleSetEnabled(conn, req, manager)
+	case "idle.setStages":
+		handleSetStages(conn, req, manager)
+	case "idle.setRespectInhibitors":
+		handleSetRespectInhibitors(conn, req, manager)
+	case "idle.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGetState(conn net.Conn, req models.Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleSetEnabled(conn net.Conn, req models.Request, manager *Manager) {
+	enabled, err := params.Bool(req.Params, "enabled")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.SetEnabled(enabled); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "idle enabled set"})
+}
+
+func handleSetStages(conn net.Conn, req models.Request, manager *Manager) {
+	stages, err := parseStages(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.SetStages(stages); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "idle stages set"})
+}
+
+func handleSetRespectInhibitors(conn net.Conn, req models.Request, manager *Manager) {
+	enabled, err := params.Bool(req.Params, "enabled")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.SetRespectInhibitors(enabled); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "idle inhibitor setting updated"})
+}
+
+func parseStages(p map[string]any) ([]Stage, error) {
+	raw, err := params.Get[[]any](p, "stages")
+	if err != nil {
+		return nil, err
+	}
+
+	stages := make([]Stage, 0, len(raw))
+	for i, item := range raw {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("stage %d: expected object", i)
+		}
+		timeout, err := params.Int(obj, "timeout")
+		if err != nil {
+			return nil, fmt.Errorf("stage %d: %w", i, err)
+		}
+		action, err := params.String(obj, "action")
+		if err != nil {
+			return nil, fmt.Errorf("stage %d: %w", i, err)
+		}
+		stages = append(stages, Stage{
+			Timeout:    timeout,
+			Action:     Action(action),
+			DimPercent: params.IntOpt(obj, "dimPercent", 0),
+		})
+	}
+	return stages, nil
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,690 @@
+package idle
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/ext_idle_notify"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_output_power"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/loginctl"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wlcontext"
+	wlclient "github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+const (
+	probeTimeout = 30 * time.Second
+	probeGrace   = 2 * time.Second
+)
+
+func NewManager(wlCtx wlcontext.WaylandContext, config Config) (*Manager, error) {
+	m := &Manager{
+		wlCtx:    wlCtx,
+		display:  wlCtx.Display(),
+		config:   config,
+		dimmed:   make(map[string]int),
+		events:   make(chan event, 64),
+		stopChan: make(chan struct{}),
+		dirty:    make(chan struct{}, 1),
+	}
+
+	if err := m.setupRegistry(); err != nil {
+		return nil, err
+	}
+
+	m.wg.Add(1)
+	go m.eventLoop()
+
+	m.notifierWg.Add(1)
+	go m.notifier()
+
+	m.post(m.rebuildNotifications)
+
+	return m, nil
+}
+
+func (m *Manager) post(fn func()) {
+	m.wlCtx.Post(fn)
+}
+
+func (m *Manager) setupRegistry() error {
+	ctx := m.display.Context()
+
+	registry, err := m.display.GetRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to get registry: %w", err)
+	}
+	m.registry = registry
+
+	registry.SetGlobalHandler(func(e wlclient.RegistryGlobalEvent) {
+		switch e.Interface {
+		case ext_idle_notify.ExtIdleNotifierV1InterfaceName:
+			notifier := ext_idle_notify.NewExtIdleNotifierV1(ctx)
+			version := min(e.Version, 2)
+			if err := registry.Bind(e.Name, e.Interface, version, notifier); err != nil {
+				log.Errorf("Idle: failed to bind %s: %v", e.Interface, err)
+				return
+			}
+			m.idleNotifier = notifier
+			m.notifierVer = version
+			log.Infof("Idle: bound %s", e.Interface)
+		case wlr_output_power.ZwlrOutputPowerManagerV1InterfaceName:
+			powerMgr := wlr_output_power.NewZwlrOutputPowerManagerV1(ctx)
+			if err := registry.Bind(e.Name, e.Interface, 1, powerMgr); err != nil {
+				log.Warnf("Idle: failed to bind %s: %v", e.Interface, err)
+				return
+			}
+			m.powerMgr = powerMgr
+		case "wl_seat":
+			if m.seat != nil {
+				return
+			}
+			seat := wlclient.NewSeat(ctx)
+			if err := registry.Bind(e.Name, e.Interface, 1, seat); err != nil {
+				log.Errorf("Idle: failed to bind wl_seat: %v", err)
+				return
+			}
+			m.seat = seat
+		case "wl_output":
+			output := wlclient.NewOutput(ctx)
+			if err := registry.Bind(e.Name, e.Interface, 1, output); err != nil {
+				log.Warnf("Idle: failed to bind wl_output: %v", err)
+				return
+			}
+			m.outputs.Store(e.Name, &outputPower{output: output})
+		}
+	})
+
+	registry.SetGlobalRemoveHandler(func(e wlclient.RegistryGlobalRemoveEvent) {
+		op, ok := m.outputs.LoadAndDelete(e.Name)
+		if !ok {
+			return
+		}
+		if op.control != nil {
+			op.control.Destroy()
+		}
+		op.output.Release()
+	})
+
+	m.display.Roundtrip()
+	m.display.Roundtrip()
+
+	if m.idleNotifier == nil {
+		return fmt.Errorf("compositor does not support %s", ext_idle_notify.ExtIdleNotifierV1InterfaceName)
+	}
+
+	if m.seat == nil {
+		return fmt.Errorf("no seat available")
+	}
+
+	return nil
+}
+
+// rebuildNotifications recreates one idle notification per configured stage.
+// Must run on the Wayland dispatcher goroutine.
+func (m *Manager) rebuildNotifications() {
+	config := m.getConfig()
+
+	m.stageMutex.Lock()
+	for _, st := range m.stages {
+		if st.handle != nil {
+			st.handle.Destroy()
+		}
+	}
+	m.generation++
+	gen := m.generation
+	m.stages = make([]*stageState, 0, len(config.Stages))
+
+	if config.Enabled && m.idleNotifier != nil && m.seat != nil {
+		for i, stage := range config.Stages {
+			st := &stageState{stage: stage}
+			m.stages = append(m.stages, st)
+
+			handle, err := m.idleNotifier.GetIdleNotification(uint32(stage.Timeout)*1000, m.seat)
+			if err != nil {
+				log.Warnf("Idle: failed to create notification for %s stage: %v", stage.Action, err)
+				continue
+			}
+
+			index := i
+			handle.SetIdledHandler(func(e ext_idle_notify.ExtIdleNotificationV1IdledEvent) {
+				m.queueEvent(event{kind: eventIdled, gen: gen, index: index})
+			})
+			handle.SetResumedHandler(func(e ext_idle_notify.ExtIdleNotificationV1ResumedEvent) {
+				m.queueEvent(event{kind: eventResumed, gen: gen, index: index})
+			})
+			st.handle = handle
+		}
+	}
+	m.rebuildProbes(gen)
+	m.stageMutex.Unlock()
+
+	m.queueEvent(event{kind: eventReset, gen: gen})
+}
+
+// rebuildProbes pairs a regular idle notification with an input-only one.
+// When only the input-only one idles, a Wayland idle inhibitor is holding
+// the session awake. Needs ext_idle_notifier_v1 version 2 and stageMutex.
+func (m *Manager) rebuildProbes(gen uint64) {
+	for i, probe := range m.probes {
+		if probe != nil {
+			probe.Destroy()
+		}
+		m.probes[i] = nil
+		m.probeIdle[i] = false
+	}
+	m.waylandInhibited = false
+
+	if m.idleNotifier == nil || m.seat == nil || m.notifierVer < 2 {
+		return
+	}
+
+	timeout := uint32(probeTimeout / time.Millisecond)
+	regular, err := m.idleNotifier.GetIdleNotification(timeout, m.seat)
+	if err != nil {
+		log.Debugf("Idle: failed to create inhibit probe: %v", err)
+		return
+	}
+	input, err := m.idleNotifier.GetInputIdleNotification(timeout, m.seat)
+	if err != nil {
+		regular.Destroy()
+		log.Debugf("Idle: failed to create input inhibit probe: %v", err)
+		return
+	}
+
+	for index, probe := range []*ext_idle_notify.ExtIdleNotificationV1{probeRegular: regular, probeInput: input} {
+		probe.SetIdledHandler(func(e ext_idle_notify.ExtIdleNotificationV1IdledEvent) {
+			m.queueEvent(event{kind: eventProbeIdled, gen: gen, index: index})
+		})
+		probe.SetResumedHandler(func(e ext_idle_notify.ExtIdleNotificationV1ResumedEvent) {
+			m.queueEvent(event{kind: eventProbeResumed, gen: gen, index: index})
+		})
+		m.probes[index] = probe
+	}
+}
+
+func (m *Manager) queueEvent(ev event) {
+	select {
+	case m.events <- ev:
+	default:
+		log.Warn("Idle: event queue full, dropping event")
+	}
+}
+
+func (m *Manager) eventLoop() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case ev := <-m.events:
+			m.handleEvent(ev)
+		}
+	}
+}
+
+func (m *Manager) handleEvent(ev event) {
+	switch ev.kind {
+	case eventIdled:
+		m.handleIdled(ev.gen, ev.index)
+	case eventResumed:
+		m.handleResumed(ev.gen, ev.index)
+	case eventReset:
+		m.restoreAll()
+	case eventProbeIdled, eventProbeResumed, eventProbeCheck:
+		m.handleProbe(ev)
+	}
+	m.updateState()
+}
+
+func (m *Manager) handleProbe(ev event) {
+	m.stageMutex.Lock()
+	defer m.stageMutex.Unlock()
+
+	if ev.gen != m.generation || ev.index < probeRegular || ev.index > probeInput {
+		return
+	}
+
+	switch ev.kind {
+	case eventProbeIdled:
+		m.probeIdle[ev.index] = true
+		if ev.index == probeRegular {
+			m.waylandInhibited = false
+			return
+		}
+		// The compositor may deliver the two idled events in either order.
+		time.AfterFunc(probeGrace, func() {
+			m.queueEvent(event{kind: eventProbeCheck, gen: ev.gen, index: probeInput})
+		})
+	case eventProbeResumed:
+		m.probeIdle[ev.index] = false
+		m.waylandInhibited = false
+	case eventProbeCheck:
+		m.waylandInhibited = m.probeIdle[probeInput] && !m.probeIdle[probeRegular]
+	}
+}
+
+// WaylandInhibited reports whether a Wayland idle inhibitor was observed
+// keeping the session awake during the current idle period.
+func (m *Manager) WaylandInhibited() bool {
+	m.stageMutex.Lock()
+	defer m.stageMutex.Unlock()
+	return m.waylandInhibited
+}
+
+func (m *Manager) lookupStage(gen uint64, index int) *stageState {
+	if gen != m.generation || index < 0 || index >= len(m.stages) {
+		return nil
+	}
+	return m.stages[index]
+}
+
+func (m *Manager) handleIdled(gen uint64, index int) {
+	m.stageMutex.Lock()
+	st := m.lookupStage(gen, index)
+	if st == nil {
+		m.stageMutex.Unlock()
+		return
+	}
+	st.idle = true
+	setHint := !m.idleHint
+	m.idleHint = true
+	stage := st.stage
+	m.stageMutex.Unlock()
+
+	if setHint {
+		if session := m.getSession(); session != nil {
+			if err := session.SetIdleHint(true); err != nil {
+				log.Debugf("Idle: failed to set idle hint: %v", err)
+			}
+		}
+	}
+
+	if inhibitors := m.activeInhibitors(); len(inhibitors) > 0 {
+		log.Infof("Idle: %s stage inhibited by %v", stage.Action, inhibitors)
+		m.stageMutex.Lock()
+		st.inhibited = true
+		m.stageMutex.Unlock()
+		return
+	}
+
+	log.Infof("Idle: %ds elapsed, running %s", stage.Timeout, stage.Action)
+	if err := m.runAction(stage); err != nil {
+		log.Warnf("Idle: %s failed: %v", stage.Action, err)
+		return
+	}
+
+	m.stageMutex.Lock()
+	st.fired = true
+	m.stageMutex.Unlock()
+}
+
+func (m *Manager) handleResumed(gen uint64, index int) {
+	m.stageMutex.Lock()
+	st := m.lookupStage(gen, index)
+	if st == nil {
+		m.stageMutex.Unlock()
+		return
+	}
+	fired := st.fired
+	stage := st.stage
+	st.idle = false
+	st.fired = false
+	st.inhibited = false
+
+	anyIdle := false
+	for _, other := range m.stages {
+		if other.idle {
+			anyIdle = true
+			break
+		}
+	}
+	clearHint := !anyIdle && m.idleHint
+	if clearHint {
+		m.idleHint = false
+	}
+	m.stageMutex.Unlock()
+
+	if fired {
+		m.undoAction(stage)
+	}
+
+	if clearHint {
+		if session := m.getSession(); session != nil {
+			if err := session.SetIdleHint(false); err != nil {
+				log.Debugf("Idle: failed to clear idle hint: %v", err)
+			}
+		}
+	}
+}
+
+func (m *Manager) restoreAll() {
+	if len(m.dimmed) > 0 {
+		m.undim()
+	}
+	if m.dpmsOff {
+		if err := m.setDPMS(true); err != nil {
+			log.Warnf("Idle: failed to restore outputs: %v", err)
+		}
+	}
+
+	m.stageMutex.Lock()
+	clearHint := m.idleHint
+	m.idleHint = false
+	m.stageMutex.Unlock()
+
+	if !clearHint {
+		return
+	}
+	if session := m.getSession(); session != nil {
+		if err := session.SetIdleHint(false); err != nil {
+			log.Debugf("Idle: failed to clear idle hint: %v", err)
+		}
+	}
+}
+
+func (m *Manager) activeInhibitors() []string {
+	if !m.getConfig().RespectInhibitors {
+		return nil
+	}
+
+	if source := m.getInhibitorSource(); source != nil {
+		return source.BlockingInhibitors("idle")
+	}
+
+	var inhibitors []string
+
+	if ss := m.getScreensaver(); ss != nil {
+		for _, inh := range ss.GetScreensaverState().Inhibitors {
+			inhibitors = append(inhibitors, fmt.Sprintf("%s (%s)", inh.AppName, inh.Reason))
+		}
+	}
+
+	if session := m.getSession(); session != nil {
+		logindInhibitors, err := session.ListInhibitors()
+		if err != nil {
+			log.Debugf("Idle: failed to list logind inhibitors: %v", err)
+		}
+		for _, inh := range logindInhibitors {
+			if !inh.Blocks("idle") {
+				continue
+			}
+			inhibitors = append(inhibitors, fmt.Sprintf("%s (%s)", inh.Who, inh.Why))
+		}
+	}
+
+	return inhibitors
+}
+
+func (m *Manager) getConfig() Config {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+	return m.config
+}
+
+func (m *Manager) getSession() SessionController {
+	m.backendMutex.RLock()
+	defer m.backendMutex.RUnlock()
+	return m.session
+}
+
+func (m *Manager) getBrightness() BrightnessController {
+	m.backendMutex.RLock()
+	defer m.backendMutex.RUnlock()
+	return m.brightness
+}
+
+func (m *Manager) getScreensaver() ScreensaverSource {
+	m.backendMutex.RLock()
+	defer m.backendMutex.RUnlock()
+	return m.screensaver
+}
+
+func (m *Manager) getInhibitorSource() InhibitorSource {
+	m.backendMutex.RLock()
+	defer m.backendMutex.RUnlock()
+	return m.inhibitors
+}
+
+func (m *Manager) SetInhibitorSource(s InhibitorSource) {
+	m.backendMutex.Lock()
+	m.inhibitors = s
+	m.backendMutex.Unlock()
+}
+
+func (m *Manager) SetBrightnessController(b BrightnessController) {
+	m.backendMutex.Lock()
+	m.brightness = b
+	m.backendMutex.Unlock()
+}
+
+func (m *Manager) SetScreensaverSource(s ScreensaverSource) {
+	m.backendMutex.Lock()
+	m.screensaver = s
+	m.backendMutex.Unlock()
+}
+
+// WatchLoginctl uses lm for lock/suspend/idle hints and restarts the idle
+// timers after the system resumes from sleep.
+func (m *Manager) WatchLoginctl(lm *loginctl.Manager) {
+	m.backendMutex.Lock()
+	m.session = lm
+	m.backendMutex.Unlock()
+
+	ch := lm.Subscribe("idle")
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer lm.Unsubscribe("idle")
+
+		preparing := lm.GetState().PreparingForSleep
+		for {
+			select {
+			case <-m.stopChan:
+				return
+			case state, ok := <-ch:
+				if !ok {
+					return
+				}
+				if preparing && !state.PreparingForSleep {
+					log.Info("Idle: system resumed, resetting stages")
+					m.post(m.rebuildNotifications)
+				}
+				preparing = state.PreparingForSleep
+			}
+		}
+	}()
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{
+			Config:     m.getConfig(),
+			Available:  m.idleNotifier != nil,
+			Inhibitors: []string{},
+			Stages:     []StageState{},
+		}
+	}
+	stateCopy := *m.state
+	return stateCopy
+}
+
+func (m *Manager) updateState() {
+	inhibitors := m.activeInhibitors()
+	if inhibitors == nil {
+		inhibitors = []string{}
+	}
+
+	m.stageMutex.Lock()
+	stages := make([]StageState, 0, len(m.stages))
+	idle := false
+	waylandInhibited := m.waylandInhibited
+	for _, st := range m.stages {
+		stages = append(stages, StageState{
+			Stage:     st.stage,
+			Idle:      st.idle,
+			Fired:     st.fired,
+			Inhibited: st.inhibited,
+		})
+		idle = idle || st.idle
+	}
+	m.stageMutex.Unlock()
+
+	newState := State{
+		Config:           m.getConfig(),
+		Available:        m.idleNotifier != nil,
+		Idle:             idle,
+		Inhibited:        len(inhibitors) > 0,
+		Inhibitors:       inhibitors,
+		Stages:           stages,
+		WaylandInhibited: waylandInhibited,
+	}
+
+	m.stateMutex.Lock()
+	m.state = &newState
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if ch, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(ch)
+	}
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) notifier() {
+	defer m.notifierWg.Done()
+
+	const minGap = 100 * time.Millisecond
+	timer := time.NewTimer(minGap)
+	timer.Stop()
+	var pending bool
+
+	for {
+		select {
+		case <-m.stopChan:
+			timer.Stop()
+			return
+		case <-m.dirty:
+			if pending {
+				continue
+			}
+			pending = true
+			timer.Reset(minGap)
+		case <-timer.C:
+			if !pending {
+				continue
+			}
+
+			currentState := m.GetState()
+
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &currentState) {
+				pending = false
+				continue
+			}
+
+			m.subscribers.Range(func(key string, ch chan State) bool {
+				select {
+				case ch <- currentState:
+				default:
+					log.Warn("Idle: subscriber channel full, dropping update")
+				}
+				return true
+			})
+
+			stateCopy := currentState
+			m.lastNotified = &stateCopy
+			pending = false
+		}
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	if old.Available != new.Available || old.Idle != new.Idle || old.Inhibited != new.Inhibited || old.WaylandInhibited != new.WaylandInhibited {
+		return true
+	}
+	if old.Config.Enabled != new.Config.Enabled || old.Config.RespectInhibitors != new.Config.RespectInhibitors {
+		return true
+	}
+	if len(old.Inhibitors) != len(new.Inhibitors) || len(old.Stages) != len(new.Stages) {
+		return true
+	}
+	for i := range new.Inhibitors {
+		if old.Inhibitors[i] != new.Inhibitors[i] {
+			return true
+		}
+	}
+	for i := range new.Stages {
+		if old.Stages[i] != new.Stages[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+	m.notifierWg.Wait()
+
+	m.undim()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+
+	m.stageMutex.Lock()
+	for _, st := range m.stages {
+		if st.handle != nil {
+			st.handle.Destroy()
+		}
+	}
+	m.stages = nil
+	for i, probe := range m.probes {
+		if probe != nil {
+			probe.Destroy()
+		}
+		m.probes[i] = nil
+	}
+	m.stageMutex.Unlock()
+
+	m.outputs.Range(func(key uint32, op *outputPower) bool {
+		if op.control != nil {
+			op.control.Destroy()
+		}
+		m.outputs.Delete(key)
+		return true
+	})
+
+	if m.powerMgr != nil {
+		m.powerMgr.Destroy()
+	}
+	if m.idleNotifier != nil {
+		m.idleNotifier.Destroy()
+	}
+	if m.registry != nil {
+		m.registry.Destroy()
+	}
+}
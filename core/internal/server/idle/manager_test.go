@@ -0,0 +1,317 @@
+package idle
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/brightness"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/freedesktop"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/loginctl"
+)
+
+type fakeSession struct {
+	mu         sync.Mutex
+	locks      int
+	suspends   int
+	idleHints  []bool
+	inhibitors []loginctl.Inhibitor
+}
+
+func (f *fakeSession) Lock() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.locks++
+	return nil
+}
+
+func (f *fakeSession) Suspend() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.suspends++
+	return nil
+}
+
+func (f *fakeSession) SetIdleHint(idle bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.idleHints = append(f.idleHints, idle)
+	return nil
+}
+
+func (f *fakeSession) ListInhibitors() ([]loginctl.Inhibitor, error) {
+	return f.inhibitors, nil
+}
+
+type fakeBrightness struct {
+	devices []brightness.Device
+	sets    map[string]int
+}
+
+func (f *fakeBrightness) GetState() brightness.State {
+	return brightness.State{Devices: f.devices}
+}
+
+func (f *fakeBrightness) SetBrightness(deviceID string, percent int) error {
+	f.sets[deviceID] = percent
+	for i := range f.devices {
+		if f.devices[i].ID == deviceID {
+			f.devices[i].CurrentPercent = percent
+		}
+	}
+	return nil
+}
+
+type fakeScreensaver struct {
+	state freedesktop.ScreensaverState
+}
+
+func (f *fakeScreensaver) GetScreensaverState() freedesktop.ScreensaverState {
+	return f.state
+}
+
+func newTestManager(config Config, stages ...Stage) *Manager {
+	m := &Manager{
+		config:     config,
+		dimmed:     make(map[string]int),
+		events:     make(chan event, 64),
+		stopChan:   make(chan struct{}),
+		dirty:      make(chan struct{}, 1),
+		generation: 1,
+	}
+	for _, s := range stages {
+		m.stages = append(m.stages, &stageState{stage: s})
+	}
+	return m
+}
+
+func TestValidateStages(t *testing.T) {
+	stages, err := ValidateStages([]Stage{
+		{Timeout: 600, Action: ActionSuspend},
+		{Timeout: 120, Action: ActionDim},
+		{Timeout: 300, Action: ActionLock, DimPercent: 50},
+	})
+	require.NoError(t, err)
+	require.Len(t, stages, 3)
+	assert.Equal(t, ActionDim, stages[0].Action)
+	assert.Equal(t, defaultDimPercent, stages[0].DimPercent)
+	assert.Equal(t, ActionLock, stages[1].Action)
+	assert.Equal(t, 0, stages[1].DimPercent)
+	assert.Equal(t, ActionSuspend, stages[2].Action)
+}
+
+func TestValidateStages_Invalid(t *testing.T) {
+	_, err := ValidateStages([]Stage{{Timeout: 0, Action: ActionLock}})
+	assert.Error(t, err)
+
+	_, err = ValidateStages([]Stage{{Timeout: 10, Action: "hibernate"}})
+	assert.Error(t, err)
+
+	_, err = ValidateStages([]Stage{{Timeout: 10, Action: ActionDim, DimPercent: 150}})
+	assert.Error(t, err)
+}
+
+func TestManager_DimStageRestoresOnResume(t *testing.T) {
+	session := &fakeSession{}
+	b := &fakeBrightness{
+		devices: []brightness.Device{
+			{ID: "backlight:intel_backlight", Class: brightness.ClassBacklight, CurrentPercent: 80},
+			{ID: "leds:kbd_backlight", Class: brightness.ClassLED, CurrentPercent: 100},
+			{ID: "ddc:i2c-5", Class: brightness.ClassDDC, CurrentPercent: 10},
+		},
+		sets: make(map[string]int),
+	}
+	m := newTestManager(Config{Enabled: true}, Stage{Timeout: 60, Action: ActionDim, DimPercent: 20})
+	m.session = session
+	m.brightness = b
+
+	m.handleIdled(1, 0)
+
+	assert.Equal(t, map[string]int{"backlight:intel_backlight": 20}, b.sets)
+	assert.True(t, m.stages[0].fired)
+	assert.Equal(t, []bool{true}, session.idleHints)
+
+	m.handleResumed(1, 0)
+
+	assert.Equal(t, 80, b.sets["backlight:intel_backlight"])
+	assert.Empty(t, m.dimmed)
+	assert.False(t, m.stages[0].idle)
+	assert.Equal(t, []bool{true, false}, session.idleHints)
+}
+
+func TestManager_LockAndSuspendStages(t *testing.T) {
+	session := &fakeSession{}
+	m := newTestManager(Config{Enabled: true},
+		Stage{Timeout: 300, Action: ActionLock},
+		Stage{Timeout: 900, Action: ActionSuspend},
+	)
+	m.session = session
+
+	m.handleIdled(1, 0)
+	m.handleIdled(1, 1)
+
+	assert.Equal(t, 1, session.locks)
+	assert.Equal(t, 1, session.suspends)
+	assert.Equal(t, []bool{true}, session.idleHints)
+
+	m.handleResumed(1, 1)
+	assert.Equal(t, []bool{true}, session.idleHints)
+	m.handleResumed(1, 0)
+	assert.Equal(t, []bool{true, false}, session.idleHints)
+}
+
+func TestManager_InhibitedByScreensaver(t *testing.T) {
+	session := &fakeSession{}
+	m := newTestManager(Config{Enabled: true, RespectInhibitors: true}, Stage{Timeout: 300, Action: ActionLock})
+	m.session = session
+	m.screensaver = &fakeScreensaver{state: freedesktop.ScreensaverState{
+		Inhibitors: []freedesktop.ScreensaverInhibitor{{AppName: "firefox", Reason: "video playing"}},
+	}}
+
+	m.handleIdled(1, 0)
+
+	assert.Equal(t, 0, session.locks)
+	assert.True(t, m.stages[0].inhibited)
+	assert.False(t, m.stages[0].fired)
+}
+
+func TestManager_InhibitedByLogind(t *testing.T) {
+	session := &fakeSession{inhibitors: []loginctl.Inhibitor{
+		{What: "sleep", Who: "DankMaterialShell", Mode: "delay"},
+		{What: "idle", Who: "mpv", Why: "playing", Mode: "block"},
+	}}
+	m := newTestManager(Config{Enabled: true, RespectInhibitors: true}, Stage{Timeout: 300, Action: ActionLock})
+	m.session = session
+
+	assert.Equal(t, []string{"mpv (playing)"}, m.activeInhibitors())
+
+	m.handleIdled(1, 0)
+	assert.Equal(t, 0, session.locks)
+
+	m.config.RespectInhibitors = false
+	m.handleResumed(1, 0)
+	m.handleIdled(1, 0)
+	assert.Equal(t, 1, session.locks)
+}
+
+func TestManager_StaleGenerationIgnored(t *testing.T) {
+	session := &fakeSession{}
+	m := newTestManager(Config{Enabled: true}, Stage{Timeout: 300, Action: ActionLock})
+	m.session = session
+
+	m.handleIdled(0, 0)
+	m.handleIdled(1, 5)
+
+	assert.Equal(t, 0, session.locks)
+	assert.False(t, m.stages[0].idle)
+}
+
+func TestManager_RestoreAll(t *testing.T) {
+	session := &fakeSession{}
+	b := &fakeBrightness{sets: make(map[string]int)}
+	m := newTestManager(Config{Enabled: true})
+	m.session = session
+	m.brightness = b
+	m.dimmed["backlight:acpi_video0"] = 55
+	m.idleHint = true
+
+	m.restoreAll()
+
+	assert.Equal(t, 55, b.sets["backlight:acpi_video0"])
+	assert.Empty(t, m.dimmed)
+	assert.False(t, m.idleHint)
+	assert.Equal(t, []bool{false}, session.idleHints)
+}
+
+func TestManager_UpdateState(t *testing.T) {
+	m := newTestManager(Config{Enabled: true}, Stage{Timeout: 60, Action: ActionDim, DimPercent: 30})
+	m.stages[0].idle = true
+
+	m.updateState()
+	state := m.GetState()
+
+	assert.True(t, state.Idle)
+	assert.False(t, state.Inhibited)
+	assert.NotNil(t, state.Inhibitors)
+	require.Len(t, state.Stages, 1)
+	assert.Equal(t, ActionDim, state.Stages[0].Action)
+	assert.True(t, state.Stages[0].Idle)
+}
+
+func TestStateChanged(t *testing.T) {
+	assert.True(t, stateChanged(nil, &State{}))
+
+	a := &State{Stages: []StageState{{Stage: Stage{Timeout: 60, Action: ActionDim}}}}
+	b := &State{Stages: []StageState{{Stage: Stage{Timeout: 60, Action: ActionDim}}}}
+	assert.False(t, stateChanged(a, b))
+
+	b.Stages[0].Fired = true
+	assert.True(t, stateChanged(a, b))
+
+	c := &State{Stages: a.Stages, Inhibitors: []string{"mpv (playing)"}}
+	assert.True(t, stateChanged(a, c))
+}
+
+func TestParseStages(t *testing.T) {
+	stages, err := parseStages(map[string]any{
+		"stages": []any{
+			map[string]any{"timeout": float64(120), "action": "dim", "dimPercent": float64(40)},
+			map[string]any{"timeout": float64(300), "action": "lock"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Stage{
+		{Timeout: 120, Action: ActionDim, DimPercent: 40},
+		{Timeout: 300, Action: ActionLock},
+	}, stages)
+
+	_, err = parseStages(map[string]any{"stages": []any{"lock"}})
+	assert.Error(t, err)
+
+	_, err = parseStages(map[string]any{})
+	assert.Error(t, err)
+}
+
+func TestManager_HandleProbe(t *testing.T) {
+	m := newTestManager(Config{Enabled: true})
+
+	m.handleProbe(event{kind: eventProbeIdled, gen: 1, index: probeInput})
+	m.handleProbe(event{kind: eventProbeCheck, gen: 1, index: probeInput})
+	assert.True(t, m.WaylandInhibited())
+
+	m.handleProbe(event{kind: eventProbeResumed, gen: 1, index: probeInput})
+	assert.False(t, m.WaylandInhibited())
+
+	m.handleProbe(event{kind: eventProbeIdled, gen: 1, index: probeInput})
+	m.handleProbe(event{kind: eventProbeIdled, gen: 1, index: probeRegular})
+	m.handleProbe(event{kind: eventProbeCheck, gen: 1, index: probeInput})
+	assert.False(t, m.WaylandInhibited())
+
+	m.handleProbe(event{kind: eventProbeResumed, gen: 1, index: probeRegular})
+	m.handleProbe(event{kind: eventProbeCheck, gen: 0, index: probeInput})
+	assert.False(t, m.WaylandInhibited())
+}
+
+type fakeInhibitorSource struct {
+	blocking []string
+}
+
+func (f *fakeInhibitorSource) BlockingInhibitors(op string) []string {
+	return f.blocking
+}
+
+func TestManager_InhibitorSourceOverrides(t *testing.T) {
+	session := &fakeSession{inhibitors: []loginctl.Inhibitor{
+		{What: "idle", Who: "mpv", Why: "playing", Mode: "block"},
+	}}
+	m := newTestManager(Config{Enabled: true, RespectInhibitors: true}, Stage{Timeout: 300, Action: ActionLock})
+	m.session = session
+	m.SetInhibitorSource(&fakeInhibitorSource{})
+
+	assert.Empty(t, m.activeInhibitors())
+
+	m.handleIdled(1, 0)
+	assert.Equal(t, 1, session.locks)
+}
@@ -0,0 +1,250 @@
+package idle
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/ext_idle_notify"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_output_power"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/brightness"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/freedesktop"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/loginctl"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wlcontext"
+	wlclient "github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+type Action string
+
+const (
+	ActionDim     Action = "dim"
+	ActionLock    Action = "lock"
+	ActionDPMS    Action = "dpms"
+	ActionSuspend Action = "suspend"
+)
+
+const defaultDimPercent = 30
+
+type Stage struct {
+	Timeout    int    `json:"timeout"`
+	Action     Action `json:"action"`
+	DimPercent int    `json:"dimPercent,omitempty"`
+}
+
+type Config struct {
+	Enabled           bool    `json:"enabled"`
+	RespectInhibitors bool    `json:"respectInhibitors"`
+	Stages            []Stage `json:"stages"`
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Enabled:           false,
+		RespectInhibitors: true,
+		Stages: []Stage{
+			{Timeout: 240, Action: ActionDim, DimPercent: defaultDimPercent},
+			{Timeout: 300, Action: ActionLock},
+			{Timeout: 360, Action: ActionDPMS},
+		},
+	}
+}
+
+func ValidateStages(stages []Stage) ([]Stage, error) {
+	out := make([]Stage, 0, len(stages))
+	for i, s := range stages {
+		if s.Timeout <= 0 {
+			return nil, fmt.Errorf("stage %d: timeout must be positive", i)
+		}
+		switch s.Action {
+		case ActionDim:
+			if s.DimPercent == 0 {
+				s.DimPercent = defaultDimPercent
+			}
+			if s.DimPercent < 1 || s.DimPercent > 100 {
+				return nil, fmt.Errorf("stage %d: dimPercent out of range: %d", i, s.DimPercent)
+			}
+		case ActionLock, ActionDPMS, ActionSuspend:
+			s.DimPercent = 0
+		default:
+			return nil, fmt.Errorf("stage %d: unknown action: %s", i, s.Action)
+		}
+		out = append(out, s)
+	}
+	sort.SliceStable(out, func(a, b int) bool {
+		return out[a].Timeout < out[b].Timeout
+	})
+	return out, nil
+}
+
+func getConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "idle.json"), nil
+}
+
+func LoadConfig() Config {
+	cfg := DefaultConfig()
+
+	path, err := getConfigPath()
+	if err != nil {
+		return cfg
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return DefaultConfig()
+	}
+
+	stages, err := ValidateStages(cfg.Stages)
+	if err != nil {
+		return DefaultConfig()
+	}
+	cfg.Stages = stages
+	return cfg
+}
+
+func SaveConfig(cfg Config) error {
+	path, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+type StageState struct {
+	Stage
+	Idle      bool `json:"idle"`
+	Fired     bool `json:"fired"`
+	Inhibited bool `json:"inhibited"`
+}
+
+type State struct {
+	Config           Config       `json:"config"`
+	Available        bool         `json:"available"`
+	Idle             bool         `json:"idle"`
+	Inhibited        bool         `json:"inhibited"`
+	Inhibitors       []string     `json:"inhibitors"`
+	WaylandInhibited bool         `json:"waylandInhibited"`
+	Stages           []StageState `json:"stages"`
+}
+
+type SessionController interface {
+	Lock() error
+	Suspend() error
+	SetIdleHint(idle bool) error
+	ListInhibitors() ([]loginctl.Inhibitor, error)
+}
+
+type BrightnessController interface {
+	GetState() brightness.State
+	SetBrightness(deviceID string, percent int) error
+}
+
+type ScreensaverSource interface {
+	GetScreensaverState() freedesktop.ScreensaverState
+}
+
+// InhibitorSource replaces the built-in ScreenSaver/logind lookup, e.g. to
+// apply user ignore rules.
+type InhibitorSource interface {
+	BlockingInhibitors(op string) []string
+}
+
+type eventKind int
+
+const (
+	eventIdled eventKind = iota
+	eventResumed
+	eventReset
+	eventRefresh
+	eventProbeIdled
+	eventProbeResumed
+	eventProbeCheck
+)
+
+const (
+	probeRegular = iota
+	probeInput
+)
+
+type event struct {
+	kind  eventKind
+	gen   uint64
+	index int
+}
+
+type stageState struct {
+	stage     Stage
+	handle    *ext_idle_notify.ExtIdleNotificationV1
+	idle      bool
+	fired     bool
+	inhibited bool
+}
+
+type outputPower struct {
+	output  *wlclient.Output
+	control *wlr_output_power.ZwlrOutputPowerV1
+}
+
+type Manager struct {
+	wlCtx        wlcontext.WaylandContext
+	display      wlclient.WaylandDisplay
+	registry     *wlclient.Registry
+	idleNotifier *ext_idle_notify.ExtIdleNotifierV1
+	notifierVer  uint32
+	seat         *wlclient.Seat
+	powerMgr     *wlr_output_power.ZwlrOutputPowerManagerV1
+	outputs      syncmap.Map[uint32, *outputPower]
+
+	configMutex sync.RWMutex
+	config      Config
+
+	backendMutex sync.RWMutex
+	session      SessionController
+	brightness   BrightnessController
+	screensaver  ScreensaverSource
+	inhibitors   InhibitorSource
+
+	stageMutex sync.Mutex
+	stages     []*stageState
+	generation uint64
+	dimmed     map[string]int
+	dpmsOff    bool
+	idleHint   bool
+
+	probes           [2]*ext_idle_notify.ExtIdleNotificationV1
+	probeIdle        [2]bool
+	waylandInhibited bool
+
+	events   chan event
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+
+	stateMutex sync.RWMutex
+	state      *State
+
+	subscribers  syncmap.Map[string, chan State]
+	dirty        chan struct{}
+	notifierWg   sync.WaitGroup
+	lastNotified *State
+}
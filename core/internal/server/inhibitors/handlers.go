@@ -0,0 +1,111 @@
+package inhibitors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "inhibitors manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "inhibitors.list":
+		handleList(conn, req, manager)
+	case "inhibitors.setRules":
+		handleSetRules(conn, req, manager)
+	case "inhibitors.release":
+		handleRelease(conn, req, manager)
+	case "inhibitors.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleList(conn net.Conn, req models.Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleSetRules(conn net.Conn, req models.Request, manager *Manager) {
+	rules, err := parseRules(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.SetRules(rules); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "inhibitor rules set"})
+}
+
+func handleRelease(conn net.Conn, req models.Request, manager *Manager) {
+	id, err := params.StringNonEmpty(req.Params, "id")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.Release(id); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "inhibitor released"})
+}
+
+func parseRules(p map[string]any) ([]Rule, error) {
+	raw, err := params.Get[[]any](p, "rules")
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(raw))
+	for i, item := range raw {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("rule %d: expected object", i)
+		}
+		action, err := params.String(obj, "action")
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+		rules = append(rules, Rule{
+			Source: Source(params.StringOpt(obj, "source", "")),
+			App:    params.StringOpt(obj, "app", ""),
+			Exe:    params.StringOpt(obj, "exe", ""),
+			Reason: params.StringOpt(obj, "reason", ""),
+			Action: RuleAction(action),
+		})
+	}
+	return rules, nil
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
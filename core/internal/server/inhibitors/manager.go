@@ -0,0 +1,492 @@
+package inhibitors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/freedesktop"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/idle"
+)
+
+// logind has no signal for inhibitor changes, so its list is polled.
+const logindPollInterval = 5 * time.Second
+
+func NewManager(config Config) *Manager {
+	m := &Manager{
+		config:      config,
+		procRoot:    "/proc",
+		refreshChan: make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+		dirty:       make(chan struct{}, 1),
+	}
+
+	m.wg.Add(1)
+	go m.refreshLoop()
+
+	m.notifierWg.Add(1)
+	go m.notifier()
+
+	m.Refresh()
+
+	return m
+}
+
+func (m *Manager) Refresh() {
+	select {
+	case m.refreshChan <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) refreshLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(logindPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			m.refresh()
+		case <-m.refreshChan:
+			m.refresh()
+		}
+	}
+}
+
+func (m *Manager) refresh() {
+	config := m.getConfig()
+	inhibitors, releases := applyRules(m.collect(), config.Rules)
+
+	if len(releases) > 0 {
+		if ss := m.getScreensaver(); ss != nil {
+			for _, inh := range releases {
+				cookie, ok := screensaverCookie(inh.ID)
+				if !ok {
+					continue
+				}
+				if ss.ReleaseScreensaverInhibitor(cookie) {
+					log.Infof("Inhibitors: auto-released %s (%s) by rule", inh.App, inh.Reason)
+				}
+			}
+		}
+	}
+
+	if ss := m.getScreensaver(); ss != nil {
+		m.forgetStalePeers(ss)
+	}
+
+	m.setState(inhibitors, config.Rules)
+}
+
+func (m *Manager) setState(inhibitors []Inhibitor, rules []Rule) {
+	newState := State{
+		Inhibitors: inhibitors,
+		Rules:      rules,
+	}
+	for _, inh := range inhibitors {
+		newState.IdleBlocked = newState.IdleBlocked || inh.Blocks("idle")
+		newState.SleepBlocked = newState.SleepBlocked || inh.Blocks("sleep")
+	}
+
+	m.stateMutex.Lock()
+	m.state = &newState
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+func (m *Manager) collect() []Inhibitor {
+	inhibitors := []Inhibitor{}
+
+	if logind := m.getLogind(); logind != nil {
+		list, err := logind.ListInhibitors()
+		if err != nil {
+			log.Debugf("Inhibitors: failed to list logind inhibitors: %v", err)
+		}
+		// One process may hold several locks for the same operation, so the
+		// id includes the mode and reason and counts any exact duplicates.
+		seen := make(map[string]int)
+		for _, inh := range list {
+			id := fmt.Sprintf("logind:%d:%s:%s:%s", inh.PID, inh.What, inh.Mode, inh.Why)
+			n := seen[id]
+			seen[id]++
+			if n > 0 {
+				id = fmt.Sprintf("%s:%d", id, n)
+			}
+			inhibitors = append(inhibitors, Inhibitor{
+				ID:      id,
+				Source:  SourceLogind,
+				What:    strings.Split(inh.What, ":"),
+				Mode:    inh.Mode,
+				App:     inh.Who,
+				Reason:  inh.Why,
+				Process: m.resolveProcess(inh.PID),
+			})
+		}
+	}
+
+	if ss := m.getScreensaver(); ss != nil {
+		for _, inh := range ss.GetScreensaverState().Inhibitors {
+			inhibitors = append(inhibitors, Inhibitor{
+				ID:         fmt.Sprintf("screensaver:%d", inh.Cookie),
+				Source:     SourceScreensaver,
+				What:       []string{"idle"},
+				Mode:       "block",
+				App:        inh.AppName,
+				Reason:     inh.Reason,
+				Since:      inh.StartTime,
+				Process:    m.resolveProcess(m.peerPID(ss, inh.Peer)),
+				Releasable: true,
+			})
+		}
+	}
+
+	if wl := m.getWayland(); wl != nil && wl.WaylandInhibited() {
+		inhibitors = append(inhibitors, Inhibitor{
+			ID:     string(SourceWayland),
+			Source: SourceWayland,
+			What:   []string{"idle"},
+			Mode:   "block",
+			Reason: "idle inhibitor on a visible surface",
+		})
+	}
+
+	return inhibitors
+}
+
+// applyRules marks inhibitors matched by an ignore rule and returns the
+// releasable ones matched by a release rule separately. Release rules act as
+// ignore rules for inhibitors DMS does not own. First matching rule wins.
+func applyRules(inhibitors []Inhibitor, rules []Rule) ([]Inhibitor, []Inhibitor) {
+	kept := make([]Inhibitor, 0, len(inhibitors))
+	var released []Inhibitor
+
+	for _, inh := range inhibitors {
+		idx := slices.IndexFunc(rules, func(r Rule) bool { return r.Matches(inh) })
+		if idx == -1 {
+			kept = append(kept, inh)
+			continue
+		}
+		if rules[idx].Action == RuleRelease && inh.Releasable {
+			released = append(released, inh)
+			continue
+		}
+		inh.Ignored = true
+		kept = append(kept, inh)
+	}
+
+	return kept, released
+}
+
+func screensaverCookie(id string) (uint32, bool) {
+	raw, ok := strings.CutPrefix(id, string(SourceScreensaver)+":")
+	if !ok {
+		return 0, false
+	}
+	cookie, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(cookie), true
+}
+
+func (m *Manager) peerPID(ss ScreensaverSource, peer string) uint32 {
+	if peer == "" {
+		return 0
+	}
+	if pid, ok := m.peerPIDs.Load(peer); ok {
+		return pid
+	}
+	pid, err := ss.ScreensaverPeerPID(peer)
+	if err != nil {
+		log.Debugf("Inhibitors: failed to resolve pid of %s: %v", peer, err)
+		return 0
+	}
+	m.peerPIDs.Store(peer, pid)
+	return pid
+}
+
+// forgetStalePeers drops cached pids of peers that no longer hold a
+// ScreenSaver inhibitor, since bus names can be reused by other processes.
+func (m *Manager) forgetStalePeers(ss ScreensaverSource) {
+	live := make(map[string]bool)
+	for _, inh := range ss.GetScreensaverState().Inhibitors {
+		live[inh.Peer] = true
+	}
+	m.peerPIDs.Range(func(peer string, _ uint32) bool {
+		if !live[peer] {
+			m.peerPIDs.Delete(peer)
+		}
+		return true
+	})
+}
+
+func (m *Manager) resolveProcess(pid uint32) *ProcessInfo {
+	if pid == 0 {
+		return nil
+	}
+
+	dir := filepath.Join(m.procRoot, strconv.FormatUint(uint64(pid), 10))
+	if _, err := os.Stat(dir); err != nil {
+		return &ProcessInfo{PID: pid}
+	}
+
+	info := &ProcessInfo{PID: pid}
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		info.Exe = strings.TrimSuffix(exe, " (deleted)")
+	}
+	if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		info.Comm = strings.TrimSpace(string(comm))
+	}
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		info.Cmdline = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	}
+	return info
+}
+
+// BlockingInhibitors lists non-ignored inhibitors that block op. Wayland
+// inhibitors are left out since the compositor already enforces them.
+func (m *Manager) BlockingInhibitors(op string) []string {
+	state := m.GetState()
+
+	var blocking []string
+	for _, inh := range state.Inhibitors {
+		if inh.Source == SourceWayland || !inh.Blocks(op) {
+			continue
+		}
+		blocking = append(blocking, fmt.Sprintf("%s (%s)", inh.App, inh.Reason))
+	}
+	return blocking
+}
+
+func (m *Manager) Release(id string) error {
+	cookie, ok := screensaverCookie(id)
+	if !ok {
+		return fmt.Errorf("only ScreenSaver inhibitors can be released, add an ignore rule for %s", id)
+	}
+
+	ss := m.getScreensaver()
+	if ss == nil {
+		return fmt.Errorf("screensaver service not available")
+	}
+	if !ss.ReleaseScreensaverInhibitor(cookie) {
+		return fmt.Errorf("inhibitor not found: %s", id)
+	}
+
+	m.Refresh()
+	return nil
+}
+
+func (m *Manager) SetRules(rules []Rule) error {
+	if err := ValidateRules(rules); err != nil {
+		return err
+	}
+
+	m.configMutex.Lock()
+	m.config.Rules = rules
+	config := m.config
+	m.configMutex.Unlock()
+
+	if err := SaveConfig(config); err != nil {
+		return err
+	}
+
+	m.Refresh()
+	return nil
+}
+
+func (m *Manager) getConfig() Config {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+	return m.config
+}
+
+func (m *Manager) getLogind() LogindSource {
+	m.backendMutex.RLock()
+	defer m.backendMutex.RUnlock()
+	return m.logind
+}
+
+func (m *Manager) getScreensaver() ScreensaverSource {
+	m.backendMutex.RLock()
+	defer m.backendMutex.RUnlock()
+	return m.screensaver
+}
+
+func (m *Manager) getWayland() WaylandSource {
+	m.backendMutex.RLock()
+	defer m.backendMutex.RUnlock()
+	return m.wayland
+}
+
+func (m *Manager) SetLogindSource(s LogindSource) {
+	m.backendMutex.Lock()
+	m.logind = s
+	m.backendMutex.Unlock()
+	m.Refresh()
+}
+
+// WatchScreensaver tracks ScreenSaver inhibitors held through fm.
+func (m *Manager) WatchScreensaver(fm *freedesktop.Manager) {
+	m.backendMutex.Lock()
+	m.screensaver = fm
+	m.backendMutex.Unlock()
+
+	ch := fm.SubscribeScreensaver("inhibitors")
+	watchChannel(m, ch, func() { fm.UnsubscribeScreensaver("inhibitors") })
+}
+
+// WatchIdle tracks Wayland idle-inhibit state observed by im.
+func (m *Manager) WatchIdle(im *idle.Manager) {
+	m.backendMutex.Lock()
+	m.wayland = im
+	m.backendMutex.Unlock()
+
+	ch := im.Subscribe("inhibitors")
+	watchChannel(m, ch, func() { im.Unsubscribe("inhibitors") })
+}
+
+func watchChannel[T any](m *Manager, ch chan T, unsubscribe func()) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-m.stopChan:
+				return
+			case _, ok := <-ch:
+				if !ok {
+					return
+				}
+				m.Refresh()
+			}
+		}
+	}()
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{
+			Inhibitors: []Inhibitor{},
+			Rules:      m.getConfig().Rules,
+		}
+	}
+	stateCopy := *m.state
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if ch, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(ch)
+	}
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) notifier() {
+	defer m.notifierWg.Done()
+
+	const minGap = 100 * time.Millisecond
+	timer := time.NewTimer(minGap)
+	timer.Stop()
+	var pending bool
+
+	for {
+		select {
+		case <-m.stopChan:
+			timer.Stop()
+			return
+		case <-m.dirty:
+			if pending {
+				continue
+			}
+			pending = true
+			timer.Reset(minGap)
+		case <-timer.C:
+			if !pending {
+				continue
+			}
+
+			currentState := m.GetState()
+
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &currentState) {
+				pending = false
+				continue
+			}
+
+			m.subscribers.Range(func(key string, ch chan State) bool {
+				select {
+				case ch <- currentState:
+				default:
+					log.Warn("Inhibitors: subscriber channel full, dropping update")
+				}
+				return true
+			})
+
+			stateCopy := currentState
+			m.lastNotified = &stateCopy
+			pending = false
+		}
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	if old.IdleBlocked != new.IdleBlocked || old.SleepBlocked != new.SleepBlocked {
+		return true
+	}
+	if !slices.Equal(old.Rules, new.Rules) || len(old.Inhibitors) != len(new.Inhibitors) {
+		return true
+	}
+	for i := range new.Inhibitors {
+		a, b := old.Inhibitors[i], new.Inhibitors[i]
+		if a.ID != b.ID || a.App != b.App || a.Reason != b.Reason || a.Mode != b.Mode || a.Ignored != b.Ignored {
+			return true
+		}
+		if !slices.Equal(a.What, b.What) {
+			return true
+		}
+		if (a.Process == nil) != (b.Process == nil) || (a.Process != nil && *a.Process != *b.Process) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+	m.notifierWg.Wait()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,254 @@
+package inhibitors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/freedesktop"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/loginctl"
+)
+
+type fakeLogind struct {
+	inhibitors []loginctl.Inhibitor
+}
+
+func (f *fakeLogind) ListInhibitors() ([]loginctl.Inhibitor, error) {
+	return f.inhibitors, nil
+}
+
+type fakeScreensaver struct {
+	state    freedesktop.ScreensaverState
+	pids     map[string]uint32
+	lookups  int
+	released []uint32
+}
+
+func (f *fakeScreensaver) GetScreensaverState() freedesktop.ScreensaverState {
+	return f.state
+}
+
+func (f *fakeScreensaver) ReleaseScreensaverInhibitor(cookie uint32) bool {
+	for i, inh := range f.state.Inhibitors {
+		if inh.Cookie == cookie {
+			f.state.Inhibitors = append(f.state.Inhibitors[:i], f.state.Inhibitors[i+1:]...)
+			f.released = append(f.released, cookie)
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeScreensaver) ScreensaverPeerPID(peer string) (uint32, error) {
+	f.lookups++
+	return f.pids[peer], nil
+}
+
+type fakeWayland struct {
+	inhibited bool
+}
+
+func (f *fakeWayland) WaylandInhibited() bool {
+	return f.inhibited
+}
+
+func newTestManager(t *testing.T, rules ...Rule) *Manager {
+	procRoot := t.TempDir()
+	pidDir := filepath.Join(procRoot, "4242")
+	require.NoError(t, os.MkdirAll(pidDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(pidDir, "comm"), []byte("firefox\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(pidDir, "cmdline"), []byte("/usr/lib/firefox/firefox\x00--new-window\x00"), 0o644))
+	require.NoError(t, os.Symlink("/usr/lib/firefox/firefox", filepath.Join(pidDir, "exe")))
+
+	return &Manager{
+		config:      Config{Rules: rules},
+		procRoot:    procRoot,
+		refreshChan: make(chan struct{}, 1),
+		stopChan:    make(chan struct{}),
+		dirty:       make(chan struct{}, 1),
+	}
+}
+
+func testSources() (*fakeLogind, *fakeScreensaver) {
+	logind := &fakeLogind{inhibitors: []loginctl.Inhibitor{
+		{What: "sleep", Who: "DankMaterialShell", Why: "lock screen", Mode: "delay", PID: 1},
+		{What: "idle:sleep", Who: "mpv", Why: "playing", Mode: "block", PID: 77},
+	}}
+	ss := &fakeScreensaver{
+		state: freedesktop.ScreensaverState{Inhibitors: []freedesktop.ScreensaverInhibitor{
+			{Cookie: 3, AppName: "firefox", Reason: "video-playing", Peer: ":1.42", StartTime: 100},
+		}},
+		pids: map[string]uint32{":1.42": 4242},
+	}
+	return logind, ss
+}
+
+func TestManager_Collect(t *testing.T) {
+	m := newTestManager(t)
+	logind, ss := testSources()
+	m.logind = logind
+	m.screensaver = ss
+	m.wayland = &fakeWayland{inhibited: true}
+
+	inhibitors := m.collect()
+	require.Len(t, inhibitors, 4)
+
+	assert.Equal(t, SourceLogind, inhibitors[1].Source)
+	assert.Equal(t, []string{"idle", "sleep"}, inhibitors[1].What)
+	assert.Equal(t, uint32(77), inhibitors[1].Process.PID)
+
+	ff := inhibitors[2]
+	assert.Equal(t, "screensaver:3", ff.ID)
+	assert.True(t, ff.Releasable)
+	require.NotNil(t, ff.Process)
+	assert.Equal(t, "/usr/lib/firefox/firefox", ff.Process.Exe)
+	assert.Equal(t, "firefox", ff.Process.Comm)
+	assert.Equal(t, "/usr/lib/firefox/firefox --new-window", ff.Process.Cmdline)
+
+	assert.Equal(t, SourceWayland, inhibitors[3].Source)
+
+	m.collect()
+	assert.Equal(t, 1, ss.lookups)
+}
+
+func TestManager_CollectUniqueLogindIDs(t *testing.T) {
+	m := newTestManager(t)
+	m.logind = &fakeLogind{inhibitors: []loginctl.Inhibitor{
+		{What: "sleep", Who: "app", Why: "saving", Mode: "block", PID: 9},
+		{What: "sleep", Who: "app", Why: "saving", Mode: "delay", PID: 9},
+		{What: "sleep", Who: "app", Why: "saving", Mode: "delay", PID: 9},
+	}}
+
+	inhibitors := m.collect()
+	require.Len(t, inhibitors, 3)
+	assert.Equal(t, "logind:9:sleep:block:saving", inhibitors[0].ID)
+	assert.Equal(t, "logind:9:sleep:delay:saving", inhibitors[1].ID)
+	assert.Equal(t, "logind:9:sleep:delay:saving:1", inhibitors[2].ID)
+}
+
+func TestManager_RefreshAppliesRules(t *testing.T) {
+	m := newTestManager(t,
+		Rule{App: "fire*", Action: RuleRelease},
+		Rule{Exe: "mpv", Action: RuleIgnore},
+		Rule{Source: SourceLogind, App: "mpv", Action: RuleRelease},
+	)
+	logind, ss := testSources()
+	m.logind = logind
+	m.screensaver = ss
+
+	m.refresh()
+	state := m.GetState()
+
+	assert.Equal(t, []uint32{3}, ss.released)
+	require.Len(t, state.Inhibitors, 2)
+	assert.False(t, state.Inhibitors[0].Ignored)
+	assert.True(t, state.Inhibitors[1].Ignored)
+	assert.False(t, state.IdleBlocked)
+	assert.False(t, state.SleepBlocked)
+	assert.Empty(t, m.BlockingInhibitors("idle"))
+}
+
+func TestManager_BlockingInhibitors(t *testing.T) {
+	m := newTestManager(t)
+	logind, ss := testSources()
+	m.logind = logind
+	m.screensaver = ss
+	m.wayland = &fakeWayland{inhibited: true}
+
+	m.refresh()
+	state := m.GetState()
+
+	assert.True(t, state.IdleBlocked)
+	assert.True(t, state.SleepBlocked)
+	assert.Equal(t, []string{"mpv (playing)", "firefox (video-playing)"}, m.BlockingInhibitors("idle"))
+	assert.Equal(t, []string{"mpv (playing)"}, m.BlockingInhibitors("sleep"))
+}
+
+func TestManager_Release(t *testing.T) {
+	m := newTestManager(t)
+	_, ss := testSources()
+	m.screensaver = ss
+
+	assert.Error(t, m.Release("logind:77:idle:sleep:block:playing"))
+	assert.Error(t, m.Release("screensaver:9"))
+	assert.NoError(t, m.Release("screensaver:3"))
+	assert.Equal(t, []uint32{3}, ss.released)
+}
+
+func TestRule_Matches(t *testing.T) {
+	inh := Inhibitor{
+		Source:  SourceScreensaver,
+		App:     "Firefox",
+		Reason:  "Video Playing",
+		Process: &ProcessInfo{PID: 1, Exe: "/usr/lib/firefox/firefox"},
+	}
+
+	assert.True(t, Rule{App: "firefox"}.Matches(inh))
+	assert.True(t, Rule{Exe: "firefox"}.Matches(inh))
+	assert.True(t, Rule{Exe: "/usr/lib/*/firefox"}.Matches(inh))
+	assert.True(t, Rule{Reason: "video"}.Matches(inh))
+	assert.False(t, Rule{Source: SourceLogind, App: "firefox"}.Matches(inh))
+	assert.False(t, Rule{App: "chromium"}.Matches(inh))
+	assert.False(t, Rule{Exe: "firefox"}.Matches(Inhibitor{App: "firefox"}))
+}
+
+func TestValidateRules(t *testing.T) {
+	assert.NoError(t, ValidateRules([]Rule{{App: "firefox", Action: RuleIgnore}}))
+	assert.Error(t, ValidateRules([]Rule{{App: "firefox", Action: "kill"}}))
+	assert.Error(t, ValidateRules([]Rule{{Action: RuleIgnore}}))
+	assert.Error(t, ValidateRules([]Rule{{App: "[", Action: RuleIgnore}}))
+	assert.Error(t, ValidateRules([]Rule{{App: "x", Source: "dbus", Action: RuleIgnore}}))
+}
+
+func TestStateChanged(t *testing.T) {
+	assert.True(t, stateChanged(nil, &State{}))
+
+	a := &State{Inhibitors: []Inhibitor{{ID: "screensaver:1", What: []string{"idle"}, Process: &ProcessInfo{PID: 1}}}}
+	b := &State{Inhibitors: []Inhibitor{{ID: "screensaver:1", What: []string{"idle"}, Process: &ProcessInfo{PID: 1}}}}
+	assert.False(t, stateChanged(a, b))
+
+	b.Inhibitors[0].Ignored = true
+	assert.True(t, stateChanged(a, b))
+
+	c := &State{Inhibitors: a.Inhibitors, Rules: []Rule{{App: "x", Action: RuleIgnore}}}
+	assert.True(t, stateChanged(a, c))
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := parseRules(map[string]any{
+		"rules": []any{
+			map[string]any{"app": "firefox", "reason": "video", "action": "release"},
+			map[string]any{"source": "logind", "exe": "steam", "action": "ignore"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Rule{
+		{App: "firefox", Reason: "video", Action: RuleRelease},
+		{Source: SourceLogind, Exe: "steam", Action: RuleIgnore},
+	}, rules)
+
+	_, err = parseRules(map[string]any{"rules": []any{map[string]any{"app": "x"}}})
+	assert.Error(t, err)
+}
+
+func TestManager_ForgetsReleasedPeers(t *testing.T) {
+	m := newTestManager(t)
+	_, ss := testSources()
+	m.screensaver = ss
+
+	m.refresh()
+	_, ok := m.peerPIDs.Load(":1.42")
+	require.True(t, ok)
+
+	require.NoError(t, m.Release("screensaver:3"))
+	m.refresh()
+	_, ok = m.peerPIDs.Load(":1.42")
+	assert.False(t, ok)
+
+	ss.state.Inhibitors = append(ss.state.Inhibitors, freedesktop.ScreensaverInhibitor{Cookie: 4, AppName: "mpv", Peer: ":1.42"})
+	m.refresh()
+	assert.Equal(t, 2, ss.lookups)
+}
@@ -0,0 +1,224 @@
+package inhibitors
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/freedesktop"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/loginctl"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+type Source string
+
+const (
+	SourceLogind      Source = "logind"
+	SourceScreensaver Source = "screensaver"
+	SourceWayland     Source = "wayland"
+)
+
+type RuleAction string
+
+const (
+	RuleIgnore  RuleAction = "ignore"
+	RuleRelease RuleAction = "release"
+)
+
+type ProcessInfo struct {
+	PID     uint32 `json:"pid"`
+	Exe     string `json:"exe,omitempty"`
+	Comm    string `json:"comm,omitempty"`
+	Cmdline string `json:"cmdline,omitempty"`
+}
+
+type Inhibitor struct {
+	ID         string       `json:"id"`
+	Source     Source       `json:"source"`
+	What       []string     `json:"what"`
+	Mode       string       `json:"mode"`
+	App        string       `json:"app"`
+	Reason     string       `json:"reason"`
+	Since      int64        `json:"since,omitempty"`
+	Process    *ProcessInfo `json:"process,omitempty"`
+	Ignored    bool         `json:"ignored"`
+	Releasable bool         `json:"releasable"`
+}
+
+// Blocks reports whether the inhibitor holds off op ("idle", "sleep", ...).
+func (i Inhibitor) Blocks(op string) bool {
+	if i.Ignored || i.Mode != "block" {
+		return false
+	}
+	for _, w := range i.What {
+		if w == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule matches inhibitors by glob (app, exe) and substring (reason). Empty
+// fields match anything, but a rule needs at least one non-empty matcher.
+type Rule struct {
+	Source Source     `json:"source,omitempty"`
+	App    string     `json:"app,omitempty"`
+	Exe    string     `json:"exe,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+	Action RuleAction `json:"action"`
+}
+
+func (r Rule) Matches(inh Inhibitor) bool {
+	if r.Source != "" && r.Source != inh.Source {
+		return false
+	}
+	if r.App != "" && !globMatch(r.App, inh.App) {
+		return false
+	}
+	if r.Exe != "" {
+		if inh.Process == nil || inh.Process.Exe == "" {
+			return false
+		}
+		if !globMatch(r.Exe, inh.Process.Exe) && !globMatch(r.Exe, filepath.Base(inh.Process.Exe)) {
+			return false
+		}
+	}
+	if r.Reason != "" && !strings.Contains(strings.ToLower(inh.Reason), strings.ToLower(r.Reason)) {
+		return false
+	}
+	return true
+}
+
+func globMatch(pattern, value string) bool {
+	ok, err := filepath.Match(strings.ToLower(pattern), strings.ToLower(value))
+	return err == nil && ok
+}
+
+func ValidateRules(rules []Rule) error {
+	for i, r := range rules {
+		switch r.Action {
+		case RuleIgnore, RuleRelease:
+		default:
+			return fmt.Errorf("rule %d: unknown action: %s", i, r.Action)
+		}
+		switch r.Source {
+		case "", SourceLogind, SourceScreensaver, SourceWayland:
+		default:
+			return fmt.Errorf("rule %d: unknown source: %s", i, r.Source)
+		}
+		if r.App == "" && r.Exe == "" && r.Reason == "" {
+			return fmt.Errorf("rule %d: needs at least one of app, exe or reason", i)
+		}
+		for _, pattern := range []string{r.App, r.Exe} {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %d: invalid pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+type Config struct {
+	Rules []Rule `json:"rules"`
+}
+
+func getConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "inhibitors.json"), nil
+}
+
+func LoadConfig() Config {
+	cfg := Config{Rules: []Rule{}}
+
+	path, err := getConfigPath()
+	if err != nil {
+		return cfg
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{Rules: []Rule{}}
+	}
+
+	if err := ValidateRules(cfg.Rules); err != nil {
+		return Config{Rules: []Rule{}}
+	}
+	if cfg.Rules == nil {
+		cfg.Rules = []Rule{}
+	}
+	return cfg
+}
+
+func SaveConfig(cfg Config) error {
+	path, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+type State struct {
+	Inhibitors   []Inhibitor `json:"inhibitors"`
+	Rules        []Rule      `json:"rules"`
+	IdleBlocked  bool        `json:"idleBlocked"`
+	SleepBlocked bool        `json:"sleepBlocked"`
+}
+
+type LogindSource interface {
+	ListInhibitors() ([]loginctl.Inhibitor, error)
+}
+
+type ScreensaverSource interface {
+	GetScreensaverState() freedesktop.ScreensaverState
+	ReleaseScreensaverInhibitor(cookie uint32) bool
+	ScreensaverPeerPID(peer string) (uint32, error)
+}
+
+type WaylandSource interface {
+	WaylandInhibited() bool
+}
+
+type Manager struct {
+	configMutex sync.RWMutex
+	config      Config
+
+	backendMutex sync.RWMutex
+	logind       LogindSource
+	screensaver  ScreensaverSource
+	wayland      WaylandSource
+
+	procRoot string
+	peerPIDs syncmap.Map[string, uint32]
+
+	refreshChan chan struct{}
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+
+	stateMutex sync.RWMutex
+	state      *State
+
+	subscribers  syncmap.Map[string, chan State]
+	dirty        chan struct{}
+	notifierWg   sync.WaitGroup
+	lastNotified *State
+}
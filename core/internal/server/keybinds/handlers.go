@@ -0,0 +1,92 @@
+package keybinds
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/keybinds/providers"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request) {
+	switch req.Method {
+	case "keybinds.migrate":
+		handleMigrate(conn, req)
+	case "keybinds.check":
+		handleCheck(conn, req)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleMigrate(conn net.Conn, req models.Request) {
+	fromName, err := params.StringNonEmpty(req.Params, "from")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	toName, err := params.StringNonEmpty(req.Params, "to")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	if err := providers.CheckMigration(fromName, toName); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	registry := keybinds.GetDefaultRegistry()
+	from, err := registry.Get(fromName)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	target, err := registry.Get(toName)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	to, ok := target.(keybinds.WritableProvider)
+	if !ok {
+		models.RespondError(conn, req.ID, fmt.Sprintf("provider %s does not support writing keybinds", toName))
+		return
+	}
+
+	result, err := providers.Migrate(from, to, params.BoolOpt(req.Params, "dryRun", false))
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
+
+func handleCheck(conn net.Conn, req models.Request) {
+	providerName, err := params.StringNonEmpty(req.Params, "provider")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	provider, err := keybinds.GetDefaultRegistry().Get(providerName)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	analyzer := keybinds.NewConflictAnalyzer(provider, keybinds.ApplicationProviders(nil))
+
+	var report *keybinds.ConflictReport
+	switch key := params.StringOpt(req.Params, "key", ""); key {
+	case "":
+		report, err = analyzer.Analyze()
+	default:
+		report, err = analyzer.CheckBind(key, params.StringOpt(req.Params, "action", ""))
+	}
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, report)
+}
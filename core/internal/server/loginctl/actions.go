@@ -2,6 +2,7 @@ package loginctl
 
 import (
 	"fmt"
+	"strings"
 )
 
 func (m *Manager) Lock() error {
@@ -72,6 +73,62 @@ func (m *Manager) Terminate() error {
 	return nil
 }
 
+func (m *Manager) Suspend() error {
+	if m.managerObj == nil {
+		return fmt.Errorf("manager object not available")
+	}
+	if err := m.managerObj.Call(dbusManagerInterface+".Suspend", 0, false).Err; err != nil {
+		return fmt.Errorf("failed to suspend: %w", err)
+	}
+	return nil
+}
+
+// PowerAction invokes a logind power method such as "Reboot" or "PowerOff".
+func (m *Manager) PowerAction(method string) error {
+	if m.managerObj == nil {
+		return fmt.Errorf("manager object not available")
+	}
+	if err := m.managerObj.Call(dbusManagerInterface+"."+method, 0, false).Err; err != nil {
+		return fmt.Errorf("failed to %s: %w", strings.ToLower(method), err)
+	}
+	return nil
+}
+
+// CanPowerAction returns logind's answer to Can<method>: "yes", "no",
+// "challenge" or "na".
+func (m *Manager) CanPowerAction(method string) (string, error) {
+	if m.managerObj == nil {
+		return "", fmt.Errorf("manager object not available")
+	}
+	var result string
+	if err := m.managerObj.Call(dbusManagerInterface+".Can"+method, 0).Store(&result); err != nil {
+		return "", fmt.Errorf("failed to query Can%s: %w", method, err)
+	}
+	return result, nil
+}
+
+func (m *Manager) SetRebootToFirmwareSetup(enabled bool) error {
+	if m.managerObj == nil {
+		return fmt.Errorf("manager object not available")
+	}
+	if err := m.managerObj.Call(dbusManagerInterface+".SetRebootToFirmwareSetup", 0, enabled).Err; err != nil {
+		return fmt.Errorf("failed to set reboot to firmware setup: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) ListInhibitors() ([]Inhibitor, error) {
+	if m.managerObj == nil {
+		return nil, fmt.Errorf("manager object not available")
+	}
+
+	var inhibitors []Inhibitor
+	if err := m.managerObj.Call(dbusManagerInterface+".ListInhibitors", 0).Store(&inhibitors); err != nil {
+		return nil, fmt.Errorf("failed to list inhibitors: %w", err)
+	}
+	return inhibitors, nil
+}
+
 func (m *Manager) SetLockBeforeSuspend(enabled bool) {
 	m.lockBeforeSuspend.Store(enabled)
 }
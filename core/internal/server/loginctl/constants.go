@@ -5,5 +5,6 @@ const (
 	dbusPath             = "/org/freedesktop/login1"
 	dbusManagerInterface = "org.freedesktop.login1.Manager"
 	dbusSessionInterface = "org.freedesktop.login1.Session"
+	dbusSeatInterface    = "org.freedesktop.login1.Seat"
 	dbusPropsInterface   = "org.freedesktop.DBus.Properties"
 )
@@ -29,6 +29,12 @@ func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
 		handleLockerReady(conn, req, manager)
 	case "loginctl.terminate":
 		handleTerminate(conn, req, manager)
+	case "loginctl.listSessions":
+		handleListSessions(conn, req, manager)
+	case "loginctl.listUsers":
+		handleListUsers(conn, req, manager)
+	case "loginctl.switchTo":
+		handleSwitchTo(conn, req, manager)
 	case "loginctl.subscribe":
 		handleSubscribe(conn, req, manager)
 	default:
@@ -125,6 +131,51 @@ func handleTerminate(conn net.Conn, req models.Request, manager *Manager) {
 	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "terminated"})
 }
 
+func handleListSessions(conn net.Conn, req models.Request, manager *Manager) {
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, sessions)
+}
+
+func handleListUsers(conn net.Conn, req models.Request, manager *Manager) {
+	users, err := manager.ListUsers()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, users)
+}
+
+func handleSwitchTo(conn net.Conn, req models.Request, manager *Manager) {
+	if sessionID := params.StringOpt(req.Params, "session", ""); sessionID != "" {
+		if err := manager.SwitchToSession(sessionID); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "switched to session " + sessionID})
+		return
+	}
+
+	vt, err := params.Int(req.Params, "vt")
+	if err != nil {
+		models.RespondError(conn, req.ID, "missing or invalid 'session' or 'vt' parameter")
+		return
+	}
+	if vt <= 0 {
+		models.RespondError(conn, req.ID, "vt must be positive")
+		return
+	}
+
+	if err := manager.SwitchToVT(uint32(vt)); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: fmt.Sprintf("switched to VT %d", vt)})
+}
+
 func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
 	clientID := fmt.Sprintf("client-%p", conn)
 	stateChan := manager.Subscribe(clientID)
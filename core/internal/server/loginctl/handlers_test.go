@@ -498,3 +498,29 @@ func TestHandleSubscribe(t *testing.T) {
 	case <-time.After(100 * time.Millisecond):
 	}
 }
+
+func TestPowerAction(t *testing.T) {
+	mockManagerObj := mockdbus.NewMockBusObject(t)
+	mockManagerObj.EXPECT().Call("org.freedesktop.login1.Manager.Reboot", dbus.Flags(0), false).Return(&dbus.Call{})
+	mockManagerObj.EXPECT().Call("org.freedesktop.login1.Manager.PowerOff", dbus.Flags(0), false).Return(&dbus.Call{Err: assert.AnError})
+
+	manager := &Manager{managerObj: mockManagerObj}
+	assert.NoError(t, manager.PowerAction("Reboot"))
+
+	err := manager.PowerAction("PowerOff")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to poweroff")
+}
+
+func TestCanPowerAction(t *testing.T) {
+	mockManagerObj := mockdbus.NewMockBusObject(t)
+	mockManagerObj.EXPECT().Call("org.freedesktop.login1.Manager.CanHibernate", dbus.Flags(0)).Return(&dbus.Call{Body: []any{"challenge"}})
+
+	manager := &Manager{managerObj: mockManagerObj}
+	result, err := manager.CanPowerAction("Hibernate")
+	require.NoError(t, err)
+	assert.Equal(t, "challenge", result)
+
+	_, err = (&Manager{}).CanPowerAction("Hibernate")
+	assert.Error(t, err)
+}
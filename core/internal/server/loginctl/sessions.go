@@ -0,0 +1,186 @@
+package loginctl
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/dbusutil"
+	"github.com/godbus/dbus/v5"
+)
+
+type SessionInfo struct {
+	SessionID  string `json:"sessionId"`
+	User       uint32 `json:"user"`
+	UserName   string `json:"userName"`
+	Seat       string `json:"seat"`
+	State      string `json:"state"`
+	Active     bool   `json:"active"`
+	LockedHint bool   `json:"lockedHint"`
+	Type       string `json:"type"`
+	Class      string `json:"class"`
+	Remote     bool   `json:"remote"`
+	RemoteHost string `json:"remoteHost"`
+	TTY        string `json:"tty"`
+	Display    string `json:"display"`
+	VTNr       uint32 `json:"vtnr"`
+	Current    bool   `json:"current"`
+}
+
+type UserInfo struct {
+	UID      uint32   `json:"uid"`
+	Name     string   `json:"name"`
+	Current  bool     `json:"current"`
+	Sessions []string `json:"sessions"`
+}
+
+type listedSession struct {
+	ID   string
+	UID  uint32
+	User string
+	Seat string
+	Path dbus.ObjectPath
+}
+
+type listedUser struct {
+	UID  uint32
+	Name string
+	Path dbus.ObjectPath
+}
+
+// ListSessions returns every session that belongs to the current user or
+// sits on the current seat, so the UI can offer switching between them.
+func (m *Manager) ListSessions() ([]SessionInfo, error) {
+	if m.managerObj == nil || m.conn == nil {
+		return nil, fmt.Errorf("manager object not available")
+	}
+
+	var listed []listedSession
+	if err := m.managerObj.Call(dbusManagerInterface+".ListSessions", 0).Store(&listed); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	current := m.snapshotState()
+
+	sessions := make([]SessionInfo, 0, len(listed))
+	for _, s := range listed {
+		if s.UID != current.User && (current.Seat == "" || s.Seat != current.Seat) {
+			continue
+		}
+
+		info := SessionInfo{
+			SessionID: s.ID,
+			User:      s.UID,
+			UserName:  s.User,
+			Seat:      s.Seat,
+			Current:   s.Path == dbus.ObjectPath(current.SessionPath),
+		}
+
+		var props map[string]dbus.Variant
+		err := m.conn.Object(dbusDest, s.Path).Call(dbusPropsInterface+".GetAll", 0, dbusSessionInterface).Store(&props)
+		if err == nil {
+			info.State = dbusutil.GetOr(props, "State", "")
+			info.Active = dbusutil.GetOr(props, "Active", false)
+			info.LockedHint = dbusutil.GetOr(props, "LockedHint", false)
+			info.Type = dbusutil.GetOr(props, "Type", "")
+			info.Class = dbusutil.GetOr(props, "Class", "")
+			info.Remote = dbusutil.GetOr(props, "Remote", false)
+			info.RemoteHost = dbusutil.GetOr(props, "RemoteHost", "")
+			info.TTY = dbusutil.GetOr(props, "TTY", "")
+			info.Display = dbusutil.GetOr(props, "Display", "")
+			info.VTNr = dbusutil.GetOr(props, "VTNr", uint32(0))
+		}
+
+		sessions = append(sessions, info)
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if sessions[i].VTNr != sessions[j].VTNr {
+			return sessions[i].VTNr < sessions[j].VTNr
+		}
+		return sessions[i].SessionID < sessions[j].SessionID
+	})
+	return sessions, nil
+}
+
+// ListUsers returns logged-in users with their session IDs.
+func (m *Manager) ListUsers() ([]UserInfo, error) {
+	if m.managerObj == nil {
+		return nil, fmt.Errorf("manager object not available")
+	}
+
+	var listedUsers []listedUser
+	if err := m.managerObj.Call(dbusManagerInterface+".ListUsers", 0).Store(&listedUsers); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	var listedSessions []listedSession
+	if err := m.managerObj.Call(dbusManagerInterface+".ListSessions", 0).Store(&listedSessions); err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	byUID := make(map[uint32][]string)
+	for _, s := range listedSessions {
+		byUID[s.UID] = append(byUID[s.UID], s.ID)
+	}
+
+	current := m.snapshotState()
+
+	users := make([]UserInfo, 0, len(listedUsers))
+	for _, u := range listedUsers {
+		sessions := byUID[u.UID]
+		if sessions == nil {
+			sessions = []string{}
+		}
+		sort.Strings(sessions)
+		users = append(users, UserInfo{
+			UID:      u.UID,
+			Name:     u.Name,
+			Current:  u.UID == current.User,
+			Sessions: sessions,
+		})
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].UID < users[j].UID })
+	return users, nil
+}
+
+// SwitchToSession activates another session on the current seat.
+func (m *Manager) SwitchToSession(sessionID string) error {
+	seat, err := m.currentSeat()
+	if err != nil {
+		return err
+	}
+	if err := m.managerObj.Call(dbusManagerInterface+".ActivateSessionOnSeat", 0, sessionID, seat).Err; err != nil {
+		return fmt.Errorf("failed to switch to session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// SwitchToVT switches the current seat to virtual terminal vt.
+func (m *Manager) SwitchToVT(vt uint32) error {
+	seat, err := m.currentSeat()
+	if err != nil {
+		return err
+	}
+
+	var seatPath dbus.ObjectPath
+	if err := m.managerObj.Call(dbusManagerInterface+".GetSeat", 0, seat).Store(&seatPath); err != nil {
+		return fmt.Errorf("failed to get seat %s: %w", seat, err)
+	}
+
+	if err := m.conn.Object(dbusDest, seatPath).Call(dbusSeatInterface+".SwitchTo", 0, vt).Err; err != nil {
+		return fmt.Errorf("failed to switch to VT %d: %w", vt, err)
+	}
+	return nil
+}
+
+func (m *Manager) currentSeat() (string, error) {
+	if m.managerObj == nil || m.conn == nil {
+		return "", fmt.Errorf("manager object not available")
+	}
+	seat := m.snapshotState().Seat
+	if seat == "" {
+		return "", fmt.Errorf("session is not attached to a seat")
+	}
+	return seat, nil
+}
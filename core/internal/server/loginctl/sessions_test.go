@@ -0,0 +1,216 @@
+package loginctl
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startPrivateBus launches a throwaway dbus-daemon and returns its address.
+func startPrivateBus(t *testing.T) string {
+	t.Helper()
+
+	path, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not available")
+	}
+
+	cmd := exec.Command(path, "--session", "--nofork", "--print-address=1")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	addr, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	return strings.TrimSpace(addr)
+}
+
+type fakeLogindManager struct {
+	mu        sync.Mutex
+	sessions  []listedSession
+	users     []listedUser
+	activated []string
+}
+
+func (f *fakeLogindManager) ListSessions() ([]listedSession, *dbus.Error) {
+	return f.sessions, nil
+}
+
+func (f *fakeLogindManager) ListUsers() ([]listedUser, *dbus.Error) {
+	return f.users, nil
+}
+
+func (f *fakeLogindManager) ActivateSessionOnSeat(id, seat string) *dbus.Error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.activated = append(f.activated, id+"@"+seat)
+	return nil
+}
+
+func (f *fakeLogindManager) GetSeat(id string) (dbus.ObjectPath, *dbus.Error) {
+	if id != "seat0" {
+		return "", dbus.MakeFailedError(assert.AnError)
+	}
+	return "/org/freedesktop/login1/seat/seat0", nil
+}
+
+type fakeSeat struct {
+	mu       sync.Mutex
+	switched []uint32
+}
+
+func (f *fakeSeat) SwitchTo(vt uint32) *dbus.Error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.switched = append(f.switched, vt)
+	return nil
+}
+
+type fakeSessionProps map[string]dbus.Variant
+
+func (f fakeSessionProps) GetAll(iface string) (map[string]dbus.Variant, *dbus.Error) {
+	return f, nil
+}
+
+type fakeLogindBus struct {
+	manager *fakeLogindManager
+	seat    *fakeSeat
+	client  *Manager
+}
+
+func setupFakeLogind(t *testing.T) *fakeLogindBus {
+	addr := startPrivateBus(t)
+
+	server, err := dbus.Connect(addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+
+	reply, err := server.RequestName(dbusDest, dbus.NameFlagDoNotQueue)
+	require.NoError(t, err)
+	require.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+
+	fake := &fakeLogindBus{
+		manager: &fakeLogindManager{
+			sessions: []listedSession{
+				{ID: "2", UID: 1000, User: "alice", Seat: "seat0", Path: "/org/freedesktop/login1/session/_32"},
+				{ID: "5", UID: 1001, User: "bob", Seat: "seat0", Path: "/org/freedesktop/login1/session/_35"},
+				{ID: "7", UID: 1002, User: "carol", Seat: "seat1", Path: "/org/freedesktop/login1/session/_37"},
+				{ID: "9", UID: 1000, User: "alice", Seat: "", Path: "/org/freedesktop/login1/session/_39"},
+			},
+			users: []listedUser{
+				{UID: 1002, Name: "carol", Path: "/org/freedesktop/login1/user/_1002"},
+				{UID: 1000, Name: "alice", Path: "/org/freedesktop/login1/user/_1000"},
+				{UID: 1001, Name: "bob", Path: "/org/freedesktop/login1/user/_1001"},
+			},
+		},
+		seat: &fakeSeat{},
+	}
+
+	require.NoError(t, server.Export(fake.manager, dbusPath, dbusManagerInterface))
+	require.NoError(t, server.Export(fake.seat, "/org/freedesktop/login1/seat/seat0", dbusSeatInterface))
+
+	props := map[dbus.ObjectPath]fakeSessionProps{
+		"/org/freedesktop/login1/session/_32": {
+			"State": dbus.MakeVariant("active"), "Active": dbus.MakeVariant(true), "Type": dbus.MakeVariant("wayland"),
+			"Class": dbus.MakeVariant("user"), "VTNr": dbus.MakeVariant(uint32(2)), "TTY": dbus.MakeVariant("tty2"),
+		},
+		"/org/freedesktop/login1/session/_35": {
+			"State": dbus.MakeVariant("online"), "LockedHint": dbus.MakeVariant(true), "Type": dbus.MakeVariant("wayland"),
+			"Class": dbus.MakeVariant("user"), "VTNr": dbus.MakeVariant(uint32(3)),
+		},
+		"/org/freedesktop/login1/session/_39": {
+			"State": dbus.MakeVariant("active"), "Remote": dbus.MakeVariant(true), "RemoteHost": dbus.MakeVariant("10.0.0.5"),
+			"Type": dbus.MakeVariant("tty"), "Class": dbus.MakeVariant("user"),
+		},
+	}
+	for path, p := range props {
+		require.NoError(t, server.Export(p, path, dbusPropsInterface))
+	}
+
+	client, err := dbus.Connect(addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	fake.client = &Manager{
+		conn:       client,
+		managerObj: client.Object(dbusDest, dbusPath),
+		state: &SessionState{
+			SessionID:   "2",
+			SessionPath: "/org/freedesktop/login1/session/_32",
+			User:        1000,
+			Seat:        "seat0",
+		},
+	}
+	return fake
+}
+
+func TestManager_ListSessions(t *testing.T) {
+	fake := setupFakeLogind(t)
+
+	sessions, err := fake.client.ListSessions()
+	require.NoError(t, err)
+	require.Len(t, sessions, 3)
+
+	remote := sessions[0]
+	assert.Equal(t, "9", remote.SessionID)
+	assert.True(t, remote.Remote)
+	assert.Equal(t, "10.0.0.5", remote.RemoteHost)
+	assert.False(t, remote.Current)
+
+	own := sessions[1]
+	assert.Equal(t, "2", own.SessionID)
+	assert.True(t, own.Current)
+	assert.True(t, own.Active)
+	assert.Equal(t, "active", own.State)
+	assert.Equal(t, uint32(2), own.VTNr)
+	assert.Equal(t, "tty2", own.TTY)
+
+	other := sessions[2]
+	assert.Equal(t, "5", other.SessionID)
+	assert.Equal(t, "bob", other.UserName)
+	assert.True(t, other.LockedHint)
+	assert.Equal(t, "online", other.State)
+}
+
+func TestManager_ListUsers(t *testing.T) {
+	fake := setupFakeLogind(t)
+
+	users, err := fake.client.ListUsers()
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+
+	assert.Equal(t, "alice", users[0].Name)
+	assert.True(t, users[0].Current)
+	assert.Equal(t, []string{"2", "9"}, users[0].Sessions)
+	assert.Equal(t, "bob", users[1].Name)
+	assert.False(t, users[1].Current)
+	assert.Equal(t, []string{"7"}, users[2].Sessions)
+}
+
+func TestManager_SwitchTo(t *testing.T) {
+	fake := setupFakeLogind(t)
+
+	require.NoError(t, fake.client.SwitchToSession("5"))
+	fake.manager.mu.Lock()
+	assert.Equal(t, []string{"5@seat0"}, fake.manager.activated)
+	fake.manager.mu.Unlock()
+
+	require.NoError(t, fake.client.SwitchToVT(3))
+	fake.seat.mu.Lock()
+	assert.Equal(t, []uint32{3}, fake.seat.switched)
+	fake.seat.mu.Unlock()
+
+	fake.client.state.Seat = ""
+	assert.Error(t, fake.client.SwitchToSession("5"))
+	assert.Error(t, fake.client.SwitchToVT(3))
+}
@@ -2,6 +2,7 @@ package loginctl
 
 import (
 	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -32,6 +33,28 @@ type SessionState struct {
 	PreparingForSleep bool   `json:"preparingForSleep"`
 }
 
+type Inhibitor struct {
+	What string `json:"what"`
+	Who  string `json:"who"`
+	Why  string `json:"why"`
+	Mode string `json:"mode"`
+	UID  uint32 `json:"uid"`
+	PID  uint32 `json:"pid"`
+}
+
+// Blocks reports whether the inhibitor blocks op ("idle", "sleep", ...).
+func (i Inhibitor) Blocks(op string) bool {
+	if i.Mode != "block" {
+		return false
+	}
+	for _, w := range strings.Split(i.What, ":") {
+		if w == op {
+			return true
+		}
+	}
+	return false
+}
+
 type EventType string
 
 const (
@@ -48,3 +48,13 @@ func TestSessionEvent_Struct(t *testing.T) {
 	assert.Equal(t, "1", event.Data.SessionID)
 	assert.True(t, event.Data.Locked)
 }
+
+func TestInhibitor_Blocks(t *testing.T) {
+	inh := Inhibitor{What: "sleep:idle", Who: "firefox", Mode: "block"}
+	assert.True(t, inh.Blocks("idle"))
+	assert.True(t, inh.Blocks("sleep"))
+	assert.False(t, inh.Blocks("shutdown"))
+
+	inh.Mode = "delay"
+	assert.False(t, inh.Blocks("idle"))
+}
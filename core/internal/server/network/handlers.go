@@ -161,6 +161,7 @@ func handleConnectWiFi(conn net.Conn, req models.Request, manager *Manager) {
 	connReq.Password = params.StringOpt(req.Params, "password", "")
 	connReq.Username = params.StringOpt(req.Params, "username", "")
 	connReq.Device = params.StringOpt(req.Params, "device", "")
+	connReq.Hidden = params.BoolOpt(req.Params, "hidden", false)
 
 	if interactive, ok := models.Get[bool](req, "interactive"); ok {
 		connReq.Interactive = interactive
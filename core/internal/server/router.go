@@ -9,20 +9,30 @@ import (
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/bluez"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/brightness"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/clipboard"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/colorhistory"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/cups"
 	serverDbus "github.com/AvengeMedia/DankMaterialShell/core/internal/server/dbus"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/dwl"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/evdev"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/extworkspace"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/freedesktop"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/idle"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/inhibitors"
+	serverKeybinds "github.com/AvengeMedia/DankMaterialShell/core/internal/server/keybinds"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/loginctl"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/network"
 	serverPlugins "github.com/AvengeMedia/DankMaterialShell/core/internal/server/plugins"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/screenhistory"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/screenrecord"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/session"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/thememode"
 	serverThemes "github.com/AvengeMedia/DankMaterialShell/core/internal/server/themes"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/toplevel"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wayland"
+	serverWindowrules "github.com/AvengeMedia/DankMaterialShell/core/internal/server/windowrules"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wlroutput"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/workspacemeta"
 )
 
 func RouteRequest(conn net.Conn, req models.Request) {
@@ -40,6 +50,16 @@ func RouteRequest(conn net.Conn, req models.Request) {
 		return
 	}
 
+	if strings.HasPrefix(req.Method, "keybinds.") {
+		serverKeybinds.HandleRequest(conn, req)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "windowrules.") {
+		serverWindowrules.HandleRequest(conn, req, toplevelManager)
+		return
+	}
+
 	if strings.HasPrefix(req.Method, "themes.") {
 		serverThemes.HandleRequest(conn, req)
 		return
@@ -165,6 +185,51 @@ func RouteRequest(conn net.Conn, req models.Request) {
 		return
 	}
 
+	if strings.HasPrefix(req.Method, "idle.") {
+		if idleManager == nil {
+			models.RespondError(conn, req.ID, "idle manager not initialized")
+			return
+		}
+		idle.HandleRequest(conn, req, idleManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "toplevels.") {
+		if toplevelManager == nil {
+			models.RespondError(conn, req.ID, "toplevel manager not initialized")
+			return
+		}
+		toplevel.HandleRequest(conn, req, toplevelManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "inhibitors.") {
+		if inhibitorsManager == nil {
+			models.RespondError(conn, req.ID, "inhibitors manager not initialized")
+			return
+		}
+		inhibitors.HandleRequest(conn, req, inhibitorsManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "session.") {
+		if sessionManager == nil {
+			models.RespondError(conn, req.ID, "session manager not initialized")
+			return
+		}
+		session.HandleRequest(conn, req, sessionManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "workspacemeta.") {
+		if workspaceMetaManager == nil {
+			models.RespondError(conn, req.ID, "workspacemeta manager not initialized")
+			return
+		}
+		workspacemeta.HandleRequest(conn, req, workspaceMetaManager)
+		return
+	}
+
 	if strings.HasPrefix(req.Method, "dbus.") {
 		if dbusManager == nil {
 			models.RespondError(conn, req.ID, "dbus manager not initialized")
@@ -174,6 +239,21 @@ func RouteRequest(conn net.Conn, req models.Request) {
 		return
 	}
 
+	if strings.HasPrefix(req.Method, "screenshot.record.") {
+		screenrecord.HandleRequest(conn, req, screenRecordManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "screenshot.history.") {
+		screenhistory.HandleRequest(conn, req, screenHistoryManager)
+		return
+	}
+
+	if strings.HasPrefix(req.Method, "color.history.") || req.Method == "color.contrast" {
+		colorhistory.HandleRequest(conn, req, colorHistoryManager)
+		return
+	}
+
 	if strings.HasPrefix(req.Method, "clipboard.") {
 		switch req.Method {
 		case "clipboard.getConfig":
@@ -0,0 +1,122 @@
+package screenhistory
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "screenshot history manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "screenshot.history.list":
+		models.Respond(conn, req.ID, manager.List(params.IntOpt(req.Params, "limit", 0)))
+	case "screenshot.history.get":
+		handleGet(conn, req, manager)
+	case "screenshot.history.copy":
+		handleCopy(conn, req, manager)
+	case "screenshot.history.open":
+		handleOpen(conn, req, manager)
+	case "screenshot.history.delete":
+		handleDelete(conn, req, manager)
+	case "screenshot.history.prune":
+		handlePrune(conn, req, manager)
+	case "screenshot.history.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleGet(conn net.Conn, req models.Request, manager *Manager) {
+	entry, err := manager.Get(params.StringOpt(req.Params, "id", ""))
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, entry)
+}
+
+func handleCopy(conn net.Conn, req models.Request, manager *Manager) {
+	entry, err := manager.Copy(params.StringOpt(req.Params, "id", ""))
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "copied to clipboard", Value: entry.ID})
+}
+
+func handleOpen(conn net.Conn, req models.Request, manager *Manager) {
+	entry, err := manager.Get(params.StringOpt(req.Params, "id", ""))
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	if entry.Missing {
+		models.RespondError(conn, req.ID, fmt.Sprintf("%s no longer exists", entry.Path))
+		return
+	}
+	screenshot.OpenFile(entry.Path)
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "opened", Value: entry.Path})
+}
+
+func handleDelete(conn net.Conn, req models.Request, manager *Manager) {
+	id, err := params.StringNonEmpty(req.Params, "id")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	entry, err := manager.Delete(id, params.BoolOpt(req.Params, "deleteFile", false))
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "deleted", Value: entry.ID})
+}
+
+func handlePrune(conn net.Conn, req models.Request, manager *Manager) {
+	opts := screenshot.PruneOptions{
+		MaxAge:      time.Duration(params.FloatOpt(req.Params, "maxAge", 0) * float64(time.Second)),
+		MaxSize:     int64(params.FloatOpt(req.Params, "maxSize", 0)),
+		DeleteFiles: params.BoolOpt(req.Params, "deleteFiles", false),
+	}
+
+	removed, err := manager.Prune(opts)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: fmt.Sprintf("pruned %d captures", len(removed))})
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package screenhistory
+
+import (
+	"os"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+)
+
+// captures are added by short-lived dms processes; a burst of index writes
+// is published as one update
+const reloadDelay = 100 * time.Millisecond
+
+func NewManager(history *screenshot.History, copyFn CopyFunc) *Manager {
+	m := &Manager{
+		history:  history,
+		copy:     copyFn,
+		stopChan: make(chan struct{}),
+	}
+	m.reload()
+
+	if watcher := m.newWatcher(); watcher != nil {
+		m.wg.Add(1)
+		go m.watch(watcher)
+	}
+	return m
+}
+
+func (m *Manager) newWatcher() *fsnotify.Watcher {
+	if err := os.MkdirAll(m.history.Dir(), 0o700); err != nil {
+		log.Warnf("Screenshot history: failed to create %s: %v", m.history.Dir(), err)
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Warnf("Screenshot history: failed to create watcher: %v", err)
+		return nil
+	}
+
+	if err := watcher.Add(m.history.Dir()); err != nil {
+		log.Warnf("Screenshot history: failed to watch %s: %v", m.history.Dir(), err)
+		watcher.Close()
+		return nil
+	}
+	return watcher
+}
+
+func (m *Manager) watch(watcher *fsnotify.Watcher) {
+	defer m.wg.Done()
+	defer watcher.Close()
+
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Name != m.history.IndexPath() {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Remove) == 0 {
+				continue
+			}
+			if pending == nil {
+				pending = time.After(reloadDelay)
+			}
+		case <-pending:
+			pending = nil
+			m.reload()
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("Screenshot history watcher error: %v", err)
+		}
+	}
+}
+
+func (m *Manager) reload() {
+	entries, err := m.history.List()
+	if err != nil {
+		log.Warnf("Screenshot history: %v", err)
+		return
+	}
+	if entries == nil {
+		entries = []screenshot.HistoryEntry{}
+	}
+
+	m.stateMutex.Lock()
+	m.state = State{Entries: entries}
+	state := m.state
+	m.stateMutex.Unlock()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		select {
+		case ch <- state:
+		default:
+			log.Warn("Screenshot history: subscriber channel full, dropping update")
+		}
+		return true
+	})
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	return m.state
+}
+
+func (m *Manager) List(limit int) []screenshot.HistoryEntry {
+	entries := m.GetState().Entries
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+func (m *Manager) Get(ref string) (screenshot.HistoryEntry, error) {
+	return m.history.Get(ref)
+}
+
+// Copy puts a capture on the clipboard again.
+func (m *Manager) Copy(ref string) (screenshot.HistoryEntry, error) {
+	entry, data, mimeType, err := m.history.ReadImage(ref)
+	if err != nil {
+		return entry, err
+	}
+	if err := m.copy(data, mimeType); err != nil {
+		return entry, err
+	}
+	if err := m.history.MarkCopied(entry.ID); err != nil {
+		return entry, err
+	}
+	m.reload()
+	return entry, nil
+}
+
+func (m *Manager) Delete(ref string, deleteFile bool) (screenshot.HistoryEntry, error) {
+	entry, err := m.history.Delete(ref, deleteFile)
+	if err != nil {
+		return entry, err
+	}
+	m.reload()
+	return entry, nil
+}
+
+func (m *Manager) Prune(opts screenshot.PruneOptions) ([]screenshot.HistoryEntry, error) {
+	removed, err := m.history.Prune(opts)
+	if err != nil {
+		return nil, err
+	}
+	m.reload()
+	return removed, nil
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if ch, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(ch)
+	}
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.wg.Wait()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,61 @@
+package screenhistory
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+)
+
+func TestManagerPicksUpExternalCaptures(t *testing.T) {
+	history := screenshot.NewHistory(t.TempDir())
+	m := NewManager(history, func([]byte, string) error { return nil })
+	defer m.Close()
+
+	assert.Empty(t, m.GetState().Entries)
+	updates := m.Subscribe("test")
+
+	// a separate dms process writes the index
+	other := screenshot.NewHistory(history.Dir())
+	entry, err := other.Add(screenshot.HistoryEntry{Mode: "region"}, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+	require.NoError(t, err)
+
+	select {
+	case state := <-updates:
+		require.Len(t, state.Entries, 1)
+		assert.Equal(t, entry.ID, state.Entries[0].ID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no update after the index changed")
+	}
+	assert.Len(t, m.List(0), 1)
+}
+
+func TestManagerCopy(t *testing.T) {
+	history := screenshot.NewHistory(t.TempDir())
+	entry, err := history.Add(screenshot.HistoryEntry{Mode: "full"}, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+	require.NoError(t, err)
+
+	var copiedMime string
+	var copied []byte
+	m := NewManager(history, func(data []byte, mimeType string) error {
+		copied, copiedMime = data, mimeType
+		return nil
+	})
+	defer m.Close()
+
+	_, err = m.Copy("")
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", copiedMime)
+	assert.NotEmpty(t, copied)
+	assert.True(t, m.GetState().Entries[0].Copied)
+
+	_, err = m.Delete(entry.ID, false)
+	require.NoError(t, err)
+	assert.Empty(t, m.GetState().Entries)
+	_, err = m.Copy("")
+	assert.Error(t, err)
+}
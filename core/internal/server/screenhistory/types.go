@@ -0,0 +1,28 @@
+package screenhistory
+
+import (
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+type State struct {
+	Entries []screenshot.HistoryEntry `json:"entries"`
+}
+
+// CopyFunc puts data on the clipboard, normally through the clipboard
+// manager when it is running.
+type CopyFunc func(data []byte, mimeType string) error
+
+type Manager struct {
+	history *screenshot.History
+	copy    CopyFunc
+
+	stateMutex sync.RWMutex
+	state      State
+
+	subscribers syncmap.Map[string, chan State]
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+}
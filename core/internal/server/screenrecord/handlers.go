@@ -0,0 +1,133 @@
+package screenrecord
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "screen recording manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "screenshot.record.start":
+		handleStart(conn, req, manager)
+	case "screenshot.record.stop":
+		handleStop(conn, req, manager)
+	case "screenshot.record.pause":
+		handleControl(conn, req, manager.Pause, "recording paused")
+	case "screenshot.record.resume":
+		handleControl(conn, req, manager.Resume, "recording resumed")
+	case "screenshot.record.toggle":
+		handleControl(conn, req, manager.Toggle, "recording toggled")
+	case "screenshot.record.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "screenshot.record.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func parseConfig(p map[string]any) (screenshot.RecordConfig, error) {
+	cfg := screenshot.DefaultRecordConfig()
+
+	mode, err := screenshot.ParseMode(params.StringOpt(p, "mode", "region"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Mode = mode
+	cfg.OutputName = params.StringOpt(p, "output", "")
+	if cfg.Mode == screenshot.ModeOutput && cfg.OutputName == "" {
+		return cfg, fmt.Errorf("output mode requires an output name")
+	}
+
+	format, err := screenshot.ParseRecordFormat(params.StringOpt(p, "format", "gif"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Format = format
+	cfg.Encoder = params.StringOpt(p, "encoder", "")
+	if cfg.Format == screenshot.RecordPipe && cfg.Encoder == "" {
+		return cfg, fmt.Errorf("pipe format requires an encoder command")
+	}
+
+	cfg.FPS = params.IntOpt(p, "fps", screenshot.DefaultRecordFPS)
+	if cfg.FPS < 1 || cfg.FPS > screenshot.MaxRecordFPS {
+		return cfg, fmt.Errorf("fps must be between 1 and %d", screenshot.MaxRecordFPS)
+	}
+	if params.BoolOpt(p, "cursor", false) {
+		cfg.Cursor = screenshot.CursorOn
+	}
+	cfg.OutputDir = params.StringOpt(p, "dir", "")
+	cfg.Filename = params.StringOpt(p, "filename", "")
+	cfg.MaxDuration = time.Duration(params.FloatOpt(p, "maxDuration", 0) * float64(time.Second))
+
+	return cfg, nil
+}
+
+func handleStart(conn net.Conn, req models.Request, manager *Manager) {
+	cfg, err := parseConfig(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.Start(cfg); err != nil {
+		if errors.Is(err, screenshot.ErrRecordCancelled) {
+			models.Respond(conn, req.ID, models.SuccessResult{Success: false, Message: "cancelled"})
+			return
+		}
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleStop(conn net.Conn, req models.Request, manager *Manager) {
+	state, err := manager.Stop()
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, state)
+}
+
+func handleControl(conn net.Conn, req models.Request, action func() error, message string) {
+	if err := action(); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: message})
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package screenrecord
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+)
+
+// finishing a recording only flushes the encoder, but an external encoder
+// may take a while to exit
+const stopTimeout = 30 * time.Second
+
+func NewManager() *Manager {
+	return &Manager{
+		newRecording: func(cfg screenshot.RecordConfig) Recording {
+			return screenshot.NewRecorder(cfg)
+		},
+	}
+}
+
+// Start begins a recording. It blocks while the region selector is shown.
+func (m *Manager) Start(cfg screenshot.RecordConfig) error {
+	m.mu.Lock()
+	if m.starting || m.active != nil {
+		m.mu.Unlock()
+		return fmt.Errorf("a recording is already in progress")
+	}
+	m.starting = true
+	m.mu.Unlock()
+
+	rec := m.newRecording(cfg)
+	rec.OnState(m.setState)
+	err := rec.Start()
+
+	m.mu.Lock()
+	m.starting = false
+	if err == nil {
+		m.active = rec
+	}
+	m.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		<-rec.Done()
+		m.mu.Lock()
+		if m.active == rec {
+			m.active = nil
+		}
+		m.mu.Unlock()
+		if state := rec.State(); state.Error != "" {
+			log.Warnf("Screen recording failed: %s", state.Error)
+		}
+	}()
+	return nil
+}
+
+// Stop ends the active recording and returns its final state once the file
+// has been written.
+func (m *Manager) Stop() (State, error) {
+	rec, err := m.current()
+	if err != nil {
+		return State{}, err
+	}
+
+	rec.Stop()
+	select {
+	case <-rec.Done():
+	case <-time.After(stopTimeout):
+		return rec.State(), fmt.Errorf("timed out waiting for the recording to finish")
+	}
+
+	state := rec.State()
+	if state.Error != "" {
+		return state, fmt.Errorf("%s", state.Error)
+	}
+	return state, nil
+}
+
+func (m *Manager) Pause() error {
+	rec, err := m.current()
+	if err != nil {
+		return err
+	}
+	rec.Pause()
+	return nil
+}
+
+func (m *Manager) Resume() error {
+	rec, err := m.current()
+	if err != nil {
+		return err
+	}
+	rec.Resume()
+	return nil
+}
+
+// Toggle pauses a running recording or resumes a paused one.
+func (m *Manager) Toggle() error {
+	rec, err := m.current()
+	if err != nil {
+		return err
+	}
+	if rec.State().Paused {
+		rec.Resume()
+	} else {
+		rec.Pause()
+	}
+	return nil
+}
+
+func (m *Manager) current() (Recording, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.active == nil {
+		return nil, fmt.Errorf("no recording in progress")
+	}
+	return m.active, nil
+}
+
+func (m *Manager) GetState() State {
+	m.mu.Lock()
+	rec := m.active
+	state := m.state
+	m.mu.Unlock()
+
+	if rec != nil {
+		return rec.State()
+	}
+	return state
+}
+
+func (m *Manager) setState(state State) {
+	m.mu.Lock()
+	m.state = state
+	m.mu.Unlock()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		select {
+		case ch <- state:
+		default:
+			log.Warn("Screen recording: subscriber channel full, dropping update")
+		}
+		return true
+	})
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if ch, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(ch)
+	}
+}
+
+func (m *Manager) Close() {
+	if rec, err := m.current(); err == nil {
+		rec.Stop()
+		select {
+		case <-rec.Done():
+		case <-time.After(stopTimeout):
+		}
+	}
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,134 @@
+package screenrecord
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+)
+
+type fakeRecording struct {
+	mu       sync.Mutex
+	startErr error
+	onState  func(screenshot.RecordState)
+	state    screenshot.RecordState
+	done     chan struct{}
+}
+
+func newFakeRecording(startErr error) *fakeRecording {
+	return &fakeRecording{startErr: startErr, done: make(chan struct{})}
+}
+
+func (f *fakeRecording) OnState(fn func(screenshot.RecordState)) { f.onState = fn }
+
+func (f *fakeRecording) Start() error {
+	if f.startErr != nil {
+		return f.startErr
+	}
+	f.set(func(s *screenshot.RecordState) {
+		s.Recording = true
+		s.Path = "/tmp/rec.gif"
+	})
+	return nil
+}
+
+func (f *fakeRecording) Stop() {
+	f.set(func(s *screenshot.RecordState) {
+		s.Recording = false
+		s.Frames = 3
+	})
+	close(f.done)
+}
+
+func (f *fakeRecording) Pause()  { f.set(func(s *screenshot.RecordState) { s.Paused = true }) }
+func (f *fakeRecording) Resume() { f.set(func(s *screenshot.RecordState) { s.Paused = false }) }
+
+func (f *fakeRecording) State() screenshot.RecordState {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state
+}
+
+func (f *fakeRecording) Done() <-chan struct{} { return f.done }
+
+func (f *fakeRecording) set(update func(*screenshot.RecordState)) {
+	f.mu.Lock()
+	update(&f.state)
+	state := f.state
+	f.mu.Unlock()
+	f.onState(state)
+}
+
+func newTestManager(rec *fakeRecording) *Manager {
+	m := NewManager()
+	m.newRecording = func(screenshot.RecordConfig) Recording { return rec }
+	return m
+}
+
+func TestManagerLifecycle(t *testing.T) {
+	rec := newFakeRecording(nil)
+	m := newTestManager(rec)
+
+	updates := m.Subscribe("test")
+	defer m.Unsubscribe("test")
+
+	_, err := m.Stop()
+	assert.Error(t, err, "nothing to stop yet")
+
+	require.NoError(t, m.Start(screenshot.DefaultRecordConfig()))
+	assert.True(t, (<-updates).Recording)
+	assert.Error(t, m.Start(screenshot.DefaultRecordConfig()), "one recording at a time")
+
+	require.NoError(t, m.Toggle())
+	assert.True(t, (<-updates).Paused)
+	assert.True(t, m.GetState().Paused)
+	require.NoError(t, m.Toggle())
+	assert.False(t, (<-updates).Paused)
+
+	state, err := m.Stop()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/rec.gif", state.Path)
+	assert.Equal(t, 3, state.Frames)
+
+	assert.Eventually(t, func() bool {
+		_, err := m.current()
+		return err != nil
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "/tmp/rec.gif", m.GetState().Path, "the last state stays available")
+	assert.Error(t, m.Pause())
+}
+
+func TestManagerStartFailure(t *testing.T) {
+	m := newTestManager(newFakeRecording(screenshot.ErrRecordCancelled))
+
+	err := m.Start(screenshot.DefaultRecordConfig())
+	assert.True(t, errors.Is(err, screenshot.ErrRecordCancelled))
+
+	m.newRecording = func(screenshot.RecordConfig) Recording { return newFakeRecording(nil) }
+	assert.NoError(t, m.Start(screenshot.DefaultRecordConfig()), "a failed start does not block the next one")
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := parseConfig(map[string]any{"mode": "output", "output": "DP-1", "format": "webp", "fps": float64(30), "cursor": true, "maxDuration": 2.5})
+	require.NoError(t, err)
+	assert.Equal(t, screenshot.ModeOutput, cfg.Mode)
+	assert.Equal(t, screenshot.RecordWebP, cfg.Format)
+	assert.Equal(t, 30, cfg.FPS)
+	assert.Equal(t, screenshot.CursorOn, cfg.Cursor)
+	assert.Equal(t, 2500*time.Millisecond, cfg.MaxDuration)
+
+	for _, p := range []map[string]any{
+		{"mode": "output"},
+		{"format": "pipe"},
+		{"fps": float64(0)},
+		{"mode": "everything"},
+	} {
+		_, err := parseConfig(p)
+		assert.Error(t, err, "%v", p)
+	}
+}
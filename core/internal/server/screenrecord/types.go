@@ -0,0 +1,32 @@
+package screenrecord
+
+import (
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+type State = screenshot.RecordState
+
+// Recording is the part of screenshot.Recorder the manager drives.
+type Recording interface {
+	OnState(fn func(screenshot.RecordState))
+	Start() error
+	Stop()
+	Pause()
+	Resume()
+	State() screenshot.RecordState
+	Done() <-chan struct{}
+}
+
+type Manager struct {
+	newRecording func(screenshot.RecordConfig) Recording
+
+	mu       sync.Mutex
+	active   Recording
+	starting bool
+	state    State
+
+	subscribers syncmap.Map[string, chan State]
+}
@@ -14,28 +14,39 @@ import (
 	"syscall"
 	"time"
 
+	clipboardstore "github.com/AvengeMedia/DankMaterialShell/core/internal/clipboard"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/colorpicker"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/screenshot"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/apppicker"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/bluez"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/brightness"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/clipboard"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/colorhistory"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/cups"
 	serverDbus "github.com/AvengeMedia/DankMaterialShell/core/internal/server/dbus"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/dwl"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/evdev"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/extworkspace"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/freedesktop"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/idle"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/inhibitors"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/loginctl"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/network"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/screenhistory"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/screenrecord"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/session"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/thememode"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/toplevel"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wayland"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wlcontext"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wlroutput"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/workspacemeta"
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
 )
 
-const APIVersion = 24
+const APIVersion = 38
 
 var CLIVersion = "dev"
 
@@ -70,6 +81,14 @@ var clipboardManager *clipboard.Manager
 var dbusManager *serverDbus.Manager
 var wlContext *wlcontext.SharedContext
 var themeModeManager *thememode.Manager
+var idleManager *idle.Manager
+var toplevelManager *toplevel.Manager
+var inhibitorsManager *inhibitors.Manager
+var sessionManager *session.Manager
+var workspaceMetaManager *workspacemeta.Manager
+var screenRecordManager *screenrecord.Manager
+var screenHistoryManager *screenhistory.Manager
+var colorHistoryManager *colorhistory.Manager
 
 const dbusClientID = "dms-dbus-client"
 
@@ -200,7 +219,7 @@ func InitializeWaylandManager() error {
 		wlContext = ctx
 	}
 
-	config := wayland.DefaultConfig()
+	config := wayland.LoadConfig()
 	manager, err := wayland.NewManager(wlContext.Display(), config)
 	if err != nil {
 		log.Errorf("Failed to initialize wayland manager: %v", err)
@@ -265,6 +284,10 @@ func InitializeDwlManager() error {
 	}
 
 	dwlManager = manager
+	if workspaceMetaManager != nil {
+		manager.SetMetadata(workspaceMetaManager)
+		workspaceMetaManager.SetActivator(manager)
+	}
 
 	log.Info("DWL IPC initialized successfully")
 	return nil
@@ -302,6 +325,12 @@ func InitializeExtWorkspaceManager() error {
 	}
 
 	extWorkspaceManager = manager
+	if workspaceMetaManager != nil {
+		manager.SetMetadata(workspaceMetaManager)
+		if dwlManager == nil {
+			workspaceMetaManager.SetActivator(manager)
+		}
+	}
 
 	log.Info("ExtWorkspace initialized successfully")
 	return nil
@@ -369,6 +398,115 @@ func InitializeClipboardManager() error {
 	return nil
 }
 
+func InitializeIdleManager() error {
+	log.Info("Attempting to initialize idle manager...")
+
+	if wlContext == nil {
+		ctx, err := wlcontext.New()
+		if err != nil {
+			log.Errorf("Failed to create shared Wayland context: %v", err)
+			return err
+		}
+		wlContext = ctx
+	}
+
+	manager, err := idle.NewManager(wlContext, idle.LoadConfig())
+	if err != nil {
+		log.Debugf("Failed to initialize idle manager: %v", err)
+		return err
+	}
+
+	idleManager = manager
+
+	log.Info("Idle manager initialized successfully")
+	return nil
+}
+
+func InitializeToplevelManager() error {
+	log.Info("Attempting to initialize toplevel manager...")
+
+	if wlContext == nil {
+		ctx, err := wlcontext.New()
+		if err != nil {
+			log.Errorf("Failed to create shared Wayland context: %v", err)
+			return err
+		}
+		wlContext = ctx
+	}
+
+	manager, err := toplevel.NewManager(wlContext)
+	if err != nil {
+		log.Debugf("Failed to initialize toplevel manager: %v", err)
+		return err
+	}
+
+	toplevelManager = manager
+
+	log.Info("Toplevel manager initialized successfully")
+	return nil
+}
+
+func InitializeInhibitorsManager() {
+	inhibitorsManager = inhibitors.NewManager(inhibitors.LoadConfig())
+	log.Info("Inhibitors manager initialized successfully")
+}
+
+func InitializeScreenRecordManager() {
+	screenRecordManager = screenrecord.NewManager()
+}
+
+func InitializeScreenHistoryManager() {
+	screenHistoryManager = screenhistory.NewManager(screenshot.DefaultHistory(), func(data []byte, mimeType string) error {
+		if clipboardManager != nil {
+			return clipboardManager.SetClipboard(data, mimeType)
+		}
+		return clipboardstore.Copy(data, mimeType)
+	})
+}
+
+func InitializeColorHistoryManager() {
+	colorHistoryManager = colorhistory.NewManager(colorpicker.DefaultPalette(), func(data []byte, mimeType string) error {
+		if clipboardManager != nil {
+			return clipboardManager.SetClipboard(data, mimeType)
+		}
+		return clipboardstore.Copy(data, mimeType)
+	})
+}
+
+func InitializeWorkspaceMetaManager() {
+	workspaceMetaManager = workspacemeta.NewManager(workspacemeta.LoadConfig())
+	switch {
+	case dwlManager != nil:
+		workspaceMetaManager.SetActivator(dwlManager)
+	case extWorkspaceManager != nil:
+		workspaceMetaManager.SetActivator(extWorkspaceManager)
+	}
+
+	metaChan := workspaceMetaManager.Subscribe("workspace-managers")
+	go func() {
+		for range metaChan {
+			if dwlManager != nil {
+				dwlManager.RefreshMetadata()
+			}
+			if extWorkspaceManager != nil {
+				extWorkspaceManager.RefreshMetadata()
+			}
+		}
+	}()
+
+	log.Info("Workspace metadata manager initialized successfully")
+}
+
+func InitializeSessionManager() error {
+	if loginctlManager == nil {
+		return fmt.Errorf("loginctl manager not available")
+	}
+
+	sessionManager = session.NewManager(loginctlManager, session.LoadConfig())
+	log.Info("Session manager initialized successfully")
+	return nil
+}
+
 func InitializeDbusManager() error {
 	manager, err := serverDbus.NewManager()
 	if err != nil {
@@ -475,6 +613,38 @@ func getCapabilities() Capabilities {
 		caps = append(caps, "dbus")
 	}
 
+	if idleManager != nil {
+		caps = append(caps, "idle")
+	}
+
+	if toplevelManager != nil {
+		caps = append(caps, "toplevels")
+	}
+
+	if inhibitorsManager != nil {
+		caps = append(caps, "inhibitors")
+	}
+
+	if sessionManager != nil {
+		caps = append(caps, "session")
+	}
+
+	if workspaceMetaManager != nil {
+		caps = append(caps, "workspacemeta")
+	}
+
+	if screenRecordManager != nil {
+		caps = append(caps, "screenshot.record")
+	}
+
+	if screenHistoryManager != nil {
+		caps = append(caps, "screenshot.history")
+	}
+
+	if colorHistoryManager != nil {
+		caps = append(caps, "color.history")
+	}
+
 	return Capabilities{Capabilities: caps}
 }
 
@@ -541,6 +711,38 @@ func getServerInfo() ServerInfo {
 		caps = append(caps, "dbus")
 	}
 
+	if idleManager != nil {
+		caps = append(caps, "idle")
+	}
+
+	if toplevelManager != nil {
+		caps = append(caps, "toplevels")
+	}
+
+	if inhibitorsManager != nil {
+		caps = append(caps, "inhibitors")
+	}
+
+	if sessionManager != nil {
+		caps = append(caps, "session")
+	}
+
+	if workspaceMetaManager != nil {
+		caps = append(caps, "workspacemeta")
+	}
+
+	if screenRecordManager != nil {
+		caps = append(caps, "screenshot.record")
+	}
+
+	if screenHistoryManager != nil {
+		caps = append(caps, "screenshot.history")
+	}
+
+	if colorHistoryManager != nil {
+		caps = append(caps, "color.history")
+	}
+
 	return ServerInfo{
 		APIVersion:   APIVersion,
 		CLIVersion:   CLIVersion,
@@ -1208,6 +1410,262 @@ func handleSubscribe(conn net.Conn, req models.Request) {
 		}()
 	}
 
+	if shouldSubscribe("idle") && idleManager != nil {
+		wg.Add(1)
+		idleChan := idleManager.Subscribe(clientID + "-idle")
+		go func() {
+			defer wg.Done()
+			defer idleManager.Unsubscribe(clientID + "-idle")
+
+			initialState := idleManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "idle", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-idleChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "idle", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("toplevels") && toplevelManager != nil {
+		wg.Add(1)
+		toplevelChan := toplevelManager.Subscribe(clientID + "-toplevels")
+		go func() {
+			defer wg.Done()
+			defer toplevelManager.Unsubscribe(clientID + "-toplevels")
+
+			initialState := toplevelManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "toplevels", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-toplevelChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "toplevels", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("inhibitors") && inhibitorsManager != nil {
+		wg.Add(1)
+		inhibitorsChan := inhibitorsManager.Subscribe(clientID + "-inhibitors")
+		go func() {
+			defer wg.Done()
+			defer inhibitorsManager.Unsubscribe(clientID + "-inhibitors")
+
+			initialState := inhibitorsManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "inhibitors", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-inhibitorsChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "inhibitors", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("screenshot.record") && screenRecordManager != nil {
+		wg.Add(1)
+		recordChan := screenRecordManager.Subscribe(clientID + "-screenrecord")
+		go func() {
+			defer wg.Done()
+			defer screenRecordManager.Unsubscribe(clientID + "-screenrecord")
+
+			initialState := screenRecordManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "screenshot.record", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-recordChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "screenshot.record", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("screenshot.history") && screenHistoryManager != nil {
+		wg.Add(1)
+		historyChan := screenHistoryManager.Subscribe(clientID + "-screenhistory")
+		go func() {
+			defer wg.Done()
+			defer screenHistoryManager.Unsubscribe(clientID + "-screenhistory")
+
+			initialState := screenHistoryManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "screenshot.history", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-historyChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "screenshot.history", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("color.history") && colorHistoryManager != nil {
+		wg.Add(1)
+		colorChan := colorHistoryManager.Subscribe(clientID + "-colorhistory")
+		go func() {
+			defer wg.Done()
+			defer colorHistoryManager.Unsubscribe(clientID + "-colorhistory")
+
+			initialState := colorHistoryManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "color.history", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-colorChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "color.history", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("session") && sessionManager != nil {
+		wg.Add(1)
+		sessionChan := sessionManager.Subscribe(clientID + "-session")
+		go func() {
+			defer wg.Done()
+			defer sessionManager.Unsubscribe(clientID + "-session")
+
+			initialState := sessionManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "session", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-sessionChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "session", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
+	if shouldSubscribe("workspacemeta") && workspaceMetaManager != nil {
+		wg.Add(1)
+		workspaceMetaChan := workspaceMetaManager.Subscribe(clientID + "-workspacemeta")
+		go func() {
+			defer wg.Done()
+			defer workspaceMetaManager.Unsubscribe(clientID + "-workspacemeta")
+
+			initialState := workspaceMetaManager.GetState()
+			select {
+			case eventChan <- ServiceEvent{Service: "workspacemeta", Data: initialState}:
+			case <-stopChan:
+				return
+			}
+
+			for {
+				select {
+				case state, ok := <-workspaceMetaChan:
+					if !ok {
+						return
+					}
+					select {
+					case eventChan <- ServiceEvent{Service: "workspacemeta", Data: state}:
+					case <-stopChan:
+						return
+					}
+				case <-stopChan:
+					return
+				}
+			}
+		}()
+	}
+
 	if shouldSubscribe("dbus") && dbusManager != nil {
 		wg.Add(1)
 		dbusChan := dbusManager.SubscribeSignals(dbusClientID)
@@ -1286,6 +1744,31 @@ func cleanupManagers() {
 	if extWorkspaceManager != nil {
 		extWorkspaceManager.Close()
 	}
+	if idleManager != nil {
+		idleManager.Close()
+	}
+	if toplevelManager != nil {
+		toplevelManager.Close()
+	}
+	if inhibitorsManager != nil {
+		inhibitorsManager.Close()
+	}
+	if screenRecordManager != nil {
+		screenRecordManager.Close()
+	}
+
+	if screenHistoryManager != nil {
+		screenHistoryManager.Close()
+	}
+	if colorHistoryManager != nil {
+		colorHistoryManager.Close()
+	}
+	if sessionManager != nil {
+		sessionManager.Close()
+	}
+	if workspaceMetaManager != nil {
+		workspaceMetaManager.Close()
+	}
 	if brightnessManager != nil {
 		brightnessManager.Close()
 	}
@@ -1340,6 +1823,11 @@ func Start(printDocs bool) error {
 		log.Info(" plugins.uninstall           - Uninstall plugin (params: name)")
 		log.Info(" plugins.update              - Update plugin (params: name)")
 		log.Info(" plugins.search              - Search plugins (params: query, category?, compositor?, capability?)")
+		log.Info("Keybinds:")
+		log.Info(" keybinds.migrate            - Translate binds between compositors (params: from, to, dryRun?)")
+		log.Info(" keybinds.check              - Report conflicting binds (params: provider, key?, action?)")
+		log.Info("Window Rules:")
+		log.Info(" windowrules.test            - Match criteria against open windows (params: matchCriteria, compositor?)")
 		log.Info("Network:")
 		log.Info(" network.getState            - Get current network state")
 		log.Info(" network.wifi.scan           - Scan for WiFi networks (params: device?)")
@@ -1380,6 +1868,9 @@ func Start(printDocs bool) error {
 		log.Info(" loginctl.setSleepInhibitorEnabled - Enable/disable sleep inhibitor (params: enabled)")
 		log.Info(" loginctl.lockerReady        - Signal locker UI is ready (releases sleep inhibitor)")
 		log.Info(" loginctl.terminate          - Terminate session")
+		log.Info(" loginctl.listSessions       - List sessions of this user and seat")
+		log.Info(" loginctl.listUsers          - List logged-in users")
+		log.Info(" loginctl.switchTo           - Switch session or VT on this seat (params: session | vt)")
 		log.Info(" loginctl.subscribe          - Subscribe to session state changes (streaming)")
 		log.Info("Freedesktop:")
 		log.Info(" freedesktop.getState                  - Get accounts & settings state")
@@ -1398,6 +1889,7 @@ func Start(printDocs bool) error {
 		log.Info(" wayland.gamma.setManualTimes          - Set manual times (params: sunrise, sunset)")
 		log.Info(" wayland.gamma.setGamma                - Set gamma value (params: gamma)")
 		log.Info(" wayland.gamma.setEnabled              - Enable/disable gamma control (params: enabled)")
+		log.Info(" wayland.gamma.setOutputConfig         - Set per-output override (params: output, mode?, lowTemp?, highTemp?, temp?, gamma?, redGain?, greenGain?, blueGain?, brightness?, clear?)")
 		log.Info(" wayland.gamma.subscribe               - Subscribe to gamma state changes (streaming)")
 		log.Info("Theme automation:")
 		log.Info(" theme.auto.getState                   - Get current theme automation state")
@@ -1463,6 +1955,8 @@ func Start(printDocs bool) error {
 		log.Info(" wlroutput.getState                    - Get current output configuration state")
 		log.Info(" wlroutput.applyConfiguration          - Apply output configuration (params: heads)")
 		log.Info(" wlroutput.testConfiguration           - Test output configuration without applying (params: heads)")
+		log.Info(" wlroutput.previewConfig               - Diff the current outputs against the outputs file (params: compositor?)")
+		log.Info(" wlroutput.writeConfig                 - Write the current outputs to the outputs file (params: compositor?)")
 		log.Info(" wlroutput.subscribe                   - Subscribe to output state changes (streaming)")
 		log.Info("   Head configuration params:")
 		log.Info("     - name         : Output name (required)")
@@ -1488,6 +1982,65 @@ func Start(printDocs bool) error {
 		log.Info(" clipboard.getConfig                   - Get clipboard configuration")
 		log.Info(" clipboard.setConfig                   - Set configuration (params: maxHistory?, maxEntrySize?, autoClearDays?, clearAtStartup?)")
 		log.Info(" clipboard.subscribe                   - Subscribe to clipboard state changes (streaming)")
+		log.Info("Idle:")
+		log.Info(" idle.getState                         - Get idle state (config, stages, inhibitors)")
+		log.Info(" idle.setEnabled                       - Enable/disable idle handling (params: enabled)")
+		log.Info(" idle.setStages                        - Set idle stages (params: stages [{timeout, action, dimPercent?}])")
+		log.Info(" idle.setRespectInhibitors             - Honor ScreenSaver/logind inhibitors (params: enabled)")
+		log.Info(" idle.subscribe                        - Subscribe to idle state changes (streaming)")
+		log.Info("   Stage actions: dim, lock, dpms, suspend (timeout in seconds)")
+		log.Info("Toplevels:")
+		log.Info(" toplevels.getState                    - Get window list (appId, title, outputs, state flags, focusOrder)")
+		log.Info(" toplevels.activate                    - Focus a window (params: id)")
+		log.Info(" toplevels.close                       - Ask a window to close (params: id)")
+		log.Info(" toplevels.minimize                    - Minimize/restore a window (params: id, minimized?)")
+		log.Info(" toplevels.maximize                    - Maximize/restore a window (params: id, maximized?)")
+		log.Info(" toplevels.fullscreen                  - Fullscreen/restore a window (params: id, fullscreen?, output?)")
+		log.Info(" toplevels.subscribe                   - Subscribe to window list changes (streaming)")
+		log.Info("   Control requests need wlr-foreign-toplevel-management; ext-foreign-toplevel-list is read-only")
+		log.Info("")
+		log.Info("Inhibitors:")
+		log.Info(" inhibitors.list                       - List logind/ScreenSaver/Wayland inhibitors with owner process")
+		log.Info(" inhibitors.setRules                   - Set ignore/release rules (params: rules [{source?, app?, exe?, reason?, action}])")
+		log.Info(" inhibitors.release                    - Drop a ScreenSaver inhibitor held by an app (params: id)")
+		log.Info(" inhibitors.subscribe                  - Subscribe to inhibitor changes (streaming)")
+		log.Info("")
+		log.Info("Screen Recording:")
+		log.Info(" screenshot.record.start               - Record a region or output (params: mode?, output?, format? [gif|apng|webp|pipe], fps?, cursor?, dir?, filename?, encoder?, maxDuration?)")
+		log.Info(" screenshot.record.stop                - Stop recording and wait for the file")
+		log.Info(" screenshot.record.pause               - Pause recording")
+		log.Info(" screenshot.record.resume              - Resume recording")
+		log.Info(" screenshot.record.toggle              - Pause or resume recording")
+		log.Info(" screenshot.record.getState            - Get recording state (elapsed, frames, path)")
+		log.Info(" screenshot.record.subscribe           - Subscribe to recording state and elapsed time (streaming)")
+		log.Info(" screenshot.history.list               - List recent captures, newest first (params: limit?)")
+		log.Info(" screenshot.history.get                - Get a capture (params: id? [prefix, default latest])")
+		log.Info(" screenshot.history.copy               - Copy a capture to the clipboard again (params: id?)")
+		log.Info(" screenshot.history.open               - Open a capture with xdg-open (params: id?)")
+		log.Info(" screenshot.history.delete             - Remove a capture (params: id, deleteFile?)")
+		log.Info(" screenshot.history.prune              - Drop old captures (params: maxAge? [seconds], maxSize? [bytes], deleteFiles?)")
+		log.Info(" screenshot.history.subscribe          - Subscribe to history changes (streaming)")
+		log.Info("")
+		log.Info("Session:")
+		log.Info(" session.getState                      - Get power capabilities (logind Can*), pending action and hooks")
+		log.Info(" session.suspend                       - Suspend (params: delay?)")
+		log.Info(" session.hibernate                     - Hibernate (params: delay?)")
+		log.Info(" session.hybridSleep                   - Hybrid sleep (params: delay?)")
+		log.Info(" session.reboot                        - Reboot (params: delay?)")
+		log.Info(" session.poweroff                      - Power off (params: delay?)")
+		log.Info(" session.rebootToFirmware              - Reboot into firmware setup (params: delay?)")
+		log.Info(" session.cancel                        - Cancel a delayed action")
+		log.Info(" session.setHooks                      - Set pre-action hooks (params: hooks [{name?, command, actions?, timeout?}])")
+		log.Info(" session.subscribe                     - Subscribe to session state and countdown (streaming)")
+		log.Info("")
+		log.Info("Workspace metadata:")
+		log.Info(" workspacemeta.getState                - Get workspace labels/icons/colors and app assignments")
+		log.Info(" workspacemeta.setMeta                 - Set workspace metadata (params: coordinates, output?, label?, icon?, color?)")
+		log.Info(" workspacemeta.setAssignments          - Set sticky app assignments (params: assignments [{appId, output?, coordinates}])")
+		log.Info(" workspacemeta.resolveApp              - Get the workspace assigned to an app (params: appId)")
+		log.Info(" workspacemeta.activateForApp          - Switch to the workspace assigned to an app before launching it (params: appId)")
+		log.Info(" workspacemeta.subscribe               - Subscribe to metadata changes (streaming)")
+		log.Info("   Metadata is merged into dwl.getState tags and extworkspace.getState workspaces")
 		log.Info("")
 	}
 	log.Info("Initializing managers...")
@@ -1518,6 +2071,8 @@ func Start(printDocs bool) error {
 
 	loginctlReady := make(chan struct{})
 	freedesktopReady := make(chan struct{})
+	brightnessReady := make(chan struct{})
+	idleReady := make(chan struct{})
 
 	go func() {
 		defer close(loginctlReady)
@@ -1579,6 +2134,8 @@ func Start(printDocs bool) error {
 		log.Debugf("AppPicker manager unavailable: %v", err)
 	}
 
+	InitializeWorkspaceMetaManager()
+
 	if err := InitializeDwlManager(); err != nil {
 		log.Debugf("DWL manager unavailable: %v", err)
 	}
@@ -1623,6 +2180,7 @@ func Start(printDocs bool) error {
 	}
 
 	go func() {
+		defer close(brightnessReady)
 		if err := InitializeBrightnessManager(); err != nil {
 			log.Warnf("Brightness manager unavailable: %v", err)
 		} else {
@@ -1642,6 +2200,17 @@ func Start(printDocs bool) error {
 		if err := InitializeClipboardManager(); err != nil {
 			log.Warnf("Clipboard manager unavailable: %v", err)
 		}
+		if err := InitializeIdleManager(); err != nil {
+			log.Debugf("Idle manager unavailable: %v", err)
+		} else {
+			notifyCapabilityChange()
+		}
+		close(idleReady)
+		if err := InitializeToplevelManager(); err != nil {
+			log.Debugf("Toplevel manager unavailable: %v", err)
+		} else {
+			notifyCapabilityChange()
+		}
 		if wlContext != nil {
 			wlContext.Start()
 			log.Info("Wayland event dispatcher started")
@@ -1656,6 +2225,56 @@ func Start(printDocs bool) error {
 		}
 	}()
 
+	InitializeInhibitorsManager()
+	InitializeScreenRecordManager()
+	InitializeScreenHistoryManager()
+	InitializeColorHistoryManager()
+
+	go func() {
+		<-loginctlReady
+		if loginctlManager != nil {
+			inhibitorsManager.SetLogindSource(loginctlManager)
+			if err := InitializeSessionManager(); err != nil {
+				log.Debugf("Session manager unavailable: %v", err)
+			} else {
+				notifyCapabilityChange()
+			}
+		}
+
+		<-freedesktopReady
+		if freedesktopManager != nil {
+			inhibitorsManager.WatchScreensaver(freedesktopManager)
+		}
+
+		<-idleReady
+		if idleManager != nil {
+			inhibitorsManager.WatchIdle(idleManager)
+			idleManager.SetInhibitorSource(inhibitorsManager)
+		}
+	}()
+
+	go func() {
+		<-idleReady
+		if idleManager == nil {
+			return
+		}
+
+		<-loginctlReady
+		if loginctlManager != nil {
+			idleManager.WatchLoginctl(loginctlManager)
+		}
+
+		<-freedesktopReady
+		if freedesktopManager != nil {
+			idleManager.SetScreensaverSource(freedesktopManager)
+		}
+
+		<-brightnessReady
+		if brightnessManager != nil {
+			idleManager.SetBrightnessController(brightnessManager)
+		}
+	}()
+
 	log.Info("")
 	log.Infof("Ready! Capabilities: %v", getCapabilities().Capabilities)
 
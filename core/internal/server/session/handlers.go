@@ -0,0 +1,129 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "session manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "session.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "session.suspend", "session.hibernate", "session.hybridSleep",
+		"session.reboot", "session.poweroff", "session.rebootToFirmware":
+		handleAction(conn, req, manager, Action(strings.TrimPrefix(req.Method, "session.")))
+	case "session.cancel":
+		handleCancel(conn, req, manager)
+	case "session.setHooks":
+		handleSetHooks(conn, req, manager)
+	case "session.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleAction(conn net.Conn, req models.Request, manager *Manager, action Action) {
+	delay := params.IntOpt(req.Params, "delay", 0)
+
+	if err := manager.Execute(action, delay); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	msg := fmt.Sprintf("%s started", action)
+	if delay > 0 {
+		msg = fmt.Sprintf("%s scheduled in %ds", action, delay)
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: msg})
+}
+
+func handleCancel(conn net.Conn, req models.Request, manager *Manager) {
+	if err := manager.Cancel(); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "pending action cancelled"})
+}
+
+func handleSetHooks(conn net.Conn, req models.Request, manager *Manager) {
+	hooks, err := parseHooks(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.SetHooks(hooks); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "hooks set"})
+}
+
+func parseHooks(p map[string]any) ([]Hook, error) {
+	raw, err := params.Get[[]any](p, "hooks")
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := make([]Hook, 0, len(raw))
+	for i, item := range raw {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("hook %d: expected object", i)
+		}
+		command, err := params.String(obj, "command")
+		if err != nil {
+			return nil, fmt.Errorf("hook %d: %w", i, err)
+		}
+
+		hook := Hook{
+			Name:    params.StringOpt(obj, "name", ""),
+			Command: command,
+			Timeout: params.IntOpt(obj, "timeout", 0),
+		}
+		if actions, ok := obj["actions"].([]any); ok {
+			for _, a := range actions {
+				s, ok := a.(string)
+				if !ok {
+					return nil, fmt.Errorf("hook %d: actions must be strings", i)
+				}
+				hook.Actions = append(hook.Actions, Action(s))
+			}
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+func NewManager(backend Backend, config Config) *Manager {
+	m := &Manager{
+		backend:  backend,
+		config:   config,
+		tick:     time.Second,
+		stopChan: make(chan struct{}),
+		dirty:    make(chan struct{}, 1),
+		state: &State{
+			Capabilities: map[Action]string{},
+			Hooks:        config.Hooks,
+			LastHooks:    []HookResult{},
+		},
+	}
+
+	m.RefreshCapabilities()
+
+	m.notifierWg.Add(1)
+	go m.notifier()
+
+	return m
+}
+
+func (m *Manager) getConfig() Config {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+	return m.config
+}
+
+func (m *Manager) RefreshCapabilities() {
+	caps := make(map[Action]string, len(allActions))
+	for _, a := range allActions {
+		caps[a] = m.queryCapability(a)
+	}
+
+	m.stateMutex.Lock()
+	m.state.Capabilities = caps
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+}
+
+func (m *Manager) queryCapability(a Action) string {
+	method, _ := logindMethod(a)
+	result, err := m.backend.CanPowerAction(method)
+	if err != nil {
+		log.Debugf("Session: Can%s failed: %v", method, err)
+		return "na"
+	}
+	return result
+}
+
+// checkCapability re-queries logind right before acting so stale answers
+// (e.g. swap removed since startup) don't let a doomed request through.
+func (m *Manager) checkCapability(a Action) error {
+	result := m.queryCapability(a)
+
+	m.stateMutex.Lock()
+	caps := maps.Clone(m.state.Capabilities)
+	caps[a] = result
+	m.state.Capabilities = caps
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+
+	switch result {
+	case "yes", "challenge":
+		return nil
+	default:
+		return fmt.Errorf("%s not available (logind: %s)", a, result)
+	}
+}
+
+// Execute runs action now, or after delay seconds with a cancellable
+// countdown. A new request replaces any pending one.
+func (m *Manager) Execute(a Action, delay int) error {
+	if _, ok := logindMethod(a); !ok {
+		return fmt.Errorf("unknown action: %s", a)
+	}
+	if delay < 0 {
+		return fmt.Errorf("delay must not be negative")
+	}
+	if err := m.checkCapability(a); err != nil {
+		return err
+	}
+
+	if delay == 0 {
+		m.dropPending()
+		return m.run(a)
+	}
+
+	m.schedule(a, delay)
+	return nil
+}
+
+func (m *Manager) schedule(a Action, delay int) {
+	cancel := make(chan struct{})
+
+	m.pendingMutex.Lock()
+	if m.pendingCancel != nil {
+		close(m.pendingCancel)
+	}
+	m.pendingCancel = cancel
+	m.setPending(&Pending{
+		Action:    a,
+		Remaining: delay,
+		Deadline:  time.Now().Add(time.Duration(delay) * m.tick).Unix(),
+	})
+	m.pendingMutex.Unlock()
+
+	log.Infof("Session: %s in %ds", a, delay)
+
+	m.wg.Add(1)
+	go m.countdown(a, delay, cancel)
+}
+
+func (m *Manager) countdown(a Action, remaining int, cancel chan struct{}) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.tick)
+	defer ticker.Stop()
+
+	for remaining > 0 {
+		select {
+		case <-cancel:
+			return
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			remaining--
+			m.pendingMutex.Lock()
+			if m.pendingCancel == cancel {
+				m.stateMutex.Lock()
+				if m.state.Pending != nil {
+					p := *m.state.Pending
+					p.Remaining = remaining
+					m.state.Pending = &p
+				}
+				m.stateMutex.Unlock()
+				m.notifySubscribers()
+			}
+			m.pendingMutex.Unlock()
+		}
+	}
+
+	m.pendingMutex.Lock()
+	if m.pendingCancel != cancel {
+		m.pendingMutex.Unlock()
+		return
+	}
+	m.pendingCancel = nil
+	m.setPending(nil)
+	m.pendingMutex.Unlock()
+
+	if err := m.run(a); err != nil {
+		log.Warnf("Session: delayed %s failed: %v", a, err)
+	}
+}
+
+func (m *Manager) Cancel() error {
+	m.pendingMutex.Lock()
+	defer m.pendingMutex.Unlock()
+
+	if m.pendingCancel == nil {
+		return errors.New("no pending action")
+	}
+	close(m.pendingCancel)
+	m.pendingCancel = nil
+	m.setPending(nil)
+	log.Info("Session: pending action cancelled")
+	return nil
+}
+
+func (m *Manager) dropPending() {
+	m.pendingMutex.Lock()
+	defer m.pendingMutex.Unlock()
+
+	if m.pendingCancel == nil {
+		return
+	}
+	close(m.pendingCancel)
+	m.pendingCancel = nil
+	m.setPending(nil)
+}
+
+// setPending must be called with pendingMutex held.
+func (m *Manager) setPending(p *Pending) {
+	m.stateMutex.Lock()
+	m.state.Pending = p
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+}
+
+func (m *Manager) setRunning(a Action) {
+	m.stateMutex.Lock()
+	m.state.Running = a
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+}
+
+func (m *Manager) run(a Action) error {
+	m.actionMutex.Lock()
+	defer m.actionMutex.Unlock()
+
+	m.setRunning(a)
+	defer m.setRunning("")
+
+	results := m.runHooks(a)
+	m.stateMutex.Lock()
+	m.state.LastHooks = results
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+
+	log.Infof("Session: executing %s", a)
+
+	if a == ActionRebootToFirmware {
+		if err := m.backend.SetRebootToFirmwareSetup(true); err != nil {
+			return err
+		}
+		return m.backend.PowerAction("Reboot")
+	}
+
+	method, _ := logindMethod(a)
+	return m.backend.PowerAction(method)
+}
+
+// runHooks runs matching hooks in order. Failures are recorded but never
+// abort the action; a stuck hook must not keep the machine awake.
+func (m *Manager) runHooks(a Action) []HookResult {
+	results := []HookResult{}
+	for _, h := range m.getConfig().Hooks {
+		if !h.appliesTo(a) {
+			continue
+		}
+		result := runHook(h, a)
+		if !result.Success {
+			log.Warnf("Session: hook %q failed: %s", result.Name, result.Error)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func runHook(h Hook, a Action) HookResult {
+	result := HookResult{Name: h.Name}
+	if result.Name == "" {
+		result.Name = h.Command
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+	cmd.Env = append(os.Environ(), "DMS_SESSION_ACTION="+string(a))
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	start := time.Now()
+	err := cmd.Run()
+	result.Duration = time.Since(start).Milliseconds()
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.TimedOut = true
+		result.Error = fmt.Sprintf("timed out after %s", h.timeout())
+	case err != nil:
+		result.Error = err.Error()
+	default:
+		result.Success = true
+	}
+	return result
+}
+
+func (m *Manager) SetHooks(hooks []Hook) error {
+	if err := ValidateHooks(hooks); err != nil {
+		return err
+	}
+
+	m.configMutex.Lock()
+	m.config.Hooks = hooks
+	cfg := m.config
+	m.configMutex.Unlock()
+
+	if err := SaveConfig(cfg); err != nil {
+		log.Warnf("Session: failed to save config: %v", err)
+	}
+
+	m.stateMutex.Lock()
+	m.state.Hooks = hooks
+	m.stateMutex.Unlock()
+	m.notifySubscribers()
+	return nil
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	stateCopy := *m.state
+	return stateCopy
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if ch, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(ch)
+	}
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) notifier() {
+	defer m.notifierWg.Done()
+
+	const minGap = 100 * time.Millisecond
+	timer := time.NewTimer(minGap)
+	timer.Stop()
+	var pending bool
+
+	for {
+		select {
+		case <-m.stopChan:
+			timer.Stop()
+			return
+		case <-m.dirty:
+			if pending {
+				continue
+			}
+			pending = true
+			timer.Reset(minGap)
+		case <-timer.C:
+			if !pending {
+				continue
+			}
+
+			currentState := m.GetState()
+
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &currentState) {
+				pending = false
+				continue
+			}
+
+			m.subscribers.Range(func(key string, ch chan State) bool {
+				select {
+				case ch <- currentState:
+				default:
+					log.Warn("Session: subscriber channel full, dropping update")
+				}
+				return true
+			})
+
+			stateCopy := currentState
+			m.lastNotified = &stateCopy
+			pending = false
+		}
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	if old.Running != new.Running || !maps.Equal(old.Capabilities, new.Capabilities) {
+		return true
+	}
+	if (old.Pending == nil) != (new.Pending == nil) || (old.Pending != nil && *old.Pending != *new.Pending) {
+		return true
+	}
+	if !slices.Equal(old.LastHooks, new.LastHooks) || len(old.Hooks) != len(new.Hooks) {
+		return true
+	}
+	for i := range new.Hooks {
+		a, b := old.Hooks[i], new.Hooks[i]
+		if a.Name != b.Name || a.Command != b.Command || a.Timeout != b.Timeout || !slices.Equal(a.Actions, b.Actions) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) Close() {
+	m.dropPending()
+	close(m.stopChan)
+	m.wg.Wait()
+	m.notifierWg.Wait()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+}
@@ -0,0 +1,195 @@
+package session
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackend struct {
+	mu       sync.Mutex
+	can      map[string]string
+	calls    []string
+	firmware bool
+}
+
+func (f *fakeBackend) PowerAction(method string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, method)
+	return nil
+}
+
+func (f *fakeBackend) CanPowerAction(method string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if v, ok := f.can[method]; ok {
+		return v, nil
+	}
+	return "yes", nil
+}
+
+func (f *fakeBackend) SetRebootToFirmwareSetup(enabled bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.firmware = enabled
+	return nil
+}
+
+func (f *fakeBackend) getCalls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func newTestManager(backend *fakeBackend, hooks ...Hook) *Manager {
+	return &Manager{
+		backend:  backend,
+		config:   Config{Hooks: hooks},
+		tick:     10 * time.Millisecond,
+		stopChan: make(chan struct{}),
+		dirty:    make(chan struct{}, 1),
+		state: &State{
+			Capabilities: map[Action]string{},
+			Hooks:        hooks,
+			LastHooks:    []HookResult{},
+		},
+	}
+}
+
+func TestManager_ExecuteImmediate(t *testing.T) {
+	backend := &fakeBackend{}
+	m := newTestManager(backend)
+
+	require.NoError(t, m.Execute(ActionPoweroff, 0))
+	assert.Equal(t, []string{"PowerOff"}, backend.getCalls())
+
+	require.NoError(t, m.Execute(ActionRebootToFirmware, 0))
+	assert.True(t, backend.firmware)
+	assert.Equal(t, []string{"PowerOff", "Reboot"}, backend.getCalls())
+
+	assert.Error(t, m.Execute("shutdown", 0))
+	assert.Error(t, m.Execute(ActionReboot, -1))
+}
+
+func TestManager_ExecuteUnavailable(t *testing.T) {
+	backend := &fakeBackend{can: map[string]string{"Hibernate": "no"}}
+	m := newTestManager(backend)
+
+	err := m.Execute(ActionHibernate, 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not available")
+	assert.Empty(t, backend.getCalls())
+	assert.Equal(t, "no", m.GetState().Capabilities[ActionHibernate])
+}
+
+func TestManager_RefreshCapabilities(t *testing.T) {
+	backend := &fakeBackend{can: map[string]string{"HybridSleep": "na", "Suspend": "challenge"}}
+	m := newTestManager(backend)
+
+	m.RefreshCapabilities()
+	caps := m.GetState().Capabilities
+	assert.Len(t, caps, len(allActions))
+	assert.Equal(t, "na", caps[ActionHybridSleep])
+	assert.Equal(t, "challenge", caps[ActionSuspend])
+	assert.Equal(t, "yes", caps[ActionRebootToFirmware])
+}
+
+func TestManager_DelayedCountdown(t *testing.T) {
+	backend := &fakeBackend{}
+	m := newTestManager(backend)
+	defer m.Close()
+
+	require.NoError(t, m.Execute(ActionSuspend, 3))
+	pending := m.GetState().Pending
+	require.NotNil(t, pending)
+	assert.Equal(t, ActionSuspend, pending.Action)
+	assert.Equal(t, 3, pending.Remaining)
+
+	require.Eventually(t, func() bool {
+		return len(backend.getCalls()) == 1
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"Suspend"}, backend.getCalls())
+	assert.Nil(t, m.GetState().Pending)
+}
+
+func TestManager_Cancel(t *testing.T) {
+	backend := &fakeBackend{}
+	m := newTestManager(backend)
+	m.tick = time.Hour
+	defer m.Close()
+
+	assert.Error(t, m.Cancel())
+
+	require.NoError(t, m.Execute(ActionReboot, 5))
+	require.NoError(t, m.Execute(ActionPoweroff, 5))
+	assert.Equal(t, ActionPoweroff, m.GetState().Pending.Action)
+
+	require.NoError(t, m.Cancel())
+	assert.Nil(t, m.GetState().Pending)
+	assert.Empty(t, backend.getCalls())
+}
+
+func TestManager_Hooks(t *testing.T) {
+	backend := &fakeBackend{}
+	m := newTestManager(backend,
+		Hook{Name: "ok", Command: `test "$DMS_SESSION_ACTION" = suspend`},
+		Hook{Name: "reboot-only", Command: "false", Actions: []Action{ActionReboot}},
+		Hook{Name: "fails", Command: "exit 3"},
+		Hook{Name: "slow", Command: "sleep 5", Timeout: 1},
+	)
+
+	start := time.Now()
+	require.NoError(t, m.Execute(ActionSuspend, 0))
+	assert.Less(t, time.Since(start), 4*time.Second)
+
+	results := m.GetState().LastHooks
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, "fails", results[1].Name)
+	assert.False(t, results[1].Success)
+	assert.NotEmpty(t, results[1].Error)
+	assert.True(t, results[2].TimedOut)
+	assert.Equal(t, []string{"Suspend"}, backend.getCalls())
+}
+
+func TestValidateHooks(t *testing.T) {
+	assert.NoError(t, ValidateHooks([]Hook{{Command: "true", Actions: []Action{ActionSuspend}}}))
+	assert.Error(t, ValidateHooks([]Hook{{Command: "  "}}))
+	assert.Error(t, ValidateHooks([]Hook{{Command: "true", Timeout: -1}}))
+	assert.Error(t, ValidateHooks([]Hook{{Command: "true", Actions: []Action{"lock"}}}))
+}
+
+func TestStateChanged(t *testing.T) {
+	assert.True(t, stateChanged(nil, &State{}))
+
+	a := &State{Capabilities: map[Action]string{ActionSuspend: "yes"}, Pending: &Pending{Action: ActionSuspend, Remaining: 3}}
+	b := &State{Capabilities: map[Action]string{ActionSuspend: "yes"}, Pending: &Pending{Action: ActionSuspend, Remaining: 3}}
+	assert.False(t, stateChanged(a, b))
+
+	b.Pending = &Pending{Action: ActionSuspend, Remaining: 2}
+	assert.True(t, stateChanged(a, b))
+
+	c := &State{Capabilities: map[Action]string{ActionSuspend: "no"}, Pending: a.Pending}
+	assert.True(t, stateChanged(a, c))
+}
+
+func TestParseHooks(t *testing.T) {
+	hooks, err := parseHooks(map[string]any{
+		"hooks": []any{
+			map[string]any{"name": "music", "command": "playerctl pause", "actions": []any{"suspend", "hibernate"}, "timeout": float64(2)},
+			map[string]any{"command": "sync"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []Hook{
+		{Name: "music", Command: "playerctl pause", Actions: []Action{ActionSuspend, ActionHibernate}, Timeout: 2},
+		{Command: "sync"},
+	}, hooks)
+
+	_, err = parseHooks(map[string]any{"hooks": []any{map[string]any{"name": "x"}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,211 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+type Action string
+
+const (
+	ActionSuspend          Action = "suspend"
+	ActionHibernate        Action = "hibernate"
+	ActionHybridSleep      Action = "hybridSleep"
+	ActionReboot           Action = "reboot"
+	ActionPoweroff         Action = "poweroff"
+	ActionRebootToFirmware Action = "rebootToFirmware"
+)
+
+var allActions = []Action{
+	ActionSuspend,
+	ActionHibernate,
+	ActionHybridSleep,
+	ActionReboot,
+	ActionPoweroff,
+	ActionRebootToFirmware,
+}
+
+// logindMethod maps an action to the logind Manager method suffix used for
+// both the action itself and its Can* query.
+func logindMethod(a Action) (string, bool) {
+	switch a {
+	case ActionSuspend:
+		return "Suspend", true
+	case ActionHibernate:
+		return "Hibernate", true
+	case ActionHybridSleep:
+		return "HybridSleep", true
+	case ActionReboot:
+		return "Reboot", true
+	case ActionPoweroff:
+		return "PowerOff", true
+	case ActionRebootToFirmware:
+		return "RebootToFirmwareSetup", true
+	}
+	return "", false
+}
+
+const defaultHookTimeout = 5
+
+type Hook struct {
+	Name    string   `json:"name"`
+	Command string   `json:"command"`
+	Actions []Action `json:"actions,omitempty"`
+	Timeout int      `json:"timeout,omitempty"`
+}
+
+func (h Hook) appliesTo(a Action) bool {
+	if len(h.Actions) == 0 {
+		return true
+	}
+	for _, action := range h.Actions {
+		if action == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (h Hook) timeout() time.Duration {
+	if h.Timeout <= 0 {
+		return defaultHookTimeout * time.Second
+	}
+	return time.Duration(h.Timeout) * time.Second
+}
+
+func ValidateHooks(hooks []Hook) error {
+	for i, h := range hooks {
+		if strings.TrimSpace(h.Command) == "" {
+			return fmt.Errorf("hook %d: command is required", i)
+		}
+		if h.Timeout < 0 {
+			return fmt.Errorf("hook %d: timeout must not be negative", i)
+		}
+		for _, a := range h.Actions {
+			if _, ok := logindMethod(a); !ok {
+				return fmt.Errorf("hook %d: unknown action: %s", i, a)
+			}
+		}
+	}
+	return nil
+}
+
+type Config struct {
+	Hooks []Hook `json:"hooks"`
+}
+
+func getConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "session.json"), nil
+}
+
+func LoadConfig() Config {
+	cfg := Config{Hooks: []Hook{}}
+
+	path, err := getConfigPath()
+	if err != nil {
+		return cfg
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{Hooks: []Hook{}}
+	}
+
+	if err := ValidateHooks(cfg.Hooks); err != nil {
+		return Config{Hooks: []Hook{}}
+	}
+	if cfg.Hooks == nil {
+		cfg.Hooks = []Hook{}
+	}
+	return cfg
+}
+
+func SaveConfig(cfg Config) error {
+	path, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+type Pending struct {
+	Action    Action `json:"action"`
+	Remaining int    `json:"remaining"`
+	Deadline  int64  `json:"deadline"`
+}
+
+type HookResult struct {
+	Name     string `json:"name"`
+	Success  bool   `json:"success"`
+	TimedOut bool   `json:"timedOut,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration int64  `json:"durationMs"`
+}
+
+type State struct {
+	// Capabilities holds logind's Can* answer per action: "yes", "no",
+	// "challenge" or "na".
+	Capabilities map[Action]string `json:"capabilities"`
+	Pending      *Pending          `json:"pending"`
+	Running      Action            `json:"running,omitempty"`
+	Hooks        []Hook            `json:"hooks"`
+	LastHooks    []HookResult      `json:"lastHooks"`
+}
+
+// Backend is the logind surface the manager drives; loginctl.Manager
+// satisfies it.
+type Backend interface {
+	PowerAction(method string) error
+	CanPowerAction(method string) (string, error)
+	SetRebootToFirmwareSetup(enabled bool) error
+}
+
+type Manager struct {
+	backend Backend
+
+	configMutex sync.RWMutex
+	config      Config
+
+	// actionMutex serialises hook runs and logind calls.
+	actionMutex sync.Mutex
+
+	pendingMutex  sync.Mutex
+	pendingCancel chan struct{}
+	tick          time.Duration
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+
+	stateMutex sync.RWMutex
+	state      *State
+
+	subscribers  syncmap.Map[string, chan State]
+	dirty        chan struct{}
+	notifierWg   sync.WaitGroup
+	lastNotified *State
+}
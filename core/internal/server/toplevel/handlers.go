@@ -0,0 +1,147 @@
+package toplevel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "toplevel manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "toplevels.getState":
+		handleGetState(conn, req, manager)
+	case "toplevels.activate":
+		handleActivate(conn, req, manager)
+	case "toplevels.close":
+		handleClose(conn, req, manager)
+	case "toplevels.minimize":
+		handleMinimize(conn, req, manager)
+	case "toplevels.maximize":
+		handleMaximize(conn, req, manager)
+	case "toplevels.fullscreen":
+		handleFullscreen(conn, req, manager)
+	case "toplevels.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func toplevelID(p map[string]any) (uint32, error) {
+	id, err := params.Int(p, "id")
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, fmt.Errorf("invalid toplevel id: %d", id)
+	}
+	return uint32(id), nil
+}
+
+func handleGetState(conn net.Conn, req models.Request, manager *Manager) {
+	models.Respond(conn, req.ID, manager.GetState())
+}
+
+func handleActivate(conn net.Conn, req models.Request, manager *Manager) {
+	id, err := toplevelID(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.Activate(id); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "toplevel activated"})
+}
+
+func handleClose(conn net.Conn, req models.Request, manager *Manager) {
+	id, err := toplevelID(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if err := manager.CloseToplevel(id); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "toplevel close requested"})
+}
+
+func handleMinimize(conn net.Conn, req models.Request, manager *Manager) {
+	id, err := toplevelID(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	minimized := params.BoolOpt(req.Params, "minimized", true)
+	if err := manager.SetMinimized(id, minimized); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "toplevel minimized set"})
+}
+
+func handleMaximize(conn net.Conn, req models.Request, manager *Manager) {
+	id, err := toplevelID(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	maximized := params.BoolOpt(req.Params, "maximized", true)
+	if err := manager.SetMaximized(id, maximized); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "toplevel maximized set"})
+}
+
+func handleFullscreen(conn net.Conn, req models.Request, manager *Manager) {
+	id, err := toplevelID(req.Params)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	fullscreen := params.BoolOpt(req.Params, "fullscreen", true)
+	output := params.StringOpt(req.Params, "output", "")
+	if err := manager.SetFullscreen(id, fullscreen, output); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "toplevel fullscreen set"})
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
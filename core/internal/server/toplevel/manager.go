@@ -0,0 +1,563 @@
+package toplevel
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/ext_foreign_toplevel"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_foreign_toplevel"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wlcontext"
+	wlclient "github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+)
+
+const requestTimeout = 2 * time.Second
+
+func NewManager(wlCtx wlcontext.WaylandContext) (*Manager, error) {
+	m := &Manager{
+		wlCtx:     wlCtx,
+		display:   wlCtx.Display(),
+		toplevels: make(map[uint32]*toplevelState),
+		stopChan:  make(chan struct{}),
+		dirty:     make(chan struct{}, 1),
+	}
+
+	if err := m.setupRegistry(); err != nil {
+		return nil, err
+	}
+
+	m.notifierWg.Add(1)
+	go m.notifier()
+
+	m.updateState()
+
+	return m, nil
+}
+
+func (m *Manager) post(fn func()) {
+	m.wlCtx.Post(fn)
+}
+
+func (m *Manager) setupRegistry() error {
+	ctx := m.display.Context()
+
+	registry, err := m.display.GetRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to get registry: %w", err)
+	}
+	m.registry = registry
+
+	var wlrName, wlrVersion, extName uint32
+
+	registry.SetGlobalHandler(func(e wlclient.RegistryGlobalEvent) {
+		switch e.Interface {
+		case wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1InterfaceName:
+			wlrName, wlrVersion = e.Name, e.Version
+		case ext_foreign_toplevel.ExtForeignToplevelListV1InterfaceName:
+			extName = e.Name
+		case "wl_seat":
+			if m.seat != nil {
+				return
+			}
+			seat := wlclient.NewSeat(ctx)
+			if err := registry.Bind(e.Name, e.Interface, 1, seat); err != nil {
+				log.Warnf("Toplevel: failed to bind wl_seat: %v", err)
+				return
+			}
+			m.seat = seat
+		case "wl_output":
+			output := wlclient.NewOutput(ctx)
+			if err := registry.Bind(e.Name, e.Interface, 4, output); err != nil {
+				log.Warnf("Toplevel: failed to bind wl_output: %v", err)
+				return
+			}
+			outputID := output.ID()
+			output.SetNameHandler(func(ev wlclient.OutputNameEvent) {
+				m.outputNames.Store(outputID, ev.Name)
+				m.updateState()
+			})
+			m.outputs.Store(e.Name, output)
+		}
+	})
+
+	registry.SetGlobalRemoveHandler(func(e wlclient.RegistryGlobalRemoveEvent) {
+		output, ok := m.outputs.LoadAndDelete(e.Name)
+		if !ok {
+			return
+		}
+		m.outputNames.Delete(output.ID())
+		output.Release()
+		m.updateState()
+	})
+
+	m.display.Roundtrip()
+
+	// wlr exposes state and control requests, so prefer it over the
+	// read-only ext list when the compositor offers both.
+	switch {
+	case wlrName != 0:
+		manager := wlr_foreign_toplevel.NewZwlrForeignToplevelManagerV1(ctx)
+		manager.SetToplevelHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1ToplevelEvent) {
+			m.handleWlrToplevel(e.Toplevel)
+		})
+		manager.SetFinishedHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1FinishedEvent) {
+			log.Info("Toplevel: wlr toplevel manager finished")
+			manager.Destroy()
+		})
+		if err := registry.Bind(wlrName, wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1InterfaceName, min(wlrVersion, 3), manager); err != nil {
+			return fmt.Errorf("failed to bind %s: %w", wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1InterfaceName, err)
+		}
+		m.wlrManager = manager
+		log.Infof("Toplevel: bound %s v%d", wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1InterfaceName, min(wlrVersion, 3))
+	case extName != 0:
+		list := ext_foreign_toplevel.NewExtForeignToplevelListV1(ctx)
+		list.SetToplevelHandler(func(e ext_foreign_toplevel.ExtForeignToplevelListV1ToplevelEvent) {
+			m.handleExtToplevel(e.Toplevel)
+		})
+		list.SetFinishedHandler(func(e ext_foreign_toplevel.ExtForeignToplevelListV1FinishedEvent) {
+			log.Info("Toplevel: ext toplevel list finished")
+		})
+		if err := registry.Bind(extName, ext_foreign_toplevel.ExtForeignToplevelListV1InterfaceName, 1, list); err != nil {
+			return fmt.Errorf("failed to bind %s: %w", ext_foreign_toplevel.ExtForeignToplevelListV1InterfaceName, err)
+		}
+		m.extList = list
+		log.Infof("Toplevel: bound %s (read-only)", ext_foreign_toplevel.ExtForeignToplevelListV1InterfaceName)
+	default:
+		return fmt.Errorf("compositor supports neither %s nor %s",
+			wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1InterfaceName,
+			ext_foreign_toplevel.ExtForeignToplevelListV1InterfaceName)
+	}
+
+	m.display.Roundtrip()
+
+	return nil
+}
+
+func (m *Manager) addToplevel(tl *toplevelState) {
+	m.toplevelMutex.Lock()
+	m.nextID++
+	tl.id = m.nextID
+	m.toplevels[tl.id] = tl
+	m.order = append(m.order, tl.id)
+	m.toplevelMutex.Unlock()
+}
+
+func (m *Manager) removeToplevel(id uint32) {
+	m.toplevelMutex.Lock()
+	delete(m.toplevels, id)
+	m.order = slices.DeleteFunc(m.order, func(other uint32) bool { return other == id })
+	m.toplevelMutex.Unlock()
+}
+
+func (m *Manager) modify(fn func()) {
+	m.toplevelMutex.Lock()
+	fn()
+	m.toplevelMutex.Unlock()
+}
+
+func (m *Manager) handleWlrToplevel(handle *wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1) {
+	tl := &toplevelState{wlr: handle}
+	m.addToplevel(tl)
+
+	handle.SetTitleHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1TitleEvent) {
+		m.modify(func() { tl.title = e.Title })
+	})
+	handle.SetAppIdHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1AppIdEvent) {
+		m.modify(func() { tl.appID = e.AppId })
+	})
+	handle.SetOutputEnterHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1OutputEnterEvent) {
+		if e.Output == nil {
+			return
+		}
+		m.modify(func() {
+			if !slices.Contains(tl.outputs, e.Output.ID()) {
+				tl.outputs = append(tl.outputs, e.Output.ID())
+			}
+		})
+	})
+	handle.SetOutputLeaveHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1OutputLeaveEvent) {
+		if e.Output == nil {
+			return
+		}
+		m.modify(func() {
+			tl.outputs = slices.DeleteFunc(tl.outputs, func(id uint32) bool { return id == e.Output.ID() })
+		})
+	})
+	handle.SetStateHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateEvent) {
+		flags := decodeStates(e.State)
+		m.modify(func() {
+			if flags.activated && !tl.flags.activated {
+				m.focusSeq++
+				tl.focusSeq = m.focusSeq
+			}
+			tl.flags = flags
+		})
+	})
+	handle.SetParentHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1ParentEvent) {
+		m.modify(func() {
+			tl.parent = 0
+			if e.Parent == nil {
+				return
+			}
+			for _, other := range m.toplevels {
+				if other.wlr == e.Parent {
+					tl.parent = other.id
+					break
+				}
+			}
+		})
+	})
+	handle.SetDoneHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1DoneEvent) {
+		m.modify(func() { tl.ready = true })
+		m.updateState()
+	})
+	handle.SetClosedHandler(func(e wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1ClosedEvent) {
+		m.removeToplevel(tl.id)
+		handle.Destroy()
+		m.updateState()
+	})
+}
+
+func (m *Manager) handleExtToplevel(handle *ext_foreign_toplevel.ExtForeignToplevelHandleV1) {
+	tl := &toplevelState{ext: handle}
+	m.addToplevel(tl)
+
+	handle.SetTitleHandler(func(e ext_foreign_toplevel.ExtForeignToplevelHandleV1TitleEvent) {
+		m.modify(func() { tl.title = e.Title })
+	})
+	handle.SetAppIdHandler(func(e ext_foreign_toplevel.ExtForeignToplevelHandleV1AppIdEvent) {
+		m.modify(func() { tl.appID = e.AppId })
+	})
+	handle.SetIdentifierHandler(func(e ext_foreign_toplevel.ExtForeignToplevelHandleV1IdentifierEvent) {
+		m.modify(func() { tl.identifier = e.Identifier })
+	})
+	handle.SetDoneHandler(func(e ext_foreign_toplevel.ExtForeignToplevelHandleV1DoneEvent) {
+		m.modify(func() { tl.ready = true })
+		m.updateState()
+	})
+	handle.SetClosedHandler(func(e ext_foreign_toplevel.ExtForeignToplevelHandleV1ClosedEvent) {
+		m.removeToplevel(tl.id)
+		handle.Destroy()
+		m.updateState()
+	})
+}
+
+func decodeStates(data []byte) toplevelFlags {
+	var flags toplevelFlags
+	for i := 0; i+4 <= len(data); i += 4 {
+		switch wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1State(wlclient.Uint32(data[i : i+4])) {
+		case wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateActivated:
+			flags.activated = true
+		case wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateMaximized:
+			flags.maximized = true
+		case wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateMinimized:
+			flags.minimized = true
+		case wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateFullscreen:
+			flags.fullscreen = true
+		}
+	}
+	return flags
+}
+
+func (m *Manager) protocol() Protocol {
+	switch {
+	case m.wlrManager != nil:
+		return ProtocolWlr
+	case m.extList != nil:
+		return ProtocolExt
+	default:
+		return ProtocolNone
+	}
+}
+
+func (m *Manager) GetState() State {
+	m.stateMutex.RLock()
+	defer m.stateMutex.RUnlock()
+	if m.state == nil {
+		return State{
+			Protocol:   m.protocol(),
+			CanControl: m.wlrManager != nil,
+			Toplevels:  []Toplevel{},
+			FocusOrder: []uint32{},
+		}
+	}
+	stateCopy := *m.state
+	return stateCopy
+}
+
+func (m *Manager) updateState() {
+	m.toplevelMutex.RLock()
+	toplevels := make([]Toplevel, 0, len(m.order))
+	focused := make([]*toplevelState, 0, len(m.order))
+	for _, id := range m.order {
+		tl, ok := m.toplevels[id]
+		if !ok || !tl.ready {
+			continue
+		}
+
+		outputs := make([]string, 0, len(tl.outputs))
+		for _, outputID := range tl.outputs {
+			if name, ok := m.outputNames.Load(outputID); ok {
+				outputs = append(outputs, name)
+			}
+		}
+
+		toplevels = append(toplevels, Toplevel{
+			ID:         tl.id,
+			Identifier: tl.identifier,
+			AppID:      tl.appID,
+			Title:      tl.title,
+			Outputs:    outputs,
+			Parent:     tl.parent,
+			Activated:  tl.flags.activated,
+			Maximized:  tl.flags.maximized,
+			Minimized:  tl.flags.minimized,
+			Fullscreen: tl.flags.fullscreen,
+		})
+		focused = append(focused, tl)
+	}
+	m.toplevelMutex.RUnlock()
+
+	// Most recently activated first, never-activated windows keep creation order.
+	sort.SliceStable(focused, func(a, b int) bool {
+		return focused[a].focusSeq > focused[b].focusSeq
+	})
+	focusOrder := make([]uint32, 0, len(focused))
+	for _, tl := range focused {
+		focusOrder = append(focusOrder, tl.id)
+	}
+
+	newState := State{
+		Protocol:   m.protocol(),
+		CanControl: m.wlrManager != nil,
+		Toplevels:  toplevels,
+		FocusOrder: focusOrder,
+	}
+
+	m.stateMutex.Lock()
+	m.state = &newState
+	m.stateMutex.Unlock()
+
+	m.notifySubscribers()
+}
+
+func (m *Manager) withHandle(id uint32, fn func(tl *toplevelState) error) error {
+	if m.wlrManager == nil {
+		return fmt.Errorf("window control requires %s", wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1InterfaceName)
+	}
+
+	errChan := make(chan error, 1)
+	m.post(func() {
+		m.toplevelMutex.RLock()
+		tl, ok := m.toplevels[id]
+		m.toplevelMutex.RUnlock()
+		if !ok || tl.wlr == nil {
+			errChan <- fmt.Errorf("toplevel not found: %d", id)
+			return
+		}
+		errChan <- fn(tl)
+	})
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-time.After(requestTimeout):
+		return fmt.Errorf("timed out waiting for wayland thread")
+	}
+}
+
+func (m *Manager) Activate(id uint32) error {
+	if m.seat == nil {
+		return fmt.Errorf("no seat available")
+	}
+	return m.withHandle(id, func(tl *toplevelState) error {
+		return tl.wlr.Activate(m.seat)
+	})
+}
+
+func (m *Manager) CloseToplevel(id uint32) error {
+	return m.withHandle(id, func(tl *toplevelState) error {
+		return tl.wlr.Close()
+	})
+}
+
+func (m *Manager) SetMinimized(id uint32, minimized bool) error {
+	return m.withHandle(id, func(tl *toplevelState) error {
+		if minimized {
+			return tl.wlr.SetMinimized()
+		}
+		return tl.wlr.UnsetMinimized()
+	})
+}
+
+func (m *Manager) SetMaximized(id uint32, maximized bool) error {
+	return m.withHandle(id, func(tl *toplevelState) error {
+		if maximized {
+			return tl.wlr.SetMaximized()
+		}
+		return tl.wlr.UnsetMaximized()
+	})
+}
+
+func (m *Manager) SetFullscreen(id uint32, fullscreen bool, outputName string) error {
+	return m.withHandle(id, func(tl *toplevelState) error {
+		if !fullscreen {
+			return tl.wlr.UnsetFullscreen()
+		}
+		if outputName == "" {
+			return tl.wlr.SetFullscreen(nil)
+		}
+		output := m.findOutput(outputName)
+		if output == nil {
+			return fmt.Errorf("output not found: %s", outputName)
+		}
+		return tl.wlr.SetFullscreen(output)
+	})
+}
+
+func (m *Manager) findOutput(name string) *wlclient.Output {
+	var found *wlclient.Output
+	m.outputs.Range(func(key uint32, output *wlclient.Output) bool {
+		if outputName, ok := m.outputNames.Load(output.ID()); ok && outputName == name {
+			found = output
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if ch, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(ch)
+	}
+}
+
+func (m *Manager) notifySubscribers() {
+	select {
+	case m.dirty <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Manager) notifier() {
+	defer m.notifierWg.Done()
+
+	const minGap = 100 * time.Millisecond
+	timer := time.NewTimer(minGap)
+	timer.Stop()
+	var pending bool
+
+	for {
+		select {
+		case <-m.stopChan:
+			timer.Stop()
+			return
+		case <-m.dirty:
+			if pending {
+				continue
+			}
+			pending = true
+			timer.Reset(minGap)
+		case <-timer.C:
+			if !pending {
+				continue
+			}
+
+			currentState := m.GetState()
+
+			if m.lastNotified != nil && !stateChanged(m.lastNotified, &currentState) {
+				pending = false
+				continue
+			}
+
+			m.subscribers.Range(func(key string, ch chan State) bool {
+				select {
+				case ch <- currentState:
+				default:
+					log.Warn("Toplevel: subscriber channel full, dropping update")
+				}
+				return true
+			})
+
+			stateCopy := currentState
+			m.lastNotified = &stateCopy
+			pending = false
+		}
+	}
+}
+
+func stateChanged(old, new *State) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	if old.Protocol != new.Protocol || old.CanControl != new.CanControl {
+		return true
+	}
+	if !slices.Equal(old.FocusOrder, new.FocusOrder) || len(old.Toplevels) != len(new.Toplevels) {
+		return true
+	}
+	for i := range new.Toplevels {
+		a, b := old.Toplevels[i], new.Toplevels[i]
+		if a.ID != b.ID || a.Identifier != b.Identifier || a.AppID != b.AppID || a.Title != b.Title || a.Parent != b.Parent {
+			return true
+		}
+		if a.Activated != b.Activated || a.Maximized != b.Maximized || a.Minimized != b.Minimized || a.Fullscreen != b.Fullscreen {
+			return true
+		}
+		if !slices.Equal(a.Outputs, b.Outputs) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) Close() {
+	close(m.stopChan)
+	m.notifierWg.Wait()
+
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+
+	m.toplevelMutex.Lock()
+	for _, tl := range m.toplevels {
+		if tl.wlr != nil {
+			tl.wlr.Destroy()
+		}
+		if tl.ext != nil {
+			tl.ext.Destroy()
+		}
+	}
+	m.toplevels = make(map[uint32]*toplevelState)
+	m.order = nil
+	m.toplevelMutex.Unlock()
+
+	if m.wlrManager != nil {
+		m.wlrManager.Stop()
+	}
+	if m.extList != nil {
+		m.extList.Stop()
+		m.extList.Destroy()
+	}
+
+	m.outputs.Range(func(key uint32, output *wlclient.Output) bool {
+		output.Release()
+		m.outputs.Delete(key)
+		return true
+	})
+
+	if m.registry != nil {
+		m.registry.Destroy()
+	}
+}
@@ -0,0 +1,147 @@
+package toplevel
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_foreign_toplevel"
+)
+
+func newTestManager() *Manager {
+	return &Manager{
+		toplevels: make(map[uint32]*toplevelState),
+		stopChan:  make(chan struct{}),
+		dirty:     make(chan struct{}, 1),
+	}
+}
+
+func encodeStates(states ...wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1State) []byte {
+	data := make([]byte, 4*len(states))
+	for i, s := range states {
+		binary.NativeEndian.PutUint32(data[i*4:], uint32(s))
+	}
+	return data
+}
+
+func TestDecodeStates(t *testing.T) {
+	flags := decodeStates(encodeStates(
+		wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateActivated,
+		wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateFullscreen,
+	))
+	assert.Equal(t, toplevelFlags{activated: true, fullscreen: true}, flags)
+
+	assert.Equal(t, toplevelFlags{}, decodeStates(nil))
+	assert.Equal(t, toplevelFlags{minimized: true}, decodeStates(append(
+		encodeStates(wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1StateMinimized), 0xff, 0xff)))
+}
+
+func TestManager_UpdateState(t *testing.T) {
+	m := newTestManager()
+	m.outputNames.Store(10, "DP-1")
+	m.outputNames.Store(11, "HDMI-A-1")
+
+	a := &toplevelState{appID: "firefox", title: "Mozilla Firefox", outputs: []uint32{10}, ready: true}
+	b := &toplevelState{appID: "kitty", title: "zsh", outputs: []uint32{11, 99}, ready: true, focusSeq: 2,
+		flags: toplevelFlags{activated: true}}
+	c := &toplevelState{appID: "pending"}
+	d := &toplevelState{appID: "foot", title: "htop", ready: true, focusSeq: 1}
+	m.addToplevel(a)
+	m.addToplevel(b)
+	m.addToplevel(c)
+	m.addToplevel(d)
+
+	m.updateState()
+	state := m.GetState()
+
+	require.Len(t, state.Toplevels, 3)
+	assert.Equal(t, "firefox", state.Toplevels[0].AppID)
+	assert.Equal(t, []string{"DP-1"}, state.Toplevels[0].Outputs)
+	assert.Equal(t, []string{"HDMI-A-1"}, state.Toplevels[1].Outputs)
+	assert.True(t, state.Toplevels[1].Activated)
+	assert.NotNil(t, state.Toplevels[2].Outputs)
+	assert.Equal(t, []uint32{b.id, d.id, a.id}, state.FocusOrder)
+}
+
+func TestManager_RemoveToplevel(t *testing.T) {
+	m := newTestManager()
+	a := &toplevelState{ready: true}
+	b := &toplevelState{ready: true}
+	m.addToplevel(a)
+	m.addToplevel(b)
+
+	m.removeToplevel(a.id)
+	m.updateState()
+
+	state := m.GetState()
+	require.Len(t, state.Toplevels, 1)
+	assert.Equal(t, b.id, state.Toplevels[0].ID)
+	assert.Equal(t, []uint32{b.id}, m.order)
+
+	c := &toplevelState{}
+	m.addToplevel(c)
+	assert.NotEqual(t, a.id, c.id)
+}
+
+func TestManager_GetStateNilState(t *testing.T) {
+	m := newTestManager()
+	state := m.GetState()
+	assert.Equal(t, ProtocolNone, state.Protocol)
+	assert.False(t, state.CanControl)
+	assert.NotNil(t, state.Toplevels)
+	assert.NotNil(t, state.FocusOrder)
+}
+
+func TestManager_ControlRequiresWlr(t *testing.T) {
+	m := newTestManager()
+	assert.Error(t, m.CloseToplevel(1))
+	assert.Error(t, m.SetMinimized(1, true))
+	assert.Error(t, m.SetFullscreen(1, true, ""))
+}
+
+func TestStateChanged(t *testing.T) {
+	assert.True(t, stateChanged(nil, &State{}))
+
+	a := &State{Protocol: ProtocolWlr, Toplevels: []Toplevel{{ID: 1, AppID: "kitty", Outputs: []string{"DP-1"}}}, FocusOrder: []uint32{1}}
+	b := &State{Protocol: ProtocolWlr, Toplevels: []Toplevel{{ID: 1, AppID: "kitty", Outputs: []string{"DP-1"}}}, FocusOrder: []uint32{1}}
+	assert.False(t, stateChanged(a, b))
+
+	b.Toplevels[0].Minimized = true
+	assert.True(t, stateChanged(a, b))
+
+	c := &State{Protocol: ProtocolWlr, Toplevels: []Toplevel{{ID: 1, AppID: "kitty", Outputs: []string{"HDMI-A-1"}}}, FocusOrder: []uint32{1}}
+	assert.True(t, stateChanged(a, c))
+
+	d := &State{Protocol: ProtocolWlr, Toplevels: a.Toplevels}
+	assert.True(t, stateChanged(a, d))
+}
+
+func TestToplevelID(t *testing.T) {
+	id, err := toplevelID(map[string]any{"id": float64(7)})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), id)
+
+	_, err = toplevelID(map[string]any{"id": float64(0)})
+	assert.Error(t, err)
+
+	_, err = toplevelID(map[string]any{})
+	assert.Error(t, err)
+}
+
+func TestStateWindows(t *testing.T) {
+	state := State{Toplevels: []Toplevel{
+		{ID: 3, AppID: "foot", Title: "shell", Outputs: []string{"DP-1", "HDMI-A-1"}, Activated: true},
+		{ID: 7, AppID: "firefox", Title: "web", Fullscreen: true},
+	}}
+
+	windows := state.Windows()
+	require.Len(t, windows, 2)
+	assert.Equal(t, "3", windows[0].ID)
+	assert.Equal(t, "DP-1", windows[0].Output)
+	assert.True(t, windows[0].Focused)
+	assert.Empty(t, windows[1].Output)
+	assert.True(t, windows[1].Fullscreen)
+	assert.False(t, windows[1].Focused)
+}
@@ -0,0 +1,111 @@
+package toplevel
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/ext_foreign_toplevel"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_foreign_toplevel"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/wlcontext"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowlist"
+	wlclient "github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+type Protocol string
+
+const (
+	ProtocolNone Protocol = ""
+	ProtocolWlr  Protocol = "wlr-foreign-toplevel-management"
+	ProtocolExt  Protocol = "ext-foreign-toplevel-list"
+)
+
+type Toplevel struct {
+	ID         uint32   `json:"id"`
+	Identifier string   `json:"identifier,omitempty"`
+	AppID      string   `json:"appId"`
+	Title      string   `json:"title"`
+	Outputs    []string `json:"outputs"`
+	Parent     uint32   `json:"parent,omitempty"`
+	Activated  bool     `json:"activated"`
+	Maximized  bool     `json:"maximized"`
+	Minimized  bool     `json:"minimized"`
+	Fullscreen bool     `json:"fullscreen"`
+}
+
+type State struct {
+	Protocol   Protocol   `json:"protocol"`
+	CanControl bool       `json:"canControl"`
+	Toplevels  []Toplevel `json:"toplevels"`
+	FocusOrder []uint32   `json:"focusOrder"`
+}
+
+// Windows converts the toplevels into the window list used where the
+// compositor has no window IPC. The toplevel protocols carry no workspace or
+// floating state, so those stay empty.
+func (s State) Windows() []windowlist.Window {
+	windows := make([]windowlist.Window, 0, len(s.Toplevels))
+	for _, t := range s.Toplevels {
+		w := windowlist.Window{
+			ID:         strconv.FormatUint(uint64(t.ID), 10),
+			AppID:      t.AppID,
+			Title:      t.Title,
+			Focused:    t.Activated,
+			Fullscreen: t.Fullscreen,
+		}
+		if len(t.Outputs) > 0 {
+			w.Output = t.Outputs[0]
+		}
+		windows = append(windows, w)
+	}
+	return windows
+}
+
+type toplevelFlags struct {
+	activated  bool
+	maximized  bool
+	minimized  bool
+	fullscreen bool
+}
+
+type toplevelState struct {
+	id         uint32
+	wlr        *wlr_foreign_toplevel.ZwlrForeignToplevelHandleV1
+	ext        *ext_foreign_toplevel.ExtForeignToplevelHandleV1
+	identifier string
+	appID      string
+	title      string
+	outputs    []uint32
+	parent     uint32
+	flags      toplevelFlags
+	focusSeq   uint64
+	ready      bool
+}
+
+type Manager struct {
+	wlCtx      wlcontext.WaylandContext
+	display    wlclient.WaylandDisplay
+	registry   *wlclient.Registry
+	wlrManager *wlr_foreign_toplevel.ZwlrForeignToplevelManagerV1
+	extList    *ext_foreign_toplevel.ExtForeignToplevelListV1
+	seat       *wlclient.Seat
+
+	outputs     syncmap.Map[uint32, *wlclient.Output]
+	outputNames syncmap.Map[uint32, string]
+
+	toplevelMutex sync.RWMutex
+	toplevels     map[uint32]*toplevelState
+	order         []uint32
+	nextID        uint32
+	focusSeq      uint64
+
+	stopChan chan struct{}
+
+	stateMutex sync.RWMutex
+	state      *State
+
+	subscribers  syncmap.Map[string, chan State]
+	dirty        chan struct{}
+	notifierWg   sync.WaitGroup
+	lastNotified *State
+}
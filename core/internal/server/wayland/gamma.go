@@ -165,3 +165,110 @@ func GenerateIdentityRamp(size uint32) GammaRamp {
 
 	return ramp
 }
+
+// RampParams describes the full correction applied to one output: the
+// night light temperature and gamma plus a per-channel calibration.
+type RampParams struct {
+	Temp       int
+	Gamma      float64
+	RedGain    float64
+	GreenGain  float64
+	BlueGain   float64
+	Brightness float64
+}
+
+func neutralRampParams() RampParams {
+	return RampParams{Temp: 6500, Gamma: 1.0, RedGain: 1.0, GreenGain: 1.0, BlueGain: 1.0, Brightness: 1.0}
+}
+
+func (p RampParams) isNeutral() bool {
+	return p == neutralRampParams()
+}
+
+func GenerateCorrectedRamp(size uint32, p RampParams) GammaRamp {
+	if p.isNeutral() {
+		return GenerateIdentityRamp(size)
+	}
+
+	ramp := GammaRamp{
+		Red:   make([]uint16, size),
+		Green: make([]uint16, size),
+		Blue:  make([]uint16, size),
+	}
+
+	wp := calcWhitepoint(p.Temp)
+	r := wp.r * p.RedGain * p.Brightness
+	g := wp.g * p.GreenGain * p.Brightness
+	b := wp.b * p.BlueGain * p.Brightness
+
+	for i := uint32(0); i < size; i++ {
+		val := float64(i) / float64(size-1)
+		ramp.Red[i] = uint16(clamp01(math.Pow(val*r, 1.0/p.Gamma)) * 65535.0)
+		ramp.Green[i] = uint16(clamp01(math.Pow(val*g, 1.0/p.Gamma)) * 65535.0)
+		ramp.Blue[i] = uint16(clamp01(math.Pow(val*b, 1.0/p.Gamma)) * 65535.0)
+	}
+
+	return ramp
+}
+
+// resolveRampParams computes the parameters for an output from the global
+// config and its override. pos is the sun position, or nil when no schedule
+// is available; ok is false when the output cannot be resolved yet.
+func resolveRampParams(config Config, oc OutputConfig, pos *float64) (RampParams, bool) {
+	p := neutralRampParams()
+	if oc.Mode == OutputModeOff {
+		return p, true
+	}
+
+	if oc.RedGain > 0 {
+		p.RedGain = oc.RedGain
+	}
+	if oc.GreenGain > 0 {
+		p.GreenGain = oc.GreenGain
+	}
+	if oc.BlueGain > 0 {
+		p.BlueGain = oc.BlueGain
+	}
+	if oc.Brightness > 0 {
+		p.Brightness = oc.Brightness
+	}
+
+	if oc.Mode == OutputModeStatic {
+		if oc.Temp > 0 {
+			p.Temp = oc.Temp
+		}
+		if oc.Gamma > 0 {
+			p.Gamma = oc.Gamma
+		}
+		return p, true
+	}
+
+	if !config.Enabled {
+		if oc.Gamma > 0 {
+			p.Gamma = oc.Gamma
+		}
+		return p, true
+	}
+
+	p.Gamma = config.Gamma
+	if oc.Gamma > 0 {
+		p.Gamma = oc.Gamma
+	}
+	low, high := config.LowTemp, config.HighTemp
+	if oc.LowTemp > 0 {
+		low = oc.LowTemp
+	}
+	if oc.HighTemp > 0 {
+		high = oc.HighTemp
+	}
+
+	switch {
+	case low == high:
+		p.Temp = low
+	case pos == nil:
+		return p, false
+	default:
+		p.Temp = low + int(float64(high-low)**pos)
+	}
+	return p, true
+}
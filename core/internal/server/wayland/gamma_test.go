@@ -118,3 +118,87 @@ func TestClamp(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerateCorrectedRamp(t *testing.T) {
+	const size = 256
+	last := size - 1
+
+	identity := GenerateIdentityRamp(size)
+	neutral := GenerateCorrectedRamp(size, neutralRampParams())
+	for i := 0; i < size; i++ {
+		if neutral.Red[i] != identity.Red[i] || neutral.Blue[i] != identity.Blue[i] {
+			t.Fatalf("neutral params should produce identity ramp at index %d", i)
+		}
+	}
+
+	p := neutralRampParams()
+	p.RedGain = 0.5
+	ramp := GenerateCorrectedRamp(size, p)
+	if ramp.Red[last] >= ramp.Green[last] {
+		t.Errorf("red gain 0.5 should reduce red, got R:%d G:%d", ramp.Red[last], ramp.Green[last])
+	}
+	if ramp.Green[last] != identity.Green[last] {
+		t.Errorf("green should be unaffected, got %d", ramp.Green[last])
+	}
+
+	p = neutralRampParams()
+	p.Brightness = 0.5
+	ramp = GenerateCorrectedRamp(size, p)
+	if got := ramp.Blue[last]; got < 32000 || got > 33000 {
+		t.Errorf("brightness 0.5 should halve the top value, got %d", got)
+	}
+
+	p = neutralRampParams()
+	p.Temp = 3000
+	ramp = GenerateCorrectedRamp(size, p)
+	warm := GenerateGammaRamp(size, 3000, 1.0)
+	if ramp.Blue[last] != warm.Blue[last] {
+		t.Errorf("temperature-only params should match GenerateGammaRamp, got %d want %d", ramp.Blue[last], warm.Blue[last])
+	}
+}
+
+func TestResolveRampParams(t *testing.T) {
+	config := DefaultConfig()
+	config.Enabled = true
+	night := 0.0
+	half := 0.5
+
+	tests := []struct {
+		name     string
+		config   Config
+		oc       OutputConfig
+		pos      *float64
+		wantTemp int
+		wantOK   bool
+	}{
+		{"follow_global_night", config, OutputConfig{}, &night, 4000, true},
+		{"follow_override_range", config, OutputConfig{LowTemp: 3000, HighTemp: 5000}, &half, 4000, true},
+		{"follow_no_schedule", config, OutputConfig{}, nil, 0, false},
+		{"static_ignores_sun", config, OutputConfig{Mode: OutputModeStatic, Temp: 5000}, &night, 5000, true},
+		{"static_default_temp", config, OutputConfig{Mode: OutputModeStatic}, nil, 6500, true},
+		{"off_is_neutral", config, OutputConfig{Mode: OutputModeOff, RedGain: 0.5}, &night, 6500, true},
+		{"disabled_is_neutral", DefaultConfig(), OutputConfig{}, &night, 6500, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := resolveRampParams(tt.config, tt.oc, tt.pos)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && p.Temp != tt.wantTemp {
+				t.Errorf("temp = %d, want %d", p.Temp, tt.wantTemp)
+			}
+		})
+	}
+
+	p, _ := resolveRampParams(config, OutputConfig{Mode: OutputModeOff, RedGain: 0.5}, &night)
+	if !p.isNeutral() {
+		t.Errorf("off mode should be neutral, got %+v", p)
+	}
+
+	p, _ = resolveRampParams(DefaultConfig(), OutputConfig{BlueGain: 0.8, Brightness: 0.7}, nil)
+	if p.BlueGain != 0.8 || p.Brightness != 0.7 || p.Temp != 6500 {
+		t.Errorf("calibration should apply with night light disabled, got %+v", p)
+	}
+}
@@ -31,6 +31,8 @@ func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
 		handleSetGamma(conn, req, manager)
 	case "wayland.gamma.setEnabled":
 		handleSetEnabled(conn, req, manager)
+	case "wayland.gamma.setOutputConfig":
+		handleSetOutputConfig(conn, req, manager)
 	case "wayland.gamma.subscribe":
 		handleSubscribe(conn, req, manager)
 	default:
@@ -159,6 +161,42 @@ func handleSetEnabled(conn net.Conn, req models.Request, manager *Manager) {
 	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "enabled state set"})
 }
 
+func handleSetOutputConfig(conn net.Conn, req models.Request, manager *Manager) {
+	output, err := params.StringNonEmpty(req.Params, "output")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	if params.BoolOpt(req.Params, "clear", false) {
+		if err := manager.SetOutputConfig(output, nil); err != nil {
+			models.RespondError(conn, req.ID, err.Error())
+			return
+		}
+		models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "output config cleared"})
+		return
+	}
+
+	oc := &OutputConfig{
+		Mode:       OutputMode(params.StringOpt(req.Params, "mode", "")),
+		LowTemp:    int(params.FloatOpt(req.Params, "lowTemp", 0)),
+		HighTemp:   int(params.FloatOpt(req.Params, "highTemp", 0)),
+		Temp:       int(params.FloatOpt(req.Params, "temp", 0)),
+		Gamma:      params.FloatOpt(req.Params, "gamma", 0),
+		RedGain:    params.FloatOpt(req.Params, "redGain", 0),
+		GreenGain:  params.FloatOpt(req.Params, "greenGain", 0),
+		BlueGain:   params.FloatOpt(req.Params, "blueGain", 0),
+		Brightness: params.FloatOpt(req.Params, "brightness", 0),
+	}
+
+	if err := manager.SetOutputConfig(output, oc); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "output config set"})
+}
+
 func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
 	clientID := fmt.Sprintf("client-%p", conn)
 	stateChan := manager.Subscribe(clientID)
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"maps"
 	"os"
+	"sort"
 	"syscall"
 	"time"
 
@@ -68,7 +70,7 @@ func NewManager(display wlclient.WaylandDisplay, config Config) (*Manager, error
 	m.wg.Add(1)
 	go m.waylandActor()
 
-	if config.Enabled {
+	if config.needsControls() {
 		m.post(func() {
 			log.Info("Gamma control enabled at startup")
 			gammaMgr := m.gammaControl.(*wlr_gamma_control.ZwlrGammaControlManagerV1)
@@ -166,17 +168,14 @@ func (m *Manager) setupRegistry() error {
 			outputID := output.ID()
 			output.SetNameHandler(func(ev wlclient.OutputNameEvent) {
 				outputNames[outputID] = ev.Name
+				m.outputNames.Store(outputID, ev.Name)
 			})
 			if gammaMgr != nil {
 				outputs = append(outputs, output)
 			}
 			m.outputRegNames.Store(outputID, e.Name)
 
-			m.configMutex.RLock()
-			enabled := m.config.Enabled
-			m.configMutex.RUnlock()
-
-			if enabled && m.controlsInitialized {
+			if m.controlsWanted() && m.controlsInitialized {
 				m.post(func() {
 					if err := m.addOutputControl(output); err != nil {
 						log.Warnf("Failed to add output control: %v", err)
@@ -205,6 +204,7 @@ func (m *Manager) setupRegistry() error {
 				foundOut.gammaControl.(*wlr_gamma_control.ZwlrGammaControlV1).Destroy()
 			}
 			m.outputs.Delete(foundID)
+			m.outputNames.Delete(foundID)
 
 			hasOutputs := false
 			m.outputs.Range(func(_ uint32, _ *outputState) bool {
@@ -275,8 +275,8 @@ func (m *Manager) setupControlHandlers(state *outputState, control *wlr_gamma_co
 				out.rampSize = size
 				out.failed = false
 				out.retryCount = 0
+				out.applied = nil
 			}
-			m.lastAppliedTemp = 0
 			m.applyCurrentTemp("gamma_size")
 		})
 	})
@@ -289,6 +289,7 @@ func (m *Manager) setupControlHandlers(state *outputState, control *wlr_gamma_co
 			}
 			out.failed = true
 			out.rampSize = 0
+			out.applied = nil
 			out.retryCount++
 			out.lastFailTime = time.Now()
 
@@ -323,12 +324,14 @@ func (m *Manager) addOutputControl(output *wlclient.Output) error {
 	return nil
 }
 
-func (m *Manager) recreateOutputControl(out *outputState) error {
+func (m *Manager) controlsWanted() bool {
 	m.configMutex.RLock()
-	enabled := m.config.Enabled
-	m.configMutex.RUnlock()
+	defer m.configMutex.RUnlock()
+	return m.config.needsControls()
+}
 
-	if !enabled || !m.controlsInitialized {
+func (m *Manager) recreateOutputControl(out *outputState) error {
+	if !m.controlsWanted() || !m.controlsInitialized {
 		return nil
 	}
 	if _, ok := m.outputs.Load(out.id); !ok {
@@ -516,6 +519,11 @@ func (m *Manager) getTempFromPosition(pos float64) int {
 	return low + int(float64(high-low)*pos)
 }
 
+func (m *Manager) outputConfig(config Config, id uint32) (string, OutputConfig) {
+	name, _ := m.outputNames.Load(id)
+	return name, config.OutputConfigs[name]
+}
+
 func (m *Manager) getNextDeadline(now time.Time) time.Time {
 	m.scheduleMutex.RLock()
 	sched := m.schedule
@@ -583,11 +591,7 @@ func (m *Manager) tomorrow(now time.Time) time.Time {
 func (m *Manager) schedulerLoop() {
 	defer m.wg.Done()
 
-	m.configMutex.RLock()
-	enabled := m.config.Enabled
-	m.configMutex.RUnlock()
-
-	if enabled {
+	if m.controlsWanted() {
 		m.post(func() { m.applyCurrentTemp("startup") })
 	}
 
@@ -627,17 +631,11 @@ func (m *Manager) schedulerLoop() {
 			m.scheduleMutex.Unlock()
 			m.recalcSchedule(time.Now())
 			m.updateStateFromSchedule()
-			m.configMutex.RLock()
-			enabled := m.config.Enabled
-			m.configMutex.RUnlock()
-			if enabled {
+			if m.controlsWanted() {
 				m.post(func() { m.applyCurrentTemp("updateTrigger") })
 			}
 		case <-timer.C:
-			m.configMutex.RLock()
-			enabled := m.config.Enabled
-			m.configMutex.RUnlock()
-			if enabled {
+			if m.controlsWanted() {
 				m.post(func() { m.applyCurrentTemp("timer") })
 			}
 		}
@@ -652,42 +650,25 @@ func (m *Manager) applyCurrentTemp(_ string) {
 	// Ensure schedule is up-to-date (handles display wake after overnight sleep)
 	m.recalcSchedule(time.Now())
 
-	m.configMutex.RLock()
-	low, high := m.config.LowTemp, m.config.HighTemp
-	m.configMutex.RUnlock()
-
-	if low == high {
-		m.applyGamma(low)
-		m.updateStateFromSchedule()
-		return
+	var pos *float64
+	if m.hasValidSchedule() {
+		p := m.getSunPosition(time.Now())
+		pos = &p
 	}
 
-	if !m.hasValidSchedule() {
-		m.updateStateFromSchedule()
-		return
-	}
-
-	now := time.Now()
-	pos := m.getSunPosition(now)
-	temp := m.getTempFromPosition(pos)
-
-	m.applyGamma(temp)
+	m.applyGamma(pos)
 	m.updateStateFromSchedule()
 }
 
-func (m *Manager) applyGamma(temp int) {
+func (m *Manager) applyGamma(pos *float64) {
 	m.configMutex.RLock()
-	gamma := m.config.Gamma
+	config := m.config
 	m.configMutex.RUnlock()
 
 	if !m.controlsInitialized {
 		return
 	}
 
-	if m.lastAppliedTemp == temp && m.lastAppliedGamma == gamma {
-		return
-	}
-
 	var outs []*outputState
 	m.outputs.Range(func(_ uint32, out *outputState) bool {
 		outs = append(outs, out)
@@ -698,8 +679,9 @@ func (m *Manager) applyGamma(temp int) {
 	}
 
 	type job struct {
-		out  *outputState
-		data []byte
+		out    *outputState
+		params RampParams
+		data   []byte
 	}
 	var jobs []job
 
@@ -707,7 +689,15 @@ func (m *Manager) applyGamma(temp int) {
 		if out.failed || out.rampSize == 0 {
 			continue
 		}
-		ramp := GenerateGammaRamp(out.rampSize, temp, gamma)
+		_, oc := m.outputConfig(config, out.id)
+		params, ok := resolveRampParams(config, oc, pos)
+		if !ok {
+			continue
+		}
+		if out.applied != nil && *out.applied == params {
+			continue
+		}
+		ramp := GenerateCorrectedRamp(out.rampSize, params)
 		buf := bytes.NewBuffer(make([]byte, 0, int(out.rampSize)*6))
 		for _, v := range ramp.Red {
 			binary.Write(buf, binary.LittleEndian, v)
@@ -718,7 +708,7 @@ func (m *Manager) applyGamma(temp int) {
 		for _, v := range ramp.Blue {
 			binary.Write(buf, binary.LittleEndian, v)
 		}
-		jobs = append(jobs, job{out: out, data: buf.Bytes()})
+		jobs = append(jobs, job{out: out, params: params, data: buf.Bytes()})
 	}
 
 	for _, j := range jobs {
@@ -726,6 +716,7 @@ func (m *Manager) applyGamma(temp int) {
 			log.Warnf("gamma: failed to set output %d: %v", j.out.id, err)
 			j.out.failed = true
 			j.out.rampSize = 0
+			j.out.applied = nil
 			outID := j.out.id
 			time.AfterFunc(300*time.Millisecond, func() {
 				m.post(func() {
@@ -734,11 +725,10 @@ func (m *Manager) applyGamma(temp int) {
 					}
 				})
 			})
+			continue
 		}
+		j.out.applied = &j.params
 	}
-
-	m.lastAppliedTemp = temp
-	m.lastAppliedGamma = gamma
 }
 
 func (m *Manager) setGammaBytes(out *outputState, data []byte) error {
@@ -796,6 +786,28 @@ func (m *Manager) updateStateFromSchedule() {
 		isDay = now.After(times.Sunrise) && now.Before(times.Sunset)
 	}
 
+	var outputs []OutputStatus
+	m.outputs.Range(func(id uint32, _ *outputState) bool {
+		name, oc := m.outputConfig(config, id)
+		if name == "" {
+			return true
+		}
+		params, _ := resolveRampParams(config, oc, &pos)
+		mode := oc.Mode
+		if mode == "" {
+			mode = OutputModeFollow
+		}
+		outputs = append(outputs, OutputStatus{
+			Name:        name,
+			Mode:        mode,
+			CurrentTemp: params.Temp,
+			Gamma:       params.Gamma,
+			Brightness:  params.Brightness,
+		})
+		return true
+	})
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Name < outputs[j].Name })
+
 	newState := State{
 		Config:         config,
 		CurrentTemp:    temp,
@@ -806,6 +818,7 @@ func (m *Manager) updateStateFromSchedule() {
 		NightTime:      times.Night,
 		IsDay:          isDay,
 		SunPosition:    pos,
+		Outputs:        outputs,
 	}
 
 	m.stateMutex.Lock()
@@ -882,18 +895,12 @@ func (m *Manager) handleDBusSignal(sig *dbus.Signal) {
 	if !ok || preparing {
 		return
 	}
-	m.configMutex.RLock()
-	enabled := m.config.Enabled
-	m.configMutex.RUnlock()
-	if !enabled {
+	if !m.controlsWanted() {
 		return
 	}
 	time.AfterFunc(500*time.Millisecond, func() {
 		m.post(func() {
-			m.configMutex.RLock()
-			stillEnabled := m.config.Enabled
-			m.configMutex.RUnlock()
-			if !stillEnabled || !m.controlsInitialized {
+			if !m.controlsWanted() || !m.controlsInitialized {
 				return
 			}
 			m.outputs.Range(func(_ uint32, out *outputState) bool {
@@ -903,6 +910,7 @@ func (m *Manager) handleDBusSignal(sig *dbus.Signal) {
 				}
 				out.retryCount = 0
 				out.failed = false
+				out.applied = nil
 				m.recreateOutputControl(out)
 				return true
 			})
@@ -1035,17 +1043,47 @@ func (m *Manager) SetGamma(gamma float64) error {
 
 func (m *Manager) SetEnabled(enabled bool) {
 	m.configMutex.Lock()
-	wasEnabled := m.config.Enabled
-	if wasEnabled == enabled {
+	if m.config.Enabled == enabled {
 		m.configMutex.Unlock()
 		return
 	}
 	m.config.Enabled = enabled
-	highTemp := m.config.HighTemp
+	want := m.config.needsControls()
+	m.configMutex.Unlock()
+
+	m.syncControls(want)
+}
+
+// SetOutputConfig installs an override for the named output; nil removes it.
+func (m *Manager) SetOutputConfig(name string, oc *OutputConfig) error {
+	if oc != nil {
+		if err := oc.Validate(); err != nil {
+			return err
+		}
+	}
+
+	m.configMutex.Lock()
+	configs := maps.Clone(m.config.OutputConfigs)
+	if configs == nil {
+		configs = make(map[string]OutputConfig)
+	}
+	if oc == nil {
+		delete(configs, name)
+	} else {
+		configs[name] = *oc
+	}
+	m.config.OutputConfigs = configs
+	want := m.config.needsControls()
+	config := m.config
 	m.configMutex.Unlock()
 
+	m.syncControls(want)
+	return SaveConfig(config)
+}
+
+func (m *Manager) syncControls(want bool) {
 	switch {
-	case enabled && !m.controlsInitialized:
+	case want && !m.controlsInitialized:
 		m.post(func() {
 			gammaMgr := m.gammaControl.(*wlr_gamma_control.ZwlrGammaControlManagerV1)
 			if err := m.setupOutputControls(m.availableOutputs, gammaMgr); err != nil {
@@ -1055,9 +1093,9 @@ func (m *Manager) SetEnabled(enabled bool) {
 			m.controlsInitialized = true
 			m.triggerUpdate()
 		})
-	case enabled && !wasEnabled:
+	case want:
 		m.triggerUpdate()
-	case !enabled && m.controlsInitialized:
+	case m.controlsInitialized:
 		m.post(func() {
 			m.outputs.Range(func(id uint32, out *outputState) bool {
 				if out.gammaControl != nil {
@@ -1071,7 +1109,6 @@ func (m *Manager) SetEnabled(enabled bool) {
 			})
 			m.controlsInitialized = false
 		})
-		_ = highTemp
 	}
 }
 
@@ -1,7 +1,12 @@
 package wayland
 
 import (
+	"encoding/json"
+	"maps"
 	"math"
+	"os"
+	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -33,18 +38,53 @@ type Config struct {
 	Enabled           bool
 	ElevationTwilight float64
 	ElevationDaylight float64
+	OutputConfigs     map[string]OutputConfig
+}
+
+type OutputMode string
+
+const (
+	OutputModeFollow OutputMode = "follow"
+	OutputModeStatic OutputMode = "static"
+	OutputModeOff    OutputMode = "off"
+)
+
+// OutputConfig overrides the global settings for a single output. Zero
+// values fall back to the global config (follow mode) or to neutral.
+// Static mode applies Temp and the calibration regardless of the sun
+// position; off applies a neutral ramp, ignoring night mode and the
+// per-output gains.
+type OutputConfig struct {
+	Mode       OutputMode `json:"mode,omitempty"`
+	LowTemp    int        `json:"lowTemp,omitempty"`
+	HighTemp   int        `json:"highTemp,omitempty"`
+	Temp       int        `json:"temp,omitempty"`
+	Gamma      float64    `json:"gamma,omitempty"`
+	RedGain    float64    `json:"redGain,omitempty"`
+	GreenGain  float64    `json:"greenGain,omitempty"`
+	BlueGain   float64    `json:"blueGain,omitempty"`
+	Brightness float64    `json:"brightness,omitempty"`
+}
+
+type OutputStatus struct {
+	Name        string     `json:"name"`
+	Mode        OutputMode `json:"mode"`
+	CurrentTemp int        `json:"currentTemp"`
+	Gamma       float64    `json:"gamma"`
+	Brightness  float64    `json:"brightness"`
 }
 
 type State struct {
-	Config         Config    `json:"config"`
-	CurrentTemp    int       `json:"currentTemp"`
-	NextTransition time.Time `json:"nextTransition"`
-	SunriseTime    time.Time `json:"sunriseTime"`
-	SunsetTime     time.Time `json:"sunsetTime"`
-	DawnTime       time.Time `json:"dawnTime"`
-	NightTime      time.Time `json:"nightTime"`
-	IsDay          bool      `json:"isDay"`
-	SunPosition    float64   `json:"sunPosition"`
+	Config         Config         `json:"config"`
+	CurrentTemp    int            `json:"currentTemp"`
+	NextTransition time.Time      `json:"nextTransition"`
+	SunriseTime    time.Time      `json:"sunriseTime"`
+	SunsetTime     time.Time      `json:"sunsetTime"`
+	DawnTime       time.Time      `json:"dawnTime"`
+	NightTime      time.Time      `json:"nightTime"`
+	IsDay          bool           `json:"isDay"`
+	SunPosition    float64        `json:"sunPosition"`
+	Outputs        []OutputStatus `json:"outputs"`
 }
 
 type cmd struct {
@@ -70,6 +110,7 @@ type Manager struct {
 	registry            *wlclient.Registry
 	gammaControl        any
 	availableOutputs    []*wlclient.Output
+	outputNames         syncmap.Map[uint32, string]
 	outputRegNames      syncmap.Map[uint32, uint32]
 	outputs             syncmap.Map[uint32, *outputState]
 	controlsInitialized bool
@@ -96,9 +137,6 @@ type Manager struct {
 
 	dbusConn   *dbus.Conn
 	dbusSignal chan *dbus.Signal
-
-	lastAppliedTemp  int
-	lastAppliedGamma float64
 }
 
 type outputState struct {
@@ -111,6 +149,7 @@ type outputState struct {
 	isVirtual    bool
 	retryCount   int
 	lastFailTime time.Time
+	applied      *RampParams
 }
 
 func DefaultConfig() Config {
@@ -125,6 +164,68 @@ func DefaultConfig() Config {
 	}
 }
 
+// savedConfig is the part of Config the daemon persists. The global night
+// light settings are pushed by the shell on every start.
+type savedConfig struct {
+	OutputConfigs map[string]OutputConfig `json:"outputConfigs"`
+}
+
+func getConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "gamma.json"), nil
+}
+
+func LoadConfig() Config {
+	cfg := DefaultConfig()
+
+	path, err := getConfigPath()
+	if err != nil {
+		return cfg
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg
+	}
+
+	var saved savedConfig
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return cfg
+	}
+
+	for name, oc := range saved.OutputConfigs {
+		if oc.Validate() != nil {
+			continue
+		}
+		if cfg.OutputConfigs == nil {
+			cfg.OutputConfigs = make(map[string]OutputConfig)
+		}
+		cfg.OutputConfigs[name] = oc
+	}
+	return cfg
+}
+
+func SaveConfig(cfg Config) error {
+	path, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(savedConfig{OutputConfigs: cfg.OutputConfigs}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
 func (c *Config) Validate() error {
 	if c.LowTemp < 1000 || c.LowTemp > 10000 {
 		return errdefs.ErrInvalidTemperature
@@ -150,9 +251,70 @@ func (c *Config) Validate() error {
 	if (c.ManualSunrise != nil) != (c.ManualSunset != nil) {
 		return errdefs.ErrInvalidManualTimes
 	}
+	for _, oc := range c.OutputConfigs {
+		if err := oc.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *OutputConfig) Validate() error {
+	switch c.Mode {
+	case "", OutputModeFollow, OutputModeStatic, OutputModeOff:
+	default:
+		return errdefs.ErrInvalidOutputMode
+	}
+	for _, t := range []int{c.LowTemp, c.HighTemp, c.Temp} {
+		if t != 0 && (t < 1000 || t > 10000) {
+			return errdefs.ErrInvalidTemperature
+		}
+	}
+	if c.LowTemp != 0 && c.HighTemp != 0 && c.LowTemp > c.HighTemp {
+		return errdefs.ErrInvalidTemperature
+	}
+	if c.Gamma < 0 || c.Gamma > 10 {
+		return errdefs.ErrInvalidGamma
+	}
+	for _, g := range []float64{c.RedGain, c.GreenGain, c.BlueGain} {
+		if g < 0 || g > 2 {
+			return errdefs.ErrInvalidGain
+		}
+	}
+	if c.Brightness != 0 && (c.Brightness < 0.1 || c.Brightness > 1) {
+		return errdefs.ErrInvalidBrightness
+	}
 	return nil
 }
 
+// calibrated reports whether the output needs a gamma control even while
+// night light is disabled.
+func (c *OutputConfig) calibrated() bool {
+	if c.Mode == OutputModeStatic {
+		return true
+	}
+	if c.Mode == OutputModeOff {
+		return false
+	}
+	return (c.Gamma != 0 && c.Gamma != 1) ||
+		(c.RedGain != 0 && c.RedGain != 1) ||
+		(c.GreenGain != 0 && c.GreenGain != 1) ||
+		(c.BlueGain != 0 && c.BlueGain != 1) ||
+		(c.Brightness != 0 && c.Brightness != 1)
+}
+
+func (c *Config) needsControls() bool {
+	if c.Enabled {
+		return true
+	}
+	for _, oc := range c.OutputConfigs {
+		if oc.calibrated() {
+			return true
+		}
+	}
+	return false
+}
+
 func (m *Manager) GetState() State {
 	m.stateMutex.RLock()
 	defer m.stateMutex.RUnlock()
@@ -206,5 +368,11 @@ func stateChanged(old, new *State) bool {
 	if old.SunPosition != new.SunPosition {
 		return true
 	}
+	if !slices.Equal(old.Outputs, new.Outputs) {
+		return true
+	}
+	if !maps.Equal(old.Config.OutputConfigs, new.Config.OutputConfigs) {
+		return true
+	}
 	return false
 }
@@ -208,6 +208,62 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
+func TestOutputConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		oc      OutputConfig
+		wantErr bool
+	}{
+		{"zero", OutputConfig{}, false},
+		{"static", OutputConfig{Mode: OutputModeStatic, Temp: 5500, RedGain: 1.1, Brightness: 0.8}, false},
+		{"follow_range", OutputConfig{Mode: OutputModeFollow, LowTemp: 3000, HighTemp: 6000}, false},
+		{"bad_mode", OutputConfig{Mode: "night"}, true},
+		{"bad_temp", OutputConfig{Temp: 500}, true},
+		{"inverted_range", OutputConfig{LowTemp: 6000, HighTemp: 3000}, true},
+		{"bad_gain", OutputConfig{GreenGain: 2.5}, true},
+		{"negative_gain", OutputConfig{BlueGain: -1}, true},
+		{"bad_brightness", OutputConfig{Brightness: 0.05}, true},
+		{"bad_gamma", OutputConfig{Gamma: 11}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.oc.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	config := DefaultConfig()
+	config.OutputConfigs = map[string]OutputConfig{"DP-1": {Mode: "bogus"}}
+	if err := config.Validate(); err == nil {
+		t.Error("Config.Validate() should reject invalid output configs")
+	}
+}
+
+func TestConfigNeedsControls(t *testing.T) {
+	config := DefaultConfig()
+	if config.needsControls() {
+		t.Error("default config should not need controls")
+	}
+
+	config.OutputConfigs = map[string]OutputConfig{"DP-1": {Mode: OutputModeOff}, "DP-2": {RedGain: 1}}
+	if config.needsControls() {
+		t.Error("off and neutral overrides should not need controls")
+	}
+
+	config.OutputConfigs["DP-2"] = OutputConfig{Brightness: 0.8}
+	if !config.needsControls() {
+		t.Error("calibrated output should need controls")
+	}
+
+	config.OutputConfigs = map[string]OutputConfig{"DP-1": {Mode: OutputModeStatic}}
+	if !config.needsControls() {
+		t.Error("static output should need controls")
+	}
+}
+
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
 
@@ -328,3 +384,24 @@ func floatPtr(f float64) *float64 {
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
+
+func TestSaveLoadOutputConfigs(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	config := DefaultConfig()
+	config.OutputConfigs = map[string]OutputConfig{
+		"DP-1":     {Mode: OutputModeStatic, Temp: 5500, RedGain: 0.95},
+		"HDMI-A-1": {Mode: OutputModeOff},
+	}
+	if err := SaveConfig(config); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	loaded := LoadConfig()
+	if len(loaded.OutputConfigs) != 2 || loaded.OutputConfigs["DP-1"] != config.OutputConfigs["DP-1"] {
+		t.Errorf("Output configs not restored: %+v", loaded.OutputConfigs)
+	}
+	if loaded.LowTemp != DefaultConfig().LowTemp {
+		t.Errorf("Global settings should stay at their defaults, got LowTemp %d", loaded.LowTemp)
+	}
+}
@@ -0,0 +1,64 @@
+package windowrules
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/toplevel"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowlist"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowrules"
+)
+
+// HandleRequest serves windowrules.* methods. toplevels may be nil; it
+// supplies the window list on compositors without a window IPC.
+func HandleRequest(conn net.Conn, req models.Request, toplevels *toplevel.Manager) {
+	switch req.Method {
+	case "windowrules.test":
+		handleTest(conn, req, toplevels)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleTest(conn net.Conn, req models.Request, toplevels *toplevel.Manager) {
+	criteriaParam, ok := params.Any(req.Params, "matchCriteria")
+	if !ok {
+		models.RespondError(conn, req.ID, "missing 'matchCriteria' parameter")
+		return
+	}
+
+	criteriaJSON, err := json.Marshal(criteriaParam)
+	if err != nil {
+		models.RespondError(conn, req.ID, "invalid 'matchCriteria' parameter format")
+		return
+	}
+
+	var criteria windowrules.MatchCriteria
+	if err := json.Unmarshal(criteriaJSON, &criteria); err != nil {
+		models.RespondError(conn, req.ID, fmt.Sprintf("invalid matchCriteria: %v", err))
+		return
+	}
+
+	var fallback func() ([]windowlist.Window, error)
+	if toplevels != nil {
+		fallback = func() ([]windowlist.Window, error) {
+			return toplevels.GetState().Windows(), nil
+		}
+	}
+
+	compositor, windows, err := windowrules.LiveWindows(fallback)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	result, err := windowrules.Test(params.StringOpt(req.Params, "compositor", compositor), criteria, windows)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
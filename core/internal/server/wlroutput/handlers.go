@@ -6,9 +6,12 @@ import (
 	"net"
 	"time"
 
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/compositor"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/config"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_output_management"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
 )
 
 type HeadConfig struct {
@@ -44,6 +47,10 @@ func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
 		handleApplyConfiguration(conn, req, manager, false)
 	case "wlroutput.testConfiguration":
 		handleApplyConfiguration(conn, req, manager, true)
+	case "wlroutput.previewConfig":
+		handleWriteConfig(conn, req, manager, true)
+	case "wlroutput.writeConfig":
+		handleWriteConfig(conn, req, manager, false)
 	case "wlroutput.subscribe":
 		handleSubscribe(conn, req, manager)
 	default:
@@ -86,6 +93,23 @@ func handleApplyConfiguration(conn net.Conn, req models.Request, manager *Manage
 	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: msg})
 }
 
+func handleWriteConfig(conn net.Conn, req models.Request, manager *Manager, preview bool) {
+	name := params.StringOpt(req.Params, "compositor", compositor.Detect().String())
+	outputs := manager.GetState().OutputSettings()
+
+	write := config.WriteOutputs
+	if preview {
+		write = config.PreviewOutputs
+	}
+
+	result, err := write(name, outputs)
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, result)
+}
+
 func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
 	clientID := fmt.Sprintf("client-%p", conn)
 	stateChan := manager.Subscribe(clientID)
@@ -3,6 +3,7 @@ package wlroutput
 import (
 	"sync"
 
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/config"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/proto/wlr_output_management"
 	wlclient "github.com/AvengeMedia/DankMaterialShell/core/pkg/go-wayland/wayland/client"
 	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
@@ -118,6 +119,30 @@ func (m *Manager) GetState() State {
 	return stateCopy
 }
 
+// OutputSettings converts the state into the form persisted to the
+// compositor's outputs file.
+func (s State) OutputSettings() []config.OutputSettings {
+	settings := make([]config.OutputSettings, 0, len(s.Outputs))
+	for _, o := range s.Outputs {
+		out := config.OutputSettings{
+			Name:         o.Name,
+			Enabled:      o.Enabled,
+			X:            o.X,
+			Y:            o.Y,
+			Scale:        o.Scale,
+			Transform:    o.Transform,
+			AdaptiveSync: o.AdaptiveSyncSupported && o.AdaptiveSync == 1,
+		}
+		if o.CurrentMode != nil {
+			out.Width = o.CurrentMode.Width
+			out.Height = o.CurrentMode.Height
+			out.Refresh = o.CurrentMode.Refresh
+		}
+		settings = append(settings, out)
+	}
+	return settings
+}
+
 func (m *Manager) Subscribe(id string) chan State {
 	ch := make(chan State, 64)
 
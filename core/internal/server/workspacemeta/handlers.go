@@ -0,0 +1,157 @@
+package workspacemeta
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/models"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/server/params"
+)
+
+func HandleRequest(conn net.Conn, req models.Request, manager *Manager) {
+	if manager == nil {
+		models.RespondError(conn, req.ID, "workspacemeta manager not initialized")
+		return
+	}
+
+	switch req.Method {
+	case "workspacemeta.getState":
+		models.Respond(conn, req.ID, manager.GetState())
+	case "workspacemeta.setMeta":
+		handleSetMeta(conn, req, manager)
+	case "workspacemeta.setAssignments":
+		handleSetAssignments(conn, req, manager)
+	case "workspacemeta.resolveApp":
+		handleResolveApp(conn, req, manager)
+	case "workspacemeta.activateForApp":
+		handleActivateForApp(conn, req, manager)
+	case "workspacemeta.subscribe":
+		handleSubscribe(conn, req, manager)
+	default:
+		models.RespondError(conn, req.ID, fmt.Sprintf("unknown method: %s", req.Method))
+	}
+}
+
+func handleSetMeta(conn net.Conn, req models.Request, manager *Manager) {
+	coords, err := parseCoordinates(req.Params, "coordinates")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	manager.SetMeta(params.StringOpt(req.Params, "output", AnyOutput), coords, Meta{
+		Label: params.StringOpt(req.Params, "label", ""),
+		Icon:  params.StringOpt(req.Params, "icon", ""),
+		Color: params.StringOpt(req.Params, "color", ""),
+	})
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "workspace metadata set"})
+}
+
+func handleSetAssignments(conn net.Conn, req models.Request, manager *Manager) {
+	raw, err := params.Get[[]any](req.Params, "assignments")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	assignments := make([]Assignment, 0, len(raw))
+	for i, item := range raw {
+		obj, ok := item.(map[string]any)
+		if !ok {
+			models.RespondError(conn, req.ID, fmt.Sprintf("assignment %d: expected object", i))
+			return
+		}
+		coords, err := parseCoordinates(obj, "coordinates")
+		if err != nil {
+			models.RespondError(conn, req.ID, fmt.Sprintf("assignment %d: %v", i, err))
+			return
+		}
+		assignments = append(assignments, Assignment{
+			AppID:       params.StringOpt(obj, "appId", ""),
+			Output:      params.StringOpt(obj, "output", ""),
+			Coordinates: coords,
+		})
+	}
+
+	if err := manager.SetAssignments(assignments); err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+	models.Respond(conn, req.ID, models.SuccessResult{Success: true, Message: "assignments set"})
+}
+
+func handleResolveApp(conn net.Conn, req models.Request, manager *Manager) {
+	appID, err := params.StringNonEmpty(req.Params, "appId")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	assignment, ok := manager.ResolveApp(appID)
+	if !ok {
+		models.Respond[*Assignment](conn, req.ID, nil)
+		return
+	}
+	models.Respond(conn, req.ID, &assignment)
+}
+
+func handleActivateForApp(conn net.Conn, req models.Request, manager *Manager) {
+	appID, err := params.StringNonEmpty(req.Params, "appId")
+	if err != nil {
+		models.RespondError(conn, req.ID, err.Error())
+		return
+	}
+
+	assignment, ok, err := manager.ActivateForApp(appID)
+	switch {
+	case err != nil:
+		models.RespondError(conn, req.ID, err.Error())
+	case !ok:
+		models.Respond[*Assignment](conn, req.ID, nil)
+	default:
+		models.Respond(conn, req.ID, &assignment)
+	}
+}
+
+func parseCoordinates(p map[string]any, key string) ([]uint32, error) {
+	raw, err := params.Get[[]any](p, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("'%s' must not be empty", key)
+	}
+
+	coords := make([]uint32, 0, len(raw))
+	for _, v := range raw {
+		f, ok := v.(float64)
+		if !ok || f < 0 || f != float64(uint32(f)) {
+			return nil, fmt.Errorf("invalid '%s' parameter", key)
+		}
+		coords = append(coords, uint32(f))
+	}
+	return coords, nil
+}
+
+func handleSubscribe(conn net.Conn, req models.Request, manager *Manager) {
+	clientID := fmt.Sprintf("client-%p", conn)
+	stateChan := manager.Subscribe(clientID)
+	defer manager.Unsubscribe(clientID)
+
+	initialState := manager.GetState()
+	if err := json.NewEncoder(conn).Encode(models.Response[State]{
+		ID:     req.ID,
+		Result: &initialState,
+	}); err != nil {
+		return
+	}
+
+	for state := range stateChan {
+		if err := json.NewEncoder(conn).Encode(models.Response[State]{
+			Result: &state,
+		}); err != nil {
+			return
+		}
+	}
+}
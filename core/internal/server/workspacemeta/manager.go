@@ -0,0 +1,174 @@
+package workspacemeta
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+)
+
+func NewManager(config Config) *Manager {
+	return &Manager{
+		config: config,
+		save:   SaveConfig,
+	}
+}
+
+func (m *Manager) getConfig() Config {
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+	return m.config
+}
+
+// Lookup returns the metadata for a workspace, preferring an entry for the
+// given output over one stored for AnyOutput.
+func (m *Manager) Lookup(output string, coordinates []uint32) (Meta, bool) {
+	if len(coordinates) == 0 {
+		return Meta{}, false
+	}
+
+	m.configMutex.RLock()
+	defer m.configMutex.RUnlock()
+
+	if output != "" {
+		if meta, ok := m.config.Workspaces[Key(output, coordinates)]; ok {
+			return meta, true
+		}
+	}
+	meta, ok := m.config.Workspaces[Key(AnyOutput, coordinates)]
+	return meta, ok
+}
+
+// SetMeta stores metadata for a workspace; a zero Meta removes the entry.
+func (m *Manager) SetMeta(output string, coordinates []uint32, meta Meta) {
+	key := Key(output, coordinates)
+
+	m.configMutex.Lock()
+	workspaces := maps.Clone(m.config.Workspaces)
+	if meta.IsZero() {
+		delete(workspaces, key)
+	} else {
+		workspaces[key] = meta
+	}
+	m.config.Workspaces = workspaces
+	cfg := m.config
+	m.configMutex.Unlock()
+
+	m.persist(cfg)
+}
+
+func (m *Manager) SetAssignments(assignments []Assignment) error {
+	if err := ValidateAssignments(assignments); err != nil {
+		return err
+	}
+
+	m.configMutex.Lock()
+	m.config.Assignments = assignments
+	cfg := m.config
+	m.configMutex.Unlock()
+
+	m.persist(cfg)
+	return nil
+}
+
+// ResolveApp returns the first assignment matching appID.
+func (m *Manager) ResolveApp(appID string) (Assignment, bool) {
+	for _, a := range m.getConfig().Assignments {
+		if a.Matches(appID) {
+			return a, true
+		}
+	}
+	return Assignment{}, false
+}
+
+// SetActivator installs the workspace backend ActivateForApp switches with.
+func (m *Manager) SetActivator(a Activator) {
+	m.activatorMutex.Lock()
+	m.activator = a
+	m.activatorMutex.Unlock()
+}
+
+// ActivateForApp switches to the workspace appID is assigned to. It reports
+// the matching assignment, or false when appID has none.
+func (m *Manager) ActivateForApp(appID string) (Assignment, bool, error) {
+	assignment, ok := m.ResolveApp(appID)
+	if !ok {
+		return Assignment{}, false, nil
+	}
+
+	m.activatorMutex.RLock()
+	activator := m.activator
+	m.activatorMutex.RUnlock()
+	if activator == nil {
+		return assignment, true, fmt.Errorf("no workspace backend available")
+	}
+
+	if err := activator.ActivateCoordinates(assignment.Output, assignment.Coordinates); err != nil {
+		return assignment, true, err
+	}
+	return assignment, true, nil
+}
+
+func (m *Manager) persist(cfg Config) {
+	if err := m.save(cfg); err != nil {
+		log.Warnf("WorkspaceMeta: failed to save config: %v", err)
+	}
+	m.notifySubscribers()
+}
+
+func (m *Manager) GetState() State {
+	cfg := m.getConfig()
+
+	entries := make([]Entry, 0, len(cfg.Workspaces))
+	for key, meta := range cfg.Workspaces {
+		output, coords, err := parseKey(key)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{Output: output, Coordinates: coords, Meta: meta})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Output != entries[j].Output {
+			return entries[i].Output < entries[j].Output
+		}
+		return slices.Compare(entries[i].Coordinates, entries[j].Coordinates) < 0
+	})
+
+	return State{
+		Workspaces:  entries,
+		Assignments: cfg.Assignments,
+	}
+}
+
+func (m *Manager) Subscribe(id string) chan State {
+	ch := make(chan State, 64)
+	m.subscribers.Store(id, ch)
+	return ch
+}
+
+func (m *Manager) Unsubscribe(id string) {
+	if ch, ok := m.subscribers.LoadAndDelete(id); ok {
+		close(ch)
+	}
+}
+
+func (m *Manager) notifySubscribers() {
+	state := m.GetState()
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		select {
+		case ch <- state:
+		default:
+		}
+		return true
+	})
+}
+
+func (m *Manager) Close() {
+	m.subscribers.Range(func(key string, ch chan State) bool {
+		close(ch)
+		m.subscribers.Delete(key)
+		return true
+	})
+}
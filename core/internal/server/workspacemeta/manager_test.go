@@ -0,0 +1,155 @@
+package workspacemeta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager() (*Manager, *[]Config) {
+	var saved []Config
+	m := NewManager(defaultConfig())
+	m.save = func(cfg Config) error {
+		saved = append(saved, cfg)
+		return nil
+	}
+	return m, &saved
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "DP-1/0,2", Key("DP-1", []uint32{0, 2}))
+	assert.Equal(t, "*/3", Key("", []uint32{3}))
+
+	output, coords, err := parseKey("HDMI-A-1/4,5")
+	require.NoError(t, err)
+	assert.Equal(t, "HDMI-A-1", output)
+	assert.Equal(t, []uint32{4, 5}, coords)
+
+	for _, bad := range []string{"DP-1", "/1", "DP-1/", "DP-1/a", "DP-1/1,-2"} {
+		_, _, err := parseKey(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestManager_Lookup(t *testing.T) {
+	m, saved := newTestManager()
+
+	m.SetMeta(AnyOutput, []uint32{1}, Meta{Label: "web"})
+	m.SetMeta("DP-2", []uint32{1}, Meta{Label: "chat", Color: "#112233"})
+
+	meta, ok := m.Lookup("DP-1", []uint32{1})
+	require.True(t, ok)
+	assert.Equal(t, "web", meta.Label)
+
+	meta, ok = m.Lookup("DP-2", []uint32{1})
+	require.True(t, ok)
+	assert.Equal(t, Meta{Label: "chat", Color: "#112233"}, meta)
+
+	_, ok = m.Lookup("DP-1", []uint32{2})
+	assert.False(t, ok)
+	_, ok = m.Lookup("DP-1", nil)
+	assert.False(t, ok)
+
+	m.SetMeta("DP-2", []uint32{1}, Meta{})
+	meta, _ = m.Lookup("DP-2", []uint32{1})
+	assert.Equal(t, "web", meta.Label)
+
+	assert.Len(t, *saved, 3)
+}
+
+func TestManager_GetStateSorted(t *testing.T) {
+	m, _ := newTestManager()
+	m.SetMeta("DP-1", []uint32{2}, Meta{Icon: "b"})
+	m.SetMeta("DP-1", []uint32{0, 1}, Meta{Icon: "a"})
+	m.SetMeta(AnyOutput, []uint32{5}, Meta{Icon: "c"})
+
+	state := m.GetState()
+	require.Len(t, state.Workspaces, 3)
+	assert.Equal(t, "*", state.Workspaces[0].Output)
+	assert.Equal(t, []uint32{0, 1}, state.Workspaces[1].Coordinates)
+	assert.Equal(t, "b", state.Workspaces[2].Icon)
+}
+
+func TestManager_Assignments(t *testing.T) {
+	m, _ := newTestManager()
+
+	assert.Error(t, m.SetAssignments([]Assignment{{AppID: "", Coordinates: []uint32{1}}}))
+	assert.Error(t, m.SetAssignments([]Assignment{{AppID: "firefox"}}))
+	assert.Error(t, m.SetAssignments([]Assignment{{AppID: "[", Coordinates: []uint32{1}}}))
+
+	require.NoError(t, m.SetAssignments([]Assignment{
+		{AppID: "org.mozilla.*", Coordinates: []uint32{2}},
+		{AppID: "*", Output: "DP-1", Coordinates: []uint32{9}},
+	}))
+
+	a, ok := m.ResolveApp("org.mozilla.Firefox")
+	require.True(t, ok)
+	assert.Equal(t, []uint32{2}, a.Coordinates)
+
+	a, ok = m.ResolveApp("kitty")
+	require.True(t, ok)
+	assert.Equal(t, "DP-1", a.Output)
+}
+
+func TestManager_SubscribeNotifies(t *testing.T) {
+	m, _ := newTestManager()
+	ch := m.Subscribe("test")
+
+	m.SetMeta("DP-1", []uint32{1}, Meta{Label: "x"})
+	state := <-ch
+	assert.Len(t, state.Workspaces, 1)
+
+	m.Close()
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestParseCoordinates(t *testing.T) {
+	coords, err := parseCoordinates(map[string]any{"c": []any{float64(0), float64(3)}}, "c")
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0, 3}, coords)
+
+	_, err = parseCoordinates(map[string]any{"c": []any{}}, "c")
+	assert.Error(t, err)
+	_, err = parseCoordinates(map[string]any{"c": []any{float64(-1)}}, "c")
+	assert.Error(t, err)
+	_, err = parseCoordinates(map[string]any{"c": []any{1.5}}, "c")
+	assert.Error(t, err)
+}
+
+type fakeActivator struct {
+	output      string
+	coordinates []uint32
+	calls       int
+}
+
+func (f *fakeActivator) ActivateCoordinates(output string, coordinates []uint32) error {
+	f.output = output
+	f.coordinates = coordinates
+	f.calls++
+	return nil
+}
+
+func TestManager_ActivateForApp(t *testing.T) {
+	m, _ := newTestManager()
+	require.NoError(t, m.SetAssignments([]Assignment{{AppID: "firefox*", Output: "DP-1", Coordinates: []uint32{2}}}))
+
+	_, _, err := m.ActivateForApp("firefox")
+	assert.Error(t, err)
+
+	activator := &fakeActivator{}
+	m.SetActivator(activator)
+
+	assignment, ok, err := m.ActivateForApp("Firefox-ESR")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "firefox*", assignment.AppID)
+	assert.Equal(t, "DP-1", activator.output)
+	assert.Equal(t, []uint32{2}, activator.coordinates)
+
+	_, ok, err = m.ActivateForApp("kitty")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, activator.calls)
+}
@@ -0,0 +1,186 @@
+package workspacemeta
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/pkg/syncmap"
+)
+
+// AnyOutput keys metadata that applies to a workspace coordinate on every
+// output; output-specific entries win over it.
+const AnyOutput = "*"
+
+type Meta struct {
+	Label string `json:"label,omitempty"`
+	Icon  string `json:"icon,omitempty"`
+	Color string `json:"color,omitempty"`
+}
+
+func (m Meta) IsZero() bool {
+	return m.Label == "" && m.Icon == "" && m.Color == ""
+}
+
+type Entry struct {
+	Output      string   `json:"output"`
+	Coordinates []uint32 `json:"coordinates"`
+	Meta
+}
+
+// Assignment pins apps matching AppID (a case-insensitive glob) to a
+// workspace. Launchers call workspacemeta.activateForApp before spawning the
+// app, so it opens there.
+type Assignment struct {
+	AppID       string   `json:"appId"`
+	Output      string   `json:"output,omitempty"`
+	Coordinates []uint32 `json:"coordinates"`
+}
+
+func (a Assignment) Matches(appID string) bool {
+	ok, err := filepath.Match(strings.ToLower(a.AppID), strings.ToLower(appID))
+	return err == nil && ok
+}
+
+// Key builds the storage key for output and coordinates, e.g. "DP-1/0,2".
+func Key(output string, coordinates []uint32) string {
+	if output == "" {
+		output = AnyOutput
+	}
+	parts := make([]string, len(coordinates))
+	for i, c := range coordinates {
+		parts[i] = strconv.FormatUint(uint64(c), 10)
+	}
+	return output + "/" + strings.Join(parts, ",")
+}
+
+func parseKey(key string) (string, []uint32, error) {
+	idx := strings.LastIndex(key, "/")
+	if idx <= 0 {
+		return "", nil, fmt.Errorf("invalid key: %s", key)
+	}
+	output, rest := key[:idx], key[idx+1:]
+	if rest == "" {
+		return "", nil, fmt.Errorf("invalid key: %s", key)
+	}
+
+	var coords []uint32
+	for _, p := range strings.Split(rest, ",") {
+		v, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid key: %s", key)
+		}
+		coords = append(coords, uint32(v))
+	}
+	return output, coords, nil
+}
+
+func ValidateAssignments(assignments []Assignment) error {
+	for i, a := range assignments {
+		if a.AppID == "" {
+			return fmt.Errorf("assignment %d: appId is required", i)
+		}
+		if _, err := filepath.Match(a.AppID, ""); err != nil {
+			return fmt.Errorf("assignment %d: invalid pattern %q: %w", i, a.AppID, err)
+		}
+		if len(a.Coordinates) == 0 {
+			return fmt.Errorf("assignment %d: coordinates are required", i)
+		}
+	}
+	return nil
+}
+
+type Config struct {
+	Workspaces  map[string]Meta `json:"workspaces"`
+	Assignments []Assignment    `json:"assignments"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Workspaces:  map[string]Meta{},
+		Assignments: []Assignment{},
+	}
+}
+
+func getConfigPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "DankMaterialShell", "workspaces.json"), nil
+}
+
+func LoadConfig() Config {
+	path, err := getConfigPath()
+	if err != nil {
+		return defaultConfig()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return defaultConfig()
+	}
+
+	cfg := defaultConfig()
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return defaultConfig()
+	}
+
+	for key := range cfg.Workspaces {
+		if _, _, err := parseKey(key); err != nil {
+			delete(cfg.Workspaces, key)
+		}
+	}
+	if cfg.Workspaces == nil {
+		cfg.Workspaces = map[string]Meta{}
+	}
+	if ValidateAssignments(cfg.Assignments) != nil || cfg.Assignments == nil {
+		cfg.Assignments = []Assignment{}
+	}
+	return cfg
+}
+
+func SaveConfig(cfg Config) error {
+	path, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+type State struct {
+	Workspaces  []Entry      `json:"workspaces"`
+	Assignments []Assignment `json:"assignments"`
+}
+
+// Activator switches to a workspace by output and coordinates. The dwl and
+// ext-workspace managers implement it; an empty output means the focused
+// one.
+type Activator interface {
+	ActivateCoordinates(output string, coordinates []uint32) error
+}
+
+type Manager struct {
+	configMutex sync.RWMutex
+	config      Config
+	save        func(Config) error
+
+	activatorMutex sync.RWMutex
+	activator      Activator
+
+	subscribers syncmap.Map[string, chan State]
+}
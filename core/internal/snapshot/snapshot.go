@@ -0,0 +1,200 @@
+package snapshot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// CommandLine describes the running dms invocation for the history log.
+func CommandLine() string {
+	if len(os.Args) == 0 {
+		return "dms"
+	}
+	return strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " ")
+}
+
+// Recorder records the files dms writes. *Store is the real implementation;
+// Discard writes files without recording them.
+type Recorder interface {
+	WriteFile(command, path string, data []byte, perm os.FileMode) error
+	Record(command, path string, before []byte, beforeExists bool, after []byte, afterExists bool) error
+}
+
+// Discard is a Recorder that applies writes without keeping history. Tests
+// install it with SetRecorder so they never touch the user's history.
+var Discard Recorder = discard{}
+
+type discard struct{}
+
+func (discard) WriteFile(_, path string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(path, data, perm)
+}
+
+func (discard) Record(string, string, []byte, bool, []byte, bool) error {
+	return nil
+}
+
+var (
+	recorderMu sync.RWMutex
+	recorder   Recorder
+)
+
+// SetRecorder replaces the recorder used by WriteFile and Record and returns
+// a function that restores the previous one. A nil recorder selects the
+// default store.
+func SetRecorder(r Recorder) func() {
+	recorderMu.Lock()
+	prev := recorder
+	recorder = r
+	recorderMu.Unlock()
+	return func() {
+		recorderMu.Lock()
+		recorder = prev
+		recorderMu.Unlock()
+	}
+}
+
+func currentRecorder() Recorder {
+	recorderMu.RLock()
+	r := recorder
+	recorderMu.RUnlock()
+	if r == nil {
+		return Default()
+	}
+	return r
+}
+
+// WriteFile is a drop-in replacement for os.WriteFile that records the change
+// with the current recorder.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return currentRecorder().WriteFile(CommandLine(), path, data, perm)
+}
+
+// Record stores a change that was applied without WriteFile with the current
+// recorder.
+func Record(path string, before []byte, beforeExists bool, after []byte) error {
+	return currentRecorder().Record(CommandLine(), path, before, beforeExists, after, true)
+}
+
+// ReadExisting returns the current content of path and whether it exists, for
+// use with Record.
+func ReadExisting(path string) ([]byte, bool) {
+	return readFile(path)
+}
+
+func (s *Store) Diff(rev *Revision) (string, error) {
+	var sb strings.Builder
+	for _, f := range rev.Files {
+		before, err := s.Object(f.Before)
+		if err != nil {
+			return "", fmt.Errorf("read snapshot of %s: %w", f.Path, err)
+		}
+		after, err := s.Object(f.After)
+		if err != nil {
+			return "", fmt.Errorf("read snapshot of %s: %w", f.Path, err)
+		}
+
+		diff, err := UnifiedDiff(f.Path, before, f.Before != "", after, f.After != "")
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(diff)
+	}
+	return sb.String(), nil
+}
+
+// UnifiedDiff renders the change to path as a unified diff, using /dev/null
+// for a side that does not exist.
+func UnifiedDiff(path string, before []byte, beforeExists bool, after []byte, afterExists bool) (string, error) {
+	fromFile, toFile := "a"+path, "b"+path
+	if !beforeExists {
+		fromFile = "/dev/null"
+	}
+	if !afterExists {
+		toFile = "/dev/null"
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(before),
+		B:        splitLines(after),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+func splitLines(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return difflib.SplitLines(string(data))
+}
+
+type RollbackError struct {
+	Conflicts []string
+}
+
+func (e *RollbackError) Error() string {
+	return fmt.Sprintf("files changed since this revision: %s", strings.Join(e.Conflicts, ", "))
+}
+
+// Rollback restores every file touched by rev to its content before rev.
+// Files modified again afterwards are refused with a *RollbackError unless
+// force is set. The rollback is itself recorded, so it can be undone.
+func (s *Store) Rollback(command string, rev *Revision, force bool) ([]string, error) {
+	if !force {
+		var conflicts []string
+		for _, f := range rev.Files {
+			current, exists := readFile(f.Path)
+			if exists != (f.After != "") || (exists && hashOf(current) != f.After) {
+				conflicts = append(conflicts, f.Path)
+			}
+		}
+		if len(conflicts) > 0 {
+			return nil, &RollbackError{Conflicts: conflicts}
+		}
+	}
+
+	var restored []string
+	for _, f := range rev.Files {
+		current, exists := readFile(f.Path)
+
+		if f.Before == "" {
+			if exists {
+				if err := os.Remove(f.Path); err != nil {
+					return restored, err
+				}
+				if err := s.Record(command, f.Path, current, true, nil, false); err != nil {
+					return restored, err
+				}
+			}
+			restored = append(restored, f.Path)
+			continue
+		}
+
+		data, err := s.Object(f.Before)
+		if err != nil {
+			return restored, fmt.Errorf("read snapshot of %s: %w", f.Path, err)
+		}
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0o644
+		}
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+			return restored, err
+		}
+		if err := os.WriteFile(f.Path, data, mode); err != nil {
+			return restored, err
+		}
+		if err := s.Record(command, f.Path, current, exists, data, true); err != nil {
+			return restored, err
+		}
+		restored = append(restored, f.Path)
+	}
+	return restored, nil
+}
@@ -0,0 +1,248 @@
+package snapshot
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/fileindex"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/log"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+)
+
+const (
+	maxRevisions = 200
+	// Writes from the same process and command this close together are
+	// grouped into one revision, so a deploy touching several files can be
+	// undone in one go.
+	coalesceWindow = 5 * time.Second
+)
+
+type FileChange struct {
+	Path   string      `json:"path"`
+	Before string      `json:"before,omitempty"`
+	After  string      `json:"after,omitempty"`
+	Mode   os.FileMode `json:"mode,omitempty"`
+}
+
+type Revision struct {
+	ID      string       `json:"id"`
+	Time    time.Time    `json:"time"`
+	Command string       `json:"command"`
+	PID     int          `json:"pid"`
+	Files   []FileChange `json:"files"`
+}
+
+type Store struct {
+	dir   string
+	index *fileindex.Index[Revision]
+}
+
+func DefaultDir() string {
+	return filepath.Join(utils.XDGStateHome(), "DankMaterialShell", "snapshots")
+}
+
+func Default() *Store {
+	return NewStore(DefaultDir())
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir, index: fileindex.New[Revision](dir, "revisions.json")}
+}
+
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// WriteFile writes data to path and records the change. A failure to record
+// is logged but never fails the write itself.
+func (s *Store) WriteFile(command, path string, data []byte, perm os.FileMode) error {
+	before, existed := readFile(path)
+	if err := os.WriteFile(path, data, perm); err != nil {
+		return err
+	}
+	if err := s.Record(command, path, before, existed, data, true); err != nil {
+		log.Warnf("Failed to record snapshot of %s: %v", path, err)
+	}
+	return nil
+}
+
+// Record stores a change made outside of WriteFile, e.g. a file moved into
+// place with elevated privileges.
+func (s *Store) Record(command, path string, before []byte, beforeExists bool, after []byte, afterExists bool) error {
+	if beforeExists == afterExists && bytes.Equal(before, after) {
+		return nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := s.index.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	change := FileChange{Path: absPath}
+	if beforeExists {
+		if change.Before, err = s.putObject(before); err != nil {
+			return err
+		}
+	}
+	if afterExists {
+		if change.After, err = s.putObject(after); err != nil {
+			return err
+		}
+	}
+	if info, err := os.Stat(absPath); err == nil {
+		change.Mode = info.Mode().Perm()
+	}
+
+	revisions, err := s.index.Load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	pid := os.Getpid()
+	if n := len(revisions); n > 0 {
+		last := &revisions[n-1]
+		if last.PID == pid && last.Command == command && now.Sub(last.Time) < coalesceWindow {
+			mergeChange(last, change)
+			last.Time = now
+			return s.index.Save(revisions)
+		}
+	}
+
+	revisions = append(revisions, Revision{
+		ID:      fileindex.ID(now, command, absPath),
+		Time:    now,
+		Command: command,
+		PID:     pid,
+		Files:   []FileChange{change},
+	})
+
+	if len(revisions) > maxRevisions {
+		revisions = revisions[len(revisions)-maxRevisions:]
+		if err := s.index.Save(revisions); err != nil {
+			return err
+		}
+		return s.gc(revisions)
+	}
+	return s.index.Save(revisions)
+}
+
+func mergeChange(rev *Revision, change FileChange) {
+	for i := range rev.Files {
+		if rev.Files[i].Path == change.Path {
+			rev.Files[i].After = change.After
+			rev.Files[i].Mode = change.Mode
+			return
+		}
+	}
+	rev.Files = append(rev.Files, change)
+}
+
+func (s *Store) List() ([]Revision, error) {
+	return s.index.List()
+}
+
+// Resolve finds a revision by unique id prefix. An empty ref or "latest"
+// returns the most recent revision.
+func (s *Store) Resolve(ref string) (*Revision, error) {
+	revisions, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, fmt.Errorf("no config history recorded yet")
+	}
+
+	if ref == "" || ref == "latest" {
+		return &revisions[len(revisions)-1], nil
+	}
+
+	var found *Revision
+	for i := range revisions {
+		if !strings.HasPrefix(revisions[i].ID, ref) {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("revision %q is ambiguous", ref)
+		}
+		found = &revisions[i]
+	}
+	if found == nil {
+		return nil, fmt.Errorf("revision %q not found", ref)
+	}
+	return found, nil
+}
+
+func (s *Store) Object(hash string) ([]byte, error) {
+	if hash == "" {
+		return nil, nil
+	}
+	return os.ReadFile(s.objectPath(hash))
+}
+
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, "objects", hash[:2], hash[2:])
+}
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Store) putObject(data []byte) (string, error) {
+	hash := hashOf(data)
+	path := s.objectPath(hash)
+
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+	if err := fileindex.WriteAtomic(path, data, 0o600); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func (s *Store) gc(revisions []Revision) error {
+	referenced := make(map[string]bool)
+	for _, rev := range revisions {
+		for _, f := range rev.Files {
+			referenced[f.Before] = true
+			referenced[f.After] = true
+		}
+	}
+
+	objectsDir := filepath.Join(s.dir, "objects")
+	return filepath.WalkDir(objectsDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || strings.HasSuffix(d.Name(), ".tmp") {
+			return nil
+		}
+		hash := filepath.Base(filepath.Dir(path)) + d.Name()
+		if !referenced[hash] {
+			os.Remove(path)
+		}
+		return nil
+	})
+}
+
+func readFile(path string) ([]byte, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,188 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteFileRecordsRevision(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(filepath.Join(tmpDir, "snapshots"))
+	path := filepath.Join(tmpDir, "config.kdl")
+
+	if err := os.WriteFile(path, []byte("a\nb\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.WriteFile("dms setup", path, []byte("a\nc\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := store.WriteFile("dms setup", filepath.Join(tmpDir, "new.conf"), []byte("x\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	revisions, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(revisions) != 1 || len(revisions[0].Files) != 2 {
+		t.Fatalf("Expected writes to coalesce into one revision, got %+v", revisions)
+	}
+	if revisions[0].Command != "dms setup" || revisions[0].Files[1].Before != "" {
+		t.Errorf("Unexpected revision: %+v", revisions[0])
+	}
+
+	diff, err := store.Diff(&revisions[0])
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	for _, want := range []string{"-b\n", "+c\n", "--- /dev/null", "+x\n"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Diff missing %q:\n%s", want, diff)
+		}
+	}
+
+	if err := store.WriteFile("dms keybinds set", path, []byte("a\nd\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	revisions, _ = store.List()
+	if len(revisions) != 2 {
+		t.Errorf("Expected a different command to start a new revision, got %d", len(revisions))
+	}
+}
+
+func TestWriteFileSkipsUnchanged(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(filepath.Join(tmpDir, "snapshots"))
+	path := filepath.Join(tmpDir, "config.kdl")
+
+	os.WriteFile(path, []byte("same"), 0o644)
+	if err := store.WriteFile("dms setup", path, []byte("same"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if revisions, _ := store.List(); len(revisions) != 0 {
+		t.Errorf("Expected no revision for an unchanged file, got %+v", revisions)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if _, err := store.Resolve(""); err == nil {
+		t.Error("Expected an error for an empty history")
+	}
+
+	path := filepath.Join(t.TempDir(), "f")
+	store.WriteFile("one", path, []byte("1"), 0o644)
+	store.WriteFile("two", path, []byte("2"), 0o644)
+
+	revisions, _ := store.List()
+	latest, err := store.Resolve("latest")
+	if err != nil || latest.ID != revisions[1].ID {
+		t.Errorf("Resolve(latest) = %v, %v", latest, err)
+	}
+	first, err := store.Resolve(revisions[0].ID[:6])
+	if err != nil || first.Command != "one" {
+		t.Errorf("Resolve(prefix) = %v, %v", first, err)
+	}
+	if _, err := store.Resolve("zzzz"); err == nil {
+		t.Error("Expected an error for an unknown revision")
+	}
+}
+
+func TestRollback(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(filepath.Join(tmpDir, "snapshots"))
+	existing := filepath.Join(tmpDir, "binds.kdl")
+	created := filepath.Join(tmpDir, "dms", "windowrules.kdl")
+
+	os.WriteFile(existing, []byte("original"), 0o600)
+	os.MkdirAll(filepath.Dir(created), 0o755)
+	store.WriteFile("dms setup", existing, []byte("broken"), 0o600)
+	store.WriteFile("dms setup", created, []byte("new"), 0o644)
+
+	rev, err := store.Resolve("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.WriteFile(existing, []byte("edited by hand"), 0o600)
+	_, err = store.Rollback("dms config rollback", rev, false)
+	var conflict *RollbackError
+	if !errors.As(err, &conflict) || len(conflict.Conflicts) != 1 || conflict.Conflicts[0] != existing {
+		t.Fatalf("Expected a conflict for the edited file, got %v", err)
+	}
+
+	restored, err := store.Rollback("dms config rollback", rev, true)
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("Expected 2 restored files, got %v", restored)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "original" {
+		t.Errorf("File not restored, got %q", data)
+	}
+	if info, _ := os.Stat(existing); info.Mode().Perm() != 0o600 {
+		t.Errorf("Mode not preserved, got %v", info.Mode().Perm())
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("Created file should have been removed")
+	}
+
+	revisions, _ := store.List()
+	last := revisions[len(revisions)-1]
+	if last.Command != "dms config rollback" || len(last.Files) != 2 {
+		t.Errorf("Rollback was not recorded: %+v", last)
+	}
+}
+
+func TestPruneRemovesOldObjects(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(filepath.Join(tmpDir, "snapshots"))
+	path := filepath.Join(tmpDir, "f")
+
+	for i := 0; i < maxRevisions+2; i++ {
+		// distinct commands keep every write in its own revision
+		if err := store.WriteFile(time.Duration(i).String(), path, []byte(time.Duration(i).String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revisions, _ := store.List()
+	if len(revisions) != maxRevisions {
+		t.Fatalf("Expected %d revisions, got %d", maxRevisions, len(revisions))
+	}
+
+	if _, err := os.Stat(store.objectPath(hashOf([]byte(time.Duration(0).String())))); !os.IsNotExist(err) {
+		t.Error("Object only referenced by a pruned revision should be removed")
+	}
+}
+
+func TestSetRecorder(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStore(filepath.Join(tmpDir, "snapshots"))
+	path := filepath.Join(tmpDir, "config.kdl")
+
+	restore := SetRecorder(store)
+	if err := WriteFile(path, []byte("a\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if revisions, _ := store.List(); len(revisions) != 1 {
+		t.Errorf("Expected the installed store to record the write, got %+v", revisions)
+	}
+
+	SetRecorder(Discard)
+	if err := WriteFile(path, []byte("b\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "b\n" {
+		t.Errorf("Discard did not write the file, got %q", data)
+	}
+	if revisions, _ := store.List(); len(revisions) != 1 {
+		t.Errorf("Discard should not record, got %d revisions", len(revisions))
+	}
+	restore()
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/config"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/deps"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/distros"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -41,6 +42,9 @@ type Model struct {
 	skipGentooUseFlags bool
 	sudoPassword       string
 	existingConfigs    []ExistingConfigInfo
+	mergeConflicts     []config.MergeConflict
+	mergeResolutions   map[string]bool
+	selectedConflict   int
 	fingerprintFailed  bool
 }
 
@@ -86,6 +90,7 @@ func NewModel(version string, logFilePath string) Model {
 		reinstallItems:   make(map[string]bool),
 		disabledItems:    make(map[string]bool),
 		replaceConfigs:   make(map[string]bool),
+		mergeResolutions: make(map[string]bool),
 		installationLogs: []string{},
 	}
 }
@@ -157,6 +162,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateInstallingPackagesState(msg)
 	case StateConfigConfirmation:
 		return m.updateConfigConfirmationState(msg)
+	case StateConfigConflicts:
+		return m.updateConfigConflictsState(msg)
 	case StateDeployingConfigs:
 		return m.updateDeployingConfigsState(msg)
 	case StateInstallComplete:
@@ -199,6 +206,8 @@ func (m Model) View() string {
 		return m.viewInstallingPackages()
 	case StateConfigConfirmation:
 		return m.viewConfigConfirmation()
+	case StateConfigConflicts:
+		return m.viewConfigConflicts()
 	case StateDeployingConfigs:
 		return m.viewDeployingConfigs()
 	case StateInstallComplete:
@@ -15,6 +15,7 @@ const (
 	StatePasswordPrompt
 	StateInstallingPackages
 	StateConfigConfirmation
+	StateConfigConflicts
 	StateDeployingConfigs
 	StateInstallComplete
 	StateFinalComplete
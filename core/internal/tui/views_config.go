@@ -89,6 +89,9 @@ func (m Model) updateDeployingConfigsState(msg tea.Msg) (tea.Model, tea.Cmd) {
 					logMsg += fmt.Sprintf(" (backup: %s)", deployResult.BackupPath)
 				}
 				m.installationLogs = append(m.installationLogs, logMsg)
+				if len(deployResult.Conflicts) > 0 {
+					m.installationLogs = append(m.installationLogs, fmt.Sprintf("  merged with your edits, %d conflicting section(s) resolved", len(deployResult.Conflicts)))
+				}
 			}
 		}
 
@@ -100,42 +103,39 @@ func (m Model) updateDeployingConfigsState(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, m.listenForLogs()
 }
 
-func (m Model) deployConfigurations() tea.Cmd {
-	return func() tea.Msg {
-		// Determine the selected window manager
-		var wm deps.WindowManager
-		switch m.selectedWM {
+func (m Model) selectedTargets() (deps.WindowManager, deps.Terminal) {
+	wm := m.selectedWindowManager()
+
+	var terminal deps.Terminal
+	if m.osInfo != nil && m.osInfo.Distribution.ID == "gentoo" {
+		switch m.selectedTerminal {
 		case 0:
-			wm = deps.WindowManagerNiri
+			terminal = deps.TerminalKitty
 		case 1:
-			wm = deps.WindowManagerHyprland
+			terminal = deps.TerminalAlacritty
 		default:
-			wm = deps.WindowManagerNiri
+			terminal = deps.TerminalKitty
 		}
-
-		// Determine the selected terminal
-		var terminal deps.Terminal
-		if m.osInfo != nil && m.osInfo.Distribution.ID == "gentoo" {
-			switch m.selectedTerminal {
-			case 0:
-				terminal = deps.TerminalKitty
-			case 1:
-				terminal = deps.TerminalAlacritty
-			default:
-				terminal = deps.TerminalKitty
-			}
-		} else {
-			switch m.selectedTerminal {
-			case 0:
-				terminal = deps.TerminalGhostty
-			case 1:
-				terminal = deps.TerminalKitty
-			default:
-				terminal = deps.TerminalAlacritty
-			}
+	} else {
+		switch m.selectedTerminal {
+		case 0:
+			terminal = deps.TerminalGhostty
+		case 1:
+			terminal = deps.TerminalKitty
+		default:
+			terminal = deps.TerminalAlacritty
 		}
+	}
+
+	return wm, terminal
+}
+
+func (m Model) deployConfigurations() tea.Cmd {
+	return func() tea.Msg {
+		wm, terminal := m.selectedTargets()
 
 		deployer := config.NewConfigDeployer(m.logChan)
+		deployer.SetMergeResolutions(m.mergeResolutions)
 
 		results, err := deployer.DeployConfigurationsSelectiveWithReinstalls(context.Background(), wm, terminal, m.dependencies, m.replaceConfigs, m.reinstallItems)
 
@@ -276,11 +276,14 @@ func (m Model) updateConfigConfirmationState(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		case "enter":
-			m.state = StateDeployingConfigs
-			return m, m.deployConfigurations()
+			return m, m.planMerges()
 		}
 	}
 
+	if result, ok := msg.(mergePlanResult); ok {
+		return m.handleMergePlan(result)
+	}
+
 	return m, nil
 }
 
@@ -288,27 +291,15 @@ func (m Model) checkExistingConfigurations() tea.Cmd {
 	return func() tea.Msg {
 		var configs []ExistingConfigInfo
 
-		if m.selectedWM == 0 {
-			niriPath := filepath.Join(os.Getenv("HOME"), ".config", "niri", "config.kdl")
-			niriExists := false
-			if _, err := os.Stat(niriPath); err == nil {
-				niriExists = true
-			}
-			configs = append(configs, ExistingConfigInfo{
-				ConfigType: "Niri",
-				Path:       niriPath,
-				Exists:     niriExists,
-			})
-		} else {
-			hyprlandPath := filepath.Join(os.Getenv("HOME"), ".config", "hypr", "hyprland.conf")
-			hyprlandExists := false
-			if _, err := os.Stat(hyprlandPath); err == nil {
-				hyprlandExists = true
+		if configType, path, ok := config.NewConfigDeployer(nil).MainConfig(m.selectedWindowManager()); ok {
+			exists := false
+			if _, err := os.Stat(path); err == nil {
+				exists = true
 			}
 			configs = append(configs, ExistingConfigInfo{
-				ConfigType: "Hyprland",
-				Path:       hyprlandPath,
-				Exists:     hyprlandExists,
+				ConfigType: configType,
+				Path:       path,
+				Exists:     exists,
 			})
 		}
 
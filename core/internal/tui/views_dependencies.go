@@ -217,13 +217,7 @@ func (m Model) installPackages() tea.Cmd {
 			}
 		}
 
-		// Convert TUI selection to deps enum
-		var wm deps.WindowManager
-		if m.selectedWM == 0 {
-			wm = deps.WindowManagerNiri
-		} else {
-			wm = deps.WindowManagerHyprland
-		}
+		wm := m.selectedWindowManager()
 
 		installerProgressChan := make(chan distros.InstallProgressMsg, 100)
 
@@ -254,7 +248,7 @@ func (m Model) installPackages() tea.Cmd {
 					}
 					if greeterSelected {
 						compositorName := "niri"
-						if m.selectedWM == 1 {
+						if wm == deps.WindowManagerHyprland {
 							compositorName = "Hyprland"
 						}
 						m.packageProgressChan <- packageInstallProgressMsg{
@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type mergePlanResult struct {
+	conflicts []config.MergeConflict
+	error     error
+}
+
+func (m Model) planMerges() tea.Cmd {
+	return func() tea.Msg {
+		wm, terminal := m.selectedTargets()
+		conflicts, err := config.NewConfigDeployer(nil).PlanMerges(wm, terminal, true)
+		return mergePlanResult{
+			conflicts: conflicts,
+			error:     err,
+		}
+	}
+}
+
+func (m Model) handleMergePlan(result mergePlanResult) (tea.Model, tea.Cmd) {
+	if result.error != nil {
+		m.err = result.error
+		m.state = StateError
+		return m, nil
+	}
+
+	// configs the user chose to keep are not touched, so their conflicts don't matter
+	m.mergeConflicts = nil
+	for _, conflict := range result.conflicts {
+		if m.replaceConfigs[conflict.ConfigType] {
+			m.mergeConflicts = append(m.mergeConflicts, conflict)
+		}
+	}
+
+	if len(m.mergeConflicts) == 0 {
+		m.state = StateDeployingConfigs
+		return m, m.deployConfigurations()
+	}
+
+	m.selectedConflict = 0
+	m.state = StateConfigConflicts
+	return m, nil
+}
+
+func (m Model) viewConfigConflicts() string {
+	var b strings.Builder
+
+	b.WriteString(m.renderBanner())
+	b.WriteString("\n")
+
+	title := m.styles.Title.Render("Merge Conflicts")
+	b.WriteString(title)
+	b.WriteString("\n\n")
+
+	info := m.styles.Normal.Render("These sections were changed both by you and by the updated template.")
+	b.WriteString(info)
+	b.WriteString("\n\n")
+
+	for i, conflict := range m.mergeConflicts {
+		var status string
+		if m.mergeResolutions[conflict.ID] {
+			status = m.styles.Warning.Render("Take template")
+		} else {
+			status = m.styles.Success.Render("Keep mine")
+		}
+
+		line := fmt.Sprintf("%-10s %-30s %s", conflict.ConfigType, conflict.Section, status)
+		if i == m.selectedConflict {
+			b.WriteString(m.styles.SelectedOption.Render("▶ " + line))
+		} else {
+			b.WriteString(m.styles.Normal.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+
+	if m.selectedConflict < len(m.mergeConflicts) {
+		conflict := m.mergeConflicts[m.selectedConflict]
+		b.WriteString("\n")
+		b.WriteString(m.styles.Subtle.Render("Yours:"))
+		b.WriteString("\n")
+		b.WriteString(m.styles.Normal.Render(indentSection(conflict.Ours)))
+		b.WriteString("\n")
+		b.WriteString(m.styles.Subtle.Render("Template:"))
+		b.WriteString("\n")
+		b.WriteString(m.styles.Normal.Render(indentSection(conflict.Theirs)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	help := m.styles.Subtle.Render("↑/↓: Navigate, Space: Toggle mine/template, Enter: Deploy")
+	b.WriteString(help)
+
+	return b.String()
+}
+
+func indentSection(text string) string {
+	text = strings.Trim(text, "\n")
+	if strings.TrimSpace(text) == "" {
+		return "    (removed)"
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = "    " + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) updateConfigConflictsState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "up":
+			if m.selectedConflict > 0 {
+				m.selectedConflict--
+			}
+		case "down":
+			if m.selectedConflict < len(m.mergeConflicts)-1 {
+				m.selectedConflict++
+			}
+		case " ":
+			if m.selectedConflict < len(m.mergeConflicts) {
+				id := m.mergeConflicts[m.selectedConflict].ID
+				m.mergeResolutions[id] = !m.mergeResolutions[id]
+			}
+		case "enter":
+			m.state = StateDeployingConfigs
+			return m, m.deployConfigurations()
+		}
+	}
+
+	return m, nil
+}
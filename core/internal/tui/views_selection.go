@@ -10,6 +10,41 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+type windowManagerOption struct {
+	name        string
+	description string
+	wm          deps.WindowManager
+}
+
+func (m Model) windowManagerOptions() []windowManagerOption {
+	options := []windowManagerOption{
+		{"niri", "Scrollable-tiling Wayland compositor.", deps.WindowManagerNiri},
+	}
+
+	if m.osInfo == nil || m.osInfo.Distribution.ID != "debian" {
+		options = append(options, windowManagerOption{"Hyprland", "Dynamic tiling Wayland compositor.", deps.WindowManagerHyprland})
+	}
+
+	options = append(options, windowManagerOption{"sway", "i3-compatible tiling Wayland compositor.", deps.WindowManagerSway})
+
+	if m.osInfo != nil && m.osInfo.Distribution.ID == "arch" {
+		options = append(options, windowManagerOption{"MangoWC", "dwl based tiling Wayland compositor.", deps.WindowManagerMangoWC})
+	}
+	if m.osInfo != nil && (m.osInfo.Distribution.ID == "arch" || m.osInfo.Distribution.ID == "fedora") {
+		options = append(options, windowManagerOption{"miracle-wm", "Mir based tiling Wayland compositor.", deps.WindowManagerMiracle})
+	}
+
+	return options
+}
+
+func (m Model) selectedWindowManager() deps.WindowManager {
+	options := m.windowManagerOptions()
+	if m.selectedWM >= 0 && m.selectedWM < len(options) {
+		return options[m.selectedWM].wm
+	}
+	return deps.WindowManagerNiri
+}
+
 func (m Model) viewSelectWindowManager() string {
 	var b strings.Builder
 
@@ -20,19 +55,7 @@ func (m Model) viewSelectWindowManager() string {
 	b.WriteString(title)
 	b.WriteString("\n\n")
 
-	options := []struct {
-		name        string
-		description string
-	}{
-		{"niri", "Scrollable-tiling Wayland compositor."},
-	}
-
-	if m.osInfo == nil || m.osInfo.Distribution.ID != "debian" {
-		options = append(options, struct {
-			name        string
-			description string
-		}{"Hyprland", "Dynamic tiling Wayland compositor."})
-	}
+	options := m.windowManagerOptions()
 
 	for i, option := range options {
 		if i == m.selectedWM {
@@ -152,10 +175,7 @@ func (m Model) updateSelectTerminalState(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m Model) updateSelectWindowManagerState(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		maxWMIndex := 1
-		if m.osInfo != nil && m.osInfo.Distribution.ID == "debian" {
-			maxWMIndex = 0
-		}
+		maxWMIndex := len(m.windowManagerOptions()) - 1
 
 		switch keyMsg.String() {
 		case "up":
@@ -189,13 +209,7 @@ func (m Model) detectDependencies() tea.Cmd {
 			return depsDetectedMsg{deps: nil, err: err}
 		}
 
-		// Convert TUI selection to deps enum
-		var wm deps.WindowManager
-		if m.selectedWM == 0 {
-			wm = deps.WindowManagerNiri // First option is Niri
-		} else {
-			wm = deps.WindowManagerHyprland // Second option is Hyprland
-		}
+		wm := m.selectedWindowManager()
 
 		var terminal deps.Terminal
 		if m.osInfo != nil && m.osInfo.Distribution.ID == "gentoo" {
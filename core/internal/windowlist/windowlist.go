@@ -0,0 +1,232 @@
+// Package windowlist lists the open windows through the compositor's own IPC,
+// which unlike the foreign toplevel protocols reports workspaces and
+// floating state.
+package windowlist
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/compositor"
+)
+
+type Window struct {
+	ID         string `json:"id"`
+	AppID      string `json:"appId"`
+	Title      string `json:"title"`
+	Workspace  string `json:"workspace,omitempty"`
+	Output     string `json:"output,omitempty"`
+	Floating   bool   `json:"floating"`
+	Focused    bool   `json:"focused"`
+	Urgent     bool   `json:"urgent"`
+	XWayland   bool   `json:"xwayland"`
+	Fullscreen bool   `json:"fullscreen"`
+	Pinned     bool   `json:"pinned"`
+}
+
+// ErrUnsupported is returned by List on compositors without a window IPC.
+var ErrUnsupported = errors.New("listing windows is not supported")
+
+func List() ([]Window, error) {
+	switch c := compositor.Detect(); c {
+	case compositor.Hyprland:
+		return listHyprlandWindows()
+	case compositor.Niri:
+		return listNiriWindows()
+	case compositor.Sway:
+		return listSwayWindows("swaymsg")
+	case compositor.Scroll:
+		return listSwayWindows("scrollmsg")
+	case compositor.Miracle:
+		return listSwayWindows("miraclemsg")
+	default:
+		return nil, fmt.Errorf("%w on %s", ErrUnsupported, c)
+	}
+}
+
+type hyprlandClient struct {
+	Address    string          `json:"address"`
+	Class      string          `json:"class"`
+	Title      string          `json:"title"`
+	Floating   bool            `json:"floating"`
+	Fullscreen json.RawMessage `json:"fullscreen"`
+	Pinned     bool            `json:"pinned"`
+	XWayland   bool            `json:"xwayland"`
+	FocusID    int             `json:"focusHistoryID"`
+	Mapped     bool            `json:"mapped"`
+	Workspace  struct {
+		Name string `json:"name"`
+	} `json:"workspace"`
+}
+
+func listHyprlandWindows() ([]Window, error) {
+	output, err := exec.Command("hyprctl", "-j", "clients").Output()
+	if err != nil {
+		return nil, fmt.Errorf("hyprctl clients: %w", err)
+	}
+
+	var clients []hyprlandClient
+	if err := json.Unmarshal(output, &clients); err != nil {
+		return nil, fmt.Errorf("parse clients: %w", err)
+	}
+
+	windows := make([]Window, 0, len(clients))
+	for _, c := range clients {
+		if !c.Mapped {
+			continue
+		}
+		// fullscreen is a bool on older releases and a mode number on newer ones
+		fullscreen := string(c.Fullscreen)
+		windows = append(windows, Window{
+			ID:         c.Address,
+			AppID:      c.Class,
+			Title:      c.Title,
+			Workspace:  c.Workspace.Name,
+			Floating:   c.Floating,
+			Focused:    c.FocusID == 0,
+			XWayland:   c.XWayland,
+			Fullscreen: fullscreen != "" && fullscreen != "0" && fullscreen != "false",
+			Pinned:     c.Pinned,
+		})
+	}
+	return windows, nil
+}
+
+type niriWindow struct {
+	ID          uint64  `json:"id"`
+	Title       string  `json:"title"`
+	AppID       string  `json:"app_id"`
+	WorkspaceID *uint64 `json:"workspace_id"`
+	IsFocused   bool    `json:"is_focused"`
+	IsFloating  bool    `json:"is_floating"`
+	IsUrgent    bool    `json:"is_urgent"`
+}
+
+type niriWorkspace struct {
+	ID     uint64 `json:"id"`
+	Idx    int    `json:"idx"`
+	Name   string `json:"name"`
+	Output string `json:"output"`
+}
+
+func listNiriWindows() ([]Window, error) {
+	output, err := exec.Command("niri", "msg", "-j", "windows").Output()
+	if err != nil {
+		return nil, fmt.Errorf("niri msg windows: %w", err)
+	}
+
+	var niriWindows []niriWindow
+	if err := json.Unmarshal(output, &niriWindows); err != nil {
+		return nil, fmt.Errorf("parse windows: %w", err)
+	}
+
+	workspaces := make(map[uint64]niriWorkspace)
+	if output, err := exec.Command("niri", "msg", "-j", "workspaces").Output(); err == nil {
+		var list []niriWorkspace
+		if json.Unmarshal(output, &list) == nil {
+			for _, ws := range list {
+				workspaces[ws.ID] = ws
+			}
+		}
+	}
+
+	windows := make([]Window, 0, len(niriWindows))
+	for _, w := range niriWindows {
+		info := Window{
+			ID:       strconv.FormatUint(w.ID, 10),
+			AppID:    w.AppID,
+			Title:    w.Title,
+			Floating: w.IsFloating,
+			Focused:  w.IsFocused,
+			Urgent:   w.IsUrgent,
+		}
+		if w.WorkspaceID != nil {
+			if ws, ok := workspaces[*w.WorkspaceID]; ok {
+				info.Output = ws.Output
+				info.Workspace = ws.Name
+				if info.Workspace == "" {
+					info.Workspace = strconv.Itoa(ws.Idx)
+				}
+			}
+		}
+		windows = append(windows, info)
+	}
+	return windows, nil
+}
+
+type swayNode struct {
+	ID               int64      `json:"id"`
+	Type             string     `json:"type"`
+	Name             string     `json:"name"`
+	AppID            *string    `json:"app_id"`
+	Shell            string     `json:"shell"`
+	PID              int        `json:"pid"`
+	Focused          bool       `json:"focused"`
+	Urgent           bool       `json:"urgent"`
+	Sticky           bool       `json:"sticky"`
+	FullscreenMode   int        `json:"fullscreen_mode"`
+	Nodes            []swayNode `json:"nodes"`
+	FloatingNodes    []swayNode `json:"floating_nodes"`
+	WindowProperties *struct {
+		Class string `json:"class"`
+	} `json:"window_properties"`
+}
+
+func listSwayWindows(msgCmd string) ([]Window, error) {
+	output, err := exec.Command(msgCmd, "-t", "get_tree").Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s get_tree: %w", msgCmd, err)
+	}
+
+	var root swayNode
+	if err := json.Unmarshal(output, &root); err != nil {
+		return nil, fmt.Errorf("parse tree: %w", err)
+	}
+
+	var windows []Window
+	collectSwayWindows(&root, "", "", false, &windows)
+	return windows, nil
+}
+
+func collectSwayWindows(node *swayNode, output, workspace string, floating bool, windows *[]Window) {
+	switch node.Type {
+	case "output":
+		output = node.Name
+	case "workspace":
+		workspace = node.Name
+	}
+
+	isView := node.PID > 0 && len(node.Nodes) == 0 && len(node.FloatingNodes) == 0
+	if isView && (node.Type == "con" || node.Type == "floating_con") {
+		info := Window{
+			ID:         strconv.FormatInt(node.ID, 10),
+			Title:      node.Name,
+			Workspace:  workspace,
+			Output:     output,
+			Floating:   floating || node.Type == "floating_con",
+			Focused:    node.Focused,
+			Urgent:     node.Urgent,
+			XWayland:   node.Shell == "xwayland",
+			Fullscreen: node.FullscreenMode != 0,
+			Pinned:     node.Sticky,
+		}
+		switch {
+		case node.AppID != nil && *node.AppID != "":
+			info.AppID = *node.AppID
+		case node.WindowProperties != nil:
+			info.AppID = node.WindowProperties.Class
+		}
+		*windows = append(*windows, info)
+		return
+	}
+
+	for i := range node.Nodes {
+		collectSwayWindows(&node.Nodes[i], output, workspace, floating, windows)
+	}
+	for i := range node.FloatingNodes {
+		collectSwayWindows(&node.FloatingNodes[i], output, workspace, true, windows)
+	}
+}
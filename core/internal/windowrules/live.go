@@ -0,0 +1,40 @@
+package windowrules
+
+import (
+	"errors"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/compositor"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowlist"
+)
+
+// LiveWindows queries the running compositor for its open windows. On
+// compositors without a window IPC (labwc, dwl/mangowc) fallback supplies
+// them instead, typically from the foreign toplevel list.
+func LiveWindows(fallback func() ([]windowlist.Window, error)) (string, []Window, error) {
+	name := compositor.Detect().String()
+	infos, err := windowlist.List()
+	if errors.Is(err, windowlist.ErrUnsupported) && fallback != nil {
+		infos, err = fallback()
+	}
+	if err != nil {
+		return name, nil, err
+	}
+
+	windows := make([]Window, len(infos))
+	for i, info := range infos {
+		windows[i] = Window{
+			ID:         info.ID,
+			AppID:      info.AppID,
+			Title:      info.Title,
+			Workspace:  info.Workspace,
+			Output:     info.Output,
+			IsFloating: info.Floating,
+			IsFocused:  info.Focused,
+			IsUrgent:   info.Urgent,
+			XWayland:   info.XWayland,
+			Fullscreen: info.Fullscreen,
+			Pinned:     info.Pinned,
+		}
+	}
+	return name, windows, nil
+}
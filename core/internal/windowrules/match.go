@@ -0,0 +1,261 @@
+package windowrules
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type Window struct {
+	ID         string `json:"id"`
+	AppID      string `json:"appId"`
+	Title      string `json:"title"`
+	Workspace  string `json:"workspace,omitempty"`
+	Output     string `json:"output,omitempty"`
+	IsFloating bool   `json:"isFloating"`
+	IsFocused  bool   `json:"isFocused"`
+	IsUrgent   bool   `json:"isUrgent"`
+	XWayland   bool   `json:"xwayland"`
+	Fullscreen bool   `json:"fullscreen"`
+	Pinned     bool   `json:"pinned"`
+}
+
+type MatchMode int
+
+const (
+	// MatchRegex matches when the pattern is found anywhere in the value (niri, sway, mangowc).
+	MatchRegex MatchMode = iota
+	// MatchRegexFull requires the pattern to match the whole value (hyprland).
+	MatchRegexFull
+	// MatchGlob uses case-insensitive shell globs (labwc).
+	MatchGlob
+)
+
+func MatchModeFor(compositor string) MatchMode {
+	switch strings.ToLower(compositor) {
+	case "hyprland":
+		return MatchRegexFull
+	case "labwc":
+		return MatchGlob
+	default:
+		return MatchRegex
+	}
+}
+
+type Matcher struct {
+	mode    MatchMode
+	focused *Window
+	regexps map[string]*regexp.Regexp
+}
+
+func NewMatcher(compositor string, windows []Window) *Matcher {
+	m := &Matcher{
+		mode:    MatchModeFor(compositor),
+		regexps: make(map[string]*regexp.Regexp),
+	}
+	for i := range windows {
+		if windows[i].IsFocused {
+			m.focused = &windows[i]
+			break
+		}
+	}
+	return m
+}
+
+func (m *Matcher) Match(c MatchCriteria, w Window) (bool, error) {
+	if c.AppID != "" {
+		ok, err := m.matchString(c.AppID, w.AppID, m.focusedValue(func(f *Window) string { return f.AppID }))
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	if c.Title != "" {
+		ok, err := m.matchString(c.Title, w.Title, m.focusedValue(func(f *Window) string { return f.Title }))
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	checks := []struct {
+		want *bool
+		have bool
+	}{
+		{c.IsFloating, w.IsFloating},
+		{c.IsActive, w.IsFocused},
+		{c.IsFocused, w.IsFocused},
+		{c.IsUrgent, w.IsUrgent},
+		{c.XWayland, w.XWayland},
+		{c.Fullscreen, w.Fullscreen},
+		{c.Pinned, w.Pinned},
+	}
+	for _, check := range checks {
+		if check.want != nil && *check.want != check.have {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Unchecked lists the criteria that cannot be evaluated against live windows
+// and are ignored by Match.
+func Unchecked(c MatchCriteria) []string {
+	var fields []string
+	if c.IsActiveInColumn != nil {
+		fields = append(fields, "isActiveInColumn")
+	}
+	if c.IsWindowCastTarget != nil {
+		fields = append(fields, "isWindowCastTarget")
+	}
+	if c.AtStartup != nil {
+		fields = append(fields, "atStartup")
+	}
+	if c.Initialised != nil {
+		fields = append(fields, "initialised")
+	}
+	return fields
+}
+
+func (m *Matcher) focusedValue(get func(*Window) string) string {
+	if m.focused == nil {
+		return ""
+	}
+	return get(m.focused)
+}
+
+func (m *Matcher) matchString(pattern, value, focused string) (bool, error) {
+	if pattern == "__focused__" {
+		return m.focused != nil && value == focused, nil
+	}
+
+	switch m.mode {
+	case MatchGlob:
+		ok, err := path.Match(strings.ToLower(pattern), strings.ToLower(value))
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		return ok, nil
+	case MatchRegexFull:
+		if negated, ok := strings.CutPrefix(pattern, "negative:"); ok {
+			matched, err := m.matchRegex("^(?:"+negated+")$", value)
+			return !matched, err
+		}
+		return m.matchRegex("^(?:"+pattern+")$", value)
+	default:
+		return m.matchRegex(pattern, value)
+	}
+}
+
+func (m *Matcher) matchRegex(pattern, value string) (bool, error) {
+	re, ok := m.regexps[pattern]
+	if !ok {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid regex %q: %w", pattern, err)
+		}
+		m.regexps[pattern] = re
+	}
+	return re.MatchString(value), nil
+}
+
+type TestResult struct {
+	Compositor string   `json:"compositor"`
+	Matches    []Window `json:"matches"`
+	Total      int      `json:"total"`
+	Unchecked  []string `json:"unchecked,omitempty"`
+}
+
+func Test(compositor string, c MatchCriteria, windows []Window) (*TestResult, error) {
+	m := NewMatcher(compositor, windows)
+	result := &TestResult{
+		Compositor: compositor,
+		Matches:    []Window{},
+		Total:      len(windows),
+		Unchecked:  Unchecked(c),
+	}
+	for _, w := range windows {
+		ok, err := m.Match(c, w)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result.Matches = append(result.Matches, w)
+		}
+	}
+	return result, nil
+}
+
+type AppliedRule struct {
+	Position  int        `json:"position"`
+	Rule      WindowRule `json:"rule"`
+	Unchecked []string   `json:"unchecked,omitempty"`
+}
+
+type WhyResult struct {
+	Window Window        `json:"window"`
+	Rules  []AppliedRule `json:"rules"`
+}
+
+// RulesForWindow returns the enabled rules matching w in config order, which
+// is also the order the compositor applies them in.
+func RulesForWindow(compositor string, rules []WindowRule, windows []Window, w Window) (*WhyResult, error) {
+	m := NewMatcher(compositor, windows)
+	result := &WhyResult{Window: w, Rules: []AppliedRule{}}
+	for i, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		ok, err := m.Match(rule.MatchCriteria, w)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %w", i+1, ruleLabel(rule), err)
+		}
+		if ok {
+			result.Rules = append(result.Rules, AppliedRule{
+				Position:  i + 1,
+				Rule:      rule,
+				Unchecked: Unchecked(rule.MatchCriteria),
+			})
+		}
+	}
+	return result, nil
+}
+
+// FindWindows resolves a user supplied window reference: an exact window id,
+// an app id (case-insensitive) or a substring of the title, in that order.
+func FindWindows(windows []Window, query string) []Window {
+	for _, w := range windows {
+		if w.ID == query {
+			return []Window{w}
+		}
+	}
+
+	var found []Window
+	for _, w := range windows {
+		if strings.EqualFold(w.AppID, query) {
+			found = append(found, w)
+		}
+	}
+	if len(found) > 0 {
+		return found
+	}
+
+	lower := strings.ToLower(query)
+	for _, w := range windows {
+		if strings.Contains(strings.ToLower(w.Title), lower) {
+			found = append(found, w)
+		}
+	}
+	return found
+}
+
+func ruleLabel(rule WindowRule) string {
+	switch {
+	case rule.Name != "":
+		return rule.Name
+	case rule.ID != "":
+		return rule.ID
+	default:
+		return rule.Source
+	}
+}
@@ -0,0 +1,108 @@
+package windowrules
+
+import "testing"
+
+func boolPtr(b bool) *bool { return &b }
+
+var testWindows = []Window{
+	{ID: "1", AppID: "org.gnome.Calculator", Title: "Calculator", IsFloating: true},
+	{ID: "2", AppID: "firefox", Title: "Mozilla Firefox", IsFocused: true},
+	{ID: "3", AppID: "firefox", Title: "Picture-in-Picture", IsFloating: true},
+	{ID: "4", AppID: "Steam", Title: "Steam", XWayland: true},
+}
+
+func matchIDs(t *testing.T, compositor string, c MatchCriteria) []string {
+	t.Helper()
+	result, err := Test(compositor, c, testWindows)
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+	ids := []string{}
+	for _, w := range result.Matches {
+		ids = append(ids, w.ID)
+	}
+	return ids
+}
+
+func TestMatchModes(t *testing.T) {
+	tests := []struct {
+		name       string
+		compositor string
+		criteria   MatchCriteria
+		want       []string
+	}{
+		{"regex partial", "niri", MatchCriteria{AppID: "fire"}, []string{"2", "3"}},
+		{"regex anchored", "niri", MatchCriteria{AppID: `^org\.gnome\.`}, []string{"1"}},
+		{"regex with bool", "sway", MatchCriteria{AppID: "firefox", IsFloating: boolPtr(true)}, []string{"3"}},
+		{"hyprland full match", "hyprland", MatchCriteria{AppID: "fire"}, []string{}},
+		{"hyprland group", "hyprland", MatchCriteria{AppID: "^(firefox)$", Title: "Picture.*"}, []string{"3"}},
+		{"hyprland negative", "hyprland", MatchCriteria{AppID: "negative:firefox"}, []string{"1", "4"}},
+		{"glob", "labwc", MatchCriteria{AppID: "STEAM"}, []string{"4"}},
+		{"glob wildcard", "labwc", MatchCriteria{Title: "*fox"}, []string{"2"}},
+		{"focused", "sway", MatchCriteria{AppID: "__focused__"}, []string{"2", "3"}},
+		{"xwayland", "niri", MatchCriteria{XWayland: boolPtr(true)}, []string{"4"}},
+		{"empty matches all", "niri", MatchCriteria{}, []string{"1", "2", "3", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchIDs(t, tt.compositor, tt.criteria)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchInvalidRegex(t *testing.T) {
+	if _, err := Test("niri", MatchCriteria{AppID: "("}, testWindows); err == nil {
+		t.Error("Expected an error for an invalid regex")
+	}
+}
+
+func TestTestReportsUnchecked(t *testing.T) {
+	result, err := Test("niri", MatchCriteria{AppID: "firefox", AtStartup: boolPtr(true)}, testWindows)
+	if err != nil {
+		t.Fatalf("Test failed: %v", err)
+	}
+	if len(result.Unchecked) != 1 || result.Unchecked[0] != "atStartup" || result.Total != 4 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestRulesForWindow(t *testing.T) {
+	rules := []WindowRule{
+		{ID: "a", Enabled: true, MatchCriteria: MatchCriteria{AppID: "firefox"}},
+		{ID: "b", Enabled: false, MatchCriteria: MatchCriteria{AppID: "firefox"}},
+		{ID: "c", Enabled: true, MatchCriteria: MatchCriteria{AppID: "steam"}},
+		{ID: "d", Enabled: true, MatchCriteria: MatchCriteria{Title: "^Picture"}},
+	}
+
+	result, err := RulesForWindow("niri", rules, testWindows, testWindows[2])
+	if err != nil {
+		t.Fatalf("RulesForWindow failed: %v", err)
+	}
+	if len(result.Rules) != 2 || result.Rules[0].Rule.ID != "a" || result.Rules[1].Position != 4 {
+		t.Errorf("Unexpected rules: %+v", result.Rules)
+	}
+}
+
+func TestFindWindows(t *testing.T) {
+	if got := FindWindows(testWindows, "4"); len(got) != 1 || got[0].AppID != "Steam" {
+		t.Errorf("Lookup by id failed: %+v", got)
+	}
+	if got := FindWindows(testWindows, "FIREFOX"); len(got) != 2 {
+		t.Errorf("Lookup by app id failed: %+v", got)
+	}
+	if got := FindWindows(testWindows, "picture"); len(got) != 1 || got[0].ID != "3" {
+		t.Errorf("Lookup by title failed: %+v", got)
+	}
+	if got := FindWindows(testWindows, "nothing"); len(got) != 0 {
+		t.Errorf("Expected no windows, got %+v", got)
+	}
+}
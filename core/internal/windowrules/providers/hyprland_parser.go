@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowrules"
 )
@@ -548,7 +549,7 @@ func (p *HyprlandWritableProvider) writeDMSRules(rules []windowrules.WindowRule)
 		lines = append(lines, p.formatRuleLines(rule)...)
 	}
 
-	return os.WriteFile(rulesPath, []byte(strings.Join(lines, "\n")), 0644)
+	return snapshot.WriteFile(rulesPath, []byte(strings.Join(lines, "\n")), 0644)
 }
 
 func (p *HyprlandWritableProvider) formatRuleLines(rule windowrules.WindowRule) []string {
@@ -0,0 +1,575 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowrules"
+)
+
+type LabwcRuleAction struct {
+	Name   string
+	Params map[string]string
+}
+
+type LabwcWindowRule struct {
+	Identifier string
+	Title      string
+	Type       string
+	Properties map[string]string
+	Actions    []LabwcRuleAction
+	DMSID      string
+	DMSName    string
+	IsDMS      bool
+	Source     string
+	Line       int
+}
+
+const (
+	labwcDMSBeginMarker = "DMS-RULES-BEGIN"
+	labwcDMSEndMarker   = "DMS-RULES-END"
+)
+
+var (
+	labwcDMSRuleCommentRegex = regexp.MustCompile(`^DMS-RULE:\s*id=([^,]+),\s*name=(.*)$`)
+	labwcDMSBlockRegex       = regexp.MustCompile(`(?s)[ \t]*<!--\s*DMS-RULES-BEGIN.*?<!--\s*DMS-RULES-END\s*-->[ \t]*\n?`)
+	labwcEmptyRulesRegex     = regexp.MustCompile(`<windowRules\s*/>`)
+)
+
+const labwcEmptyConfig = `<?xml version="1.0"?>
+<labwc_config>
+</labwc_config>
+`
+
+type LabwcRulesParser struct {
+	rcFile        string
+	rules         []LabwcWindowRule
+	dmsBlockFound bool
+	rulesBefore   int
+	rulesAfterDMS int
+}
+
+func NewLabwcRulesParser(configDir string) *LabwcRulesParser {
+	expanded, err := utils.ExpandPath(configDir)
+	if err != nil {
+		expanded = configDir
+	}
+	return &LabwcRulesParser{rcFile: filepath.Join(expanded, "rc.xml")}
+}
+
+func (p *LabwcRulesParser) Parse() ([]LabwcWindowRule, error) {
+	data, err := os.ReadFile(p.rcFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.parse(data); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", p.rcFile, err)
+	}
+	return p.rules, nil
+}
+
+func (p *LabwcRulesParser) parse(data []byte) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var stack []string
+	var current *LabwcWindowRule
+	var action *LabwcRuleAction
+	var param string
+	var pendingID, pendingName string
+	inDMS, dmsEnded := false, false
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			stack = append(stack, name)
+
+			switch {
+			case name == "windowRule" && parent == "windowRules":
+				line, _ := decoder.InputPos()
+				current = &LabwcWindowRule{
+					Properties: make(map[string]string),
+					DMSID:      pendingID,
+					DMSName:    pendingName,
+					IsDMS:      inDMS,
+					Source:     p.rcFile,
+					Line:       line,
+				}
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "identifier":
+						current.Identifier = attr.Value
+					case "title":
+						current.Title = attr.Value
+					case "type":
+						current.Type = attr.Value
+					default:
+						current.Properties[attr.Name.Local] = attr.Value
+					}
+				}
+				pendingID, pendingName = "", ""
+			case name == "action" && current != nil && action == nil:
+				action = &LabwcRuleAction{Params: make(map[string]string)}
+				for _, attr := range t.Attr {
+					if attr.Name.Local == "name" {
+						action.Name = attr.Value
+						continue
+					}
+					action.Params[attr.Name.Local] = attr.Value
+				}
+			case action != nil && parent == "action":
+				param = name
+			}
+
+		case xml.EndElement:
+			name := t.Name.Local
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+
+			switch {
+			case name == "windowRule" && current != nil:
+				switch {
+				case current.IsDMS:
+				case dmsEnded:
+					p.rulesAfterDMS++
+				default:
+					p.rulesBefore++
+				}
+				p.rules = append(p.rules, *current)
+				current = nil
+			case name == "action" && action != nil && len(stack) > 0 && stack[len(stack)-1] == "windowRule":
+				current.Actions = append(current.Actions, *action)
+				action = nil
+			case name == param:
+				param = ""
+			}
+
+		case xml.CharData:
+			if action != nil && param != "" {
+				if text := strings.TrimSpace(string(t)); text != "" {
+					action.Params[param] = text
+				}
+			}
+
+		case xml.Comment:
+			comment := strings.TrimSpace(string(t))
+			switch {
+			case strings.HasPrefix(comment, labwcDMSBeginMarker):
+				inDMS = true
+				p.dmsBlockFound = true
+			case strings.HasPrefix(comment, labwcDMSEndMarker):
+				inDMS = false
+				dmsEnded = true
+			default:
+				if matches := labwcDMSRuleCommentRegex.FindStringSubmatch(comment); matches != nil {
+					pendingID = strings.TrimSpace(matches[1])
+					pendingName = strings.TrimSpace(matches[2])
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *LabwcRulesParser) HasDMSRulesIncluded() bool {
+	return p.dmsBlockFound
+}
+
+func (p *LabwcRulesParser) buildDMSStatus() *windowrules.DMSRulesStatus {
+	status := &windowrules.DMSRulesStatus{
+		Exists:          p.dmsBlockFound,
+		Included:        p.dmsBlockFound,
+		IncludePosition: -1,
+		RulesAfterDMS:   p.rulesAfterDMS,
+	}
+	if p.dmsBlockFound {
+		status.IncludePosition = p.rulesBefore
+	}
+
+	switch {
+	case !p.dmsBlockFound:
+		status.Effective = false
+		status.StatusMessage = "rc.xml has no DMS window rules block"
+	case p.rulesAfterDMS > 0:
+		status.Effective = true
+		status.OverriddenBy = p.rulesAfterDMS
+		status.StatusMessage = "Some DMS rules may be overridden by config rules"
+	default:
+		status.Effective = true
+		status.StatusMessage = "DMS window rules are active"
+	}
+
+	return status
+}
+
+type LabwcRulesParseResult struct {
+	Rules            []LabwcWindowRule
+	DMSRulesIncluded bool
+	DMSStatus        *windowrules.DMSRulesStatus
+}
+
+func ParseLabwcWindowRules(configDir string) (*LabwcRulesParseResult, error) {
+	parser := NewLabwcRulesParser(configDir)
+	rules, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+	return &LabwcRulesParseResult{
+		Rules:            rules,
+		DMSRulesIncluded: parser.HasDMSRulesIncluded(),
+		DMSStatus:        parser.buildDMSStatus(),
+	}, nil
+}
+
+func applyLabwcRuleAction(actions *windowrules.Actions, action LabwcRuleAction) {
+	t := true
+	switch action.Name {
+	case "Maximize", "ToggleMaximize":
+		actions.OpenMaximized = &t
+	case "ToggleFullscreen":
+		actions.OpenFullscreen = &t
+	case "ToggleOmnipresent":
+		actions.Pin = &t
+	case "SendToDesktop":
+		actions.OpenOnWorkspace = action.Params["to"]
+	case "MoveToOutput":
+		actions.OpenOnOutput = action.Params["output"]
+	case "ResizeTo":
+		if w, h := action.Params["width"], action.Params["height"]; w != "" && h != "" {
+			actions.Size = w + " " + h
+		}
+	case "MoveTo":
+		if x, y := action.Params["x"], action.Params["y"]; x != "" && y != "" {
+			actions.Move = x + " " + y
+		}
+	case "SetDecorations":
+		if action.Params["decorations"] == "none" {
+			actions.NoBorder = &t
+		}
+	}
+}
+
+func convertLabwcRule(lr LabwcWindowRule) windowrules.WindowRule {
+	wr := windowrules.WindowRule{
+		Enabled: true,
+		Source:  lr.Source,
+		MatchCriteria: windowrules.MatchCriteria{
+			AppID: lr.Identifier,
+			Title: lr.Title,
+		},
+	}
+	if lr.Properties["serverDecoration"] == "no" {
+		t := true
+		wr.Actions.NoBorder = &t
+	}
+	for _, action := range lr.Actions {
+		applyLabwcRuleAction(&wr.Actions, action)
+	}
+	return wr
+}
+
+func ConvertLabwcRulesToWindowRules(labwcRules []LabwcWindowRule) []windowrules.WindowRule {
+	result := make([]windowrules.WindowRule, 0, len(labwcRules))
+	for i, lr := range labwcRules {
+		wr := convertLabwcRule(lr)
+		wr.ID = strconv.Itoa(i)
+		if lr.IsDMS && lr.DMSID != "" {
+			wr.ID = lr.DMSID
+			wr.Name = lr.DMSName
+		}
+		result = append(result, wr)
+	}
+	return result
+}
+
+// LabwcWritableProvider keeps DMS rules in a marked block inside the
+// <windowRules> section of rc.xml, since labwc has no include mechanism.
+type LabwcWritableProvider struct {
+	configDir string
+}
+
+func NewLabwcWritableProvider(configDir string) *LabwcWritableProvider {
+	return &LabwcWritableProvider{configDir: configDir}
+}
+
+func (p *LabwcWritableProvider) Name() string {
+	return "labwc"
+}
+
+func (p *LabwcWritableProvider) GetOverridePath() string {
+	expanded, _ := utils.ExpandPath(p.configDir)
+	return filepath.Join(expanded, "rc.xml")
+}
+
+func (p *LabwcWritableProvider) GetRuleSet() (*windowrules.RuleSet, error) {
+	result, err := ParseLabwcWindowRules(p.configDir)
+	if err != nil {
+		return nil, err
+	}
+	return &windowrules.RuleSet{
+		Title:            "labwc Window Rules",
+		Provider:         "labwc",
+		Rules:            ConvertLabwcRulesToWindowRules(result.Rules),
+		DMSRulesIncluded: result.DMSRulesIncluded,
+		DMSStatus:        result.DMSStatus,
+	}, nil
+}
+
+func (p *LabwcWritableProvider) SetRule(rule windowrules.WindowRule) error {
+	if rule.MatchCriteria.AppID == "" && rule.MatchCriteria.Title == "" {
+		return fmt.Errorf("labwc window rules need an app id or title")
+	}
+
+	rules, err := p.LoadDMSRules()
+	if err != nil {
+		rules = []windowrules.WindowRule{}
+	}
+
+	found := false
+	for i, r := range rules {
+		if r.ID == rule.ID {
+			rules[i] = rule
+			found = true
+			break
+		}
+	}
+	if !found {
+		rules = append(rules, rule)
+	}
+
+	return p.writeDMSRules(rules)
+}
+
+func (p *LabwcWritableProvider) RemoveRule(id string) error {
+	rules, err := p.LoadDMSRules()
+	if err != nil {
+		return err
+	}
+
+	newRules := make([]windowrules.WindowRule, 0, len(rules))
+	for _, r := range rules {
+		if r.ID != id {
+			newRules = append(newRules, r)
+		}
+	}
+
+	return p.writeDMSRules(newRules)
+}
+
+func (p *LabwcWritableProvider) ReorderRules(ids []string) error {
+	rules, err := p.LoadDMSRules()
+	if err != nil {
+		return err
+	}
+
+	ruleMap := make(map[string]windowrules.WindowRule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	newRules := make([]windowrules.WindowRule, 0, len(ids))
+	for _, id := range ids {
+		if r, ok := ruleMap[id]; ok {
+			newRules = append(newRules, r)
+			delete(ruleMap, id)
+		}
+	}
+
+	for _, r := range rules {
+		if _, ok := ruleMap[r.ID]; ok {
+			newRules = append(newRules, r)
+		}
+	}
+
+	return p.writeDMSRules(newRules)
+}
+
+func (p *LabwcWritableProvider) LoadDMSRules() ([]windowrules.WindowRule, error) {
+	parser := NewLabwcRulesParser(p.configDir)
+	labwcRules, err := parser.Parse()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []windowrules.WindowRule{}, nil
+		}
+		return nil, err
+	}
+
+	var rules []windowrules.WindowRule
+	for _, lr := range labwcRules {
+		if !lr.IsDMS {
+			continue
+		}
+		wr := convertLabwcRule(lr)
+		wr.ID = lr.DMSID
+		wr.Name = lr.DMSName
+		if wr.ID == "" {
+			wr.ID = lr.Identifier
+			if wr.ID == "" {
+				wr.ID = lr.Title
+			}
+		}
+		rules = append(rules, wr)
+	}
+
+	return rules, nil
+}
+
+func (p *LabwcWritableProvider) writeDMSRules(rules []windowrules.WindowRule) error {
+	rcPath := p.GetOverridePath()
+
+	data, err := os.ReadFile(rcPath)
+	switch {
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(filepath.Dir(rcPath), 0755); err != nil {
+			return err
+		}
+		data = []byte(labwcEmptyConfig)
+	case err != nil:
+		return err
+	}
+
+	var block strings.Builder
+	block.WriteString("    <!-- " + labwcDMSBeginMarker + ": managed by DankMaterialShell, changes may be overwritten -->\n")
+	for _, rule := range rules {
+		block.WriteString(p.formatRule(rule))
+	}
+	block.WriteString("    <!-- " + labwcDMSEndMarker + " -->\n")
+
+	content, err := insertLabwcDMSBlock(string(data), block.String())
+	if err != nil {
+		return err
+	}
+	return snapshot.WriteFile(rcPath, []byte(content), 0644)
+}
+
+// insertLabwcDMSBlock replaces an existing DMS block, or appends one to the
+// end of <windowRules> so DMS rules are applied after the user's.
+func insertLabwcDMSBlock(content, block string) (string, error) {
+	if loc := labwcDMSBlockRegex.FindStringIndex(content); loc != nil {
+		return content[:loc[0]] + block + content[loc[1]:], nil
+	}
+
+	if idx := strings.LastIndex(content, "</windowRules>"); idx >= 0 {
+		start := lineStart(content, idx)
+		return content[:start] + block + content[start:], nil
+	}
+
+	section := "  <windowRules>\n" + block + "  </windowRules>\n"
+	if loc := labwcEmptyRulesRegex.FindStringIndex(content); loc != nil {
+		start := lineStart(content, loc[0])
+		end := loc[1]
+		if end < len(content) && content[end] == '\n' {
+			end++
+		}
+		return content[:start] + section + content[end:], nil
+	}
+
+	if idx := strings.LastIndex(content, "</labwc_config>"); idx >= 0 {
+		start := lineStart(content, idx)
+		return content[:start] + section + content[start:], nil
+	}
+
+	return "", fmt.Errorf("rc.xml has no <labwc_config> root element")
+}
+
+// lineStart returns the start of the line containing idx when only
+// whitespace precedes idx on that line, so inserted text keeps the closing
+// tag's indentation intact.
+func lineStart(content string, idx int) int {
+	start := strings.LastIndex(content[:idx], "\n") + 1
+	if strings.TrimSpace(content[start:idx]) != "" {
+		return idx
+	}
+	return start
+}
+
+func (p *LabwcWritableProvider) formatRule(rule windowrules.WindowRule) string {
+	var sb strings.Builder
+	name := strings.ReplaceAll(rule.Name, "--", "-")
+	fmt.Fprintf(&sb, "    <!-- DMS-RULE: id=%s, name=%s -->\n", strings.ReplaceAll(rule.ID, "--", "-"), name)
+
+	attrs := ""
+	if rule.MatchCriteria.AppID != "" {
+		attrs += fmt.Sprintf(` identifier="%s"`, xmlEscape(rule.MatchCriteria.AppID))
+	}
+	if rule.MatchCriteria.Title != "" {
+		attrs += fmt.Sprintf(` title="%s"`, xmlEscape(rule.MatchCriteria.Title))
+	}
+
+	a := rule.Actions
+	if a.NoBorder != nil && *a.NoBorder {
+		attrs += ` serverDecoration="no"`
+	}
+
+	var actions []string
+	if a.OpenMaximized != nil && *a.OpenMaximized {
+		actions = append(actions, `<action name="Maximize" />`)
+	}
+	if a.OpenFullscreen != nil && *a.OpenFullscreen {
+		actions = append(actions, `<action name="ToggleFullscreen" />`)
+	}
+	if a.Pin != nil && *a.Pin {
+		actions = append(actions, `<action name="ToggleOmnipresent" />`)
+	}
+	workspace := a.OpenOnWorkspace
+	if workspace == "" {
+		workspace = a.Workspace
+	}
+	if workspace != "" {
+		actions = append(actions, fmt.Sprintf(`<action name="SendToDesktop" to="%s" follow="no" />`, xmlEscape(workspace)))
+	}
+	output := a.OpenOnOutput
+	if output == "" {
+		output = a.Monitor
+	}
+	if output != "" {
+		actions = append(actions, fmt.Sprintf(`<action name="MoveToOutput" output="%s" />`, xmlEscape(output)))
+	}
+	if fields := strings.Fields(a.Size); len(fields) == 2 {
+		actions = append(actions, fmt.Sprintf(`<action name="ResizeTo" width="%s" height="%s" />`, xmlEscape(fields[0]), xmlEscape(fields[1])))
+	}
+	if fields := strings.Fields(a.Move); len(fields) == 2 {
+		actions = append(actions, fmt.Sprintf(`<action name="MoveTo" x="%s" y="%s" />`, xmlEscape(fields[0]), xmlEscape(fields[1])))
+	}
+
+	if len(actions) == 0 {
+		fmt.Fprintf(&sb, "    <windowRule%s />\n", attrs)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "    <windowRule%s>\n", attrs)
+	for _, action := range actions {
+		fmt.Fprintf(&sb, "      %s\n", action)
+	}
+	sb.WriteString("    </windowRule>\n")
+	return sb.String()
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,162 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const labwcRulesConfig = `<?xml version="1.0"?>
+<labwc_config>
+  <windowRules>
+    <windowRule identifier="org.gnome.Calculator" serverDecoration="no">
+      <action name="ResizeTo" width="400" height="600" />
+    </windowRule>
+  </windowRules>
+</labwc_config>
+`
+
+func writeLabwcConfig(t *testing.T, content string) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "rc.xml"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write rc.xml: %v", err)
+	}
+	return tmpDir
+}
+
+func TestParseLabwcWindowRules(t *testing.T) {
+	tmpDir := writeLabwcConfig(t, `<?xml version="1.0"?>
+<labwc_config>
+  <windowRules>
+    <windowRule identifier="firefox" title="*Private*">
+      <action name="MoveToOutput"><output>HDMI-A-1</output></action>
+      <action name="SendToDesktop" to="2" />
+    </windowRule>
+    <!-- DMS-RULES-BEGIN -->
+    <!-- DMS-RULE: id=wr_1, name=Music -->
+    <windowRule identifier="spotify">
+      <action name="ToggleOmnipresent" />
+    </windowRule>
+    <!-- DMS-RULES-END -->
+    <windowRule identifier="spotify"><action name="Maximize" /></windowRule>
+  </windowRules>
+</labwc_config>
+`)
+
+	result, err := ParseLabwcWindowRules(tmpDir)
+	if err != nil {
+		t.Fatalf("ParseLabwcWindowRules failed: %v", err)
+	}
+	if len(result.Rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d", len(result.Rules))
+	}
+	if !result.DMSRulesIncluded || result.DMSStatus.RulesAfterDMS != 1 || result.DMSStatus.IncludePosition != 1 {
+		t.Errorf("Unexpected DMS status: %+v", result.DMSStatus)
+	}
+
+	rules := ConvertLabwcRulesToWindowRules(result.Rules)
+	first := rules[0]
+	if first.MatchCriteria.AppID != "firefox" || first.MatchCriteria.Title != "*Private*" {
+		t.Errorf("Unexpected match criteria: %+v", first.MatchCriteria)
+	}
+	if first.Actions.OpenOnOutput != "HDMI-A-1" || first.Actions.OpenOnWorkspace != "2" {
+		t.Errorf("Unexpected actions: %+v", first.Actions)
+	}
+	if result.Rules[0].Line != 4 {
+		t.Errorf("Expected rule on line 4, got %d", result.Rules[0].Line)
+	}
+
+	dms := rules[1]
+	if dms.ID != "wr_1" || dms.Name != "Music" || dms.Actions.Pin == nil || !*dms.Actions.Pin {
+		t.Errorf("Unexpected DMS rule: %+v", dms)
+	}
+}
+
+func TestLabwcSetAndLoadDMSRules(t *testing.T) {
+	tmpDir := writeLabwcConfig(t, labwcRulesConfig)
+	provider := NewLabwcWritableProvider(tmpDir)
+
+	rule := newTestWindowRule("wr_1", "Browser & co", "firefox")
+	rule.Actions.OpenMaximized = boolPtr(true)
+	rule.Actions.NoBorder = boolPtr(true)
+	rule.Actions.Size = "800 600"
+	if err := provider.SetRule(rule); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+	if err := provider.SetRule(newTestWindowRule("wr_2", "Term", "foot")); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+
+	rules, err := provider.LoadDMSRules()
+	if err != nil {
+		t.Fatalf("LoadDMSRules failed: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 DMS rules, got %d", len(rules))
+	}
+	if rules[0].Name != "Browser & co" || rules[0].Actions.Size != "800 600" || rules[0].Actions.NoBorder == nil {
+		t.Errorf("Rule did not round trip: %+v", rules[0])
+	}
+
+	ruleSet, err := provider.GetRuleSet()
+	if err != nil {
+		t.Fatalf("GetRuleSet failed: %v", err)
+	}
+	if len(ruleSet.Rules) != 3 || !ruleSet.DMSStatus.Effective || ruleSet.DMSStatus.RulesAfterDMS != 0 {
+		t.Errorf("Unexpected rule set: %d rules, status %+v", len(ruleSet.Rules), ruleSet.DMSStatus)
+	}
+
+	if err := provider.ReorderRules([]string{"wr_2", "wr_1"}); err != nil {
+		t.Fatalf("ReorderRules failed: %v", err)
+	}
+	if err := provider.RemoveRule("wr_1"); err != nil {
+		t.Fatalf("RemoveRule failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(provider.GetOverridePath())
+	content := string(data)
+	if strings.Count(content, "DMS-RULES-BEGIN") != 1 || strings.Contains(content, "firefox") {
+		t.Errorf("Expected a single block without the removed rule:\n%s", content)
+	}
+	if !strings.Contains(content, `identifier="org.gnome.Calculator"`) {
+		t.Errorf("User rule was lost:\n%s", content)
+	}
+}
+
+func TestLabwcWriteWithoutWindowRules(t *testing.T) {
+	tmpDir := writeLabwcConfig(t, "<?xml version=\"1.0\"?>\n<labwc_config>\n  <core><gap>4</gap></core>\n</labwc_config>\n")
+	provider := NewLabwcWritableProvider(tmpDir)
+
+	if err := provider.SetRule(newTestWindowRule("wr_1", "", "foot")); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(provider.GetOverridePath())
+	content := string(data)
+	if !strings.Contains(content, "<windowRules>") || !strings.Contains(content, "<gap>4</gap>") {
+		t.Errorf("Expected a windowRules section next to existing settings:\n%s", content)
+	}
+	if !strings.HasSuffix(content, "</windowRules>\n</labwc_config>\n") {
+		t.Errorf("Section was not placed before the closing root tag:\n%s", content)
+	}
+
+	if err := provider.SetRule(newTestWindowRule("wr_2", "", "")); err == nil {
+		t.Error("Expected an error for a rule without app id or title")
+	}
+}
+
+func TestLabwcWriteCreatesConfig(t *testing.T) {
+	tmpDir := filepath.Join(t.TempDir(), "labwc")
+	provider := NewLabwcWritableProvider(tmpDir)
+
+	if err := provider.SetRule(newTestWindowRule("wr_1", "", "foot")); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+
+	rules, err := provider.LoadDMSRules()
+	if err != nil || len(rules) != 1 {
+		t.Fatalf("Expected the new rule to load back, got %v, %v", rules, err)
+	}
+}
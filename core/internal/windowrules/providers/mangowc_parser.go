@@ -0,0 +1,512 @@
+package providers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowrules"
+)
+
+type MangoWCWindowRule struct {
+	AppID   string
+	Title   string
+	Props   [][2]string
+	DMSID   string
+	DMSName string
+	Source  string
+	Line    int
+}
+
+type MangoWCRulesParser struct {
+	configDir        string
+	dmsRulesPath     string
+	processedFiles   map[string]bool
+	rules            []MangoWCWindowRule
+	dmsRulesExists   bool
+	dmsRulesIncluded bool
+	includeCount     int
+	dmsIncludePos    int
+	rulesAfterDMS    int
+}
+
+func NewMangoWCRulesParser(configDir string) *MangoWCRulesParser {
+	return &MangoWCRulesParser{
+		configDir:      configDir,
+		processedFiles: make(map[string]bool),
+		rules:          []MangoWCWindowRule{},
+		dmsIncludePos:  -1,
+	}
+}
+
+func (p *MangoWCRulesParser) Parse() ([]MangoWCWindowRule, error) {
+	expandedDir, err := utils.ExpandPath(p.configDir)
+	if err != nil {
+		return nil, err
+	}
+	if abs, err := filepath.Abs(expandedDir); err == nil {
+		expandedDir = abs
+	}
+
+	p.dmsRulesPath = filepath.Join(expandedDir, "dms", "windowrules.conf")
+	if _, err := os.Stat(p.dmsRulesPath); err == nil {
+		p.dmsRulesExists = true
+	}
+
+	mainConfig := filepath.Join(expandedDir, "config.conf")
+	if _, err := os.Stat(mainConfig); os.IsNotExist(err) {
+		mainConfig = filepath.Join(expandedDir, "mango.conf")
+	}
+
+	if err := p.parseFile(mainConfig); err != nil {
+		return nil, err
+	}
+
+	if p.dmsRulesExists && !p.dmsRulesIncluded {
+		_ = p.parseFile(p.dmsRulesPath)
+	}
+
+	return p.rules, nil
+}
+
+func (p *MangoWCRulesParser) parseFile(filePath string) error {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+
+	if p.processedFiles[absPath] {
+		return nil
+	}
+	p.processedFiles[absPath] = true
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return err
+	}
+
+	isDMS := absPath == p.dmsRulesPath
+	var dmsID, dmsName string
+
+	for lineNum, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			dmsID, dmsName = "", ""
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			if matches := dmsRuleCommentRegex.FindStringSubmatch(trimmed); matches != nil {
+				dmsID, dmsName = matches[1], matches[2]
+			}
+			continue
+		case strings.HasPrefix(trimmed, "source"):
+			p.handleSource(trimmed, filepath.Dir(absPath))
+			continue
+		}
+
+		rule := parseMangoWCRuleLine(trimmed)
+		if rule == nil {
+			continue
+		}
+		rule.Source = absPath
+		rule.Line = lineNum + 1
+		if isDMS {
+			rule.DMSID, rule.DMSName = dmsID, dmsName
+		} else if p.dmsRulesIncluded {
+			p.rulesAfterDMS++
+		}
+		p.rules = append(p.rules, *rule)
+	}
+
+	return nil
+}
+
+func (p *MangoWCRulesParser) handleSource(line, baseDir string) {
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) < 2 {
+		return
+	}
+
+	p.includeCount++
+
+	expanded, err := utils.ExpandPath(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return
+	}
+	if !filepath.IsAbs(expanded) {
+		expanded = filepath.Join(baseDir, expanded)
+	}
+	if abs, err := filepath.Abs(expanded); err == nil && abs == p.dmsRulesPath {
+		p.dmsRulesIncluded = true
+		p.dmsIncludePos = p.includeCount
+	}
+
+	_ = p.parseFile(expanded)
+}
+
+func parseMangoWCRuleLine(line string) *MangoWCWindowRule {
+	key, value, ok := strings.Cut(line, "=")
+	if !ok || strings.TrimSpace(key) != "windowrule" {
+		return nil
+	}
+
+	rule := &MangoWCWindowRule{}
+	for _, part := range strings.Split(value, ",") {
+		name, val, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			continue
+		}
+		name, val = strings.TrimSpace(name), strings.TrimSpace(val)
+		switch name {
+		case "appid":
+			rule.AppID = val
+		case "title":
+			rule.Title = val
+		default:
+			rule.Props = append(rule.Props, [2]string{name, val})
+		}
+	}
+	return rule
+}
+
+func applyMangoWCRuleProps(actions *windowrules.Actions, props [][2]string) {
+	t, f := true, false
+	var width, height, offsetX, offsetY string
+	for _, prop := range props {
+		enabled := prop[1] == "1"
+		switch prop[0] {
+		case "isfloating":
+			if enabled {
+				actions.OpenFloating = &t
+			} else {
+				actions.Tile = &t
+			}
+		case "isfullscreen":
+			if enabled {
+				actions.OpenFullscreen = &t
+			}
+		case "isnoborder":
+			if enabled {
+				actions.NoBorder = &t
+			}
+		case "isglobal":
+			if enabled {
+				actions.Pin = &t
+			}
+		case "isopensilent":
+			if enabled {
+				actions.OpenFocused = &f
+			}
+		case "tags":
+			actions.OpenOnWorkspace = prop[1]
+		case "monitor":
+			actions.OpenOnOutput = prop[1]
+		case "focused_opacity":
+			if v, err := strconv.ParseFloat(prop[1], 64); err == nil {
+				actions.Opacity = &v
+			}
+		case "width":
+			width = prop[1]
+		case "height":
+			height = prop[1]
+		case "offsetx":
+			offsetX = prop[1]
+		case "offsety":
+			offsetY = prop[1]
+		}
+	}
+	if width != "" && height != "" {
+		actions.Size = width + " " + height
+	}
+	if offsetX != "" && offsetY != "" {
+		actions.Move = offsetX + " " + offsetY
+	}
+}
+
+func ConvertMangoWCRulesToWindowRules(mangoRules []MangoWCWindowRule) []windowrules.WindowRule {
+	result := make([]windowrules.WindowRule, 0, len(mangoRules))
+	for i, mr := range mangoRules {
+		wr := windowrules.WindowRule{
+			ID:      strconv.Itoa(i),
+			Enabled: true,
+			Source:  mr.Source,
+			MatchCriteria: windowrules.MatchCriteria{
+				AppID: mr.AppID,
+				Title: mr.Title,
+			},
+		}
+		if mr.DMSID != "" {
+			wr.ID = mr.DMSID
+			wr.Name = mr.DMSName
+		}
+		applyMangoWCRuleProps(&wr.Actions, mr.Props)
+		result = append(result, wr)
+	}
+	return result
+}
+
+func (p *MangoWCRulesParser) buildDMSStatus() *windowrules.DMSRulesStatus {
+	status := &windowrules.DMSRulesStatus{
+		Exists:          p.dmsRulesExists,
+		Included:        p.dmsRulesIncluded,
+		IncludePosition: p.dmsIncludePos,
+		TotalIncludes:   p.includeCount,
+		RulesAfterDMS:   p.rulesAfterDMS,
+	}
+
+	switch {
+	case !p.dmsRulesExists:
+		status.Effective = false
+		status.StatusMessage = "dms/windowrules.conf does not exist"
+	case !p.dmsRulesIncluded:
+		status.Effective = false
+		status.StatusMessage = "dms/windowrules.conf is not sourced in config"
+	case p.rulesAfterDMS > 0:
+		status.Effective = true
+		status.OverriddenBy = p.rulesAfterDMS
+		status.StatusMessage = "Some DMS rules may be overridden by config rules"
+	default:
+		status.Effective = true
+		status.StatusMessage = "DMS window rules are active"
+	}
+
+	return status
+}
+
+type MangoWCRulesParseResult struct {
+	Rules            []MangoWCWindowRule
+	DMSRulesIncluded bool
+	DMSStatus        *windowrules.DMSRulesStatus
+}
+
+func ParseMangoWCWindowRules(configDir string) (*MangoWCRulesParseResult, error) {
+	parser := NewMangoWCRulesParser(configDir)
+	rules, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+	return &MangoWCRulesParseResult{
+		Rules:            rules,
+		DMSRulesIncluded: parser.dmsRulesIncluded,
+		DMSStatus:        parser.buildDMSStatus(),
+	}, nil
+}
+
+type MangoWCWritableProvider struct {
+	configDir string
+}
+
+func NewMangoWCWritableProvider(configDir string) *MangoWCWritableProvider {
+	return &MangoWCWritableProvider{configDir: configDir}
+}
+
+func (p *MangoWCWritableProvider) Name() string {
+	return "mangowc"
+}
+
+func (p *MangoWCWritableProvider) GetOverridePath() string {
+	expanded, _ := utils.ExpandPath(p.configDir)
+	if abs, err := filepath.Abs(expanded); err == nil {
+		expanded = abs
+	}
+	return filepath.Join(expanded, "dms", "windowrules.conf")
+}
+
+func (p *MangoWCWritableProvider) GetRuleSet() (*windowrules.RuleSet, error) {
+	result, err := ParseMangoWCWindowRules(p.configDir)
+	if err != nil {
+		return nil, err
+	}
+	return &windowrules.RuleSet{
+		Title:            "MangoWC Window Rules",
+		Provider:         "mangowc",
+		Rules:            ConvertMangoWCRulesToWindowRules(result.Rules),
+		DMSRulesIncluded: result.DMSRulesIncluded,
+		DMSStatus:        result.DMSStatus,
+	}, nil
+}
+
+func (p *MangoWCWritableProvider) SetRule(rule windowrules.WindowRule) error {
+	if rule.MatchCriteria.AppID == "" && rule.MatchCriteria.Title == "" {
+		return fmt.Errorf("mangowc window rules need an app id or title")
+	}
+
+	rules, err := p.LoadDMSRules()
+	if err != nil {
+		rules = []windowrules.WindowRule{}
+	}
+
+	found := false
+	for i, r := range rules {
+		if r.ID == rule.ID {
+			rules[i] = rule
+			found = true
+			break
+		}
+	}
+	if !found {
+		rules = append(rules, rule)
+	}
+
+	return p.writeDMSRules(rules)
+}
+
+func (p *MangoWCWritableProvider) RemoveRule(id string) error {
+	rules, err := p.LoadDMSRules()
+	if err != nil {
+		return err
+	}
+
+	newRules := make([]windowrules.WindowRule, 0, len(rules))
+	for _, r := range rules {
+		if r.ID != id {
+			newRules = append(newRules, r)
+		}
+	}
+
+	return p.writeDMSRules(newRules)
+}
+
+func (p *MangoWCWritableProvider) ReorderRules(ids []string) error {
+	rules, err := p.LoadDMSRules()
+	if err != nil {
+		return err
+	}
+
+	ruleMap := make(map[string]windowrules.WindowRule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	newRules := make([]windowrules.WindowRule, 0, len(ids))
+	for _, id := range ids {
+		if r, ok := ruleMap[id]; ok {
+			newRules = append(newRules, r)
+			delete(ruleMap, id)
+		}
+	}
+
+	for _, r := range rules {
+		if _, ok := ruleMap[r.ID]; ok {
+			newRules = append(newRules, r)
+		}
+	}
+
+	return p.writeDMSRules(newRules)
+}
+
+func (p *MangoWCWritableProvider) LoadDMSRules() ([]windowrules.WindowRule, error) {
+	rulesPath := p.GetOverridePath()
+	if _, err := os.Stat(rulesPath); err != nil {
+		if os.IsNotExist(err) {
+			return []windowrules.WindowRule{}, nil
+		}
+		return nil, err
+	}
+
+	parser := NewMangoWCRulesParser(p.configDir)
+	parser.dmsRulesPath = rulesPath
+	if err := parser.parseFile(rulesPath); err != nil {
+		return nil, err
+	}
+
+	for i := range parser.rules {
+		if parser.rules[i].DMSID != "" {
+			continue
+		}
+		parser.rules[i].DMSID = parser.rules[i].AppID
+		if parser.rules[i].DMSID == "" {
+			parser.rules[i].DMSID = parser.rules[i].Title
+		}
+	}
+
+	return ConvertMangoWCRulesToWindowRules(parser.rules), nil
+}
+
+func (p *MangoWCWritableProvider) writeDMSRules(rules []windowrules.WindowRule) error {
+	rulesPath := p.GetOverridePath()
+
+	if err := os.MkdirAll(filepath.Dir(rulesPath), 0755); err != nil {
+		return err
+	}
+
+	var lines []string
+	lines = append(lines, "# DMS Window Rules - Managed by DankMaterialShell")
+	lines = append(lines, "# Do not edit manually - changes may be overwritten")
+	lines = append(lines, "")
+
+	for _, rule := range rules {
+		lines = append(lines, p.formatRuleLines(rule)...)
+	}
+
+	return snapshot.WriteFile(rulesPath, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+func (p *MangoWCWritableProvider) formatRuleLines(rule windowrules.WindowRule) []string {
+	var parts []string
+	a := rule.Actions
+
+	if a.OpenFloating != nil && *a.OpenFloating {
+		parts = append(parts, "isfloating:1")
+	}
+	if a.Tile != nil && *a.Tile {
+		parts = append(parts, "isfloating:0")
+	}
+	if a.OpenFullscreen != nil && *a.OpenFullscreen {
+		parts = append(parts, "isfullscreen:1")
+	}
+	if a.NoBorder != nil && *a.NoBorder {
+		parts = append(parts, "isnoborder:1")
+	}
+	if a.Pin != nil && *a.Pin {
+		parts = append(parts, "isglobal:1")
+	}
+	if a.OpenFocused != nil && !*a.OpenFocused {
+		parts = append(parts, "isopensilent:1")
+	}
+	if a.Opacity != nil {
+		parts = append(parts, fmt.Sprintf("focused_opacity:%.2f", *a.Opacity))
+	}
+
+	workspace := a.OpenOnWorkspace
+	if workspace == "" {
+		workspace = a.Workspace
+	}
+	if workspace != "" {
+		parts = append(parts, "tags:"+workspace)
+	}
+	output := a.OpenOnOutput
+	if output == "" {
+		output = a.Monitor
+	}
+	if output != "" {
+		parts = append(parts, "monitor:"+output)
+	}
+	if fields := strings.Fields(a.Size); len(fields) == 2 {
+		parts = append(parts, "width:"+fields[0], "height:"+fields[1])
+	}
+	if fields := strings.Fields(a.Move); len(fields) == 2 {
+		parts = append(parts, "offsetx:"+fields[0], "offsety:"+fields[1])
+	}
+
+	lines := []string{fmt.Sprintf("# DMS-RULE: id=%s, name=%s", rule.ID, rule.Name)}
+	if len(parts) == 0 {
+		lines = append(lines, fmt.Sprintf("# (no actions defined for rule %s)", rule.ID))
+		return append(lines, "")
+	}
+
+	if rule.MatchCriteria.AppID != "" {
+		parts = append(parts, "appid:"+rule.MatchCriteria.AppID)
+	}
+	if rule.MatchCriteria.Title != "" {
+		parts = append(parts, "title:"+rule.MatchCriteria.Title)
+	}
+
+	lines = append(lines, "windowrule="+strings.Join(parts, ","))
+	return append(lines, "")
+}
@@ -0,0 +1,91 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseMangoWCRuleLine(t *testing.T) {
+	rule := parseMangoWCRuleLine("windowrule=isfloating:1,width:900,height:600,appid:pavucontrol")
+	if rule == nil {
+		t.Fatal("Expected rule to parse")
+	}
+	if rule.AppID != "pavucontrol" || len(rule.Props) != 3 {
+		t.Errorf("Unexpected rule: %+v", rule)
+	}
+
+	if parseMangoWCRuleLine("bind=SUPER,q,killclient") != nil {
+		t.Error("Non windowrule lines should not parse")
+	}
+
+	rules := ConvertMangoWCRulesToWindowRules([]MangoWCWindowRule{*rule})
+	a := rules[0].Actions
+	if a.OpenFloating == nil || !*a.OpenFloating || a.Size != "900 600" {
+		t.Errorf("Unexpected actions: %+v", a)
+	}
+}
+
+func TestMangoWCWritableProvider(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := `windowrule=isfloating:1,appid:mpv
+source=./dms/windowrules.conf
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.conf"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := NewMangoWCWritableProvider(tmpDir)
+	if provider.Name() != "mangowc" {
+		t.Errorf("Name() = %q, want mangowc", provider.Name())
+	}
+
+	rule1 := newTestWindowRule("rule1", "Music", "spotify")
+	rule1.Actions.OpenOnWorkspace = "4"
+	rule1.Actions.OpenFocused = boolPtr(false)
+	rule2 := newTestWindowRule("rule2", "Video", "mpv")
+	rule2.Actions.OpenFullscreen = boolPtr(true)
+
+	if err := provider.SetRule(rule1); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+	if err := provider.SetRule(rule2); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+	if err := provider.SetRule(newTestWindowRule("rule3", "", "")); err == nil {
+		t.Error("Expected an error for a rule without app id or title")
+	}
+
+	data, _ := os.ReadFile(provider.GetOverridePath())
+	if !strings.Contains(string(data), "windowrule=isopensilent:1,tags:4,appid:spotify") {
+		t.Errorf("Unexpected DMS file:\n%s", data)
+	}
+
+	ruleSet, err := provider.GetRuleSet()
+	if err != nil {
+		t.Fatalf("GetRuleSet failed: %v", err)
+	}
+	if len(ruleSet.Rules) != 3 || !ruleSet.DMSStatus.Effective || ruleSet.DMSStatus.RulesAfterDMS != 0 {
+		t.Errorf("Unexpected rule set: %+v %+v", ruleSet.Rules, ruleSet.DMSStatus)
+	}
+	if ruleSet.Rules[1].ID != "rule1" || ruleSet.Rules[1].Actions.OpenFocused == nil || *ruleSet.Rules[1].Actions.OpenFocused {
+		t.Errorf("DMS rule did not round trip: %+v", ruleSet.Rules[1])
+	}
+
+	if err := provider.ReorderRules([]string{"rule2", "rule1"}); err != nil {
+		t.Fatalf("ReorderRules failed: %v", err)
+	}
+	rules, _ := provider.LoadDMSRules()
+	if len(rules) != 2 || rules[0].ID != "rule2" {
+		t.Errorf("Unexpected order: %+v", rules)
+	}
+
+	if err := provider.RemoveRule("rule2"); err != nil {
+		t.Fatalf("RemoveRule failed: %v", err)
+	}
+	rules, _ = provider.LoadDMSRules()
+	if len(rules) != 1 || rules[0].ID != "rule1" {
+		t.Errorf("Unexpected rules after removal: %+v", rules)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/sblinch/kdl-go"
 	"github.com/sblinch/kdl-go/document"
 
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
 	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowrules"
 )
 
@@ -737,7 +738,7 @@ func (p *NiriWritableProvider) writeDMSRules(rules []windowrules.WindowRule) err
 		lines = append(lines, "")
 	}
 
-	return os.WriteFile(rulesPath, []byte(strings.Join(lines, "\n")), 0644)
+	return snapshot.WriteFile(rulesPath, []byte(strings.Join(lines, "\n")), 0644)
 }
 
 func (p *NiriWritableProvider) formatRule(rule windowrules.WindowRule) string {
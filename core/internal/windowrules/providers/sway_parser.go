@@ -0,0 +1,708 @@
+package providers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/snapshot"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/utils"
+	"github.com/AvengeMedia/DankMaterialShell/core/internal/windowrules"
+)
+
+type SwayCriterion struct {
+	Key   string
+	Value string
+}
+
+type SwayWindowRule struct {
+	Directive string
+	Criteria  []SwayCriterion
+	Command   string
+	DMSID     string
+	DMSName   string
+	Source    string
+	Line      int
+}
+
+type SwayRulesParser struct {
+	configDir        string
+	dmsRulesPath     string
+	processedFiles   map[string]bool
+	rules            []SwayWindowRule
+	variables        map[string]string
+	dmsRulesExists   bool
+	dmsRulesIncluded bool
+	includeCount     int
+	dmsIncludePos    int
+	rulesAfterDMS    int
+}
+
+func NewSwayRulesParser(configDir string) *SwayRulesParser {
+	return &SwayRulesParser{
+		configDir:      configDir,
+		processedFiles: make(map[string]bool),
+		rules:          []SwayWindowRule{},
+		variables:      make(map[string]string),
+		dmsIncludePos:  -1,
+	}
+}
+
+func (p *SwayRulesParser) Parse() ([]SwayWindowRule, error) {
+	expandedDir, err := utils.ExpandPath(p.configDir)
+	if err != nil {
+		return nil, err
+	}
+	if abs, err := filepath.Abs(expandedDir); err == nil {
+		expandedDir = abs
+	}
+
+	p.dmsRulesPath = filepath.Join(expandedDir, "dms", "windowrules.conf")
+	if _, err := os.Stat(p.dmsRulesPath); err == nil {
+		p.dmsRulesExists = true
+	}
+
+	if err := p.parseFile(filepath.Join(expandedDir, "config")); err != nil {
+		return nil, err
+	}
+
+	if p.dmsRulesExists && !p.dmsRulesIncluded {
+		_ = p.parseFile(p.dmsRulesPath)
+	}
+
+	return p.rules, nil
+}
+
+func (p *SwayRulesParser) parseFile(filePath string) error {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+
+	if p.processedFiles[absPath] {
+		return nil
+	}
+	p.processedFiles[absPath] = true
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return err
+	}
+
+	isDMS := absPath == p.dmsRulesPath
+	var dmsID, dmsName string
+
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNum := i + 1
+		trimmed := strings.TrimSpace(lines[i])
+		for strings.HasSuffix(trimmed, "\\") && i+1 < len(lines) {
+			i++
+			trimmed = strings.TrimSuffix(trimmed, "\\") + " " + strings.TrimSpace(lines[i])
+		}
+
+		switch {
+		case trimmed == "":
+			dmsID, dmsName = "", ""
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			if matches := dmsRuleCommentRegex.FindStringSubmatch(trimmed); matches != nil {
+				dmsID, dmsName = matches[1], matches[2]
+			}
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(trimmed, "set "); ok {
+			fields := strings.Fields(rest)
+			if len(fields) >= 2 && strings.HasPrefix(fields[0], "$") {
+				p.variables[fields[0]] = strings.Join(fields[1:], " ")
+			}
+			continue
+		}
+
+		if target, ok := strings.CutPrefix(trimmed, "include "); ok {
+			p.handleInclude(strings.TrimSpace(target), filepath.Dir(absPath))
+			continue
+		}
+
+		rule := parseSwayRuleLine(p.expandVariables(trimmed))
+		if rule == nil {
+			continue
+		}
+		rule.Source = absPath
+		rule.Line = lineNum
+		if isDMS {
+			rule.DMSID, rule.DMSName = dmsID, dmsName
+		} else if p.dmsRulesIncluded {
+			p.rulesAfterDMS++
+		}
+		p.rules = append(p.rules, *rule)
+	}
+
+	return nil
+}
+
+func (p *SwayRulesParser) handleInclude(target, baseDir string) {
+	p.includeCount++
+
+	expanded, err := utils.ExpandPath(p.expandVariables(strings.Trim(target, `"'`)))
+	if err != nil {
+		return
+	}
+	if !filepath.IsAbs(expanded) {
+		expanded = filepath.Join(baseDir, expanded)
+	}
+
+	matches, err := filepath.Glob(expanded)
+	if err != nil {
+		return
+	}
+	for _, match := range matches {
+		if abs, err := filepath.Abs(match); err == nil && abs == p.dmsRulesPath {
+			p.dmsRulesIncluded = true
+			p.dmsIncludePos = p.includeCount
+		}
+		_ = p.parseFile(match)
+	}
+}
+
+func (p *SwayRulesParser) expandVariables(text string) string {
+	if !strings.Contains(text, "$") {
+		return text
+	}
+	for name, value := range p.variables {
+		text = strings.ReplaceAll(text, name, value)
+	}
+	return text
+}
+
+var swayRuleRegex = regexp.MustCompile(`^(for_window|assign|no_focus)\s*\[(.*?)\]\s*(.*)$`)
+
+func parseSwayRuleLine(line string) *SwayWindowRule {
+	matches := swayRuleRegex.FindStringSubmatch(line)
+	if matches == nil {
+		return nil
+	}
+	command := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(matches[3]), "→"))
+	return &SwayWindowRule{
+		Directive: matches[1],
+		Criteria:  parseSwayCriteria(matches[2]),
+		Command:   command,
+	}
+}
+
+func parseSwayCriteria(s string) []SwayCriterion {
+	var criteria []SwayCriterion
+	i := 0
+	for i < len(s) {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(s) && s[i] != '=' && s[i] != ' ' {
+			i++
+		}
+		key := s[start:i]
+		if key == "" {
+			break
+		}
+
+		value := ""
+		if i < len(s) && s[i] == '=' {
+			i++
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				quote := s[i]
+				i++
+				var sb strings.Builder
+				for i < len(s) && s[i] != quote {
+					if s[i] == '\\' && i+1 < len(s) && s[i+1] == quote {
+						i++
+					}
+					sb.WriteByte(s[i])
+					i++
+				}
+				i++
+				value = sb.String()
+			} else {
+				start := i
+				for i < len(s) && s[i] != ' ' {
+					i++
+				}
+				value = s[start:i]
+			}
+		}
+		criteria = append(criteria, SwayCriterion{Key: key, Value: value})
+	}
+	return criteria
+}
+
+func applySwayCriteria(match *windowrules.MatchCriteria, criteria []SwayCriterion) {
+	t, f := true, false
+	for _, c := range criteria {
+		switch c.Key {
+		case "app_id":
+			match.AppID = c.Value
+		case "class":
+			if match.AppID == "" {
+				match.AppID = c.Value
+			}
+		case "title":
+			match.Title = c.Value
+		case "shell":
+			xwayland := c.Value == "xwayland"
+			match.XWayland = &xwayland
+		case "floating":
+			match.IsFloating = &t
+		case "tiling":
+			match.IsFloating = &f
+		case "urgent":
+			match.IsUrgent = &t
+		}
+	}
+}
+
+// swayMoveTarget extracts the destination of "move [container|window] to
+// workspace|output <target>" style commands.
+func swayMoveTarget(fields []string, kind string) (string, bool) {
+	for i, field := range fields {
+		if field != kind || i+1 >= len(fields) {
+			continue
+		}
+		rest := fields[i+1:]
+		if rest[0] == "number" && len(rest) > 1 {
+			rest = rest[1:]
+		}
+		return strings.Join(rest, " "), true
+	}
+	return "", false
+}
+
+func swayNumbers(fields []string) []string {
+	var numbers []string
+	for _, field := range fields {
+		field = strings.TrimSuffix(strings.TrimSuffix(field, "px"), "ppt")
+		if _, err := strconv.Atoi(field); err == nil {
+			numbers = append(numbers, field)
+		}
+	}
+	return numbers
+}
+
+func applySwayCommand(actions *windowrules.Actions, directive, command string) {
+	t := true
+	switch directive {
+	case "no_focus":
+		actions.NoFocus = &t
+		return
+	case "assign":
+		fields := strings.Fields(command)
+		if output, ok := swayMoveTarget(fields, "output"); ok {
+			actions.OpenOnOutput = output
+			return
+		}
+		if ws, ok := swayMoveTarget(fields, "workspace"); ok {
+			actions.OpenOnWorkspace = ws
+			return
+		}
+		if len(fields) > 0 {
+			actions.OpenOnWorkspace = strings.Join(fields, " ")
+		}
+		return
+	}
+
+	for _, cmd := range strings.FieldsFunc(command, func(r rune) bool { return r == ',' || r == ';' }) {
+		fields := strings.Fields(cmd)
+		if len(fields) == 0 {
+			continue
+		}
+		arg := ""
+		if len(fields) > 1 {
+			arg = fields[1]
+		}
+
+		switch fields[0] {
+		case "floating":
+			switch arg {
+			case "enable":
+				actions.OpenFloating = &t
+			case "disable":
+				actions.Tile = &t
+			}
+		case "fullscreen":
+			if arg == "" || arg == "enable" {
+				actions.OpenFullscreen = &t
+			}
+		case "sticky":
+			if arg == "enable" {
+				actions.Pin = &t
+			}
+		case "border":
+			if arg == "none" || (arg == "pixel" && len(fields) > 2 && fields[2] == "0") {
+				actions.NoBorder = &t
+			}
+		case "focus":
+			if len(fields) == 1 {
+				actions.OpenFocused = &t
+			}
+		case "opacity":
+			value := fields[len(fields)-1]
+			if f, err := strconv.ParseFloat(value, 64); err == nil {
+				actions.Opacity = &f
+			}
+		case "inhibit_idle":
+			actions.Idleinhibit = arg
+		case "resize":
+			if arg == "set" {
+				if numbers := swayNumbers(fields[2:]); len(numbers) == 2 {
+					actions.Size = numbers[0] + " " + numbers[1]
+				}
+			}
+		case "move":
+			if output, ok := swayMoveTarget(fields, "output"); ok {
+				actions.OpenOnOutput = output
+			} else if ws, ok := swayMoveTarget(fields, "workspace"); ok {
+				actions.OpenOnWorkspace = ws
+			} else if arg == "position" {
+				actions.Move = strings.Join(fields[2:], " ")
+			}
+		}
+	}
+}
+
+// ConvertSwayRulesToWindowRules turns parsed lines into rules. Consecutive
+// lines written for the same DMS rule are merged back into one rule.
+func ConvertSwayRulesToWindowRules(swayRules []SwayWindowRule) []windowrules.WindowRule {
+	result := make([]windowrules.WindowRule, 0, len(swayRules))
+	for i, sr := range swayRules {
+		if sr.DMSID != "" && len(result) > 0 {
+			last := &result[len(result)-1]
+			if last.ID == sr.DMSID && last.Source == sr.Source {
+				applySwayCommand(&last.Actions, sr.Directive, sr.Command)
+				continue
+			}
+		}
+
+		wr := windowrules.WindowRule{
+			ID:      strconv.Itoa(i),
+			Enabled: true,
+			Source:  sr.Source,
+		}
+		if sr.DMSID != "" {
+			wr.ID = sr.DMSID
+			wr.Name = sr.DMSName
+		}
+		applySwayCriteria(&wr.MatchCriteria, sr.Criteria)
+		applySwayCommand(&wr.Actions, sr.Directive, sr.Command)
+		result = append(result, wr)
+	}
+	return result
+}
+
+func (p *SwayRulesParser) buildDMSStatus() *windowrules.DMSRulesStatus {
+	status := &windowrules.DMSRulesStatus{
+		Exists:          p.dmsRulesExists,
+		Included:        p.dmsRulesIncluded,
+		IncludePosition: p.dmsIncludePos,
+		TotalIncludes:   p.includeCount,
+		RulesAfterDMS:   p.rulesAfterDMS,
+	}
+
+	switch {
+	case !p.dmsRulesExists:
+		status.Effective = false
+		status.StatusMessage = "dms/windowrules.conf does not exist"
+	case !p.dmsRulesIncluded:
+		status.Effective = false
+		status.StatusMessage = "dms/windowrules.conf is not included in config"
+	case p.rulesAfterDMS > 0:
+		status.Effective = true
+		status.OverriddenBy = p.rulesAfterDMS
+		status.StatusMessage = "Some DMS rules may be overridden by config rules"
+	default:
+		status.Effective = true
+		status.StatusMessage = "DMS window rules are active"
+	}
+
+	return status
+}
+
+type SwayRulesParseResult struct {
+	Rules            []SwayWindowRule
+	DMSRulesIncluded bool
+	DMSStatus        *windowrules.DMSRulesStatus
+}
+
+func ParseSwayWindowRules(configDir string) (*SwayRulesParseResult, error) {
+	parser := NewSwayRulesParser(configDir)
+	rules, err := parser.Parse()
+	if err != nil {
+		return nil, err
+	}
+	return &SwayRulesParseResult{
+		Rules:            rules,
+		DMSRulesIncluded: parser.dmsRulesIncluded,
+		DMSStatus:        parser.buildDMSStatus(),
+	}, nil
+}
+
+type SwayWritableProvider struct {
+	configDir string
+}
+
+func NewSwayWritableProvider(configDir string) *SwayWritableProvider {
+	return &SwayWritableProvider{configDir: configDir}
+}
+
+func (p *SwayWritableProvider) Name() string {
+	if strings.Contains(filepath.Base(p.configDir), "scroll") {
+		return "scroll"
+	}
+	return "sway"
+}
+
+func (p *SwayWritableProvider) GetOverridePath() string {
+	expanded, _ := utils.ExpandPath(p.configDir)
+	if abs, err := filepath.Abs(expanded); err == nil {
+		expanded = abs
+	}
+	return filepath.Join(expanded, "dms", "windowrules.conf")
+}
+
+func (p *SwayWritableProvider) GetRuleSet() (*windowrules.RuleSet, error) {
+	result, err := ParseSwayWindowRules(p.configDir)
+	if err != nil {
+		return nil, err
+	}
+	title := "Sway Window Rules"
+	if p.Name() == "scroll" {
+		title = "Scroll Window Rules"
+	}
+	return &windowrules.RuleSet{
+		Title:            title,
+		Provider:         p.Name(),
+		Rules:            ConvertSwayRulesToWindowRules(result.Rules),
+		DMSRulesIncluded: result.DMSRulesIncluded,
+		DMSStatus:        result.DMSStatus,
+	}, nil
+}
+
+func (p *SwayWritableProvider) SetRule(rule windowrules.WindowRule) error {
+	rules, err := p.LoadDMSRules()
+	if err != nil {
+		rules = []windowrules.WindowRule{}
+	}
+
+	found := false
+	for i, r := range rules {
+		if r.ID == rule.ID {
+			rules[i] = rule
+			found = true
+			break
+		}
+	}
+	if !found {
+		rules = append(rules, rule)
+	}
+
+	return p.writeDMSRules(rules)
+}
+
+func (p *SwayWritableProvider) RemoveRule(id string) error {
+	rules, err := p.LoadDMSRules()
+	if err != nil {
+		return err
+	}
+
+	newRules := make([]windowrules.WindowRule, 0, len(rules))
+	for _, r := range rules {
+		if r.ID != id {
+			newRules = append(newRules, r)
+		}
+	}
+
+	return p.writeDMSRules(newRules)
+}
+
+func (p *SwayWritableProvider) ReorderRules(ids []string) error {
+	rules, err := p.LoadDMSRules()
+	if err != nil {
+		return err
+	}
+
+	ruleMap := make(map[string]windowrules.WindowRule)
+	for _, r := range rules {
+		ruleMap[r.ID] = r
+	}
+
+	newRules := make([]windowrules.WindowRule, 0, len(ids))
+	for _, id := range ids {
+		if r, ok := ruleMap[id]; ok {
+			newRules = append(newRules, r)
+			delete(ruleMap, id)
+		}
+	}
+
+	for _, r := range rules {
+		if _, ok := ruleMap[r.ID]; ok {
+			newRules = append(newRules, r)
+		}
+	}
+
+	return p.writeDMSRules(newRules)
+}
+
+func (p *SwayWritableProvider) LoadDMSRules() ([]windowrules.WindowRule, error) {
+	rulesPath := p.GetOverridePath()
+	if _, err := os.Stat(rulesPath); err != nil {
+		if os.IsNotExist(err) {
+			return []windowrules.WindowRule{}, nil
+		}
+		return nil, err
+	}
+
+	parser := NewSwayRulesParser(p.configDir)
+	parser.dmsRulesPath = rulesPath
+	if err := parser.parseFile(rulesPath); err != nil {
+		return nil, err
+	}
+
+	for i := range parser.rules {
+		if parser.rules[i].DMSID != "" {
+			continue
+		}
+		var match windowrules.MatchCriteria
+		applySwayCriteria(&match, parser.rules[i].Criteria)
+		parser.rules[i].DMSID = match.AppID
+		if parser.rules[i].DMSID == "" {
+			parser.rules[i].DMSID = match.Title
+		}
+	}
+
+	return ConvertSwayRulesToWindowRules(parser.rules), nil
+}
+
+func (p *SwayWritableProvider) writeDMSRules(rules []windowrules.WindowRule) error {
+	rulesPath := p.GetOverridePath()
+
+	if err := os.MkdirAll(filepath.Dir(rulesPath), 0755); err != nil {
+		return err
+	}
+
+	var lines []string
+	lines = append(lines, "# DMS Window Rules - Managed by DankMaterialShell")
+	lines = append(lines, "# Do not edit manually - changes may be overwritten")
+	lines = append(lines, "")
+
+	for _, rule := range rules {
+		lines = append(lines, p.formatRuleLines(rule)...)
+	}
+
+	return snapshot.WriteFile(rulesPath, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+func swayQuote(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
+
+func (p *SwayWritableProvider) formatCriteria(match windowrules.MatchCriteria) string {
+	var parts []string
+	if match.AppID != "" {
+		parts = append(parts, "app_id="+swayQuote(match.AppID))
+	}
+	if match.Title != "" {
+		parts = append(parts, "title="+swayQuote(match.Title))
+	}
+	if match.XWayland != nil {
+		shell := "xdg_shell"
+		if *match.XWayland {
+			shell = "xwayland"
+		}
+		parts = append(parts, "shell="+swayQuote(shell))
+	}
+	if match.IsFloating != nil {
+		if *match.IsFloating {
+			parts = append(parts, "floating")
+		} else {
+			parts = append(parts, "tiling")
+		}
+	}
+	if match.IsUrgent != nil && *match.IsUrgent {
+		parts = append(parts, "urgent=latest")
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+func (p *SwayWritableProvider) formatRuleLines(rule windowrules.WindowRule) []string {
+	var lines []string
+	lines = append(lines, fmt.Sprintf("# DMS-RULE: id=%s, name=%s", rule.ID, rule.Name))
+
+	criteria := p.formatCriteria(rule.MatchCriteria)
+	a := rule.Actions
+
+	workspace := a.OpenOnWorkspace
+	if workspace == "" {
+		workspace = a.Workspace
+	}
+	output := a.OpenOnOutput
+	if output == "" {
+		output = a.Monitor
+	}
+
+	if workspace != "" {
+		lines = append(lines, fmt.Sprintf("assign %s workspace %s", criteria, workspace))
+	} else if output != "" {
+		lines = append(lines, fmt.Sprintf("assign %s output %s", criteria, output))
+	}
+
+	var commands []string
+	if a.OpenFloating != nil && *a.OpenFloating {
+		commands = append(commands, "floating enable")
+	}
+	if a.Tile != nil && *a.Tile {
+		commands = append(commands, "floating disable")
+	}
+	if a.OpenFullscreen != nil && *a.OpenFullscreen {
+		commands = append(commands, "fullscreen enable")
+	}
+	if a.Pin != nil && *a.Pin {
+		commands = append(commands, "sticky enable")
+	}
+	if a.NoBorder != nil && *a.NoBorder {
+		commands = append(commands, "border none")
+	}
+	if a.OpenFocused != nil && *a.OpenFocused {
+		commands = append(commands, "focus")
+	}
+	if a.Opacity != nil {
+		commands = append(commands, fmt.Sprintf("opacity %.2f", *a.Opacity))
+	}
+	if a.Idleinhibit != "" {
+		commands = append(commands, "inhibit_idle "+a.Idleinhibit)
+	}
+	if fields := strings.Fields(a.Size); len(fields) == 2 {
+		commands = append(commands, fmt.Sprintf("resize set %s %s", fields[0], fields[1]))
+	}
+	if a.Move != "" {
+		commands = append(commands, "move position "+a.Move)
+	}
+	if workspace != "" && output != "" {
+		commands = append(commands, "move to output "+output)
+	}
+	if len(commands) > 0 {
+		lines = append(lines, fmt.Sprintf("for_window %s %s", criteria, strings.Join(commands, ", ")))
+	}
+
+	if a.NoFocus != nil && *a.NoFocus {
+		lines = append(lines, fmt.Sprintf("no_focus %s", criteria))
+	}
+
+	if len(lines) == 1 {
+		lines = append(lines, fmt.Sprintf("# (no actions defined for rule %s)", rule.ID))
+	}
+
+	lines = append(lines, "")
+	return lines
+}
@@ -0,0 +1,161 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSwayRuleLine(t *testing.T) {
+	tests := []struct {
+		line      string
+		directive string
+		criteria  int
+		command   string
+	}{
+		{`for_window [app_id="firefox" title="Picture-in-Picture"] floating enable, sticky enable`, "for_window", 2, "floating enable, sticky enable"},
+		{`assign [class="Steam"] → workspace number 5`, "assign", 1, "workspace number 5"},
+		{`no_focus [window_role="pop-up"]`, "no_focus", 1, ""},
+		{`for_window [floating] border none`, "for_window", 1, "border none"},
+		{`bindsym $mod+q kill`, "", 0, ""},
+	}
+
+	for _, tt := range tests {
+		rule := parseSwayRuleLine(tt.line)
+		if tt.directive == "" {
+			if rule != nil {
+				t.Errorf("parseSwayRuleLine(%q) should not match", tt.line)
+			}
+			continue
+		}
+		if rule == nil {
+			t.Fatalf("parseSwayRuleLine(%q) returned nil", tt.line)
+		}
+		if rule.Directive != tt.directive || len(rule.Criteria) != tt.criteria || rule.Command != tt.command {
+			t.Errorf("parseSwayRuleLine(%q) = %+v", tt.line, rule)
+		}
+	}
+
+	criteria := parseSwayCriteria(`app_id="^org\.gnome\..*" title='My "quoted" title' tiling`)
+	if len(criteria) != 3 || criteria[0].Value != `^org\.gnome\..*` || criteria[1].Value != `My "quoted" title` || criteria[2].Key != "tiling" {
+		t.Errorf("Unexpected criteria: %+v", criteria)
+	}
+}
+
+func TestConvertSwayRules(t *testing.T) {
+	rules := ConvertSwayRulesToWindowRules([]SwayWindowRule{
+		{Directive: "for_window", Criteria: []SwayCriterion{{"app_id", "pavucontrol"}}, Command: "floating enable, resize set width 800 px height 600 px, move position 10 20"},
+		{Directive: "assign", Criteria: []SwayCriterion{{"class", "Steam"}, {"shell", "xwayland"}}, Command: "workspace number 5"},
+		{Directive: "for_window", Criteria: []SwayCriterion{{"title", "Music"}}, Command: "move container to output HDMI-A-1; opacity set 0.8"},
+	})
+
+	if len(rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d", len(rules))
+	}
+	a := rules[0].Actions
+	if a.OpenFloating == nil || a.Size != "800 600" || a.Move != "10 20" {
+		t.Errorf("Unexpected actions for rule 0: %+v", a)
+	}
+	if rules[1].MatchCriteria.AppID != "Steam" || rules[1].MatchCriteria.XWayland == nil || rules[1].Actions.OpenOnWorkspace != "5" {
+		t.Errorf("Unexpected rule 1: %+v", rules[1])
+	}
+	if rules[2].Actions.OpenOnOutput != "HDMI-A-1" || rules[2].Actions.Opacity == nil || *rules[2].Actions.Opacity != 0.8 {
+		t.Errorf("Unexpected actions for rule 2: %+v", rules[2].Actions)
+	}
+}
+
+func TestSwayWritableProvider(t *testing.T) {
+	tmpDir := t.TempDir()
+	provider := NewSwayWritableProvider(tmpDir)
+
+	if provider.Name() != "sway" {
+		t.Errorf("Name() = %q, want sway", provider.Name())
+	}
+	if provider.GetOverridePath() != filepath.Join(tmpDir, "dms", "windowrules.conf") {
+		t.Errorf("Unexpected override path %q", provider.GetOverridePath())
+	}
+
+	rule := newTestWindowRule("rule1", "Calculator", "org.gnome.Calculator")
+	rule.Actions.OpenFloating = boolPtr(true)
+	rule.Actions.Opacity = floatPtr(0.9)
+	rule.Actions.OpenOnWorkspace = "3"
+	rule2 := newTestWindowRule("rule2", "Terminal", "foot")
+	rule2.Actions.NoBorder = boolPtr(true)
+	rule3 := newTestWindowRule("rule3", "Browser", "firefox")
+	rule3.Actions.OpenOnOutput = "DP-1"
+
+	if err := provider.SetRule(rule); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+	_ = provider.SetRule(rule2)
+	_ = provider.SetRule(rule3)
+
+	rules, err := provider.LoadDMSRules()
+	if err != nil {
+		t.Fatalf("LoadDMSRules failed: %v", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d: %+v", len(rules), rules)
+	}
+	first := rules[0]
+	if first.ID != "rule1" || first.Name != "Calculator" || first.Actions.OpenOnWorkspace != "3" || first.Actions.OpenFloating == nil || first.Actions.Opacity == nil {
+		t.Errorf("Rule did not round trip: %+v", first)
+	}
+
+	if err := provider.ReorderRules([]string{"rule3", "rule1"}); err != nil {
+		t.Fatalf("ReorderRules failed: %v", err)
+	}
+	if err := provider.RemoveRule("rule1"); err != nil {
+		t.Fatalf("RemoveRule failed: %v", err)
+	}
+	rules, _ = provider.LoadDMSRules()
+	if len(rules) != 2 || rules[0].ID != "rule3" || rules[1].ID != "rule2" {
+		t.Errorf("Unexpected rules after reorder and remove: %+v", rules)
+	}
+}
+
+func TestSwayDMSStatus(t *testing.T) {
+	tmpDir := t.TempDir()
+	provider := NewSwayWritableProvider(tmpDir)
+
+	config := `set $dms dms/windowrules.conf
+for_window [app_id="mpv"] floating enable
+include $dms
+for_window [app_id="foot"] border pixel 2
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "config"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ruleSet, err := provider.GetRuleSet()
+	if err != nil {
+		t.Fatalf("GetRuleSet failed: %v", err)
+	}
+	if ruleSet.DMSStatus.Exists || ruleSet.DMSStatus.Effective {
+		t.Errorf("Expected missing DMS file, got %+v", ruleSet.DMSStatus)
+	}
+
+	rule := newTestWindowRule("rule1", "", "foot")
+	rule.Actions.NoBorder = boolPtr(true)
+	if err := provider.SetRule(rule); err != nil {
+		t.Fatalf("SetRule failed: %v", err)
+	}
+
+	ruleSet, err = provider.GetRuleSet()
+	if err != nil {
+		t.Fatalf("GetRuleSet failed: %v", err)
+	}
+	status := ruleSet.DMSStatus
+	if !status.Included || status.IncludePosition != 1 || status.RulesAfterDMS != 1 || status.OverriddenBy != 1 {
+		t.Errorf("Unexpected DMS status: %+v", status)
+	}
+	if len(ruleSet.Rules) != 3 || ruleSet.Rules[1].ID != "rule1" {
+		t.Errorf("Expected DMS rule in include order, got %+v", ruleSet.Rules)
+	}
+
+	data, _ := os.ReadFile(provider.GetOverridePath())
+	if !strings.Contains(string(data), `for_window [app_id="foot"] border none`) {
+		t.Errorf("Unexpected DMS file:\n%s", data)
+	}
+}
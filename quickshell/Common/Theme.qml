@@ -139,7 +139,7 @@ Singleton {
 
     Component.onCompleted: {
         Quickshell.execDetached(["mkdir", "-p", stateDir]);
-        Proc.runCommand("matugenCheck", ["which", "matugen"], (output, code) => {
+        Proc.runCommand("matugenCheck", ["sh", "-c", "command -v matugen || command -v dms"], (output, code) => {
             matugenAvailable = (code === 0) && !envDisableMatugen;
             const isGreeterMode = (typeof SessionData !== "undefined" && SessionData.isGreeterMode);
 
@@ -1315,6 +1315,9 @@ Singleton {
         if (stockColors) {
             args.push("--stock-colors", JSON.stringify(stockColors));
         }
+        if (typeof SettingsData !== "undefined" && SettingsData.matugenBackend && SettingsData.matugenBackend !== "auto") {
+            args.push("--backend", SettingsData.matugenBackend);
+        }
         if (typeof SettingsData !== "undefined" && SettingsData.syncModeWithPortal) {
             args.push("--sync-mode-with-portal");
         }
@@ -8,6 +8,7 @@ DankModal {
 
     property string outputName: ""
     property var changes: []
+    property string diff: ""
     property int countdown: 10
 
     signal confirmed
@@ -115,6 +116,16 @@ DankModal {
                     }
                 }
 
+                StyledText {
+                    width: parent.width
+                    visible: root.diff !== ""
+                    text: root.diff
+                    font.family: SettingsData.monoFontFamily
+                    font.pixelSize: Theme.fontSizeSmall
+                    color: Theme.surfaceVariantText
+                    wrapMode: Text.WrapAnywhere
+                }
+
                 Item {
                     width: parent.width
                     height: 36
@@ -35,6 +35,8 @@ Singleton {
 
     property bool validatingConfig: false
     property string validationError: ""
+    property string pendingConfigDiff: ""
+    property var swayStateCallback: null
 
     property var currentOutputSet: []
     property string matchedProfile: ""
@@ -297,6 +299,12 @@ Singleton {
         onTriggered: root.manualActivation = false
     }
 
+    Timer {
+        id: swayStateTimer
+        interval: 500
+        onTriggered: root.runSwayStateCallback()
+    }
+
     Timer {
         id: autoSelectDebounceTimer
         interval: 800
@@ -367,6 +375,7 @@ Singleton {
         function onStateChanged() {
             root.outputs = root.buildOutputsMap();
             root.reloadSavedOutputs();
+            root.runSwayStateCallback();
         }
     }
 
@@ -507,6 +516,8 @@ Singleton {
             return parseHyprlandOutputs(content);
         case "dwl":
             return parseMangoOutputs(content);
+        case "sway":
+            return parseSwayOutputs(content);
         default:
             return {};
         }
@@ -551,6 +562,79 @@ Singleton {
         return result;
     }
 
+    function parseSwayOutputs(content) {
+        const result = {};
+        const disableRegex = /^\s*output\s+(\S+)\s+disable\s*$/gm;
+        let match;
+        while ((match = disableRegex.exec(content)) !== null) {
+            result[match[1]] = {
+                "name": match[1],
+                "logical": {
+                    "x": 0,
+                    "y": 0,
+                    "scale": 1.0,
+                    "transform": "Normal"
+                },
+                "modes": [],
+                "current_mode": -1,
+                "vrr_enabled": false,
+                "vrr_supported": false
+            };
+        }
+
+        const outputRegex = /^\s*output\s+(\S+)\s*\{([^}]*)\}/gm;
+        while ((match = outputRegex.exec(content)) !== null) {
+            const name = match[1];
+            const body = match[2];
+
+            const modeMatch = body.match(/mode\s+(\d+)x(\d+)@([\d.]+)Hz/);
+            const posMatch = body.match(/position\s+(-?\d+)\s+(-?\d+)/);
+            const scaleMatch = body.match(/scale\s+([\d.]+)/);
+            const transformMatch = body.match(/transform\s+(\S+)/);
+            const vrrMatch = body.match(/adaptive_sync\s+on/);
+
+            result[name] = {
+                "name": name,
+                "logical": {
+                    "x": posMatch ? parseInt(posMatch[1]) : 0,
+                    "y": posMatch ? parseInt(posMatch[2]) : 0,
+                    "scale": scaleMatch ? parseFloat(scaleMatch[1]) : 1.0,
+                    "transform": transformMatch ? mapSwayTransform(transformMatch[1]) : "Normal"
+                },
+                "modes": modeMatch ? [
+                    {
+                        "width": parseInt(modeMatch[1]),
+                        "height": parseInt(modeMatch[2]),
+                        "refresh_rate": Math.round(parseFloat(modeMatch[3]) * 1000)
+                    }
+                ] : [],
+                "current_mode": 0,
+                "vrr_enabled": !!vrrMatch,
+                "vrr_supported": true
+            };
+        }
+        return result;
+    }
+
+    function mapSwayTransform(transform) {
+        switch (transform) {
+        case "flipped":
+            return "Flipped";
+        case "flipped-90":
+            return "Flipped90";
+        case "flipped-180":
+            return "Flipped180";
+        case "flipped-270":
+            return "Flipped270";
+        case "90":
+        case "180":
+        case "270":
+            return transform;
+        default:
+            return "Normal";
+        }
+    }
+
     function parseHyprlandOutputs(content) {
         const result = {};
         const lines = content.split("\n");
@@ -758,6 +842,13 @@ Singleton {
                 "grepPattern": 'source.*dms/outputs.conf',
                 "includeLine": "source=./dms/outputs.conf"
             };
+        case "sway":
+            return {
+                "configFile": configDir + "/sway/config",
+                "outputsFile": configDir + "/sway/dms/outputs.conf",
+                "grepPattern": 'include.*dms/outputs.conf',
+                "includeLine": "include ./dms/outputs.conf"
+            };
         default:
             return null;
         }
@@ -765,7 +856,7 @@ Singleton {
 
     function checkIncludeStatus() {
         const compositor = CompositorService.compositor;
-        if (compositor !== "niri" && compositor !== "hyprland" && compositor !== "dwl") {
+        if (compositor !== "niri" && compositor !== "hyprland" && compositor !== "dwl" && compositor !== "sway") {
             includeStatus = {
                 "exists": false,
                 "included": false
@@ -909,7 +1000,81 @@ Singleton {
         case "dwl":
             DwlService.generateOutputsConfig(outputsData);
             break;
+        case "sway":
+            applySwayOutputs(outputsData, () => writeSwayOutputsConfig());
+            break;
+        }
+    }
+
+    // sway has no DMS generator of its own: the outputs are applied live and
+    // the daemon renders the applied state into sway/dms/outputs.conf.
+    function buildWlrHeads(outputsData) {
+        const heads = [];
+        for (const output of wlrOutputs) {
+            const data = outputsData[output.name];
+            if (!data || !output.enabled) {
+                heads.push({
+                    "name": output.name,
+                    "enabled": output.enabled
+                });
+                continue;
+            }
+
+            const head = {
+                "name": output.name,
+                "enabled": true,
+                "position": {
+                    "x": data.logical?.x ?? 0,
+                    "y": data.logical?.y ?? 0
+                },
+                "scale": data.logical?.scale ?? 1.0,
+                "transform": mapTransformToWlr(data.logical?.transform ?? "Normal")
+            };
+            const mode = data.modes?.[data.current_mode];
+            if (mode?.id !== undefined)
+                head.modeId = mode.id;
+            if (data.vrr_supported)
+                head.adaptiveSync = data.vrr_enabled ? 1 : 0;
+            heads.push(head);
         }
+        return heads;
+    }
+
+    function applySwayOutputs(outputsData, onApplied) {
+        WlrOutputService.applyConfiguration(buildWlrHeads(outputsData), (success, message) => {
+            if (!success) {
+                ToastService.showError(I18n.tr("Failed to apply display configuration"), message, "", "display-config");
+                backendFetchOutputs();
+                return;
+            }
+            afterSwayState(onApplied);
+        });
+    }
+
+    // The compositor reports the applied heads after the apply succeeds, so
+    // anything rendered from the daemon's state waits for that update.
+    function afterSwayState(callback) {
+        swayStateCallback = callback;
+        swayStateTimer.restart();
+    }
+
+    function runSwayStateCallback() {
+        const callback = swayStateCallback;
+        if (!callback)
+            return;
+        swayStateCallback = null;
+        swayStateTimer.stop();
+        callback();
+    }
+
+    function writeSwayOutputsConfig() {
+        WlrOutputService.writeConfig((result, error) => {
+            if (error) {
+                ToastService.showError(I18n.tr("Failed to save display configuration"), error, "", "display-config");
+                return;
+            }
+            reloadSavedOutputs();
+        });
     }
 
     function normalizeOutputPositions(outputsData) {
@@ -1188,6 +1353,7 @@ Singleton {
         originalNiriSettings = null;
         originalHyprlandSettings = null;
         originalDisplayNameMode = "";
+        pendingConfigDiff = "";
     }
 
     function discardChanges() {
@@ -1262,6 +1428,20 @@ Singleton {
             return;
         }
 
+        if (CompositorService.isSway) {
+            if (formatChanged)
+                SettingsData.saveSettings();
+            applySwayOutputs(buildOutputsWithPendingChanges(), () => {
+                WlrOutputService.previewConfig((preview, error) => {
+                    pendingConfigDiff = preview?.diff ?? "";
+                    if (error)
+                        ToastService.showError(I18n.tr("Failed to preview display configuration"), error, "", "display-config");
+                    changesApplied(changeDescriptions);
+                });
+            });
+            return;
+        }
+
         changesApplied(changeDescriptions);
 
         if (formatChanged)
@@ -1460,6 +1640,8 @@ Singleton {
     }
 
     function confirmChanges() {
+        if (CompositorService.isSway)
+            writeSwayOutputsConfig();
         clearPendingChanges();
         changesConfirmed();
     }
@@ -48,6 +48,7 @@ Item {
         target: DisplayConfigState
         function onChangesApplied(changeDescriptions) {
             confirmationModal.changes = changeDescriptions;
+            confirmationModal.diff = DisplayConfigState.pendingConfigDiff;
             confirmationModal.open();
         }
         function onChangesConfirmed() {
@@ -2145,6 +2145,34 @@ Item {
                 iconName: "auto_awesome"
                 visible: Theme.matugenAvailable
 
+                SettingsDropdownRow {
+                    tab: "theme"
+                    tags: ["matugen", "backend", "native", "generator"]
+                    settingKey: "matugenBackend"
+                    text: I18n.tr("Color Generator")
+                    description: I18n.tr("Automatic uses matugen when installed and falls back to the built-in generator")
+                    options: [I18n.tr("Automatic", "color generator option"), "matugen", I18n.tr("Built-in", "color generator option")]
+                    currentValue: {
+                        switch (SettingsData.matugenBackend) {
+                        case "matugen":
+                            return "matugen";
+                        case "native":
+                            return I18n.tr("Built-in", "color generator option");
+                        default:
+                            return I18n.tr("Automatic", "color generator option");
+                        }
+                    }
+                    onValueChanged: value => {
+                        if (value === "matugen") {
+                            SettingsData.set("matugenBackend", "matugen");
+                        } else if (value === I18n.tr("Built-in", "color generator option")) {
+                            SettingsData.set("matugenBackend", "native");
+                        } else {
+                            SettingsData.set("matugenBackend", "auto");
+                        }
+                    }
+                }
+
                 SettingsToggleRow {
                     tab: "theme"
                     tags: ["matugen", "user", "templates"]
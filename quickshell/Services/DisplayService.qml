@@ -52,6 +52,8 @@ Singleton {
     property real gammaSunPosition: gammaState?.sunPosition ?? 0
     property int gammaLowTemp: gammaState?.config?.LowTemp ?? 0
     property int gammaHighTemp: gammaState?.config?.HighTemp ?? 0
+    property var gammaOutputs: gammaState?.outputs ?? []
+    property var gammaOutputConfigs: gammaState?.config?.OutputConfigs ?? ({})
 
     function markDeviceUserControlled(deviceId) {
         const newControlled = Object.assign({}, userControlledDevices);
@@ -672,6 +674,30 @@ Singleton {
         }
     }
 
+    function setGammaOutputConfig(outputName, config, callback) {
+        if (!DMSService.isConnected) {
+            return;
+        }
+
+        const params = Object.assign({}, config || {}, {
+            "output": outputName
+        });
+        DMSService.sendRequest("wayland.gamma.setOutputConfig", params, response => {
+            if (response.error) {
+                console.error("DisplayService: Failed to set output gamma config:", response.error);
+            }
+            if (callback) {
+                callback(!response.error, response.error || "");
+            }
+        });
+    }
+
+    function clearGammaOutputConfig(outputName, callback) {
+        setGammaOutputConfig(outputName, {
+            "clear": true
+        }, callback);
+    }
+
     function checkGammaControlAvailability() {
         if (!DMSService.isConnected) {
             return;
@@ -209,7 +209,27 @@ Singleton {
         return envObj;
     }
 
+    function launchOnAssignedWorkspace(desktopEntry, launch) {
+        const appId = desktopEntry.id || "";
+        if (!appId || !DMSService.isConnected || DMSService.apiVersion < 38 || !DMSService.capabilities.includes("workspacemeta")) {
+            launch();
+            return;
+        }
+
+        DMSService.sendRequest("workspacemeta.activateForApp", {
+            "appId": appId
+        }, response => {
+            if (response.error)
+                console.warn("SessionService: Failed to switch to assigned workspace:", response.error);
+            launch();
+        });
+    }
+
     function launchDesktopEntry(desktopEntry, useNvidia) {
+        launchOnAssignedWorkspace(desktopEntry, () => _launchDesktopEntry(desktopEntry, useNvidia));
+    }
+
+    function _launchDesktopEntry(desktopEntry, useNvidia) {
         let cmd = desktopEntry.command;
         if (useNvidia && nvidiaCommand)
             cmd = [nvidiaCommand].concat(cmd);
@@ -264,6 +284,10 @@ Singleton {
     }
 
     function launchDesktopAction(desktopEntry, action, useNvidia) {
+        launchOnAssignedWorkspace(desktopEntry, () => _launchDesktopAction(desktopEntry, action, useNvidia));
+    }
+
+    function _launchDesktopAction(desktopEntry, action, useNvidia) {
         let cmd = action.command;
         if (useNvidia && nvidiaCommand)
             cmd = [nvidiaCommand].concat(cmd);
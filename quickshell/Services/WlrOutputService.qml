@@ -272,4 +272,32 @@ Singleton {
     function configureMultipleOutputs(configs, callback) {
         applyConfiguration(configs, callback)
     }
+
+    function previewConfig(callback) {
+        requestConfigWrite("wlroutput.previewConfig", callback)
+    }
+
+    function writeConfig(callback) {
+        requestConfigWrite("wlroutput.writeConfig", callback)
+    }
+
+    function requestConfigWrite(method, callback) {
+        if (!DMSService.isConnected || !wlrOutputAvailable) {
+            if (callback) {
+                callback(null, "Not connected")
+            }
+            return
+        }
+
+        DMSService.sendRequest(method, {
+            "compositor": CompositorService.compositor
+        }, response => {
+            if (response.error) {
+                console.warn("WlrOutputService:", method, "error:", response.error)
+            }
+            if (callback) {
+                callback(response.result || null, response.error || "")
+            }
+        })
+    }
 }